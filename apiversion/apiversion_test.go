@@ -0,0 +1,80 @@
+package apiversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangid/graphql-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioning(t *testing.T) {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := Middleware()(NewVersionedHandler("1").
+		Version("1", func(rw http.ResponseWriter, req *http.Request) { rw.Write([]byte("v1")) },
+			Deprecation{Sunset: sunset, Link: "https://docs/migrate-v2"}).
+		Version("2", func(rw http.ResponseWriter, req *http.Request) { rw.Write([]byte("v2")) }))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Client-Name", "mobile")
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "v1", rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get(HeaderDeprecation))
+	assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", rec.Header().Get(HeaderSunset))
+	assert.Equal(t, `<https://docs/migrate-v2>; rel="deprecation"; type="text/html"`, rec.Header().Get(HeaderLink))
+	assert.Equal(t, `"GET /orders is deprecated and will be removed at 2030-01-01"`, rec.Header().Get(HeaderWarning))
+
+	headerReq := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	headerReq.Header.Set(HeaderAPIVersion, "v2")
+	acceptReq := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	acceptReq.Header.Set("Accept", "application/vnd.candi.v2+json")
+	for _, req := range []*http.Request{httptest.NewRequest(http.MethodGet, "/v2/orders", nil), headerReq, acceptReq} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, "v2", rec.Body.String())
+		assert.Equal(t, "2", rec.Header().Get(HeaderAPIVersion))
+		assert.Empty(t, rec.Header().Get(HeaderDeprecation))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v3/orders", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	Deprecate(Deprecation{Name: "legacy", Sunset: time.Now().Add(-time.Hour), Enforce: true})(http.NotFoundHandler()).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/legacy", nil))
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	usages := map[string]Usage{}
+	for _, usage := range Usages() {
+		usages[usage.Name] = usage
+	}
+	assert.Equal(t, int64(1), usages["GET /orders"].Count)
+	assert.Equal(t, "mobile", usages["GET /orders"].LastClient)
+}
+
+func TestGraphQLDeprecations(t *testing.T) {
+	schema := graphql.MustParseSchema(`
+		schema { query: Query }
+		type Query { user: User }
+		type User {
+			name: String @deprecated(reason: "use fullName, sunset 2030-06-30")
+			fullName: String
+			age: Int @deprecated
+		}`, nil)
+
+	deprecations := GraphQLDeprecations(schema.ASTSchema())
+	assert.Len(t, deprecations, 2)
+	assert.Equal(t, time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC), deprecations["User.name"].Sunset)
+	assert.Equal(t, "No longer supported", deprecations["User.age"].Message)
+
+	ctx := WithCollector(context.Background())
+	assert.True(t, Collect(ctx, deprecations["User.name"]))
+	assert.True(t, Collect(ctx, deprecations["User.name"]))
+	assert.Len(t, Collected(ctx), 1)
+	assert.False(t, Collect(context.Background(), deprecations["User.age"]))
+}
@@ -0,0 +1,168 @@
+// Package apiversion REST API versioning (path prefix or header negotiation) and deprecation of REST route and GraphQL
+// field (@deprecated directive) with usage tracking, Deprecation/Sunset/Link/Warning response header is emitted
+// automatically so client get notice before removal
+package apiversion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/wrapper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// HeaderDeprecation deprecation response header (RFC 9745)
+	HeaderDeprecation = "Deprecation"
+	// HeaderSunset sunset response header (RFC 8594)
+	HeaderSunset = "Sunset"
+	// HeaderLink link response header, contain deprecation documentation link
+	HeaderLink = "Link"
+	// HeaderWarning warning response header
+	HeaderWarning = "Warning"
+
+	// KindREST usage kind of REST route
+	KindREST = "rest"
+	// KindGraphQL usage kind of GraphQL field
+	KindGraphQL = "graphql"
+)
+
+type (
+	// Deprecation deprecated API
+	Deprecation struct {
+		// Name of API, REST "{method} {path}" or GraphQL "{type}.{field}"
+		Name string `json:"name"`
+		// Since deprecation date, zero for unknown
+		Since time.Time `json:"since,omitzero"`
+		// Sunset date when API will be removed, zero for unknown
+		Sunset time.Time `json:"sunset,omitzero"`
+		// Link documentation of migration
+		Link    string `json:"link,omitempty"`
+		Message string `json:"message,omitempty"`
+		// Enforce respond 410 Gone after sunset date (REST only)
+		Enforce bool `json:"-"`
+	}
+
+	// Usage usage statistic of deprecated API
+	Usage struct {
+		Kind       string    `json:"kind"`
+		Name       string    `json:"name"`
+		Count      int64     `json:"count"`
+		LastUsedAt time.Time `json:"last_used_at"`
+		LastClient string    `json:"last_client,omitempty"`
+	}
+)
+
+// Warning human readable warning message
+func (d *Deprecation) Warning() string {
+	msg := d.Name + " is deprecated"
+	if !d.Sunset.IsZero() {
+		msg += " and will be removed at " + d.Sunset.Format(time.DateOnly)
+	}
+	if d.Message != "" {
+		msg += ", " + d.Message
+	}
+	return msg
+}
+
+// SetHeader set Deprecation, Sunset, Link and Warning response header
+func (d *Deprecation) SetHeader(header http.Header) {
+	if d.Since.IsZero() {
+		header.Set(HeaderDeprecation, "true")
+	} else {
+		header.Set(HeaderDeprecation, "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		header.Set(HeaderSunset, d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add(HeaderLink, fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, d.Link))
+	}
+	header.Add(HeaderWarning, strconv.Quote(d.Warning()))
+}
+
+// Deprecate middleware for deprecated REST route, emit deprecation header and record usage. Name default to
+// "{method} {path}" of request
+func Deprecate(d Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			deprecation := d
+			if deprecation.Name == "" {
+				deprecation.Name = req.Method + " " + req.URL.Path
+			}
+			RecordUsage(req.Context(), KindREST, deprecation.Name, ClientName(req))
+			deprecation.SetHeader(rw.Header())
+			if deprecation.Enforce && !deprecation.Sunset.IsZero() && time.Now().After(deprecation.Sunset) {
+				wrapper.NewHTTPResponse(http.StatusGone, deprecation.Name+" has been removed at "+deprecation.Sunset.Format(time.DateOnly)).JSON(rw)
+				return
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+var usageTracker = struct {
+	once    sync.Once
+	mu      sync.Mutex
+	usages  map[string]*Usage
+	counter metric.Int64Counter
+}{usages: make(map[string]*Usage)}
+
+// RecordUsage record usage of deprecated API, exported as OpenTelemetry counter "candi.api.deprecated.usage"
+func RecordUsage(ctx context.Context, kind, name, client string) {
+	usageTracker.once.Do(func() {
+		usageTracker.counter, _ = otel.GetMeterProvider().Meter("github.com/golangid/candi/apiversion").Int64Counter(
+			"candi.api.deprecated.usage", metric.WithDescription("Number of deprecated REST route and GraphQL field usage"),
+		)
+	})
+	if usageTracker.counter != nil {
+		usageTracker.counter.Add(ctx, 1, metric.WithAttributes(attribute.String("kind", kind), attribute.String("name", name)))
+	}
+
+	usageTracker.mu.Lock()
+	defer usageTracker.mu.Unlock()
+	usage, ok := usageTracker.usages[kind+":"+name]
+	if !ok {
+		usage = &Usage{Kind: kind, Name: name}
+		usageTracker.usages[kind+":"+name] = usage
+	}
+	usage.Count++
+	usage.LastUsedAt = time.Now()
+	if client != "" {
+		usage.LastClient = client
+	}
+}
+
+// Usages usage statistic of all deprecated API since startup
+func Usages() []Usage {
+	usageTracker.mu.Lock()
+	defer usageTracker.mu.Unlock()
+	usages := make([]Usage, 0, len(usageTracker.usages))
+	for _, usage := range usageTracker.usages {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Count > usages[j].Count })
+	return usages
+}
+
+// UsageHTTPHandler admin http handler for get usage statistic of deprecated API
+func UsageHTTPHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		wrapper.NewHTTPResponse(http.StatusOK, "Deprecated API usage", Usages()).JSON(rw)
+	})
+}
+
+// ClientName client name of request from header "X-Client-Name" or user agent
+func ClientName(req *http.Request) string {
+	if client := req.Header.Get("X-Client-Name"); client != "" {
+		return client
+	}
+	return req.UserAgent()
+}
@@ -0,0 +1,88 @@
+package apiversion
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gqltypes "github.com/golangid/graphql-go/types"
+)
+
+// sunset date in @deprecated reason, example: @deprecated(reason: "use fullName, sunset 2025-06-30")
+var sunsetRegex = regexp.MustCompile(`(?i)sunset[:=\s]+(\d{4}-\d{2}-\d{2})`)
+
+// GraphQLDeprecations deprecated field of object and interface type in schema (field with @deprecated directive),
+// map key is "{type}.{field}"
+func GraphQLDeprecations(schema *gqltypes.Schema) map[string]Deprecation {
+	deprecations := make(map[string]Deprecation)
+	for typeName, namedType := range schema.Types {
+		if strings.HasPrefix(typeName, "__") {
+			continue
+		}
+		var fields gqltypes.FieldsDefinition
+		switch t := namedType.(type) {
+		case *gqltypes.ObjectTypeDefinition:
+			fields = t.Fields
+		case *gqltypes.InterfaceTypeDefinition:
+			fields = t.Fields
+		}
+		for _, field := range fields {
+			directive := field.Directives.Get("deprecated")
+			if directive == nil {
+				continue
+			}
+			deprecation := Deprecation{Name: typeName + "." + field.Name, Message: "No longer supported"}
+			if reason, ok := directive.Arguments.Get("reason"); ok {
+				deprecation.Message, _ = reason.Deserialize(nil).(string)
+			}
+			if match := sunsetRegex.FindStringSubmatch(deprecation.Message); len(match) > 1 {
+				deprecation.Sunset, _ = time.Parse(time.DateOnly, match[1])
+			}
+			deprecations[deprecation.Name] = deprecation
+		}
+	}
+	return deprecations
+}
+
+type collector struct {
+	mu           sync.Mutex
+	deprecations map[string]Deprecation
+}
+
+type collectorKey struct{}
+
+// WithCollector set collector of used deprecated field in request context
+func WithCollector(ctx context.Context) context.Context {
+	return context.WithValue(ctx, collectorKey{}, &collector{deprecations: make(map[string]Deprecation)})
+}
+
+// Collect add used deprecated field to collector in context, return false if context has no collector
+func Collect(ctx context.Context, deprecation Deprecation) bool {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	c.deprecations[deprecation.Name] = deprecation
+	c.mu.Unlock()
+	return true
+}
+
+// Collected used deprecated field collected in context, sorted by name
+func Collected(ctx context.Context) []Deprecation {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deprecations := make([]Deprecation, 0, len(c.deprecations))
+	for _, deprecation := range c.deprecations {
+		deprecations = append(deprecations, deprecation)
+	}
+	sort.Slice(deprecations, func(i, j int) bool { return deprecations[i].Name < deprecations[j].Name })
+	return deprecations
+}
@@ -0,0 +1,141 @@
+package apiversion
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/golangid/candi/wrapper"
+)
+
+// HeaderAPIVersion default request header of API version negotiation, resolved version is also set in response header
+const HeaderAPIVersion = "X-API-Version"
+
+type (
+	// OptionFunc type
+	OptionFunc func(*negotiator)
+
+	negotiator struct {
+		headerKey      string
+		defaultVersion string
+		pathPrefix     bool
+	}
+
+	contextKey struct{}
+)
+
+// vendor media type version, example "application/vnd.company.v2+json"
+var acceptVersionRegex = regexp.MustCompile(`\.v(\d+)\+`)
+
+// SetHeaderKey option func, request header of API version (default "X-API-Version")
+func SetHeaderKey(headerKey string) OptionFunc {
+	return func(n *negotiator) {
+		n.headerKey = headerKey
+	}
+}
+
+// SetDefaultVersion option func, version of request without version (default "1")
+func SetDefaultVersion(version string) OptionFunc {
+	return func(n *negotiator) {
+		n.defaultVersion = version
+	}
+}
+
+// SetPathPrefix option func, read version from path segment "/v{version}/" (default true)
+func SetPathPrefix(pathPrefix bool) OptionFunc {
+	return func(n *negotiator) {
+		n.pathPrefix = pathPrefix
+	}
+}
+
+// Middleware resolve API version of request from path prefix ("/v2/orders"), version header or vendor media type
+// in Accept header ("application/vnd.company.v2+json"), get resolved version with FromContext
+func Middleware(opts ...OptionFunc) func(http.Handler) http.Handler {
+	n := negotiator{headerKey: HeaderAPIVersion, defaultVersion: "1", pathPrefix: true}
+	for _, opt := range opts {
+		opt(&n)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			version := n.resolve(req)
+			rw.Header().Set(n.headerKey, version)
+			next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), contextKey{}, version)))
+		})
+	}
+}
+
+func (n *negotiator) resolve(req *http.Request) string {
+	if n.pathPrefix {
+		for _, segment := range strings.Split(req.URL.Path, "/") {
+			if len(segment) > 1 && segment[0] == 'v' && isDigits(segment[1:]) {
+				return segment[1:]
+			}
+		}
+	}
+	if version := strings.TrimPrefix(req.Header.Get(n.headerKey), "v"); version != "" {
+		return version
+	}
+	if match := acceptVersionRegex.FindStringSubmatch(req.Header.Get("Accept")); len(match) > 1 {
+		return match[1]
+	}
+	return n.defaultVersion
+}
+
+// FromContext get API version resolved by Middleware
+func FromContext(ctx context.Context) string {
+	version, _ := ctx.Value(contextKey{}).(string)
+	return version
+}
+
+// VersionedHandler dispatch request to handler of resolved API version (from Middleware), deprecated version
+// emit deprecation header
+type VersionedHandler struct {
+	defaultVersion string
+	handlers       map[string]http.Handler
+}
+
+// NewVersionedHandler create versioned handler, default version is used when request has no resolved version
+func NewVersionedHandler(defaultVersion string) *VersionedHandler {
+	return &VersionedHandler{defaultVersion: defaultVersion, handlers: make(map[string]http.Handler)}
+}
+
+// Version register handler of version, with optional deprecation
+func (v *VersionedHandler) Version(version string, handlerFunc http.HandlerFunc, deprecation ...Deprecation) *VersionedHandler {
+	var handler http.Handler = handlerFunc
+	if len(deprecation) > 0 {
+		handler = Deprecate(deprecation[0])(handler)
+	}
+	v.handlers[version] = handler
+	return v
+}
+
+// ServeHTTP implement http.Handler
+func (v *VersionedHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	version := FromContext(req.Context())
+	if version == "" {
+		version = v.defaultVersion
+	}
+	handler, ok := v.handlers[version]
+	if !ok {
+		versions := make([]string, 0, len(v.handlers))
+		for version := range v.handlers {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "Unsupported API version "+version+", supported versions: "+
+			strings.Join(versions, ", ")).JSON(rw)
+		return
+	}
+	handler.ServeHTTP(rw, req)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
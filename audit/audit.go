@@ -0,0 +1,235 @@
+// Package audit audit trail of who-did-what, record actor (from token claim), action, resource, before/after diff
+// (opt-in per handler) and outcome of REST, gRPC and GraphQL request to pluggable sink (SQL table, Kafka topic)
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"github.com/google/uuid"
+)
+
+const (
+	// OutcomeSuccess outcome of succeed action
+	OutcomeSuccess = "success"
+	// OutcomeFailure outcome of failed action
+	OutcomeFailure = "failure"
+)
+
+type (
+	// Entry audit trail entry
+	Entry struct {
+		ID         string            `json:"id"`
+		TenantID   string            `json:"tenant_id,omitempty"`
+		Actor      string            `json:"actor"`
+		Action     string            `json:"action"`
+		Resource   string            `json:"resource,omitempty"`
+		ResourceID string            `json:"resource_id,omitempty"`
+		Transport  string            `json:"transport"`
+		Outcome    string            `json:"outcome"`
+		Error      string            `json:"error,omitempty"`
+		Before     json.RawMessage   `json:"before,omitempty"`
+		After      json.RawMessage   `json:"after,omitempty"`
+		Diff       map[string]Change `json:"diff,omitempty"`
+		TraceID    string            `json:"trace_id,omitempty"`
+		RemoteAddr string            `json:"remote_addr,omitempty"`
+		CreatedAt  time.Time         `json:"created_at"`
+	}
+
+	// Change changed field value, field is JSON path (example "address.city")
+	Change struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+
+	// Sink destination of audit entry
+	Sink interface {
+		Record(ctx context.Context, entry *Entry) error
+	}
+
+	// Store queryable sink
+	Store interface {
+		Sink
+		Find(ctx context.Context, filter *Filter) (entries []Entry, count int, err error)
+	}
+
+	// Filter query audit trail, empty field is not filtered
+	Filter struct {
+		TenantID   string    `json:"tenant_id"`
+		Actor      string    `json:"actor"`
+		Action     string    `json:"action"`
+		Resource   string    `json:"resource"`
+		ResourceID string    `json:"resource_id"`
+		From       time.Time `json:"from"`
+		To         time.Time `json:"to"`
+		Page       int       `json:"page"`
+		Limit      int       `json:"limit"`
+	}
+
+	// Recorder record audit entry to sinks
+	Recorder struct {
+		sinks          []Sink
+		actorExtractor func(ctx context.Context) string
+		tokenValidator interfaces.TokenValidator
+	}
+
+	// OptionFunc option func of recorder
+	OptionFunc func(*Recorder)
+
+	entryContextKey struct{}
+
+	publisherSink struct {
+		publisher interfaces.Publisher
+		topic     string
+	}
+)
+
+// SetActorExtractor option func, default actor is subject of token claim in context
+func SetActorExtractor(extractor func(ctx context.Context) string) OptionFunc {
+	return func(r *Recorder) {
+		r.actorExtractor = extractor
+	}
+}
+
+// SetTokenValidator option func, validate bearer token of request for get actor when token claim is not in context
+// (example GraphQL request which authenticated by directive after audit middleware)
+func SetTokenValidator(tokenValidator interfaces.TokenValidator) OptionFunc {
+	return func(r *Recorder) {
+		r.tokenValidator = tokenValidator
+	}
+}
+
+// NewRecorder create audit recorder, entry is recorded to all sinks
+func NewRecorder(sinks []Sink, opts ...OptionFunc) *Recorder {
+	r := &Recorder{sinks: sinks, actorExtractor: candishared.ActorFromContext}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// NewPublisherSink sink publish audit entry as JSON message to topic (example Kafka publisher), message key is entry id
+func NewPublisherSink(publisher interfaces.Publisher, topic string) Sink {
+	return &publisherSink{publisher: publisher, topic: topic}
+}
+
+func (p *publisherSink) Record(ctx context.Context, entry *Entry) error {
+	return p.publisher.PublishMessage(ctx, &candishared.PublisherArgument{
+		Topic: p.topic, Key: entry.ID, Message: candihelper.ToBytes(entry),
+		ContentType: candihelper.HeaderMIMEApplicationJSON,
+	})
+}
+
+// SetResource set resource and resource id of audit entry in context (from handler)
+func SetResource(ctx context.Context, resource, resourceID string) {
+	if entry := entryFromContext(ctx); entry != nil {
+		if resource != "" {
+			entry.Resource = resource
+		}
+		entry.ResourceID = resourceID
+	}
+}
+
+// SetBefore set state of resource before changed (opt-in in handler), diff with after state is recorded
+func SetBefore(ctx context.Context, before any) {
+	if entry := entryFromContext(ctx); entry != nil {
+		entry.Before = candihelper.ToBytes(before)
+	}
+}
+
+// SetAfter set state of resource after changed (opt-in in handler)
+func SetAfter(ctx context.Context, after any) {
+	if entry := entryFromContext(ctx); entry != nil {
+		entry.After = candihelper.ToBytes(after)
+	}
+}
+
+func entryFromContext(ctx context.Context) *Entry {
+	entry, _ := ctx.Value(entryContextKey{}).(*Entry)
+	return entry
+}
+
+// begin set new entry to context for populated by handler
+func (r *Recorder) begin(ctx context.Context, transport, action, resource string) (context.Context, *Entry) {
+	entry := &Entry{Transport: transport, Action: action, Resource: resource}
+	return context.WithValue(ctx, entryContextKey{}, entry), entry
+}
+
+// Record fill actor, tenant, trace and outcome of entry and record to all sinks, error of sink is logged
+func (r *Recorder) Record(ctx context.Context, entry *Entry, err error) {
+	entry.ID = uuid.NewString()
+	entry.CreatedAt = time.Now()
+	if entry.Actor == "" {
+		entry.Actor = r.actorExtractor(ctx)
+	}
+	entry.TenantID = candishared.GetTenantFromContext(ctx)
+	entry.TraceID = tracer.GetTraceID(ctx)
+	entry.Outcome = OutcomeSuccess
+	if err != nil {
+		entry.Outcome, entry.Error = OutcomeFailure, err.Error()
+	}
+	if len(entry.Before) > 0 || len(entry.After) > 0 {
+		entry.Diff = Diff(entry.Before, entry.After)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, sink := range r.sinks {
+		if err := sink.Record(ctx, entry); err != nil {
+			logger.LogE(fmt.Sprintf("audit: record %s: %s", entry.Action, err.Error()))
+		}
+	}
+}
+
+// Diff changed fields between before and after JSON document, nested object is flatten with dot separated path
+func Diff(before, after []byte) map[string]Change {
+	beforeFields, afterFields := make(map[string]any), make(map[string]any)
+	var beforeValue, afterValue any
+	json.Unmarshal(before, &beforeValue)
+	json.Unmarshal(after, &afterValue)
+	flatten("", beforeValue, beforeFields)
+	flatten("", afterValue, afterFields)
+
+	keys := make([]string, 0, len(beforeFields)+len(afterFields))
+	for k := range beforeFields {
+		keys = append(keys, k)
+	}
+	for k := range afterFields {
+		if _, ok := beforeFields[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	diff := make(map[string]Change)
+	for _, k := range keys {
+		if !reflect.DeepEqual(beforeFields[k], afterFields[k]) {
+			diff[k] = Change{Before: beforeFields[k], After: afterFields[k]}
+		}
+	}
+	return diff
+}
+
+func flatten(prefix string, value any, fields map[string]any) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		if prefix != "" || value != nil {
+			fields[prefix] = value
+		}
+		return
+	}
+	for k, v := range object {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		flatten(k, v, fields)
+	}
+}
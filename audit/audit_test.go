@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestDiff(t *testing.T) {
+	diff := Diff([]byte(`{"name":"a","address":{"city":"x","zip":"1"},"tags":["a"]}`),
+		[]byte(`{"name":"b","address":{"city":"x","zip":"2"},"tags":["a"],"phone":"123"}`))
+	assert.Equal(t, map[string]Change{
+		"name":        {Before: "a", After: "b"},
+		"address.zip": {Before: "1", After: "2"},
+		"phone":       {Before: nil, After: "123"},
+	}, diff)
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	store := NewMemoryStore()
+	recorder := NewRecorder([]Sink{store})
+
+	handler := recorder.HTTPMiddleware("update_order", "order")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		SetResource(req.Context(), "", "order-1")
+		SetBefore(req.Context(), map[string]any{"status": "new"})
+		SetAfter(req.Context(), map[string]any{"status": "paid"})
+		if req.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	ctx := candishared.SetToContext(context.Background(), candishared.ContextKeyTokenClaim, &candishared.TokenClaim{
+		StandardClaims: jwt.StandardClaims{Subject: "user-1"},
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/orders/1", nil).WithContext(ctx))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/orders/1", nil))
+
+	entries, count, err := store.Find(context.Background(), &Filter{Resource: "order"})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	assert.Equal(t, OutcomeFailure, entries[0].Outcome)
+	assert.Equal(t, "", entries[0].Actor)
+	assert.Equal(t, "user-1", entries[1].Actor)
+	assert.Equal(t, OutcomeSuccess, entries[1].Outcome)
+	assert.Equal(t, "order-1", entries[1].ResourceID)
+	assert.Equal(t, Change{Before: "new", After: "paid"}, entries[1].Diff["status"])
+
+	rec := httptest.NewRecorder()
+	HTTPHandler(store).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?actor=user-1", nil))
+	assert.Contains(t, rec.Body.String(), `"totalRecords":1`)
+	assert.Contains(t, rec.Body.String(), `"action":"update_order"`)
+}
+
+func TestGraphQLMiddleware(t *testing.T) {
+	store := NewMemoryStore()
+	handler := NewRecorder([]Sink{store}).GraphQLMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"errors":[{"message":"order not found"}]}`))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql",
+		strings.NewReader(`{"query":"query { orders { id } }"}`)))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql",
+		strings.NewReader(`{"operationName":"CancelOrder","query":"mutation CancelOrder { cancelOrder(id: 1) }"}`)))
+
+	entries, count, _ := store.Find(context.Background(), &Filter{})
+	require.Equal(t, 1, count)
+	assert.Equal(t, "CancelOrder", entries[0].Action)
+	assert.Equal(t, TransportGraphQL, entries[0].Transport)
+	assert.Equal(t, "order not found", entries[0].Error)
+}
+
+func TestGRPCUnaryInterceptor(t *testing.T) {
+	store := NewMemoryStore()
+	interceptor := NewRecorder([]Sink{store}, SetActorExtractor(func(ctx context.Context) string { return "service-a" })).
+		GRPCUnaryInterceptor()
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/CancelOrder"},
+		func(ctx context.Context, req any) (any, error) {
+			SetResource(ctx, "order", "order-1")
+			return nil, errors.New("already paid")
+		})
+	assert.Error(t, err)
+
+	entries, _, _ := store.Find(context.Background(), &Filter{ResourceID: "order-1"})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "service-a", entries[0].Actor)
+	assert.Equal(t, "/order.OrderService/CancelOrder", entries[0].Action)
+	assert.Equal(t, "already paid", entries[0].Error)
+}
@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/wrapper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// TransportREST transport of REST request
+	TransportREST = "rest"
+	// TransportGRPC transport of gRPC request
+	TransportGRPC = "grpc"
+	// TransportGraphQL transport of GraphQL request
+	TransportGraphQL = "graphql"
+)
+
+type auditResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       *bytes.Buffer
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	if w.statusCode == 0 {
+		w.statusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	if w.body != nil {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// HTTPMiddleware record audit entry of REST handler, place after auth middleware for get actor from token claim.
+// Request with response status code >= 400 is recorded as failure
+func (r *Recorder) HTTPMiddleware(action, resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, entry := r.begin(req.Context(), TransportREST, action, resource)
+			entry.RemoteAddr = remoteAddr(req)
+			entry.Actor = r.httpActor(ctx, req)
+
+			aw := &auditResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(aw, req.WithContext(ctx))
+
+			var err error
+			if aw.statusCode >= http.StatusBadRequest {
+				err = fmt.Errorf("%d %s", aw.statusCode, http.StatusText(aw.statusCode))
+			}
+			r.Record(ctx, entry, err)
+		})
+	}
+}
+
+// GraphQLMiddleware record audit entry of GraphQL mutation (query is not recorded), action is operation name of
+// mutation. Response with errors is recorded as failure
+func (r *Recorder) GraphQLMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, _ := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		var payload struct {
+			OperationName string `json:"operationName"`
+			Query         string `json:"query"`
+		}
+		json.Unmarshal(body, &payload)
+		if !strings.HasPrefix(strings.TrimSpace(payload.Query), "mutation") {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		ctx, entry := r.begin(req.Context(), TransportGraphQL, cmp.Or(payload.OperationName, "mutation"), "")
+		entry.RemoteAddr = remoteAddr(req)
+		entry.Actor = r.httpActor(ctx, req)
+
+		aw := &auditResponseWriter{ResponseWriter: w, body: &bytes.Buffer{}}
+		next.ServeHTTP(aw, req.WithContext(ctx))
+
+		var response struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(aw.body.Bytes(), &response)
+		var err error
+		if len(response.Errors) > 0 {
+			err = fmt.Errorf("%s", response.Errors[0].Message)
+		}
+		r.Record(ctx, entry, err)
+	})
+}
+
+// GRPCUnaryInterceptor record audit entry of gRPC unary method, action is full method name
+// (register with grpc server option AddUnaryInterceptors)
+func (r *Recorder) GRPCUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		ctx, entry := r.begin(ctx, TransportGRPC, info.FullMethod, "")
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			entry.RemoteAddr = p.Addr.String()
+		}
+
+		resp, err = handler(ctx, req)
+		r.Record(ctx, entry, err)
+		return resp, err
+	}
+}
+
+// HTTPHandler query audit trail, filter from query param (actor, action, resource, resource_id, from, to in RFC3339,
+// page, limit). Tenant of context is always applied
+func HTTPHandler(store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		filter := &Filter{
+			TenantID: candishared.GetTenantFromContext(req.Context()),
+			Actor:    query.Get("actor"), Action: query.Get("action"),
+			Resource: query.Get("resource"), ResourceID: query.Get("resource_id"),
+			Page: atoi(query.Get("page")), Limit: atoi(query.Get("limit")),
+		}
+		filter.From, _ = time.Parse(time.RFC3339, query.Get("from"))
+		filter.To, _ = time.Parse(time.RFC3339, query.Get("to"))
+		filter.normalize()
+
+		entries, count, err := store.Find(req.Context(), filter)
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusInternalServerError, "Failed query audit trail", err).JSON(w)
+			return
+		}
+		wrapper.NewHTTPResponseWithMeta(http.StatusOK, "Success", candishared.NewMeta(filter.Page, filter.Limit, count), entries).JSON(w)
+	})
+}
+
+func (r *Recorder) httpActor(ctx context.Context, req *http.Request) string {
+	if actor := r.actorExtractor(ctx); actor != "" || r.tokenValidator == nil {
+		return actor
+	}
+	authType, token, _ := strings.Cut(req.Header.Get(candihelper.HeaderAuthorization), " ")
+	if !strings.EqualFold(authType, "Bearer") || token == "" {
+		return ""
+	}
+	if claim, err := r.tokenValidator.ValidateToken(ctx, token); err == nil && claim != nil {
+		return claim.Subject
+	}
+	return ""
+}
+
+func remoteAddr(req *http.Request) string {
+	if forwarded := req.Header.Get(candihelper.HeaderXForwardedFor); forwarded != "" {
+		addr, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(addr)
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
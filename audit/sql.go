@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+)
+
+type sqlStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(argIndex int) string
+}
+
+// NewSQLStore store audit entry in SQL table (created if not exist), support postgres and mysql
+func NewSQLStore(ctx context.Context, db *sql.DB, table string) (Store, error) {
+	driver := fmt.Sprintf("%T", db.Driver())
+	s := &sqlStore{db: db, table: table, placeholder: candishared.SQLPlaceholderOf(db)}
+	textType, timeType := "TEXT", "TIMESTAMP"
+	if !strings.Contains(driver, "pq.") && !strings.Contains(driver, "stdlib.") {
+		timeType = "DATETIME(6)"
+	}
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+		id VARCHAR(36) PRIMARY KEY,
+		tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+		actor VARCHAR(255) NOT NULL DEFAULT '',
+		action VARCHAR(255) NOT NULL,
+		resource VARCHAR(255) NOT NULL DEFAULT '',
+		resource_id VARCHAR(255) NOT NULL DEFAULT '',
+		transport VARCHAR(16) NOT NULL,
+		outcome VARCHAR(16) NOT NULL,
+		error_message `+textType+`,
+		before_data `+textType+`,
+		after_data `+textType+`,
+		diff `+textType+`,
+		trace_id VARCHAR(64) NOT NULL DEFAULT '',
+		remote_addr VARCHAR(64) NOT NULL DEFAULT '',
+		created_at `+timeType+` NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("audit: create table %s: %w", table, err)
+	}
+	return s, nil
+}
+
+func (s *sqlStore) Record(ctx context.Context, entry *Entry) error {
+	diff, _ := json.Marshal(entry.Diff)
+	_, err := s.db.ExecContext(ctx, candishared.SQLRebind(`INSERT INTO `+s.table+` (id, tenant_id, actor, action, resource, resource_id,
+		transport, outcome, error_message, before_data, after_data, diff, trace_id, remote_addr, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, s.placeholder),
+		entry.ID, entry.TenantID, entry.Actor, entry.Action, entry.Resource, entry.ResourceID,
+		entry.Transport, entry.Outcome, entry.Error, string(entry.Before), string(entry.After), string(diff),
+		entry.TraceID, entry.RemoteAddr, entry.CreatedAt.UTC(),
+	)
+	return err
+}
+
+func (s *sqlStore) Find(ctx context.Context, filter *Filter) (entries []Entry, count int, err error) {
+	filter.normalize()
+	var conditions []string
+	var args []any
+	for _, field := range [][2]string{
+		{"tenant_id", filter.TenantID}, {"actor", filter.Actor}, {"action", filter.Action},
+		{"resource", filter.Resource}, {"resource_id", filter.ResourceID},
+	} {
+		if field[1] != "" {
+			conditions = append(conditions, field[0]+" = ?")
+			args = append(args, field[1])
+		}
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filter.To.UTC())
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if err := s.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT COUNT(*) FROM `+s.table+where, s.placeholder), args...).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, candishared.SQLRebind(`SELECT id, tenant_id, actor, action, resource, resource_id, transport,
+		outcome, error_message, before_data, after_data, diff, trace_id, remote_addr, created_at FROM `+s.table+where+
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d OFFSET %d", filter.Limit, (filter.Page-1)*filter.Limit), s.placeholder), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry Entry
+		var errMessage, before, after, diff sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.TenantID, &entry.Actor, &entry.Action, &entry.Resource, &entry.ResourceID,
+			&entry.Transport, &entry.Outcome, &errMessage, &before, &after, &diff, &entry.TraceID, &entry.RemoteAddr,
+			&entry.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entry.Error = errMessage.String
+		if before.String != "" {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.String != "" {
+			entry.After = json.RawMessage(after.String)
+		}
+		json.Unmarshal([]byte(diff.String), &entry.Diff)
+		entries = append(entries, entry)
+	}
+	return entries, count, rows.Err()
+}
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// NewMemoryStore in memory store, for development and testing
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+func (m *memoryStore) Record(ctx context.Context, entry *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *memoryStore) Find(ctx context.Context, filter *Filter) (entries []Entry, count int, err error) {
+	filter.normalize()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if entry := m.entries[i]; filter.match(&entry) {
+			entries = append(entries, entry)
+		}
+	}
+	count = len(entries)
+	offset := min((filter.Page-1)*filter.Limit, count)
+	return entries[offset:min(offset+filter.Limit, count)], count, nil
+}
+
+func (f *Filter) normalize() {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 {
+		f.Limit = 10
+	}
+}
+
+func (f *Filter) match(entry *Entry) bool {
+	return (f.TenantID == "" || f.TenantID == entry.TenantID) && (f.Actor == "" || f.Actor == entry.Actor) &&
+		(f.Action == "" || f.Action == entry.Action) && (f.Resource == "" || f.Resource == entry.Resource) &&
+		(f.ResourceID == "" || f.ResourceID == entry.ResourceID) &&
+		(f.From.IsZero() || !entry.CreatedAt.Before(f.From)) && (f.To.IsZero() || !entry.CreatedAt.After(f.To))
+}
@@ -0,0 +1,239 @@
+// Package backfill long-running reindex/backfill job, iterate table/collection in keyed batches, process batch with
+// user callback, persist checkpoint (candiutils.Checkpoint) so job resume after restart, throttle by rate and report
+// progress (to task queue worker dashboard when run as task queue job)
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/logger"
+)
+
+type (
+	// Source keyed batch iterator of table/collection
+	Source[T any] interface {
+		// Count items with key greater than afterKey (empty for all items) for progress, return 0 if unknown
+		Count(ctx context.Context, afterKey string) (int64, error)
+		// Fetch next batch with key greater than afterKey (empty for first batch) ordered by key, return key of last item
+		Fetch(ctx context.Context, afterKey string, limit int) (batch []T, lastKey string, err error)
+	}
+
+	// ProcessFunc process batch of items
+	ProcessFunc[T any] func(ctx context.Context, batch []T) error
+
+	// Progress progress of backfill job
+	Progress struct {
+		Name      string `json:"name"`
+		LastKey   string `json:"last_key"`
+		Processed int64  `json:"processed"`
+		Total     int64  `json:"total"`
+		Done      bool   `json:"done"`
+	}
+
+	// Job backfill job
+	Job[T any] struct {
+		name    string
+		source  Source[T]
+		process ProcessFunc[T]
+		opt     option
+	}
+
+	option struct {
+		batchSize    int
+		rate         float64
+		checkpoint   candiutils.Checkpoint
+		progressFunc func(ctx context.Context, progress Progress)
+	}
+
+	// OptionFunc option func of backfill job
+	OptionFunc func(*option)
+
+	batch[T any] struct {
+		items   []T
+		lastKey string
+	}
+)
+
+// SetBatchSize option func, default 100
+func SetBatchSize(batchSize int) OptionFunc {
+	return func(o *option) {
+		o.batchSize = batchSize
+	}
+}
+
+// SetRate option func, throttle processed items per second, default unlimited
+func SetRate(itemsPerSecond float64) OptionFunc {
+	return func(o *option) {
+		o.rate = itemsPerSecond
+	}
+}
+
+// SetCheckpoint option func, storage of last processed key (example: candiutils.NewRedisCheckpoint,
+// candiutils.NewSQLCheckpoint), default in memory checkpoint (not resumable after restart)
+func SetCheckpoint(checkpoint candiutils.Checkpoint) OptionFunc {
+	return func(o *option) {
+		o.checkpoint = checkpoint
+	}
+}
+
+// SetProgressFunc option func, called after each batch processed
+func SetProgressFunc(progressFunc func(ctx context.Context, progress Progress)) OptionFunc {
+	return func(o *option) {
+		o.progressFunc = progressFunc
+	}
+}
+
+// New create backfill job, name is checkpoint name
+func New[T any](name string, source Source[T], process ProcessFunc[T], opts ...OptionFunc) *Job[T] {
+	j := &Job[T]{name: name, source: source, process: process, opt: option{batchSize: 100}}
+	for _, opt := range opts {
+		opt(&j.opt)
+	}
+	if j.opt.checkpoint == nil {
+		j.opt.checkpoint = candiutils.NewMemoryCheckpoint()
+	}
+	return j
+}
+
+// Run backfill from last checkpoint until all items processed with candiutils.MapReduce, checkpoint is saved after
+// each processed batch. Checkpoint is kept when finished, so next Run only process item added after last key until Reset
+func (j *Job[T]) Run(ctx context.Context) error {
+	return j.run(ctx, j.opt.progressFunc)
+}
+
+func (j *Job[T]) run(ctx context.Context, progressFunc func(context.Context, Progress)) error {
+	lastKey, err := j.opt.checkpoint.Load(ctx, j.name)
+	if err != nil {
+		return fmt.Errorf("backfill %s: load checkpoint: %w", j.name, err)
+	}
+	progress := Progress{Name: j.name, LastKey: lastKey}
+	if total, err := j.source.Count(ctx, ""); err == nil && total > 0 {
+		progress.Total = total
+		if remaining, err := j.source.Count(ctx, lastKey); err == nil {
+			progress.Processed = max(total-remaining, 0)
+		}
+	}
+
+	var cursor *batchCursor[T]
+	result, err := candiutils.MapReduce(ctx, candiutils.MapReduceConfig[batch[T]]{
+		Workers: 1, Name: j.name, Checkpoint: j.opt.checkpoint, CheckpointEvery: 1, StopOnError: true,
+		Key: func(b batch[T]) string { return b.lastKey },
+	}, func(ctx context.Context, checkpoint string) (candiutils.Cursor[batch[T]], error) {
+		cursor = &batchCursor[T]{job: j, afterKey: checkpoint}
+		return cursor, nil
+	}, func(ctx context.Context, b batch[T]) (batch[T], error) {
+		return b, j.process(ctx, b.items)
+	}, func(b batch[T]) {
+		progress.LastKey = b.lastKey
+		progress.Processed += int64(len(b.items))
+		progress.Total = max(progress.Total, progress.Processed)
+		if progressFunc != nil {
+			progressFunc(ctx, progress)
+		}
+	})
+	switch {
+	case err != nil && result.Failed > 0:
+		return fmt.Errorf("backfill %s: process batch after key %q: %w", j.name, result.Checkpoint, errors.Unwrap(err))
+	case cursor != nil && cursor.err != nil && ctx.Err() == nil:
+		return fmt.Errorf("backfill %s: fetch after key %q: %w", j.name, cursor.afterKey, cursor.err)
+	case err != nil:
+		return fmt.Errorf("backfill %s: %w", j.name, err)
+	}
+
+	progress.Done = true
+	if progressFunc != nil {
+		progressFunc(ctx, progress)
+	}
+	return nil
+}
+
+// Reset remove checkpoint of job, next Run start from beginning
+func (j *Job[T]) Reset(ctx context.Context) error {
+	return j.opt.checkpoint.Save(ctx, j.name, "")
+}
+
+// Checkpoint get key of last processed item, empty if job is not started
+func (j *Job[T]) Checkpoint(ctx context.Context) (string, error) {
+	return j.opt.checkpoint.Load(ctx, j.name)
+}
+
+// TaskHandler run backfill as task queue worker job, progress is reported to task queue dashboard and job retry resume
+// from last checkpoint. Usage: group.Add("backfill-user-index", job.TaskHandler())
+func (j *Job[T]) TaskHandler() types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		jobID := eventContext.Key()
+		return j.run(eventContext.Context(), func(ctx context.Context, progress Progress) {
+			if j.opt.progressFunc != nil {
+				j.opt.progressFunc(ctx, progress)
+			}
+			if err := taskqueueworker.UpdateProgressJob(ctx, jobID, progress.Processed, progress.Total); err != nil {
+				logger.LogE(fmt.Sprintf("backfill %s: update progress job %s: %s", j.name, jobID, err.Error()))
+			}
+		})
+	}
+}
+
+// batchCursor candiutils.Cursor of source batch, fetch is throttled by job rate
+type batchCursor[T any] struct {
+	job      *Job[T]
+	afterKey string
+	current  batch[T]
+	fetched  int64
+	start    time.Time
+	done     bool
+	err      error
+}
+
+func (c *batchCursor[T]) Next(ctx context.Context) bool {
+	if c.done {
+		return false
+	}
+	if c.start.IsZero() {
+		c.start = time.Now()
+	}
+	if c.err = c.throttle(ctx); c.err != nil {
+		return false
+	}
+	items, lastKey, err := c.job.source.Fetch(ctx, c.afterKey, c.job.opt.batchSize)
+	if err != nil {
+		c.err = err
+		return false
+	}
+	if len(items) == 0 {
+		return false
+	}
+	c.done = len(items) < c.job.opt.batchSize
+	c.current, c.afterKey = batch[T]{items: items, lastKey: lastKey}, lastKey
+	c.fetched += int64(len(items))
+	return true
+}
+
+func (c *batchCursor[T]) Decode() (batch[T], error) { return c.current, nil }
+func (c *batchCursor[T]) Err() error                { return c.err }
+func (c *batchCursor[T]) Close() error              { return nil }
+
+// throttle wait until fetched items within rate
+func (c *batchCursor[T]) throttle(ctx context.Context) error {
+	if c.job.opt.rate <= 0 {
+		return nil
+	}
+	wait := time.Duration(float64(c.fetched)/c.job.opt.rate*float64(time.Second)) - time.Since(c.start)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobResumeFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	items := make(SliceSource[int], 25)
+	for i := range items {
+		items[i] = i
+	}
+
+	var processed []int
+	failAt := 12
+	var progress []int64
+	checkpoint := candiutils.NewMemoryCheckpoint()
+	job := New("reindex-number", items, func(ctx context.Context, batch []int) error {
+		for _, item := range batch {
+			if item == failAt {
+				return errors.New("database unavailable")
+			}
+		}
+		processed = append(processed, batch...)
+		return nil
+	}, SetBatchSize(5), SetCheckpoint(checkpoint), SetProgressFunc(func(ctx context.Context, p Progress) {
+		progress = append(progress, p.Processed)
+	}))
+
+	err := job.Run(ctx)
+	assert.ErrorContains(t, err, `after key "9"`)
+	lastKey, _ := job.Checkpoint(ctx)
+	assert.Equal(t, "9", lastKey, "failed batch is not checkpointed")
+
+	failAt = -1
+	require.NoError(t, job.Run(ctx))
+	assert.Len(t, processed, 25)
+	assert.Equal(t, []int{10, 11, 12, 13, 14}, processed[10:15])
+	assert.Equal(t, []int64{5, 10, 15, 20, 25, 25}, progress)
+
+	require.NoError(t, job.Run(ctx))
+	assert.Len(t, processed, 25, "finished job resume after last key")
+	lastKey, _ = checkpoint.Load(ctx, "reindex-number")
+	assert.Equal(t, "24", lastKey)
+
+	require.NoError(t, job.Reset(ctx))
+	require.NoError(t, job.Run(ctx))
+	assert.Len(t, processed, 50)
+}
+
+func TestJobRate(t *testing.T) {
+	job := New("throttled", make(SliceSource[int], 30), func(ctx context.Context, batch []int) error { return nil },
+		SetBatchSize(10), SetRate(200))
+	start := time.Now()
+	require.NoError(t, job.Run(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
@@ -0,0 +1,172 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golangid/candi/candishared"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// SQLSource iterate sql table ordered by key column, row is map of column name to value
+	SQLSource struct {
+		DB        *sql.DB
+		Table     string
+		KeyColumn string
+		// Columns selected columns, default all columns
+		Columns []string
+		// Where additional condition (without WHERE keyword) with args
+		Where     string
+		WhereArgs []any
+	}
+
+	// MongoSource iterate mongo collection ordered by _id
+	MongoSource struct {
+		Collection *mongo.Collection
+		Filter     bson.M
+	}
+
+	// SliceSource iterate in memory slice, key is index
+	SliceSource[T any] []T
+)
+
+// Count implement Source
+func (s *SQLSource) Count(ctx context.Context, afterKey string) (total int64, err error) {
+	where, args := s.where(afterKey)
+	err = s.DB.QueryRowContext(ctx, candishared.SQLRebind(`SELECT COUNT(*) FROM `+s.Table+where, s.placeholder()), args...).Scan(&total)
+	return total, err
+}
+
+// Fetch implement Source
+func (s *SQLSource) Fetch(ctx context.Context, afterKey string, limit int) (batch []map[string]any, lastKey string, err error) {
+	columns := "*"
+	if len(s.Columns) > 0 {
+		columns = strings.Join(s.Columns, ", ")
+	}
+	where, args := s.where(afterKey)
+	rows, err := s.DB.QueryContext(ctx, candishared.SQLRebind(`SELECT `+columns+` FROM `+s.Table+where+
+		` ORDER BY `+s.KeyColumn+` ASC LIMIT `+strconv.Itoa(limit), s.placeholder()), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return nil, "", err
+	}
+	for rows.Next() {
+		values := make([]any, len(columnNames))
+		pointers := make([]any, len(columnNames))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, "", err
+		}
+		row := make(map[string]any, len(columnNames))
+		for i, column := range columnNames {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		lastKey = fmt.Sprint(row[s.KeyColumn])
+		batch = append(batch, row)
+	}
+	return batch, lastKey, rows.Err()
+}
+
+func (s *SQLSource) where(afterKey string) (string, []any) {
+	var conditions []string
+	var args []any
+	if s.Where != "" {
+		conditions = append(conditions, "("+s.Where+")")
+		args = append(args, s.WhereArgs...)
+	}
+	if afterKey != "" {
+		conditions = append(conditions, s.KeyColumn+" > ?")
+		args = append(args, afterKey)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (s *SQLSource) placeholder() func(int) string {
+	return candishared.SQLPlaceholderOf(s.DB)
+}
+
+// Count implement Source
+func (m *MongoSource) Count(ctx context.Context, afterKey string) (int64, error) {
+	return m.Collection.CountDocuments(ctx, m.filter(afterKey))
+}
+
+// Fetch implement Source, key of ObjectID is hex string
+func (m *MongoSource) Fetch(ctx context.Context, afterKey string, limit int) (batch []bson.M, lastKey string, err error) {
+	cursor, err := m.Collection.Find(ctx, m.filter(afterKey),
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := cursor.All(ctx, &batch); err != nil {
+		return nil, "", err
+	}
+	if len(batch) > 0 {
+		switch id := batch[len(batch)-1]["_id"].(type) {
+		case primitive.ObjectID:
+			lastKey = id.Hex()
+		default:
+			lastKey = fmt.Sprint(id)
+		}
+	}
+	return batch, lastKey, nil
+}
+
+func (m *MongoSource) filter(afterKey string) bson.M {
+	filter := bson.M{}
+	for k, v := range m.Filter {
+		filter[k] = v
+	}
+	if afterKey != "" {
+		var key any = afterKey
+		if id, err := primitive.ObjectIDFromHex(afterKey); err == nil {
+			key = id
+		}
+		filter["_id"] = bson.M{"$gt": key}
+	}
+	return filter
+}
+
+// Count implement Source
+func (s SliceSource[T]) Count(ctx context.Context, afterKey string) (int64, error) {
+	start, err := s.start(afterKey)
+	return int64(max(len(s)-start, 0)), err
+}
+
+// Fetch implement Source
+func (s SliceSource[T]) Fetch(ctx context.Context, afterKey string, limit int) (batch []T, lastKey string, err error) {
+	start, err := s.start(afterKey)
+	if err != nil || start >= len(s) {
+		return nil, "", err
+	}
+	end := min(start+limit, len(s))
+	return s[start:end], strconv.Itoa(end - 1), nil
+}
+
+func (s SliceSource[T]) start(afterKey string) (int, error) {
+	if afterKey == "" {
+		return 0, nil
+	}
+	index, err := strconv.Atoi(afterKey)
+	return index + 1, err
+}
@@ -0,0 +1,179 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/tracer"
+	"github.com/google/uuid"
+)
+
+// ErrNoPublishRoute event type not match any publish route
+var ErrNoPublishRoute = errors.New("no publish route match the event type")
+
+const (
+	// HeaderEventID header of event id in published message
+	HeaderEventID = "X-Event-ID"
+	// HeaderEventType header of event type in published message
+	HeaderEventType = "X-Event-Type"
+)
+
+type (
+	// Event domain event to publish with EventPublisher
+	Event struct {
+		// ID default is generated uuid
+		ID   string
+		Type string
+		// Key message key (kafka partition key, redis broker key), default is event ID
+		Key string
+		// OccurredAt default is time when published
+		OccurredAt time.Time
+		Header     map[string]any
+		Data       any
+	}
+
+	// EventEnvelope standard message body of event published by EventPublisher
+	EventEnvelope struct {
+		ID           string            `json:"id"`
+		Type         string            `json:"type"`
+		Source       string            `json:"source,omitempty"`
+		OccurredAt   time.Time         `json:"occurred_at"`
+		TraceContext map[string]string `json:"trace_context,omitempty"`
+		Data         json.RawMessage   `json:"data"`
+	}
+
+	// PublishRoute rule of routing event type to broker and topic
+	PublishRoute struct {
+		// EventType exact event type, prefix pattern with "*" suffix (example: "order.*"), or "*" for all event
+		EventType string
+		Broker    types.Worker
+		// Topic kafka topic, rabbitmq routing key (in broker exchange) or redis broker topic, default is event type
+		Topic string
+		// Delay publish delay, required for redis broker
+		Delay time.Duration
+	}
+
+	// EventPublisher broker agnostic publisher, route event to one or more brokers by publish route
+	EventPublisher struct {
+		brokers *Broker
+		source  string
+		routes  []PublishRoute
+	}
+)
+
+/*
+NewEventPublisher create broker agnostic publisher with routing rules, source is name of service publishing the event.
+Event matching multiple routes is published to all matched routes. Example:
+
+	publisher, err := broker.NewEventPublisher(brokerDeps, "order-service",
+		broker.PublishRoute{EventType: "order.*", Broker: types.Kafka, Topic: "order-events"},
+		broker.PublishRoute{EventType: "order.expired", Broker: types.RedisSubscriber, Delay: 30 * time.Minute},
+		broker.PublishRoute{EventType: "*", Broker: types.RabbitMQ},
+	)
+	...
+	err = publisher.Publish(ctx, &broker.Event{Type: "order.created", Key: order.ID, Data: order})
+*/
+func NewEventPublisher(brokers *Broker, source string, routes ...PublishRoute) (*EventPublisher, error) {
+	for _, route := range routes {
+		if route.EventType == "" {
+			return nil, errors.New("publish route: event type cannot empty")
+		}
+		if _, ok := brokers.GetBrokers()[route.Broker]; !ok {
+			return nil, fmt.Errorf("publish route %q: broker %s is not registered", route.EventType, route.Broker)
+		}
+	}
+	return &EventPublisher{brokers: brokers, source: source, routes: routes}, nil
+}
+
+// Routes get publish routes matched with event type
+func (p *EventPublisher) Routes(eventType string) (routes []PublishRoute) {
+	for _, route := range p.routes {
+		if matchEventType(route.EventType, eventType) {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// Publish wrap event in standard envelope and publish to all matched routes
+func (p *EventPublisher) Publish(ctx context.Context, event *Event) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "EventPublisher:Publish")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	if event.Type == "" {
+		return candierrors.Permanent(errors.New("event type cannot empty"))
+	}
+	routes := p.Routes(event.Type)
+	if len(routes) == 0 {
+		return candierrors.Permanent(fmt.Errorf("%w: %s", ErrNoPublishRoute, event.Type))
+	}
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.Key == "" {
+		event.Key = event.ID
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	trace.SetTag("event_id", event.ID)
+	trace.SetTag("event_type", event.Type)
+
+	message, err := p.envelope(trace, event)
+	if err != nil {
+		return candierrors.Permanent(err)
+	}
+
+	mErr := candihelper.NewMultiError()
+	for _, route := range routes {
+		header := map[string]any{HeaderEventID: event.ID, HeaderEventType: event.Type}
+		for k, v := range event.Header {
+			header[k] = v
+		}
+		topic := route.Topic
+		if topic == "" {
+			topic = event.Type
+		}
+		mErr.Append(string(route.Broker)+":"+topic, p.brokers.GetBrokers()[route.Broker].GetPublisher().PublishMessage(ctx, &candishared.PublisherArgument{
+			Topic:       topic,
+			Key:         event.Key,
+			Header:      header,
+			ContentType: candihelper.HeaderMIMEApplicationJSON,
+			Message:     message,
+			Delay:       route.Delay,
+			Timestamp:   event.OccurredAt,
+		}))
+	}
+	if mErr.HasError() {
+		return mErr
+	}
+	return nil
+}
+
+func (p *EventPublisher) envelope(trace tracer.Tracer, event *Event) ([]byte, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event data: %w", err)
+	}
+	envelope := EventEnvelope{
+		ID: event.ID, Type: event.Type, Source: p.source, OccurredAt: event.OccurredAt, Data: data,
+		TraceContext: make(map[string]string),
+	}
+	trace.InjectRequestHeader(envelope.TraceContext)
+	return json.Marshal(envelope)
+}
+
+func matchEventType(pattern, eventType string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(eventType, prefix)
+	}
+	return pattern == eventType
+}
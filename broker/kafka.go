@@ -4,15 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/codebase/interfaces"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/deadline"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
 )
@@ -188,6 +191,19 @@ func (p *kafkaPublisher) PublishMessage(ctx context.Context, args *candishared.P
 		trace.Finish(tracer.FinishWithError(err))
 	}()
 
+	if err := args.ApplyCodec(); err != nil {
+		return candierrors.Permanent(err)
+	}
+	if err := args.ApplyCloudEvent(candishared.CloudEventsKafkaHeaderPrefix); err != nil {
+		return candierrors.Permanent(err)
+	}
+	if args.Header == nil {
+		args.Header = make(map[string]any)
+	}
+	deadline.InjectMessageHeader(ctx, args.Header)
+	candishared.InjectBaggageMessageHeader(ctx, args.Header)
+	candishared.InjectRequestIDMessageHeader(ctx, args.Header)
+
 	var payload []byte
 	if len(args.Message) > 0 {
 		payload = args.Message
@@ -210,6 +226,15 @@ func (p *kafkaPublisher) PublishMessage(ctx context.Context, args *candishared.P
 	if !args.Timestamp.IsZero() {
 		msg.Timestamp = args.Timestamp
 	}
+	if delay := args.GetDelay(); delay > 0 {
+		// scheduled message is published to delay topic and relayed to target topic by KafkaDelayRelayer
+		msg.Topic = KafkaDelayTopic
+		msg.Headers = append(msg.Headers,
+			sarama.RecordHeader{Key: []byte(KafkaHeaderDeliverAt), Value: []byte(strconv.FormatInt(time.Now().Add(delay).UnixMilli(), 10))},
+			sarama.RecordHeader{Key: []byte(KafkaHeaderTargetTopic), Value: []byte(args.Topic)},
+		)
+		trace.SetTag("deliver_at", time.Now().Add(delay).Format(time.RFC3339))
+	}
 
 	traceHeader := map[string]string{}
 	trace.InjectRequestHeader(traceHeader)
@@ -226,12 +251,35 @@ func (p *kafkaPublisher) PublishMessage(ctx context.Context, args *candishared.P
 			Value: candihelper.ToBytes(valueHeader),
 		})
 	}
+	if _, ok := args.Header[candihelper.HeaderXTenantID]; !ok {
+		if tenantID := candishared.GetTenantFromContext(ctx); tenantID != "" {
+			msg.Headers = append(msg.Headers, sarama.RecordHeader{
+				Key:   []byte(candihelper.HeaderXTenantID),
+				Value: []byte(tenantID),
+			})
+		}
+	}
 
 	if p.producerSync != nil {
 		_, _, err = p.producerSync.SendMessage(msg)
 		trace.SetError(err)
+		err = classifyKafkaProducerError(err)
 	} else {
 		p.producerAsync.Input() <- msg
 	}
 	return
 }
+
+// classifyKafkaProducerError classify error for retry machinery, invalid or too large message is poison
+func classifyKafkaProducerError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sarama.ErrMessageSizeTooLarge), errors.Is(err, sarama.ErrInvalidMessage),
+		errors.Is(err, sarama.ErrInvalidMessageSize):
+		return candierrors.Poison(err)
+	case errors.Is(err, sarama.ErrTopicAuthorizationFailed), errors.Is(err, sarama.ErrClusterAuthorizationFailed):
+		return candierrors.Permanent(err)
+	}
+	return candierrors.Transient(err)
+}
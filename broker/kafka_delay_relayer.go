@@ -0,0 +1,157 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/logger"
+)
+
+const (
+	// KafkaHeaderDeliverAt header of scheduled delivery time in unix milliseconds
+	KafkaHeaderDeliverAt = "x-deliver-at"
+	// KafkaHeaderTargetTopic header of target topic of scheduled message
+	KafkaHeaderTargetTopic = "x-target-topic"
+)
+
+// KafkaDelayTopic internal topic of scheduled message (PublisherArgument with Delay or DeliverAt)
+var KafkaDelayTopic = "candi-delayed-messages"
+
+type (
+	// KafkaDelayRelayer relay scheduled message from delay topic to target topic when due, run one relayer per cluster
+	// or more with same consumer group for partition balancing
+	KafkaDelayRelayer struct {
+		client        sarama.Client
+		consumerGroup string
+		producer      sarama.SyncProducer
+		engine        sarama.ConsumerGroup
+		ctx           context.Context
+		cancel        context.CancelFunc
+		done          chan struct{}
+	}
+
+	// KafkaDelayRelayerOptionFunc option func of kafka delay relayer
+	KafkaDelayRelayerOptionFunc func(*KafkaDelayRelayer)
+)
+
+// KafkaDelayRelayerSetConsumerGroup option func, default "candi-delay-relayer"
+func KafkaDelayRelayerSetConsumerGroup(consumerGroup string) KafkaDelayRelayerOptionFunc {
+	return func(r *KafkaDelayRelayer) {
+		r.consumerGroup = consumerGroup
+	}
+}
+
+// NewKafkaDelayRelayer create kafka delay relayer, register in service applications (GetApplications).
+// Message in a partition is relayed in order, so message with longer delay published earlier hold shorter one behind it
+func NewKafkaDelayRelayer(client sarama.Client, opts ...KafkaDelayRelayerOptionFunc) (*KafkaDelayRelayer, error) {
+	r := &KafkaDelayRelayer{client: client, consumerGroup: "candi-delay-relayer", done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	var err error
+	if r.producer, err = sarama.NewSyncProducerFromClient(client); err != nil {
+		return nil, fmt.Errorf("kafka delay relayer: %w", err)
+	}
+	if r.engine, err = sarama.NewConsumerGroupFromClient(r.consumerGroup, client); err != nil {
+		return nil, fmt.Errorf("kafka delay relayer: %w", err)
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	return r, nil
+}
+
+// Serve consume delay topic until shutdown
+func (r *KafkaDelayRelayer) Serve() {
+	defer close(r.done)
+	for r.ctx.Err() == nil {
+		if err := r.engine.Consume(r.ctx, []string{KafkaDelayTopic}, r); err != nil && !errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			logger.LogE(fmt.Sprintf("kafka delay relayer: %s", err.Error()))
+			select {
+			case <-r.ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// Shutdown stop relayer, pending message is relayed by other relayer or after restart
+func (r *KafkaDelayRelayer) Shutdown(ctx context.Context) {
+	defer logger.LogWithDefer("\x1b[33;5mStopping Kafka Delay Relayer:\x1b[0m")()
+	r.cancel()
+	r.engine.Close()
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+	}
+	r.producer.Close()
+}
+
+// Name server name
+func (r *KafkaDelayRelayer) Name() string {
+	return "kafka-delay-relayer"
+}
+
+// Setup implement sarama.ConsumerGroupHandler
+func (r *KafkaDelayRelayer) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+// Cleanup implement sarama.ConsumerGroupHandler
+func (r *KafkaDelayRelayer) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim implement sarama.ConsumerGroupHandler, wait until message due then publish to target topic
+func (r *KafkaDelayRelayer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for message := range claim.Messages() {
+		msg, deliverAt, err := relayedKafkaMessage(message)
+		if err != nil {
+			logger.LogE(fmt.Sprintf("kafka delay relayer: skip message offset %d: %s", message.Offset, err.Error()))
+			session.MarkMessage(message, "")
+			continue
+		}
+
+		if wait := time.Until(deliverAt); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-session.Context().Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+		}
+
+		if _, _, err := r.producer.SendMessage(msg); err != nil {
+			// not marked, message is relayed again after rebalance or restart
+			return fmt.Errorf("relay message to %s: %w", msg.Topic, err)
+		}
+		session.MarkMessage(message, "")
+	}
+	return nil
+}
+
+// relayedKafkaMessage construct message to target topic from scheduled message, delay headers is removed
+func relayedKafkaMessage(message *sarama.ConsumerMessage) (msg *sarama.ProducerMessage, deliverAt time.Time, err error) {
+	msg = &sarama.ProducerMessage{
+		Key: sarama.ByteEncoder(message.Key), Value: sarama.ByteEncoder(message.Value), Timestamp: message.Timestamp,
+	}
+	for _, header := range message.Headers {
+		switch strings.ToLower(string(header.Key)) {
+		case KafkaHeaderDeliverAt:
+			ms, err := strconv.ParseInt(string(header.Value), 10, 64)
+			if err != nil {
+				return nil, deliverAt, fmt.Errorf("invalid header %s: %w", KafkaHeaderDeliverAt, err)
+			}
+			deliverAt = time.UnixMilli(ms)
+		case KafkaHeaderTargetTopic:
+			msg.Topic = string(header.Value)
+		default:
+			msg.Headers = append(msg.Headers, *header)
+		}
+	}
+	if msg.Topic == "" {
+		return nil, deliverAt, errors.New("missing header " + KafkaHeaderTargetTopic)
+	}
+	return msg, deliverAt, nil
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/codebase/interfaces"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/deadline"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -168,13 +170,19 @@ func (r *RabbitMQPublisher) PublishMessage(ctx context.Context, args *candishare
 
 	ch, err := r.conn.Channel()
 	if err != nil {
-		return err
+		return candierrors.Transient(err)
 	}
 	defer ch.Close()
 
+	if err := args.ApplyCodec(); err != nil {
+		return candierrors.Permanent(err)
+	}
 	if args.ContentType == "" {
 		args.ContentType = candihelper.HeaderMIMEApplicationJSON
 	}
+	if err := args.ApplyCloudEvent(candishared.CloudEventsAMQPHeaderPrefix); err != nil {
+		return candierrors.Permanent(err)
+	}
 
 	if args.Header == nil {
 		args.Header = make(map[string]any)
@@ -185,6 +193,14 @@ func (r *RabbitMQPublisher) PublishMessage(ctx context.Context, args *candishare
 	for k, v := range traceHeader {
 		args.Header[k] = v
 	}
+	deadline.InjectMessageHeader(ctx, args.Header)
+	candishared.InjectBaggageMessageHeader(ctx, args.Header)
+	candishared.InjectRequestIDMessageHeader(ctx, args.Header)
+	if _, ok := args.Header[candihelper.HeaderXTenantID]; !ok {
+		if tenantID := candishared.GetTenantFromContext(ctx); tenantID != "" {
+			args.Header[candihelper.HeaderXTenantID] = tenantID
+		}
+	}
 
 	trace.SetTag("topic", args.Topic)
 	trace.SetTag("key", args.Key)
@@ -195,8 +211,8 @@ func (r *RabbitMQPublisher) PublishMessage(ctx context.Context, args *candishare
 		ContentType:  args.ContentType,
 		Headers:      amqp.Table(args.Header),
 	}
-	if args.Delay > 0 {
-		msg.Headers[RabbitMQDelayHeader] = args.Delay.Milliseconds()
+	if delay := args.GetDelay(); delay > 0 {
+		msg.Headers[RabbitMQDelayHeader] = delay.Milliseconds()
 	}
 	if !args.Timestamp.IsZero() {
 		msg.Timestamp = args.Timestamp
@@ -211,10 +227,11 @@ func (r *RabbitMQPublisher) PublishMessage(ctx context.Context, args *candishare
 	trace.Log("header", msg.Headers)
 	trace.Log("message", msg.Body)
 
-	return ch.PublishWithContext(ctx,
+	err = ch.PublishWithContext(ctx,
 		r.exchange,
 		args.Topic, // routing key
 		false,      // mandatory
 		false,      // immediate
 		msg)
+	return candierrors.Transient(err)
 }
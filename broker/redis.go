@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/codebase/factory/types"
@@ -128,7 +130,7 @@ func (r *RedisBroker) PublishMessage(ctx context.Context, args *candishared.Publ
 	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
 
 	if args.Key == "" {
-		return errors.New("key cannot empty")
+		return candierrors.Permanent(errors.New("key cannot empty"))
 	}
 
 	trace.SetTag("topic", args.Topic)
@@ -138,14 +140,16 @@ func (r *RedisBroker) PublishMessage(ctx context.Context, args *candishared.Publ
 		return r.deleteMessage(ctx, args)
 	}
 	if err := args.Validate(); err != nil {
-		return err
+		return candierrors.Permanent(err)
 	}
-	if args.Delay <= 0 {
-		return errors.New("delay cannot empty")
+	delay := args.GetDelay()
+	if delay <= 0 && args.DeliverAt.IsZero() {
+		return candierrors.Permanent(errors.New("delay cannot empty"))
 	}
+	delay = max(delay, time.Millisecond)
 
 	trace.Log("header", args.Header)
-	trace.Log("delay", args.Delay.String())
+	trace.Log("delay", delay.String())
 	trace.Log("message", args.Message)
 
 	conn := r.Pool.Get()
@@ -157,11 +161,11 @@ func (r *RedisBroker) PublishMessage(ctx context.Context, args *candishared.Publ
 		EventID: eventID, HandlerName: args.Topic, Key: args.Key,
 	})
 	if _, err := conn.Do("SET", string(redisMessage), 1); err != nil {
-		return err
+		return candierrors.Transient(err)
 	}
-	_, err = conn.Do("EXPIRE", string(redisMessage), int(args.Delay.Seconds()))
+	_, err = conn.Do("PEXPIRE", string(redisMessage), delay.Milliseconds())
 	_, err = conn.Do("HSET", RedisBrokerKey, args.Key, args.Message)
-	return err
+	return candierrors.Transient(err)
 }
 
 // deleteMessage method
@@ -0,0 +1,333 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/tracer"
+	bolt "go.etcd.io/bbolt"
+)
+
+const boltCacheBucket = "candi_cache"
+
+// ErrBoltKeyNotFound error returned when key not exist or has been expired
+var ErrBoltKeyNotFound = errors.New("bolt: key not found")
+
+// BoltCache embedded key-value store (bbolt) implement interfaces.Cache,
+// for single instance service without external cache infrastructure
+type BoltCache struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// NewBoltCache constructor
+func NewBoltCache(db *bolt.DB) *BoltCache {
+	c := &BoltCache{db: db, bucket: []byte(boltCacheBucket)}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(c.bucket)
+		return err
+	}); err != nil {
+		panic("bolt cache: " + err.Error())
+	}
+	return c
+}
+
+// Get method
+func (b *BoltCache) Get(ctx context.Context, key string) (data []byte, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:get")
+	defer func() { trace.Log("result", data); trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.key", key)
+
+	err = b.db.View(func(tx *bolt.Tx) error {
+		value, ok := b.lookup(tx, []byte(key))
+		if !ok {
+			return ErrBoltKeyNotFound
+		}
+		data = append(data, value...)
+		return nil
+	})
+	return
+}
+
+// GetKeys method
+func (b *BoltCache) GetKeys(ctx context.Context, pattern string) (data []string, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:get_keys")
+	defer func() { trace.Log("result", data); trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.key", pattern)
+
+	err = b.db.View(func(tx *bolt.Tx) error {
+		return b.scan(tx, pattern+"*", func(key []byte) {
+			data = append(data, string(key))
+		})
+	})
+	return
+}
+
+// GetTTL method
+func (b *BoltCache) GetTTL(ctx context.Context, key string) (dur time.Duration, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:get_ttl")
+	defer func() { trace.Log("result", dur.String()); trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.key", key)
+
+	err = b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(b.bucket).Get([]byte(key))
+		if raw == nil {
+			dur = -2 * time.Second // same as redis TTL reply when key does not exist
+			return nil
+		}
+		expiredAt, _ := decodeBoltValue(raw)
+		switch {
+		case expiredAt.IsZero():
+			dur = -1 * time.Second // same as redis TTL reply when key has no expire
+		case time.Now().After(expiredAt):
+			dur = -2 * time.Second
+		default:
+			dur = time.Until(expiredAt).Truncate(time.Second)
+		}
+		return nil
+	})
+	return
+}
+
+// Set method
+func (b *BoltCache) Set(ctx context.Context, key string, value any, expire time.Duration) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:set")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.key", key)
+	trace.SetTag("db.expired", expire.String())
+	trace.Log("value", value)
+
+	var expiredAt time.Time
+	if expire > 0 {
+		expiredAt = time.Now().Add(expire)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(key), encodeBoltValue(expiredAt, candihelper.ToBytes(value)))
+	})
+}
+
+// Exists method
+func (b *BoltCache) Exists(ctx context.Context, key string) (exist bool, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:exists")
+	defer func() { trace.Log("result", exist); trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.key", key)
+
+	err = b.db.View(func(tx *bolt.Tx) error {
+		_, exist = b.lookup(tx, []byte(key))
+		return nil
+	})
+	return
+}
+
+// Delete method with pattern
+func (b *BoltCache) Delete(ctx context.Context, key string) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:delete")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.key", key)
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		_, err := b.delete(tx, key)
+		return err
+	})
+}
+
+// DoCommand method, only support basic command (GET, SET, DEL, EXISTS, KEYS, TTL, EXPIRE, INCR, DECR)
+func (b *BoltCache) DoCommand(ctx context.Context, isWrite bool, command string, args ...any) (reply any, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "bolt:do_command")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	trace.SetTag("db.command", command)
+	trace.Log("args", args)
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("bolt: missing key argument for command %s", command)
+	}
+	key := candihelper.ToString(args[0])
+
+	switch strings.ToUpper(command) {
+	case "GET":
+		return b.Get(ctx, key)
+	case "SET":
+		if len(args) < 2 {
+			return nil, errors.New("bolt: missing value argument for command SET")
+		}
+		return "OK", b.Set(ctx, key, args[1], 0)
+	case "DEL":
+		return b.del(args...)
+	case "EXISTS":
+		exist, err := b.Exists(ctx, key)
+		if exist {
+			return int64(1), err
+		}
+		return int64(0), err
+	case "KEYS":
+		return b.GetKeys(ctx, strings.TrimSuffix(key, "*"))
+	case "TTL":
+		ttl, err := b.GetTTL(ctx, key)
+		return int64(ttl.Seconds()), err
+	case "EXPIRE":
+		if len(args) < 2 {
+			return nil, errors.New("bolt: missing seconds argument for command EXPIRE")
+		}
+		return int64(1), b.expire(key, time.Duration(candihelper.ToInt(args[1]))*time.Second)
+	case "INCR":
+		return b.incrBy(key, 1)
+	case "DECR":
+		return b.incrBy(key, -1)
+	}
+	return nil, fmt.Errorf("bolt: unsupported command %s", command)
+}
+
+func (b *BoltCache) lookup(tx *bolt.Tx, key []byte) ([]byte, bool) {
+	raw := tx.Bucket(b.bucket).Get(key)
+	if raw == nil {
+		return nil, false
+	}
+	expiredAt, value := decodeBoltValue(raw)
+	if !expiredAt.IsZero() && time.Now().After(expiredAt) {
+		return nil, false
+	}
+	return value, true
+}
+
+// delete key or keys match pattern (with "*" suffix), return number of existing key deleted
+func (b *BoltCache) delete(tx *bolt.Tx, key string) (deleted int64, err error) {
+	if !strings.HasSuffix(key, "*") {
+		if _, ok := b.lookup(tx, []byte(key)); ok {
+			deleted++
+		}
+		return deleted, tx.Bucket(b.bucket).Delete([]byte(key))
+	}
+
+	var keys [][]byte
+	// key slice of cursor is only valid until the transaction is modified
+	if err := b.scan(tx, key, func(k []byte) { keys = append(keys, append([]byte{}, k...)) }); err != nil {
+		return 0, err
+	}
+	for _, k := range keys {
+		if err := tx.Bucket(b.bucket).Delete(k); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// del same as redis DEL reply, number of keys that were removed
+func (b *BoltCache) del(keys ...any) (deleted int64, err error) {
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		for _, key := range keys {
+			n, err := b.delete(tx, candihelper.ToString(key))
+			if err != nil {
+				return err
+			}
+			deleted += n
+		}
+		return nil
+	})
+	return
+}
+
+func (b *BoltCache) scan(tx *bolt.Tx, pattern string, fn func(key []byte)) error {
+	prefix, rest := literalPrefix(pattern)
+	isPrefixPattern := rest == "*"
+
+	cur := tx.Bucket(b.bucket).Cursor()
+	for k, _ := cur.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = cur.Next() {
+		if !isPrefixPattern {
+			ok, err := path.Match(pattern, string(k))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if _, ok := b.lookup(tx, k); ok {
+			fn(k)
+		}
+	}
+	return nil
+}
+
+// literalPrefix unescaped literal prefix of glob pattern until first special character, and the rest of pattern
+func literalPrefix(pattern string) (prefix, rest string) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*', '?', '[':
+			return b.String(), pattern[i:]
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+				continue
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), ""
+}
+
+func (b *BoltCache) expire(key string, ttl time.Duration) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		value, ok := b.lookup(tx, []byte(key))
+		if !ok {
+			return nil
+		}
+		return tx.Bucket(b.bucket).Put([]byte(key), encodeBoltValue(time.Now().Add(ttl), value))
+	})
+}
+
+func (b *BoltCache) incrBy(key string, n int) (res int64, err error) {
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		var expiredAt time.Time
+		if raw := tx.Bucket(b.bucket).Get([]byte(key)); raw != nil {
+			var value []byte
+			expiredAt, value = decodeBoltValue(raw)
+			if !expiredAt.IsZero() && time.Now().After(expiredAt) {
+				expiredAt = time.Time{}
+			} else {
+				res = int64(candihelper.ToInt(string(value)))
+			}
+		}
+		res += int64(n)
+		return tx.Bucket(b.bucket).Put([]byte(key), encodeBoltValue(expiredAt, []byte(fmt.Sprint(res))))
+	})
+	return
+}
+
+// encodeBoltValue store expired time (unix nano, 0 if never expired) as 8 bytes prefix of value
+func encodeBoltValue(expiredAt time.Time, value []byte) []byte {
+	buff := make([]byte, 8+len(value))
+	if !expiredAt.IsZero() {
+		binary.BigEndian.PutUint64(buff, uint64(expiredAt.UnixNano()))
+	}
+	copy(buff[8:], value)
+	return buff
+}
+
+func decodeBoltValue(raw []byte) (expiredAt time.Time, value []byte) {
+	if len(raw) < 8 {
+		return expiredAt, raw
+	}
+	if unix := binary.BigEndian.Uint64(raw[:8]); unix > 0 {
+		expiredAt = time.Unix(0, int64(unix))
+	}
+	return expiredAt, raw[8:]
+}
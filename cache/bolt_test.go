@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltCache(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	c := NewBoltCache(db)
+
+	assert.NoError(t, c.Set(ctx, "user:1", "agung", 0))
+	assert.NoError(t, c.Set(ctx, "user:2", map[string]int{"id": 2}, time.Minute))
+	assert.NoError(t, c.Set(ctx, "expired", "value", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	data, err := c.Get(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "agung", string(data))
+
+	_, err = c.Get(ctx, "expired")
+	assert.ErrorIs(t, err, ErrBoltKeyNotFound)
+
+	keys, err := c.GetKeys(ctx, "user:")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+	keys, err = c.GetKeys(ctx, EscapePattern("*"))
+	assert.NoError(t, err)
+	assert.Empty(t, keys, "escaped pattern matched literally")
+
+	ttl, _ := c.GetTTL(ctx, "user:2")
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+	count, err := c.DoCommand(ctx, true, "INCR", "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, c.Delete(ctx, "user:*"))
+	exist, _ := c.Exists(ctx, "user:1")
+	assert.False(t, exist)
+
+	for i := range 1000 {
+		assert.NoError(t, c.Set(ctx, fmt.Sprintf("session:%04d", i), strings.Repeat("x", 100), 0))
+	}
+	assert.NoError(t, c.Delete(ctx, "session:*"))
+	keys, err = c.GetKeys(ctx, "session:")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	assert.NoError(t, c.Set(ctx, "a", "1", 0))
+	assert.NoError(t, c.Set(ctx, "b", "2", 0))
+	deleted, err := c.DoCommand(ctx, true, "DEL", "a", "b", "expired", "unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted, "DEL reply number of existing key")
+	deleted, err = c.DoCommand(ctx, true, "DEL", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
@@ -34,18 +34,45 @@ func (r *RedisCache) Get(ctx context.Context, key string) (data []byte, err erro
 	return redis.Bytes(cl.Do("GET", key))
 }
 
-// GetKeys method
+// GetKeys method, iterate with SCAN so redis is not blocked on large keyspace
 func (r *RedisCache) GetKeys(ctx context.Context, pattern string) (data []string, err error) {
 	trace, ctx := tracer.StartTraceWithContext(ctx, "redis:get_keys")
 	defer func() { trace.Log("result", data); trace.Finish(tracer.FinishWithError(err)) }()
 
-	trace.SetTag("db.statement", "KEYS")
+	trace.SetTag("db.statement", "SCAN")
 	trace.SetTag("db.key", pattern)
 
 	cl := r.read.Get()
 	defer cl.Close()
 
-	return redis.Strings(cl.Do("KEYS", fmt.Sprintf("%s*", pattern)))
+	cursor := "0"
+	for {
+		values, err := redis.Values(cl.Do("SCAN", cursor, "MATCH", fmt.Sprintf("%s*", pattern), "COUNT", 1000))
+		if err != nil {
+			return data, err
+		}
+		var keys []string
+		if _, err := redis.Scan(values, &cursor, &keys); err != nil {
+			return data, err
+		}
+		data = append(data, keys...)
+		if cursor == "0" {
+			return data, nil
+		}
+	}
+}
+
+// EscapePattern escape glob special character (*, ?, [, ], \) in s, so s matched literally in GetKeys pattern
+func EscapePattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
 }
 
 // GetTTL method
@@ -0,0 +1,121 @@
+package candierrors
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Code standard error code, can be mapped to http status and grpc code
+type Code string
+
+const (
+	// CodeUnknown error code
+	CodeUnknown Code = "UNKNOWN"
+	// CodeInvalid error code for invalid request/argument
+	CodeInvalid Code = "INVALID_ARGUMENT"
+	// CodeNotFound error code
+	CodeNotFound Code = "NOT_FOUND"
+	// CodeAlreadyExists error code
+	CodeAlreadyExists Code = "ALREADY_EXISTS"
+	// CodeConflict error code, example for version conflict in optimistic locking
+	CodeConflict Code = "CONFLICT"
+	// CodeUnauthenticated error code
+	CodeUnauthenticated Code = "UNAUTHENTICATED"
+	// CodeForbidden error code
+	CodeForbidden Code = "PERMISSION_DENIED"
+	// CodeFailedPrecondition error code
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+	// CodeTooManyRequests error code
+	CodeTooManyRequests Code = "RESOURCE_EXHAUSTED"
+	// CodeCanceled error code
+	CodeCanceled Code = "CANCELED"
+	// CodeTimeout error code
+	CodeTimeout Code = "DEADLINE_EXCEEDED"
+	// CodeUnimplemented error code
+	CodeUnimplemented Code = "UNIMPLEMENTED"
+	// CodeUnavailable error code
+	CodeUnavailable Code = "UNAVAILABLE"
+	// CodeInternal error code
+	CodeInternal Code = "INTERNAL"
+)
+
+type codeMapping struct {
+	httpStatus int
+	grpcCode   codes.Code
+}
+
+var codeMappings = map[Code]codeMapping{
+	CodeUnknown:            {http.StatusInternalServerError, codes.Unknown},
+	CodeInvalid:            {http.StatusBadRequest, codes.InvalidArgument},
+	CodeNotFound:           {http.StatusNotFound, codes.NotFound},
+	CodeAlreadyExists:      {http.StatusConflict, codes.AlreadyExists},
+	CodeConflict:           {http.StatusConflict, codes.Aborted},
+	CodeUnauthenticated:    {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeForbidden:          {http.StatusForbidden, codes.PermissionDenied},
+	CodeFailedPrecondition: {http.StatusPreconditionFailed, codes.FailedPrecondition},
+	CodeTooManyRequests:    {http.StatusTooManyRequests, codes.ResourceExhausted},
+	CodeCanceled:           {499, codes.Canceled}, // client closed request
+	CodeTimeout:            {http.StatusGatewayTimeout, codes.DeadlineExceeded},
+	CodeUnimplemented:      {http.StatusNotImplemented, codes.Unimplemented},
+	CodeUnavailable:        {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeInternal:           {http.StatusInternalServerError, codes.Internal},
+}
+
+// HTTPStatus get http status code from error code
+func (c Code) HTTPStatus() int {
+	if m, ok := codeMappings[c]; ok {
+		return m.httpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode get grpc code from error code
+func (c Code) GRPCCode() codes.Code {
+	if m, ok := codeMappings[c]; ok {
+		return m.grpcCode
+	}
+	return codes.Unknown
+}
+
+// CodeFromHTTPStatus get error code from http status
+func CodeFromHTTPStatus(httpStatus int) Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalid
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestTimeout:
+		return CodeTimeout
+	}
+	for code, m := range codeMappings {
+		if m.httpStatus == httpStatus && code != CodeUnknown && code != CodeAlreadyExists {
+			return code
+		}
+	}
+	if httpStatus < http.StatusBadRequest {
+		return ""
+	}
+	if httpStatus < http.StatusInternalServerError {
+		return CodeInvalid
+	}
+	return CodeInternal
+}
+
+// CodeFromGRPC get error code from grpc code
+func CodeFromGRPC(grpcCode codes.Code) Code {
+	switch grpcCode {
+	case codes.OK:
+		return ""
+	case codes.OutOfRange:
+		return CodeInvalid
+	case codes.DataLoss:
+		return CodeInternal
+	}
+	for code, m := range codeMappings {
+		if m.grpcCode == grpcCode {
+			return code
+		}
+	}
+	return CodeUnknown
+}
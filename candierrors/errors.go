@@ -0,0 +1,206 @@
+// Package candierrors standard error with code, message, metadata, and cause chain,
+// automatically mapped to http status (wrapper.NewHTTPResponseFromError), grpc status, and graphql error extensions
+package candierrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Error standard error
+type Error struct {
+	Code     Code
+	Message  string
+	Metadata map[string]any
+	cause    error
+}
+
+// New construct error with code and message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf construct error with code and formatted message
+func Newf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap construct error with code and message, wrapping cause error
+func Wrap(cause error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, cause: cause}
+}
+
+// Invalid error for invalid request/argument
+func Invalid(message string) *Error { return New(CodeInvalid, message) }
+
+// NotFound error
+func NotFound(message string) *Error { return New(CodeNotFound, message) }
+
+// AlreadyExists error
+func AlreadyExists(message string) *Error { return New(CodeAlreadyExists, message) }
+
+// Conflict error
+func Conflict(message string) *Error { return New(CodeConflict, message) }
+
+// Unauthenticated error
+func Unauthenticated(message string) *Error { return New(CodeUnauthenticated, message) }
+
+// Forbidden error
+func Forbidden(message string) *Error { return New(CodeForbidden, message) }
+
+// FailedPrecondition error
+func FailedPrecondition(message string) *Error { return New(CodeFailedPrecondition, message) }
+
+// TooManyRequests error
+func TooManyRequests(message string) *Error { return New(CodeTooManyRequests, message) }
+
+// Timeout error
+func Timeout(message string) *Error { return New(CodeTimeout, message) }
+
+// Unimplemented error
+func Unimplemented(message string) *Error { return New(CodeUnimplemented, message) }
+
+// Unavailable error
+func Unavailable(message string) *Error { return New(CodeUnavailable, message) }
+
+// Internal error
+func Internal(message string) *Error { return New(CodeInternal, message) }
+
+// Error implement error
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap get cause error
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is error with same code (and same message if target message is not empty)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code && (t.Message == "" || e.Message == t.Message)
+}
+
+// WithCause return copy of error with cause
+func (e *Error) WithCause(cause error) *Error {
+	c := e.clone()
+	c.cause = cause
+	return c
+}
+
+// WithMetadata return copy of error with additional metadata
+func (e *Error) WithMetadata(key string, value any) *Error {
+	c := e.clone()
+	c.Metadata[key] = value
+	return c
+}
+
+// HTTPStatusCode get http status code
+func (e *Error) HTTPStatusCode() int {
+	return e.Code.HTTPStatus()
+}
+
+// GRPCStatus implement grpc status error, metadata included in error details
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code.GRPCCode(), e.Message)
+	if len(e.Metadata) == 0 {
+		return st
+	}
+	details, err := structpb.NewStruct(e.Metadata)
+	if err != nil {
+		return st
+	}
+	if withDetails, err := st.WithDetails(details); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// Extensions implement graphql error extensions
+func (e *Error) Extensions() map[string]any {
+	ext := map[string]any{"code": e.Code}
+	if len(e.Metadata) > 0 {
+		ext["metadata"] = e.Metadata
+	}
+	return ext
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.Metadata = make(map[string]any, len(e.Metadata)+1)
+	maps.Copy(c.Metadata, e.Metadata)
+	return &c
+}
+
+// As find first *Error in err chain
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Is check error code in err chain
+func Is(err error, code Code) bool {
+	return CodeOf(err) == code
+}
+
+// CodeOf get error code from err chain, also resolve context error,
+// error with `HTTPStatusCode() int` method, and grpc status error
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	if e, ok := As(err); ok {
+		return e.Code
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	}
+
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) {
+		return CodeFromHTTPStatus(httpErr.HTTPStatusCode())
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		return CodeFromGRPC(grpcErr.GRPCStatus().Code())
+	}
+	return CodeUnknown
+}
+
+// HTTPStatus get http status code from err chain
+func HTTPStatus(err error) int {
+	return CodeOf(err).HTTPStatus()
+}
+
+// ToGRPCStatus convert error to grpc status error
+func ToGRPCStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := As(err); ok {
+		return e.GRPCStatus().Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(CodeOf(err).GRPCCode(), err.Error())
+}
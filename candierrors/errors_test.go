@@ -0,0 +1,81 @@
+package candierrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	err := fmt.Errorf("usecase: %w", Wrap(cause, CodeNotFound, "user not found").WithMetadata("user_id", "123"))
+
+	assert.Equal(t, "usecase: user not found: sql: no rows in result set", err.Error())
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, errors.Is(err, NotFound("")))
+	assert.False(t, errors.Is(err, Invalid("")))
+	assert.True(t, Is(err, CodeNotFound))
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(err))
+
+	e, ok := As(err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"code": CodeNotFound, "metadata": map[string]any{"user_id": "123"}}, e.Extensions())
+
+	st, _ := status.FromError(ToGRPCStatus(err))
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "user not found", st.Message())
+	assert.Len(t, st.Details(), 1)
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, Code(""), CodeOf(nil))
+	assert.Equal(t, CodeTimeout, CodeOf(fmt.Errorf("call: %w", context.DeadlineExceeded)))
+	assert.Equal(t, CodeForbidden, CodeOf(status.Error(codes.PermissionDenied, "denied")))
+	assert.Equal(t, CodeUnknown, CodeOf(errors.New("unknown")))
+	assert.Equal(t, CodeInvalid, CodeFromHTTPStatus(http.StatusBadRequest))
+	assert.Equal(t, CodeNotFound, CodeFromHTTPStatus(http.StatusNotFound))
+	assert.Equal(t, CodeInternal, CodeFromHTTPStatus(http.StatusInternalServerError))
+}
+
+func TestRetryClass(t *testing.T) {
+	assert.Equal(t, RetryClass(""), ClassOf(nil))
+	assert.Nil(t, Transient(nil))
+
+	err := fmt.Errorf("publish: %w", Throttled(errors.New("rate limited"), 3*time.Second))
+	assert.Equal(t, ClassThrottled, ClassOf(err))
+	assert.Equal(t, 3*time.Second, RetryAfter(err))
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, "publish: rate limited", err.Error())
+
+	assert.Equal(t, ClassPermanent, ClassOf(Permanent(Unavailable("down"))))
+	assert.Equal(t, ClassTransient, ClassOf(Transient(NotFound("not replicated yet"))))
+	assert.False(t, IsRetryable(Poison(errors.New("invalid payload"))))
+
+	// default classification
+	assert.Equal(t, ClassPermanent, ClassOf(Invalid("invalid")))
+	assert.Equal(t, ClassThrottled, ClassOf(status.Error(codes.ResourceExhausted, "quota")))
+	assert.Equal(t, ClassTransient, ClassOf(context.DeadlineExceeded))
+	assert.Equal(t, ClassTransient, ClassOf(errors.New("connection reset")))
+	assert.Equal(t, ClassPoison, ClassOf(json.Unmarshal([]byte("{"), &struct{}{})))
+	_, ok := Classify(errors.New("connection reset"))
+	assert.False(t, ok)
+
+	errDuplicate := errors.New("duplicate key")
+	RegisterClassifier(func(err error) (RetryClass, bool) {
+		if errors.Is(err, errDuplicate) {
+			return ClassPermanent, true
+		}
+		return "", false
+	})
+	class, ok := Classify(fmt.Errorf("insert: %w", errDuplicate))
+	assert.True(t, ok)
+	assert.Equal(t, ClassPermanent, class)
+}
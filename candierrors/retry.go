@@ -0,0 +1,130 @@
+package candierrors
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RetryClass classification of error for retry machinery (http client, publisher, and worker retry)
+type RetryClass string
+
+const (
+	// ClassTransient temporary failure, safe to retry
+	ClassTransient RetryClass = "TRANSIENT"
+	// ClassPermanent failure which never succeed when retried with same input
+	ClassPermanent RetryClass = "PERMANENT"
+	// ClassThrottled rejected by rate limit, retry after a delay
+	ClassThrottled RetryClass = "THROTTLED"
+	// ClassPoison malformed message/payload, must not be retried (move to dead letter if any)
+	ClassPoison RetryClass = "POISON"
+)
+
+// Classifier mapping hook for classify error from other package (example: driver or sdk error),
+// return false if error is not recognized
+type Classifier func(err error) (class RetryClass, ok bool)
+
+type classifiedError struct {
+	class      RetryClass
+	retryAfter time.Duration
+	err        error
+}
+
+var (
+	classifierMu sync.RWMutex
+	classifiers  []Classifier
+)
+
+// Transient mark error as transient
+func Transient(err error) error { return classify(err, ClassTransient, 0) }
+
+// Permanent mark error as permanent
+func Permanent(err error) error { return classify(err, ClassPermanent, 0) }
+
+// Throttled mark error as throttled, retryAfter is minimum delay before next attempt (zero for use default delay)
+func Throttled(err error, retryAfter time.Duration) error {
+	return classify(err, ClassThrottled, retryAfter)
+}
+
+// Poison mark error as poison (payload cannot be processed)
+func Poison(err error) error { return classify(err, ClassPoison, 0) }
+
+func classify(err error, class RetryClass, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{class: class, retryAfter: retryAfter, err: err}
+}
+
+func (c *classifiedError) Error() string { return c.err.Error() }
+func (c *classifiedError) Unwrap() error { return c.err }
+
+// RegisterClassifier add mapping hook, evaluated in registration order before default classification by error code
+func RegisterClassifier(classifier Classifier) {
+	classifierMu.Lock()
+	defer classifierMu.Unlock()
+	classifiers = append(classifiers, classifier)
+}
+
+// Classify get explicit retry class of error from wrapper in err chain or registered classifier,
+// return false if error is not classified
+func Classify(err error) (RetryClass, bool) {
+	if err == nil {
+		return "", false
+	}
+	var c *classifiedError
+	if errors.As(err, &c) {
+		return c.class, true
+	}
+
+	classifierMu.RLock()
+	defer classifierMu.RUnlock()
+	for _, classifier := range classifiers {
+		if class, ok := classifier(err); ok {
+			return class, true
+		}
+	}
+	return "", false
+}
+
+// ClassOf get retry class of error, if not explicitly classified then derived from error code
+// (json decode error is classified as poison, unknown error as transient)
+func ClassOf(err error) RetryClass {
+	if err == nil {
+		return ""
+	}
+	if class, ok := Classify(err); ok {
+		return class
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return ClassPoison
+	}
+
+	switch CodeOf(err) {
+	case CodeTooManyRequests:
+		return ClassThrottled
+	case CodeInvalid, CodeNotFound, CodeAlreadyExists, CodeUnauthenticated, CodeForbidden,
+		CodeFailedPrecondition, CodeUnimplemented, CodeCanceled:
+		return ClassPermanent
+	}
+	return ClassTransient
+}
+
+// IsRetryable check if error is transient or throttled
+func IsRetryable(err error) bool {
+	class := ClassOf(err)
+	return class == ClassTransient || class == ClassThrottled
+}
+
+// RetryAfter get delay from throttled error, zero if not set
+func RetryAfter(err error) time.Duration {
+	var c *classifiedError
+	if errors.As(err, &c) {
+		return c.retryAfter
+	}
+	return 0
+}
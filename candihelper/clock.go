@@ -0,0 +1,20 @@
+package candihelper
+
+import "time"
+
+// Clock abstraction of current time, used by scheduler, retry, cache TTL and token expiry check. Inject fake clock
+// (see testkit.FakeClock) for deterministic test of time based logic without sleeping
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+}
+
+// SystemClock clock of system time
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                  { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (systemClock) Until(t time.Time) time.Duration { return time.Until(t) }
@@ -54,6 +54,16 @@ const (
 	HeaderLastModified = "Last-Modified"
 	// HeaderIfModifiedSince header const
 	HeaderIfModifiedSince = "If-Modified-Since"
+	// HeaderIdempotencyKey header const
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderAcceptLanguage header const
+	HeaderAcceptLanguage = "Accept-Language"
+	// HeaderXTenantID header const
+	HeaderXTenantID = "X-Tenant-ID"
+	// HeaderXRequestID header const
+	HeaderXRequestID = "X-Request-ID"
+	// HeaderXPayloadCodec header const, name of codec used for encode message payload
+	HeaderXPayloadCodec = "X-Payload-Codec"
 	// HeaderMIMEApplicationJSON const
 	HeaderMIMEApplicationJSON = "application/json"
 	// HeaderMIMEApplicationXML const
@@ -0,0 +1,486 @@
+package candihelper
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat file format for export helpers
+type ExportFormat string
+
+const (
+	// ExportFormatCSV comma separated values
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatXLSX office open xml spreadsheet
+	ExportFormatXLSX ExportFormat = "xlsx"
+
+	// ExportTag struct tag for column header, "-" for skip field (fallback to json tag, then field name)
+	ExportTag = "export"
+
+	defaultExportFlushEvery = 1000
+	defaultExportSheetName  = "Sheet1"
+)
+
+// ContentType mime type of export format
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ExportWriter abstract row writer for export file
+type ExportWriter interface {
+	WriteRow(values ...any) error
+	Flush() error
+	Close() error
+}
+
+type (
+	// ExportOption for export helpers
+	ExportOption struct {
+		header       []string
+		noHeader     bool
+		sheetName    string
+		timeFormat   string
+		flushEvery   int
+		allowFormula bool
+	}
+
+	// ExportOptionFunc option func type
+	ExportOptionFunc func(*ExportOption)
+)
+
+// SetExportHeader option func, override header generated from struct tags
+func SetExportHeader(header ...string) ExportOptionFunc {
+	return func(o *ExportOption) {
+		o.header = header
+	}
+}
+
+// SetExportWithoutHeader option func, skip write header row
+func SetExportWithoutHeader() ExportOptionFunc {
+	return func(o *ExportOption) {
+		o.noHeader = true
+	}
+}
+
+// SetExportSheetName option func, set worksheet name for xlsx format (default "Sheet1")
+func SetExportSheetName(name string) ExportOptionFunc {
+	return func(o *ExportOption) {
+		o.sheetName = name
+	}
+}
+
+// SetExportTimeFormat option func, set layout for time value (default time.RFC3339)
+func SetExportTimeFormat(layout string) ExportOptionFunc {
+	return func(o *ExportOption) {
+		o.timeFormat = layout
+	}
+}
+
+// SetExportFlushEvery option func, flush written rows to underlying writer every n rows (default 1000),
+// underlying writer flushed too if implement http.Flusher
+func SetExportFlushEvery(n int) ExportOptionFunc {
+	return func(o *ExportOption) {
+		o.flushEvery = n
+	}
+}
+
+// SetExportAllowFormula option func, disable escaping text cell starting with formula character (=, +, -, @, tab, carriage return),
+// by default text cell is prefixed with single quote to prevent formula injection when opened in spreadsheet application
+func SetExportAllowFormula() ExportOptionFunc {
+	return func(o *ExportOption) {
+		o.allowFormula = true
+	}
+}
+
+func newExportOption(opts ...ExportOptionFunc) *ExportOption {
+	o := &ExportOption{
+		sheetName: defaultExportSheetName, timeFormat: time.RFC3339, flushEvery: defaultExportFlushEvery,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// NewExportWriter create row writer with given format to w
+func NewExportWriter(w io.Writer, format ExportFormat, opts ...ExportOptionFunc) (ExportWriter, error) {
+	o := newExportOption(opts...)
+	switch format {
+	case ExportFormatCSV:
+		return &csvExportWriter{w: w, csv: csv.NewWriter(w), timeFormat: o.timeFormat, allowFormula: o.allowFormula}, nil
+	case ExportFormatXLSX:
+		return newXLSXExportWriter(w, o.sheetName, o.timeFormat, o.allowFormula)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ExportHeader get column headers from struct T fields
+func ExportHeader[T any]() (header []string) {
+	typ := ReflectTypeUnwrapPtr(reflect.TypeOf((*T)(nil)).Elem())
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, i := range exportFieldIndexes(typ) {
+		header = append(header, exportColumnName(typ.Field(i)))
+	}
+	return header
+}
+
+// ExportRows stream rows from source to w with given format, header taken from struct tags of T.
+// Source must call yield for each row, returned count is total exported rows (exclude header)
+func ExportRows[T any](ctx context.Context, w io.Writer, format ExportFormat, source func(ctx context.Context, yield func(T) error) error, opts ...ExportOptionFunc) (count int, err error) {
+	o := newExportOption(opts...)
+	writer, err := NewExportWriter(w, format, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	header := o.header
+	if header == nil {
+		header = ExportHeader[T]()
+	}
+	if !o.noHeader && len(header) > 0 {
+		values := make([]any, len(header))
+		for i, h := range header {
+			values[i] = h
+		}
+		if err := writer.WriteRow(values...); err != nil {
+			return 0, err
+		}
+	}
+
+	var indexes []int
+	if typ := ReflectTypeUnwrapPtr(reflect.TypeOf((*T)(nil)).Elem()); typ.Kind() == reflect.Struct {
+		indexes = exportFieldIndexes(typ)
+	}
+	err = source(ctx, func(row T) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writer.WriteRow(exportRowValues(row, indexes)...); err != nil {
+			return err
+		}
+		count++
+		if o.flushEvery > 0 && count%o.flushEvery == 0 {
+			return writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+// ExportChannel stream rows from channel to w with given format until channel closed
+func ExportChannel[T any](ctx context.Context, w io.Writer, format ExportFormat, ch <-chan T, opts ...ExportOptionFunc) (int, error) {
+	return ExportRows(ctx, w, format, func(ctx context.Context, yield func(T) error) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case row, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				if err := yield(row); err != nil {
+					return err
+				}
+			}
+		}
+	}, opts...)
+}
+
+// HTTPExport stream rows from source as file attachment in http response, file extension appended to filename
+func HTTPExport[T any](ctx context.Context, w http.ResponseWriter, filename string, format ExportFormat, source func(ctx context.Context, yield func(T) error) error, opts ...ExportOptionFunc) (int, error) {
+	if !strings.HasSuffix(filename, "."+string(format)) {
+		filename += "." + string(format)
+	}
+	w.Header().Set(HeaderContentType, format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set(HeaderCacheControl, "no-store")
+	return ExportRows(ctx, w, format, source, opts...)
+}
+
+// ExportStorage abstract object storage destination for export
+type ExportStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+}
+
+// ExportToStorage stream rows from source directly to object storage without buffering the whole file,
+// suitable for export job in task queue worker (return the object key to user after finished)
+func ExportToStorage[T any](ctx context.Context, storage ExportStorage, key string, format ExportFormat, source func(ctx context.Context, yield func(T) error) error, opts ...ExportOptionFunc) (count int, err error) {
+	pr, pw := io.Pipe()
+	putErr := make(chan error, 1)
+	go func() {
+		err := storage.Put(ctx, key, pr, format.ContentType())
+		pr.CloseWithError(err)
+		putErr <- err
+	}()
+
+	count, err = ExportRows(ctx, pw, format, source, opts...)
+	pw.CloseWithError(err)
+	if perr := <-putErr; err == nil {
+		err = perr
+	}
+	return count, err
+}
+
+func exportFieldIndexes(typ reflect.Type) (indexes []int) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || field.Tag.Get(ExportTag) == "-" {
+			continue
+		}
+		if field.Tag.Get(ExportTag) == "" && field.Tag.Get("json") == "-" {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+func exportColumnName(field reflect.StructField) string {
+	if name := field.Tag.Get(ExportTag); name != "" {
+		return name
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+func exportRowValues(row any, indexes []int) []any {
+	val := ReflectValueUnwrapPtr(reflect.ValueOf(row))
+	switch val.Kind() {
+	case reflect.Struct:
+		values := make([]any, len(indexes))
+		for i, idx := range indexes {
+			values[i] = val.Field(idx).Interface()
+		}
+		return values
+	case reflect.Slice, reflect.Array:
+		values := make([]any, val.Len())
+		for i := range values {
+			values[i] = val.Index(i).Interface()
+		}
+		return values
+	case reflect.Invalid:
+		return nil
+	}
+	return []any{val.Interface()}
+}
+
+// formatExportValue format cell value, numeric is true if value must be written as number
+func formatExportValue(value any, timeFormat string) (str string, numeric bool) {
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return "", false
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return "", false
+	}
+
+	switch v := val.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return "", false
+		}
+		return v.Format(timeFormat), false
+	case []byte:
+		return string(v), false
+	case fmt.Stringer:
+		return v.String(), false
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), false
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, val.Type().Bits()), true
+	}
+	return fmt.Sprint(val.Interface()), false
+}
+
+// escapeExportFormula prefix text cell starting with formula character with single quote
+func escapeExportFormula(str string) string {
+	if str != "" && strings.ContainsRune("=+-@\t\r", rune(str[0])) {
+		return "'" + str
+	}
+	return str
+}
+
+// flushUnderlying flush underlying writer if writer is buffered (example: http.ResponseWriter)
+func flushUnderlying(w io.Writer) error {
+	switch f := w.(type) {
+	case http.Flusher:
+		f.Flush()
+	case interface{ Flush() error }:
+		return f.Flush()
+	}
+	return nil
+}
+
+type csvExportWriter struct {
+	w            io.Writer
+	csv          *csv.Writer
+	timeFormat   string
+	allowFormula bool
+	record       []string
+}
+
+func (c *csvExportWriter) WriteRow(values ...any) error {
+	c.record = c.record[:0]
+	for _, value := range values {
+		str, numeric := formatExportValue(value, c.timeFormat)
+		if !numeric && !c.allowFormula {
+			str = escapeExportFormula(str)
+		}
+		c.record = append(c.record, str)
+	}
+	return c.csv.Write(c.record)
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	return flushUnderlying(c.w)
+}
+
+func (c *csvExportWriter) Close() error {
+	return c.Flush()
+}
+
+// xlsxExportWriter minimal streaming xlsx writer, worksheet rows written directly to zip entry
+// so memory usage is constant regardless number of rows
+type xlsxExportWriter struct {
+	w            io.Writer
+	zip          *zip.Writer
+	sheet        io.Writer
+	timeFormat   string
+	allowFormula bool
+	rowNum       int
+	buf          bytes.Buffer
+	closed       bool
+}
+
+func newXLSXExportWriter(w io.Writer, sheetName, timeFormat string, allowFormula bool) (*xlsxExportWriter, error) {
+	if sheetName == "" {
+		sheetName = defaultExportSheetName
+	}
+	if name := []rune(sheetName); len(name) > 31 {
+		sheetName = string(name[:31])
+	}
+	var escapedName bytes.Buffer
+	xml.EscapeText(&escapedName, []byte(sheetName))
+
+	x := &xlsxExportWriter{w: w, zip: zip.NewWriter(w), timeFormat: timeFormat, allowFormula: allowFormula}
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escapedName.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, part := range parts {
+		f, err := x.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x.sheet = sheet
+	_, err = io.WriteString(x.sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x, err
+}
+
+func (x *xlsxExportWriter) WriteRow(values ...any) error {
+	if x.closed {
+		return errors.New("xlsx export writer already closed")
+	}
+	x.rowNum++
+	x.buf.Reset()
+	x.buf.WriteString(`<row r="` + strconv.Itoa(x.rowNum) + `">`)
+	for _, value := range values {
+		str, numeric := formatExportValue(value, x.timeFormat)
+		switch {
+		case str == "":
+			x.buf.WriteString(`<c/>`)
+		case numeric:
+			x.buf.WriteString(`<c><v>` + str + `</v></c>`)
+		default:
+			if !x.allowFormula {
+				str = escapeExportFormula(str)
+			}
+			x.buf.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(&x.buf, []byte(str))
+			x.buf.WriteString(`</t></is></c>`)
+		}
+	}
+	x.buf.WriteString(`</row>`)
+	_, err := x.sheet.Write(x.buf.Bytes())
+	return err
+}
+
+func (x *xlsxExportWriter) Flush() error {
+	if err := x.zip.Flush(); err != nil {
+		return err
+	}
+	return flushUnderlying(x.w)
+}
+
+func (x *xlsxExportWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	x.closed = true
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := x.zip.Close(); err != nil {
+		return err
+	}
+	return flushUnderlying(x.w)
+}
@@ -0,0 +1,171 @@
+package candihelper
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exportTestUser struct {
+	ID        int        `json:"id" export:"ID"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email" export:"-"`
+	Balance   float64    `json:"balance" export:"Balance"`
+	CreatedAt time.Time  `json:"createdAt" export:"Created At"`
+	DeletedAt *time.Time `json:"deletedAt"`
+	internal  string
+}
+
+func exportTestSource(users ...exportTestUser) func(context.Context, func(exportTestUser) error) error {
+	return func(ctx context.Context, yield func(exportTestUser) error) error {
+		for _, u := range users {
+			if err := yield(u); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestExportRows(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []exportTestUser{
+		{ID: 1, Name: "agung", Email: "a@mail.com", Balance: 10.5, CreatedAt: createdAt},
+		{ID: 2, Name: `dwi, "kurnia"`, Balance: 7, CreatedAt: createdAt, DeletedAt: &createdAt},
+	}
+
+	assert.Equal(t, []string{"ID", "name", "Balance", "Created At", "deletedAt"}, ExportHeader[exportTestUser]())
+
+	var buf bytes.Buffer
+	count, err := ExportRows(ctx, &buf, ExportFormatCSV, exportTestSource(users...), SetExportTimeFormat(time.DateOnly))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "ID,name,Balance,Created At,deletedAt\n"+
+		"1,agung,10.5,2024-01-02,\n"+
+		"2,\"dwi, \"\"kurnia\"\"\",7,2024-01-02,2024-01-02\n", buf.String())
+
+	buf.Reset()
+	count, err = ExportRows(ctx, &buf, ExportFormatXLSX, exportTestSource(users...), SetExportSheetName("Users & Co"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	assert.Contains(t, files["xl/workbook.xml"], `name="Users &amp; Co"`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<row r="2"><c><v>1</v></c><c t="inlineStr"><is><t xml:space="preserve">agung</t></is></c><c><v>10.5</v></c>`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `dwi, &#34;kurnia&#34;`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `</row></sheetData></worksheet>`)
+
+	_, err = ExportRows(ctx, &buf, "pdf", exportTestSource())
+	assert.Error(t, err)
+}
+
+func TestExportChannel(t *testing.T) {
+	ch := make(chan []any, 2)
+	ch <- []any{"a", 1}
+	ch <- []any{"b", nil}
+	close(ch)
+
+	var buf bytes.Buffer
+	count, err := ExportChannel(context.Background(), &buf, ExportFormatCSV, ch, SetExportHeader("key", "value"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "key,value\na,1\nb,\n", buf.String())
+}
+
+func TestExportFormulaInjection(t *testing.T) {
+	ctx := context.Background()
+	row := func() <-chan []any {
+		ch := make(chan []any, 1)
+		ch <- []any{"=HYPERLINK(\"http://evil\")", "@SUM(A1)", "+1", "\tcmd", -5, "safe"}
+		close(ch)
+		return ch
+	}
+
+	var buf bytes.Buffer
+	_, err := ExportChannel(ctx, &buf, ExportFormatCSV, row(), SetExportWithoutHeader())
+	assert.NoError(t, err)
+	assert.Equal(t, "\"'=HYPERLINK(\"\"http://evil\"\")\",'@SUM(A1),'+1,'\tcmd,-5,safe\n", buf.String())
+
+	buf.Reset()
+	_, err = ExportChannel(ctx, &buf, ExportFormatCSV, row(), SetExportWithoutHeader(), SetExportAllowFormula())
+	assert.NoError(t, err)
+	assert.Equal(t, "\"=HYPERLINK(\"\"http://evil\"\")\",@SUM(A1),+1,\"\tcmd\",-5,safe\n", buf.String())
+
+	buf.Reset()
+	sheetName := strings.Repeat("é", 40)
+	_, err = ExportChannel(ctx, &buf, ExportFormatXLSX, row(), SetExportWithoutHeader(), SetExportSheetName(sheetName))
+	assert.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	assert.Contains(t, files["xl/workbook.xml"], `name="`+strings.Repeat("é", 31)+`"`, "sheet name truncated by rune")
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<t xml:space="preserve">&#39;@SUM(A1)</t>`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c><v>-5</v></c>`)
+}
+
+func TestHTTPExport(t *testing.T) {
+	rec := httptest.NewRecorder()
+	count, err := HTTPExport(context.Background(), rec, "users", ExportFormatCSV,
+		exportTestSource(exportTestUser{ID: 1}, exportTestUser{ID: 2}, exportTestUser{ID: 3}), SetExportFlushEvery(2), SetExportWithoutHeader())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, `attachment; filename="users.csv"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "1,,0,,\n2,,0,,\n3,,0,,\n", rec.Body.String())
+}
+
+type exportTestStorage struct {
+	data        map[string][]byte
+	contentType string
+}
+
+func (s *exportTestStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.data[key], s.contentType = b, contentType
+	return nil
+}
+
+func TestExportToStorage(t *testing.T) {
+	storage := &exportTestStorage{data: map[string][]byte{}}
+	count, err := ExportToStorage(context.Background(), storage, "exports/users.csv", ExportFormatCSV,
+		exportTestSource(exportTestUser{ID: 1, Name: "agung"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "ID,name,Balance,Created At,deletedAt\n1,agung,0,,\n", string(storage.data["exports/users.csv"]))
+	assert.Equal(t, ExportFormatCSV.ContentType(), storage.contentType)
+
+	errSource := errors.New("source failed")
+	_, err = ExportToStorage(context.Background(), storage, "exports/failed.csv", ExportFormatCSV,
+		func(ctx context.Context, yield func(exportTestUser) error) error { return errSource })
+	assert.ErrorIs(t, err, errSource)
+	assert.NotContains(t, storage.data, "exports/failed.csv")
+}
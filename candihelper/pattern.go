@@ -0,0 +1,40 @@
+package candihelper
+
+import (
+	"path"
+	"sort"
+)
+
+// PatternMatcher match name with glob pattern (see path.Match), longest pattern is matched first
+type PatternMatcher[V any] struct {
+	patterns []string
+	values   map[string]V
+}
+
+// NewPatternMatcher create pattern matcher from map of glob pattern and value
+func NewPatternMatcher[V any](values map[string]V) *PatternMatcher[V] {
+	m := &PatternMatcher[V]{values: values}
+	for pattern := range values {
+		m.patterns = append(m.patterns, pattern)
+	}
+	sort.Slice(m.patterns, func(i, j int) bool {
+		if len(m.patterns[i]) != len(m.patterns[j]) {
+			return len(m.patterns[i]) > len(m.patterns[j])
+		}
+		return m.patterns[i] < m.patterns[j]
+	})
+	return m
+}
+
+// Match value of longest pattern matched with name, false if no pattern matched
+func (m *PatternMatcher[V]) Match(name string) (value V, ok bool) {
+	if m == nil {
+		return value, false
+	}
+	for _, pattern := range m.patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return m.values[pattern], true
+		}
+	}
+	return value, false
+}
@@ -0,0 +1,25 @@
+package candihelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternMatcher(t *testing.T) {
+	m := NewPatternMatcher(map[string]int64{"POST /v1/*": 1, "POST /v1/upload/*": 2, "* /health": 0})
+
+	value, ok := m.Match("POST /v1/upload/avatar")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), value)
+	value, _ = m.Match("POST /v1/order")
+	assert.Equal(t, int64(1), value)
+	_, ok = m.Match("GET /health")
+	assert.True(t, ok)
+	_, ok = m.Match("GET /v1/order")
+	assert.False(t, ok)
+
+	var nilMatcher *PatternMatcher[int]
+	_, ok = nilMatcher.Match("/any")
+	assert.False(t, ok)
+}
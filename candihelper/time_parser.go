@@ -30,6 +30,11 @@ const (
 
 // ParseDurationExpression with input format HH:mm:ss
 func ParseDurationExpression(t string) (duration, nextDuration time.Duration, err error) {
+	return ParseDurationExpressionFrom(t, time.Now())
+}
+
+// ParseDurationExpressionFrom with input format HH:mm:ss, first duration calculated from given time
+func ParseDurationExpressionFrom(t string, now time.Time) (duration, nextDuration time.Duration, err error) {
 	interval, err := time.ParseDuration(t)
 	if err == nil {
 		return interval, 0, nil
@@ -86,7 +91,6 @@ func ParseDurationExpression(t string) (duration, nextDuration time.Duration, er
 		}
 	}
 
-	now := time.Now()
 	atTime := time.Date(now.Year(), now.Month(), now.Day(), hour, min, sec, 0, now.Location())
 	if now.Before(atTime) {
 		duration = atTime.Sub(now)
@@ -0,0 +1,200 @@
+package candihelper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	defaultUploadMaxFileSize    = 10 << 20
+	defaultUploadMaxRequestSize = 32 << 20
+	defaultUploadMaxMemory      = 8 << 20
+)
+
+var (
+	// ErrUploadTooLarge uploaded file or request body exceed size limit
+	ErrUploadTooLarge = errors.New("upload: file too large")
+	// ErrUploadTypeNotAllowed content type of uploaded file is not allowed
+	ErrUploadTypeNotAllowed = errors.New("upload: file type not allowed")
+	// ErrUploadMissingFile file field not found in multipart request
+	ErrUploadMissingFile = errors.New("upload: missing file")
+)
+
+type (
+	// UploadOption limit of multipart upload
+	UploadOption struct {
+		maxFileSize    int64
+		maxRequestSize int64
+		maxMemory      int64
+		allowedTypes   []string
+	}
+
+	// UploadOptionFunc option func type
+	UploadOptionFunc func(*UploadOption)
+)
+
+// SetUploadMaxFileSize option func, max size of each file (default 10MB)
+func SetUploadMaxFileSize(size int64) UploadOptionFunc {
+	return func(o *UploadOption) {
+		o.maxFileSize = size
+	}
+}
+
+// SetUploadMaxRequestSize option func, max size of whole multipart request body (default 32MB)
+func SetUploadMaxRequestSize(size int64) UploadOptionFunc {
+	return func(o *UploadOption) {
+		o.maxRequestSize = size
+	}
+}
+
+// SetUploadMaxMemory option func, max size of request kept in memory, remaining file part is stored in
+// temporary file (default 8MB). Temporary file is removed with RemoveUploadTempFiles
+func SetUploadMaxMemory(size int64) UploadOptionFunc {
+	return func(o *UploadOption) {
+		o.maxMemory = size
+	}
+}
+
+// SetUploadAllowedTypes option func, allowed content type of file support wildcard (example: "image/*",
+// "application/pdf"), default allow all type. Content type is detected from file content, not from client header
+func SetUploadAllowedTypes(contentTypes ...string) UploadOptionFunc {
+	return func(o *UploadOption) {
+		o.allowedTypes = contentTypes
+	}
+}
+
+// NewUploadOption create upload option with default limit
+func NewUploadOption(opts ...UploadOptionFunc) *UploadOption {
+	o := &UploadOption{
+		maxFileSize: defaultUploadMaxFileSize, maxRequestSize: defaultUploadMaxRequestSize, maxMemory: defaultUploadMaxMemory,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UploadFile validated uploaded file of multipart request, implement GraphQL scalar "Upload"
+// (declare "scalar Upload" in schema and use UploadFile as resolver argument type)
+type UploadFile struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+
+	header *multipart.FileHeader
+}
+
+// Open uploaded file content
+func (f *UploadFile) Open() (multipart.File, error) {
+	if f.header == nil {
+		return nil, ErrUploadMissingFile
+	}
+	return f.header.Open()
+}
+
+// ImplementsGraphQLType implement GraphQL scalar "Upload"
+func (f *UploadFile) ImplementsGraphQLType(name string) bool {
+	return name == "Upload"
+}
+
+// UnmarshalGraphQL set file from variable of GraphQL multipart request
+func (f *UploadFile) UnmarshalGraphQL(input any) error {
+	switch file := input.(type) {
+	case *UploadFile:
+		*f = *file
+		return nil
+	case UploadFile:
+		*f = file
+		return nil
+	}
+	return errors.New("upload: Upload value must be sent with multipart request")
+}
+
+// ParseMultipartUpload parse multipart request body with size limit of option, call RemoveUploadTempFiles
+// after request finished
+func ParseMultipartUpload(w http.ResponseWriter, req *http.Request, opt *UploadOption) (*multipart.Form, error) {
+	if opt.maxRequestSize > 0 {
+		req.Body = http.MaxBytesReader(w, req.Body, opt.maxRequestSize)
+	}
+	if err := req.ParseMultipartForm(opt.maxMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, fmt.Errorf("%w: request body exceed %d bytes", ErrUploadTooLarge, maxBytesErr.Limit)
+		}
+		return nil, err
+	}
+	return req.MultipartForm, nil
+}
+
+// NewUploadFile validate size and content type of file part with option
+func NewUploadFile(header *multipart.FileHeader, opt *UploadOption) (*UploadFile, error) {
+	if opt.maxFileSize > 0 && header.Size > opt.maxFileSize {
+		return nil, fmt.Errorf("%w: %s exceed %d bytes", ErrUploadTooLarge, header.Filename, opt.maxFileSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(sniff[:n])
+	if contentType == HeaderMIMEOctetStream && header.Header.Get(HeaderContentType) != "" {
+		// unknown binary format, trust declared type
+		contentType = header.Header.Get(HeaderContentType)
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	if !uploadTypeAllowed(contentType, opt.allowedTypes) {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrUploadTypeNotAllowed, header.Filename, contentType)
+	}
+
+	return &UploadFile{Filename: path.Base(strings.ReplaceAll(header.Filename, `\`, "/")), ContentType: contentType, Size: header.Size, header: header}, nil
+}
+
+// FormUploadFile parse multipart request and get validated file of form field for REST upload handler,
+// call RemoveUploadTempFiles after request finished
+func FormUploadFile(w http.ResponseWriter, req *http.Request, field string, opts ...UploadOptionFunc) (*UploadFile, error) {
+	opt := NewUploadOption(opts...)
+	form, err := ParseMultipartUpload(w, req, opt)
+	if err != nil {
+		return nil, err
+	}
+	files := form.File[field]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUploadMissingFile, field)
+	}
+	return NewUploadFile(files[0], opt)
+}
+
+// RemoveUploadTempFiles remove temporary files of parsed multipart form
+func RemoveUploadTempFiles(req *http.Request) error {
+	if req.MultipartForm == nil {
+		return nil
+	}
+	return req.MultipartForm.RemoveAll()
+}
+
+func uploadTypeAllowed(contentType string, allowedTypes []string) bool {
+	if len(allowedTypes) == 0 {
+		return true
+	}
+	for _, pattern := range allowedTypes {
+		if ok, _ := path.Match(pattern, contentType); ok || pattern == contentType {
+			return true
+		}
+	}
+	return false
+}
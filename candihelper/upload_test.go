@@ -0,0 +1,64 @@
+package candihelper
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUploadRequest(t *testing.T, field, filename string, content []byte) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(field, filename)
+	require.NoError(t, err)
+	part.Write(content)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set(HeaderContentType, form.FormDataContentType())
+	return req
+}
+
+func TestFormUploadFile(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+	req := newUploadRequest(t, "avatar", `C:\photos\me.png`, png)
+	defer RemoveUploadTempFiles(req)
+	file, err := FormUploadFile(httptest.NewRecorder(), req, "avatar", SetUploadAllowedTypes("image/*"))
+	require.NoError(t, err)
+	assert.Equal(t, "me.png", file.Filename)
+	assert.Equal(t, "image/png", file.ContentType)
+	assert.Equal(t, int64(len(png)), file.Size)
+	f, err := file.Open()
+	require.NoError(t, err)
+	content, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, png, content)
+
+	_, err = FormUploadFile(httptest.NewRecorder(), newUploadRequest(t, "avatar", "me.png", []byte("<html><script>")), "avatar",
+		SetUploadAllowedTypes("image/*"))
+	assert.ErrorIs(t, err, ErrUploadTypeNotAllowed, "content type is detected from content")
+
+	_, err = FormUploadFile(httptest.NewRecorder(), newUploadRequest(t, "avatar", "me.png", png), "avatar", SetUploadMaxFileSize(10))
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	_, err = FormUploadFile(httptest.NewRecorder(), newUploadRequest(t, "avatar", "me.png", png), "avatar", SetUploadMaxRequestSize(64))
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	_, err = FormUploadFile(httptest.NewRecorder(), newUploadRequest(t, "avatar", "me.png", png), "document")
+	assert.ErrorIs(t, err, ErrUploadMissingFile)
+}
+
+func TestUploadFileUnmarshalGraphQL(t *testing.T) {
+	var file UploadFile
+	assert.True(t, file.ImplementsGraphQLType("Upload"))
+	require.NoError(t, file.UnmarshalGraphQL(&UploadFile{Filename: "a.txt", Size: 1}))
+	assert.Equal(t, "a.txt", file.Filename)
+	assert.Error(t, file.UnmarshalGraphQL("a.txt"))
+}
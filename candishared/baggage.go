@@ -0,0 +1,173 @@
+package candishared
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// HeaderBaggage header (HTTP header, gRPC metadata, message header and task queue job metadata) of propagated
+	// baggage, encoded in W3C baggage format "key1=value1,key2=value2"
+	HeaderBaggage = "baggage"
+
+	// BaggageKeyTenant baggage key of tenant id, rehydrated with SetTenantToContext on consumer side
+	BaggageKeyTenant = "tenant_id"
+	// BaggageKeyLocale baggage key of locale, rehydrated to ContextKeyLocale on consumer side
+	BaggageKeyLocale = "locale"
+
+	// MaxBaggageSize max encoded baggage size, item exceeding the limit is not propagated
+	MaxBaggageSize = 8192
+)
+
+// SetBaggage set baggage item to context, baggage is propagated automatically to outgoing HTTP request, gRPC call,
+// published message and task queue job, and rehydrated in the consumer context
+func SetBaggage(ctx context.Context, key, value string) context.Context {
+	return SetBaggageItems(ctx, map[string]string{key: value})
+}
+
+// SetBaggageItems set multiple baggage item to context, empty value remove item
+func SetBaggageItems(ctx context.Context, items map[string]string) context.Context {
+	baggage := maps.Clone(getBaggage(ctx))
+	if baggage == nil {
+		baggage = make(map[string]string, len(items))
+	}
+	for key, value := range items {
+		if key = strings.TrimSpace(key); key == "" {
+			continue
+		}
+		if value == "" {
+			delete(baggage, key)
+			continue
+		}
+		baggage[key] = value
+	}
+	return SetToContext(ctx, ContextKeyBaggage, baggage)
+}
+
+// GetBaggage get baggage item from context, empty if not set
+func GetBaggage(ctx context.Context, key string) string {
+	return getBaggage(ctx)[key]
+}
+
+// GetAllBaggage get copy of all baggage item from context
+func GetAllBaggage(ctx context.Context) map[string]string {
+	return maps.Clone(getBaggage(ctx))
+}
+
+func getBaggage(ctx context.Context) map[string]string {
+	baggage, _ := GetValueFromContext(ctx, ContextKeyBaggage).(map[string]string)
+	return baggage
+}
+
+// EncodeBaggage encode baggage item to W3C baggage format, sorted by key
+func EncodeBaggage(items map[string]string) string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		member := url.QueryEscape(key) + "=" + url.QueryEscape(items[key])
+		if sb.Len()+len(member)+1 > MaxBaggageSize {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(member)
+	}
+	return sb.String()
+}
+
+// DecodeBaggage decode W3C baggage format, member property (after ";") is ignored
+func DecodeBaggage(s string) map[string]string {
+	items := make(map[string]string)
+	for _, member := range strings.Split(s, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		if !ok {
+			continue
+		}
+		key, err := url.QueryUnescape(strings.TrimSpace(key))
+		if err != nil || key == "" {
+			continue
+		}
+		if value, err = url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			items[key] = value
+		}
+	}
+	return items
+}
+
+// InjectBaggage set baggage header from context (tenant id and locale in context is included) to outgoing header
+func InjectBaggage(ctx context.Context, header map[string]string) {
+	if encoded := encodeContextBaggage(ctx); encoded != "" {
+		header[HeaderBaggage] = encoded
+	}
+}
+
+// InjectBaggageMessageHeader set baggage header from context to published message header, existing header is kept
+func InjectBaggageMessageHeader(ctx context.Context, header map[string]any) {
+	if _, ok := header[HeaderBaggage]; ok {
+		return
+	}
+	if encoded := encodeContextBaggage(ctx); encoded != "" {
+		header[HeaderBaggage] = encoded
+	}
+}
+
+func encodeContextBaggage(ctx context.Context) string {
+	baggage := getBaggage(ctx)
+	tenantID := GetTenantFromContext(ctx)
+	locale, _ := GetValueFromContext(ctx, ContextKeyLocale).(string)
+	if (tenantID != "" && baggage[BaggageKeyTenant] == "") || (locale != "" && baggage[BaggageKeyLocale] == "") {
+		baggage = maps.Clone(baggage)
+		if baggage == nil {
+			baggage = make(map[string]string, 2)
+		}
+		if tenantID != "" && baggage[BaggageKeyTenant] == "" {
+			baggage[BaggageKeyTenant] = tenantID
+		}
+		if locale != "" && baggage[BaggageKeyLocale] == "" {
+			baggage[BaggageKeyLocale] = locale
+		}
+	}
+	if len(baggage) == 0 {
+		return ""
+	}
+	return EncodeBaggage(baggage)
+}
+
+// ExtractBaggage rehydrate baggage from incoming header (key is case insensitive) to context, tenant id and locale
+// baggage item is also set to context when not set yet
+func ExtractBaggage(ctx context.Context, header map[string]string) context.Context {
+	encoded, ok := header[HeaderBaggage]
+	if !ok {
+		for key, value := range header {
+			if strings.EqualFold(key, HeaderBaggage) {
+				encoded = value
+				break
+			}
+		}
+	}
+	if encoded == "" {
+		return ctx
+	}
+	items := DecodeBaggage(encoded)
+	if len(items) == 0 {
+		return ctx
+	}
+	ctx = SetBaggageItems(ctx, items)
+	if tenantID := items[BaggageKeyTenant]; tenantID != "" && GetTenantFromContext(ctx) == "" {
+		ctx = SetTenantToContext(ctx, tenantID)
+	}
+	if locale := items[BaggageKeyLocale]; locale != "" && GetValueFromContext(ctx, ContextKeyLocale) == nil {
+		ctx = SetToContext(ctx, ContextKeyLocale, locale)
+	}
+	return ctx
+}
@@ -0,0 +1,35 @@
+package candishared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaggage(t *testing.T) {
+	ctx := SetBaggage(context.Background(), "feature_flags", "new-checkout,dark-mode")
+	ctx = SetBaggageItems(ctx, map[string]string{"debug": "true", "empty": ""})
+	assert.Equal(t, "new-checkout,dark-mode", GetBaggage(ctx, "feature_flags"))
+	assert.Equal(t, map[string]string{"feature_flags": "new-checkout,dark-mode", "debug": "true"}, GetAllBaggage(ctx))
+
+	child := SetBaggage(ctx, "debug", "")
+	assert.Equal(t, "", GetBaggage(child, "debug"))
+	assert.Equal(t, "true", GetBaggage(ctx, "debug"), "parent context must not be modified")
+
+	ctx = SetTenantToContext(ctx, "tenant-a")
+	header := map[string]string{}
+	InjectBaggage(ctx, header)
+	assert.Equal(t, "debug=true,feature_flags=new-checkout%2Cdark-mode,tenant_id=tenant-a", header[HeaderBaggage])
+
+	msgHeader := map[string]any{}
+	InjectBaggageMessageHeader(ctx, msgHeader)
+	assert.Equal(t, header[HeaderBaggage], msgHeader[HeaderBaggage])
+
+	consumerCtx := ExtractBaggage(context.Background(), map[string]string{"Baggage": header[HeaderBaggage]})
+	assert.Equal(t, "new-checkout,dark-mode", GetBaggage(consumerCtx, "feature_flags"))
+	assert.Equal(t, "tenant-a", GetTenantFromContext(consumerCtx))
+
+	assert.Equal(t, map[string]string{"a": "1", "b": "x y"}, DecodeBaggage("a=1;prop=p, b=x%20y,invalid,=2"))
+	assert.Equal(t, context.Background(), ExtractBaggage(context.Background(), nil))
+}
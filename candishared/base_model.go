@@ -0,0 +1,121 @@
+package candishared
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+)
+
+const (
+	// SQLConditionNotDeleted where condition of not soft deleted row
+	SQLConditionNotDeleted = "deleted_at IS NULL"
+	// SQLConditionVersion where condition of optimistic locking version
+	SQLConditionVersion = "version = ?"
+)
+
+// ErrVersionConflict data has been modified by another process (optimistic locking)
+var ErrVersionConflict = candierrors.Conflict("data has been modified, please reload and try again")
+
+// BaseModel standard model with audit columns, soft delete and optimistic locking version, embed to GORM/SQL/Mongo model
+type BaseModel struct {
+	CreatedAt time.Time  `gorm:"column:created_at" sql:"created_at" bson:"created_at" json:"created_at" ignoreUpdate:"true"`
+	CreatedBy string     `gorm:"column:created_by" sql:"created_by" bson:"created_by" json:"created_by" ignoreUpdate:"true"`
+	UpdatedAt time.Time  `gorm:"column:updated_at" sql:"updated_at" bson:"updated_at" json:"updated_at"`
+	UpdatedBy string     `gorm:"column:updated_by" sql:"updated_by" bson:"updated_by" json:"updated_by"`
+	DeletedAt *time.Time `gorm:"column:deleted_at;default:null" sql:"deleted_at" bson:"deleted_at" json:"deleted_at,omitempty"`
+	DeletedBy string     `gorm:"column:deleted_by" sql:"deleted_by" bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version   int64      `gorm:"column:version" sql:"version" bson:"version" json:"version"`
+}
+
+// BeforeCreate set created and updated audit columns with actor (subject of token claim) from context and initial version
+func (m *BaseModel) BeforeCreate(ctx context.Context) {
+	now, actor := time.Now(), ActorFromContext(ctx)
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
+	m.UpdatedAt, m.CreatedBy, m.UpdatedBy = now, actor, actor
+	m.Version = 1
+}
+
+// BeforeUpdate set updated audit columns and increment version, return previous version for update condition
+// (example: db.Where(candishared.SQLConditionVersion, prevVersion))
+func (m *BaseModel) BeforeUpdate(ctx context.Context) (prevVersion int64) {
+	prevVersion = m.Version
+	m.UpdatedAt, m.UpdatedBy = time.Now(), ActorFromContext(ctx)
+	m.Version++
+	return prevVersion
+}
+
+// SoftDelete mark model as deleted, update with BeforeUpdate for deleted audit columns
+func (m *BaseModel) SoftDelete(ctx context.Context) {
+	now := time.Now()
+	m.DeletedAt, m.DeletedBy = &now, ActorFromContext(ctx)
+}
+
+// Restore unmark soft deleted model
+func (m *BaseModel) Restore() {
+	m.DeletedAt, m.DeletedBy = nil, ""
+}
+
+// IsDeleted check model is soft deleted
+func (m *BaseModel) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// ActorFromContext get actor (subject of token claim) from context
+func ActorFromContext(ctx context.Context) string {
+	if claim, ok := GetValueFromContext(ctx, ContextKeyTokenClaim).(*TokenClaim); ok && claim != nil {
+		return claim.Subject
+	}
+	return ""
+}
+
+// CheckVersionAffected check affected rows of update with version condition, return ErrVersionConflict if no row updated
+func CheckVersionAffected(rowsAffected int64) error {
+	if rowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// GORMQuerier query builder with where clause (example *gorm.DB)
+type GORMQuerier[DB any] interface {
+	Where(query any, args ...any) DB
+}
+
+// GORMScopeNotDeleted soft delete aware query scope, usage: db.Scopes(candishared.GORMScopeNotDeleted[*gorm.DB])
+func GORMScopeNotDeleted[DB GORMQuerier[DB]](db DB) DB {
+	return db.Where(SQLConditionNotDeleted)
+}
+
+// GORMScopeOnlyDeleted query scope of soft deleted row, usage: db.Scopes(candishared.GORMScopeOnlyDeleted[*gorm.DB])
+func GORMScopeOnlyDeleted[DB GORMQuerier[DB]](db DB) DB {
+	return db.Where("deleted_at IS NOT NULL")
+}
+
+// GORMScopeVersion optimistic locking query scope for update with previous version from BeforeUpdate
+func GORMScopeVersion[DB GORMQuerier[DB]](prevVersion int64) func(DB) DB {
+	return func(db DB) DB {
+		return db.Where(SQLConditionVersion, prevVersion)
+	}
+}
+
+// MongoFilterNotDeleted add soft delete condition to mongo filter (bson.M)
+func MongoFilterNotDeleted(filter map[string]any) map[string]any {
+	if filter == nil {
+		filter = make(map[string]any)
+	}
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// MongoFilterVersion add optimistic locking condition with previous version from BeforeUpdate to mongo filter (bson.M),
+// check MatchedCount of update result with CheckVersionAffected
+func MongoFilterVersion(filter map[string]any, prevVersion int64) map[string]any {
+	if filter == nil {
+		filter = make(map[string]any)
+	}
+	filter["version"] = prevVersion
+	return filter
+}
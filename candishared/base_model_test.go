@@ -0,0 +1,55 @@
+package candishared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDB struct{ conditions []string }
+
+func (f *fakeDB) Where(query any, args ...any) *fakeDB {
+	f.conditions = append(f.conditions, query.(string))
+	return f
+}
+
+func TestBaseModel(t *testing.T) {
+	type Order struct {
+		ID     int    `gorm:"column:id" json:"id"`
+		Status string `gorm:"column:status" json:"status"`
+		BaseModel
+	}
+
+	ctx := SetToContext(context.Background(), ContextKeyTokenClaim, &TokenClaim{StandardClaims: jwt.StandardClaims{Subject: "user-1"}})
+	var order Order
+	order.BeforeCreate(ctx)
+	assert.Equal(t, "user-1", order.CreatedBy)
+	assert.Equal(t, int64(1), order.Version)
+
+	order.Status = "paid"
+	prevVersion := order.BeforeUpdate(context.Background())
+	assert.Equal(t, int64(1), prevVersion)
+	assert.Equal(t, "", order.UpdatedBy)
+
+	updated := DBUpdateTools{KeyExtractorFunc: DBUpdateGORMExtractorKey}.ToMap(order)
+	assert.Equal(t, int64(2), updated["version"])
+	assert.Equal(t, "paid", updated["status"])
+	assert.Nil(t, updated["deleted_at"])
+	assert.NotContains(t, updated, "created_at")
+	assert.NotContains(t, updated, "created_by")
+
+	order.SoftDelete(ctx)
+	assert.True(t, order.IsDeleted())
+	assert.Equal(t, "user-1", order.DeletedBy)
+	order.Restore()
+	assert.False(t, order.IsDeleted())
+
+	db := GORMScopeVersion[*fakeDB](prevVersion)(GORMScopeNotDeleted(&fakeDB{}))
+	assert.Equal(t, []string{SQLConditionNotDeleted, SQLConditionVersion}, db.conditions)
+	assert.Equal(t, map[string]any{"status": "paid", "deleted_at": nil, "version": int64(1)},
+		MongoFilterVersion(MongoFilterNotDeleted(map[string]any{"status": "paid"}), prevVersion))
+	assert.ErrorIs(t, CheckVersionAffected(0), ErrVersionConflict)
+	assert.NoError(t, CheckVersionAffected(1))
+}
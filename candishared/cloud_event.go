@@ -0,0 +1,274 @@
+package candishared
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/google/uuid"
+)
+
+const (
+	// CloudEventsSpecVersion supported CloudEvents spec version
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType content type of CloudEvents structured mode in JSON format
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// CloudEventsKafkaHeaderPrefix header prefix of CloudEvents binary mode in Kafka protocol binding
+	CloudEventsKafkaHeaderPrefix = "ce_"
+	// CloudEventsAMQPHeaderPrefix header prefix of CloudEvents binary mode in AMQP protocol binding
+	CloudEventsAMQPHeaderPrefix = "cloudEvents_"
+	// CloudEventsHTTPHeaderPrefix header prefix of CloudEvents binary mode in HTTP protocol binding
+	CloudEventsHTTPHeaderPrefix = "ce-"
+)
+
+// CloudEventMode content mode of CloudEvents message
+type CloudEventMode int
+
+const (
+	// CloudEventModeBinary event attributes in message header, message body is event data
+	CloudEventModeBinary CloudEventMode = iota
+	// CloudEventModeStructured event attributes and data encoded in message body (application/cloudevents+json)
+	CloudEventModeStructured
+)
+
+// CloudEvent CloudEvents 1.0 context attributes
+type CloudEvent struct {
+	ID              string
+	Source          string
+	Type            string
+	SpecVersion     string
+	Subject         string
+	DataContentType string
+	DataSchema      string
+	Time            time.Time
+	// Extensions extension context attributes (name must be lowercase alphanumeric)
+	Extensions map[string]string
+}
+
+var cloudEventHeaderPrefixes = []string{
+	CloudEventsKafkaHeaderPrefix, CloudEventsAMQPHeaderPrefix, CloudEventsHTTPHeaderPrefix, "cloudEvents:",
+}
+
+func (c *CloudEvent) setDefault() {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	if c.SpecVersion == "" {
+		c.SpecVersion = CloudEventsSpecVersion
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+}
+
+// Validate required attributes
+func (c *CloudEvent) Validate() error {
+	if c.ID == "" || c.Source == "" || c.Type == "" {
+		return errors.New("cloudevents: id, source and type attribute cannot empty")
+	}
+	if c.SpecVersion != CloudEventsSpecVersion {
+		return errors.New("cloudevents: unsupported specversion " + c.SpecVersion)
+	}
+	return nil
+}
+
+// attributes context attributes in lowercase name
+func (c *CloudEvent) attributes() map[string]string {
+	attrs := map[string]string{
+		"id": c.ID, "source": c.Source, "type": c.Type, "specversion": c.SpecVersion,
+	}
+	for k, v := range map[string]string{"subject": c.Subject, "datacontenttype": c.DataContentType, "dataschema": c.DataSchema} {
+		if v != "" {
+			attrs[k] = v
+		}
+	}
+	if !c.Time.IsZero() {
+		attrs["time"] = c.Time.Format(time.RFC3339Nano)
+	}
+	for k, v := range c.Extensions {
+		attrs[strings.ToLower(k)] = v
+	}
+	return attrs
+}
+
+func (c *CloudEvent) setAttribute(name, value string) {
+	switch name {
+	case "id":
+		c.ID = value
+	case "source":
+		c.Source = value
+	case "type":
+		c.Type = value
+	case "specversion":
+		c.SpecVersion = value
+	case "subject":
+		c.Subject = value
+	case "datacontenttype":
+		c.DataContentType = value
+	case "dataschema":
+		c.DataSchema = value
+	case "time":
+		c.Time, _ = time.Parse(time.RFC3339Nano, value)
+	default:
+		if c.Extensions == nil {
+			c.Extensions = make(map[string]string)
+		}
+		c.Extensions[name] = value
+	}
+}
+
+// BinaryHeader context attributes as message header with protocol binding prefix
+// (datacontenttype is mapped to content type header by protocol)
+func (c *CloudEvent) BinaryHeader(prefix string) map[string]string {
+	header := make(map[string]string)
+	for k, v := range c.attributes() {
+		if k != "datacontenttype" {
+			header[prefix+k] = v
+		}
+	}
+	return header
+}
+
+// Structured encode context attributes and data in JSON format (structured mode), JSON data is embedded as is,
+// other data is encoded in data_base64
+func (c *CloudEvent) Structured(data []byte) ([]byte, error) {
+	envelope := make(map[string]any)
+	for k, v := range c.attributes() {
+		envelope[k] = v
+	}
+	if len(data) > 0 {
+		if isJSONContentType(c.DataContentType) && json.Valid(data) {
+			envelope["data"] = json.RawMessage(data)
+		} else {
+			envelope["data_base64"] = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	return json.Marshal(envelope)
+}
+
+// ParseCloudEvent parse CloudEvents message in binary or structured mode from message header and body,
+// return event data (message body in binary mode, data attribute in structured mode), ok is false if message is not CloudEvents
+func ParseCloudEvent(header map[string]string, message []byte) (event *CloudEvent, data []byte, ok bool) {
+	contentType := ""
+	for k, v := range header {
+		if strings.EqualFold(k, "content-type") || strings.EqualFold(k, "content_type") {
+			contentType = v
+		}
+	}
+
+	if strings.HasPrefix(contentType, CloudEventsContentType) {
+		var envelope map[string]json.RawMessage
+		if json.Unmarshal(message, &envelope) != nil {
+			return nil, nil, false
+		}
+		event = &CloudEvent{}
+		for k, v := range envelope {
+			switch k {
+			case "data":
+				data = v
+			case "data_base64":
+				var encoded string
+				json.Unmarshal(v, &encoded)
+				data, _ = base64.StdEncoding.DecodeString(encoded)
+			default:
+				var value any
+				json.Unmarshal(v, &value)
+				if s, isString := value.(string); isString {
+					event.setAttribute(k, s)
+				} else {
+					event.setAttribute(k, string(v))
+				}
+			}
+		}
+		return event, data, event.Validate() == nil
+	}
+
+	for k, v := range header {
+		for _, prefix := range cloudEventHeaderPrefixes {
+			if len(k) > len(prefix) && strings.EqualFold(k[:len(prefix)], prefix) {
+				if event == nil {
+					event = &CloudEvent{}
+				}
+				event.setAttribute(strings.ToLower(k[len(prefix):]), v)
+				break
+			}
+		}
+	}
+	if event == nil || event.Validate() != nil {
+		return nil, nil, false
+	}
+	if event.DataContentType == "" {
+		event.DataContentType = contentType
+	}
+	return event, message, true
+}
+
+// ParseCloudEventHTTPRequest parse CloudEvents from HTTP request (binary or structured mode)
+func ParseCloudEventHTTPRequest(req *http.Request) (event *CloudEvent, data []byte, err error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	event, data, ok := ParseCloudEventHTTP(req.Header, body)
+	if !ok {
+		return nil, body, errors.New("cloudevents: request is not valid cloudevents")
+	}
+	return event, data, nil
+}
+
+// ParseCloudEventHTTP parse CloudEvents from HTTP header and read body (binary or structured mode),
+// ok is false if request is not CloudEvents
+func ParseCloudEventHTTP(httpHeader http.Header, body []byte) (event *CloudEvent, data []byte, ok bool) {
+	header := make(map[string]string, len(httpHeader))
+	for k := range httpHeader {
+		header[k] = httpHeader.Get(k)
+	}
+	return ParseCloudEvent(header, body)
+}
+
+// ApplyCloudEvent wrap message in CloudEvents envelope if CloudEvent is set, binary mode attributes is set in header
+// with protocol binding prefix
+func (p *PublisherArgument) ApplyCloudEvent(headerPrefix string) error {
+	if p.CloudEvent == nil {
+		return nil
+	}
+	p.CloudEvent.setDefault()
+	if p.CloudEvent.DataContentType == "" {
+		p.CloudEvent.DataContentType = p.ContentType
+	}
+	if err := p.CloudEvent.Validate(); err != nil {
+		return err
+	}
+	if p.Header == nil {
+		p.Header = make(map[string]any)
+	}
+	if len(p.Message) == 0 && p.Data != nil {
+		p.Message = candihelper.ToBytes(p.Data)
+	}
+
+	if p.CloudEventMode == CloudEventModeStructured {
+		message, err := p.CloudEvent.Structured(p.Message)
+		if err != nil {
+			return err
+		}
+		p.Message, p.ContentType = message, CloudEventsContentType
+	} else {
+		for k, v := range p.CloudEvent.BinaryHeader(headerPrefix) {
+			p.Header[k] = v
+		}
+	}
+	if headerPrefix == CloudEventsKafkaHeaderPrefix && p.ContentType != "" {
+		p.Header["content-type"] = p.ContentType
+	}
+	return nil
+}
+
+func isJSONContentType(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "application/json") || strings.HasSuffix(strings.Split(contentType, ";")[0], "+json")
+}
@@ -0,0 +1,69 @@
+package candishared
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudEvent(t *testing.T) {
+	t.Run("Binary mode", func(t *testing.T) {
+		args := &PublisherArgument{
+			Topic: "order-events", Message: []byte(`{"id":"1"}`), ContentType: "application/json",
+			CloudEvent: &CloudEvent{Source: "order-service", Type: "order.created", Extensions: map[string]string{"tenant": "acme"}},
+		}
+		require.NoError(t, args.ApplyCloudEvent(CloudEventsKafkaHeaderPrefix))
+		assert.NotEmpty(t, args.CloudEvent.ID)
+
+		header := make(map[string]string)
+		for k, v := range args.Header {
+			header[k] = v.(string)
+		}
+		assert.Equal(t, "order.created", header["ce_type"])
+		assert.Equal(t, "1.0", header["ce_specversion"])
+
+		event, data, ok := ParseCloudEvent(header, args.Message)
+		require.True(t, ok)
+		assert.Equal(t, args.CloudEvent.ID, event.ID)
+		assert.Equal(t, "application/json", event.DataContentType)
+		assert.Equal(t, "acme", event.Extensions["tenant"])
+		assert.Equal(t, `{"id":"1"}`, string(data))
+	})
+
+	t.Run("Structured mode", func(t *testing.T) {
+		args := &PublisherArgument{
+			Topic: "order.created", Message: []byte(`{"id":"1"}`), CloudEventMode: CloudEventModeStructured,
+			CloudEvent: &CloudEvent{Source: "order-service", Type: "order.created"},
+		}
+		require.NoError(t, args.ApplyCloudEvent(CloudEventsAMQPHeaderPrefix))
+		assert.Equal(t, CloudEventsContentType, args.ContentType)
+
+		event, data, ok := ParseCloudEvent(map[string]string{"content-type": args.ContentType}, args.Message)
+		require.True(t, ok)
+		assert.Equal(t, "order-service", event.Source)
+		assert.Equal(t, args.CloudEvent.Time.UnixNano(), event.Time.UnixNano())
+		assert.Equal(t, `{"id":"1"}`, string(data))
+	})
+
+	t.Run("HTTP binary mode", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/events", strings.NewReader("hello"))
+		req.Header.Set("Content-Type", "text/plain")
+		req.Header.Set("Ce-Id", "abc")
+		req.Header.Set("Ce-Source", "knative")
+		req.Header.Set("Ce-Type", "dev.knative.ping")
+		req.Header.Set("Ce-Specversion", "1.0")
+		event, data, err := ParseCloudEventHTTPRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, "dev.knative.ping", event.Type)
+		assert.Equal(t, "text/plain", event.DataContentType)
+		assert.Equal(t, "hello", string(data))
+	})
+
+	t.Run("Not CloudEvents", func(t *testing.T) {
+		_, _, ok := ParseCloudEvent(map[string]string{"ce_id": "1"}, []byte("{}"))
+		assert.False(t, ok)
+	})
+}
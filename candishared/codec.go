@@ -0,0 +1,186 @@
+package candishared
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/candihelper"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec name of builtin payload codec
+const (
+	CodecJSON        = "json"
+	CodecMsgpack     = "msgpack"
+	CodecProto       = "proto"
+	CodecJSONGzip    = "json+gzip"
+	CodecMsgpackGzip = "msgpack+gzip"
+	CodecProtoGzip   = "proto+gzip"
+)
+
+var (
+	// ErrUnknownCodec codec is not registered
+	ErrUnknownCodec = errors.New("codec: unknown codec")
+
+	codecMu  sync.RWMutex
+	codecMap = make(map[string]Codec)
+)
+
+// Codec encode and decode message payload, codec name is sent in message header (X-Payload-Codec)
+// so consumer can decode payload with the same codec
+type Codec interface {
+	Name() string
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+func init() {
+	for _, codec := range []Codec{jsonCodec{}, msgpackCodec{}, protoCodec{}} {
+		RegisterCodec(codec)
+		RegisterCodec(NewGzipCodec(codec))
+	}
+}
+
+// RegisterCodec register codec with codec name, replace registered codec with the same name
+func RegisterCodec(codec Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecMap[strings.ToLower(codec.Name())] = codec
+}
+
+// GetCodec get registered codec by name (case insensitive)
+func GetCodec(name string) (Codec, error) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	codec, ok := codecMap[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, name)
+	}
+	return codec, nil
+}
+
+// GetCodecFromHeader get codec from X-Payload-Codec message header, json codec if header is not set
+func GetCodecFromHeader(header map[string]string) (Codec, error) {
+	if name := codecNameFromHeader(header); name != "" {
+		return GetCodec(name)
+	}
+	return jsonCodec{}, nil
+}
+
+func codecNameFromHeader(header map[string]string) string {
+	for k, v := range header {
+		if strings.EqualFold(k, candihelper.HeaderXPayloadCodec) {
+			return v
+		}
+	}
+	return ""
+}
+
+// ApplyCodec encode Data with Codec (Message is sent as is if set, must be encoded with the codec by caller) and
+// set codec name in header, content type is set with codec content type if empty
+func (p *PublisherArgument) ApplyCodec() error {
+	if p.Codec == "" {
+		return nil
+	}
+	codec, err := GetCodec(p.Codec)
+	if err != nil {
+		return err
+	}
+	if len(p.Message) == 0 && p.Data != nil {
+		if p.Message, err = codec.Marshal(p.Data); err != nil {
+			return fmt.Errorf("codec %s: %w", codec.Name(), err)
+		}
+	}
+	if p.Header == nil {
+		p.Header = make(map[string]any)
+	}
+	p.Header[candihelper.HeaderXPayloadCodec] = codec.Name()
+	if p.ContentType == "" {
+		p.ContentType = codec.ContentType()
+	}
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return CodecJSON }
+func (jsonCodec) ContentType() string                { return candihelper.HeaderMIMEApplicationJSON }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// protoCodec binary proto codec, value must be proto.Message
+type protoCodec struct{}
+
+func (protoCodec) Name() string        { return CodecProto }
+func (protoCodec) ContentType() string { return "application/x-protobuf" }
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("proto codec: %T is not proto message", v)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		// pointer to proto message pointer, example: &payload with payload type *pb.Message
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Pointer {
+			return fmt.Errorf("proto codec: %T is not proto message", v)
+		}
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+		}
+		if msg, ok = rv.Elem().Interface().(proto.Message); !ok {
+			return fmt.Errorf("proto codec: %T is not proto message", v)
+		}
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+type gzipCodec struct {
+	inner Codec
+}
+
+// NewGzipCodec wrap codec with gzip compression, codec name is inner codec name with "+gzip" suffix
+func NewGzipCodec(inner Codec) Codec {
+	return gzipCodec{inner: inner}
+}
+
+func (c gzipCodec) Name() string        { return c.inner.Name() + "+gzip" }
+func (c gzipCodec) ContentType() string { return c.inner.ContentType() }
+func (c gzipCodec) Marshal(v any) ([]byte, error) {
+	data, err := c.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+func (c gzipCodec) Unmarshal(data []byte, v any) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("gzip codec: %w", err)
+	}
+	defer zr.Close()
+	decompressed, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("gzip codec: %w", err)
+	}
+	return c.inner.Unmarshal(decompressed, v)
+}
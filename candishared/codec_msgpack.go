@@ -0,0 +1,351 @@
+package candishared
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// msgpackMaxDepth max nesting of array and map when decoding, deeper data is rejected to prevent stack exhaustion
+const msgpackMaxDepth = 100
+
+var errMsgpackShortBuffer = errors.New("msgpack codec: unexpected end of data")
+
+// msgpackCodec JSON-compatible MessagePack codec, value is encoded with the json representation (json struct tag and
+// json.Marshaler is used) so message can be decoded to the same type as json codec. Decoded message from other encoder
+// is converted to json before unmarshal: bin become base64 string (decoded to []byte field), timestamp extension
+// become RFC3339 string, non string map key is stringified and other extension type is rejected
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string        { return CodecMsgpack }
+func (msgpackCodec) ContentType() string { return "application/msgpack" }
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := msgpackEncode(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	d := msgpackDecoder{data: data}
+	value, err := d.decode()
+	if err != nil {
+		return err
+	}
+	if d.pos != len(data) {
+		return fmt.Errorf("msgpack codec: %d trailing bytes", len(data)-d.pos)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func msgpackEncode(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			msgpackEncodeInt(buf, i)
+		} else if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			buf.WriteByte(0xcf)
+			buf.Write(binary.BigEndian.AppendUint64(nil, u))
+		} else if f, err := v.Float64(); err == nil {
+			buf.WriteByte(0xcb)
+			buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+		} else {
+			return fmt.Errorf("msgpack codec: invalid number %s", v)
+		}
+	case string:
+		switch n := len(v); {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.Write([]byte{0xd9, byte(n)})
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+		default:
+			buf.WriteByte(0xdb)
+			buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+		}
+		buf.WriteString(v)
+	case []any:
+		msgpackEncodeLen(buf, len(v), 0x90, 0xdc)
+		for _, elem := range v {
+			if err := msgpackEncode(buf, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		msgpackEncodeLen(buf, len(v), 0x80, 0xde)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			msgpackEncode(buf, k)
+			if err := msgpackEncode(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack codec: unsupported type %T", value)
+	}
+	return nil
+}
+
+func msgpackEncodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.Write([]byte{0xd0, byte(int8(i))})
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(i))))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(i))))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+// msgpackEncodeLen write array or map header, fix is fix format prefix and code is 16 bit length format code
+func msgpackEncodeLen(buf *bytes.Buffer, n int, fix, code byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(code + 1)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// msgpackDecoder decode MessagePack to json compatible value, bin is decoded to []byte (base64 string in json)
+// and timestamp extension to time.Time
+type msgpackDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackShortBuffer
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *msgpackDecoder) decode() (any, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	switch c := b[0]; {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.decodeMap(int(c & 0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.decodeArray(int(c & 0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		return d.decodeString(int(c & 0x1f))
+	case c == 0xc0:
+		return nil, nil
+	case c == 0xc2, c == 0xc3:
+		return c == 0xc3, nil
+	case c >= 0xc4 && c <= 0xc6: // bin 8, 16, 32
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(n))
+		return append([]byte{}, b...), err
+	case c >= 0xc7 && c <= 0xc9: // ext 8, 16, 32
+		n, err := d.readUint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(int(n))
+	case c == 0xca:
+		n, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case c == 0xcb:
+		n, err := d.readUint(8)
+		return math.Float64frombits(n), err
+	case c >= 0xcc && c <= 0xcf: // uint 8, 16, 32, 64
+		return d.readUint(1 << (c - 0xcc))
+	case c >= 0xd0 && c <= 0xd3: // int 8, 16, 32, 64
+		size := 1 << (c - 0xd0)
+		n, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, nil
+	case c >= 0xd4 && c <= 0xd8: // fixext 1, 2, 4, 8, 16
+		return d.decodeExt(1 << (c - 0xd4))
+	case c >= 0xd9 && c <= 0xdb: // str 8, 16, 32
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case c == 0xdc, c == 0xdd: // array 16, 32
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case c == 0xde, c == 0xdf: // map 16, 32
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	default:
+		return nil, fmt.Errorf("msgpack codec: invalid format 0x%x", c)
+	}
+}
+
+func (d *msgpackDecoder) decodeString(n int) (any, error) {
+	b, err := d.read(n)
+	return string(b), err
+}
+
+// enter increase nesting depth of array or map, caller must defer leave
+func (d *msgpackDecoder) enter() error {
+	if d.depth++; d.depth > msgpackMaxDepth {
+		return fmt.Errorf("msgpack codec: exceeded max depth %d", msgpackMaxDepth)
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) leave() { d.depth-- }
+
+func (d *msgpackDecoder) decodeArray(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackShortBuffer
+	}
+	defer d.leave()
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	arr := make([]any, n)
+	for i := range arr {
+		var err error
+		if arr[i], err = d.decode(); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackShortBuffer
+	}
+	defer d.leave()
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := key.(string); ok {
+			m[s] = value
+		} else {
+			m[fmt.Sprint(key)] = value
+		}
+	}
+	return m, nil
+}
+
+// decodeExt decode extension, only timestamp extension (type -1) is supported
+func (d *msgpackDecoder) decodeExt(n int) (any, error) {
+	typ, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ[0]) != -1 {
+		return nil, fmt.Errorf("msgpack codec: unsupported extension type %d", int8(typ[0]))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b[:4]))).UTC(), nil
+	default:
+		return nil, fmt.Errorf("msgpack codec: invalid timestamp length %d", n)
+	}
+}
@@ -0,0 +1,107 @@
+package candishared
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type codecPayload struct {
+	ID        string         `json:"id"`
+	Amount    int64          `json:"amount"`
+	Price     float64        `json:"price"`
+	Tags      []string       `json:"tags"`
+	Meta      map[string]any `json:"meta"`
+	Raw       []byte         `json:"raw"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func TestCodec(t *testing.T) {
+	payload := codecPayload{
+		ID: "ORD-1", Amount: -70000, Price: 12.5, Tags: []string{"a", "b"},
+		Meta: map[string]any{"active": true, "note": nil}, Raw: []byte{0, 1, 2},
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	for _, name := range []string{CodecJSON, CodecMsgpack, CodecJSONGzip, CodecMsgpackGzip} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := GetCodec(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, codec.Name())
+
+			data, err := codec.Marshal(payload)
+			require.NoError(t, err)
+			var decoded codecPayload
+			require.NoError(t, codec.Unmarshal(data, &decoded))
+			assert.Equal(t, payload, decoded)
+		})
+	}
+
+	msgpack, _ := GetCodec(CodecMsgpack)
+	data, err := msgpack.Marshal(map[string]any{"a": 1, "b": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0xa1, 'x'}, data)
+
+	// bin and timestamp extension from other msgpack encoder
+	var decoded struct {
+		Raw  []byte    `json:"raw"`
+		Time time.Time `json:"time"`
+	}
+	require.NoError(t, msgpack.Unmarshal([]byte{
+		0x82, 0xa3, 'r', 'a', 'w', 0xc4, 0x02, 0x0a, 0x0b,
+		0xa4, 't', 'i', 'm', 'e', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x3c,
+	}, &decoded))
+	assert.Equal(t, []byte{0x0a, 0x0b}, decoded.Raw)
+	assert.Equal(t, time.Unix(60, 0).UTC(), decoded.Time.UTC())
+	assert.Error(t, msgpack.Unmarshal([]byte{0x92, 0x01}, &decoded))
+
+	// non string map key is stringified
+	var keys map[string]string
+	require.NoError(t, msgpack.Unmarshal([]byte{0x81, 0x01, 0xa1, 'x'}, &keys))
+	assert.Equal(t, map[string]string{"1": "x"}, keys)
+
+	// nested array deeper than max depth is rejected
+	var nested any
+	deep := append(bytes.Repeat([]byte{0x91}, msgpackMaxDepth), 0xc0)
+	require.NoError(t, msgpack.Unmarshal(deep, &nested))
+	assert.ErrorContains(t, msgpack.Unmarshal(append([]byte{0x91}, deep...), &nested), "exceeded max depth")
+	assert.ErrorContains(t, msgpack.Unmarshal(bytes.Repeat([]byte{0x81, 0xa1, 'a'}, 100000), &nested), "exceeded max depth")
+
+	_, err = GetCodec("avro")
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+}
+
+func TestEventContextDecode(t *testing.T) {
+	args := &PublisherArgument{Topic: "orders", Codec: CodecMsgpackGzip, Data: bindPayload{OrderID: "ORD-1"}}
+	require.NoError(t, args.ApplyCodec())
+	assert.Equal(t, CodecMsgpackGzip, args.Header[candihelper.HeaderXPayloadCodec])
+	assert.Equal(t, "application/msgpack", args.ContentType)
+
+	eventContext := NewEventContext(bytes.NewBuffer(nil))
+	eventContext.SetHeader(map[string]string{"x-payload-codec": CodecMsgpackGzip})
+	eventContext.Write(args.Message)
+	payload, err := BindMessage[bindPayload](eventContext)
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-1", payload.OrderID)
+
+	pb, _ := structpb.NewStruct(map[string]any{"order_id": "ORD-2"})
+	args = &PublisherArgument{Topic: "orders", Codec: CodecProtoGzip, Data: pb}
+	require.NoError(t, args.ApplyCodec())
+	eventContext.Reset()
+	eventContext.SetHeader(map[string]string{candihelper.HeaderXPayloadCodec: CodecProtoGzip})
+	eventContext.Write(args.Message)
+	pbPayload, err := BindMessage[*structpb.Struct](eventContext)
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-2", pbPayload.Fields["order_id"].GetStringValue())
+
+	eventContext.SetHeader(map[string]string{candihelper.HeaderXPayloadCodec: "avro"})
+	_, err = BindMessage[bindPayload](eventContext)
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+
+	args = &PublisherArgument{Topic: "orders", Codec: CodecProto, Data: bindPayload{}}
+	assert.Error(t, args.ApplyCodec())
+}
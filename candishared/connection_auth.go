@@ -0,0 +1,182 @@
+package candishared
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+)
+
+var (
+	// ErrConnectionAuthExpired token of connection is expired
+	ErrConnectionAuthExpired = errors.New("Token is expired")
+	// ErrPrincipalChanged refreshed token belongs to different subject
+	ErrPrincipalChanged = errors.New("Token subject cannot be changed in connection")
+)
+
+type connectionAuthKey struct{}
+
+type (
+	// ConnectionAuth authenticated principal of long lived connection (websocket, SSE, GraphQL subscription),
+	// token can be refreshed mid-connection and expiration of current token is notified with Expired channel
+	ConnectionAuth struct {
+		validate             func(ctx context.Context, token string) (*TokenClaim, error)
+		allowPrincipalChange bool
+		clock                candihelper.Clock
+
+		mu       sync.Mutex
+		token    string
+		claim    *TokenClaim
+		expired  chan struct{}
+		timer    *time.Timer
+		version  int
+		hooks    map[int]func(prev, next *TokenClaim)
+		nextHook int
+	}
+
+	// ConnectionAuthOption option func type
+	ConnectionAuthOption func(*ConnectionAuth)
+)
+
+// ConnectionAuthSetAllowPrincipalChange option func, allow refresh with token of different subject
+// (default false, refresh is rejected)
+func ConnectionAuthSetAllowPrincipalChange(allow bool) ConnectionAuthOption {
+	return func(a *ConnectionAuth) {
+		a.allowPrincipalChange = allow
+	}
+}
+
+// ConnectionAuthSetClock option func, default candihelper.SystemClock
+func ConnectionAuthSetClock(clock candihelper.Clock) ConnectionAuthOption {
+	return func(a *ConnectionAuth) {
+		a.clock = clock
+	}
+}
+
+// NewConnectionAuth create auth of connection, validate is token validator (example: Middleware.Bearer),
+// call Close when connection closed
+func NewConnectionAuth(validate func(ctx context.Context, token string) (*TokenClaim, error), opts ...ConnectionAuthOption) *ConnectionAuth {
+	a := &ConnectionAuth{
+		validate: validate,
+		clock:    candihelper.SystemClock,
+		expired:  make(chan struct{}),
+		hooks:    make(map[int]func(prev, next *TokenClaim)),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Authenticate validate token and set as current principal of connection (first authentication or refresh),
+// change hooks is called when subject or claim changed. Current principal is kept when return error
+func (a *ConnectionAuth) Authenticate(ctx context.Context, token string) (*TokenClaim, error) {
+	claim, err := a.validate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	now := a.clock.Now()
+	if claim.ExpiresAt != 0 && now.Unix() >= claim.ExpiresAt {
+		return nil, ErrConnectionAuthExpired
+	}
+
+	a.mu.Lock()
+	prev := a.claim
+	if prev != nil && prev.Subject != claim.Subject && !a.allowPrincipalChange {
+		a.mu.Unlock()
+		return nil, ErrPrincipalChanged
+	}
+	a.token, a.claim = token, claim
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	select {
+	case <-a.expired:
+		a.expired = make(chan struct{})
+	default:
+	}
+	a.version++
+	if claim.ExpiresAt != 0 {
+		version := a.version
+		a.timer = time.AfterFunc(time.Unix(claim.ExpiresAt, 0).Sub(now), func() { a.expire(version) })
+	}
+	hooks := make([]func(prev, next *TokenClaim), 0, len(a.hooks))
+	for _, hook := range a.hooks {
+		hooks = append(hooks, hook)
+	}
+	a.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(prev, claim)
+	}
+	return claim, nil
+}
+
+// Claim current principal, nil when not authenticated or token is expired
+func (a *ConnectionAuth) Claim() *TokenClaim {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.claim
+}
+
+// Token current valid token, empty when not authenticated or token is expired
+func (a *ConnectionAuth) Token() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.token
+}
+
+// Expired channel closed when current token is expired, new channel is returned after refreshed
+func (a *ConnectionAuth) Expired() <-chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.expired
+}
+
+// OnChange register hook called when principal is authenticated or refreshed (prev is nil on first authentication),
+// return func for unregister hook (example: when subscription is finished)
+func (a *ConnectionAuth) OnChange(hook func(prev, next *TokenClaim)) (unregister func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	id := a.nextHook
+	a.nextHook++
+	a.hooks[id] = hook
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		delete(a.hooks, id)
+	}
+}
+
+// Close stop expiration timer
+func (a *ConnectionAuth) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+}
+
+func (a *ConnectionAuth) expire(version int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.version != version {
+		// refreshed before timer fired
+		return
+	}
+	a.token, a.claim = "", nil
+	close(a.expired)
+}
+
+// SetConnectionAuthToContext set connection auth to context
+func SetConnectionAuthToContext(ctx context.Context, auth *ConnectionAuth) context.Context {
+	return context.WithValue(ctx, connectionAuthKey{}, auth)
+}
+
+// ConnectionAuthFromContext get connection auth from context of handler in long lived connection
+func ConnectionAuthFromContext(ctx context.Context) (*ConnectionAuth, bool) {
+	auth, ok := ctx.Value(connectionAuthKey{}).(*ConnectionAuth)
+	return auth, ok
+}
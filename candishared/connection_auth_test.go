@@ -0,0 +1,84 @@
+package candishared
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionAuth(t *testing.T) {
+	ctx := context.Background()
+	tokens := map[string]*TokenClaim{}
+	validate := func(ctx context.Context, token string) (*TokenClaim, error) {
+		claim, ok := tokens[token]
+		if !ok {
+			return nil, errors.New("Invalid token")
+		}
+		return claim, nil
+	}
+	newClaim := func(subject string, ttl time.Duration) *TokenClaim {
+		claim := &TokenClaim{Role: subject}
+		claim.Subject = subject
+		// expiration in second precision, start from next second boundary
+		claim.ExpiresAt = time.Now().Truncate(time.Second).Add(time.Second + ttl).Unix()
+		return claim
+	}
+	tokens["a1"], tokens["a2"], tokens["b1"] = newClaim("a", 0), newClaim("a", time.Hour), newClaim("b", time.Hour)
+
+	auth := NewConnectionAuth(validate)
+	defer auth.Close()
+	var changes []string
+	unregister := auth.OnChange(func(prev, next *TokenClaim) {
+		if prev == nil {
+			changes = append(changes, "nil>"+next.Subject)
+			return
+		}
+		changes = append(changes, prev.Subject+">"+next.Subject)
+	})
+
+	_, err := auth.Authenticate(ctx, "unknown")
+	assert.Error(t, err)
+	assert.Nil(t, auth.Claim())
+
+	_, err = auth.Authenticate(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "a1", auth.Token())
+	select {
+	case <-auth.Expired():
+	case <-time.After(3 * time.Second):
+		t.Fatal("connection auth must be expired")
+	}
+	assert.Nil(t, auth.Claim())
+	assert.Empty(t, auth.Token())
+
+	_, err = auth.Authenticate(ctx, "a2")
+	require.NoError(t, err)
+	expired := auth.Expired()
+	_, err = auth.Authenticate(ctx, "b1")
+	assert.ErrorIs(t, err, ErrPrincipalChanged)
+	assert.Equal(t, "a", auth.Claim().Subject)
+	select {
+	case <-expired:
+		t.Fatal("refreshed connection auth must not be expired")
+	default:
+	}
+
+	unregister()
+	_, err = auth.Authenticate(ctx, "a2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nil>a", "nil>a"}, changes)
+
+	auth = NewConnectionAuth(validate, ConnectionAuthSetAllowPrincipalChange(true))
+	defer auth.Close()
+	auth.Authenticate(ctx, "a2")
+	ctx = SetConnectionAuthToContext(ctx, auth)
+	fromContext, ok := ConnectionAuthFromContext(ctx)
+	require.True(t, ok)
+	_, err = fromContext.Authenticate(ctx, "b1")
+	require.NoError(t, err)
+	assert.Equal(t, "b", auth.Claim().Subject)
+}
@@ -20,6 +20,21 @@ const (
 
 	// ContextKeySQLTransaction context key
 	ContextKeySQLTransaction ContextKey = "sqltx"
+
+	// ContextKeyLocale context key
+	ContextKeyLocale ContextKey = "locale"
+
+	// ContextKeyScopes context key
+	ContextKeyScopes ContextKey = "scopes"
+
+	// ContextKeyTenant context key
+	ContextKeyTenant ContextKey = "tenant"
+
+	// ContextKeyBaggage context key
+	ContextKeyBaggage ContextKey = "baggage"
+
+	// ContextKeyRequestID context key
+	ContextKeyRequestID ContextKey = "requestID"
 )
 
 // SetToContext will set context with specific key
@@ -41,3 +56,29 @@ func ParseTokenClaimFromContext(ctx context.Context) *TokenClaim {
 func ParseWorkerKeyFromContext(ctx context.Context) []byte {
 	return GetValueFromContext(ctx, ContextKeyWorkerKey).([]byte)
 }
+
+// SetScopesToContext set caller scopes (permission) to context, used for field masking policy
+func SetScopesToContext(ctx context.Context, scopes ...string) context.Context {
+	existing, _ := GetValueFromContext(ctx, ContextKeyScopes).([]string)
+	return SetToContext(ctx, ContextKeyScopes, append(existing[:len(existing):len(existing)], scopes...))
+}
+
+// GetScopesFromContext get caller scopes from context, token claim role also included as scope
+func GetScopesFromContext(ctx context.Context) (scopes []string) {
+	scopes, _ = GetValueFromContext(ctx, ContextKeyScopes).([]string)
+	if claim, ok := GetValueFromContext(ctx, ContextKeyTokenClaim).(*TokenClaim); ok && claim != nil && claim.Role != "" {
+		scopes = append(scopes[:len(scopes):len(scopes)], claim.Role)
+	}
+	return scopes
+}
+
+// SetTenantToContext set tenant id to context, propagated to published message header (X-Tenant-ID)
+func SetTenantToContext(ctx context.Context, tenantID string) context.Context {
+	return SetToContext(ctx, ContextKeyTenant, tenantID)
+}
+
+// GetTenantFromContext get tenant id from context, empty if not set
+func GetTenantFromContext(ctx context.Context) string {
+	tenantID, _ := GetValueFromContext(ctx, ContextKeyTenant).(string)
+	return tenantID
+}
@@ -0,0 +1,162 @@
+package candishared
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer coalesces bursts of calls with the same key (example: aggregate ID) into a single call,
+// executed after no new call for the key within window. If maxWait is set, pending call is
+// executed at the latest maxWait after the first call in burst (soft real-time guarantee).
+type Debouncer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	maxWait time.Duration
+	pending map[string]*debounceEntry
+}
+
+type debounceEntry struct {
+	timer   *time.Timer
+	firstAt time.Time
+	fn      func()
+}
+
+// NewDebouncer constructs debouncer, set maxWait zero for unlimited wait.
+func NewDebouncer(window, maxWait time.Duration) *Debouncer {
+	return &Debouncer{
+		window:  window,
+		maxWait: maxWait,
+		pending: make(map[string]*debounceEntry),
+	}
+}
+
+// Debounce schedules fn for the key, replacing pending fn (only the latest fn in burst is executed).
+// fn is executed in separate goroutine, do not use handler context in fn because it may be canceled.
+func (d *Debouncer) Debounce(key string, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := d.pending[key]
+	if !ok {
+		entry = &debounceEntry{firstAt: now}
+		entry.timer = time.AfterFunc(d.window, func() { d.fire(key, entry) })
+		d.pending[key] = entry
+	}
+	entry.fn = fn
+
+	delay := d.window
+	if d.maxWait > 0 {
+		if remaining := entry.firstAt.Add(d.maxWait).Sub(now); remaining < delay {
+			delay = max(remaining, 0)
+		}
+	}
+	entry.timer.Reset(delay)
+}
+
+// Flush executes pending fn for the key immediately, return false if no pending call.
+func (d *Debouncer) Flush(key string) bool {
+	d.mu.Lock()
+	entry, ok := d.pending[key]
+	if ok {
+		entry.timer.Stop()
+		delete(d.pending, key)
+	}
+	d.mu.Unlock()
+
+	if ok {
+		entry.fn()
+	}
+	return ok
+}
+
+// Cancel drops pending fn for the key without executing it.
+func (d *Debouncer) Cancel(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.pending[key]; ok {
+		entry.timer.Stop()
+		delete(d.pending, key)
+	}
+}
+
+// Pending returns the number of keys with pending call.
+func (d *Debouncer) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// FlushAll executes all pending fn immediately, can be used at graceful shutdown.
+func (d *Debouncer) FlushAll() {
+	d.mu.Lock()
+	entries := d.pending
+	d.pending = make(map[string]*debounceEntry)
+	d.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.timer.Stop()
+		entry.fn()
+	}
+}
+
+func (d *Debouncer) fire(key string, entry *debounceEntry) {
+	d.mu.Lock()
+	if d.pending[key] != entry {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, key)
+	fn := entry.fn
+	d.mu.Unlock()
+
+	fn()
+}
+
+// Throttler limits action with the same key to at most once per window.
+type Throttler struct {
+	mu        sync.Mutex
+	window    time.Duration
+	lastAt    map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewThrottler constructs throttler.
+func NewThrottler(window time.Duration) *Throttler {
+	return &Throttler{
+		window: window,
+		lastAt: make(map[string]time.Time),
+	}
+}
+
+// Allow reports whether action for the key is allowed in current window.
+func (t *Throttler) Allow(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.lastPrune) > t.window {
+		for k, last := range t.lastAt {
+			if now.Sub(last) >= t.window {
+				delete(t.lastAt, k)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	if last, ok := t.lastAt[key]; ok && now.Sub(last) < t.window {
+		return false
+	}
+	t.lastAt[key] = now
+	return true
+}
+
+// Do executes fn if action for the key is allowed, return false if throttled.
+func (t *Throttler) Do(key string, fn func()) bool {
+	if !t.Allow(key) {
+		return false
+	}
+	fn()
+	return true
+}
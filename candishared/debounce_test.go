@@ -0,0 +1,43 @@
+package candishared
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebouncer(t *testing.T) {
+	d := NewDebouncer(30*time.Millisecond, 0)
+
+	var count, last int32
+	for i := int32(1); i <= 5; i++ {
+		d.Debounce("order-1", func() {
+			atomic.AddInt32(&count, 1)
+			atomic.StoreInt32(&last, i)
+		})
+	}
+	assert.Equal(t, 1, d.Pending())
+
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	assert.Equal(t, int32(5), atomic.LoadInt32(&last))
+	assert.Equal(t, 0, d.Pending())
+
+	d.Debounce("order-2", func() { atomic.AddInt32(&count, 1) })
+	assert.True(t, d.Flush("order-2"))
+	assert.False(t, d.Flush("order-2"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestThrottler(t *testing.T) {
+	th := NewThrottler(50 * time.Millisecond)
+
+	assert.True(t, th.Allow("order-1"))
+	assert.False(t, th.Allow("order-1"))
+	assert.True(t, th.Allow("order-2"))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, th.Do("order-1", func() {}))
+}
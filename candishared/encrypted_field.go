@@ -0,0 +1,443 @@
+package candishared
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const (
+	encryptedFieldPrefix = "enc:"
+
+	// HKDF info of subkeys derived from data encryption key, so AES-GCM key and deterministic nonce HMAC key are never the same
+	fieldEncryptionKeyInfo = "candi encrypted field aes-gcm"
+	fieldNonceKeyInfo      = "candi encrypted field deterministic nonce"
+)
+
+var (
+	fieldCipher         FieldCipher
+	fieldCipherMu       sync.RWMutex
+	fieldAllowPlaintext atomic.Bool
+
+	// ErrFieldCipherNotSet field cipher is not set with SetFieldCipher
+	ErrFieldCipherNotSet = errors.New("encrypted field: field cipher is not set")
+	// ErrFieldNotEncrypted loaded value is not encrypted and plaintext is not allowed with SetFieldAllowPlaintext
+	ErrFieldNotEncrypted = errors.New("encrypted field: value is not encrypted")
+)
+
+type (
+	// KeyProvider provide data encryption key from config or KMS, support key rotation (decrypt with key id of ciphertext)
+	KeyProvider interface {
+		// CurrentKey active key for encrypt
+		CurrentKey() (keyID string, key []byte, err error)
+		// Key get key with id for decrypt
+		Key(keyID string) ([]byte, error)
+		// KeyIDs all key ids (current and rotated), for deterministic lookup across rotated keys
+		KeyIDs() []string
+	}
+
+	// FieldCipher encrypt and decrypt field value, aad is additional authenticated data (field context, example: "users.email")
+	// that must be same for encrypt and decrypt
+	FieldCipher interface {
+		// Encrypt plaintext, deterministic encryption produce same ciphertext for same plaintext and aad (for equality lookup)
+		Encrypt(plaintext, aad []byte, deterministic bool) (string, error)
+		// EncryptAllKeys deterministic ciphertext with every key (current key first), for equality lookup
+		// of value encrypted with rotated key not re-encrypted yet
+		EncryptAllKeys(plaintext, aad []byte) ([]string, error)
+		Decrypt(ciphertext string, aad []byte) ([]byte, error)
+	}
+
+	// FieldContext context of encrypted field used as AES-GCM additional data, so ciphertext copied
+	// to other field (example: from "users.email" to "admins.email") fails to decrypt
+	FieldContext interface {
+		FieldContext() string
+	}
+
+	// EncryptedString string field encrypted with random nonce (AES-GCM) when persisted via SQL (driver.Valuer)
+	// and Mongo (bson.ValueMarshaler), decrypted when loaded. Value is plain in JSON
+	EncryptedString string
+
+	// DeterministicString string field with deterministic encryption, same value produce same ciphertext so can be used
+	// for equality lookup (example: db.Where("email = ?", candishared.DeterministicString(email))).
+	// Ciphertext depends on key, after key rotation use Candidates for lookup until old values are re-encrypted
+	DeterministicString string
+
+	// EncryptedStringFor EncryptedString bound to field context C
+	// (example: type userPhone struct{}; func (userPhone) FieldContext() string { return "users.phone" })
+	EncryptedStringFor[C FieldContext] string
+
+	// DeterministicStringFor DeterministicString bound to field context C, same value in different context produce different ciphertext
+	DeterministicStringFor[C FieldContext] string
+
+	staticKeyProvider struct {
+		currentKeyID string
+		keys         map[string][]byte
+	}
+
+	aesGCMCipher struct {
+		keyProvider KeyProvider
+	}
+)
+
+// SetFieldCipher set global cipher of encrypted field
+func SetFieldCipher(c FieldCipher) {
+	fieldCipherMu.Lock()
+	defer fieldCipherMu.Unlock()
+	fieldCipher = c
+}
+
+// SetFieldAllowPlaintext allow loaded value without encrypted prefix returned as is (example: during migration of existing plaintext column),
+// by default value without encrypted prefix is rejected with ErrFieldNotEncrypted
+func SetFieldAllowPlaintext(allow bool) {
+	fieldAllowPlaintext.Store(allow)
+}
+
+func getFieldCipher() (FieldCipher, error) {
+	fieldCipherMu.RLock()
+	defer fieldCipherMu.RUnlock()
+	if fieldCipher == nil {
+		return nil, ErrFieldCipherNotSet
+	}
+	return fieldCipher, nil
+}
+
+// NewStaticKeyProvider key provider from config, key must be 16, 24 or 32 bytes (AES-128, AES-192, AES-256)
+func NewStaticKeyProvider(currentKeyID string, keys map[string][]byte) (KeyProvider, error) {
+	if _, ok := keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("encrypted field: key %s not found", currentKeyID)
+	}
+	for keyID, key := range keys {
+		if strings.Contains(keyID, ":") {
+			return nil, fmt.Errorf("encrypted field: invalid key id %s", keyID)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("encrypted field: key %s: %w", keyID, err)
+		}
+	}
+	return &staticKeyProvider{currentKeyID: currentKeyID, keys: keys}, nil
+}
+
+// NewKeyProviderFromEnv key provider from environment FIELD_ENCRYPTION_KEYS with format "keyID:base64Key,...",
+// first key is current key
+func NewKeyProviderFromEnv() (KeyProvider, error) {
+	var currentKeyID string
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(os.Getenv("FIELD_ENCRYPTION_KEYS"), ",") {
+		keyID, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encrypted field: decode key %s: %w", keyID, err)
+		}
+		if currentKeyID == "" {
+			currentKeyID = keyID
+		}
+		keys[keyID] = key
+	}
+	return NewStaticKeyProvider(currentKeyID, keys)
+}
+
+func (s *staticKeyProvider) CurrentKey() (string, []byte, error) {
+	return s.currentKeyID, s.keys[s.currentKeyID], nil
+}
+
+func (s *staticKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("encrypted field: key %s not found", keyID)
+	}
+	return key, nil
+}
+
+func (s *staticKeyProvider) KeyIDs() []string {
+	keyIDs := []string{s.currentKeyID}
+	for keyID := range s.keys {
+		if keyID != s.currentKeyID {
+			keyIDs = append(keyIDs, keyID)
+		}
+	}
+	return keyIDs
+}
+
+// NewAESGCMCipher AES-GCM field cipher, ciphertext format is "enc:<keyID>:<base64 nonce+sealed>".
+// AES-GCM key and deterministic nonce HMAC-SHA256 key (of aad and plaintext) are separate subkeys derived with HKDF
+func NewAESGCMCipher(keyProvider KeyProvider) FieldCipher {
+	return &aesGCMCipher{keyProvider: keyProvider}
+}
+
+func (a *aesGCMCipher) Encrypt(plaintext, aad []byte, deterministic bool) (string, error) {
+	keyID, key, err := a.keyProvider.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	return a.encrypt(keyID, key, plaintext, aad, deterministic)
+}
+
+func (a *aesGCMCipher) EncryptAllKeys(plaintext, aad []byte) ([]string, error) {
+	var ciphertexts []string
+	for _, keyID := range a.keyProvider.KeyIDs() {
+		key, err := a.keyProvider.Key(keyID)
+		if err != nil {
+			return nil, err
+		}
+		ciphertext, err := a.encrypt(keyID, key, plaintext, aad, true)
+		if err != nil {
+			return nil, err
+		}
+		ciphertexts = append(ciphertexts, ciphertext)
+	}
+	return ciphertexts, nil
+}
+
+func (a *aesGCMCipher) encrypt(keyID string, key, plaintext, aad []byte, deterministic bool) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if deterministic {
+		nonceKey, err := hkdf.Key(sha256.New, key, nil, fieldNonceKeyInfo, sha256.Size)
+		if err != nil {
+			return "", err
+		}
+		mac := hmac.New(sha256.New, nonceKey)
+		mac.Write(binary.BigEndian.AppendUint64(nil, uint64(len(aad))))
+		mac.Write(aad)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return encryptedFieldPrefix + keyID + ":" + base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, aad)), nil
+}
+
+func (a *aesGCMCipher) Decrypt(ciphertext string, aad []byte) ([]byte, error) {
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(ciphertext, encryptedFieldPrefix), ":")
+	if !ok {
+		return nil, errors.New("encrypted field: invalid ciphertext")
+	}
+	key, err := a.keyProvider.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted field: invalid ciphertext")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	encryptionKey, err := hkdf.Key(sha256.New, key, nil, fieldEncryptionKeyInfo, len(key))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func fieldContextOf[C FieldContext]() []byte {
+	var c C
+	return []byte(c.FieldContext())
+}
+
+func encryptField(value string, aad []byte, deterministic bool) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	c, err := getFieldCipher()
+	if err != nil {
+		return "", err
+	}
+	return c.Encrypt([]byte(value), aad, deterministic)
+}
+
+func deterministicCandidates(value string, aad []byte) ([]string, error) {
+	if value == "" {
+		return []string{""}, nil
+	}
+	c, err := getFieldCipher()
+	if err != nil {
+		return nil, err
+	}
+	return c.EncryptAllKeys([]byte(value), aad)
+}
+
+// decryptField decrypt ciphertext, value without encrypted prefix is rejected unless allowed with SetFieldAllowPlaintext
+func decryptField(value string, aad []byte) (string, error) {
+	if !strings.HasPrefix(value, encryptedFieldPrefix) {
+		if value == "" || fieldAllowPlaintext.Load() {
+			return value, nil
+		}
+		return "", ErrFieldNotEncrypted
+	}
+	c, err := getFieldCipher()
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := c.Decrypt(value, aad)
+	return string(plaintext), err
+}
+
+func scanEncryptedField(src any, aad []byte) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return decryptField(v, aad)
+	case []byte:
+		return decryptField(string(v), aad)
+	default:
+		return "", fmt.Errorf("encrypted field: unsupported scan type %T", src)
+	}
+}
+
+func marshalBSONEncryptedField(value string, aad []byte, deterministic bool) (bsontype.Type, []byte, error) {
+	ciphertext, err := encryptField(value, aad, deterministic)
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(ciphertext)
+}
+
+func unmarshalBSONEncryptedField(t bsontype.Type, data []byte, aad []byte) (string, error) {
+	if t == bson.TypeNull {
+		return "", nil
+	}
+	value, ok := bson.RawValue{Type: t, Value: data}.StringValueOK()
+	if !ok {
+		return "", fmt.Errorf("encrypted field: unsupported bson type %s", t)
+	}
+	return decryptField(value, aad)
+}
+
+// Value implement driver.Valuer
+func (e EncryptedString) Value() (driver.Value, error) {
+	return encryptField(string(e), nil, false)
+}
+
+// Scan implement sql.Scanner
+func (e *EncryptedString) Scan(src any) (err error) {
+	value, err := scanEncryptedField(src, nil)
+	*e = EncryptedString(value)
+	return err
+}
+
+// MarshalBSONValue implement bson.ValueMarshaler
+func (e EncryptedString) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalBSONEncryptedField(string(e), nil, false)
+}
+
+// UnmarshalBSONValue implement bson.ValueUnmarshaler
+func (e *EncryptedString) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value, err := unmarshalBSONEncryptedField(t, data, nil)
+	*e = EncryptedString(value)
+	return err
+}
+
+// Value implement driver.Valuer
+func (d DeterministicString) Value() (driver.Value, error) {
+	return encryptField(string(d), nil, true)
+}
+
+// Scan implement sql.Scanner
+func (d *DeterministicString) Scan(src any) (err error) {
+	value, err := scanEncryptedField(src, nil)
+	*d = DeterministicString(value)
+	return err
+}
+
+// MarshalBSONValue implement bson.ValueMarshaler
+func (d DeterministicString) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalBSONEncryptedField(string(d), nil, true)
+}
+
+// UnmarshalBSONValue implement bson.ValueUnmarshaler
+func (d *DeterministicString) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value, err := unmarshalBSONEncryptedField(t, data, nil)
+	*d = DeterministicString(value)
+	return err
+}
+
+// Candidates ciphertexts with every key for equality lookup across rotated keys
+// (example: db.Where("email IN ?", candidates))
+func (d DeterministicString) Candidates() ([]string, error) {
+	return deterministicCandidates(string(d), nil)
+}
+
+// Value implement driver.Valuer
+func (e EncryptedStringFor[C]) Value() (driver.Value, error) {
+	return encryptField(string(e), fieldContextOf[C](), false)
+}
+
+// Scan implement sql.Scanner
+func (e *EncryptedStringFor[C]) Scan(src any) (err error) {
+	value, err := scanEncryptedField(src, fieldContextOf[C]())
+	*e = EncryptedStringFor[C](value)
+	return err
+}
+
+// MarshalBSONValue implement bson.ValueMarshaler
+func (e EncryptedStringFor[C]) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalBSONEncryptedField(string(e), fieldContextOf[C](), false)
+}
+
+// UnmarshalBSONValue implement bson.ValueUnmarshaler
+func (e *EncryptedStringFor[C]) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value, err := unmarshalBSONEncryptedField(t, data, fieldContextOf[C]())
+	*e = EncryptedStringFor[C](value)
+	return err
+}
+
+// Value implement driver.Valuer
+func (d DeterministicStringFor[C]) Value() (driver.Value, error) {
+	return encryptField(string(d), fieldContextOf[C](), true)
+}
+
+// Scan implement sql.Scanner
+func (d *DeterministicStringFor[C]) Scan(src any) (err error) {
+	value, err := scanEncryptedField(src, fieldContextOf[C]())
+	*d = DeterministicStringFor[C](value)
+	return err
+}
+
+// MarshalBSONValue implement bson.ValueMarshaler
+func (d DeterministicStringFor[C]) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalBSONEncryptedField(string(d), fieldContextOf[C](), true)
+}
+
+// UnmarshalBSONValue implement bson.ValueUnmarshaler
+func (d *DeterministicStringFor[C]) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value, err := unmarshalBSONEncryptedField(t, data, fieldContextOf[C]())
+	*d = DeterministicStringFor[C](value)
+	return err
+}
+
+// Candidates ciphertexts with every key for equality lookup across rotated keys
+func (d DeterministicStringFor[C]) Candidates() ([]string, error) {
+	return deterministicCandidates(string(d), fieldContextOf[C]())
+}
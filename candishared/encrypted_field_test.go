@@ -0,0 +1,97 @@
+package candishared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEncryptedField(t *testing.T) {
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "k2:MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=,k1:YWJjZGVmZ2hpamtsbW5vcA==")
+	keyProvider, err := NewKeyProviderFromEnv()
+	require.NoError(t, err)
+	SetFieldCipher(NewAESGCMCipher(keyProvider))
+	defer SetFieldCipher(nil)
+
+	value, err := EncryptedString("081234567890").Value()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(value.(string), "enc:k2:"))
+	other, _ := EncryptedString("081234567890").Value()
+	assert.NotEqual(t, value, other)
+
+	var phone EncryptedString
+	require.NoError(t, phone.Scan([]byte(value.(string))))
+	assert.Equal(t, EncryptedString("081234567890"), phone)
+	assert.ErrorIs(t, phone.Scan("plain"), ErrFieldNotEncrypted)
+	SetFieldAllowPlaintext(true)
+	require.NoError(t, phone.Scan("plain"))
+	assert.Equal(t, EncryptedString("plain"), phone)
+	SetFieldAllowPlaintext(false)
+
+	email1, _ := DeterministicString("user@mail.com").Value()
+	email2, _ := DeterministicString("user@mail.com").Value()
+	assert.Equal(t, email1, email2)
+
+	type User struct {
+		Email DeterministicString `bson:"email"`
+		Phone EncryptedString     `bson:"phone"`
+	}
+	raw, err := bson.Marshal(User{Email: "user@mail.com", Phone: "0812"})
+	require.NoError(t, err)
+	assert.Equal(t, email1, bson.Raw(raw).Lookup("email").StringValue())
+	var user User
+	require.NoError(t, bson.Unmarshal(raw, &user))
+	assert.Equal(t, User{Email: "user@mail.com", Phone: "0812"}, user)
+
+	SetFieldCipher(nil)
+	_, err = EncryptedString("x").Value()
+	assert.ErrorIs(t, err, ErrFieldCipherNotSet)
+}
+
+type userEmailContext struct{}
+
+func (userEmailContext) FieldContext() string { return "users.email" }
+
+type adminEmailContext struct{}
+
+func (adminEmailContext) FieldContext() string { return "admins.email" }
+
+func TestEncryptedFieldContext(t *testing.T) {
+	oldKeys, err := NewStaticKeyProvider("k1", map[string][]byte{"k1": []byte("abcdefghijklmnop")})
+	require.NoError(t, err)
+	SetFieldCipher(NewAESGCMCipher(oldKeys))
+	defer SetFieldCipher(nil)
+
+	userEmail, err := DeterministicStringFor[userEmailContext]("user@mail.com").Value()
+	require.NoError(t, err)
+	adminEmail, _ := DeterministicStringFor[adminEmailContext]("user@mail.com").Value()
+	assert.NotEqual(t, userEmail, adminEmail, "same value in different context produce different ciphertext")
+	plain, _ := DeterministicString("user@mail.com").Value()
+	assert.NotEqual(t, userEmail, plain)
+
+	var email DeterministicStringFor[userEmailContext]
+	require.NoError(t, email.Scan(userEmail))
+	assert.Equal(t, DeterministicStringFor[userEmailContext]("user@mail.com"), email)
+	var copied DeterministicStringFor[adminEmailContext]
+	assert.Error(t, copied.Scan(userEmail), "ciphertext copied to other context fails to decrypt")
+
+	phone, err := EncryptedStringFor[userEmailContext]("0812").Value()
+	require.NoError(t, err)
+	var decrypted EncryptedStringFor[userEmailContext]
+	require.NoError(t, decrypted.Scan(phone))
+	assert.Equal(t, EncryptedStringFor[userEmailContext]("0812"), decrypted)
+
+	rotatedKeys, err := NewStaticKeyProvider("k2", map[string][]byte{
+		"k1": []byte("abcdefghijklmnop"), "k2": []byte("12345678901234567890123456789012"),
+	})
+	require.NoError(t, err)
+	SetFieldCipher(NewAESGCMCipher(rotatedKeys))
+	candidates, err := DeterministicStringFor[userEmailContext]("user@mail.com").Candidates()
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.True(t, strings.HasPrefix(candidates[0], "enc:k2:"), "current key first")
+	assert.Equal(t, userEmail, candidates[1], "value encrypted with rotated key is found")
+}
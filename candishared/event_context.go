@@ -3,7 +3,10 @@ package candishared
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // EventContext worker context in handler
@@ -13,6 +16,8 @@ type EventContext struct {
 	header                   map[string]string
 	key                      string
 	err                      error
+	cloudEvent               *CloudEvent
+	heartbeatFunc            func() error
 
 	messageBuff *bytes.Buffer
 	resultBuff  *bytes.Buffer
@@ -48,6 +53,8 @@ func (e *EventContext) Reset() {
 	e.handlerRoute = ""
 	e.key = ""
 	e.err = nil
+	e.cloudEvent = nil
+	e.heartbeatFunc = nil
 }
 
 // SetContext setter
@@ -89,6 +96,30 @@ func (e *EventContext) SetError(err error) {
 	e.err = err
 }
 
+// SetCloudEvent setter, CloudEvents attributes of consumed message
+func (e *EventContext) SetCloudEvent(event *CloudEvent) {
+	e.cloudEvent = event
+}
+
+// SetHeartbeatFunc setter, called by Heartbeat (set by worker which support heartbeat, example task queue worker)
+func (e *EventContext) SetHeartbeatFunc(heartbeatFunc func() error) {
+	e.heartbeatFunc = heartbeatFunc
+}
+
+// Heartbeat report handler is still alive, call periodically in long-running handler so the job is not
+// considered stuck after visibility timeout. No-op if worker does not support heartbeat
+func (e *EventContext) Heartbeat() error {
+	if e.heartbeatFunc == nil {
+		return nil
+	}
+	return e.heartbeatFunc()
+}
+
+// CloudEvent get CloudEvents attributes of consumed message, nil if message is not CloudEvents
+func (e *EventContext) CloudEvent() *CloudEvent {
+	return e.cloudEvent
+}
+
 // Context get context
 func (e *EventContext) Context() context.Context {
 	return e.ctx
@@ -119,6 +150,28 @@ func (e *EventContext) Message() []byte {
 	return e.messageBuff.Bytes()
 }
 
+// Codec get payload codec of message from X-Payload-Codec header, json codec if header is not set
+func (e *EventContext) Codec() (Codec, error) {
+	return GetCodecFromHeader(e.header)
+}
+
+// Decode decode message into v with payload codec of message. Without codec header, proto message is decoded
+// with UnmarshalProtoPayload and other type with json
+func (e *EventContext) Decode(v any) error {
+	codecName := codecNameFromHeader(e.header)
+	if codecName == "" {
+		if msg, ok := v.(proto.Message); ok {
+			return UnmarshalProtoPayload(e.Message(), msg)
+		}
+		return json.Unmarshal(e.Message(), v)
+	}
+	codec, err := GetCodec(codecName)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(e.Message(), v)
+}
+
 // Err get error
 func (e *EventContext) Err() error {
 	return e.err
@@ -147,6 +200,16 @@ func (e *EventContext) WriteResult(p []byte) (n int, err error) {
 	return e.resultBuff.Write(p)
 }
 
+// WriteProtoResult write proto message to result buffer, encoded with MarshalProtoPayload
+func (e *EventContext) WriteProtoResult(msg proto.Message) error {
+	b, err := MarshalProtoPayload(msg)
+	if err != nil {
+		return err
+	}
+	_, err = e.WriteResult(b)
+	return err
+}
+
 // GetResponse get response writer buffer
 func (e *EventContext) GetResponse() *bytes.Buffer {
 	return e.resultBuff
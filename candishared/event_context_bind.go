@@ -0,0 +1,37 @@
+package candishared
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// BindMessage unmarshal event message into T with payload codec of message (see EventContext.Decode, proto message
+// if T implement proto.Message, with json, binary proto, or proto payload encoding (see MarshalProtoPayload), else json), then validate with `Validate() error` method (if T implement it) and given validators
+// (example: dependency validator ValidateStruct)
+func BindMessage[T any](e *EventContext, validators ...func(any) error) (payload T, err error) {
+	if _, ok := any(payload).(proto.Message); ok {
+		if rt := reflect.TypeOf(payload); rt.Kind() == reflect.Pointer {
+			payload = reflect.New(rt.Elem()).Interface().(T)
+		}
+		err = e.Decode(any(payload))
+	} else {
+		err = e.Decode(&payload)
+	}
+	if err != nil {
+		return payload, fmt.Errorf("bind message: %w", err)
+	}
+
+	if v, ok := any(payload).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return payload, err
+		}
+	}
+	for _, validate := range validators {
+		if err := validate(payload); err != nil {
+			return payload, err
+		}
+	}
+	return payload, nil
+}
@@ -1,9 +1,14 @@
 package candishared
 
 import (
+	"bytes"
 	"context"
-	"github.com/stretchr/testify/assert"
+	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestNewEventContext(t *testing.T) {
@@ -17,6 +22,20 @@ func TestNewEventContext(t *testing.T) {
 	assert.Equal(t, "value 2", event.Context().Value("key2"))
 }
 
+func TestEventContextHeartbeat(t *testing.T) {
+	eventContext := NewEventContext(bytes.NewBuffer(nil))
+	assert.NoError(t, eventContext.Heartbeat())
+
+	count := 0
+	eventContext.SetHeartbeatFunc(func() error { count++; return nil })
+	assert.NoError(t, eventContext.Heartbeat())
+	assert.Equal(t, 1, count)
+
+	eventContext.Reset()
+	assert.NoError(t, eventContext.Heartbeat())
+	assert.Equal(t, 1, count)
+}
+
 func TestMultiContext(t *testing.T) {
 	event := &EventContext{}
 	event.SetContext(context.Background())
@@ -27,3 +46,84 @@ func TestMultiContext(t *testing.T) {
 	assert.Equal(t, "value 1", event.Context().Value("key1"))
 	assert.Equal(t, "value 2", event.Context().Value("key2"))
 }
+
+type bindPayload struct {
+	OrderID string `json:"order_id"`
+}
+
+func (b bindPayload) Validate() error {
+	if b.OrderID == "" {
+		return errors.New("order_id is required")
+	}
+	return nil
+}
+
+func TestBindMessage(t *testing.T) {
+	eventContext := NewEventContext(bytes.NewBuffer(make([]byte, 0, 64)))
+	eventContext.WriteString(`{"order_id": "ORD-1"}`)
+
+	payload, err := BindMessage[bindPayload](eventContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORD-1", payload.OrderID)
+
+	ptrPayload, err := BindMessage[*bindPayload](eventContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORD-1", ptrPayload.OrderID)
+
+	_, err = BindMessage[bindPayload](eventContext, func(any) error { return errors.New("invalid") })
+	assert.EqualError(t, err, "invalid")
+
+	eventContext.Reset()
+	eventContext.WriteString(`{}`)
+	_, err = BindMessage[bindPayload](eventContext)
+	assert.EqualError(t, err, "order_id is required")
+
+	eventContext.Reset()
+	eventContext.WriteString(`{"order_id": "ORD-2"}`)
+	pbPayload, err := BindMessage[*structpb.Struct](eventContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORD-2", pbPayload.Fields["order_id"].GetStringValue())
+
+	eventContext.Reset()
+	binary, _ := proto.Marshal(pbPayload)
+	eventContext.Write(binary)
+	pbPayload, err = BindMessage[*structpb.Struct](eventContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORD-2", pbPayload.Fields["order_id"].GetStringValue())
+
+	eventContext.Reset()
+	eventContext.WriteString(`invalid`)
+	_, err = BindMessage[bindPayload](eventContext)
+	assert.Error(t, err)
+}
+
+func TestProtoPayload(t *testing.T) {
+	msg, _ := structpb.NewStruct(map[string]any{"order_id": "ORD-3", "amount": 1.5})
+	payload, err := MarshalProtoPayload(msg)
+	assert.NoError(t, err)
+	assert.True(t, IsProtoPayload(payload))
+	assert.False(t, IsProtoPayload([]byte(`{"@type":"type.googleapis.com/google.protobuf.Struct","value":{}}`)))
+
+	var decoded structpb.Struct
+	assert.NoError(t, UnmarshalProtoPayload(payload, &decoded))
+	assert.True(t, proto.Equal(msg, &decoded))
+	assert.Error(t, UnmarshalProtoPayload(payload, &structpb.Value{}))
+
+	assert.JSONEq(t, `{"@type":"type.googleapis.com/google.protobuf.Struct","value":{"order_id":"ORD-3","amount":1.5}}`,
+		string(ProtoPayloadToJSON(payload)))
+	assert.Equal(t, `{"order_id":"ORD-3"}`, string(ProtoPayloadToJSON([]byte(`{"order_id":"ORD-3"}`))))
+
+	empty, _ := MarshalProtoPayload(&structpb.Struct{})
+	assert.True(t, IsProtoPayload(empty))
+
+	eventContext := NewEventContext(bytes.NewBuffer(nil))
+	eventContext.Write(payload)
+	bound, err := BindMessage[*structpb.Struct](eventContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORD-3", bound.Fields["order_id"].GetStringValue())
+
+	assert.Error(t, eventContext.WriteProtoResult(msg))
+	eventContext = NewEventContextWithResult(bytes.NewBuffer(nil), bytes.NewBuffer(nil))
+	assert.NoError(t, eventContext.WriteProtoResult(msg))
+	assert.Equal(t, payload, eventContext.GetResponse().Bytes())
+}
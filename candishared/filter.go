@@ -8,6 +8,8 @@ type Filter struct {
 	Search           string `json:"search,omitempty"`
 	OrderBy          string `json:"orderBy,omitempty"`
 	Sort             string `json:"sort,omitempty" default:"desc" lower:"true"`
+	Cursor           string `json:"cursor,omitempty"`
+	Expression       string `json:"filter,omitempty"`
 	ShowAll          bool   `json:"showAll"`
 	AllowEmptyFilter bool   `json:"-"`
 }
@@ -41,6 +43,8 @@ type NullableFilter struct {
 	Sort    *string
 	ShowAll *bool
 	OrderBy *string
+	Cursor  *string
+	Filter  *string
 }
 
 func (n *NullableFilter) ToFilter() (filter Filter) {
@@ -56,6 +60,12 @@ func (n *NullableFilter) ToFilter() (filter Filter) {
 	if n.ShowAll != nil {
 		filter.ShowAll = *n.ShowAll
 	}
+	if n.Cursor != nil {
+		filter.Cursor = *n.Cursor
+	}
+	if n.Filter != nil {
+		filter.Expression = *n.Filter
+	}
 
 	if n.Limit == nil {
 		filter.Limit = 10
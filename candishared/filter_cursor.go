@@ -0,0 +1,346 @@
+package candishared
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var sortFieldPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+type (
+	// SortField sort field
+	SortField struct {
+		Field string
+		Desc  bool
+	}
+
+	// Cursor keyset pagination cursor, contains sort field values of last (next) or first (prev) item in page
+	Cursor struct {
+		Values   []any
+		Backward bool
+	}
+
+	// CursorMeta meta for cursor based pagination
+	CursorMeta struct {
+		Limit      int    `json:"limit"`
+		NextCursor string `json:"nextCursor,omitempty"`
+		PrevCursor string `json:"prevCursor,omitempty"`
+		HasNext    bool   `json:"hasNext"`
+		HasPrev    bool   `json:"hasPrev"`
+	}
+
+	cursorValue struct {
+		T string `json:"t,omitempty"`
+		V any    `json:"v"`
+	}
+	cursorPayload struct {
+		V []cursorValue `json:"v"`
+		B bool          `json:"b,omitempty"`
+	}
+)
+
+// ParseSort parse multi-field sort from OrderBy, separated by comma, with direction using prefix "-"/"+" or suffix ":desc"/":asc"
+// (example: "-createdAt,name" or "createdAt:desc,name:asc"), field without direction using Sort value (default asc).
+// Allowed is whitelist of sort field mapped to column name (empty column name will use field name),
+// if allowed is empty only field name validated as identifier
+func (f *Filter) ParseSort(allowed map[string]string) (sorts []SortField, err error) {
+	defaultDesc := strings.EqualFold(f.Sort, "desc")
+	for _, part := range strings.Split(f.OrderBy, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		sortField := SortField{Desc: defaultDesc}
+		switch {
+		case strings.HasPrefix(part, "-"):
+			sortField.Desc, part = true, part[1:]
+		case strings.HasPrefix(part, "+"):
+			sortField.Desc, part = false, part[1:]
+		}
+		if field, direction, ok := strings.Cut(part, ":"); ok {
+			switch strings.ToLower(direction) {
+			case "desc":
+				sortField.Desc = true
+			case "asc":
+				sortField.Desc = false
+			default:
+				return nil, candierrors.Invalid("invalid sort direction: "+direction).WithMetadata("field", field)
+			}
+			part = field
+		}
+
+		if len(allowed) > 0 {
+			column, ok := allowed[part]
+			if !ok {
+				return nil, candierrors.Invalid("sort by field "+part+" is not allowed").WithMetadata("field", part)
+			}
+			if column != "" {
+				part = column
+			}
+		} else if !sortFieldPattern.MatchString(part) {
+			return nil, candierrors.Invalid("invalid sort field: "+part).WithMetadata("field", part)
+		}
+		sortField.Field = part
+		sorts = append(sorts, sortField)
+	}
+	return sorts, nil
+}
+
+// EncodeCursor encode cursor to opaque string
+func EncodeCursor(cursor Cursor) string {
+	payload := cursorPayload{B: cursor.Backward}
+	for _, val := range cursor.Values {
+		switch v := val.(type) {
+		case time.Time:
+			payload.V = append(payload.V, cursorValue{T: "time", V: v.Format(time.RFC3339Nano)})
+		case *time.Time:
+			payload.V = append(payload.V, cursorValue{T: "time", V: v.Format(time.RFC3339Nano)})
+		case primitive.ObjectID:
+			payload.V = append(payload.V, cursorValue{T: "oid", V: v.Hex()})
+		default:
+			payload.V = append(payload.V, cursorValue{V: val})
+		}
+	}
+	b, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decode opaque cursor string, number value decoded as int64 (or float64 if fraction).
+// Only scalar value (string, number, bool, time, ObjectID) accepted
+func DecodeCursor(str string) (cursor Cursor, err error) {
+	invalidCursor := candierrors.Invalid("invalid cursor")
+	b, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return cursor, invalidCursor.WithCause(err)
+	}
+
+	var payload cursorPayload
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return cursor, invalidCursor.WithCause(err)
+	}
+
+	cursor.Backward = payload.B
+	for _, val := range payload.V {
+		str, _ := val.V.(string)
+		switch val.T {
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, str)
+			if err != nil {
+				return cursor, invalidCursor.WithCause(err)
+			}
+			cursor.Values = append(cursor.Values, t)
+		case "oid":
+			oid, err := primitive.ObjectIDFromHex(str)
+			if err != nil {
+				return cursor, invalidCursor.WithCause(err)
+			}
+			cursor.Values = append(cursor.Values, oid)
+		default:
+			if num, ok := val.V.(json.Number); ok {
+				if i, err := num.Int64(); err == nil {
+					cursor.Values = append(cursor.Values, i)
+				} else {
+					f, _ := num.Float64()
+					cursor.Values = append(cursor.Values, f)
+				}
+				continue
+			}
+			// only scalar allowed, object/array value from client can be used as query operator (example: {"$ne": null})
+			switch val.V.(type) {
+			case string, bool:
+				cursor.Values = append(cursor.Values, val.V)
+			default:
+				return cursor, invalidCursor.WithMetadata("reason", "cursor value must be scalar")
+			}
+		}
+	}
+	return cursor, nil
+}
+
+// GetCursor decode cursor from filter, return nil if filter has no cursor.
+// Cursor values length must be same with sort fields length
+func (f *Filter) GetCursor(sorts []SortField) (*Cursor, error) {
+	if f.Cursor == "" {
+		return nil, nil
+	}
+	cursor, err := DecodeCursor(f.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if len(cursor.Values) != len(sorts) {
+		return nil, candierrors.Invalid("cursor does not match with sort fields")
+	}
+	return &cursor, nil
+}
+
+// CursorLimit limit for query in cursor based pagination (limit + 1 for check has more data)
+func (f *Filter) CursorLimit() int {
+	return f.Limit + 1
+}
+
+// SQLPlaceholderQuestion placeholder "?" for mysql, sqlite
+func SQLPlaceholderQuestion(int) string { return "?" }
+
+// SQLPlaceholderDollar placeholder "$n" for postgres, start number after given offset (number of args already used in query)
+func SQLPlaceholderDollar(offset int) func(int) string {
+	return func(i int) string { return "$" + strconv.Itoa(offset+i) }
+}
+
+// SQLPlaceholderOf placeholder format of database driver, "$n" for postgres (lib/pq and pgx stdlib) and "?" for other
+func SQLPlaceholderOf(db *sql.DB) func(int) string {
+	switch fmt.Sprintf("%T", db.Driver()) {
+	case "*pq.Driver", "*stdlib.Driver":
+		return SQLPlaceholderDollar(0)
+	}
+	return SQLPlaceholderQuestion
+}
+
+// SQLRebind replace "?" placeholder (outside quoted string) in query written with "?" with given placeholder format,
+// "??" is written as literal "?". Example: SQLRebind("SELECT * FROM t WHERE id = ?", SQLPlaceholderDollar(0)) for postgres
+func SQLRebind(query string, placeholder func(argIndex int) string) string {
+	var (
+		sb       strings.Builder
+		argIndex int
+		quote    byte
+	)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?' && i+1 < len(query) && query[i+1] == '?':
+			i++
+		case c == '?':
+			argIndex++
+			sb.WriteString(placeholder(argIndex))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// SQLKeyset build keyset pagination where clause (empty if filter has no cursor), args, and order by clause (without "ORDER BY")
+// from sort fields, last sort field must be unique column (example: primary key) for stable pagination.
+// Example result: where "(created_at < $1) OR (created_at = $2 AND id < $3)", orderBy "created_at DESC, id DESC"
+func (f *Filter) SQLKeyset(sorts []SortField, placeholder func(argIndex int) string) (where string, args []any, orderBy string, err error) {
+	cursor, err := f.GetCursor(sorts)
+	if err != nil {
+		return "", nil, "", err
+	}
+	backward := cursor != nil && cursor.Backward
+
+	orders := make([]string, len(sorts))
+	for i, sort := range sorts {
+		orders[i] = sort.Field + " ASC"
+		if sort.Desc != backward {
+			orders[i] = sort.Field + " DESC"
+		}
+	}
+	orderBy = strings.Join(orders, ", ")
+	if cursor == nil {
+		return "", nil, orderBy, nil
+	}
+
+	var conditions []string
+	for i, sort := range sorts {
+		var parts []string
+		for j := 0; j < i; j++ {
+			args = append(args, cursor.Values[j])
+			parts = append(parts, sorts[j].Field+" = "+placeholder(len(args)))
+		}
+		operator := ">"
+		if sort.Desc != backward {
+			operator = "<"
+		}
+		args = append(args, cursor.Values[i])
+		parts = append(parts, sort.Field+" "+operator+" "+placeholder(len(args)))
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(conditions, " OR "), args, orderBy, nil
+}
+
+// MongoKeyset build keyset pagination filter (empty if filter has no cursor) and sort from sort fields,
+// last sort field must be unique field (example: _id) for stable pagination
+func (f *Filter) MongoKeyset(sorts []SortField) (filter bson.M, sort bson.D, err error) {
+	cursor, err := f.GetCursor(sorts)
+	if err != nil {
+		return nil, nil, err
+	}
+	backward := cursor != nil && cursor.Backward
+
+	for _, s := range sorts {
+		direction := 1
+		if s.Desc != backward {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: s.Field, Value: direction})
+	}
+	filter = bson.M{}
+	if cursor == nil {
+		return filter, sort, nil
+	}
+
+	var conditions bson.A
+	for i, s := range sorts {
+		condition := bson.M{}
+		for j := 0; j < i; j++ {
+			condition[sorts[j].Field] = cursor.Values[j]
+		}
+		operator := "$gt"
+		if s.Desc != backward {
+			operator = "$lt"
+		}
+		condition[s.Field] = bson.M{operator: cursor.Values[i]}
+		conditions = append(conditions, condition)
+	}
+	filter["$or"] = conditions
+	return filter, sort, nil
+}
+
+// CursorPaginate trim query result (queried with CursorLimit) to filter limit, fix order for backward cursor,
+// and build next/prev cursor from sort field values of item
+func CursorPaginate[T any](filter *Filter, items []T, sortValues func(T) []any) ([]T, CursorMeta) {
+	meta := CursorMeta{Limit: filter.Limit}
+	cursor, _ := DecodeCursor(filter.Cursor)
+	hasMore := filter.Limit > 0 && len(items) > filter.Limit
+	if hasMore {
+		items = items[:filter.Limit]
+	}
+
+	if filter.Cursor != "" && cursor.Backward {
+		slices.Reverse(items)
+		meta.HasNext, meta.HasPrev = true, hasMore
+	} else {
+		meta.HasNext, meta.HasPrev = hasMore, filter.Cursor != ""
+	}
+
+	if len(items) > 0 {
+		if meta.HasNext {
+			meta.NextCursor = EncodeCursor(Cursor{Values: sortValues(items[len(items)-1])})
+		}
+		if meta.HasPrev {
+			meta.PrevCursor = EncodeCursor(Cursor{Values: sortValues(items[0]), Backward: true})
+		}
+	}
+	return items, meta
+}
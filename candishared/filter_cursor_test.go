@@ -0,0 +1,114 @@
+package candishared
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFilterParseSort(t *testing.T) {
+	filter := Filter{OrderBy: "-createdAt, name:asc,id", Sort: "desc"}
+	sorts, err := filter.ParseSort(map[string]string{"createdAt": "created_at", "name": "", "id": ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: "created_at", Desc: true}, {Field: "name"}, {Field: "id", Desc: true}}, sorts)
+
+	filter.OrderBy = "password"
+	_, err = filter.ParseSort(map[string]string{"name": ""})
+	assert.True(t, candierrors.Is(err, candierrors.CodeInvalid))
+
+	filter.OrderBy = "name; drop table"
+	_, err = filter.ParseSort(nil)
+	assert.Error(t, err)
+}
+
+func TestCursor(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor, err := DecodeCursor(EncodeCursor(Cursor{Values: []any{createdAt, 10, "a", 1.5}, Backward: true}))
+	assert.NoError(t, err)
+	assert.True(t, cursor.Backward)
+	assert.Equal(t, []any{createdAt, int64(10), "a", 1.5}, cursor.Values)
+
+	_, err = DecodeCursor("invalid!")
+	assert.True(t, candierrors.Is(err, candierrors.CodeInvalid))
+
+	// reject non scalar value (operator injection)
+	for _, payload := range []string{
+		`{"v":[{"v":{"$ne":null}}]}`,
+		`{"v":[{"v":["a","b"]}]}`,
+		`{"v":[{"v":null}]}`,
+	} {
+		_, err = DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte(payload)))
+		assert.True(t, candierrors.Is(err, candierrors.CodeInvalid), payload)
+	}
+}
+
+func TestFilterKeyset(t *testing.T) {
+	sorts := []SortField{{Field: "created_at", Desc: true}, {Field: "id"}}
+	filter := Filter{Limit: 2}
+
+	where, args, orderBy, err := filter.SQLKeyset(sorts, SQLPlaceholderQuestion)
+	assert.NoError(t, err)
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+	assert.Equal(t, "created_at DESC, id ASC", orderBy)
+
+	filter.Cursor = EncodeCursor(Cursor{Values: []any{"2024-01-01", 5}})
+	where, args, orderBy, err = filter.SQLKeyset(sorts, SQLPlaceholderDollar(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "(created_at < $2) OR (created_at = $3 AND id > $4)", where)
+	assert.Equal(t, []any{"2024-01-01", "2024-01-01", int64(5)}, args)
+	assert.Equal(t, "created_at DESC, id ASC", orderBy)
+
+	filter.Cursor = EncodeCursor(Cursor{Values: []any{"2024-01-01", 5}, Backward: true})
+	mongoFilter, mongoSort, err := filter.MongoKeyset(sorts)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "created_at", Value: 1}, {Key: "id", Value: -1}}, mongoSort)
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"created_at": bson.M{"$gt": "2024-01-01"}},
+		bson.M{"created_at": "2024-01-01", "id": bson.M{"$lt": int64(5)}},
+	}}, mongoFilter)
+
+	filter.Cursor = EncodeCursor(Cursor{Values: []any{1}})
+	_, _, _, err = filter.SQLKeyset(sorts, SQLPlaceholderQuestion)
+	assert.Error(t, err)
+}
+
+func TestCursorPaginate(t *testing.T) {
+	sortValues := func(i int) []any { return []any{i} }
+
+	filter := Filter{Limit: 2}
+	items, meta := CursorPaginate(&filter, []int{1, 2, 3}, sortValues)
+	assert.Equal(t, []int{1, 2}, items)
+	assert.True(t, meta.HasNext)
+	assert.False(t, meta.HasPrev)
+
+	filter.Cursor = meta.NextCursor
+	items, meta = CursorPaginate(&filter, []int{3, 4}, sortValues)
+	assert.Equal(t, []int{3, 4}, items)
+	assert.False(t, meta.HasNext)
+	assert.True(t, meta.HasPrev)
+
+	// backward query return reversed order
+	filter.Cursor = meta.PrevCursor
+	cursor, _ := DecodeCursor(filter.Cursor)
+	assert.Equal(t, Cursor{Values: []any{int64(3)}, Backward: true}, cursor)
+	items, meta = CursorPaginate(&filter, []int{2, 1}, sortValues)
+	assert.Equal(t, []int{1, 2}, items)
+	assert.True(t, meta.HasNext)
+	assert.False(t, meta.HasPrev)
+	assert.Empty(t, meta.PrevCursor)
+
+	resp := NewCursorMeta(meta)
+	assert.Equal(t, 2, resp.Limit)
+	assert.Equal(t, meta.NextCursor, resp.Cursor.NextCursor)
+}
+
+func TestSQLRebind(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM t WHERE a = $1 AND b = '?' AND c ? 'k' AND d = $2",
+		SQLRebind("SELECT * FROM t WHERE a = ? AND b = '?' AND c ?? 'k' AND d = ?", SQLPlaceholderDollar(0)))
+	assert.Equal(t, "UPDATE t SET a = ? WHERE id = ?", SQLRebind("UPDATE t SET a = ? WHERE id = ?", SQLPlaceholderQuestion))
+}
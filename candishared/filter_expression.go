@@ -0,0 +1,443 @@
+package candishared
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/golangid/candi/candierrors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	maxFilterExpressionLength = 2048
+	maxFilterExpressionDepth  = 16
+)
+
+// FilterExpression AST of filter expression, logical node (AND, OR, NOT) has children,
+// comparison node (=, !=, >, >=, <, <=, LIKE, IN, NOT IN, IS NULL, IS NOT NULL) has field and value(s)
+type FilterExpression struct {
+	Operator string
+	Field    string
+	Value    any
+	Values   []any
+	Children []*FilterExpression
+}
+
+// ParseExpression parse filter expression from filter, return nil if expression is empty (see ParseFilterExpression)
+func (f *Filter) ParseExpression(allowed map[string]string) (*FilterExpression, error) {
+	if strings.TrimSpace(f.Expression) == "" {
+		return nil, nil
+	}
+	return ParseFilterExpression(f.Expression, allowed)
+}
+
+// ParseFilterExpression parse and validate filter expression, example: `age>=18 AND status IN (active,pending)`.
+// Value can be number, true/false, null, quoted string ('...' or "..."), or bare word as string.
+// Allowed is whitelist of filter field mapped to column name (empty column name will use field name), cannot empty
+func ParseFilterExpression(expr string, allowed map[string]string) (*FilterExpression, error) {
+	if len(expr) > maxFilterExpressionLength {
+		return nil, candierrors.Invalid("filter expression too long")
+	}
+	tokens, err := tokenizeFilterExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterExpressionParser{tokens: tokens, allowed: allowed}
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != filterTokenEOF {
+		return nil, p.errorAt("unexpected " + strconv.Quote(tok.text))
+	}
+	return node, nil
+}
+
+// ToSQL translate to sql where clause and args, can be used in GORM: db.Where(expr.ToSQL(candishared.SQLPlaceholderQuestion))
+func (e *FilterExpression) ToSQL(placeholder func(argIndex int) string) (where string, args []any) {
+	var b strings.Builder
+	e.writeSQL(&b, &args, placeholder)
+	return b.String(), args
+}
+
+func (e *FilterExpression) writeSQL(b *strings.Builder, args *[]any, placeholder func(int) string) {
+	addArg := func(val any) string {
+		*args = append(*args, val)
+		return placeholder(len(*args))
+	}
+
+	switch e.Operator {
+	case "AND", "OR":
+		b.WriteString("(")
+		for i, child := range e.Children {
+			if i > 0 {
+				b.WriteString(" " + e.Operator + " ")
+			}
+			child.writeSQL(b, args, placeholder)
+		}
+		b.WriteString(")")
+	case "NOT":
+		b.WriteString("NOT ")
+		e.Children[0].writeSQL(b, args, placeholder)
+	case "IN", "NOT IN":
+		placeholders := make([]string, len(e.Values))
+		for i, val := range e.Values {
+			placeholders[i] = addArg(val)
+		}
+		b.WriteString(e.Field + " " + e.Operator + " (" + strings.Join(placeholders, ", ") + ")")
+	case "IS NULL", "IS NOT NULL":
+		b.WriteString(e.Field + " " + e.Operator)
+	default:
+		b.WriteString(e.Field + " " + e.Operator + " " + addArg(e.Value))
+	}
+}
+
+// ToMongo translate to mongo filter
+func (e *FilterExpression) ToMongo() bson.M {
+	switch e.Operator {
+	case "AND", "OR":
+		conditions := make(bson.A, len(e.Children))
+		for i, child := range e.Children {
+			conditions[i] = child.ToMongo()
+		}
+		return bson.M{"$" + strings.ToLower(e.Operator): conditions}
+	case "NOT":
+		return bson.M{"$nor": bson.A{e.Children[0].ToMongo()}}
+	case "IN":
+		return bson.M{e.Field: bson.M{"$in": e.Values}}
+	case "NOT IN":
+		return bson.M{e.Field: bson.M{"$nin": e.Values}}
+	case "IS NULL":
+		return bson.M{e.Field: nil}
+	case "IS NOT NULL":
+		return bson.M{e.Field: bson.M{"$ne": nil}}
+	case "LIKE":
+		str, _ := e.Value.(string)
+		return bson.M{e.Field: bson.M{"$regex": likeToRegex(str), "$options": "i"}}
+	case "=":
+		return bson.M{e.Field: e.Value}
+	}
+	return bson.M{e.Field: bson.M{mongoComparisonOperators[e.Operator]: e.Value}}
+}
+
+var mongoComparisonOperators = map[string]string{
+	"!=": "$ne", ">": "$gt", ">=": "$gte", "<": "$lt", "<=": "$lte",
+}
+
+func likeToRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenIdent
+	filterTokenString
+	filterTokenNumber
+	filterTokenOperator
+	filterTokenLParen
+	filterTokenRParen
+	filterTokenComma
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func tokenizeFilterExpression(expr string) (tokens []filterToken, err error) {
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, filterToken{kind: filterTokenComma, text: ",", pos: i})
+			i++
+		case strings.ContainsRune("=!<>", rune(c)):
+			start := i
+			i++
+			if i < len(expr) && (expr[i] == '=' || (c == '<' && expr[i] == '>')) {
+				i++
+			}
+			op := expr[start:i]
+			switch op {
+			case "!":
+				return nil, candierrors.Invalid("invalid filter expression: unexpected '!' at position " + strconv.Itoa(start))
+			case "==":
+				op = "="
+			case "<>":
+				op = "!="
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: op, pos: start})
+		case c == '\'' || c == '"':
+			start := i
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(expr) {
+					return nil, candierrors.Invalid("invalid filter expression: unterminated string at position " + strconv.Itoa(start))
+				}
+				if expr[i] == '\\' && i+1 < len(expr) {
+					b.WriteByte(expr[i+1])
+					i += 2
+					continue
+				}
+				if expr[i] == c {
+					i++
+					break
+				}
+				b.WriteByte(expr[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, text: b.String(), pos: start})
+		case c == '-' || c == '.' || unicode.IsDigit(rune(c)):
+			start := i
+			i++
+			for i < len(expr) && (unicode.IsDigit(rune(expr[i])) || expr[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, text: expr[start:i], pos: start})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(expr) && (expr[i] == '_' || expr[i] == '.' || expr[i] == '-' ||
+				unicode.IsLetter(rune(expr[i])) || unicode.IsDigit(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenIdent, text: expr[start:i], pos: start})
+		default:
+			return nil, candierrors.Invalid("invalid filter expression: unexpected character '" + string(c) + "' at position " + strconv.Itoa(i))
+		}
+	}
+	return append(tokens, filterToken{kind: filterTokenEOF, pos: len(expr)}), nil
+}
+
+type filterExpressionParser struct {
+	tokens  []filterToken
+	pos     int
+	allowed map[string]string
+}
+
+func (p *filterExpressionParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterExpressionParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != filterTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterExpressionParser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == filterTokenIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *filterExpressionParser) errorAt(message string) error {
+	return candierrors.Invalid("invalid filter expression: " + message + " at position " + strconv.Itoa(p.peek().pos))
+}
+
+func (p *filterExpressionParser) parseOr(depth int) (*FilterExpression, error) {
+	return p.parseLogical(depth, "OR", p.parseAnd)
+}
+
+func (p *filterExpressionParser) parseAnd(depth int) (*FilterExpression, error) {
+	return p.parseLogical(depth, "AND", p.parseUnary)
+}
+
+func (p *filterExpressionParser) parseLogical(depth int, operator string, parseOperand func(int) (*FilterExpression, error)) (*FilterExpression, error) {
+	node, err := parseOperand(depth)
+	if err != nil {
+		return nil, err
+	}
+	if !p.isKeyword(operator) {
+		return node, nil
+	}
+
+	logical := &FilterExpression{Operator: operator, Children: []*FilterExpression{node}}
+	for p.isKeyword(operator) {
+		p.next()
+		node, err := parseOperand(depth)
+		if err != nil {
+			return nil, err
+		}
+		logical.Children = append(logical.Children, node)
+	}
+	return logical, nil
+}
+
+func (p *filterExpressionParser) parseUnary(depth int) (*FilterExpression, error) {
+	if depth > maxFilterExpressionDepth {
+		return nil, p.errorAt("too deep nested expression")
+	}
+
+	if p.isKeyword("NOT") {
+		p.next()
+		node, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &FilterExpression{Operator: "NOT", Children: []*FilterExpression{node}}, nil
+	}
+	if p.peek().kind == filterTokenLParen {
+		p.next()
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != filterTokenRParen {
+			return nil, p.errorAt("expected ')'")
+		}
+		p.next()
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterExpressionParser) parseComparison() (*FilterExpression, error) {
+	tok := p.peek()
+	if tok.kind != filterTokenIdent {
+		return nil, p.errorAt("expected field name")
+	}
+	column, ok := p.allowed[tok.text]
+	if !ok {
+		return nil, candierrors.Invalid("filter by field "+tok.text+" is not allowed").WithMetadata("field", tok.text)
+	}
+	if column == "" {
+		column = tok.text
+	}
+	p.next()
+
+	node := &FilterExpression{Field: column}
+	switch {
+	case p.peek().kind == filterTokenOperator:
+		node.Operator = p.next().text
+	case p.isKeyword("LIKE"):
+		p.next()
+		node.Operator = "LIKE"
+	case p.isKeyword("IN"):
+		p.next()
+		node.Operator = "IN"
+	case p.isKeyword("NOT"):
+		p.next()
+		if !p.isKeyword("IN") {
+			return nil, p.errorAt("expected IN")
+		}
+		p.next()
+		node.Operator = "NOT IN"
+	case p.isKeyword("IS"):
+		p.next()
+		node.Operator = "IS NULL"
+		if p.isKeyword("NOT") {
+			p.next()
+			node.Operator = "IS NOT NULL"
+		}
+		if !p.isKeyword("NULL") {
+			return nil, p.errorAt("expected NULL")
+		}
+		p.next()
+		return node, nil
+	default:
+		return nil, p.errorAt("expected operator")
+	}
+
+	if node.Operator == "IN" || node.Operator == "NOT IN" {
+		if p.next().kind != filterTokenLParen {
+			return nil, p.errorAt("expected '('")
+		}
+		for {
+			val, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			node.Values = append(node.Values, val)
+			if p.peek().kind == filterTokenComma {
+				p.next()
+				continue
+			}
+			if p.next().kind != filterTokenRParen {
+				return nil, p.errorAt("expected ')'")
+			}
+			return node, nil
+		}
+	}
+
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	switch node.Operator {
+	case "LIKE":
+		if _, ok := val.(string); !ok {
+			return nil, p.errorAt("LIKE value must be string")
+		}
+	case "=", "!=":
+		if val == nil {
+			node.Operator = map[string]string{"=": "IS NULL", "!=": "IS NOT NULL"}[node.Operator]
+			return node, nil
+		}
+	}
+	node.Value = val
+	return node, nil
+}
+
+func (p *filterExpressionParser) parseValue() (any, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case filterTokenString:
+		p.next()
+		return tok.text, nil
+	case filterTokenNumber:
+		p.next()
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, candierrors.Invalid("invalid filter expression: invalid number " + tok.text + " at position " + strconv.Itoa(tok.pos))
+		}
+		return f, nil
+	case filterTokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "and", "or", "not", "in", "like", "is":
+			return nil, p.errorAt("expected value")
+		case "true":
+			p.next()
+			return true, nil
+		case "false":
+			p.next()
+			return false, nil
+		case "null":
+			p.next()
+			return nil, nil
+		}
+		p.next()
+		return tok.text, nil
+	}
+	return nil, p.errorAt("expected value")
+}
@@ -0,0 +1,60 @@
+package candishared
+
+import (
+	"testing"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	allowed := map[string]string{"age": "", "status": "", "name": "full_name", "deletedAt": "deleted_at"}
+
+	filter := Filter{Expression: `age>=18 AND status IN (active, "pending") AND NOT (name LIKE 'ag%' OR deletedAt = null)`}
+	expr, err := filter.ParseExpression(allowed)
+	assert.NoError(t, err)
+
+	where, args := expr.ToSQL(SQLPlaceholderQuestion)
+	assert.Equal(t, "(age >= ? AND status IN (?, ?) AND NOT (full_name LIKE ? OR deleted_at IS NULL))", where)
+	assert.Equal(t, []any{int64(18), "active", "pending", "ag%"}, args)
+
+	where, _ = expr.ToSQL(SQLPlaceholderDollar(0))
+	assert.Equal(t, "(age >= $1 AND status IN ($2, $3) AND NOT (full_name LIKE $4 OR deleted_at IS NULL))", where)
+
+	assert.Equal(t, bson.M{"$and": bson.A{
+		bson.M{"age": bson.M{"$gte": int64(18)}},
+		bson.M{"status": bson.M{"$in": []any{"active", "pending"}}},
+		bson.M{"$nor": bson.A{bson.M{"$or": bson.A{
+			bson.M{"full_name": bson.M{"$regex": "^ag.*$", "$options": "i"}},
+			bson.M{"deleted_at": nil},
+		}}}},
+	}}, expr.ToMongo())
+
+	expr, err = ParseFilterExpression(`status <> 'it\'s' OR age NOT IN (1, 2.5) OR name IS NOT NULL`, allowed)
+	assert.NoError(t, err)
+	where, args = expr.ToSQL(SQLPlaceholderQuestion)
+	assert.Equal(t, "(status != ? OR age NOT IN (?, ?) OR full_name IS NOT NULL)", where)
+	assert.Equal(t, []any{"it's", int64(1), 2.5}, args)
+
+	empty, err := (&Filter{}).ParseExpression(allowed)
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+}
+
+func TestParseFilterExpressionInvalid(t *testing.T) {
+	allowed := map[string]string{"age": "", "status": ""}
+	for _, expr := range []string{
+		"password = 'x'",
+		"age >= 18 AND",
+		"age >= 18; DROP TABLE users",
+		"(age > 1",
+		"age 18",
+		"status IN active",
+		"age LIKE 1",
+		"status = 'unterminated",
+	} {
+		_, err := ParseFilterExpression(expr, allowed)
+		assert.True(t, candierrors.Is(err, candierrors.CodeInvalid), expr)
+	}
+}
@@ -0,0 +1,89 @@
+package candishared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const protoPayloadPrefix = `{"@type":`
+
+// protoPayload envelope for protobuf message in text storage (example: task queue job arguments and result),
+// message is encoded in binary proto (base64 in json) with type url for lookup message type in proto registry
+type protoPayload struct {
+	TypeURL string `json:"@type"`
+	Value   []byte `json:"@proto"`
+}
+
+// MarshalProtoPayload encode proto message to payload with type url, without lossy json round-trip
+func MarshalProtoPayload(msg proto.Message) ([]byte, error) {
+	value, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = []byte{} // encoded as empty string instead of null
+	}
+	return json.Marshal(protoPayload{
+		TypeURL: "type.googleapis.com/" + string(msg.ProtoReflect().Descriptor().FullName()), Value: value,
+	})
+}
+
+// UnmarshalProtoPayload decode payload to proto message, payload can be encoded with MarshalProtoPayload,
+// protojson, or binary proto
+func UnmarshalProtoPayload(payload []byte, msg proto.Message) error {
+	if p, ok := parseProtoPayload(payload); ok {
+		if expected := string(msg.ProtoReflect().Descriptor().FullName()); p.messageName() != expected {
+			return fmt.Errorf("proto payload: type %s mismatch with %s", p.messageName(), expected)
+		}
+		return proto.Unmarshal(p.Value, msg)
+	}
+	if trimmed := bytes.TrimSpace(payload); len(trimmed) > 0 && trimmed[0] == '{' {
+		return protojson.Unmarshal(trimmed, msg)
+	}
+	return proto.Unmarshal(payload, msg)
+}
+
+// IsProtoPayload check if payload is encoded with MarshalProtoPayload
+func IsProtoPayload(payload []byte) bool {
+	_, ok := parseProtoPayload(payload)
+	return ok
+}
+
+// ProtoPayloadToJSON render proto payload as json (with "@type" field) using message type from global proto registry,
+// return payload as is if not proto payload or message type is not registered
+func ProtoPayloadToJSON(payload []byte) []byte {
+	p, ok := parseProtoPayload(payload)
+	if !ok {
+		return payload
+	}
+	b, err := protojson.MarshalOptions{Resolver: protoregistry.GlobalTypes}.Marshal(&anypb.Any{TypeUrl: p.TypeURL, Value: p.Value})
+	if err != nil {
+		return payload
+	}
+	return b
+}
+
+func parseProtoPayload(payload []byte) (p protoPayload, ok bool) {
+	if !bytes.HasPrefix(payload, []byte(protoPayloadPrefix)) {
+		return p, false
+	}
+	var envelope struct {
+		TypeURL string  `json:"@type"`
+		Value   *[]byte `json:"@proto"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil || envelope.TypeURL == "" || envelope.Value == nil {
+		return p, false
+	}
+	return protoPayload{TypeURL: envelope.TypeURL, Value: *envelope.Value}, true
+}
+
+func (p *protoPayload) messageName() string {
+	return p.TypeURL[strings.LastIndexByte(p.TypeURL, '/')+1:]
+}
@@ -8,15 +8,25 @@ import (
 // PublisherArgument declare publisher argument
 type PublisherArgument struct {
 	// Topic or queue name
-	Topic           string
-	Key             string
-	Header          map[string]any
-	ContentType     string
-	Message         []byte
-	Delay           time.Duration
+	Topic       string
+	Key         string
+	Header      map[string]any
+	ContentType string
+	Message     []byte
+	Delay       time.Duration
+	// DeliverAt schedule message delivery at given time, take precedence over Delay
+	DeliverAt       time.Time
 	IsDeleteMessage bool
 	Timestamp       time.Time
 
+	// CloudEvent publish message as CloudEvents with the context attributes (id, specversion and time are set if empty)
+	CloudEvent     *CloudEvent
+	CloudEventMode CloudEventMode
+
+	// Codec name of registered payload codec (example: msgpack, json+gzip), Data is encoded with the codec
+	// and codec name is sent in X-Payload-Codec header so consumer can decode with EventContext.Decode
+	Codec string
+
 	// Deprecated : use Message
 	Data any
 }
@@ -31,3 +41,11 @@ func (p *PublisherArgument) Validate() error {
 
 	return nil
 }
+
+// GetDelay get publish delay from DeliverAt (if set) or Delay
+func (p *PublisherArgument) GetDelay() time.Duration {
+	if !p.DeliverAt.IsZero() {
+		return max(time.Until(p.DeliverAt), 0)
+	}
+	return p.Delay
+}
@@ -0,0 +1,78 @@
+package candishared
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength incoming request id longer than the limit is replaced with new generated id
+const maxRequestIDLength = 128
+
+// NewRequestID generate new request id
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// SetRequestIDToContext set request (correlation) id to context, propagated to outgoing HTTP request, gRPC call,
+// published message header and task queue job (X-Request-ID)
+func SetRequestIDToContext(ctx context.Context, requestID string) context.Context {
+	return SetToContext(ctx, ContextKeyRequestID, requestID)
+}
+
+// GetRequestID get request (correlation) id from context, empty if not set
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := GetValueFromContext(ctx, ContextKeyRequestID).(string)
+	return requestID
+}
+
+// ExtractRequestID set request id from incoming header (X-Request-ID, key is case insensitive) to context,
+// new request id is generated if header is not set or invalid
+func ExtractRequestID(ctx context.Context, header map[string]string) (context.Context, string) {
+	requestID, ok := header[candihelper.HeaderXRequestID]
+	if !ok {
+		for key, value := range header {
+			if strings.EqualFold(key, candihelper.HeaderXRequestID) {
+				requestID = value
+				break
+			}
+		}
+	}
+	if !IsValidRequestID(requestID) {
+		requestID = NewRequestID()
+	}
+	return SetRequestIDToContext(ctx, requestID), requestID
+}
+
+// InjectRequestID set request id from context to outgoing header
+func InjectRequestID(ctx context.Context, header map[string]string) {
+	if requestID := GetRequestID(ctx); requestID != "" {
+		header[candihelper.HeaderXRequestID] = requestID
+	}
+}
+
+// InjectRequestIDMessageHeader set request id from context to published message header, existing header is kept
+func InjectRequestIDMessageHeader(ctx context.Context, header map[string]any) {
+	if _, ok := header[candihelper.HeaderXRequestID]; ok {
+		return
+	}
+	if requestID := GetRequestID(ctx); requestID != "" {
+		header[candihelper.HeaderXRequestID] = requestID
+	}
+}
+
+// IsValidRequestID request id must not empty, not longer than 128 and only contain printable ASCII character
+// (except quote and backslash)
+func IsValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if c := requestID[i]; c < 0x21 || c > 0x7e || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
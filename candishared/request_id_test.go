@@ -0,0 +1,30 @@
+package candishared
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	ctx, requestID := ExtractRequestID(context.Background(), map[string]string{"x-request-id": "abc-123"})
+	assert.Equal(t, "abc-123", requestID)
+	assert.Equal(t, "abc-123", GetRequestID(ctx))
+
+	header := map[string]string{}
+	InjectRequestID(ctx, header)
+	assert.Equal(t, "abc-123", header[candihelper.HeaderXRequestID])
+
+	msgHeader := map[string]any{candihelper.HeaderXRequestID: "existing"}
+	InjectRequestIDMessageHeader(ctx, msgHeader)
+	assert.Equal(t, "existing", msgHeader[candihelper.HeaderXRequestID])
+
+	for _, invalid := range []string{"", `a"b`, "a b", strings.Repeat("x", 129)} {
+		_, requestID = ExtractRequestID(context.Background(), map[string]string{candihelper.HeaderXRequestID: invalid})
+		assert.NotEqual(t, invalid, requestID)
+		assert.True(t, IsValidRequestID(requestID))
+	}
+}
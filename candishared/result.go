@@ -21,6 +21,9 @@ type Meta struct {
 	Limit        int `json:"limit"`
 	TotalRecords int `json:"totalRecords"`
 	TotalPages   int `json:"totalPages"`
+
+	// Cursor meta for cursor based pagination
+	Cursor *CursorMeta `json:"cursor,omitempty"`
 }
 
 // NewMeta create new meta for slice data
@@ -30,7 +33,15 @@ func NewMeta(page, limit, totalRecords int) (m Meta) {
 	return m
 }
 
+// NewCursorMeta create new meta for slice data with cursor based pagination
+func NewCursorMeta(cursor CursorMeta) Meta {
+	return Meta{Limit: cursor.Limit, Cursor: &cursor}
+}
+
 // CalculatePages meta method
 func (m *Meta) CalculatePages() {
+	if m.Limit <= 0 {
+		return
+	}
 	m.TotalPages = int(math.Ceil(float64(m.TotalRecords) / float64(m.Limit)))
 }
@@ -5,6 +5,7 @@ import "github.com/golang-jwt/jwt"
 // TokenClaim for token claim data
 type TokenClaim struct {
 	jwt.StandardClaims
-	Role       string      `json:"role"`
-	Additional any `json:"additional"`
+	Role       string `json:"role"`
+	Locale     string `json:"locale,omitempty"`
+	Additional any    `json:"additional"`
 }
@@ -0,0 +1,108 @@
+package candiutils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+type (
+	// BulkProgress progress of bulk operation
+	BulkProgress struct {
+		Total, Processed, Failed int
+	}
+
+	// BulkOption option func of bulk operation
+	BulkOption func(*bulkOption)
+
+	bulkOption struct {
+		progressFunc func(BulkProgress)
+		stopOnError  bool
+	}
+)
+
+// BulkWithProgress option func, progress func is called serially after each item processed
+func BulkWithProgress(progressFunc func(BulkProgress)) BulkOption {
+	return func(o *bulkOption) {
+		o.progressFunc = progressFunc
+	}
+}
+
+// BulkStopOnError option func, cancel context of running item and skip remaining item after first failed item
+func BulkStopOnError() BulkOption {
+	return func(o *bulkOption) {
+		o.stopOnError = true
+	}
+}
+
+/*
+ForEachConcurrent run fn for each item with max limit concurrent worker (limit <= 0 for one worker per item), run with
+MapReduce over items. Error (or panic) of each item is aggregated in returned candihelper.MultiError with item index
+as key, remaining item is skipped when context is canceled (context error is appended with key "context").
+Return nil if all item is processed, example:
+
+	err := candiutils.ForEachConcurrent(ctx, orderIDs, 10, func(ctx context.Context, orderID string) error {
+		return uc.SendInvoice(ctx, orderID)
+	}, candiutils.BulkWithProgress(func(p candiutils.BulkProgress) {
+		eventContext.Heartbeat()
+	}))
+*/
+func ForEachConcurrent[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error, opts ...BulkOption) error {
+	_, err := MapConcurrent(ctx, items, limit, func(ctx context.Context, item T) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	}, opts...)
+	return err
+}
+
+// MapConcurrent fan out fn for each item with max limit concurrent worker and fan in result in same order as
+// items (result of failed or skipped item is zero value), error is aggregated like ForEachConcurrent.
+// Result can be passed to next MapConcurrent as pipeline stage
+func MapConcurrent[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error), opts ...BulkOption) ([]R, error) {
+	var opt bulkOption
+	for _, o := range opts {
+		o(&opt)
+	}
+	if limit <= 0 || limit > len(items) {
+		limit = max(len(items), 1)
+	}
+
+	type indexed struct {
+		index int
+		value R
+	}
+	indexes := make([]int, len(items))
+	for i := range items {
+		indexes[i] = i
+	}
+
+	results := make([]R, len(items))
+	result, err := MapReduce(ctx, MapReduceConfig[int]{
+		Workers: limit, Key: strconv.Itoa, StopOnError: opt.stopOnError,
+		Progress: func(r MapReduceResult) {
+			if opt.progressFunc != nil {
+				opt.progressFunc(BulkProgress{Total: len(items), Processed: r.Processed + r.Failed, Failed: r.Failed})
+			}
+		},
+	}, func(ctx context.Context, checkpoint string) (Cursor[int], error) {
+		return NewSliceCursor(indexes), nil
+	}, func(ctx context.Context, i int) (out indexed, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		out.index = i
+		out.value, err = fn(ctx, items[i])
+		return out, err
+	}, func(out indexed) {
+		results[out.index] = out.value
+	})
+
+	if ctx.Err() != nil && result.Processed+result.Failed < len(items) {
+		result.Errors.Append("context", context.Cause(ctx))
+	}
+	if result.Errors.HasError() {
+		return results, result.Errors
+	}
+	return results, err
+}
@@ -0,0 +1,74 @@
+package candiutils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachConcurrent(t *testing.T) {
+	var running, maxRunning atomic.Int64
+	var progress []BulkProgress
+	err := ForEachConcurrent(context.Background(), []int{1, 2, 3, 4, 5, 6}, 2, func(ctx context.Context, item int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch item {
+		case 3:
+			return errors.New("invalid item")
+		case 5:
+			panic("boom")
+		}
+		return nil
+	}, BulkWithProgress(func(p BulkProgress) { progress = append(progress, p) }))
+
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"2": "invalid item", "4": "panic: boom"}, err.(candihelper.MultiError).ToMap())
+	assert.LessOrEqual(t, maxRunning.Load(), int64(2))
+	require.Len(t, progress, 6)
+	assert.Equal(t, BulkProgress{Total: 6, Processed: 6, Failed: 2}, progress[5])
+
+	assert.NoError(t, ForEachConcurrent(context.Background(), []int{}, 2, func(ctx context.Context, item int) error { return nil }))
+}
+
+func TestForEachConcurrentCancel(t *testing.T) {
+	var processed atomic.Int64
+	err := ForEachConcurrent(context.Background(), make([]int, 100), 1, func(ctx context.Context, item int) error {
+		if processed.Add(1) == 3 {
+			return errors.New("failed")
+		}
+		return nil
+	}, BulkStopOnError())
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"2": "failed"}, err.(candihelper.MultiError).ToMap())
+	assert.Less(t, processed.Load(), int64(100))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ForEachConcurrent(ctx, []int{1, 2}, 1, func(ctx context.Context, item int) error { return nil })
+	require.Error(t, err)
+	assert.Equal(t, context.Canceled.Error(), err.(candihelper.MultiError).ToMap()["context"])
+}
+
+func TestMapConcurrent(t *testing.T) {
+	results, err := MapConcurrent(context.Background(), []int{1, 2, 3}, 0, func(ctx context.Context, item int) (string, error) {
+		if item == 2 {
+			return "skip", errors.New("failed")
+		}
+		return string(rune('a' + item - 1)), nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a", "", "c"}, results)
+}
@@ -0,0 +1,233 @@
+package candiutils
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+)
+
+type (
+	// CrashReport structured report of recovered panic
+	CrashReport struct {
+		ID          string         `json:"id"`
+		Service     string         `json:"service"`
+		Environment string         `json:"environment,omitempty"`
+		Source      string         `json:"source"`
+		Panic       string         `json:"panic"`
+		StackTrace  string         `json:"stack_trace"`
+		TraceID     string         `json:"trace_id,omitempty"`
+		Context     map[string]any `json:"context,omitempty"`
+		Timestamp   time.Time      `json:"timestamp"`
+	}
+
+	// CrashReporter abstraction for send crash report (stderr, sentry, etc)
+	CrashReporter interface {
+		Report(ctx context.Context, report *CrashReport)
+	}
+)
+
+var (
+	crashReporterMu sync.RWMutex
+	crashReporters  = []CrashReporter{NewStderrCrashReporter(os.Stderr)}
+)
+
+// SetCrashReporters override crash reporters used by all servers and workers when recover from panic (default stderr json)
+func SetCrashReporters(reporters ...CrashReporter) {
+	crashReporterMu.Lock()
+	defer crashReporterMu.Unlock()
+	crashReporters = reporters
+}
+
+// AddCrashReporters add crash reporters
+func AddCrashReporters(reporters ...CrashReporter) {
+	crashReporterMu.Lock()
+	defer crashReporterMu.Unlock()
+	crashReporters = append(crashReporters, reporters...)
+}
+
+// ReportPanic build crash report from recovered value with stack trace and given context data (request/message detail),
+// send to all crash reporters, and return error from recovered value.
+// Must be called in deferred func after recover() so the stack trace contains the panic origin.
+func ReportPanic(ctx context.Context, source string, recovered any, contextData map[string]any) error {
+	report := &CrashReport{
+		ID:          newCrashReportID(),
+		Service:     env.BaseEnv().ServiceName,
+		Environment: env.BaseEnv().Environment,
+		Source:      source,
+		Panic:       fmt.Sprintf("%v", recovered),
+		StackTrace:  string(debug.Stack()),
+		Context:     contextData,
+		Timestamp:   time.Now(),
+	}
+	if ctx != nil {
+		report.TraceID = tracer.GetTraceID(ctx)
+	}
+
+	crashReporterMu.RLock()
+	reporters := crashReporters
+	crashReporterMu.RUnlock()
+	for _, reporter := range reporters {
+		func() {
+			defer func() { recover() }() // reporter must not crash the recovery
+			reporter.Report(ctx, report)
+		}()
+	}
+
+	if err, ok := recovered.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", recovered)
+}
+
+// crashReportSensitiveHeaders header (lowercase) contains credential, never sent to crash reporter
+var crashReportSensitiveHeaders = map[string]bool{
+	"authorization": true, "proxy-authorization": true, "cookie": true, "set-cookie": true,
+	"x-api-key": true, "x-auth-token": true, "x-csrf-token": true,
+}
+
+// RedactHeader copy header (http.Header or grpc metadata.MD) for crash report context, credential header value is redacted
+func RedactHeader(header map[string][]string) map[string][]string {
+	redacted := make(map[string][]string, len(header))
+	for key, values := range header {
+		if crashReportSensitiveHeaders[strings.ToLower(key)] {
+			redacted[key] = []string{"[REDACTED]"}
+			continue
+		}
+		redacted[key] = values
+	}
+	return redacted
+}
+
+// RedactURL url for crash report context without query string and user info, query may contains token or personal data
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, RawPath: u.RawPath}
+	return redacted.String()
+}
+
+func newCrashReportID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type stderrCrashReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStderrCrashReporter write crash report in single line json to writer (example os.Stderr)
+func NewStderrCrashReporter(w io.Writer) CrashReporter {
+	return &stderrCrashReporter{w: w}
+}
+
+func (s *stderrCrashReporter) Report(ctx context.Context, report *CrashReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	json.NewEncoder(s.w).Encode(report)
+}
+
+type sentryCrashReporter struct {
+	dsn        string
+	endpoint   string
+	authHeader string
+	httpClient *http.Client
+	pool       WorkerPool[[]byte]
+}
+
+// NewSentryCrashReporter send crash report to sentry using envelope api (https://develop.sentry.dev/sdk/envelopes/),
+// dsn format: https://<public_key>@<host>/<project_id>
+func NewSentryCrashReporter(dsn string) (CrashReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	projectID := strings.Trim(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || projectID == "" {
+		return nil, fmt.Errorf("invalid sentry dsn: %s", dsn)
+	}
+
+	s := &sentryCrashReporter{
+		dsn:        dsn,
+		endpoint:   fmt.Sprintf("%s://%s/api/%s/envelope/", u.Scheme, u.Host, projectID),
+		authHeader: fmt.Sprintf("Sentry sentry_version=7, sentry_client=candi/1.0, sentry_key=%s", u.User.Username()),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		pool:       NewWorkerPool[[]byte](2, WorkerPoolName("sentry-crash-reporter"), WorkerPoolQueueSize(100)),
+	}
+	s.pool.Dispatch(context.Background(), s.send)
+	return s, nil
+}
+
+func (s *sentryCrashReporter) Report(ctx context.Context, report *CrashReport) {
+	body, err := s.buildEnvelope(report)
+	if err != nil {
+		logger.LogE("sentry crash reporter: " + err.Error())
+		return
+	}
+
+	// send in background, report is dropped when queue is full (panic storm)
+	s.pool.TrySubmit(context.Background(), body)
+}
+
+func (s *sentryCrashReporter) send(ctx context.Context, body []byte) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		logger.LogE("sentry crash reporter: " + err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.authHeader)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		logger.LogE("sentry crash reporter: " + err.Error())
+		return
+	}
+	resp.Body.Close()
+}
+
+func (s *sentryCrashReporter) buildEnvelope(report *CrashReport) ([]byte, error) {
+	event := map[string]any{
+		"event_id":    report.ID,
+		"timestamp":   report.Timestamp.UTC().Format(time.RFC3339Nano),
+		"platform":    "go",
+		"level":       "fatal",
+		"server_name": report.Service,
+		"environment": report.Environment,
+		"message":     report.Panic,
+		"tags":        map[string]string{"source": report.Source, "trace_id": report.TraceID},
+		"extra":       map[string]any{"context": report.Context, "stack_trace": report.StackTrace},
+		"exception": map[string]any{
+			"values": []map[string]any{{"type": "panic", "value": report.Panic}},
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(map[string]any{"event_id": report.ID, "dsn": s.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339Nano)}); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(map[string]any{"type": "event"}); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
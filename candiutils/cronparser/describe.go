@@ -0,0 +1,162 @@
+package cronexpr
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Describe returns human-readable description of cron expression,
+// example: "0 30 8 * * 1-5 *" described as "At 08:30:00, on Monday, Tuesday, Wednesday, Thursday, and Friday"
+func Describe(cronLine string) (string, error) {
+	schedule, err := Parse(cronLine)
+	if err != nil {
+		return "", err
+	}
+	return schedule.(*expression).describe(), nil
+}
+
+func (expr *expression) describe() string {
+	var parts []string
+
+	if len(expr.secondList) == 1 && len(expr.minuteList) == 1 && len(expr.hourList) == 1 {
+		parts = append(parts, fmt.Sprintf("At %02d:%02d:%02d", expr.hourList[0], expr.minuteList[0], expr.secondList[0]))
+	} else {
+		var timeParts []string
+		withSecond := !slices.Equal(expr.secondList, []int{0})
+		if withSecond {
+			timeParts = append(timeParts, describeList(expr.secondList, secondDescriptor, "second"))
+		}
+		if !withSecond || !slices.Equal(expr.minuteList, minuteDescriptor.defaultList) {
+			timeParts = append(timeParts, describeList(expr.minuteList, minuteDescriptor, "minute"))
+		}
+		if !slices.Equal(expr.hourList, hourDescriptor.defaultList) {
+			timeParts = append(timeParts, describeList(expr.hourList, hourDescriptor, "hour"))
+		}
+		timeDesc := strings.Join(timeParts, ", past ")
+		if strings.HasPrefix(timeDesc, "every") {
+			parts = append(parts, "E"+timeDesc[1:])
+		} else {
+			parts = append(parts, "At "+timeDesc)
+		}
+	}
+
+	if expr.daysOfMonthRestricted {
+		var domParts []string
+		if len(expr.daysOfMonth) > 0 {
+			domParts = append(domParts, "on "+describeList(toList(expr.daysOfMonth), domDescriptor, "day")+" of the month")
+		}
+		if len(expr.workdaysOfMonth) > 0 {
+			days := toList(expr.workdaysOfMonth)
+			var names []string
+			for _, day := range days {
+				names = append(names, "day "+strconv.Itoa(day))
+			}
+			domParts = append(domParts, "on the nearest weekday to "+joinWords(names)+" of the month")
+		}
+		if expr.lastDayOfMonth {
+			domParts = append(domParts, "on the last day of the month")
+		}
+		if expr.lastWorkdayOfMonth {
+			domParts = append(domParts, "on the last weekday of the month")
+		}
+		parts = append(parts, strings.Join(domParts, " and "))
+	}
+
+	if expr.daysOfWeekRestricted {
+		var dowParts []string
+		if len(expr.daysOfWeek) > 0 {
+			dowParts = append(dowParts, "on "+joinWords(weekdayNames(toList(expr.daysOfWeek))))
+		}
+		for _, day := range toList(expr.specificWeekDaysOfWeek) {
+			dowParts = append(dowParts, fmt.Sprintf("on the %s %s of the month", ordinal(day/7+1), time.Weekday(day%7)))
+		}
+		for _, day := range toList(expr.lastWeekDaysOfWeek) {
+			dowParts = append(dowParts, fmt.Sprintf("on the last %s of the month", time.Weekday(day%7)))
+		}
+		parts = append(parts, strings.Join(dowParts, " and "))
+	}
+
+	if len(expr.monthList) != 12 {
+		var names []string
+		for _, month := range expr.monthList {
+			names = append(names, time.Month(month).String())
+		}
+		parts = append(parts, "in "+joinWords(names))
+	}
+	if len(expr.yearList) != len(yearDescriptor.defaultList) {
+		parts = append(parts, "in "+describeList(expr.yearList, yearDescriptor, "year"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeList describe field values, example: "every minute", "every 15 minutes", "minute 0, 30", "hour 8 through 17"
+func describeList(values []int, desc fieldDescriptor, unit string) string {
+	name := strconv.Itoa
+	if slices.Equal(values, desc.defaultList) {
+		return "every " + unit
+	}
+	if len(values) == 1 {
+		return unit + " " + name(values[0])
+	}
+
+	sort.Ints(values)
+	step := values[1] - values[0]
+	isProgression := true
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			isProgression = false
+			break
+		}
+	}
+	if isProgression {
+		if step == 1 {
+			return unit + " " + name(values[0]) + " through " + name(values[len(values)-1])
+		}
+		if values[0] == desc.min && values[len(values)-1]+step > desc.max {
+			return fmt.Sprintf("every %d %ss", step, unit)
+		}
+	}
+
+	names := make([]string, len(values))
+	for i, val := range values {
+		names[i] = name(val)
+	}
+	return unit + " " + strings.Join(names, ", ")
+}
+
+func weekdayNames(days []int) (names []string) {
+	for _, day := range days {
+		names = append(names, time.Weekday(day%7).String())
+	}
+	return names
+}
+
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	case 2:
+		return words[0] + " and " + words[1]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + ", and " + words[len(words)-1]
+}
+
+func ordinal(n int) string {
+	switch n {
+	case 1:
+		return "first"
+	case 2:
+		return "second"
+	case 3:
+		return "third"
+	case 4:
+		return "fourth"
+	}
+	return "fifth"
+}
@@ -0,0 +1,95 @@
+package cronexpr
+
+import (
+	"sort"
+	"time"
+)
+
+// ParseOption option func of parse cron expression
+type ParseOption func(*expression)
+
+// SkipNonexistentTime parse option, skip wall clock time not exist in location because of daylight saving time
+// spring forward transition (default roll forward to the transition instant, so job fire once after the transition)
+func SkipNonexistentTime() ParseOption {
+	return func(expr *expression) {
+		expr.skipNonexistent = true
+	}
+}
+
+// RepeatAmbiguousTime parse option, fire at both occurrence of wall clock time repeated by daylight saving time
+// fall back transition (default fire once at first occurrence)
+func RepeatAmbiguousTime() ParseOption {
+	return func(expr *expression) {
+		expr.repeatAmbiguous = true
+	}
+}
+
+// nextInLocation closest instant in location of `fromTime` immediately following `fromTime` with wall clock time
+// matching the cron expression after `wall`
+func (expr *expression) nextInLocation(fromTime, wall time.Time) time.Time {
+	loc := fromTime.Location()
+	for {
+		wall = expr.nextWall(wall)
+		if wall.IsZero() {
+			return wall
+		}
+
+		instants := occurrences(wall, loc)
+		if len(instants) == 0 {
+			if transition := gapEnd(wall, loc); !expr.skipNonexistent && transition.After(fromTime) {
+				return transition
+			}
+			continue
+		}
+		for i, instant := range instants {
+			if instant.After(fromTime) && (i == 0 || expr.repeatAmbiguous) {
+				return instant
+			}
+		}
+	}
+}
+
+// wallClock wall clock time of t in UTC
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// occurrences instants of wall clock time in location sorted ascending, empty if wall clock time is skipped
+// (spring forward) and two instants if wall clock time is repeated (fall back)
+func occurrences(wall time.Time, loc *time.Location) (instants []time.Time) {
+	local := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	_, offset := local.Zone()
+	offsets := []int{offset}
+	start, end := local.ZoneBounds()
+	if !start.IsZero() {
+		_, offset = start.Add(-time.Nanosecond).Zone()
+		offsets = append(offsets, offset)
+	}
+	if !end.IsZero() {
+		_, offset = end.Zone()
+		offsets = append(offsets, offset)
+	}
+
+	for _, offset := range offsets {
+		instant := time.Unix(wall.Unix()-int64(offset), int64(wall.Nanosecond())).In(loc)
+		if !wallClock(instant).Equal(wall) {
+			continue
+		}
+		if i := sort.Search(len(instants), func(i int) bool { return !instants[i].Before(instant) }); i == len(instants) {
+			instants = append(instants, instant)
+		} else if !instants[i].Equal(instant) {
+			instants = append(instants[:i], append([]time.Time{instant}, instants[i:]...)...)
+		}
+	}
+	return instants
+}
+
+// gapEnd spring forward transition instant of skipped wall clock time
+func gapEnd(wall time.Time, loc *time.Location) time.Time {
+	local := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	start, end := local.ZoneBounds()
+	if wallClock(local).After(wall) {
+		return start
+	}
+	return end
+}
@@ -30,6 +30,8 @@ type expression struct {
 	lastWeekDaysOfWeek     map[int]bool
 	daysOfWeekRestricted   bool
 	yearList               []int
+	skipNonexistent        bool
+	repeatAmbiguous        bool
 }
 
 // MustParse returns a new Expression pointer. It expects a well-formed cron
@@ -37,8 +39,8 @@ type expression struct {
 // See <https://github.com/gorhill/cronexpr#implementation> for documentation
 // about what is a well-formed cron expression from this library's point of
 // view.
-func MustParse(cronLine string) Schedule {
-	expr, err := Parse(cronLine)
+func MustParse(cronLine string, opts ...ParseOption) Schedule {
+	expr, err := Parse(cronLine, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -49,8 +51,8 @@ func MustParse(cronLine string) Schedule {
 // cron expression is supplied.
 // See <https://github.com/gorhill/cronexpr#implementation> for documentation
 // about what is a well-formed cron expression from this library's point of
-// view.
-func Parse(cronLine string) (Schedule, error) {
+// view. Daylight saving time behavior can be configured with ParseOption.
+func Parse(cronLine string, opts ...ParseOption) (Schedule, error) {
 
 	// Maybe one of the built-in aliases is being used
 	cron := cronNormalizer.Replace(cronLine)
@@ -125,6 +127,9 @@ func Parse(cronLine string) (Schedule, error) {
 		expr.yearList = yearDescriptor.defaultList
 	}
 
+	for _, opt := range opts {
+		opt(&expr)
+	}
 	return &expr, nil
 }
 
@@ -136,12 +141,35 @@ func Parse(cronLine string) (Schedule, error) {
 //
 // The zero value of time.Time is returned if no matching time instant exists
 // or if a `fromTime` is itself a zero value.
+//
+// Expression is matched against wall clock time of `fromTime` location. In
+// location with daylight saving time, wall clock time skipped by spring forward
+// transition roll forward to the transition instant and wall clock time repeated
+// by fall back transition fire once at first occurrence, unless configured with
+// SkipNonexistentTime or RepeatAmbiguousTime.
 func (expr *expression) Next(fromTime time.Time) time.Time {
 	// Special case
 	if fromTime.IsZero() {
 		return fromTime
 	}
 
+	next := expr.nextInLocation(fromTime, wallClock(fromTime))
+	if expr.repeatAmbiguous {
+		// wall clock time repeated after next transition is matched again from start of repeated period
+		if _, end := fromTime.ZoneBounds(); !end.IsZero() {
+			repeated := expr.nextInLocation(fromTime, wallClock(end).Add(-time.Nanosecond))
+			if !repeated.IsZero() && (next.IsZero() || repeated.Before(next)) {
+				next = repeated
+			}
+		}
+	}
+	return next
+}
+
+// nextWall returns the closest wall clock time (in UTC, without daylight saving
+// time) immediately following `fromTime` which matches the cron expression.
+func (expr *expression) nextWall(fromTime time.Time) time.Time {
+
 	// Since expr.nextSecond()-expr.nextMonth() expects that the
 	// supplied time stamp is a perfect match to the underlying cron
 	// expression, and since this function is an entry point where `fromTime`
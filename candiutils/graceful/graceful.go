@@ -0,0 +1,237 @@
+// Package graceful zero downtime restart for bare-metal/VM deployment without orchestrator.
+// New process of current binary is started (triggered by SIGUSR2) and accept connection from same port
+// before old process drain and stop, listening socket is passed to new process (mode "handoff")
+// or bound again by new process with SO_REUSEPORT (mode "reuseport"), see GRACEFUL_RESTART environment
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/config/env"
+)
+
+const (
+	// ModeHandoff pass listening socket to new process
+	ModeHandoff = "handoff"
+	// ModeReusePort new process bind same port with SO_REUSEPORT
+	ModeReusePort = "reuseport"
+
+	envListenFDs = "CANDI_GRACEFUL_LISTEN_FDS"
+	envReadyFD   = "CANDI_GRACEFUL_READY_FD"
+	envPIDFile   = "GRACEFUL_RESTART_PID_FILE"
+)
+
+var (
+	mu          sync.Mutex
+	parseOnce   sync.Once
+	inherited   = map[string]*os.File{}
+	listeners   = map[string]net.Listener{}
+	restartLock sync.Mutex
+)
+
+// Mode active graceful restart mode from GRACEFUL_RESTART environment, empty if disabled
+func Mode() string {
+	return env.BaseEnv().GracefulRestart
+}
+
+// Enabled check if graceful restart is enabled
+func Enabled() bool {
+	return Mode() != ""
+}
+
+// RestartSignals signal for trigger graceful restart, empty if not supported in current platform
+func RestartSignals() []os.Signal {
+	return restartSignals
+}
+
+// Listen announce on local network address, use listener inherited from old process if exist,
+// or bind with SO_REUSEPORT in mode "reuseport"
+func Listen(network, address string) (net.Listener, error) {
+	parseOnce.Do(parseInherited)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := network + "://" + address
+	if f, ok := inherited[key]; ok {
+		delete(inherited, key)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err == nil {
+			listeners[key] = l
+			return l, nil
+		}
+	}
+
+	var lc net.ListenConfig
+	if Mode() == ModeReusePort {
+		lc.Control = reusePortControl
+	}
+	l, err := lc.Listen(context.Background(), network, address)
+	if err != nil {
+		return nil, err
+	}
+	listeners[key] = l
+	return l, nil
+}
+
+// IsChild check if current process is started from graceful restart
+func IsChild() bool {
+	return os.Getenv(envReadyFD) != ""
+}
+
+// Ready notify old process that current process is ready to serve (old process will drain and stop),
+// close all unused inherited listener and write pid file if GRACEFUL_RESTART_PID_FILE environment is set
+func Ready() error {
+	parseOnce.Do(parseInherited)
+
+	mu.Lock()
+	for key, f := range inherited {
+		f.Close()
+		delete(inherited, key)
+	}
+	mu.Unlock()
+
+	if pidFile := os.Getenv(envPIDFile); pidFile != "" {
+		if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+			return err
+		}
+	}
+
+	fd, err := strconv.Atoi(os.Getenv(envReadyFD))
+	if err != nil {
+		return nil
+	}
+	os.Unsetenv(envReadyFD)
+	f := os.NewFile(uintptr(fd), "graceful-ready")
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+// Restart start new process of current binary with same arguments and environment,
+// wait until new process is ready (see Ready) or context is done
+func Restart(ctx context.Context) (pid int, err error) {
+	if !Enabled() {
+		return 0, errors.New("graceful restart is disabled, set GRACEFUL_RESTART environment")
+	}
+	if !restartLock.TryLock() {
+		return 0, errors.New("graceful restart is in progress")
+	}
+	defer restartLock.Unlock()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	defer readyReader.Close()
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, envListenFDs+"=") && !strings.HasPrefix(e, envReadyFD+"=") {
+			cmd.Env = append(cmd.Env, e)
+		}
+	}
+
+	if Mode() == ModeHandoff {
+		specs, files, err := listenerFiles()
+		defer func() {
+			for _, f := range files {
+				f.Close()
+			}
+		}()
+		if err != nil {
+			readyWriter.Close()
+			return 0, err
+		}
+		cmd.ExtraFiles = files
+		cmd.Env = append(cmd.Env, envListenFDs+"="+strings.Join(specs, ";"))
+	}
+	// extra files start from fd 3 in new process (after stdin, stdout, stderr)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, readyWriter)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", envReadyFD, 2+len(cmd.ExtraFiles)))
+
+	err = cmd.Start()
+	readyWriter.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	ready, exited := make(chan error, 1), make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		_, err := readyReader.Read(b)
+		ready <- err
+	}()
+	go func() { exited <- cmd.Wait() }()
+
+	select {
+	case err = <-ready:
+		if err == nil {
+			return cmd.Process.Pid, nil
+		}
+		err = fmt.Errorf("new process not ready: %w", err)
+	case err = <-exited:
+		err = fmt.Errorf("new process exited before ready: %v", err)
+	case <-ctx.Done():
+		err = fmt.Errorf("waiting new process ready: %w", ctx.Err())
+	}
+	cmd.Process.Kill()
+	return 0, err
+}
+
+// listenerFiles duplicate all active listener socket for passing to new process
+func listenerFiles() (specs []string, files []*os.File, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	keys := make([]string, 0, len(listeners))
+	for key := range listeners {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		filer, ok := listeners[key].(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		f, err := filer.File()
+		if err != nil {
+			return specs, files, fmt.Errorf("%s: %w", key, err)
+		}
+		files = append(files, f)
+		specs = append(specs, fmt.Sprintf("%s=%d", key, 2+len(files)))
+	}
+	return specs, files, nil
+}
+
+// parseInherited parse listener passed from old process, format: "{network}://{address}={fd};..."
+func parseInherited() {
+	specs := os.Getenv(envListenFDs)
+	os.Unsetenv(envListenFDs)
+	for _, spec := range strings.Split(specs, ";") {
+		i := strings.LastIndexByte(spec, '=')
+		if i <= 0 {
+			continue
+		}
+		fd, err := strconv.Atoi(spec[i+1:])
+		if err != nil {
+			continue
+		}
+		inherited[spec[:i]] = os.NewFile(uintptr(fd), spec[:i])
+	}
+}
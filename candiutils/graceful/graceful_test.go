@@ -0,0 +1,103 @@
+package graceful
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/config/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	if IsChild() {
+		// new process from TestRestart: accept connection from inherited listener
+		env.SetEnv(env.Env{GracefulRestart: ModeHandoff})
+		l, err := Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			os.Exit(1)
+		}
+		Ready()
+		l.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		if conn, err := l.Accept(); err == nil {
+			conn.Write([]byte("new process"))
+			conn.Close()
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func resetState() {
+	parseOnce = sync.Once{}
+	inherited, listeners = map[string]*os.File{}, map[string]net.Listener{}
+}
+
+func TestListenReusePort(t *testing.T) {
+	if len(RestartSignals()) == 0 {
+		t.Skip("SO_REUSEPORT is not supported")
+	}
+	env.SetEnv(env.Env{GracefulRestart: ModeReusePort})
+	defer env.SetEnv(env.Env{})
+	resetState()
+
+	l1, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l1.Close()
+
+	// bind same port from another socket
+	l2, err := Listen("tcp", l1.Addr().String())
+	assert.NoError(t, err)
+	defer l2.Close()
+}
+
+func TestListenInherited(t *testing.T) {
+	env.SetEnv(env.Env{GracefulRestart: ModeHandoff})
+	defer env.SetEnv(env.Env{})
+	resetState()
+
+	l, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	assert.NoError(t, err)
+
+	resetState()
+	os.Setenv(envListenFDs, "tcp://127.0.0.1:0="+strconv.Itoa(int(f.Fd())))
+	inheritedListener, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer inheritedListener.Close()
+	assert.Equal(t, l.Addr().String(), inheritedListener.Addr().String())
+	assert.Empty(t, os.Getenv(envListenFDs))
+}
+
+func TestRestart(t *testing.T) {
+	if len(RestartSignals()) == 0 {
+		t.Skip("graceful restart is not supported")
+	}
+	env.SetEnv(env.Env{GracefulRestart: ModeHandoff})
+	defer env.SetEnv(env.Env{})
+
+	resetState()
+	l, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	pid, err := Restart(ctx)
+	assert.NoError(t, err)
+	assert.NotZero(t, pid)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	b, _ := io.ReadAll(conn)
+	assert.Equal(t, "new process", string(b))
+}
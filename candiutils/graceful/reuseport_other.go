@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package graceful
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+var restartSignals []os.Signal
+
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported in this platform")
+}
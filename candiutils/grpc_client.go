@@ -0,0 +1,259 @@
+package candiutils
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/tracer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// GRPCRoundRobin load balancing policy, spread call to all resolved address
+	GRPCRoundRobin = "round_robin"
+	// GRPCPickFirst load balancing policy, use first connected address
+	GRPCPickFirst = "pick_first"
+
+	// GRPCClientTargetsEnv env key of named gRPC targets, format: "name=address,name=address"
+	GRPCClientTargetsEnv = "GRPC_CLIENT_TARGETS"
+)
+
+type (
+	// GRPCTarget config of named gRPC target
+	GRPCTarget struct {
+		// Address target address with resolver scheme (default dns), example: "dns:///payment-service:8080"
+		Address string
+		// LoadBalancing GRPCRoundRobin (default) or GRPCPickFirst
+		LoadBalancing string
+		// TLS use transport security with system cert pool, default insecure
+		TLS            bool
+		MethodPolicies []GRPCMethodPolicy
+		DialOptions    []grpc.DialOption
+	}
+
+	// GRPCMethodPolicy timeout and retry policy of gRPC method
+	GRPCMethodPolicy struct {
+		// Service full service name (example: "payment.PaymentService"), empty for all service
+		Service string
+		// Method empty for all method in service
+		Method  string
+		Timeout time.Duration
+		// MaxAttempts number of attempt include original call, retry disabled if less than 2
+		MaxAttempts int
+		// InitialBackoff default 100ms, MaxBackoff default 1s
+		InitialBackoff, MaxBackoff time.Duration
+		// RetryableCodes default Unavailable
+		RetryableCodes []codes.Code
+	}
+
+	// GRPCClientOptionFunc option func of gRPC client
+	GRPCClientOptionFunc func(*grpcClient)
+
+	grpcClient struct {
+		mu          sync.Mutex
+		targets     map[string]GRPCTarget
+		conns       map[string]*grpc.ClientConn
+		dialOptions []grpc.DialOption
+	}
+)
+
+// GRPCClientOptionAddTarget add named target
+func GRPCClientOptionAddTarget(name string, target GRPCTarget) GRPCClientOptionFunc {
+	return func(c *grpcClient) {
+		c.targets[name] = target
+	}
+}
+
+// GRPCClientOptionTargetsFromEnv add named targets from env GRPC_CLIENT_TARGETS
+// (example: "payment=dns:///payment-service:8080,user=dns:///user-service:8080")
+func GRPCClientOptionTargetsFromEnv() GRPCClientOptionFunc {
+	return func(c *grpcClient) {
+		for _, pair := range strings.Split(os.Getenv(GRPCClientTargetsEnv), ",") {
+			name, address, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || name == "" {
+				continue
+			}
+			target := c.targets[name]
+			target.Address = address
+			c.targets[name] = target
+		}
+	}
+}
+
+// GRPCClientOptionDialOptions add dial options for all targets
+func GRPCClientOptionDialOptions(opts ...grpc.DialOption) GRPCClientOptionFunc {
+	return func(c *grpcClient) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// NewGRPCClient managed gRPC client of named targets, connection is created on first use and shared (multiplexed),
+// outgoing call is traced and propagate trace context and tenant in metadata
+func NewGRPCClient(opts ...GRPCClientOptionFunc) interfaces.GRPCClient {
+	c := &grpcClient{
+		targets: make(map[string]GRPCTarget),
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *grpcClient) Conn(name string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[name]; ok {
+		return conn, nil
+	}
+	target, ok := c.targets[name]
+	if !ok || target.Address == "" {
+		return nil, fmt.Errorf("grpc client: target %q is not registered", name)
+	}
+
+	serviceConfig, err := target.serviceConfig()
+	if err != nil {
+		return nil, fmt.Errorf("grpc client: target %q: %w", name, err)
+	}
+	creds := insecure.NewCredentials()
+	if target.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	dialOptions := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithChainUnaryInterceptor(grpcClientUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(grpcClientStreamInterceptor),
+	}, c.dialOptions...)
+	dialOptions = append(dialOptions, target.DialOptions...)
+
+	address := target.Address
+	if !strings.Contains(address, ":///") {
+		address = "dns:///" + address
+	}
+	conn, err := grpc.NewClient(address, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc client: target %q: %w", name, err)
+	}
+	c.conns[name] = conn
+	return conn, nil
+}
+
+func (c *grpcClient) Disconnect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	mErr := candihelper.NewMultiError()
+	for name, conn := range c.conns {
+		mErr.Append(name, conn.Close())
+		delete(c.conns, name)
+	}
+	if mErr.HasError() {
+		return mErr
+	}
+	return nil
+}
+
+// serviceConfig gRPC service config JSON of load balancing and method policies
+func (t *GRPCTarget) serviceConfig() (string, error) {
+	loadBalancing := t.LoadBalancing
+	if loadBalancing == "" {
+		loadBalancing = GRPCRoundRobin
+	}
+	if loadBalancing != GRPCRoundRobin && loadBalancing != GRPCPickFirst {
+		return "", fmt.Errorf("unsupported load balancing %q", loadBalancing)
+	}
+
+	config := map[string]any{
+		"loadBalancingConfig": []map[string]any{{loadBalancing: map[string]any{}}},
+	}
+	var methodConfigs []map[string]any
+	for _, policy := range t.MethodPolicies {
+		name := map[string]string{"service": policy.Service}
+		if policy.Method != "" {
+			name["method"] = policy.Method
+		}
+		methodConfig := map[string]any{"name": []map[string]string{name}}
+		if policy.Timeout > 0 {
+			methodConfig["timeout"] = durationProto(policy.Timeout)
+		}
+		if policy.MaxAttempts > 1 {
+			retryableCodes := policy.RetryableCodes
+			if len(retryableCodes) == 0 {
+				retryableCodes = []codes.Code{codes.Unavailable}
+			}
+			methodConfig["retryPolicy"] = map[string]any{
+				"maxAttempts":          policy.MaxAttempts,
+				"initialBackoff":       durationProto(cmp.Or(policy.InitialBackoff, 100*time.Millisecond)),
+				"maxBackoff":           durationProto(cmp.Or(policy.MaxBackoff, time.Second)),
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": retryableCodes,
+			}
+		}
+		methodConfigs = append(methodConfigs, methodConfig)
+	}
+	if len(methodConfigs) > 0 {
+		config["methodConfig"] = methodConfigs
+	}
+
+	b, err := json.Marshal(config)
+	return string(b), err
+}
+
+// durationProto duration in JSON format of google.protobuf.Duration
+func durationProto(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
+
+func grpcClientOutgoingContext(ctx context.Context, trace tracer.Tracer) context.Context {
+	header := map[string]string{}
+	trace.InjectRequestHeader(header)
+	if tenantID := candishared.GetTenantFromContext(ctx); tenantID != "" {
+		header[strings.ToLower(candihelper.HeaderXTenantID)] = tenantID
+	}
+	candishared.InjectBaggage(ctx, header)
+	candishared.InjectRequestID(ctx, header)
+	pairs := make([]string, 0, len(header)*2)
+	for k, v := range header {
+		pairs = append(pairs, strings.ToLower(k), v)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func grpcClientUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "GRPCClient")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+	trace.SetTag("method", method)
+	trace.SetTag("target", cc.Target())
+	trace.Log("request", req)
+
+	err = invoker(grpcClientOutgoingContext(ctx, trace), method, req, reply, cc, opts...)
+	if err == nil {
+		trace.Log("response", reply)
+	}
+	return err
+}
+
+func grpcClientStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (stream grpc.ClientStream, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "GRPCClientStream")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+	trace.SetTag("method", method)
+	trace.SetTag("target", cc.Target())
+
+	return streamer(grpcClientOutgoingContext(ctx, trace), desc, cc, method, opts...)
+}
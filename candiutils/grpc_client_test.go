@@ -0,0 +1,61 @@
+package candiutils
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCClient(t *testing.T) {
+	var incoming metadata.MD
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		incoming, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	client := NewGRPCClient(
+		GRPCClientOptionAddTarget("health", GRPCTarget{
+			Address: "passthrough:///bufnet",
+			MethodPolicies: []GRPCMethodPolicy{
+				{Service: "grpc.health.v1.Health", Timeout: time.Second, MaxAttempts: 3},
+			},
+			DialOptions: []grpc.DialOption{
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			},
+		}),
+	)
+
+	_, err := client.Conn("unknown")
+	assert.Error(t, err)
+
+	conn, err := client.Conn("health")
+	require.NoError(t, err)
+	sameConn, _ := client.Conn("health")
+	assert.Same(t, conn, sameConn)
+
+	ctx := candishared.SetTenantToContext(context.Background(), "acme")
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, []string{"acme"}, incoming.Get("x-tenant-id"))
+
+	assert.NoError(t, client.Disconnect(context.Background()))
+
+	target := GRPCTarget{LoadBalancing: "random"}
+	_, err = target.serviceConfig()
+	assert.Error(t, err)
+}
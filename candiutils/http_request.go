@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/deadline"
 	"github.com/golangid/candi/tracer"
 )
 
@@ -45,9 +50,27 @@ type (
 
 	// HTTPRequestOption func type
 	HTTPRequestOption func(*httpRequestImpl)
+
+	// HTTPStatusError error from http response code above error code threshold
+	HTTPStatusError struct {
+		Status   string
+		RespCode int
+	}
 )
 
-// HTTPRequestSetRetries option func
+// Error implement error
+func (e *HTTPStatusError) Error() string {
+	return e.Status
+}
+
+// HTTPStatusCode implement http status error, used by candierrors for classify error code
+func (e *HTTPStatusError) HTTPStatusCode() int {
+	return e.RespCode
+}
+
+// HTTPRequestSetRetries option func, max retry for retryable error (transient or throttled, see candierrors.ClassOf),
+// default 0 (not retried). Only idempotent method (GET, HEAD, PUT, DELETE, OPTIONS) is retried, other method
+// (POST, PATCH) is retried only when request has "Idempotency-Key" header
 func HTTPRequestSetRetries(retries int) HTTPRequestOption {
 	return func(h *httpRequestImpl) {
 		h.retries = retries
@@ -102,7 +125,7 @@ func NewHTTPRequest(opts ...HTTPRequestOption) HTTPRequest {
 	httpReq := new(httpRequestImpl)
 
 	// set default value
-	httpReq.retries = 5
+	httpReq.retries = 0
 	httpReq.sleepBetweenRetry = 500 * time.Millisecond
 	httpReq.minHTTPErrorCodeThreshold = http.StatusBadRequest
 	httpReq.timeout = 10 * time.Second
@@ -139,12 +162,48 @@ func (req *httpRequestImpl) Do(ctx context.Context, method, url string, requestB
 	return httpResult.Bytes(), httpResult.RespCode, nil
 }
 
+// DoRequest function, for http client call with retry when error is retryable and request is idempotent,
+// delay between retry is max of sleep between retry and retry after from throttled response
 func (req *httpRequestImpl) DoRequest(ctx context.Context, method, url string, requestBody []byte, headers map[string]string) (result *HTTPRequestResult, err error) {
+	retries := req.retries
+	if !isIdempotentRequest(method, headers) {
+		// request may be committed in server before timeout or 5xx, retry will duplicate side effect
+		retries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		result, err = req.doRequest(ctx, method, url, requestBody, headers)
+		if err == nil || attempt >= retries || !candierrors.IsRetryable(err) {
+			return result, err
+		}
+
+		delay := max(req.sleepBetweenRetry, candierrors.RetryAfter(err))
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func isIdempotentRequest(method string, headers map[string]string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, candihelper.HeaderIdempotencyKey) && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (req *httpRequestImpl) doRequest(ctx context.Context, method, url string, requestBody []byte, headers map[string]string) (result *HTTPRequestResult, err error) {
 	// set request http
 	httpReq, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		tracer.SetError(ctx, err)
-		return nil, err
+		return nil, candierrors.Permanent(err)
 	}
 
 	// set tracer
@@ -155,6 +214,9 @@ func (req *httpRequestImpl) DoRequest(ctx context.Context, method, url string, r
 		headers = map[string]string{}
 	}
 	trace.InjectRequestHeader(headers)
+	deadline.InjectHeader(ctx, headers)
+	candishared.InjectBaggage(ctx, headers)
+	candishared.InjectRequestID(ctx, headers)
 
 	// iterate optional data of headers
 	for key, value := range headers {
@@ -192,7 +254,25 @@ func (req *httpRequestImpl) DoRequest(ctx context.Context, method, url string, r
 	trace.Log("response.body", result.Bytes())
 
 	if req.minHTTPErrorCodeThreshold != 0 && resp.StatusCode >= req.minHTTPErrorCodeThreshold {
-		err = errors.New(resp.Status)
+		err = &HTTPStatusError{Status: resp.Status, RespCode: resp.StatusCode}
+		isThrottled := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); isThrottled && retryAfter > 0 {
+			err = candierrors.Throttled(err, retryAfter)
+		}
 	}
 	return
 }
+
+// parseRetryAfter parse Retry-After header value in delay seconds or http date format
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
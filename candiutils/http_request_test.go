@@ -0,0 +1,65 @@
+package candiutils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRequestRetry(t *testing.T) {
+	var calls int
+	statuses := []int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[calls]
+		calls++
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	httpReq := NewHTTPRequest(HTTPRequestSetRetries(2), HTTPRequestSetSleepBetweenRetry(time.Millisecond))
+
+	statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	body, code, err := httpReq.Do(ctx, http.MethodGet, server.URL, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, 3, calls)
+
+	// permanent error is not retried
+	calls, statuses = 0, []int{http.StatusBadRequest, http.StatusOK}
+	_, code, err = httpReq.Do(ctx, http.MethodGet, server.URL, nil, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, candierrors.ClassPermanent, candierrors.ClassOf(err))
+	assert.Equal(t, 1, calls)
+
+	calls, statuses = 0, []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}
+	_, _, err = httpReq.Do(ctx, http.MethodGet, server.URL, nil, nil)
+	assert.Equal(t, "502 Bad Gateway", err.Error())
+	assert.Equal(t, 3, calls)
+
+	// non idempotent method is not retried unless has idempotency key
+	calls, statuses = 0, []int{http.StatusServiceUnavailable, http.StatusOK}
+	_, code, err = httpReq.Do(ctx, http.MethodPost, server.URL, []byte(`{}`), nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, 1, calls)
+
+	calls, statuses = 0, []int{http.StatusServiceUnavailable, http.StatusOK}
+	_, code, err = httpReq.Do(ctx, http.MethodPatch, server.URL, []byte(`{}`), map[string]string{"idempotency-key": "order-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, calls)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5"))
+	assert.Zero(t, parseRetryAfter("invalid"))
+}
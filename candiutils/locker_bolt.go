@@ -0,0 +1,185 @@
+package candiutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const boltLockerBucket = "candi_locker"
+
+// BoltLocker lock using embedded key-value store (bbolt), only lock concurrent process in one runtime
+// (bbolt file cannot be opened by multiple process)
+type BoltLocker struct {
+	db            *bolt.DB
+	bucket        []byte
+	lockeroptions LockerOptions
+	pollInterval  time.Duration
+}
+
+// NewBoltLocker constructor
+func NewBoltLocker(db *bolt.DB, opts ...LockerOption) *BoltLocker {
+	lockeroptions := LockerOptions{
+		Prefix: "LOCKFOR",
+		TTL:    0,
+	}
+	for _, opt := range opts {
+		opt(&lockeroptions)
+	}
+
+	l := &BoltLocker{db: db, bucket: []byte(boltLockerBucket), lockeroptions: lockeroptions, pollInterval: 50 * time.Millisecond}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(l.bucket)
+		return err
+	}); err != nil {
+		panic("bolt locker: " + err.Error())
+	}
+	return l
+}
+
+// GetPrefixLocker returns the prefix used for keys
+func (b *BoltLocker) GetPrefixLocker() string {
+	return b.lockeroptions.Prefix + ":"
+}
+
+// GetTTLLocker returns the default TTL for keys
+func (b *BoltLocker) GetTTLLocker() time.Duration {
+	return b.lockeroptions.TTL
+}
+
+// IsLocked method
+func (b *BoltLocker) IsLocked(key string) bool {
+	return b.incr(key, 0) > 1
+}
+
+// IsLockedTTL method
+func (b *BoltLocker) IsLockedTTL(key string, ttl time.Duration) bool {
+	if ttl <= 0 {
+		ttl = b.lockeroptions.TTL
+	}
+	return b.incr(key, ttl) > 1
+}
+
+// HasBeenLocked method
+func (b *BoltLocker) HasBeenLocked(key string) (locked bool) {
+	b.db.View(func(tx *bolt.Tx) error {
+		count, _, ok := decodeBoltCounter(tx.Bucket(b.bucket).Get(b.lockKey(key)))
+		locked = ok && count > 0
+		return nil
+	})
+	return
+}
+
+// Unlock method
+func (b *BoltLocker) Unlock(key string) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Delete(b.lockKey(key))
+	})
+}
+
+// Reset method, key can contains "*" suffix for reset all matched prefix
+func (b *BoltLocker) Reset(key string) {
+	b.deletePrefix(string(b.lockKey(strings.TrimSuffix(key, "*"))), strings.HasSuffix(key, "*"))
+}
+
+// Disconnect close and reset
+func (b *BoltLocker) Disconnect(ctx context.Context) error {
+	b.deletePrefix(b.GetPrefixLocker(), true)
+	return nil
+}
+
+// Lock method, wait until another process unlock given key or timeout
+func (b *BoltLocker) Lock(key string, timeout time.Duration) (unlockFunc func(), err error) {
+	if timeout <= 0 {
+		return func() {}, errors.New("timeout must be positive")
+	}
+	if key == "" {
+		return func() {}, errors.New("key cannot empty")
+	}
+
+	unlockFunc = func() { b.Unlock(key) }
+	if !b.IsLocked(key) {
+		return unlockFunc, nil
+	}
+
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-ticker.C:
+			if !b.HasBeenLocked(key) && !b.IsLocked(key) {
+				return unlockFunc, nil
+			}
+
+		case <-deadline:
+			b.Unlock(key)
+			return unlockFunc, errors.New("timeout when waiting unlock another process")
+		}
+	}
+}
+
+func (b *BoltLocker) lockKey(key string) []byte {
+	return []byte(b.lockeroptions.Prefix + ":" + key)
+}
+
+func (b *BoltLocker) incr(key string, ttl time.Duration) (count uint64) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		lockKey := b.lockKey(key)
+		var expiredAt time.Time
+		count, expiredAt, _ = decodeBoltCounter(tx.Bucket(b.bucket).Get(lockKey))
+		count++
+		if ttl > 0 {
+			expiredAt = time.Now().Add(ttl)
+		}
+		return tx.Bucket(b.bucket).Put(lockKey, encodeBoltCounter(count, expiredAt))
+	})
+	return count
+}
+
+func (b *BoltLocker) deletePrefix(prefix string, isPattern bool) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		if !isPattern {
+			return bucket.Delete([]byte(prefix))
+		}
+
+		var keys [][]byte
+		cur := bucket.Cursor()
+		for k, _ := cur.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = cur.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		for _, k := range keys {
+			bucket.Delete(k)
+		}
+		return nil
+	})
+}
+
+// encodeBoltCounter encode lock counter and expired time (unix nano) in 16 bytes
+func encodeBoltCounter(count uint64, expiredAt time.Time) []byte {
+	buff := make([]byte, 16)
+	binary.BigEndian.PutUint64(buff[:8], count)
+	if !expiredAt.IsZero() {
+		binary.BigEndian.PutUint64(buff[8:], uint64(expiredAt.UnixNano()))
+	}
+	return buff
+}
+
+func decodeBoltCounter(raw []byte) (count uint64, expiredAt time.Time, ok bool) {
+	if len(raw) != 16 {
+		return 0, expiredAt, false
+	}
+	if unix := binary.BigEndian.Uint64(raw[8:]); unix > 0 {
+		expiredAt = time.Unix(0, int64(unix))
+		if time.Now().After(expiredAt) {
+			return 0, time.Time{}, false
+		}
+	}
+	return binary.BigEndian.Uint64(raw[:8]), expiredAt, true
+}
@@ -0,0 +1,413 @@
+package candiutils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/gomodule/redigo/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Cursor iterator of repository query result
+type Cursor[T any] interface {
+	Next(ctx context.Context) bool
+	Decode() (T, error)
+	Err() error
+	Close() error
+}
+
+// Checkpoint storage of last processed item key, for resume map-reduce from last checkpoint
+type Checkpoint interface {
+	Load(ctx context.Context, name string) (string, error)
+	Save(ctx context.Context, name, key string) error
+}
+
+// MapReduceConfig config of MapReduce
+type MapReduceConfig[T any] struct {
+	// Workers number of concurrent map func, default 10
+	Workers int
+	// Key unique and ordered key of item (example: primary key), used for checkpoint and item error key
+	Key func(T) string
+	// Name checkpoint name, required if Checkpoint is set
+	Name string
+	// Checkpoint save progress, cursor is opened from last checkpoint (require Key)
+	Checkpoint Checkpoint
+	// CheckpointEvery save checkpoint every n processed item, default 100
+	CheckpointEvery int
+	// MaxErrors stop when failed item reach max errors, zero for unlimited
+	MaxErrors int
+	// StopOnError stop at first failed item and return its error, checkpoint is not advanced past failed item
+	// so resume retry it (item completed concurrently after failed item is processed again)
+	StopOnError bool
+	// Progress called serially after each item is processed or failed
+	Progress func(MapReduceResult)
+}
+
+// MapReduceResult result of MapReduce
+type MapReduceResult struct {
+	Processed, Failed int
+	// Checkpoint key of last item which it and all items before it are processed
+	Checkpoint string
+	// Errors failed item error by item key
+	Errors candihelper.MultiError
+}
+
+/*
+MapReduce iterate cursor with bounded worker pool, map func is run concurrently and reduce func is run serially
+(no need lock for aggregate result). Cursor is opened from last checkpoint (empty if start from beginning),
+query must be ordered by item key and filtered after checkpoint key, example:
+
+	result, err := candiutils.MapReduce(ctx, candiutils.MapReduceConfig[Order]{
+		Workers: 20, Name: "backfill-order-total", Checkpoint: candiutils.NewRedisCheckpoint(redisPool),
+		Key: func(o Order) string { return o.ID },
+	}, func(ctx context.Context, checkpoint string) (candiutils.Cursor[Order], error) {
+		cur, err := db.Collection("orders").Find(ctx, bson.M{"_id": bson.M{"$gt": checkpoint}},
+			options.Find().SetSort(bson.M{"_id": 1}))
+		return candiutils.NewMongoCursor[Order](cur), err
+	}, func(ctx context.Context, o Order) (float64, error) {
+		return o.Total, repo.UpdateOrderTotal(ctx, o)
+	}, func(total float64) {
+		sum += total
+	})
+
+Failed item is collected in result errors and not retried when resume (unless StopOnError). Checkpoint is kept when finished, set checkpoint
+to empty string for restart from beginning.
+*/
+func MapReduce[T, R any](ctx context.Context, conf MapReduceConfig[T],
+	open func(ctx context.Context, checkpoint string) (Cursor[T], error),
+	mapFunc func(context.Context, T) (R, error), reduceFunc func(R)) (result MapReduceResult, err error) {
+
+	result.Errors = candihelper.NewMultiError()
+	if conf.Workers <= 0 {
+		conf.Workers = 10
+	}
+	if conf.CheckpointEvery <= 0 {
+		conf.CheckpointEvery = 100
+	}
+	if conf.Checkpoint != nil {
+		if conf.Key == nil || conf.Name == "" {
+			return result, errors.New("map reduce: key and name are required for checkpoint")
+		}
+		if result.Checkpoint, err = conf.Checkpoint.Load(ctx, conf.Name); err != nil {
+			return result, err
+		}
+	}
+
+	cursor, err := open(ctx, result.Checkpoint)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close()
+
+	type job struct {
+		seq  int
+		key  string
+		item T
+	}
+	type output struct {
+		seq   int
+		key   string
+		value R
+		err   error
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs, outputs := make(chan job), make(chan output)
+	var wg sync.WaitGroup
+	for i := 0; i < conf.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				value, err := mapFunc(workerCtx, j.item)
+				outputs <- output{seq: j.seq, key: j.key, value: value, err: err}
+			}
+		}()
+	}
+
+	var cursorErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for seq := 0; workerCtx.Err() == nil && cursor.Next(workerCtx); seq++ {
+			item, err := cursor.Decode()
+			key := "#" + strconv.Itoa(seq)
+			if err == nil && conf.Key != nil {
+				key = conf.Key(item)
+			}
+			if err != nil {
+				outputs <- output{seq: seq, key: key, err: err}
+				continue
+			}
+			select {
+			case jobs <- job{seq: seq, key: key, item: item}:
+			case <-workerCtx.Done():
+				return
+			}
+		}
+		cursorErr = cursor.Err()
+	}()
+	go func() {
+		wg.Wait()
+		close(outputs)
+	}()
+
+	var stopErr error
+	completed := make(map[int]string)
+	next, unsaved := 0, 0
+	for out := range outputs {
+		if out.err != nil {
+			result.Failed++
+			result.Errors.Append(out.key, out.err)
+			if conf.StopOnError {
+				if stopErr == nil {
+					stopErr = fmt.Errorf("map reduce: stopped at item %s: %w", out.key, out.err)
+					cancel()
+				}
+				if conf.Progress != nil {
+					conf.Progress(result)
+				}
+				continue
+			}
+			if conf.MaxErrors > 0 && result.Failed >= conf.MaxErrors && stopErr == nil {
+				stopErr = fmt.Errorf("map reduce: stopped after %d failed item", result.Failed)
+				cancel()
+			}
+		} else {
+			result.Processed++
+			reduceFunc(out.value)
+		}
+		if conf.Progress != nil {
+			conf.Progress(result)
+		}
+
+		// advance checkpoint to last item which all previous items are completed
+		completed[out.seq] = out.key
+		for key, ok := completed[next]; ok; key, ok = completed[next] {
+			delete(completed, next)
+			next++
+			unsaved++
+			if conf.Key != nil && key != "" {
+				result.Checkpoint = key
+			}
+		}
+		if conf.Checkpoint != nil && unsaved >= conf.CheckpointEvery {
+			if err := conf.Checkpoint.Save(ctx, conf.Name, result.Checkpoint); err != nil && stopErr == nil {
+				stopErr = err
+				cancel()
+			}
+			unsaved = 0
+		}
+	}
+	if conf.Checkpoint != nil && unsaved > 0 {
+		if err := conf.Checkpoint.Save(context.WithoutCancel(ctx), conf.Name, result.Checkpoint); err != nil && stopErr == nil {
+			stopErr = err
+		}
+	}
+
+	switch {
+	case stopErr != nil:
+		return result, stopErr
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	}
+	return result, cursorErr
+}
+
+type sliceCursor[T any] struct {
+	items []T
+	index int
+}
+
+// NewSliceCursor cursor from slice
+func NewSliceCursor[T any](items []T) Cursor[T] {
+	return &sliceCursor[T]{items: items, index: -1}
+}
+
+func (c *sliceCursor[T]) Next(ctx context.Context) bool {
+	c.index++
+	return c.index < len(c.items)
+}
+func (c *sliceCursor[T]) Decode() (T, error) { return c.items[c.index], nil }
+func (c *sliceCursor[T]) Err() error         { return nil }
+func (c *sliceCursor[T]) Close() error       { return nil }
+
+type sqlCursor[T any] struct {
+	rows *sql.Rows
+	scan func(*sql.Rows) (T, error)
+}
+
+// NewSQLCursor cursor from sql rows, scan func scan current row to item
+func NewSQLCursor[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) Cursor[T] {
+	return &sqlCursor[T]{rows: rows, scan: scan}
+}
+
+func (c *sqlCursor[T]) Next(ctx context.Context) bool { return c.rows.Next() }
+func (c *sqlCursor[T]) Decode() (T, error)            { return c.scan(c.rows) }
+func (c *sqlCursor[T]) Err() error                    { return c.rows.Err() }
+func (c *sqlCursor[T]) Close() error                  { return c.rows.Close() }
+
+type mongoCursor[T any] struct {
+	cur *mongo.Cursor
+}
+
+// NewMongoCursor cursor from mongo cursor, document is decoded to item
+func NewMongoCursor[T any](cur *mongo.Cursor) Cursor[T] {
+	return &mongoCursor[T]{cur: cur}
+}
+
+func (c *mongoCursor[T]) Next(ctx context.Context) bool { return c.cur.Next(ctx) }
+func (c *mongoCursor[T]) Decode() (item T, err error) {
+	err = c.cur.Decode(&item)
+	return item, err
+}
+func (c *mongoCursor[T]) Err() error   { return c.cur.Err() }
+func (c *mongoCursor[T]) Close() error { return c.cur.Close(context.Background()) }
+
+type memoryCheckpoint struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+// NewMemoryCheckpoint in memory checkpoint, resume only in same process (example: task queue job retry)
+func NewMemoryCheckpoint() Checkpoint {
+	return &memoryCheckpoint{keys: make(map[string]string)}
+}
+
+func (c *memoryCheckpoint) Load(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keys[name], nil
+}
+
+func (c *memoryCheckpoint) Save(ctx context.Context, name, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys[name] = key
+	return nil
+}
+
+type redisCheckpoint struct {
+	pool *redis.Pool
+}
+
+// NewRedisCheckpoint checkpoint stored in redis key "candi:checkpoint:{name}"
+func NewRedisCheckpoint(pool *redis.Pool) Checkpoint {
+	return &redisCheckpoint{pool: pool}
+}
+
+func (c *redisCheckpoint) Load(ctx context.Context, name string) (string, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+	key, err := redis.String(conn.Do("GET", "candi:checkpoint:"+name))
+	if errors.Is(err, redis.ErrNil) {
+		return "", nil
+	}
+	return key, err
+}
+
+func (c *redisCheckpoint) Save(ctx context.Context, name, key string) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	if key == "" {
+		_, err := conn.Do("DEL", "candi:checkpoint:"+name)
+		return err
+	}
+	_, err := conn.Do("SET", "candi:checkpoint:"+name, key)
+	return err
+}
+
+type mongoCheckpoint struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCheckpoint checkpoint stored in mongo collection (default "candi_checkpoints") with document _id is name
+func NewMongoCheckpoint(db *mongo.Database, collection string) Checkpoint {
+	if collection == "" {
+		collection = "candi_checkpoints"
+	}
+	return &mongoCheckpoint{collection: db.Collection(collection)}
+}
+
+func (c *mongoCheckpoint) Load(ctx context.Context, name string) (string, error) {
+	var checkpoint struct {
+		Key string `bson:"key"`
+	}
+	err := c.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&checkpoint)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	return checkpoint.Key, err
+}
+
+func (c *mongoCheckpoint) Save(ctx context.Context, name, key string) error {
+	if key == "" {
+		_, err := c.collection.DeleteOne(ctx, bson.M{"_id": name})
+		return err
+	}
+	_, err := c.collection.UpdateOne(ctx, bson.M{"_id": name},
+		bson.M{"$set": bson.M{"key": key, "updated_at": time.Now()}}, options.Update().SetUpsert(true))
+	return err
+}
+
+type sqlCheckpoint struct {
+	db          *sql.DB
+	table       string
+	placeholder func(argIndex int) string
+}
+
+// NewSQLCheckpoint checkpoint stored in sql table (postgres, mysql or sqlite3, default "candi_checkpoints"),
+// table is created if not exist
+func NewSQLCheckpoint(db *sql.DB, table string) (Checkpoint, error) {
+	if table == "" {
+		table = "candi_checkpoints"
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+		name VARCHAR(255) NOT NULL PRIMARY KEY,
+		checkpoint_key TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("create checkpoint table: %w", err)
+	}
+	return &sqlCheckpoint{db: db, table: table, placeholder: candishared.SQLPlaceholderOf(db)}, nil
+}
+
+func (c *sqlCheckpoint) Load(ctx context.Context, name string) (key string, err error) {
+	err = c.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT checkpoint_key FROM `+c.table+` WHERE name = ?`, c.placeholder), name).
+		Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return key, err
+}
+
+func (c *sqlCheckpoint) Save(ctx context.Context, name, key string) error {
+	if key == "" {
+		_, err := c.db.ExecContext(ctx, candishared.SQLRebind(`DELETE FROM `+c.table+` WHERE name = ?`, c.placeholder), name)
+		return err
+	}
+	res, err := c.db.ExecContext(ctx, candishared.SQLRebind(`UPDATE `+c.table+` SET checkpoint_key = ? WHERE name = ?`, c.placeholder), key, name)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected > 0 {
+		return nil
+	}
+	// mysql report zero affected row when key is unchanged
+	if existing, err := c.Load(ctx, name); err != nil || existing == key {
+		return err
+	}
+	_, err = c.db.ExecContext(ctx, candishared.SQLRebind(`INSERT INTO `+c.table+` (name, checkpoint_key) VALUES (?, ?)`, c.placeholder), name, key)
+	return err
+}
@@ -0,0 +1,96 @@
+package candiutils
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapReduce(t *testing.T) {
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i + 1
+	}
+	open := func(ctx context.Context, checkpoint string) (Cursor[int], error) {
+		after, _ := strconv.Atoi(checkpoint)
+		return NewSliceCursor(items[after:]), nil
+	}
+	conf := MapReduceConfig[int]{
+		Workers: 8, Name: "sum", Checkpoint: NewMemoryCheckpoint(), CheckpointEvery: 10,
+		Key: func(i int) string { return strconv.Itoa(i) },
+	}
+
+	var sum int
+	var calls atomic.Int32
+	result, err := MapReduce(context.Background(), conf, open, func(ctx context.Context, i int) (int, error) {
+		calls.Add(1)
+		if i == 500 {
+			return 0, errors.New("invalid")
+		}
+		return i * 2, nil
+	}, func(v int) { sum += v })
+	assert.NoError(t, err)
+	assert.Equal(t, 999, result.Processed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, map[string]string{"500": "invalid"}, result.Errors.ToMap())
+	assert.Equal(t, 2*(500500-500), sum)
+	assert.Equal(t, "1000", result.Checkpoint)
+	assert.Equal(t, int32(1000), calls.Load())
+
+	// stop after max errors, then resume from checkpoint
+	conf.Checkpoint.Save(context.Background(), "sum", "")
+	conf.MaxErrors = 1
+	result, err = MapReduce(context.Background(), conf, open, func(ctx context.Context, i int) (int, error) {
+		if i == 500 {
+			return 0, errors.New("invalid")
+		}
+		return i, nil
+	}, func(int) {})
+	assert.Error(t, err)
+	checkpoint, _ := strconv.Atoi(result.Checkpoint)
+	assert.Less(t, checkpoint, 1000)
+
+	conf.MaxErrors = 0
+	calls.Store(0)
+	result, err = MapReduce(context.Background(), conf, open, func(ctx context.Context, i int) (int, error) {
+		calls.Add(1)
+		return i, nil
+	}, func(int) {})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1000-checkpoint), calls.Load())
+	assert.Equal(t, "1000", result.Checkpoint)
+}
+
+func TestMapReduceStopOnError(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	conf := MapReduceConfig[int]{
+		Workers: 1, Name: "stop", Checkpoint: NewMemoryCheckpoint(), CheckpointEvery: 1, StopOnError: true,
+		Key: func(i int) string { return strconv.Itoa(i) },
+	}
+	open := func(ctx context.Context, checkpoint string) (Cursor[int], error) {
+		after, _ := strconv.Atoi(checkpoint)
+		return NewSliceCursor(items[after:]), nil
+	}
+	errInvalid := errors.New("invalid")
+
+	result, err := MapReduce(context.Background(), conf, open, func(ctx context.Context, i int) (int, error) {
+		if i == 3 {
+			return 0, errInvalid
+		}
+		return i, nil
+	}, func(int) {})
+	assert.ErrorIs(t, err, errInvalid)
+	assert.Equal(t, "2", result.Checkpoint, "checkpoint is not advanced past failed item")
+
+	var resumed []int
+	result, err = MapReduce(context.Background(), conf, open, func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}, func(i int) { resumed = append(resumed, i) })
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5}, resumed)
+	assert.Equal(t, "5", result.Checkpoint)
+}
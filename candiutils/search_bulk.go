@@ -0,0 +1,186 @@
+package candiutils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/logger"
+)
+
+type (
+	// SearchBulkIndexer buffer search document operation and write to search engine in bulk request,
+	// failed item with retriable status (429, 502, 503, 504) is retried with exponential backoff
+	SearchBulkIndexer struct {
+		engine        interfaces.SearchEngine
+		mu            sync.Mutex
+		flushMu       sync.Mutex
+		items         []interfaces.SearchBulkItem
+		flushItems    int
+		flushInterval time.Duration
+		maxRetries    int
+		retryBackoff  time.Duration
+		onError       func(interfaces.SearchBulkItem, interfaces.SearchBulkResult)
+
+		stop      chan struct{}
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+
+	// SearchBulkIndexerOption option func type
+	SearchBulkIndexerOption func(*SearchBulkIndexer)
+)
+
+// SearchBulkIndexerSetFlushItems option func, flush when buffered item reach size (default 500), zero disable flush by size
+func SearchBulkIndexerSetFlushItems(size int) SearchBulkIndexerOption {
+	return func(b *SearchBulkIndexer) {
+		b.flushItems = size
+	}
+}
+
+// SearchBulkIndexerSetFlushInterval option func, flush buffered item periodically in background (default 1 second),
+// zero disable background flush
+func SearchBulkIndexerSetFlushInterval(interval time.Duration) SearchBulkIndexerOption {
+	return func(b *SearchBulkIndexer) {
+		b.flushInterval = interval
+	}
+}
+
+// SearchBulkIndexerSetRetry option func, max retry of failed bulk request or retriable item (default 5)
+// and wait interval before first retry, doubled on each retry (default 200ms)
+func SearchBulkIndexerSetRetry(maxRetries int, backoff time.Duration) SearchBulkIndexerOption {
+	return func(b *SearchBulkIndexer) {
+		b.maxRetries, b.retryBackoff = maxRetries, backoff
+	}
+}
+
+// SearchBulkIndexerSetOnError option func, callback for item failed with non retriable error (example: mapping error)
+func SearchBulkIndexerSetOnError(onError func(item interfaces.SearchBulkItem, result interfaces.SearchBulkResult)) SearchBulkIndexerOption {
+	return func(b *SearchBulkIndexer) {
+		b.onError = onError
+	}
+}
+
+// NewSearchBulkIndexer constructor, call Close for flush remaining item when shutdown
+func NewSearchBulkIndexer(engine interfaces.SearchEngine, opts ...SearchBulkIndexerOption) *SearchBulkIndexer {
+	b := &SearchBulkIndexer{
+		engine:        engine,
+		flushItems:    500,
+		flushInterval: time.Second,
+		maxRetries:    5,
+		retryBackoff:  200 * time.Millisecond,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.flushInterval <= 0 {
+		close(b.done)
+		return b
+	}
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(b.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+				if err := b.Flush(context.Background()); err != nil {
+					logger.LogRed("search bulk indexer > " + err.Error())
+				}
+			}
+		}
+	}()
+	return b
+}
+
+// Add buffer item, flush when buffer is full
+func (b *SearchBulkIndexer) Add(ctx context.Context, items ...interfaces.SearchBulkItem) error {
+	b.mu.Lock()
+	b.items = append(b.items, items...)
+	full := b.flushItems > 0 && len(b.items) >= b.flushItems
+	b.mu.Unlock()
+
+	if full {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+// Len count of buffered item
+func (b *SearchBulkIndexer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
+}
+
+// Flush write all buffered item, return error when bulk request or retriable item still failed after max retries
+func (b *SearchBulkIndexer) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	items := b.items
+	b.items = nil
+	b.mu.Unlock()
+
+	backoff := b.retryBackoff
+	for attempt := 0; len(items) > 0; attempt++ {
+		var err error
+		if items, err = b.write(ctx, items); err == nil && len(items) > 0 {
+			err = fmt.Errorf("search bulk indexer: %d items failed with retriable status", len(items))
+		}
+		if err == nil {
+			return nil
+		}
+		if attempt >= b.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return nil
+}
+
+// Close stop background flush and flush remaining item
+func (b *SearchBulkIndexer) Close(ctx context.Context) (err error) {
+	b.closeOnce.Do(func() {
+		if b.flushInterval > 0 {
+			close(b.stop)
+		}
+		<-b.done
+		err = b.Flush(ctx)
+	})
+	return err
+}
+
+// write execute bulk request, return item to be retried
+func (b *SearchBulkIndexer) write(ctx context.Context, items []interfaces.SearchBulkItem) (retry []interfaces.SearchBulkItem, err error) {
+	results, err := b.engine.Bulk(ctx, items)
+	if err != nil {
+		return items, err
+	}
+	for i, result := range results {
+		switch {
+		case result.Error == "":
+		case isRetriableSearchStatus(result.Status):
+			retry = append(retry, items[i])
+		case b.onError != nil:
+			b.onError(items[i], result)
+		default:
+			logger.LogRed(fmt.Sprintf("search bulk indexer > %s %s/%s: %s", items[i].Action, items[i].Index, items[i].ID, result.Error))
+		}
+	}
+	return retry, nil
+}
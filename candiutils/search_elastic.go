@@ -0,0 +1,343 @@
+package candiutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+)
+
+const (
+	// SearchActionIndex bulk action, create or replace document
+	SearchActionIndex = "index"
+	// SearchActionCreate bulk action, create document and failed if document exist
+	SearchActionCreate = "create"
+	// SearchActionUpdate bulk action, partial update document
+	SearchActionUpdate = "update"
+	// SearchActionDelete bulk action, delete document
+	SearchActionDelete = "delete"
+)
+
+// ErrSearchNotFound error when index or document not found in search engine
+var ErrSearchNotFound = errors.New("search engine: not found")
+
+type (
+	// ElasticsearchConfig config for Elasticsearch and OpenSearch client
+	ElasticsearchConfig struct {
+		// Addresses url of cluster node, request is distributed to each node in round robin
+		Addresses []string
+		Username  string
+		Password  string
+		// APIKey base64 encoded API key, used instead of basic auth when not empty
+		APIKey string
+		// MaxRetries max retry (to next node) when connection error or response status is 429, 502, 503 or 504 (default 3)
+		MaxRetries int
+		// RetryBackoff wait interval before first retry, doubled on each retry (default 100ms)
+		RetryBackoff time.Duration
+		HTTPClient   *http.Client
+	}
+
+	// Elasticsearch client for Elasticsearch and OpenSearch REST API with tracing and retry,
+	// implement interfaces.SearchEngine
+	Elasticsearch struct {
+		cfg       ElasticsearchConfig
+		addresses []string
+		next      atomic.Uint64
+	}
+
+	// SearchError error response from search engine
+	SearchError struct {
+		Status int
+		Type   string
+		Reason string
+	}
+)
+
+// Error implement error
+func (e *SearchError) Error() string {
+	return fmt.Sprintf("search engine: status %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// Is match ErrSearchNotFound for status 404
+func (e *SearchError) Is(target error) bool {
+	return target == ErrSearchNotFound && e.Status == http.StatusNotFound
+}
+
+// NewElasticsearch constructor
+func NewElasticsearch(cfg ElasticsearchConfig) (*Elasticsearch, error) {
+	if len(cfg.Addresses) == 0 {
+		return nil, errors.New("elasticsearch: address is required")
+	}
+	e := &Elasticsearch{cfg: cfg}
+	for _, address := range cfg.Addresses {
+		if _, err := url.ParseRequestURI(address); err != nil {
+			return nil, fmt.Errorf("elasticsearch: invalid address %s: %w", address, err)
+		}
+		e.addresses = append(e.addresses, strings.TrimSuffix(address, "/"))
+	}
+	if e.cfg.MaxRetries <= 0 {
+		e.cfg.MaxRetries = 3
+	}
+	if e.cfg.RetryBackoff <= 0 {
+		e.cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	if e.cfg.HTTPClient == nil {
+		e.cfg.HTTPClient = &http.Client{Timeout: time.Minute}
+	}
+	return e, nil
+}
+
+// Do send request to search engine, body is encoded to JSON ([]byte or string sent as is) and response is decoded to result
+func (e *Elasticsearch) Do(ctx context.Context, method, path string, body, result any) (err error) {
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	case string:
+		payload = []byte(b)
+	default:
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	trace, ctx := tracer.StartTraceWithContext(ctx, "Elasticsearch")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+	trace.SetTag("db.system", "elasticsearch")
+	trace.SetTag("http.method", method)
+	trace.SetTag("http.url_path", path)
+	if len(payload) > 0 {
+		trace.Log("request.body", payload)
+	}
+
+	status, respBody, err := e.roundTrip(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+	trace.SetTag("response.code", status)
+	trace.Log("response.body", respBody)
+
+	if status >= http.StatusMultipleChoices {
+		return parseSearchError(status, respBody)
+	}
+	if result != nil && len(respBody) > 0 {
+		return json.Unmarshal(respBody, result)
+	}
+	return nil
+}
+
+// Index create or replace document, id is generated by search engine when empty
+func (e *Elasticsearch) Index(ctx context.Context, index, id string, document any) error {
+	if id == "" {
+		return e.Do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_doc", document, nil)
+	}
+	return e.Do(ctx, http.MethodPut, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), document, nil)
+}
+
+// Get decode source of document to target, return ErrSearchNotFound if document not exist
+func (e *Elasticsearch) Get(ctx context.Context, index, id string, target any) error {
+	var resp struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := e.Do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), nil, &resp); err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Source, target)
+}
+
+// Delete remove document, not error if document not exist
+func (e *Elasticsearch) Delete(ctx context.Context, index, id string) error {
+	err := e.Do(ctx, http.MethodDelete, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), nil, nil)
+	if errors.Is(err, ErrSearchNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Search execute search query (request body of _search API) and decode response to result
+func (e *Elasticsearch) Search(ctx context.Context, index string, query, result any) error {
+	return e.Do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", query, result)
+}
+
+// Bulk execute multiple operation in one request, error of each item is returned in result
+func (e *Elasticsearch) Bulk(ctx context.Context, items []interfaces.SearchBulkItem) ([]interfaces.SearchBulkResult, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var buff bytes.Buffer
+	encoder := json.NewEncoder(&buff)
+	for _, item := range items {
+		meta := map[string]string{"_index": item.Index}
+		if item.ID != "" {
+			meta["_id"] = item.ID
+		}
+		if err := encoder.Encode(map[string]any{item.Action: meta}); err != nil {
+			return nil, err
+		}
+
+		source := item.Document
+		if raw, ok := source.([]byte); ok {
+			source = json.RawMessage(raw)
+		}
+		switch item.Action {
+		case SearchActionIndex, SearchActionCreate:
+		case SearchActionUpdate:
+			source = map[string]any{"doc": source}
+		case SearchActionDelete:
+			continue
+		default:
+			return nil, fmt.Errorf("search engine: invalid bulk action '%s'", item.Action)
+		}
+		if err := encoder.Encode(source); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp struct {
+		Items []map[string]struct {
+			Index  string `json:"_index"`
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := e.Do(ctx, http.MethodPost, "/_bulk", buff.Bytes(), &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Items) != len(items) {
+		return nil, fmt.Errorf("search engine: bulk response contains %d items, expected %d", len(resp.Items), len(items))
+	}
+
+	results := make([]interfaces.SearchBulkResult, len(items))
+	for i, item := range resp.Items {
+		for _, res := range item {
+			results[i] = interfaces.SearchBulkResult{Index: res.Index, ID: res.ID, Status: res.Status}
+			if res.Error != nil {
+				results[i].Error = res.Error.Type + ": " + res.Error.Reason
+			}
+		}
+	}
+	return results, nil
+}
+
+// Health check cluster health, red status is unhealthy
+func (e *Elasticsearch) Health() map[string]error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var resp struct {
+		Status string `json:"status"`
+	}
+	err := e.Do(tracer.SkipTraceContext(ctx), http.MethodGet, "/_cluster/health", nil, &resp)
+	if err == nil && resp.Status == "red" {
+		err = errors.New("cluster health status is red")
+	}
+	return map[string]error{"elasticsearch": err}
+}
+
+// Disconnect close idle connection
+func (e *Elasticsearch) Disconnect(ctx context.Context) error {
+	defer logger.LogWithDefer("\x1b[33;5melasticsearch\x1b[0m: disconnect...")()
+	e.cfg.HTTPClient.CloseIdleConnections()
+	return nil
+}
+
+func (e *Elasticsearch) roundTrip(ctx context.Context, method, path string, payload []byte) (status int, respBody []byte, err error) {
+	contentType := candihelper.HeaderMIMEApplicationJSON
+	if strings.HasSuffix(strings.SplitN(path, "?", 2)[0], "/_bulk") {
+		contentType = "application/x-ndjson"
+	}
+
+	backoff := e.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		address := e.addresses[(e.next.Add(1)-1)%uint64(len(e.addresses))]
+		status, respBody, err = e.send(ctx, method, address+path, contentType, payload)
+		if err == nil && !isRetriableSearchStatus(status) {
+			return status, respBody, nil
+		}
+		if attempt >= e.cfg.MaxRetries || ctx.Err() != nil {
+			return status, respBody, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, respBody, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (e *Elasticsearch) send(ctx context.Context, method, requestURL, contentType string, payload []byte) (int, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if payload != nil {
+		req.Header.Set(candihelper.HeaderContentType, contentType)
+	}
+	if e.cfg.APIKey != "" {
+		req.Header.Set(candihelper.HeaderAuthorization, "ApiKey "+e.cfg.APIKey)
+	} else if e.cfg.Username != "" {
+		req.SetBasicAuth(e.cfg.Username, e.cfg.Password)
+	}
+
+	resp, err := e.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, respBody, err
+}
+
+func parseSearchError(status int, body []byte) error {
+	searchErr := &SearchError{Status: status, Type: http.StatusText(status)}
+	var resp struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) != nil || len(resp.Error) == 0 {
+		searchErr.Reason = string(body)
+		return searchErr
+	}
+
+	var detail struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(resp.Error, &detail) == nil && detail.Type != "" {
+		searchErr.Type, searchErr.Reason = detail.Type, detail.Reason
+	} else {
+		searchErr.Reason = strings.Trim(string(resp.Error), `"`)
+	}
+	return searchErr
+}
+
+func isRetriableSearchStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package candiutils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+/*
+EnsureSearchIndex create index of alias with definition (body of create index API contains settings and mappings).
+Physical index name is alias with hash of definition suffix ("{alias}_{hash}") so new index is created when definition
+is changed, documents of index pointed by alias is reindexed to new index then alias is moved to new index atomically.
+Previous index is kept (for rollback) and can be deleted manually. Return name of physical index pointed by alias
+*/
+func EnsureSearchIndex(ctx context.Context, engine interfaces.SearchEngine, alias string, definition any) (index string, err error) {
+	body, err := json.Marshal(definition)
+	if err != nil {
+		return "", err
+	}
+	index = SearchIndexName(alias, body)
+
+	current, err := searchAliasIndices(ctx, engine, alias)
+	if err != nil {
+		return "", err
+	}
+	if len(current) == 1 && current[0] == index {
+		return index, nil
+	}
+
+	err = engine.Do(ctx, http.MethodHead, "/"+url.PathEscape(index), nil, nil)
+	if errors.Is(err, ErrSearchNotFound) {
+		err = engine.Do(ctx, http.MethodPut, "/"+url.PathEscape(index), body, nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("create index %s: %w", index, err)
+	}
+
+	for _, source := range current {
+		if source == index {
+			continue
+		}
+		if err := ReindexSearchIndex(ctx, engine, source, index); err != nil {
+			return "", err
+		}
+	}
+
+	actions := []map[string]any{}
+	for _, source := range current {
+		if source != index {
+			actions = append(actions, map[string]any{"remove": map[string]string{"index": source, "alias": alias}})
+		}
+	}
+	actions = append(actions, map[string]any{"add": map[string]string{"index": index, "alias": alias}})
+	if err := engine.Do(ctx, http.MethodPost, "/_aliases", map[string]any{"actions": actions}, nil); err != nil {
+		return "", fmt.Errorf("move alias %s to %s: %w", alias, index, err)
+	}
+	return index, nil
+}
+
+// ReindexSearchIndex copy all documents from source to dest index (dest index must be created with mapping first),
+// wait until reindex completed
+func ReindexSearchIndex(ctx context.Context, engine interfaces.SearchEngine, source, dest string) error {
+	var resp struct {
+		Failures []json.RawMessage `json:"failures"`
+	}
+	err := engine.Do(ctx, http.MethodPost, "/_reindex?wait_for_completion=true&refresh=true", map[string]any{
+		"source": map[string]string{"index": source}, "dest": map[string]string{"index": dest},
+	}, &resp)
+	if err != nil {
+		return fmt.Errorf("reindex %s to %s: %w", source, dest, err)
+	}
+	if len(resp.Failures) > 0 {
+		return fmt.Errorf("reindex %s to %s: %d failures, first failure: %s", source, dest, len(resp.Failures), resp.Failures[0])
+	}
+	return nil
+}
+
+// SearchIndexName physical index name of alias with definition
+func SearchIndexName(alias string, definition []byte) string {
+	hash := sha256.Sum256(definition)
+	return alias + "_" + hex.EncodeToString(hash[:])[:8]
+}
+
+// searchAliasIndices physical index pointed by alias
+func searchAliasIndices(ctx context.Context, engine interfaces.SearchEngine, alias string) ([]string, error) {
+	var resp map[string]json.RawMessage
+	err := engine.Do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, &resp)
+	if errors.Is(err, ErrSearchNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(resp))
+	for index := range resp {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
@@ -0,0 +1,272 @@
+package candiutils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ interfaces.SearchEngine = (*Elasticsearch)(nil)
+
+// fakeSearchServer minimal in memory search engine API for alias, index, document and bulk
+type fakeSearchServer struct {
+	mu        sync.Mutex
+	indices   map[string]map[string]json.RawMessage
+	aliases   map[string]string
+	reindexed []string
+	bulkFunc  func(lines []string) (status int, body string)
+}
+
+func (f *fakeSearchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	for i := range parts {
+		parts[i], _ = url.PathUnescape(parts[i])
+	}
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"type":"index_not_found_exception","reason":"no such index"},"status":404}`))
+	}
+	switch {
+	case r.URL.Path == "/_bulk":
+		var lines []string
+		scanner := bufio.NewScanner(strings.NewReader(string(body)))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		status, resp := f.bulkFunc(lines)
+		w.WriteHeader(status)
+		w.Write([]byte(resp))
+	case parts[0] == "_alias":
+		var indices []string
+		for index, alias := range f.aliases {
+			if alias == parts[1] {
+				indices = append(indices, `"`+index+`":{"aliases":{}}`)
+			}
+		}
+		if len(indices) == 0 {
+			notFound()
+			return
+		}
+		w.Write([]byte("{" + strings.Join(indices, ",") + "}"))
+	case r.URL.Path == "/_aliases":
+		var req struct {
+			Actions []map[string]map[string]string `json:"actions"`
+		}
+		json.Unmarshal(body, &req)
+		for _, action := range req.Actions {
+			if remove, ok := action["remove"]; ok {
+				delete(f.aliases, remove["index"])
+			}
+			if add, ok := action["add"]; ok {
+				f.aliases[add["index"]] = add["alias"]
+			}
+		}
+		w.Write([]byte(`{"acknowledged":true}`))
+	case r.URL.Path == "/_reindex":
+		var req struct {
+			Source struct{ Index string } `json:"source"`
+			Dest   struct{ Index string } `json:"dest"`
+		}
+		json.Unmarshal(body, &req)
+		for id, doc := range f.indices[req.Source.Index] {
+			f.indices[req.Dest.Index][id] = doc
+		}
+		f.reindexed = append(f.reindexed, req.Source.Index+">"+req.Dest.Index)
+		w.Write([]byte(`{"failures":[]}`))
+	case len(parts) == 1 && r.Method == http.MethodHead:
+		if _, ok := f.indices[parts[0]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		f.indices[parts[0]] = make(map[string]json.RawMessage)
+		w.Write([]byte(`{"acknowledged":true}`))
+	case len(parts) == 3 && parts[1] == "_doc":
+		index := parts[0]
+		for physical, alias := range f.aliases {
+			if alias == index {
+				index = physical
+			}
+		}
+		docs, ok := f.indices[index]
+		if !ok {
+			notFound()
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			docs[parts[2]] = body
+			w.Write([]byte(`{"result":"created"}`))
+		case http.MethodGet:
+			doc, ok := docs[parts[2]]
+			if !ok {
+				notFound()
+				return
+			}
+			w.Write([]byte(`{"_id":"` + parts[2] + `","_source":` + string(doc) + `}`))
+		case http.MethodDelete:
+			if _, ok := docs[parts[2]]; !ok {
+				notFound()
+				return
+			}
+			delete(docs, parts[2])
+			w.Write([]byte(`{"result":"deleted"}`))
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"unsupported request"}`))
+	}
+}
+
+func newFakeSearchEngine(t *testing.T) (*fakeSearchServer, *Elasticsearch) {
+	fake := &fakeSearchServer{indices: make(map[string]map[string]json.RawMessage), aliases: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	engine, err := NewElasticsearch(ElasticsearchConfig{Addresses: []string{server.URL}, RetryBackoff: time.Millisecond})
+	require.NoError(t, err)
+	return fake, engine
+}
+
+func TestElasticsearch(t *testing.T) {
+	ctx := context.Background()
+	var attempt int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "elastic:secret", user+":"+pass)
+		switch {
+		case attempt == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/products/_search":
+			w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_id":"1","_source":{"name":"book"}}]}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"type":"parsing_exception","reason":"unknown query"},"status":400}`))
+		}
+	}))
+	defer server.Close()
+
+	engine, err := NewElasticsearch(ElasticsearchConfig{
+		Addresses: []string{server.URL + "/"}, Username: "elastic", Password: "secret", RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source struct{ Name string } `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	require.NoError(t, engine.Search(ctx, "products", map[string]any{"query": map[string]any{"match_all": struct{}{}}}, &result))
+	assert.Equal(t, 2, attempt, "service unavailable must be retried")
+	assert.Equal(t, "book", result.Hits.Hits[0].Source.Name)
+
+	err = engine.Search(ctx, "orders", `{"query":{"unknown":{}}}`, nil)
+	var searchErr *SearchError
+	require.ErrorAs(t, err, &searchErr)
+	assert.Equal(t, "parsing_exception", searchErr.Type)
+	assert.NotErrorIs(t, err, ErrSearchNotFound)
+}
+
+func TestElasticsearchDocument(t *testing.T) {
+	ctx := context.Background()
+	fake, engine := newFakeSearchEngine(t)
+	fake.indices["products"] = make(map[string]json.RawMessage)
+
+	require.NoError(t, engine.Index(ctx, "products", "p/1", map[string]string{"name": "book"}))
+	var doc struct{ Name string }
+	require.NoError(t, engine.Get(ctx, "products", "p/1", &doc))
+	assert.Equal(t, "book", doc.Name)
+
+	require.NoError(t, engine.Delete(ctx, "products", "p/1"))
+	require.NoError(t, engine.Delete(ctx, "products", "p/1"))
+	assert.ErrorIs(t, engine.Get(ctx, "products", "p/1", &doc), ErrSearchNotFound)
+}
+
+func TestSearchBulkIndexer(t *testing.T) {
+	fake, engine := newFakeSearchEngine(t)
+	var requests [][]string
+	fake.bulkFunc = func(lines []string) (int, string) {
+		requests = append(requests, lines)
+		if len(requests) == 1 {
+			return http.StatusOK, `{"errors":true,"items":[` +
+				`{"index":{"_index":"products","_id":"1","status":201}},` +
+				`{"update":{"_index":"products","_id":"2","status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}},` +
+				`{"delete":{"_index":"products","_id":"3","status":404}},` +
+				`{"index":{"_index":"products","_id":"4","status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`
+		}
+		return http.StatusOK, `{"errors":false,"items":[{"update":{"_index":"products","_id":"2","status":200}}]}`
+	}
+
+	var failed []string
+	indexer := NewSearchBulkIndexer(engine,
+		SearchBulkIndexerSetFlushItems(4), SearchBulkIndexerSetFlushInterval(0), SearchBulkIndexerSetRetry(2, time.Millisecond),
+		SearchBulkIndexerSetOnError(func(item interfaces.SearchBulkItem, result interfaces.SearchBulkResult) {
+			failed = append(failed, item.ID+": "+result.Error)
+		}))
+
+	ctx := context.Background()
+	require.NoError(t, indexer.Add(ctx,
+		interfaces.SearchBulkItem{Action: SearchActionIndex, Index: "products", ID: "1", Document: map[string]string{"name": "book"}},
+		interfaces.SearchBulkItem{Action: SearchActionUpdate, Index: "products", ID: "2", Document: []byte(`{"stock":1}`)},
+		interfaces.SearchBulkItem{Action: SearchActionDelete, Index: "products", ID: "3"},
+	))
+	assert.Empty(t, requests, "must not flush before buffer is full")
+	require.NoError(t, indexer.Add(ctx, interfaces.SearchBulkItem{Action: SearchActionIndex, Index: "products", ID: "4", Document: "{}"}))
+	require.NoError(t, indexer.Close(ctx))
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []string{
+		`{"index":{"_id":"1","_index":"products"}}`, `{"name":"book"}`,
+		`{"update":{"_id":"2","_index":"products"}}`, `{"doc":{"stock":1}}`,
+		`{"delete":{"_id":"3","_index":"products"}}`,
+		`{"index":{"_id":"4","_index":"products"}}`, `"{}"`,
+	}, requests[0])
+	assert.Equal(t, []string{`{"update":{"_id":"2","_index":"products"}}`, `{"doc":{"stock":1}}`}, requests[1])
+	assert.Equal(t, []string{"4: mapper_parsing_exception: failed to parse"}, failed)
+	assert.Zero(t, indexer.Len())
+}
+
+func TestEnsureSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	fake, engine := newFakeSearchEngine(t)
+
+	v1 := map[string]any{"mappings": map[string]any{"properties": map[string]any{"name": map[string]string{"type": "text"}}}}
+	index1, err := EnsureSearchIndex(ctx, engine, "products", v1)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(index1, "products_"))
+	assert.Equal(t, "products", fake.aliases[index1])
+	require.NoError(t, engine.Index(ctx, "products", "1", map[string]string{"name": "book"}))
+
+	again, err := EnsureSearchIndex(ctx, engine, "products", v1)
+	require.NoError(t, err)
+	assert.Equal(t, index1, again)
+	assert.Empty(t, fake.reindexed)
+
+	v2 := map[string]any{"mappings": map[string]any{"properties": map[string]any{"name": map[string]string{"type": "keyword"}}}}
+	index2, err := EnsureSearchIndex(ctx, engine, "products", v2)
+	require.NoError(t, err)
+	assert.NotEqual(t, index1, index2)
+	assert.Equal(t, []string{index1 + ">" + index2}, fake.reindexed)
+	assert.Equal(t, map[string]string{index2: "products"}, fake.aliases)
+
+	var doc struct{ Name string }
+	require.NoError(t, engine.Get(ctx, "products", "1", &doc))
+	assert.Equal(t, "book", doc.Name)
+}
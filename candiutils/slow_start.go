@@ -0,0 +1,108 @@
+package candiutils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SlowStart concurrency limiter with limit ramp linearly from initial to max over warm-up window
+// since started (or reset when rebalance), for avoid cold-cache stampede to database right after deploy.
+// Nil SlowStart has no limit
+type SlowStart struct {
+	initial, maxLimit int
+	window            time.Duration
+
+	mu      sync.Mutex
+	startAt time.Time
+	running int
+	release chan struct{}
+}
+
+// NewSlowStart create slow start limiter, if window is zero or initial >= max, limit is always max
+func NewSlowStart(initial, maxLimit int, window time.Duration) *SlowStart {
+	initial = min(max(initial, 1), maxLimit)
+	return &SlowStart{
+		initial: initial, maxLimit: maxLimit, window: window,
+		startAt: time.Now(), release: make(chan struct{}),
+	}
+}
+
+// Reset restart warm-up window, call when startup or consumer rebalance
+func (s *SlowStart) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.startAt = time.Now()
+	s.mu.Unlock()
+}
+
+// Limit current concurrency limit
+func (s *SlowStart) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	limit, _ := s.limit(time.Now())
+	return limit
+}
+
+// Running current running count
+func (s *SlowStart) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// limit return current limit and duration until limit increased (zero if limit reach max)
+func (s *SlowStart) limit(now time.Time) (int, time.Duration) {
+	elapsed := now.Sub(s.startAt)
+	if s.window <= 0 || s.initial >= s.maxLimit || elapsed >= s.window {
+		return s.maxLimit, 0
+	}
+	step := s.window / time.Duration(s.maxLimit-s.initial)
+	increased := int(elapsed / step)
+	return s.initial + increased, time.Duration(increased+1)*step - elapsed
+}
+
+// Acquire wait until running count below current limit
+func (s *SlowStart) Acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	for {
+		s.mu.Lock()
+		limit, nextStep := s.limit(time.Now())
+		if s.running < limit {
+			s.running++
+			s.mu.Unlock()
+			return nil
+		}
+		release := s.release
+		s.mu.Unlock()
+
+		var timer <-chan time.Time
+		if nextStep > 0 {
+			t := time.NewTimer(nextStep)
+			timer = t.C
+			defer t.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-release:
+		case <-timer:
+		}
+	}
+}
+
+// Release running slot
+func (s *SlowStart) Release() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.running = max(s.running-1, 0)
+	close(s.release)
+	s.release = make(chan struct{})
+	s.mu.Unlock()
+}
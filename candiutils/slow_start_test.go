@@ -0,0 +1,38 @@
+package candiutils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowStart(t *testing.T) {
+	s := NewSlowStart(1, 5, 100*time.Millisecond)
+	assert.Equal(t, 1, s.Limit())
+
+	ctx := context.Background()
+	assert.NoError(t, s.Acquire(ctx))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Acquire(timeoutCtx), context.DeadlineExceeded)
+
+	// released slot can be acquired
+	go func() { time.Sleep(5 * time.Millisecond); s.Release() }()
+	assert.NoError(t, s.Acquire(ctx))
+
+	// wait until limit increased over warm-up window
+	assert.NoError(t, s.Acquire(ctx))
+	assert.GreaterOrEqual(t, s.Limit(), 2)
+	assert.Equal(t, 2, s.Running())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 5, s.Limit())
+
+	s.Reset()
+	assert.Equal(t, 1, s.Limit())
+
+	assert.Equal(t, 10, NewSlowStart(0, 10, 0).Limit())
+}
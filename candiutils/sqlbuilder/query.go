@@ -0,0 +1,94 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/tracer"
+)
+
+// Queryer query executor, implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Query execute traced select query of builder and scan each row
+func Query[T any](ctx context.Context, db Queryer, b *SelectBuilder, scan func(rows *sql.Rows) (T, error)) (results []T, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "sql:query")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	query, args, err := b.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+	trace.SetTag("db.statement", query)
+	trace.Log("args", args)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		result, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// Count execute traced count query of builder
+func Count(ctx context.Context, db Queryer, b *SelectBuilder) (total int, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "sql:count")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	query, args, err := b.CountSQL()
+	if err != nil {
+		return 0, err
+	}
+	trace.SetTag("db.statement", query)
+	trace.Log("args", args)
+
+	err = db.QueryRowContext(ctx, query, args...).Scan(&total)
+	return total, err
+}
+
+/*
+Paginate execute select query with applied filter and count total rows, return results with pagination meta
+(count query is skipped when ShowAll or first page is not full), example:
+
+	builder := sqlbuilder.Select("id", "name", "created_at").From("orders").
+		Where("tenant_id = ?", tenantID).
+		SearchColumns("name", "code").
+		AllowedSort(map[string]string{"createdAt": "created_at", "name": ""}).
+		AllowedFilter(map[string]string{"status": "", "total": "total_amount"}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(candishared.SQLPlaceholderDollar(0)).
+		ApplyFilter(&filter)
+	orders, meta, err := sqlbuilder.Paginate(ctx, db, builder, func(rows *sql.Rows) (order Order, err error) {
+		err = rows.Scan(&order.ID, &order.Name, &order.CreatedAt)
+		return order, err
+	})
+*/
+func Paginate[T any](ctx context.Context, db Queryer, b *SelectBuilder, scan func(rows *sql.Rows) (T, error)) (results []T, meta candishared.Meta, err error) {
+	results, err = Query(ctx, db, b, scan)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	filter := b.Filter()
+	if filter == nil || filter.ShowAll || filter.Limit <= 0 {
+		return results, candishared.NewMeta(1, len(results), len(results)), nil
+	}
+	total := filter.Offset + len(results)
+	if (len(results) == 0 && filter.Offset > 0) || len(results) >= filter.Limit {
+		if total, err = Count(ctx, db, b); err != nil {
+			return nil, meta, err
+		}
+	}
+	return results, candishared.NewMeta(filter.Page, filter.Limit, total), nil
+}
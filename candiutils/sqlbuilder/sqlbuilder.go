@@ -0,0 +1,266 @@
+// Package sqlbuilder thin select query builder consuming candishared.Filter (search, filter expression, sort,
+// pagination and show all), query is built with "?" placeholder and rebound to placeholder format of driver
+package sqlbuilder
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/golangid/candi/candishared"
+)
+
+type (
+	// SelectBuilder select query builder
+	SelectBuilder struct {
+		columns     []string
+		from        string
+		joins       []clause
+		where       []clause
+		groupBy     []string
+		having      []clause
+		orderBy     []string
+		limit       int
+		offset      int
+		placeholder func(argIndex int) string
+
+		filter        *candishared.Filter
+		searchColumns []string
+		sortAllowed   map[string]string
+		exprAllowed   map[string]string
+	}
+
+	clause struct {
+		sql  string
+		args []any
+	}
+)
+
+// Select create select query builder with columns (default "*")
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns, placeholder: candishared.SQLPlaceholderQuestion}
+}
+
+// From set table of query
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.from = table
+	return b
+}
+
+// Join add join clause with args, example: Join("LEFT JOIN users u ON u.id = o.user_id AND u.status = ?", "active")
+func (b *SelectBuilder) Join(join string, args ...any) *SelectBuilder {
+	b.joins = append(b.joins, clause{sql: join, args: args})
+	return b
+}
+
+// Where add condition joined with AND, use "?" as placeholder (escape literal "?" with "??"), empty condition is ignored
+func (b *SelectBuilder) Where(condition string, args ...any) *SelectBuilder {
+	if strings.TrimSpace(condition) != "" {
+		b.where = append(b.where, clause{sql: condition, args: args})
+	}
+	return b
+}
+
+// WhereIn add "column IN (...)" condition, empty values match no row
+func (b *SelectBuilder) WhereIn(column string, values ...any) *SelectBuilder {
+	if len(values) == 0 {
+		return b.Where("1 = 0")
+	}
+	return b.Where(column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")+")", values...)
+}
+
+// GroupBy set group by columns
+func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Having add having condition joined with AND
+func (b *SelectBuilder) Having(condition string, args ...any) *SelectBuilder {
+	b.having = append(b.having, clause{sql: condition, args: args})
+	return b
+}
+
+// OrderBy set default order of query, used when filter has no OrderBy
+func (b *SelectBuilder) OrderBy(orders ...string) *SelectBuilder {
+	b.orderBy = orders
+	return b
+}
+
+// Limit set limit and offset of query when filter is not applied
+func (b *SelectBuilder) Limit(limit, offset int) *SelectBuilder {
+	b.limit, b.offset = limit, offset
+	return b
+}
+
+// PlaceholderFormat set placeholder format, example: candishared.SQLPlaceholderDollar(0) for postgres (default "?")
+func (b *SelectBuilder) PlaceholderFormat(placeholder func(argIndex int) string) *SelectBuilder {
+	b.placeholder = placeholder
+	return b
+}
+
+// SearchColumns set columns matched with filter Search (case insensitive contains), joined with OR
+func (b *SelectBuilder) SearchColumns(columns ...string) *SelectBuilder {
+	b.searchColumns = columns
+	return b
+}
+
+// AllowedSort set whitelist of filter OrderBy field mapped to column name (see candishared.Filter.ParseSort)
+func (b *SelectBuilder) AllowedSort(allowed map[string]string) *SelectBuilder {
+	b.sortAllowed = allowed
+	return b
+}
+
+// AllowedFilter set whitelist of filter expression field mapped to column name, filter expression is
+// rejected if not set (see candishared.ParseFilterExpression)
+func (b *SelectBuilder) AllowedFilter(allowed map[string]string) *SelectBuilder {
+	b.exprAllowed = allowed
+	return b
+}
+
+// ApplyFilter apply filter search, expression, sort and pagination (limit and offset unless ShowAll) when query is built
+func (b *SelectBuilder) ApplyFilter(filter *candishared.Filter) *SelectBuilder {
+	b.filter = filter
+	return b
+}
+
+// Filter applied filter, nil if ApplyFilter not called
+func (b *SelectBuilder) Filter() *candishared.Filter {
+	return b.filter
+}
+
+// ToSQL build select query and args
+func (b *SelectBuilder) ToSQL() (query string, args []any, err error) {
+	where, err := b.whereClauses()
+	if err != nil {
+		return "", nil, err
+	}
+	orderBy, err := b.orderClause()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	columns := "*"
+	if len(b.columns) > 0 {
+		columns = strings.Join(b.columns, ", ")
+	}
+	sb.WriteString("SELECT " + columns)
+	args = b.writeBody(&sb, where)
+	if orderBy != "" {
+		sb.WriteString(" ORDER BY " + orderBy)
+	}
+
+	limit, offset := b.limit, b.offset
+	if b.filter != nil {
+		limit, offset = 0, 0
+		if !b.filter.ShowAll && b.filter.Limit > 0 {
+			limit, offset = b.filter.Limit, b.filter.CalculateOffset()
+		}
+	}
+	if limit > 0 {
+		sb.WriteString(" LIMIT " + strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		sb.WriteString(" OFFSET " + strconv.Itoa(offset))
+	}
+	return candishared.SQLRebind(sb.String(), b.placeholder), args, nil
+}
+
+// CountSQL build count query of total rows matched with condition and filter (without sort and pagination)
+func (b *SelectBuilder) CountSQL() (query string, args []any, err error) {
+	where, err := b.whereClauses()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	if len(b.groupBy) > 0 {
+		sb.WriteString("SELECT COUNT(*) FROM (SELECT 1 AS one")
+		args = b.writeBody(&sb, where)
+		sb.WriteString(") AS grouped")
+	} else {
+		sb.WriteString("SELECT COUNT(*)")
+		args = b.writeBody(&sb, where)
+	}
+	return candishared.SQLRebind(sb.String(), b.placeholder), args, nil
+}
+
+func (b *SelectBuilder) writeBody(sb *strings.Builder, where []clause) (args []any) {
+	sb.WriteString(" FROM " + b.from)
+	for _, join := range b.joins {
+		sb.WriteString(" " + join.sql)
+		args = append(args, join.args...)
+	}
+	if len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		args = append(args, writeClauses(sb, where)...)
+	}
+	if len(b.groupBy) > 0 {
+		sb.WriteString(" GROUP BY " + strings.Join(b.groupBy, ", "))
+	}
+	if len(b.having) > 0 {
+		sb.WriteString(" HAVING ")
+		args = append(args, writeClauses(sb, b.having)...)
+	}
+	return args
+}
+
+func (b *SelectBuilder) whereClauses() ([]clause, error) {
+	where := b.where
+	if b.filter == nil {
+		return where, nil
+	}
+
+	if search := strings.TrimSpace(b.filter.Search); search != "" && len(b.searchColumns) > 0 {
+		search = "%" + strings.ToLower(search) + "%"
+		conditions := make([]string, len(b.searchColumns))
+		args := make([]any, len(b.searchColumns))
+		for i, column := range b.searchColumns {
+			conditions[i], args[i] = "LOWER("+column+") LIKE ?", search
+		}
+		where = append(where[:len(where):len(where)], clause{sql: strings.Join(conditions, " OR "), args: args})
+	}
+
+	expr, err := b.filter.ParseExpression(b.exprAllowed)
+	if err != nil {
+		return nil, err
+	}
+	if expr != nil {
+		exprSQL, args := expr.ToSQL(candishared.SQLPlaceholderQuestion)
+		where = append(where[:len(where):len(where)], clause{sql: exprSQL, args: args})
+	}
+	return where, nil
+}
+
+func (b *SelectBuilder) orderClause() (string, error) {
+	if b.filter == nil || b.filter.OrderBy == "" {
+		return strings.Join(b.orderBy, ", "), nil
+	}
+	sorts, err := b.filter.ParseSort(b.sortAllowed)
+	if err != nil {
+		return "", err
+	}
+	orders := make([]string, len(sorts))
+	for i, sort := range sorts {
+		orders[i] = sort.Field + " ASC"
+		if sort.Desc {
+			orders[i] = sort.Field + " DESC"
+		}
+	}
+	return strings.Join(orders, ", "), nil
+}
+
+func writeClauses(sb *strings.Builder, clauses []clause) (args []any) {
+	for i, c := range clauses {
+		if i > 0 {
+			sb.WriteString(" AND ")
+		}
+		if len(clauses) > 1 {
+			sb.WriteString("(" + c.sql + ")")
+		} else {
+			sb.WriteString(c.sql)
+		}
+		args = append(args, c.args...)
+	}
+	return args
+}
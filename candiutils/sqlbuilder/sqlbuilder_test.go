@@ -0,0 +1,151 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	filter := candishared.Filter{
+		Page: 2, Limit: 10, Search: "Book", OrderBy: "-createdAt,name", Expression: "status IN (paid, shipped) AND total >= 100",
+	}
+	builder := Select("o.id", "o.name").From("orders o").
+		Join("LEFT JOIN users u ON u.id = o.user_id AND u.status = ?", "active").
+		Where("o.tenant_id = ?", "t1").
+		Where("o.note <> '?' AND o.data ?? 'key'").
+		SearchColumns("o.name", "u.name").
+		AllowedSort(map[string]string{"createdAt": "o.created_at", "name": "o.name"}).
+		AllowedFilter(map[string]string{"status": "o.status", "total": "o.total_amount"}).
+		OrderBy("o.id DESC").
+		PlaceholderFormat(candishared.SQLPlaceholderDollar(0)).
+		ApplyFilter(&filter)
+
+	query, args, err := builder.ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT o.id, o.name FROM orders o LEFT JOIN users u ON u.id = o.user_id AND u.status = $1"+
+		" WHERE (o.tenant_id = $2) AND (o.note <> '?' AND o.data ? 'key') AND (LOWER(o.name) LIKE $3 OR LOWER(u.name) LIKE $4)"+
+		" AND ((o.status IN ($5, $6) AND o.total_amount >= $7))"+
+		" ORDER BY o.created_at DESC, o.name ASC LIMIT 10 OFFSET 10", query)
+	assert.Equal(t, []any{"active", "t1", "%book%", "%book%", "paid", "shipped", int64(100)}, args)
+
+	query, args, err = builder.CountSQL()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(query, "SELECT COUNT(*) FROM orders o LEFT JOIN"))
+	assert.NotContains(t, query, "ORDER BY")
+	assert.Len(t, args, 7)
+
+	filter = candishared.Filter{ShowAll: true, Limit: 10}
+	query, args, err = Select().From("orders").GroupBy("status").OrderBy("status").ApplyFilter(&filter).ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM orders GROUP BY status ORDER BY status", query)
+	assert.Empty(t, args)
+	query, _, err = Select("status", "COUNT(*)").From("orders").GroupBy("status").CountSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT 1 AS one FROM orders GROUP BY status) AS grouped", query)
+
+	query, args, _ = Select("id").From("orders").WhereIn("id", 1, 2).Limit(5, 0).ToSQL()
+	assert.Equal(t, "SELECT id FROM orders WHERE id IN (?, ?) LIMIT 5", query)
+	assert.Equal(t, []any{1, 2}, args)
+
+	_, _, err = Select().From("orders").ApplyFilter(&candishared.Filter{OrderBy: "password"}).
+		AllowedSort(map[string]string{"name": ""}).ToSQL()
+	assert.Error(t, err)
+	_, _, err = Select().From("orders").ApplyFilter(&candishared.Filter{Expression: "password = 1"}).ToSQL()
+	assert.Error(t, err)
+}
+
+func TestPaginate(t *testing.T) {
+	sql.Register("sqlbuilder-fake", fakeDriver{total: 25})
+	db, err := sql.Open("sqlbuilder-fake", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	scan := func(rows *sql.Rows) (id int64, err error) {
+		err = rows.Scan(&id)
+		return id, err
+	}
+
+	filter := candishared.Filter{Page: 1, Limit: 10}
+	ids, meta, err := Paginate(context.Background(), db, Select("id").From("orders").ApplyFilter(&filter), scan)
+	require.NoError(t, err)
+	assert.Len(t, ids, 10)
+	assert.Equal(t, candishared.Meta{Page: 1, Limit: 10, TotalRecords: 25, TotalPages: 3}, meta)
+
+	filter = candishared.Filter{Page: 3, Limit: 10}
+	ids, meta, err = Paginate(context.Background(), db, Select("id").From("orders").ApplyFilter(&filter), scan)
+	require.NoError(t, err)
+	assert.Len(t, ids, 5)
+	assert.Equal(t, 25, meta.TotalRecords)
+
+	filter = candishared.Filter{ShowAll: true}
+	ids, meta, err = Paginate(context.Background(), db, Select("id").From("orders").ApplyFilter(&filter), scan)
+	require.NoError(t, err)
+	assert.Len(t, ids, 25)
+	assert.Equal(t, 25, meta.TotalRecords)
+}
+
+// fakeDriver sql driver return total for count query, and rows with LIMIT/OFFSET applied for select query
+type (
+	fakeDriver struct{ total int }
+	fakeConn   struct{ total int }
+	fakeStmt   struct {
+		total int
+		query string
+	}
+	fakeRows struct {
+		columns []string
+		values  []int64
+	}
+)
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{total: c.total, query: query}, nil
+}
+func (fakeConn) Close() error                               { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (r *fakeRows) Columns() []string                       { return r.columns }
+func (r *fakeRows) Close() error                            { return nil }
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.HasPrefix(s.query, "SELECT COUNT(*)") {
+		return &fakeRows{columns: []string{"count"}, values: []int64{int64(s.total)}}, nil
+	}
+	limit, offset := s.total, 0
+	if _, after, ok := strings.Cut(s.query, " LIMIT "); ok {
+		limitStr, offsetStr, _ := strings.Cut(after, " OFFSET ")
+		limit = atoi(limitStr)
+		offset = atoi(offsetStr)
+	}
+	rows := &fakeRows{columns: []string{"id"}}
+	for i := offset; i < min(offset+limit, s.total); i++ {
+		rows.values = append(rows.values, int64(i+1))
+	}
+	return rows, nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func atoi(s string) (n int) {
+	for _, c := range s {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
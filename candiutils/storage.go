@@ -0,0 +1,247 @@
+package candiutils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+var (
+	// ErrStorageObjectNotFound error when object key not found in storage
+	ErrStorageObjectNotFound = errors.New("storage: object not found")
+	// ErrStorageInvalidKey error when object key is empty or escape storage root
+	ErrStorageInvalidKey = errors.New("storage: invalid object key")
+)
+
+type (
+	// LocalStorage object storage in local filesystem, implement interfaces.Storage.
+	// Suitable for development and test, signed url served by LocalStorage http handler
+	LocalStorage struct {
+		dir     string
+		baseURL string
+		secret  []byte
+		now     func() time.Time
+	}
+
+	// LocalStorageOption option func type
+	LocalStorageOption func(*LocalStorage)
+)
+
+// LocalStorageSetBaseURL option func, set public base url where LocalStorage http handler mounted, used in signed url
+func LocalStorageSetBaseURL(baseURL string) LocalStorageOption {
+	return func(l *LocalStorage) {
+		l.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// LocalStorageSetSecret option func, set secret for sign url (default random secret per process)
+func LocalStorageSetSecret(secret string) LocalStorageOption {
+	return func(l *LocalStorage) {
+		l.secret = []byte(secret)
+	}
+}
+
+// NewLocalStorage constructor, all objects stored under dir
+func NewLocalStorage(dir string, opts ...LocalStorageOption) *LocalStorage {
+	l := &LocalStorage{dir: filepath.Clean(dir), now: time.Now}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if len(l.secret) == 0 {
+		l.secret = make([]byte, 32)
+		rand.Read(l.secret)
+	}
+	return l
+}
+
+// Put write object from body
+func (l *LocalStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	// write to temporary file first, so reader never see partial object
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, contextReader{ctx: ctx, r: body}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Get open object, caller must close returned reader
+func (l *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filename, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrStorageObjectNotFound, key)
+	}
+	return f, err
+}
+
+// Delete remove object, not error if object not exist
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL generate url with expiry and signature, verified by LocalStorage http handler
+func (l *LocalStorage) SignedURL(ctx context.Context, key, method string, expire time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(l.now().Add(expire).Unix(), 10)
+	query := url.Values{
+		"method":    {method},
+		"expires":   {expires},
+		"signature": {l.sign(key, method, expires)},
+	}
+	return l.baseURL + "/" + escapeObjectKey(key) + "?" + query.Encode(), nil
+}
+
+// List objects with key prefix, sorted by key
+func (l *LocalStorage) List(ctx context.Context, prefix string) (objects []interfaces.StorageObject, err error) {
+	root := l.dir
+	if dir := path.Dir(prefix); dir != "." && dir != "/" {
+		if root, err = l.path(dir); err != nil {
+			return nil, err
+		}
+	}
+	err = filepath.WalkDir(root, func(filename string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return err
+		}
+		rel, err := filepath.Rel(l.dir, filename)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, interfaces.StorageObject{
+			Key: key, Size: info.Size(), LastModified: info.ModTime(),
+		})
+		return ctx.Err()
+	})
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, err
+}
+
+// ServeHTTP serve signed url generated by SignedURL (GET for download and PUT for upload),
+// mount with http.StripPrefix if base url contains path
+func (l *LocalStorage) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	key := strings.TrimPrefix(req.URL.Path, "/")
+	query := req.URL.Query()
+	method, expires := query.Get("method"), query.Get("expires")
+	if method != req.Method && !(method == http.MethodGet && req.Method == http.MethodHead) {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(l.sign(key, method, expires))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	if unix, _ := strconv.ParseInt(expires, 10, 64); l.now().Unix() > unix {
+		http.Error(w, "signed url expired", http.StatusForbidden)
+		return
+	}
+
+	switch method {
+	case http.MethodGet:
+		filename, err := l.path(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.ServeFile(w, req, filename)
+	case http.MethodPut:
+		if err := l.Put(req.Context(), key, req.Body, req.Header.Get("Content-Type")); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (l *LocalStorage) path(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || !fs.ValidPath(key) {
+		return "", fmt.Errorf("%w: %q", ErrStorageInvalidKey, key)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+func (l *LocalStorage) sign(key, method, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(method + "\n" + strings.TrimPrefix(key, "/") + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// contextReader stop reading when context canceled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// escapeObjectKey escape each segment of object key for url path
+func escapeObjectKey(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
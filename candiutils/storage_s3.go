@@ -0,0 +1,424 @@
+package candiutils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3MinPartSize     = 5 << 20
+	s3MaxSignedExpire = 7 * 24 * time.Hour
+)
+
+type (
+	// S3StorageConfig config for S3 compatible object storage
+	S3StorageConfig struct {
+		// Endpoint storage endpoint (default https://s3.<region>.amazonaws.com)
+		Endpoint  string
+		Region    string
+		Bucket    string
+		AccessKey string
+		SecretKey string
+		// UsePathStyle use path style url (endpoint/bucket/key) instead of virtual hosted style (bucket.endpoint/key)
+		UsePathStyle bool
+		// PartSize size of each part in multipart upload for body with unknown length (minimum and default 5MB)
+		PartSize   int
+		HTTPClient *http.Client
+	}
+
+	// S3Storage object storage for AWS S3 and S3 compatible API (MinIO, GCS XML API with HMAC key),
+	// request signed with AWS signature version 4. Implement interfaces.Storage
+	S3Storage struct {
+		cfg      S3StorageConfig
+		endpoint *url.URL
+		now      func() time.Time
+	}
+
+	s3ErrorResponse struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+)
+
+// NewS3Storage constructor
+func NewS3Storage(cfg S3StorageConfig) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 storage: bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if !strings.Contains(cfg.Endpoint, "://") {
+		cfg.Endpoint = "https://" + cfg.Endpoint
+	}
+	if cfg.PartSize < s3MinPartSize {
+		cfg.PartSize = s3MinPartSize
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("s3 storage: invalid endpoint: %w", err)
+	}
+	return &S3Storage{cfg: cfg, endpoint: endpoint, now: time.Now}, nil
+}
+
+// NewMinIOStorage constructor for MinIO (using path style url), endpoint example: http://localhost:9000
+func NewMinIOStorage(endpoint, bucket, accessKey, secretKey string) (*S3Storage, error) {
+	return NewS3Storage(S3StorageConfig{
+		Endpoint: endpoint, Bucket: bucket, AccessKey: accessKey, SecretKey: secretKey, UsePathStyle: true,
+	})
+}
+
+// NewGCSStorage constructor for Google Cloud Storage using XML API interoperability with HMAC key
+// (https://cloud.google.com/storage/docs/authentication/hmackeys)
+func NewGCSStorage(bucket, hmacAccessID, hmacSecret string) (*S3Storage, error) {
+	return NewS3Storage(S3StorageConfig{
+		Endpoint: "https://storage.googleapis.com", Region: "auto", Bucket: bucket,
+		AccessKey: hmacAccessID, SecretKey: hmacSecret, UsePathStyle: true,
+	})
+}
+
+// Put upload object, body with unknown length uploaded with multipart upload so memory usage is bounded by part size
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if key == "" {
+		return ErrStorageInvalidKey
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	part := make([]byte, s.cfg.PartSize)
+	n, err := io.ReadFull(body, part)
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		return s.putObject(ctx, key, bytes.NewReader(part[:n]), contentType, nil)
+	case err != nil:
+		return err
+	}
+	return s.multipartUpload(ctx, key, body, part, contentType)
+}
+
+// Get download object, caller must close returned reader
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if key == "" {
+		return nil, ErrStorageInvalidKey
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete remove object
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrStorageInvalidKey
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		if errors.Is(err, ErrStorageObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// SignedURL generate presigned url (maximum expire is 7 days)
+func (s *S3Storage) SignedURL(ctx context.Context, key, method string, expire time.Duration) (string, error) {
+	if key == "" {
+		return "", ErrStorageInvalidKey
+	}
+	if expire <= 0 || expire > s3MaxSignedExpire {
+		return "", fmt.Errorf("s3 storage: signed url expire must be between 1s and %s", s3MaxSignedExpire)
+	}
+
+	t := s.now().UTC()
+	u := s.objectURL(key)
+	query := url.Values{
+		"X-Amz-Algorithm":     {s3Algorithm},
+		"X-Amz-Credential":    {s.cfg.AccessKey + "/" + s.scope(t)},
+		"X-Amz-Date":          {t.Format(s3TimeFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(expire.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	canonicalRequest := strings.Join([]string{
+		method, s3URIEncode(u.Path, false), s3CanonicalQuery(query),
+		"host:" + u.Host + "\n", "host", s3UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(t, canonicalRequest))
+	u.RawQuery = s3CanonicalQuery(query)
+	return u.String(), nil
+}
+
+// List objects with key prefix
+func (s *S3Storage) List(ctx context.Context, prefix string) (objects []interfaces.StorageObject, err error) {
+	var result struct {
+		Contents []struct {
+			Key          string    `xml:"Key"`
+			LastModified time.Time `xml:"LastModified"`
+			ETag         string    `xml:"ETag"`
+			Size         int64     `xml:"Size"`
+		} `xml:"Contents"`
+		IsTruncated           bool   `xml:"IsTruncated"`
+		NextContinuationToken string `xml:"NextContinuationToken"`
+	}
+
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		result.Contents, result.IsTruncated, result.NextContinuationToken = nil, false, ""
+		if err := s.doXML(ctx, http.MethodGet, "", query, nil, nil, &result); err != nil {
+			return objects, err
+		}
+		for _, content := range result.Contents {
+			objects = append(objects, interfaces.StorageObject{
+				Key: content.Key, Size: content.Size, ETag: strings.Trim(content.ETag, `"`), LastModified: content.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (s *S3Storage) putObject(ctx context.Context, key string, body *bytes.Reader, contentType string, query url.Values) error {
+	header := http.Header{"Content-Type": {contentType}}
+	resp, err := s.do(ctx, http.MethodPut, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *S3Storage) multipartUpload(ctx context.Context, key string, body io.Reader, firstPart []byte, contentType string) (err error) {
+	var initiate struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := s.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, http.Header{"Content-Type": {contentType}}, nil, &initiate); err != nil {
+		return err
+	}
+	uploadQuery := url.Values{"uploadId": {initiate.UploadID}}
+	defer func() {
+		if err != nil {
+			// abort upload for cleanup uploaded parts, use new context because ctx may be canceled
+			if resp, abortErr := s.do(context.Background(), http.MethodDelete, key, uploadQuery, nil, nil); abortErr == nil {
+				resp.Body.Close()
+			}
+		}
+	}()
+
+	type completedPart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	var complete struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}
+
+	part := firstPart
+	for partNumber := 1; len(part) > 0; partNumber++ {
+		query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {initiate.UploadID}}
+		resp, err := s.do(ctx, http.MethodPut, key, query, nil, bytes.NewReader(part))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		complete.Parts = append(complete.Parts, completedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+
+		n, err := io.ReadFull(body, firstPart)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		part = firstPart[:n]
+	}
+
+	payload, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	return s.doXML(ctx, http.MethodPost, key, uploadQuery, nil, bytes.NewReader(payload), nil)
+}
+
+func (s *S3Storage) doXML(ctx context.Context, method, key string, query url.Values, header http.Header, body *bytes.Reader, target any) error {
+	resp, err := s.do(ctx, method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// complete multipart upload may return error in body with 200 status code
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var errResp s3ErrorResponse
+	if xml.Unmarshal(respBody, &errResp) == nil && errResp.Code != "" {
+		return fmt.Errorf("s3 storage: %s: %s", errResp.Code, errResp.Message)
+	}
+	if target == nil {
+		return nil
+	}
+	return xml.Unmarshal(respBody, target)
+}
+
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, header http.Header, body *bytes.Reader) (*http.Response, error) {
+	u := s.objectURL(key)
+	u.RawQuery = s3CanonicalQuery(query)
+
+	var reqBody io.Reader
+	payloadHash := s3UnsignedPayload
+	if body != nil {
+		hash := sha256.New()
+		body.WriteTo(hash)
+		body.Seek(0, io.SeekStart)
+		payloadHash = hex.EncodeToString(hash.Sum(nil))
+		reqBody = body
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	s.signRequest(req, payloadHash)
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		var errResp s3ErrorResponse
+		xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&errResp)
+		if resp.StatusCode == http.StatusNotFound && (errResp.Code == "" || errResp.Code == "NoSuchKey") {
+			return nil, fmt.Errorf("%w: %s", ErrStorageObjectNotFound, key)
+		}
+		if errResp.Code == "" {
+			errResp.Code = resp.Status
+		}
+		return nil, fmt.Errorf("s3 storage: %s: %s", errResp.Code, errResp.Message)
+	}
+	return resp, nil
+}
+
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	key = strings.TrimPrefix(key, "/")
+	if s.cfg.UsePathStyle {
+		u.Path = u.Path + "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = u.Path + "/" + key
+	}
+	u.RawPath = s3URIEncode(u.Path, false)
+	return &u
+}
+
+// signRequest add authorization header with signature version 4, signed headers is host, range, content-md5, content-type and x-amz-*
+func (s *S3Storage) signRequest(req *http.Request, payloadHash string) {
+	t := s.now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-amz-") || k == "range" || k == "content-md5" || k == "content-type" {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	signedHeaders := make([]string, 0, len(headers))
+	for k := range headers {
+		signedHeaders = append(signedHeaders, k)
+	}
+	sort.Strings(signedHeaders)
+	var canonicalHeaders strings.Builder
+	for _, k := range signedHeaders {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, s3URIEncode(req.URL.Path, false), s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(), strings.Join(signedHeaders, ";"), payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, s.scope(t), strings.Join(signedHeaders, ";"), s.signature(t, canonicalRequest)))
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3Storage) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + t.Format(s3TimeFormat) + "\n" + s.scope(t) + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + s.cfg.SecretKey)
+	for _, data := range []string{t.Format("20060102"), s.cfg.Region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		key = mac.Sum(nil)
+	}
+	return hex.EncodeToString(key)
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3URIEncode(k, true)+"="+s3URIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3URIEncode encode every byte except unreserved characters (and slash if not encodeSlash)
+func s3URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,301 @@
+package candiutils
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+)
+
+// EvictReason reason of entry removed from TTL cache
+type EvictReason string
+
+const (
+	// EvictReasonExpired entry TTL is expired
+	EvictReasonExpired EvictReason = "expired"
+	// EvictReasonCapacity least recently used entry removed because cache is full
+	EvictReasonCapacity EvictReason = "capacity"
+	// EvictReasonDeleted entry deleted manually (Delete or Clear)
+	EvictReasonDeleted EvictReason = "deleted"
+)
+
+type (
+	// TTLCache goroutine safe in-memory cache with max size (LRU eviction), per entry TTL, eviction callback
+	// and GetOrSet with single flight loading
+	TTLCache[K comparable, V any] struct {
+		opt     ttlCacheOption
+		onEvict func(key K, value V, reason EvictReason)
+
+		mu    sync.Mutex
+		items map[K]*list.Element
+		lru   *list.List
+		calls map[K]*ttlCacheCall[V]
+
+		closeOnce sync.Once
+		done      chan struct{}
+	}
+
+	// TTLCacheOption option func
+	TTLCacheOption func(*ttlCacheOption)
+
+	ttlCacheOption struct {
+		maxSize         int
+		defaultTTL      time.Duration
+		cleanupInterval time.Duration
+		clock           candihelper.Clock
+	}
+
+	ttlCacheEntry[K comparable, V any] struct {
+		key       K
+		value     V
+		expiredAt time.Time
+	}
+
+	ttlCacheCall[V any] struct {
+		wg    sync.WaitGroup
+		value V
+		err   error
+	}
+)
+
+// TTLCacheMaxSize option func, max number of entry, least recently used entry is evicted when full (default 0, unlimited)
+func TTLCacheMaxSize(maxSize int) TTLCacheOption {
+	return func(o *ttlCacheOption) {
+		o.maxSize = maxSize
+	}
+}
+
+// TTLCacheDefaultTTL option func, TTL of entry set without TTL (default 0, never expired)
+func TTLCacheDefaultTTL(ttl time.Duration) TTLCacheOption {
+	return func(o *ttlCacheOption) {
+		o.defaultTTL = ttl
+	}
+}
+
+// TTLCacheCleanupInterval option func, enable removing expired entry in background with interval, call Close for stop
+// the cleanup goroutine (default 0, no background cleanup, expired entry is removed when accessed or by DeleteExpired)
+func TTLCacheCleanupInterval(interval time.Duration) TTLCacheOption {
+	return func(o *ttlCacheOption) {
+		o.cleanupInterval = interval
+	}
+}
+
+// TTLCacheClock option func, clock for entry TTL (default system clock)
+func TTLCacheClock(clock candihelper.Clock) TTLCacheOption {
+	return func(o *ttlCacheOption) {
+		o.clock = clock
+	}
+}
+
+// NewTTLCache create in-memory TTL cache, no goroutine is started unless TTLCacheCleanupInterval is set
+func NewTTLCache[K comparable, V any](opts ...TTLCacheOption) *TTLCache[K, V] {
+	c := &TTLCache[K, V]{
+		opt:   ttlCacheOption{clock: candihelper.SystemClock},
+		items: make(map[K]*list.Element),
+		lru:   list.New(),
+		calls: make(map[K]*ttlCacheCall[V]),
+		done:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&c.opt)
+	}
+	if c.opt.cleanupInterval > 0 {
+		go c.cleanupLoop()
+	}
+	return c
+}
+
+// OnEvict set callback called after entry is removed from cache (outside cache lock), entry replaced by Set is not evicted
+func (c *TTLCache[K, V]) OnEvict(onEvict func(key K, value V, reason EvictReason)) *TTLCache[K, V] {
+	c.mu.Lock()
+	c.onEvict = onEvict
+	c.mu.Unlock()
+	return c
+}
+
+// Set set value of key with default TTL
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.opt.defaultTTL)
+}
+
+// SetWithTTL set value of key with TTL, zero TTL for never expired
+func (c *TTLCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	evicted := c.set(key, value, ttl)
+	c.mu.Unlock()
+	c.notify(evicted, EvictReasonCapacity)
+}
+
+// Get get value of key, false if not found or expired
+func (c *TTLCache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	elem, found := c.items[key]
+	if !found {
+		c.mu.Unlock()
+		return value, false
+	}
+	entry := elem.Value.(*ttlCacheEntry[K, V])
+	if entry.isExpired(c.opt.clock.Now()) {
+		c.remove(elem)
+		c.mu.Unlock()
+		c.notify([]*ttlCacheEntry[K, V]{entry}, EvictReasonExpired)
+		return value, false
+	}
+	c.lru.MoveToFront(elem)
+	value = entry.value
+	c.mu.Unlock()
+	return value, true
+}
+
+// GetOrSet get value of key, or load with loadFunc and set with default TTL if not found. Concurrent call of same key
+// wait for single loadFunc call (single flight), error (or panic) of loadFunc is not cached
+func (c *TTLCache[K, V]) GetOrSet(key K, loadFunc func() (V, error)) (value V, err error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	c.mu.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+	call := new(ttlCacheCall[V])
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("ttl cache load panic: %v", r)
+			err = call.err
+		}
+		c.mu.Lock()
+		delete(c.calls, key)
+		var evicted []*ttlCacheEntry[K, V]
+		if call.err == nil {
+			evicted = c.set(key, call.value, c.opt.defaultTTL)
+		}
+		c.mu.Unlock()
+		call.wg.Done()
+		c.notify(evicted, EvictReasonCapacity)
+	}()
+	call.value, call.err = loadFunc()
+	return call.value, call.err
+}
+
+// Delete remove key from cache
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	c.remove(elem)
+	c.mu.Unlock()
+	c.notify([]*ttlCacheEntry[K, V]{elem.Value.(*ttlCacheEntry[K, V])}, EvictReasonDeleted)
+}
+
+// Clear remove all entry from cache
+func (c *TTLCache[K, V]) Clear() {
+	c.mu.Lock()
+	evicted := make([]*ttlCacheEntry[K, V], 0, c.lru.Len())
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		evicted = append(evicted, elem.Value.(*ttlCacheEntry[K, V]))
+	}
+	c.items = make(map[K]*list.Element)
+	c.lru.Init()
+	c.mu.Unlock()
+	c.notify(evicted, EvictReasonDeleted)
+}
+
+// Len number of entry in cache, include expired entry not yet cleaned up
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// DeleteExpired remove all expired entry
+func (c *TTLCache[K, V]) DeleteExpired() {
+	now := c.opt.clock.Now()
+	c.mu.Lock()
+	var evicted []*ttlCacheEntry[K, V]
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if entry := elem.Value.(*ttlCacheEntry[K, V]); entry.isExpired(now) {
+			c.remove(elem)
+			evicted = append(evicted, entry)
+		}
+		elem = prev
+	}
+	c.mu.Unlock()
+	c.notify(evicted, EvictReasonExpired)
+}
+
+// Close stop background cleanup, no-op if TTLCacheCleanupInterval is not set
+func (c *TTLCache[K, V]) Close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// set must be called with lock held, return entry evicted because of capacity
+func (c *TTLCache[K, V]) set(key K, value V, ttl time.Duration) (evicted []*ttlCacheEntry[K, V]) {
+	var expiredAt time.Time
+	if ttl > 0 {
+		expiredAt = c.opt.clock.Now().Add(ttl)
+	}
+	if elem, ok := c.items[key]; ok {
+		// replace entry instead of mutating, entry may still be read outside lock by notify
+		elem.Value = &ttlCacheEntry[K, V]{key: key, value: value, expiredAt: expiredAt}
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+	c.items[key] = c.lru.PushFront(&ttlCacheEntry[K, V]{key: key, value: value, expiredAt: expiredAt})
+	for c.opt.maxSize > 0 && c.lru.Len() > c.opt.maxSize {
+		oldest := c.lru.Back()
+		c.remove(oldest)
+		evicted = append(evicted, oldest.Value.(*ttlCacheEntry[K, V]))
+	}
+	return evicted
+}
+
+// remove must be called with lock held
+func (c *TTLCache[K, V]) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.items, elem.Value.(*ttlCacheEntry[K, V]).key)
+}
+
+func (c *TTLCache[K, V]) notify(evicted []*ttlCacheEntry[K, V], reason EvictReason) {
+	if len(evicted) == 0 {
+		return
+	}
+	c.mu.Lock()
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	if onEvict == nil {
+		return
+	}
+	for _, entry := range evicted {
+		onEvict(entry.key, entry.value, reason)
+	}
+}
+
+func (c *TTLCache[K, V]) cleanupLoop() {
+	ticker := time.NewTicker(c.opt.cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
+
+func (e *ttlCacheEntry[K, V]) isExpired(now time.Time) bool {
+	return !e.expiredAt.IsZero() && now.After(e.expiredAt)
+}
@@ -0,0 +1,141 @@
+package candiutils
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLCache(t *testing.T) {
+	type evicted struct {
+		key    string
+		reason EvictReason
+	}
+	var mu sync.Mutex
+	var evictions []evicted
+	cache := NewTTLCache[string, int](TTLCacheMaxSize(2)).
+		OnEvict(func(key string, value int, reason EvictReason) {
+			mu.Lock()
+			evictions = append(evictions, evicted{key, reason})
+			mu.Unlock()
+		})
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	_, ok := cache.Get("a") // "b" become least recently used
+	assert.True(t, ok)
+	cache.Set("c", 3)
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.SetWithTTL("a", 10, 10*time.Millisecond)
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, value)
+	time.Sleep(20 * time.Millisecond)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+
+	cache.Delete("c")
+	assert.Equal(t, 0, cache.Len())
+	assert.Equal(t, []evicted{{"b", EvictReasonCapacity}, {"a", EvictReasonExpired}, {"c", EvictReasonDeleted}}, evictions)
+}
+
+func TestTTLCacheGetOrSet(t *testing.T) {
+	cache := NewTTLCache[int, string](TTLCacheDefaultTTL(time.Minute))
+	defer cache.Close()
+
+	var loaded atomic.Int64
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.GetOrSet(1, func() (string, error) {
+				loaded.Add(1)
+				time.Sleep(20 * time.Millisecond)
+				return "one", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "one", value)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), loaded.Load())
+
+	_, err := cache.GetOrSet(2, func() (string, error) { return "", errors.New("not found") })
+	require.Error(t, err)
+	_, ok := cache.Get(2)
+	assert.False(t, ok, "error must not be cached")
+
+	_, err = cache.GetOrSet(3, func() (string, error) { panic("boom") })
+	assert.ErrorContains(t, err, "boom")
+
+	cache.SetWithTTL(4, "four", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	cache.DeleteExpired()
+	assert.Equal(t, 1, cache.Len())
+}
+
+type stubClock struct{ now time.Time }
+
+func (c *stubClock) Now() time.Time                  { return c.now }
+func (c *stubClock) Since(t time.Time) time.Duration { return c.now.Sub(t) }
+func (c *stubClock) Until(t time.Time) time.Duration { return t.Sub(c.now) }
+
+func TestTTLCacheClock(t *testing.T) {
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache := NewTTLCache[string, int](TTLCacheDefaultTTL(time.Minute), TTLCacheClock(clock))
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.SetWithTTL("b", 2, time.Hour)
+	clock.now = clock.now.Add(time.Minute)
+	_, ok := cache.Get("a")
+	assert.True(t, ok, "entry is not expired until TTL passed")
+
+	clock.now = clock.now.Add(time.Second)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	cache.DeleteExpired()
+	assert.Equal(t, 1, cache.Len())
+
+	clock.now = clock.now.Add(time.Hour)
+	cache.DeleteExpired()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestTTLCacheConcurrentSetGet(t *testing.T) {
+	cache := NewTTLCache[string, []int](TTLCacheCleanupInterval(time.Millisecond))
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				cache.SetWithTTL("key", []int{i, j}, time.Millisecond)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if value, ok := cache.Get("key"); ok {
+					assert.Len(t, value, 2)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Eventually(t, func() bool { return cache.Len() == 0 }, time.Second, time.Millisecond,
+		"expired entry is removed by background cleanup")
+}
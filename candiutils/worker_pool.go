@@ -2,52 +2,306 @@ package candiutils
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golangid/candi/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	// ErrWorkerPoolClosed job submitted after pool finish or shutdown
+	ErrWorkerPoolClosed = errors.New("worker pool is closed")
+	// ErrWorkerPoolQueueFull job queue is full (TrySubmit)
+	ErrWorkerPoolQueueFull = errors.New("worker pool queue is full")
 )
 
-// WorkerPool implementation
+// WorkerPool bounded pool of worker goroutines with bounded job queue, for fan out work without unbounded goroutines.
+// Job is isolated from panic (reported as crash report), optionally limited by timeout, and pool can be drained gracefully
 type WorkerPool[T any] interface {
+	// Dispatch start worker goroutines which execute queued job with jobFunc, call once
 	Dispatch(ctx context.Context, jobFunc func(context.Context, T))
+	// AddJob add job executed with dispatch context, wait until queue has space (job is dropped if pool is closed)
 	AddJob(job T)
+	// Submit add job executed with given context, wait until queue has space, context done, or pool closed
+	// (use context.WithoutCancel for job outliving request)
+	Submit(ctx context.Context, job T) error
+	// TrySubmit add job without waiting, return ErrWorkerPoolQueueFull when queue is full
+	TrySubmit(ctx context.Context, job T) error
+	// Finish stop accepting job and wait queued and running jobs finished
 	Finish()
+	// Shutdown stop accepting job and wait queued and running jobs finished (drain), or context done
+	Shutdown(ctx context.Context) error
+	// Stats get snapshot of pool
+	Stats() WorkerPoolStats
 }
 
-type workerPool[T any] struct {
-	maxWorker int
-	wg        sync.WaitGroup
-	jobChan   chan T
+type (
+	// WorkerPoolStats snapshot of worker pool
+	WorkerPoolStats struct {
+		Name      string `json:"name"`
+		Workers   int    `json:"workers"`
+		QueueSize int    `json:"queue_size"`
+		Queued    int    `json:"queued"`
+		Running   int    `json:"running"`
+		Submitted uint64 `json:"submitted"`
+		Completed uint64 `json:"completed"`
+		Panicked  uint64 `json:"panicked"`
+		TimedOut  uint64 `json:"timed_out"`
+		Rejected  uint64 `json:"rejected"`
+	}
+
+	// WorkerPoolOption option func
+	WorkerPoolOption func(*workerPoolOption)
+
+	workerPoolOption struct {
+		name          string
+		queueSize     int
+		jobTimeout    time.Duration
+		onError       func(ctx context.Context, err error)
+		meterProvider metric.MeterProvider
+	}
+
+	workerPool[T any] struct {
+		opt          workerPoolOption
+		maxWorker    int
+		jobChan      chan workerPoolJob[T]
+		dispatchOnce sync.Once
+		dispatchCtx  context.Context
+		jobFunc      func(context.Context, T)
+		mu           sync.RWMutex
+		closed       bool
+		submitting   sync.WaitGroup
+		workers      sync.WaitGroup
+		done         chan struct{}
+
+		running                                            atomic.Int64
+		submitted, completed, panicked, timedOut, rejected atomic.Uint64
+
+		jobCounter  metric.Int64Counter
+		jobDuration metric.Float64Histogram
+	}
+
+	workerPoolJob[T any] struct {
+		ctx context.Context
+		job T
+	}
+)
+
+// WorkerPoolName option func, pool name for log, crash report source and metrics attribute (default "worker-pool")
+func WorkerPoolName(name string) WorkerPoolOption {
+	return func(o *workerPoolOption) {
+		o.name = name
+	}
 }
 
-// NewWorkerPool create an instance of WorkerPool.
-func NewWorkerPool[T any](maxWorker int) WorkerPool[T] {
+// WorkerPoolQueueSize option func, max queued job waiting for worker (default 0, AddJob and Submit wait for idle worker)
+func WorkerPoolQueueSize(size int) WorkerPoolOption {
+	return func(o *workerPoolOption) {
+		o.queueSize = size
+	}
+}
+
+// WorkerPoolJobTimeout option func, timeout of each job context (default no timeout)
+func WorkerPoolJobTimeout(timeout time.Duration) WorkerPoolOption {
+	return func(o *workerPoolOption) {
+		o.jobTimeout = timeout
+	}
+}
+
+// WorkerPoolOnError option func, called when job panic or exceed timeout (default log error)
+func WorkerPoolOnError(onError func(ctx context.Context, err error)) WorkerPoolOption {
+	return func(o *workerPoolOption) {
+		o.onError = onError
+	}
+}
+
+// WorkerPoolMeterProvider option func, set OpenTelemetry meter provider for pool metrics (default global meter provider)
+func WorkerPoolMeterProvider(meterProvider metric.MeterProvider) WorkerPoolOption {
+	return func(o *workerPoolOption) {
+		o.meterProvider = meterProvider
+	}
+}
+
+// NewWorkerPool create an instance of WorkerPool, worker goroutines is started by Dispatch.
+func NewWorkerPool[T any](maxWorker int, opts ...WorkerPoolOption) WorkerPool[T] {
+	opt := workerPoolOption{name: "worker-pool", meterProvider: otel.GetMeterProvider()}
+	for _, o := range opts {
+		o(&opt)
+	}
+	if maxWorker <= 0 {
+		maxWorker = 1
+	}
+	if opt.queueSize < 0 {
+		opt.queueSize = 0
+	}
+	if opt.onError == nil {
+		opt.onError = func(ctx context.Context, err error) {
+			logger.LogE(fmt.Sprintf("%s: %s", opt.name, err.Error()))
+		}
+	}
+
 	wp := &workerPool[T]{
+		opt:       opt,
 		maxWorker: maxWorker,
-		wg:        sync.WaitGroup{},
-		jobChan:   make(chan T),
+		jobChan:   make(chan workerPoolJob[T], opt.queueSize),
+		done:      make(chan struct{}),
 	}
-
+	meter := opt.meterProvider.Meter("github.com/golangid/candi/candiutils")
+	wp.jobCounter, _ = meter.Int64Counter("candi.worker_pool.job",
+		metric.WithDescription("Number of worker pool job by status: completed, panic, timeout, or rejected"))
+	wp.jobDuration, _ = meter.Float64Histogram("candi.worker_pool.job.duration",
+		metric.WithDescription("Duration of worker pool job execution"), metric.WithUnit("s"))
 	return wp
 }
 
 func (wp *workerPool[T]) Dispatch(ctx context.Context, jobFunc func(context.Context, T)) {
-	for i := 0; i < wp.maxWorker; i++ {
-		go func(jobFunc func(context.Context, T)) {
-			for job := range wp.jobChan {
-				jobFunc(ctx, job)
-				wp.wg.Done()
-			}
-		}(jobFunc)
-	}
+	wp.dispatchOnce.Do(func() {
+		wp.dispatchCtx, wp.jobFunc = ctx, jobFunc
+		wp.workers.Add(wp.maxWorker)
+		for range wp.maxWorker {
+			go wp.work()
+		}
+	})
 }
 
 func (wp *workerPool[T]) AddJob(job T) {
-	wp.wg.Add(1)
-	wp.jobChan <- job
+	// nil job context, executed with dispatch context
+	wp.enqueue(context.Background(), workerPoolJob[T]{job: job}, true)
+}
+
+func (wp *workerPool[T]) Submit(ctx context.Context, job T) error {
+	return wp.enqueue(ctx, workerPoolJob[T]{ctx: ctx, job: job}, true)
+}
+
+func (wp *workerPool[T]) TrySubmit(ctx context.Context, job T) error {
+	return wp.enqueue(ctx, workerPoolJob[T]{ctx: ctx, job: job}, false)
 }
 
 func (wp *workerPool[T]) Finish() {
-	close(wp.jobChan)
-	wp.wg.Wait()
+	wp.Shutdown(context.Background())
+}
+
+func (wp *workerPool[T]) Shutdown(ctx context.Context) error {
+	wp.mu.Lock()
+	if !wp.closed {
+		wp.closed = true
+		go func() {
+			wp.submitting.Wait()
+			close(wp.jobChan)
+			wp.workers.Wait()
+			close(wp.done)
+		}()
+	}
+	wp.mu.Unlock()
+
+	select {
+	case <-wp.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (wp *workerPool[T]) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Name: wp.opt.name, Workers: wp.maxWorker, QueueSize: wp.opt.queueSize, Queued: len(wp.jobChan), Running: int(wp.running.Load()),
+		Submitted: wp.submitted.Load(), Completed: wp.completed.Load(), Panicked: wp.panicked.Load(),
+		TimedOut: wp.timedOut.Load(), Rejected: wp.rejected.Load(),
+	}
+}
+
+func (wp *workerPool[T]) enqueue(ctx context.Context, job workerPoolJob[T], wait bool) error {
+	wp.mu.RLock()
+	if wp.closed {
+		wp.mu.RUnlock()
+		wp.reject(ctx)
+		return ErrWorkerPoolClosed
+	}
+	wp.submitting.Add(1)
+	wp.mu.RUnlock()
+	defer wp.submitting.Done()
+
+	if !wait {
+		select {
+		case wp.jobChan <- job:
+			wp.submitted.Add(1)
+			return nil
+		default:
+			wp.reject(ctx)
+			return ErrWorkerPoolQueueFull
+		}
+	}
+	select {
+	case wp.jobChan <- job:
+		wp.submitted.Add(1)
+		return nil
+	case <-ctx.Done():
+		wp.reject(ctx)
+		return ctx.Err()
+	}
+}
+
+func (wp *workerPool[T]) reject(ctx context.Context) {
+	wp.rejected.Add(1)
+	wp.record(ctx, "rejected", 0)
+}
+
+func (wp *workerPool[T]) work() {
+	defer wp.workers.Done()
+	for job := range wp.jobChan {
+		wp.execute(job)
+	}
+}
+
+func (wp *workerPool[T]) execute(job workerPoolJob[T]) {
+	wp.running.Add(1)
+	defer wp.running.Add(-1)
+
+	ctx := job.ctx
+	if ctx == nil {
+		ctx = wp.dispatchCtx
+	}
+	if wp.opt.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wp.opt.jobTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	status := "completed"
+	defer func() {
+		if r := recover(); r != nil {
+			status = "panic"
+			wp.panicked.Add(1)
+			wp.opt.onError(ctx, ReportPanic(ctx, wp.opt.name, r, nil))
+		}
+		wp.record(ctx, status, time.Since(start))
+	}()
+
+	wp.jobFunc(ctx, job.job)
+	if wp.opt.jobTimeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		status = "timeout"
+		wp.timedOut.Add(1)
+		wp.opt.onError(ctx, fmt.Errorf("job exceed timeout %s: %w", wp.opt.jobTimeout, ctx.Err()))
+		return
+	}
+	wp.completed.Add(1)
+}
+
+func (wp *workerPool[T]) record(ctx context.Context, status string, duration time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("pool", wp.opt.name), attribute.String("status", status))
+	if wp.jobCounter != nil {
+		wp.jobCounter.Add(context.WithoutCancel(ctx), 1, attrs)
+	}
+	if wp.jobDuration != nil && status != "rejected" {
+		wp.jobDuration.Record(context.WithoutCancel(ctx), duration.Seconds(), attrs)
+	}
 }
 
 type SyncPool[T any] struct {
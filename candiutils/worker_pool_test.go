@@ -0,0 +1,64 @@
+package candiutils
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	SetCrashReporters()
+	defer SetCrashReporters(NewStderrCrashReporter(os.Stderr))
+
+	var errCount atomic.Int64
+	pool := NewWorkerPool[string](2, WorkerPoolName("test"), WorkerPoolQueueSize(1), WorkerPoolJobTimeout(50*time.Millisecond),
+		WorkerPoolOnError(func(ctx context.Context, err error) { errCount.Add(1) }))
+
+	release := make(chan struct{})
+	var executed atomic.Int64
+	pool.Dispatch(context.Background(), func(ctx context.Context, job string) {
+		switch job {
+		case "panic":
+			panic("boom")
+		case "timeout":
+			<-ctx.Done()
+		default:
+			<-release
+			executed.Add(1)
+		}
+	})
+	require.NoError(t, pool.Submit(context.Background(), "blocking"))
+	pool.AddJob("blocking")
+	assert.Eventually(t, func() bool { return pool.Stats().Running == 2 }, time.Second, time.Millisecond)
+
+	// bounded queue
+	require.NoError(t, pool.TrySubmit(context.Background(), "blocking"))
+	assert.ErrorIs(t, pool.TrySubmit(context.Background(), "blocking"), ErrWorkerPoolQueueFull)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Submit(ctx, "blocking"), context.DeadlineExceeded)
+	close(release)
+
+	// panic isolation and timed out job
+	require.NoError(t, pool.Submit(context.Background(), "panic"))
+	require.NoError(t, pool.Submit(context.Background(), "timeout"))
+
+	// graceful drain
+	require.NoError(t, pool.Shutdown(context.Background()))
+	assert.ErrorIs(t, pool.Submit(context.Background(), "blocking"), ErrWorkerPoolClosed)
+	pool.Finish()
+
+	stats := pool.Stats()
+	assert.Equal(t, int64(3), executed.Load())
+	assert.Equal(t, uint64(5), stats.Submitted)
+	assert.Equal(t, uint64(3), stats.Completed)
+	assert.Equal(t, uint64(1), stats.Panicked)
+	assert.Equal(t, uint64(1), stats.TimedOut)
+	assert.Equal(t, uint64(3), stats.Rejected)
+	assert.Equal(t, int64(2), errCount.Load())
+}
@@ -0,0 +1,306 @@
+// Package chaos opt-in fault injection (latency, error and dropped request/message) for HTTP, gRPC and worker handler,
+// used for testing timeout, retry and circuit breaker in staging. Rule is configured from env CHAOS_RULES or admin API
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/wrapper"
+)
+
+const (
+	// RulesEnv env key of fault rules, format: "{target}={type}:{percentage}[:{param}]" separated by ";",
+	// example: "orders=latency:30:2s;GET /v1/payments/*=error:10:503;github:push=drop:5"
+	RulesEnv = "CHAOS_RULES"
+
+	// FaultLatency delay request/message with param duration
+	FaultLatency FaultType = "latency"
+	// FaultError fail request/message with param http status code (default 503)
+	FaultError FaultType = "error"
+	// FaultDrop drop request (close connection) or message (acknowledged without calling handler)
+	FaultDrop FaultType = "drop"
+)
+
+// ErrDropped returned by Inject when request/message is dropped
+var ErrDropped = errors.New("chaos: dropped")
+
+type (
+	// FaultType type of fault
+	FaultType string
+
+	// Rule fault rule
+	Rule struct {
+		// Target glob pattern of target, HTTP "{method} {path}", gRPC full method, worker handler route (topic, queue, task name)
+		Target     string
+		Type       FaultType
+		Percentage float64
+		Latency    time.Duration
+		StatusCode int
+	}
+
+	// InjectedError injected error
+	InjectedError struct {
+		Target     string
+		StatusCode int
+	}
+
+	// OptionFunc type
+	OptionFunc func(*Injector)
+
+	// Injector fault injector
+	Injector struct {
+		mu              sync.RWMutex
+		rules           []Rule
+		allowProduction bool
+		randFunc        func() float64
+	}
+)
+
+func (e *InjectedError) Error() string {
+	return fmt.Sprintf("chaos: injected error %d on %s", e.StatusCode, e.Target)
+}
+
+// MarshalJSON encode latency as duration string
+func (r Rule) MarshalJSON() ([]byte, error) {
+	var latency string
+	if r.Latency > 0 {
+		latency = r.Latency.String()
+	}
+	return json.Marshal(ruleJSON{
+		Target: r.Target, Type: r.Type, Percentage: r.Percentage, Latency: latency, StatusCode: r.StatusCode,
+	})
+}
+
+// UnmarshalJSON decode latency from duration string (example "500ms")
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var rule ruleJSON
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return err
+	}
+	*r = Rule{Target: rule.Target, Type: rule.Type, Percentage: rule.Percentage, StatusCode: rule.StatusCode}
+	if rule.Latency != "" {
+		latency, err := time.ParseDuration(rule.Latency)
+		if err != nil {
+			return err
+		}
+		r.Latency = latency
+	}
+	return r.validate()
+}
+
+type ruleJSON struct {
+	Target     string    `json:"target"`
+	Type       FaultType `json:"type"`
+	Percentage float64   `json:"percentage"`
+	Latency    string    `json:"latency,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+}
+
+func (r *Rule) validate() error {
+	if r.Target == "" {
+		return errors.New("chaos: rule target is required")
+	}
+	if _, err := path.Match(r.Target, ""); err != nil {
+		return fmt.Errorf("chaos: invalid target pattern %q: %w", r.Target, err)
+	}
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return fmt.Errorf("chaos: percentage of %q must be between 0 and 100", r.Target)
+	}
+	switch r.Type {
+	case FaultLatency:
+		if r.Latency <= 0 {
+			return fmt.Errorf("chaos: latency of %q is required", r.Target)
+		}
+	case FaultError:
+		if r.StatusCode == 0 {
+			r.StatusCode = http.StatusServiceUnavailable
+		}
+	case FaultDrop:
+	default:
+		return fmt.Errorf("chaos: invalid fault type %q", r.Type)
+	}
+	return nil
+}
+
+// ParseRules parse rules from env format, see RulesEnv
+func ParseRules(s string) (rules []Rule, err error) {
+	for _, str := range strings.Split(s, ";") {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			continue
+		}
+		idx := strings.LastIndex(str, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("chaos: invalid rule %q", str)
+		}
+		rule := Rule{Target: strings.TrimSpace(str[:idx])}
+		parts := strings.Split(str[idx+1:], ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("chaos: invalid rule %q", str)
+		}
+		rule.Type = FaultType(parts[0])
+		if rule.Percentage, err = strconv.ParseFloat(strings.TrimSuffix(parts[1], "%"), 64); err != nil {
+			return nil, fmt.Errorf("chaos: invalid percentage of rule %q", str)
+		}
+		if len(parts) > 2 {
+			switch rule.Type {
+			case FaultLatency:
+				rule.Latency, err = time.ParseDuration(parts[2])
+			case FaultError:
+				rule.StatusCode, err = strconv.Atoi(parts[2])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("chaos: invalid param of rule %q", str)
+			}
+		}
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetRules option func
+func SetRules(rules ...Rule) OptionFunc {
+	return func(i *Injector) {
+		i.rules = rules
+	}
+}
+
+// SetRulesFromEnv option func, set rules from env CHAOS_RULES
+func SetRulesFromEnv() OptionFunc {
+	return func(i *Injector) {
+		rules, err := ParseRules(os.Getenv(RulesEnv))
+		if err != nil {
+			logger.LogE(err.Error())
+			return
+		}
+		i.rules = rules
+	}
+}
+
+// SetAllowProduction option func, by default fault is never injected when ENVIRONMENT is "production"
+func SetAllowProduction(allow bool) OptionFunc {
+	return func(i *Injector) {
+		i.allowProduction = allow
+	}
+}
+
+// SetRandFunc option func, random number generator in [0, 1)
+func SetRandFunc(randFunc func() float64) OptionFunc {
+	return func(i *Injector) {
+		i.randFunc = randFunc
+	}
+}
+
+// NewInjector create fault injector
+func NewInjector(opts ...OptionFunc) *Injector {
+	i := &Injector{randFunc: rand.Float64}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if err := i.UpdateRules(i.rules); err != nil {
+		logger.LogE(err.Error())
+		i.rules = nil
+	}
+	if len(i.rules) > 0 && i.enabled() {
+		logger.LogYellow(fmt.Sprintf("chaos: fault injection is active with %d rules", len(i.rules)))
+	}
+	return i
+}
+
+// Rules current rules
+func (i *Injector) Rules() []Rule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]Rule{}, i.rules...)
+}
+
+// UpdateRules replace rules at runtime
+func (i *Injector) UpdateRules(rules []Rule) error {
+	for idx := range rules {
+		if err := rules[idx].validate(); err != nil {
+			return err
+		}
+	}
+	i.mu.Lock()
+	i.rules = rules
+	i.mu.Unlock()
+	return nil
+}
+
+// Inject apply matched rules of target: delay latency fault, then return *InjectedError for error fault or
+// ErrDropped for drop fault
+func (i *Injector) Inject(ctx context.Context, target string) error {
+	if !i.enabled() {
+		return nil
+	}
+	i.mu.RLock()
+	rules := i.rules
+	i.mu.RUnlock()
+
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Target, target); !ok || i.randFunc()*100 >= rule.Percentage {
+			continue
+		}
+		switch rule.Type {
+		case FaultLatency:
+			timer := time.NewTimer(rule.Latency)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		case FaultError:
+			return &InjectedError{Target: target, StatusCode: rule.StatusCode}
+		case FaultDrop:
+			return ErrDropped
+		}
+	}
+	return nil
+}
+
+// HTTPHandler admin http handler, GET list rules, PUT replace rules (JSON array of rule) and DELETE remove all rules
+func (i *Injector) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			wrapper.NewHTTPResponse(http.StatusOK, "Chaos rules", i.Rules()).JSON(rw)
+		case http.MethodPut:
+			var rules []Rule
+			if err := json.NewDecoder(req.Body).Decode(&rules); err != nil {
+				wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid rules", err).JSON(rw)
+				return
+			}
+			if err := i.UpdateRules(rules); err != nil {
+				wrapper.NewHTTPResponse(http.StatusBadRequest, err.Error()).JSON(rw)
+				return
+			}
+			logger.LogYellow(fmt.Sprintf("chaos: rules updated, %d rules active", len(rules)))
+			wrapper.NewHTTPResponse(http.StatusOK, "Success update chaos rules", rules).JSON(rw)
+		case http.MethodDelete:
+			i.UpdateRules(nil)
+			wrapper.NewHTTPResponse(http.StatusOK, "Success remove chaos rules").JSON(rw)
+		default:
+			wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(rw)
+		}
+	})
+}
+
+func (i *Injector) enabled() bool {
+	return i.allowProduction || os.Getenv("ENVIRONMENT") != "production"
+}
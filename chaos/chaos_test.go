@@ -0,0 +1,74 @@
+package chaos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("orders=latency:30:2s; GET /v1/payments/*=error:10% ;github:push=drop:5")
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{Target: "orders", Type: FaultLatency, Percentage: 30, Latency: 2 * time.Second},
+		{Target: "GET /v1/payments/*", Type: FaultError, Percentage: 10, StatusCode: http.StatusServiceUnavailable},
+		{Target: "github:push", Type: FaultDrop, Percentage: 5},
+	}, rules)
+
+	_, err = ParseRules("orders=latency:30")
+	assert.Error(t, err)
+	_, err = ParseRules("orders=timeout:30")
+	assert.Error(t, err)
+}
+
+func TestInjector(t *testing.T) {
+	roll := 0.5
+	injector := NewInjector(SetRandFunc(func() float64 { return roll }), SetRules(
+		Rule{Target: "orders", Type: FaultLatency, Percentage: 100, Latency: 10 * time.Millisecond},
+		Rule{Target: "orders", Type: FaultDrop, Percentage: 60},
+		Rule{Target: "GET /v1/payments/*", Type: FaultError, Percentage: 60, StatusCode: http.StatusTooManyRequests},
+	))
+
+	var called int
+	handler := injector.Wrap(func(eventContext *candishared.EventContext) error { called++; return nil })
+	eventContext := candishared.NewEventContext(&bytes.Buffer{})
+	eventContext.SetContext(context.Background())
+	eventContext.SetHandlerRoute("orders")
+	start := time.Now()
+	assert.NoError(t, handler(eventContext))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	assert.Equal(t, 0, called)
+
+	roll = 0.9
+	assert.NoError(t, handler(eventContext))
+	assert.Equal(t, 1, called)
+
+	roll = 0.1
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { rw.WriteHeader(http.StatusOK) })
+	rec := httptest.NewRecorder()
+	injector.HTTPMiddleware(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/payments/1", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	var injectedErr *InjectedError
+	assert.True(t, errors.As(injector.Inject(context.Background(), "GET /v1/payments/2"), &injectedErr))
+	assert.NoError(t, injector.Inject(context.Background(), "POST /v1/payments/2"))
+
+	rec = httptest.NewRecorder()
+	injector.HTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/chaos",
+		strings.NewReader(`[{"target":"users","type":"latency","percentage":50,"latency":"500ms"}]`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []Rule{{Target: "users", Type: FaultLatency, Percentage: 50, Latency: 500 * time.Millisecond}}, injector.Rules())
+
+	t.Setenv("ENVIRONMENT", "production")
+	injector.UpdateRules([]Rule{{Target: "*", Type: FaultDrop, Percentage: 100}})
+	assert.NoError(t, injector.Inject(context.Background(), "orders"))
+}
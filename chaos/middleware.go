@@ -0,0 +1,94 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/wrapper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// HTTPMiddleware inject fault to http request with target "{method} {path}", dropped request connection is closed
+func (i *Injector) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		err := i.Inject(req.Context(), req.Method+" "+req.URL.Path)
+		var faultErr *InjectedError
+		switch {
+		case err == nil:
+			next.ServeHTTP(rw, req)
+		case errors.As(err, &faultErr):
+			wrapper.NewHTTPResponse(faultErr.StatusCode, err.Error()).JSON(rw)
+		case errors.Is(err, ErrDropped):
+			if hijacker, ok := rw.(http.Hijacker); ok {
+				if conn, _, err := hijacker.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
+
+// GRPCUnaryInterceptor inject fault to grpc unary call with target full method
+func (i *Injector) GRPCUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := i.Inject(ctx, info.FullMethod); err != nil {
+		return nil, grpcError(err)
+	}
+	return handler(ctx, req)
+}
+
+// GRPCStreamInterceptor inject fault to grpc stream call with target full method
+func (i *Injector) GRPCStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := i.Inject(stream.Context(), info.FullMethod); err != nil {
+		return grpcError(err)
+	}
+	return handler(srv, stream)
+}
+
+// Wrap inject fault to worker handler with target handler route, dropped message is not processed and return no error
+func (i *Injector) Wrap(handlerFunc types.WorkerHandlerFunc) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		if err := i.Inject(eventContext.Context(), eventContext.HandlerRoute()); err != nil {
+			if errors.Is(err, ErrDropped) {
+				return nil
+			}
+			return err
+		}
+		return handlerFunc(eventContext)
+	}
+}
+
+// Apply inject fault to main handler of all handler in group, call after all handler added in MountHandlers
+func (i *Injector) Apply(group *types.WorkerHandlerGroup) {
+	for idx := range group.Handlers {
+		if len(group.Handlers[idx].HandlerFuncs) > 0 {
+			group.Handlers[idx].HandlerFuncs[0] = i.Wrap(group.Handlers[idx].HandlerFuncs[0])
+		}
+	}
+}
+
+func grpcError(err error) error {
+	var faultErr *InjectedError
+	switch {
+	case errors.Is(err, ErrDropped):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.As(err, &faultErr):
+		code := codes.Internal
+		switch faultErr.StatusCode {
+		case http.StatusServiceUnavailable:
+			code = codes.Unavailable
+		case http.StatusGatewayTimeout:
+			code = codes.DeadlineExceeded
+		case http.StatusTooManyRequests:
+			code = codes.ResourceExhausted
+		}
+		return status.Error(code, err.Error())
+	}
+	return status.FromContextError(err).Err()
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -80,14 +81,29 @@ func parseInput(flagParam *flagParameter) (srvConfig serviceConfig) {
 		return
 	}
 
+	if flagParam.entityFileFlag != "" {
+		entities, err := loadEntityDefinitions(flagParam.entityFileFlag)
+		if err != nil {
+			fmt.Printf(RedFormat, err.Error())
+			os.Exit(1)
+		}
+		for _, entity := range entities {
+			if err := validateDir(flagParam.getModulePath(entity.Name)); scope != InitService && err == nil {
+				fmt.Printf(RedFormat, "module '"+entity.Name+"' is exist")
+				os.Exit(1)
+			}
+			newModules = append(newModules, moduleConfig{ModuleName: entity.Name, Fields: entity.Fields})
+			flagParam.modules = append(flagParam.modules, entity.Name)
+		}
+		sort.Strings(flagParam.modules)
+		srvConfig.Modules = append(srvConfig.Modules, newModules...)
+		goto stageSelectServerHandler
+	}
+
 stageInputModules:
 	cmdInput = readInput("Please input new module names (if more than one, separated by comma):")
 	for _, moduleName := range strings.Split(cmdInput, ",") {
-		path := "internal/modules/" + moduleName
-		if flagParam.serviceName != "" {
-			path = flagParam.outputFlag + flagParam.serviceName + "/" + path
-		}
-		if err := validateDir(path); scope != InitService && err == nil {
+		if err := validateDir(flagParam.getModulePath(moduleName)); scope != InitService && err == nil {
 			fmt.Printf(RedFormat, "module '"+moduleName+"' is exist")
 			goto stageInputModules
 		}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golangid/candi/contract"
+)
+
+const contractCommandUsage = `Usage: candi contract [flags] <command> [args]
+
+Commands:
+  check                      verify consumer contracts against publisher contracts, exit with error if incompatible
+  validate <topic> <file>    validate json message file with consumer contract of topic
+  list                       print publisher and consumer contracts
+
+Publisher contract is recorded from publisher test (contract.Recorder) to "{dir}/published",
+consumer contract (json schema of expected message) is declared in "{dir}/consumed".
+
+Flags:
+`
+
+// contractCommand check message contract compatibility, use in CI
+func contractCommand(args []string) error {
+	fs := flag.NewFlagSet("candi contract", flag.ExitOnError)
+	dir := fs.String("dir", "contracts", "contract directory")
+	published := fs.String("published", "", `publisher contract directory (example: contract artifact from publisher service), default "{dir}/published"`)
+	consumed := fs.String("consumed", "", `consumer contract directory, default "{dir}/consumed"`)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), contractCommandUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("command is required")
+	}
+	if *published == "" {
+		*published = filepath.Join(*dir, contract.PublishedDir)
+	}
+	if *consumed == "" {
+		*consumed = filepath.Join(*dir, contract.ConsumedDir)
+	}
+
+	consumedContracts, err := contract.Load(*consumed)
+	if err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "check":
+		publishedContracts, err := contract.Load(*published)
+		if err != nil {
+			return err
+		}
+		violations := contract.Verify(publishedContracts, consumedContracts)
+		for _, v := range violations {
+			fmt.Printf("\x1b[31;1m✗\x1b[0m %s\n", v)
+		}
+		if len(violations) > 0 {
+			return fmt.Errorf("%d contract violation(s)", len(violations))
+		}
+		fmt.Printf("\x1b[32;1m✓\x1b[0m %d consumer contract(s) compatible with publisher\n", len(consumedContracts))
+		return nil
+
+	case "validate":
+		if fs.NArg() < 3 {
+			return errors.New("topic and message file are required")
+		}
+		c, ok := consumedContracts[fs.Arg(1)]
+		if !ok {
+			return fmt.Errorf("contract of topic %q not found in %s", fs.Arg(1), *consumed)
+		}
+		message, err := os.ReadFile(fs.Arg(2))
+		if err != nil {
+			return err
+		}
+		if err := c.Validate(message); err != nil {
+			return err
+		}
+		fmt.Printf("\x1b[32;1m✓\x1b[0m message is valid\n")
+		return nil
+
+	case "list":
+		publishedContracts, err := contract.Load(*published)
+		if err != nil {
+			return err
+		}
+		printContracts("published", publishedContracts)
+		printContracts("consumed", consumedContracts)
+		return nil
+
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+}
+
+func printContracts(title string, contracts map[string]*contract.Contract) {
+	topics := make([]string, 0, len(contracts))
+	for topic := range contracts {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	fmt.Printf("%s:\n", title)
+	for _, topic := range topics {
+		fmt.Printf("  %-40s %d example(s)\n", topic, len(contracts[topic].Examples))
+	}
+}
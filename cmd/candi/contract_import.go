@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/golangid/candi"
+	"github.com/golangid/candi/candihelper"
+)
+
+const importCommandUsage = `Usage: candi import [flags] <contract file>
+
+Generate delivery handlers, DTOs, and usecase stubs in existing module from API contract:
+  .proto                     gRPC services, handler use generated proto package from "go_package" option
+  .yaml, .yml, .json         OpenAPI 3 spec, REST handler with route of each operation
+
+Run in service directory (contains candi.json), generated usecase stubs return unimplemented error.
+
+Flags:
+`
+
+type (
+	// contractDefinition API contract (proto services or OpenAPI operations) imported to module
+	contractDefinition struct {
+		configHeader
+		ModuleName string
+		Source     string
+		Name       string
+		UseTime    bool
+		Types      []contractType
+		Operations []contractOperation
+
+		ProtoPackage string
+		ProtoImports []string
+		Services     []contractService
+		Converters   []contractConverter
+	}
+
+	contractType struct {
+		Name, Doc  string
+		Underlying string // type definition of non struct type (example: []Pet)
+		Embeds     []string
+		Fields     []contractField
+	}
+
+	contractField struct {
+		Name, Type, Tag string
+	}
+
+	contractOperation struct {
+		Name, Summary, Method, Path string
+		Request, Response           string // domain type name, empty if operation has no request/response
+		Status                      string
+		Params                      []contractParam
+		Body, BodyRequired          bool
+		BodyField                   string // field of request for decode body, empty for decode body to request
+		Middlewares                 []string
+	}
+
+	contractParam struct {
+		Name, In, Field, Type string
+	}
+
+	contractService struct {
+		Name, Server string
+		Methods      []contractRPC
+	}
+
+	contractRPC struct {
+		Name, Request, Response string
+		PBRequest, PBResponse   string
+		FromProto, ToProto      string
+	}
+
+	contractConverter struct {
+		DTO, PB            string
+		FromProto, ToProto string
+		From, To           []string
+	}
+)
+
+// importCommand generate module delivery handlers, DTOs, and usecase stubs from existing proto or OpenAPI contract
+func importCommand(args []string) error {
+	fs := flag.NewFlagSet("candi import", flag.ExitOnError)
+	moduleName := fs.String("module", "", "target module name (module must be created first with -add-module)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), importCommandUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *moduleName == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("module and contract file are required")
+	}
+
+	b, err := os.ReadFile("candi.json")
+	if err != nil {
+		return errors.New("candi.json not found, run import in service directory")
+	}
+	var srvConfig serviceConfig
+	if err := json.Unmarshal(b, &srvConfig); err != nil {
+		return fmt.Errorf("invalid candi.json: %w", err)
+	}
+	moduleDir := filepath.Join("internal", "modules", cleanPathModule(*moduleName))
+	if err := validateDir(moduleDir); err != nil {
+		return fmt.Errorf("module %s not found, create module first with \"candi -add-module\"", *moduleName)
+	}
+
+	contractFile := fs.Arg(0)
+	source, err := os.ReadFile(contractFile)
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(contractFile))
+	def := &contractDefinition{
+		configHeader: srvConfig.configHeader, ModuleName: *moduleName, Source: filepath.Base(contractFile),
+		Name: contractTypeName(strings.TrimSuffix(filepath.Base(contractFile), filepath.Ext(contractFile))),
+	}
+	def.Header = fmt.Sprintf("Code generated by candi %s.", candi.Version)
+
+	switch ext {
+	case ".proto":
+		file, err := parseProtoContract(string(source))
+		if err != nil {
+			return err
+		}
+		if err := buildProtoContract(file, def); err != nil {
+			return err
+		}
+		if file.GoPackage == "" {
+			fmt.Printf(RedFormat, `proto file has no "go_package" option, generated handler use package `+def.ProtoPackage)
+		}
+	case ".yaml", ".yml", ".json":
+		if err := parseOpenAPIContract(source, def); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported contract file %s (must be .proto, .yaml, .yml, or .json)", contractFile)
+	}
+	if len(def.Operations) == 0 && len(def.Types) == 0 {
+		return fmt.Errorf("contract %s is empty", contractFile)
+	}
+
+	tpl = template.New("import")
+	return def.generate(moduleDir)
+}
+
+func (def *contractDefinition) generate(moduleDir string) error {
+	fileName := candihelper.ToDelimited(def.Name, '_') + ".go"
+	if err := def.checkConflict(moduleDir, fileName); err != nil {
+		return err
+	}
+
+	execGenerator(FileStructure{
+		TargetDir: filepath.Join(moduleDir, "domain") + "/", FileName: fileName,
+		FromTemplate: true, DataSource: def, Source: templateContractDomain,
+	})
+
+	modName := strings.Title(candihelper.ToCamelCase(def.ModuleName))
+	var updates []fileUpdate
+	for _, op := range def.Operations {
+		execGenerator(FileStructure{
+			TargetDir: filepath.Join(moduleDir, "usecase") + "/", FileName: candihelper.ToDelimited(op.Name, '_') + ".go",
+			FromTemplate: true, SkipIfExist: true, Source: templateContractUsecase,
+			DataSource: map[string]any{"ModuleName": def.ModuleName, "PackagePrefix": def.PackagePrefix, "Operation": op},
+		})
+	}
+	for i := len(def.Operations) - 1; i >= 0; i-- { // each method is inserted at top of interface
+		signature := def.Operations[i].Signature()
+		updates = append(updates, fileUpdate{
+			filepath:     filepath.Join(moduleDir, "usecase", "usecase.go"),
+			oldContent:   "type " + modName + "Usecase interface {",
+			newContent:   "type " + modName + "Usecase interface {\n\t" + signature,
+			skipContains: "\n\t" + signature + "\n",
+		})
+	}
+
+	switch {
+	case len(def.Services) > 0:
+		if validateDir(filepath.Join(moduleDir, "delivery", "grpchandler")) != nil {
+			fmt.Printf(RedFormat, "skip gRPC handler, module "+def.ModuleName+" has no gRPC handler (add with \"candi -add-handler\")")
+			break
+		}
+		execGenerator(FileStructure{
+			TargetDir: filepath.Join(moduleDir, "delivery", "grpchandler") + "/", FileName: fileName,
+			FromTemplate: true, DataSource: def, Source: templateContractGRPC,
+		})
+		for _, service := range def.Services {
+			updates = append(updates, fileUpdate{
+				filepath:     filepath.Join(moduleDir, "delivery", deliveryHandlerLocation[GrpcHandler]),
+				oldContent:   "Register(server *grpc.Server, mwGroup *types.MiddlewareGroup) {",
+				newContent:   "Register(server *grpc.Server, mwGroup *types.MiddlewareGroup) {\n\th.register" + service.Name + "(server)",
+				skipContains: "h.register" + service.Name + "(server)",
+			})
+		}
+
+	case len(def.Operations) > 0:
+		if validateDir(filepath.Join(moduleDir, "delivery", "resthandler")) != nil {
+			fmt.Printf(RedFormat, "skip REST handler, module "+def.ModuleName+" has no REST handler (add with \"candi -add-handler\")")
+			break
+		}
+		execGenerator(FileStructure{
+			TargetDir: filepath.Join(moduleDir, "delivery", "resthandler") + "/", FileName: fileName,
+			FromTemplate: true, DataSource: def, Source: templateContractREST,
+		})
+		updates = append(updates, fileUpdate{
+			filepath:     filepath.Join(moduleDir, "delivery", deliveryHandlerLocation[RestHandler]),
+			oldContent:   "Mount(root interfaces.RESTRouter) {",
+			newContent:   "Mount(root interfaces.RESTRouter) {\n\th.mount" + def.Name + "(root)",
+			skipContains: "h.mount" + def.Name + "(root)",
+		})
+	}
+
+	for _, fu := range updates {
+		fu.readFileAndApply()
+	}
+	return nil
+}
+
+// checkConflict check generated types and methods is not declared in module (excluding file from previous import)
+func (def *contractDefinition) checkConflict(moduleDir, fileName string) error {
+	var conflicts []string
+	domainDecls := parsePackageDecls(filepath.Join(moduleDir, "domain"), fileName)
+	for _, t := range def.Types {
+		if domainDecls[t.Name] {
+			conflicts = append(conflicts, "domain type "+t.Name)
+		}
+	}
+
+	usecaseSource, _ := os.ReadFile(filepath.Join(moduleDir, "usecase", "usecase.go"))
+	restDecls := parsePackageDecls(filepath.Join(moduleDir, "delivery", "resthandler"), fileName)
+	for _, op := range def.Operations {
+		if existing := "\n\t" + op.Name + "("; strings.Contains(string(usecaseSource), existing) &&
+			!strings.Contains(string(usecaseSource), "\n\t"+op.Signature()+"\n") {
+			conflicts = append(conflicts, "usecase method "+op.Name)
+		}
+		if len(def.Services) == 0 && restDecls["RestHandler."+candihelper.ToCamelCase(op.Name)] {
+			conflicts = append(conflicts, "rest handler method "+candihelper.ToCamelCase(op.Name))
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("contract %s conflict with existing declaration in module %s: %s (rename operation or import to another module)",
+			def.Source, def.ModuleName, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// parsePackageDecls get top level type and function names (method as "Receiver.Method") in package directory
+func parsePackageDecls(dir string, excludeFile string) map[string]bool {
+	decls := make(map[string]bool)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if filepath.Base(file) == excludeFile || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						decls[typeSpec.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				name := d.Name.Name
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					recv := d.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if ident, ok := recv.(*ast.Ident); ok {
+						name = ident.Name + "." + name
+					}
+				}
+				decls[name] = true
+			}
+		}
+	}
+	return decls
+}
+
+// Signature usecase method signature of operation
+func (op contractOperation) Signature() string {
+	signature := op.Name + "(ctx context.Context"
+	if op.Request != "" {
+		signature += ", req *domain." + op.Request
+	}
+	signature += ") ("
+	if op.Response != "" {
+		signature += "resp domain." + op.Response + ", "
+	}
+	return signature + "err error)"
+}
+
+// Assign statement for set request field from path, query, or header parameter
+func (p contractParam) Assign() string {
+	source := `req.URL.Query().Get("` + p.Name + `")`
+	switch p.In {
+	case "path":
+		source = `restserver.URLParam(req, "` + p.Name + `")`
+	case "header":
+		source = `req.Header.Get("` + p.Name + `")`
+	}
+
+	field := "payload." + p.Field
+	switch p.Type {
+	case "int":
+		return field + ", _ = strconv.Atoi(" + source + ")"
+	case "int64":
+		return field + ", _ = strconv.ParseInt(" + source + ", 10, 64)"
+	case "float64":
+		return field + ", _ = strconv.ParseFloat(" + source + ", 64)"
+	case "bool":
+		return field + ", _ = strconv.ParseBool(" + source + ")"
+	case "[]string":
+		if p.In == "header" {
+			return field + ` = req.Header.Values("` + p.Name + `")`
+		}
+		return field + ` = req.URL.Query()["` + p.Name + `"]`
+	}
+	return field + " = " + source
+}
+
+// NeedImport check if generated REST handler use package (json, io, strconv, restserver, or domain)
+func (def *contractDefinition) NeedImport(pkg string) bool {
+	for _, op := range def.Operations {
+		switch {
+		case pkg == "json" && (op.Body || op.Response != ""),
+			pkg == "io" && op.Body,
+			pkg == "domain" && op.Request != "":
+			return true
+		}
+		for _, param := range op.Params {
+			switch {
+			case pkg == "strconv" && param.Type != "string" && param.Type != "[]string",
+				pkg == "restserver" && param.In == "path":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contractTypeName exported go identifier of contract name (schema, operation, or file name)
+func contractTypeName(name string) string {
+	name = strings.Title(candihelper.ToCamelCase(name))
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "X" + name
+	}
+	return name
+}
+
+// contractFieldName exported go struct field name of contract property
+func contractFieldName(name string) string {
+	if name = contractTypeName(name); name == "" {
+		return "Field"
+	}
+	return name
+}
+
+// docLine first line of description for doc comment
+func docLine(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	openAPIDocument struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+		Servers []struct {
+			URL string `yaml:"url"`
+		} `yaml:"servers"`
+		Security   []map[string][]string        `yaml:"security"`
+		Paths      orderedMap[*openAPIPathItem] `yaml:"paths"`
+		Components struct {
+			Schemas         orderedMap[*openAPISchema]     `yaml:"schemas"`
+			Parameters      map[string]*openAPIParameter   `yaml:"parameters"`
+			RequestBodies   map[string]*openAPIRequestBody `yaml:"requestBodies"`
+			Responses       map[string]*openAPIResponse    `yaml:"responses"`
+			SecuritySchemes map[string]struct {
+				Type   string `yaml:"type"`
+				Scheme string `yaml:"scheme"`
+			} `yaml:"securitySchemes"`
+		} `yaml:"components"`
+	}
+
+	openAPIPathItem struct {
+		Parameters []*openAPIParameter `yaml:"parameters"`
+		Get        *openAPIOperation   `yaml:"get"`
+		Post       *openAPIOperation   `yaml:"post"`
+		Put        *openAPIOperation   `yaml:"put"`
+		Patch      *openAPIOperation   `yaml:"patch"`
+		Delete     *openAPIOperation   `yaml:"delete"`
+	}
+
+	openAPIOperation struct {
+		OperationID string                       `yaml:"operationId"`
+		Summary     string                       `yaml:"summary"`
+		Parameters  []*openAPIParameter          `yaml:"parameters"`
+		RequestBody *openAPIRequestBody          `yaml:"requestBody"`
+		Responses   orderedMap[*openAPIResponse] `yaml:"responses"`
+		Security    *[]map[string][]string       `yaml:"security"`
+	}
+
+	openAPIParameter struct {
+		Ref      string         `yaml:"$ref"`
+		Name     string         `yaml:"name"`
+		In       string         `yaml:"in"`
+		Required bool           `yaml:"required"`
+		Schema   *openAPISchema `yaml:"schema"`
+	}
+
+	openAPIRequestBody struct {
+		Ref      string                      `yaml:"$ref"`
+		Required bool                        `yaml:"required"`
+		Content  map[string]openAPIMediaType `yaml:"content"`
+	}
+
+	openAPIResponse struct {
+		Ref     string                      `yaml:"$ref"`
+		Content map[string]openAPIMediaType `yaml:"content"`
+	}
+
+	openAPIMediaType struct {
+		Schema *openAPISchema `yaml:"schema"`
+	}
+
+	openAPISchema struct {
+		Ref                  string                       `yaml:"$ref"`
+		Type                 openAPISchemaType            `yaml:"type"`
+		Format               string                       `yaml:"format"`
+		Description          string                       `yaml:"description"`
+		Properties           orderedMap[*openAPISchema]   `yaml:"properties"`
+		Required             []string                     `yaml:"required"`
+		Items                *openAPISchema               `yaml:"items"`
+		AdditionalProperties *openAPIAdditionalProperties `yaml:"additionalProperties"`
+		AllOf                []*openAPISchema             `yaml:"allOf"`
+		OneOf                []*openAPISchema             `yaml:"oneOf"`
+		AnyOf                []*openAPISchema             `yaml:"anyOf"`
+	}
+
+	// openAPISchemaType schema type, OpenAPI 3.1 type list is reduced to first non null type
+	openAPISchemaType string
+
+	openAPIAdditionalProperties struct {
+		Schema *openAPISchema
+	}
+
+	// orderedMap yaml/json object with preserved key order
+	orderedMap[T any] []orderedEntry[T]
+
+	orderedEntry[T any] struct {
+		Key   string
+		Value T
+	}
+
+	openAPIBuilder struct {
+		doc *openAPIDocument
+		def *contractDefinition
+	}
+)
+
+func (m *orderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected object", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		*m = append(*m, orderedEntry[T]{Key: node.Content[i].Value, Value: value})
+	}
+	return nil
+}
+
+func (t *openAPISchemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = openAPISchemaType(node.Value)
+		return nil
+	}
+	for _, item := range node.Content {
+		if item.Value != "null" {
+			*t = openAPISchemaType(item.Value)
+			break
+		}
+	}
+	return nil
+}
+
+func (a *openAPIAdditionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return nil
+	}
+	return node.Decode(&a.Schema)
+}
+
+// parseOpenAPIContract parse OpenAPI 3 spec (json or yaml) to contract operations and types
+func parseOpenAPIContract(source []byte, def *contractDefinition) error {
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	if doc.Swagger != "" || !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return fmt.Errorf("unsupported OpenAPI version %q, only OpenAPI 3 is supported", doc.OpenAPI+doc.Swagger)
+	}
+
+	b := &openAPIBuilder{doc: &doc, def: def}
+	for _, schema := range doc.Components.Schemas {
+		b.addType(contractTypeName(schema.Key), schema.Value)
+	}
+
+	var basePath string
+	if len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil && !strings.Contains(u.Path, "{") {
+			basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	for _, path := range doc.Paths {
+		for _, method := range []struct {
+			name string
+			op   *openAPIOperation
+		}{
+			{"GET", path.Value.Get}, {"POST", path.Value.Post}, {"PUT", path.Value.Put},
+			{"PATCH", path.Value.Patch}, {"DELETE", path.Value.Delete},
+		} {
+			if method.op == nil {
+				continue
+			}
+			if err := b.addOperation(method.name, basePath, path.Key, path.Value.Parameters, method.op); err != nil {
+				return fmt.Errorf("%s %s: %w", method.name, path.Key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *openAPIBuilder) addOperation(method, basePath, path string, pathParams []*openAPIParameter, op *openAPIOperation) error {
+	operation := contractOperation{
+		Name: contractTypeName(op.OperationID), Summary: docLine(op.Summary), Method: method, Path: basePath + path, Status: "http.StatusOK",
+	}
+	if op.OperationID == "" { // example: "GET /pets/{petId}" named as GetPetsPetId
+		operation.Name = contractTypeName(strings.ToLower(method) + strings.NewReplacer("/", " ", "{", "", "}", "").Replace(path))
+	}
+	operation.Middlewares = b.securityMiddlewares(op.Security)
+
+	var params []*openAPIParameter
+	for _, param := range append(append([]*openAPIParameter{}, pathParams...), op.Parameters...) {
+		if ref := param.Ref; ref != "" {
+			if param = b.doc.Components.Parameters[refName(ref)]; param == nil {
+				return fmt.Errorf("parameter %s not found", ref)
+			}
+		}
+		if param.In == "cookie" {
+			continue
+		}
+		params = append(params, param)
+	}
+
+	var bodySchema *openAPISchema
+	if body := op.RequestBody; body != nil {
+		if body.Ref != "" {
+			if body = b.doc.Components.RequestBodies[refName(body.Ref)]; body == nil {
+				return fmt.Errorf("request body %s not found", op.RequestBody.Ref)
+			}
+		}
+		bodySchema = jsonMediaSchema(body.Content)
+		operation.Body, operation.BodyRequired = bodySchema != nil, body.Required
+	}
+
+	switch {
+	case len(params) == 0 && bodySchema == nil:
+	case len(params) == 0 && bodySchema.Ref != "":
+		operation.Request = b.goType(bodySchema, "")
+	default:
+		operation.Request = "Request" + operation.Name
+		request := contractType{Name: operation.Request}
+		for _, param := range params {
+			field := contractParam{Name: param.Name, In: param.In, Field: contractFieldName(param.Name), Type: paramGoType(param.Schema)}
+			request.Fields = append(request.Fields, contractField{Name: field.Field, Type: field.Type, Tag: `json:"-"`})
+			operation.Params = append(operation.Params, field)
+		}
+		if bodySchema != nil {
+			switch resolved := b.resolveSchema(bodySchema); {
+			case bodySchema.Ref != "" && resolved.isObject():
+				request.Embeds = append(request.Embeds, b.goType(bodySchema, ""))
+			case bodySchema.Ref == "" && resolved.isObject():
+				embeds, fields := b.structFields(operation.Request, bodySchema)
+				request.Embeds, request.Fields = append(request.Embeds, embeds...), append(request.Fields, fields...)
+			default:
+				request.Fields = append(request.Fields, contractField{Name: "Body", Type: b.goType(bodySchema, operation.Request+"Body"), Tag: `json:"-"`})
+				operation.BodyField = ".Body"
+			}
+		}
+		b.def.Types = append(b.def.Types, request)
+	}
+
+	code, resp := b.successResponse(op.Responses)
+	operation.Status = httpStatusExpr(code)
+	if schema := jsonMediaSchema(resp.Content); schema != nil && code != 204 {
+		name := "Response" + operation.Name
+		operation.Response = b.goType(schema, name)
+		if schema.Ref == "" && operation.Response != name { // inline schema which is not object (example: array) defined as named type
+			b.def.Types = append(b.def.Types, contractType{Name: name, Underlying: operation.Response})
+			operation.Response = name
+		}
+	}
+	b.def.Operations = append(b.def.Operations, operation)
+	return nil
+}
+
+// successResponse get first success (2xx) response of operation, return empty response if not defined
+func (b *openAPIBuilder) successResponse(responses orderedMap[*openAPIResponse]) (code int, resp *openAPIResponse) {
+	sorted := append(orderedMap[*openAPIResponse]{}, responses...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	for _, entry := range sorted {
+		status, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(entry.Key), "XX", "00"))
+		if err != nil || status < 200 || status > 299 {
+			continue
+		}
+		resp = entry.Value
+		if resp.Ref != "" {
+			resp = b.doc.Components.Responses[refName(resp.Ref)]
+		}
+		if resp == nil {
+			resp = &openAPIResponse{}
+		}
+		return status, resp
+	}
+	return 200, &openAPIResponse{}
+}
+
+func (b *openAPIBuilder) securityMiddlewares(opSecurity *[]map[string][]string) []string {
+	security := b.doc.Security
+	if opSecurity != nil {
+		security = *opSecurity
+	}
+	var schemes []string
+	for _, requirement := range security {
+		if len(requirement) == 0 { // empty requirement is optional security
+			return nil
+		}
+		for name := range requirement {
+			schemes = append(schemes, name)
+		}
+	}
+	switch {
+	case len(schemes) == 0:
+		return nil
+	case len(schemes) > 1:
+		return []string{"HTTPMultipleAuth"}
+	}
+	if scheme := b.doc.Components.SecuritySchemes[schemes[0]]; scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic") {
+		return []string{"HTTPBasicAuth"}
+	}
+	return []string{"HTTPBearerAuth"}
+}
+
+// addType add named type of component schema
+func (b *openAPIBuilder) addType(name string, schema *openAPISchema) {
+	if resolved := b.resolveSchema(schema); schema.Ref == "" && resolved.isObject() {
+		b.goType(schema, name)
+		return
+	}
+	b.def.Types = append(b.def.Types, contractType{Name: name, Doc: docLine(schema.Description), Underlying: b.goType(schema, name+"Item")})
+}
+
+// goType get go type of schema, inline object schema is added as new type with given name
+func (b *openAPIBuilder) goType(schema *openAPISchema, name string) string {
+	switch {
+	case schema == nil:
+		return "any"
+	case schema.Ref != "":
+		return contractTypeName(refName(schema.Ref))
+	case schema.isObject() && (len(schema.Properties) > 0 || len(schema.AllOf) > 0):
+		idx := len(b.def.Types)
+		b.def.Types = append(b.def.Types, contractType{Name: name, Doc: docLine(schema.Description)})
+		embeds, fields := b.structFields(name, schema)
+		b.def.Types[idx].Embeds, b.def.Types[idx].Fields = embeds, fields
+		return name
+	case len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+		return "any"
+	}
+
+	switch schema.Type {
+	case "array":
+		return "[]" + b.goType(schema.Items, name+"Item")
+	case "object", "":
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return "map[string]" + b.goType(schema.AdditionalProperties.Schema, name+"Value")
+		}
+		return "map[string]any"
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			b.def.UseTime = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	return "any"
+}
+
+func (b *openAPIBuilder) structFields(name string, schema *openAPISchema) (embeds []string, fields []contractField) {
+	for _, item := range schema.AllOf {
+		if item.Ref != "" {
+			embeds = append(embeds, b.goType(item, ""))
+			continue
+		}
+		itemEmbeds, itemFields := b.structFields(name, item)
+		embeds, fields = append(embeds, itemEmbeds...), append(fields, itemFields...)
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, prop := range schema.Required {
+		required[prop] = true
+	}
+	for _, prop := range schema.Properties {
+		field := contractField{Name: contractFieldName(prop.Key), Tag: `json:"` + prop.Key + `,omitempty"`}
+		if required[prop.Key] {
+			field.Tag = `json:"` + prop.Key + `"`
+		}
+		field.Type = b.goType(prop.Value, name+field.Name)
+		fields = append(fields, field)
+	}
+	return embeds, fields
+}
+
+func (b *openAPIBuilder) resolveSchema(schema *openAPISchema) *openAPISchema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 10; depth++ {
+		ref := refName(schema.Ref)
+		schema = nil
+		for _, s := range b.doc.Components.Schemas {
+			if s.Key == ref {
+				schema = s.Value
+			}
+		}
+	}
+	if schema == nil {
+		return &openAPISchema{}
+	}
+	return schema
+}
+
+func (s *openAPISchema) isObject() bool {
+	return (s.Type == "object" || s.Type == "") && (len(s.Properties) > 0 || len(s.AllOf) > 0)
+}
+
+// paramGoType go type of path, query, or header parameter
+func paramGoType(schema *openAPISchema) string {
+	if schema == nil {
+		return "string"
+	}
+	switch schema.Type {
+	case "integer":
+		if schema.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]string"
+	}
+	return "string"
+}
+
+func jsonMediaSchema(content map[string]openAPIMediaType) *openAPISchema {
+	if media, ok := content["application/json"]; ok {
+		return media.Schema
+	}
+	for contentType, media := range content {
+		if strings.HasSuffix(contentType, "+json") {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndexByte(ref, '/')+1:]
+}
+
+func httpStatusExpr(code int) string {
+	switch code {
+	case 200:
+		return "http.StatusOK"
+	case 201:
+		return "http.StatusCreated"
+	case 202:
+		return "http.StatusAccepted"
+	case 204:
+		return "http.StatusNoContent"
+	}
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,626 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/golangid/candi/candihelper"
+)
+
+type (
+	protoContract struct {
+		Package   string
+		GoPackage string
+		Messages  []*protoMessage
+		Enums     map[string]bool
+		Services  []protoService
+	}
+
+	protoMessage struct {
+		Name   string // relative to package, nested message separated by dot (example: Outer.Inner)
+		Fields []protoField
+	}
+
+	protoField struct {
+		Name, Type, MapKey, Oneof string
+		Repeated, Optional        bool
+	}
+
+	protoService struct {
+		Name string
+		RPCs []protoRPC
+	}
+
+	protoRPC struct {
+		Name, Request, Response    string
+		ClientStream, ServerStream bool
+	}
+
+	protoParser struct {
+		tokens []string
+		pos    int
+		file   *protoContract
+	}
+)
+
+var protoScalarTypes = map[string]string{
+	"double": "float64", "float": "float32",
+	"int32": "int32", "sint32": "int32", "sfixed32": "int32",
+	"int64": "int64", "sint64": "int64", "sfixed64": "int64",
+	"uint32": "uint32", "fixed32": "uint32", "uint64": "uint64", "fixed64": "uint64",
+	"bool": "bool", "string": "string", "bytes": "[]byte",
+}
+
+// parseProtoContract parse proto file source (messages, enums, and services), options and extensions are ignored
+func parseProtoContract(source string) (file *protoContract, err error) {
+	p := &protoParser{tokens: tokenizeProto(source), file: &protoContract{Enums: map[string]bool{}}}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid proto: %v", r)
+		}
+	}()
+
+	for !p.eof() {
+		switch tok := p.next(); tok {
+		case "package":
+			p.file.Package = p.next()
+			p.expect(";")
+		case "option":
+			if p.peek() != "go_package" {
+				p.skipStatement()
+				continue
+			}
+			p.next()
+			p.expect("=")
+			p.file.GoPackage = strings.Trim(p.next(), `"`)
+			p.expect(";")
+		case "message":
+			p.parseMessage("")
+		case "enum":
+			p.file.Enums[p.next()] = true
+			p.skipBlock()
+		case "service":
+			p.parseService()
+		case "extend":
+			p.next()
+			p.skipBlock()
+		case ";":
+		default: // syntax, edition, import
+			p.skipStatement()
+		}
+	}
+	return p.file, nil
+}
+
+func (p *protoParser) parseMessage(parent string) {
+	msg := &protoMessage{Name: p.next()}
+	if parent != "" {
+		msg.Name = parent + "." + msg.Name
+	}
+	p.file.Messages = append(p.file.Messages, msg)
+	p.expect("{")
+	p.parseFields(msg, "")
+}
+
+func (p *protoParser) parseFields(msg *protoMessage, oneof string) {
+	for {
+		switch tok := p.next(); tok {
+		case "}":
+			return
+		case ";":
+		case "message":
+			p.parseMessage(msg.Name)
+		case "enum":
+			p.file.Enums[msg.Name+"."+p.next()] = true
+			p.skipBlock()
+		case "oneof":
+			name := p.next()
+			p.expect("{")
+			p.parseFields(msg, name)
+		case "option", "reserved", "extensions":
+			p.skipStatement()
+		case "extend":
+			p.next()
+			p.skipBlock()
+		case "map":
+			p.expect("<")
+			field := protoField{MapKey: p.next(), Oneof: oneof}
+			p.expect(",")
+			field.Type = p.next()
+			p.expect(">")
+			field.Name = p.next()
+			p.skipStatement()
+			msg.Fields = append(msg.Fields, field)
+		default:
+			field := protoField{Type: tok, Oneof: oneof}
+			switch tok {
+			case "repeated":
+				field.Repeated, field.Type = true, p.next()
+			case "optional":
+				field.Optional, field.Type = true, p.next()
+			case "required":
+				field.Type = p.next()
+			}
+			if field.Type == "group" {
+				panic("group field is not supported")
+			}
+			field.Name = p.next()
+			p.skipStatement()
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+}
+
+func (p *protoParser) parseService() {
+	service := protoService{Name: p.next()}
+	p.expect("{")
+	for {
+		switch tok := p.next(); tok {
+		case "}":
+			p.file.Services = append(p.file.Services, service)
+			return
+		case "rpc":
+			rpc := protoRPC{Name: p.next()}
+			rpc.Request, rpc.ClientStream = p.parseRPCType()
+			p.expect("returns")
+			rpc.Response, rpc.ServerStream = p.parseRPCType()
+			if p.peek() == "{" {
+				p.skipBlock()
+			} else {
+				p.expect(";")
+			}
+			service.RPCs = append(service.RPCs, rpc)
+		default:
+			p.skipStatement()
+		}
+	}
+}
+
+func (p *protoParser) parseRPCType() (typeName string, stream bool) {
+	p.expect("(")
+	typeName = p.next()
+	if typeName == "stream" && p.peek() != ")" {
+		stream, typeName = true, p.next()
+	}
+	p.expect(")")
+	return typeName, stream
+}
+
+// skipStatement skip tokens until end of statement or block
+func (p *protoParser) skipStatement() {
+	for depth := 0; ; {
+		switch p.next() {
+		case "[", "(":
+			depth++
+		case "]", ")":
+			depth--
+		case ";":
+			if depth == 0 {
+				return
+			}
+		case "{":
+			p.pos--
+			p.skipBlock()
+			return
+		}
+	}
+}
+
+// skipBlock skip tokens until end of next block
+func (p *protoParser) skipBlock() {
+	for p.next() != "{" {
+	}
+	for depth := 1; depth > 0; {
+		switch p.next() {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+	}
+}
+
+func (p *protoParser) eof() bool { return p.pos >= len(p.tokens) }
+
+func (p *protoParser) peek() string {
+	if p.eof() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *protoParser) next() string {
+	if p.eof() {
+		panic("unexpected end of file")
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+func (p *protoParser) expect(tok string) {
+	if got := p.next(); got != tok {
+		panic(fmt.Sprintf("expected %q, got %q", tok, got))
+	}
+}
+
+// tokenizeProto split proto source to identifiers, literals, and symbols, comments are removed
+func tokenizeProto(source string) (tokens []string) {
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '/' && i+1 < len(source) && source[i+1] == '/':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(source) && source[i+1] == '*':
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(source) && source[j] != c {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			tokens = append(tokens, source[i:min(j+1, len(source))])
+			i = j + 1
+		case unicode.IsSpace(rune(c)):
+			i++
+		case isProtoIdentChar(c):
+			j := i
+			for j < len(source) && isProtoIdentChar(source[j]) {
+				j++
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+func isProtoIdentChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == '+' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// resolve find message or enum full name (relative to package) of type reference in scope of message
+func (f *protoContract) resolve(typeName, scope string) (name string, isEnum, ok bool) {
+	if f.Package != "" {
+		typeName = strings.TrimPrefix(strings.TrimPrefix(typeName, "."), f.Package+".")
+	}
+	for {
+		candidate := typeName
+		if scope != "" {
+			candidate = scope + "." + typeName
+		}
+		if f.Enums[candidate] {
+			return candidate, true, true
+		}
+		for _, msg := range f.Messages {
+			if msg.Name == candidate {
+				return candidate, false, true
+			}
+		}
+		if scope == "" {
+			return "", false, false
+		}
+		scope = scope[:max(strings.LastIndexByte(scope, '.'), 0)]
+	}
+}
+
+// goCamelCase go identifier of proto name, same as protoc-gen-go naming
+func goCamelCase(s string) string {
+	isLower := func(c byte) bool { return c >= 'a' && c <= 'z' }
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+		case c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// protoJSONName json name of proto field, same as protojson field naming
+func protoJSONName(s string) string {
+	var b strings.Builder
+	upperNext := false
+	for _, c := range s {
+		if c == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			c = unicode.ToUpper(c)
+			upperNext = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+type (
+	protoElement struct {
+		goType, dtoType, pbType string // type in domain, delivery, and proto
+		from, to                func(x string) string
+		enum                    bool
+		guard                   bool // proto value must be checked for nil
+	}
+
+	protoWrapperType struct {
+		goType, constructor string
+	}
+
+	protoContractBuilder struct {
+		file *protoContract
+	}
+)
+
+var protoWrapperTypes = map[string]protoWrapperType{
+	"google.protobuf.StringValue": {"string", "String"}, "google.protobuf.BytesValue": {"[]byte", "Bytes"},
+	"google.protobuf.BoolValue": {"bool", "Bool"}, "google.protobuf.DoubleValue": {"float64", "Double"},
+	"google.protobuf.FloatValue": {"float32", "Float"}, "google.protobuf.Int32Value": {"int32", "Int32"},
+	"google.protobuf.Int64Value": {"int64", "Int64"}, "google.protobuf.UInt32Value": {"uint32", "UInt32"},
+	"google.protobuf.UInt64Value": {"uint64", "UInt64"},
+}
+
+// buildProtoContract convert proto services to contract operations, and proto messages to DTO with converter
+func buildProtoContract(file *protoContract, def *contractDefinition) error {
+	def.ProtoPackage, _, _ = strings.Cut(file.GoPackage, ";")
+	if def.ProtoPackage == "" {
+		def.ProtoPackage = def.ProtoSource + "/" + def.ModuleName
+	}
+	b := &protoContractBuilder{file: file}
+
+	for _, msg := range file.Messages {
+		dto := contractType{Name: protoDTOName(msg.Name)}
+		converter := contractConverter{
+			DTO: dto.Name, PB: goCamelCase(msg.Name),
+			FromProto: candihelper.ToCamelCase(dto.Name) + "FromProto", ToProto: candihelper.ToCamelCase(dto.Name) + "ToProto",
+		}
+		for _, field := range msg.Fields {
+			goType, from, to := b.convertField(msg, field)
+			if goType == "" {
+				todo := fmt.Sprintf("// TODO: convert field %s (%s)", field.Name, field.Type)
+				converter.From, converter.To = append(converter.From, todo), append(converter.To, todo)
+				continue
+			}
+			def.UseTime = def.UseTime || strings.Contains(goType, "time.")
+			dto.Fields = append(dto.Fields, contractField{
+				Name: goCamelCase(field.Name), Type: goType, Tag: `json:"` + protoJSONName(field.Name) + `,omitempty"`,
+			})
+			converter.From, converter.To = append(converter.From, from...), append(converter.To, to...)
+		}
+		def.Types = append(def.Types, dto)
+		def.Converters = append(def.Converters, converter)
+	}
+
+	operations := make(map[string]bool)
+	for _, svc := range file.Services {
+		service := contractService{Name: goCamelCase(svc.Name)}
+		service.Server = candihelper.ToCamelCase(service.Name) + "Server"
+		for _, rpc := range svc.RPCs {
+			if rpc.ClientStream || rpc.ServerStream {
+				fmt.Printf(RedFormat, "skip streaming rpc "+svc.Name+"/"+rpc.Name+" (implement in generated gRPC server)")
+				continue
+			}
+			method := contractRPC{Name: goCamelCase(rpc.Name)}
+			if operations[method.Name] {
+				return fmt.Errorf("rpc %s is declared in multiple services", rpc.Name)
+			}
+			operations[method.Name] = true
+
+			var err error
+			if method.PBRequest, method.Request, method.FromProto, err = b.rpcMessage(rpc.Request); err != nil {
+				return fmt.Errorf("rpc %s: %w", rpc.Name, err)
+			}
+			var toProto string
+			if method.PBResponse, method.Response, toProto, err = b.rpcMessage(rpc.Response); err != nil {
+				return fmt.Errorf("rpc %s: %w", rpc.Name, err)
+			}
+			method.ToProto = strings.TrimSuffix(toProto, "FromProto") + "ToProto"
+			service.Methods = append(service.Methods, method)
+			def.Operations = append(def.Operations, contractOperation{Name: method.Name, Request: method.Request, Response: method.Response})
+		}
+		def.Services = append(def.Services, service)
+	}
+
+	def.ProtoImports = protoKnownImports(def)
+	return nil
+}
+
+// protoKnownImports get well known type packages used in generated gRPC handler
+func protoKnownImports(def *contractDefinition) (imports []string) {
+	var statements []string
+	for _, converter := range def.Converters {
+		statements = append(append(statements, converter.From...), converter.To...)
+	}
+	for _, service := range def.Services {
+		for _, method := range service.Methods {
+			statements = append(statements, method.PBRequest, method.PBResponse)
+		}
+	}
+	code := strings.Join(statements, "\n")
+	for _, pkg := range []string{"durationpb", "emptypb", "structpb", "timestamppb", "wrapperspb"} {
+		if strings.Contains(code, pkg+".") {
+			imports = append(imports, "google.golang.org/protobuf/types/known/"+pkg)
+		}
+	}
+	return imports
+}
+
+// rpcMessage get proto type, DTO type, and converter of rpc request/response message, DTO is empty for google.protobuf.Empty
+func (b *protoContractBuilder) rpcMessage(typeName string) (pbType, dto, converter string, err error) {
+	if strings.TrimPrefix(typeName, ".") == "google.protobuf.Empty" {
+		return "emptypb.Empty", "", "", nil
+	}
+	name, isEnum, ok := b.file.resolve(typeName, "")
+	if !ok || isEnum {
+		return "", "", "", fmt.Errorf("message %s is not declared in proto file", typeName)
+	}
+	dto = protoDTOName(name)
+	return "pb." + goCamelCase(name), dto, candihelper.ToCamelCase(dto) + "FromProto", nil
+}
+
+// convertField get DTO type and converter statements (from proto and to proto) of message field, empty type if not supported
+func (b *protoContractBuilder) convertField(msg *protoMessage, field protoField) (goType string, from, to []string) {
+	name := goCamelCase(field.Name)
+	typeName := strings.TrimPrefix(field.Type, ".")
+	single := !field.Repeated && field.MapKey == "" && field.Oneof == ""
+
+	if wrapper, ok := protoWrapperTypes[typeName]; ok && single {
+		return "*" + wrapper.goType,
+			[]string{fmt.Sprintf("if m.%[1]s != nil {\nv.%[1]s = &m.%[1]s.Value\n}", name)},
+			[]string{fmt.Sprintf("if v.%[1]s != nil {\nm.%[1]s = wrapperspb.%[2]s(*v.%[1]s)\n}", name, wrapper.constructor)}
+	}
+	if typeName == "google.protobuf.Struct" && single {
+		return "map[string]any",
+			[]string{fmt.Sprintf("v.%[1]s = m.%[1]s.AsMap()", name)},
+			[]string{fmt.Sprintf("m.%[1]s, _ = structpb.NewStruct(v.%[1]s)", name)}
+	}
+
+	elem, ok := b.element(typeName, msg.Name)
+	if !ok {
+		return "", nil, nil
+	}
+	src, dst := "m."+name, "v."+name
+	switch {
+	case field.MapKey != "":
+		keyType := protoScalarTypes[field.MapKey]
+		if elem.from == nil {
+			return "map[" + keyType + "]" + elem.goType, []string{dst + " = " + src}, []string{src + " = " + dst}
+		}
+		if strings.HasPrefix(elem.dtoType, "time.") {
+		}
+		loop := "if %[1]s != nil {\n%[2]s = make(map[%[3]s]%[4]s, len(%[1]s))\nfor k, x := range %[1]s {\n%[2]s[k] = %[5]s\n}\n}"
+		return "map[" + keyType + "]" + elem.goType,
+			[]string{fmt.Sprintf(loop, src, dst, keyType, elem.dtoType, elem.from("x"))},
+			[]string{fmt.Sprintf(loop, dst, src, keyType, elem.pbType, elem.to("x"))}
+
+	case field.Repeated:
+		if elem.from == nil {
+			return "[]" + elem.goType, []string{dst + " = " + src}, []string{src + " = " + dst}
+		}
+		loop := "for _, x := range %[1]s {\n%[2]s = append(%[2]s, %[3]s)\n}"
+		return "[]" + elem.goType,
+			[]string{fmt.Sprintf(loop, src, dst, elem.from("x"))}, []string{fmt.Sprintf(loop, dst, src, elem.to("x"))}
+
+	case field.Oneof != "":
+		if elem.guard {
+			return "", nil, nil
+		}
+		value := "m.Get" + name + "()"
+		if elem.from != nil {
+			value = elem.from(value)
+		}
+		wrapper := "pb." + goCamelCase(msg.Name) + "_" + name
+		set := dst
+		if elem.to != nil {
+			set = elem.to(dst)
+		}
+		return elem.goType, []string{dst + " = " + value},
+			[]string{fmt.Sprintf("if %s {\nm.%s = &%s{%s: %s}\n}", protoNonZero(dst, elem.goType), goCamelCase(field.Oneof), wrapper, name, set)}
+
+	case field.Optional && elem.from == nil && elem.goType != "[]byte":
+		return "*" + elem.goType, []string{dst + " = " + src}, []string{src + " = " + dst}
+
+	case field.Optional && elem.enum:
+		return elem.goType, []string{dst + " = " + elem.from("m.Get"+name+"()")}, []string{src + " = " + elem.to(dst) + ".Enum()"}
+
+	case elem.guard:
+		return elem.goType,
+			[]string{fmt.Sprintf("if %s != nil {\n%s = %s\n}", src, dst, elem.from(src))},
+			[]string{fmt.Sprintf("if %s {\n%s = %s\n}", protoNonZero(dst, elem.goType), src, elem.to(dst))}
+
+	case elem.from == nil:
+		return elem.goType, []string{dst + " = " + src}, []string{src + " = " + dst}
+	}
+	return elem.goType, []string{dst + " = " + elem.from(src)}, []string{src + " = " + elem.to(dst)}
+}
+
+// element get type and converter of single value (scalar, enum, message, or well known type)
+func (b *protoContractBuilder) element(typeName, scope string) (elem protoElement, ok bool) {
+	if goType, ok := protoScalarTypes[typeName]; ok {
+		return protoElement{goType: goType, dtoType: goType, pbType: goType}, true
+	}
+
+	switch typeName {
+	case "google.protobuf.Timestamp":
+		return protoElement{
+			goType: "time.Time", dtoType: "time.Time", pbType: "*timestamppb.Timestamp", guard: true,
+			from: func(x string) string { return x + ".AsTime()" },
+			to:   func(x string) string { return "timestamppb.New(" + x + ")" },
+		}, true
+	case "google.protobuf.Duration":
+		return protoElement{
+			goType: "time.Duration", dtoType: "time.Duration", pbType: "*durationpb.Duration",
+			from: func(x string) string { return x + ".AsDuration()" },
+			to:   func(x string) string { return "durationpb.New(" + x + ")" },
+		}, true
+	}
+
+	name, isEnum, ok := b.file.resolve(typeName, scope)
+	if !ok {
+		return elem, false
+	}
+	pbType := "pb." + goCamelCase(name)
+	if isEnum {
+		return protoElement{
+			goType: "int32", dtoType: "int32", pbType: pbType, enum: true,
+			from: func(x string) string { return "int32(" + x + ")" },
+			to:   func(x string) string { return pbType + "(" + x + ")" },
+		}, true
+	}
+	dto := protoDTOName(name)
+	converter := candihelper.ToCamelCase(dto)
+	return protoElement{
+		goType: "*" + dto, dtoType: "*domain." + dto, pbType: "*" + pbType,
+		from: func(x string) string { return converter + "FromProto(" + x + ")" },
+		to:   func(x string) string { return converter + "ToProto(" + x + ")" },
+	}, true
+}
+
+// protoDTOName DTO type name of proto message (nested message name is joined)
+func protoDTOName(name string) string {
+	return strings.ReplaceAll(goCamelCase(name), "_", "")
+}
+
+// protoNonZero condition expression for check if value is not zero value
+func protoNonZero(x, goType string) string {
+	switch {
+	case goType == "string":
+		return x + ` != ""`
+	case goType == "bool":
+		return x
+	case goType == "[]byte":
+		return "len(" + x + ") > 0"
+	case goType == "time.Time":
+		return "!" + x + ".IsZero()"
+	case strings.HasPrefix(goType, "*"):
+		return x + " != nil"
+	}
+	return x + " != 0"
+}
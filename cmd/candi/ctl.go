@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const ctlCommandUsage = `Usage: candi ctl [flags] list
+       candi ctl [flags] <command> [key=value ...]
+
+Inspect and mutate runtime state of running service through control server (control.NewServer(service)) on
+local unix socket. Use "list" for show registered command (example: cron.jobs, kafka.pause, cache.flush, flag.set).
+
+Flags:
+`
+
+// ctlCommand send command to control server of running service
+func ctlCommand(args []string) error {
+	fs := flag.NewFlagSet("candi ctl", flag.ExitOnError)
+	socket := fs.String("socket", "", "unix socket path of control server")
+	service := fs.String("service", "", "service name, socket path is default control socket of service")
+	timeout := fs.Duration("timeout", time.Minute, "request timeout")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), ctlCommandUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("command is required")
+	}
+
+	socketPath := *socket
+	if socketPath == "" {
+		if *service == "" {
+			fs.Usage()
+			return errors.New("socket or service is required")
+		}
+		socketPath = filepath.Join(os.TempDir(), "candi-"+*service+".sock")
+	}
+
+	method, path, body := http.MethodGet, "/commands", io.Reader(nil)
+	if fs.Arg(0) != "list" {
+		payload := map[string]any{"command": fs.Arg(0)}
+		commandArgs := make(map[string]string)
+		for _, arg := range fs.Args()[1:] {
+			k, v, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("invalid argument %q, must be \"key=value\"", arg)
+			}
+			commandArgs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		payload["args"] = commandArgs
+		b, _ := json.Marshal(payload)
+		method, path, body = http.MethodPost, "/exec", bytes.NewReader(b)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, "http://control"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot connect to control server on %s (is service running?): %w", socketPath, err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("invalid response from %s (status %d): %w", socketPath, resp.StatusCode, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.New(response.Message)
+	}
+
+	if fs.Arg(0) == "list" {
+		var commands []struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			Args        []string `json:"args"`
+		}
+		json.Unmarshal(response.Data, &commands)
+		for _, command := range commands {
+			fmt.Printf("\x1b[1m%-20s\x1b[0m %s", command.Name, command.Description)
+			if len(command.Args) > 0 {
+				fmt.Printf(" (args: %s)", strings.Join(command.Args, ", "))
+			}
+			fmt.Println()
+		}
+		return nil
+	}
+	if len(response.Data) > 0 && string(response.Data) != "null" {
+		var out bytes.Buffer
+		json.Indent(&out, response.Data, "", "  ")
+		fmt.Println(out.String())
+		return nil
+	}
+	fmt.Println(response.Message)
+	return nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// entityDefinition model for generate module CRUD from entity definition file
+	entityDefinition struct {
+		Name   string        `yaml:"name"`
+		Fields []entityField `yaml:"fields"`
+	}
+
+	entityField struct {
+		Name       string `json:"name" yaml:"name"`
+		Type       string `json:"type" yaml:"type"`
+		Required   bool   `json:"required,omitempty" yaml:"required"`
+		Searchable bool   `json:"searchable,omitempty" yaml:"searchable"`
+	}
+)
+
+var (
+	entityFieldTypeAliases = map[string]string{
+		"string": "string", "text": "string", "varchar": "string",
+		"int": "int", "integer": "int", "int32": "int",
+		"int64": "int64", "bigint": "int64",
+		"float": "float64", "float32": "float64", "float64": "float64", "double": "float64", "decimal": "float64",
+		"bool": "bool", "boolean": "bool",
+		"time": "time", "timestamp": "time", "datetime": "time", "google.protobuf.timestamp": "time",
+	}
+	reservedEntityFields = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+	protoMessageRegex = regexp.MustCompile(`(?s)message\s+(\w+)\s*\{(.*?)\}`)
+	protoFieldRegex   = regexp.MustCompile(`^\s*(optional\s+)?([\w.]+)\s+(\w+)\s*=\s*\d+`)
+)
+
+// loadEntityDefinitions load entity definitions from yaml file (key "entities") or proto file (each message is entity)
+func loadEntityDefinitions(path string) (entities []entityDefinition, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var def struct {
+			Entities []entityDefinition `yaml:"entities"`
+		}
+		if err := yaml.Unmarshal(b, &def); err != nil {
+			return nil, fmt.Errorf("invalid entity definition %s: %w", path, err)
+		}
+		entities = def.Entities
+	case ".proto":
+		entities = parseProtoEntities(string(b))
+	default:
+		return nil, fmt.Errorf("unsupported entity definition file %s (must be .yaml, .yml, or .proto)", path)
+	}
+
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("entity definition %s is empty", path)
+	}
+	for i := range entities {
+		if err := entities[i].normalize(); err != nil {
+			return nil, err
+		}
+	}
+	return entities, nil
+}
+
+func parseProtoEntities(source string) (entities []entityDefinition) {
+	for _, message := range protoMessageRegex.FindAllStringSubmatch(source, -1) {
+		entity := entityDefinition{Name: message[1]}
+		for _, line := range strings.Split(message[2], "\n") {
+			field := protoFieldRegex.FindStringSubmatch(strings.SplitN(line, "//", 2)[0])
+			if field == nil {
+				continue
+			}
+			entity.Fields = append(entity.Fields, entityField{Name: field[3], Type: field[2]})
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func (e *entityDefinition) normalize() error {
+	e.Name = strings.TrimSpace(candihelper.ToDelimited(e.Name, '-'))
+	if e.Name == "" {
+		return fmt.Errorf("entity name cannot empty")
+	}
+
+	var fields []entityField
+	for _, field := range e.Fields {
+		field.Name = candihelper.ToDelimited(strings.TrimSpace(field.Name), '_')
+		if reservedEntityFields[field.Name] {
+			continue // generated by default
+		}
+		fieldType, ok := entityFieldTypeAliases[strings.ToLower(field.Type)]
+		if !ok {
+			return fmt.Errorf("entity %s: unsupported type %q for field %s", e.Name, field.Type, field.Name)
+		}
+		field.Type = fieldType
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("entity %s: fields cannot empty", e.Name)
+	}
+	e.Fields = fields
+	return nil
+}
+
+// EntityFields get module entity fields, return default field if module is not generated from entity definition
+func (m moduleConfig) EntityFields() []entityField {
+	if len(m.Fields) > 0 {
+		return m.Fields
+	}
+	return []entityField{{Name: "field", Type: "string", Required: true, Searchable: true}}
+}
+
+// RequiredFields get required entity fields
+func (m moduleConfig) RequiredFields() (fields []entityField) {
+	for _, field := range m.EntityFields() {
+		if field.Required {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SearchField get column name for search filter (first searchable string field)
+func (m moduleConfig) SearchField() string {
+	for _, field := range m.EntityFields() {
+		if field.Searchable && field.Type == "string" {
+			return field.Column()
+		}
+	}
+	return ""
+}
+
+// HasTimeField check if entity has time field
+func (m moduleConfig) HasTimeField() bool {
+	for _, field := range m.EntityFields() {
+		if field.IsTime() {
+			return true
+		}
+	}
+	return false
+}
+
+// SQLColumns get column names separated by comma
+func (m moduleConfig) SQLColumns() string {
+	var columns []string
+	for _, field := range m.EntityFields() {
+		columns = append(columns, field.Column())
+	}
+	return strings.Join(columns, ", ")
+}
+
+// SQLInsertPlaceholders get insert placeholders for entity fields, created_at, and updated_at
+func (m moduleConfig) SQLInsertPlaceholders() string {
+	placeholders := make([]string, len(m.EntityFields())+2)
+	for i := range placeholders {
+		placeholders[i] = "?"
+		if m.SQLDriver == "postgres" {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+	}
+	return strings.Join(placeholders, ",")
+}
+
+// StructFields get struct field access of entity fields separated by comma, prefix example "&res."
+func (m moduleConfig) StructFields(prefix string) string {
+	var fields []string
+	for _, field := range m.EntityFields() {
+		fields = append(fields, prefix+field.GoName())
+	}
+	return strings.Join(fields, ", ")
+}
+
+// UpdatedFields get quoted struct field names for candishared.DBUpdateSetUpdatedFields
+func (m moduleConfig) UpdatedFields() string {
+	var fields []string
+	for _, field := range m.EntityFields() {
+		fields = append(fields, `"`+field.GoName()+`"`)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// GoName field name in go struct
+func (f entityField) GoName() string {
+	return strings.Title(candihelper.ToCamelCase(f.Name))
+}
+
+// JSONName field name in json payload, graphql, and proto
+func (f entityField) JSONName() string {
+	return candihelper.ToCamelCase(f.Name)
+}
+
+// Column field name in database
+func (f entityField) Column() string {
+	return f.Name
+}
+
+// IsTime check if field type is time
+func (f entityField) IsTime() bool {
+	return f.Type == "time"
+}
+
+// GoType type in database model
+func (f entityField) GoType() string {
+	if f.IsTime() {
+		return "time.Time"
+	}
+	return f.Type
+}
+
+// PayloadType type in request/response payload, time is formatted in RFC3339 string
+func (f entityField) PayloadType() string {
+	if f.IsTime() {
+		return "string"
+	}
+	return f.Type
+}
+
+// ProtoType type in proto message
+func (f entityField) ProtoType() string {
+	switch f.Type {
+	case "int", "int64":
+		return "int64"
+	case "float64":
+		return "double"
+	case "bool":
+		return "bool"
+	}
+	return "string"
+}
+
+// ToProto convert payload value expression to proto type
+func (f entityField) ToProto(expr string) string {
+	if f.Type == "int" {
+		return "int64(" + expr + ")"
+	}
+	return expr
+}
+
+// FromProto convert proto value expression to payload type
+func (f entityField) FromProto(expr string) string {
+	if f.Type == "int" {
+		return "int(" + expr + ")"
+	}
+	return expr
+}
+
+// GraphQLType type in graphql schema
+func (f entityField) GraphQLType() string {
+	switch f.Type {
+	case "int", "int64":
+		return "Int"
+	case "float64":
+		return "Float"
+	case "bool":
+		return "Boolean"
+	}
+	return "String"
+}
+
+// JSONSchema property definition in json schema
+func (f entityField) JSONSchema() string {
+	switch {
+	case f.Type == "int" || f.Type == "int64":
+		return `"type": "integer"`
+	case f.Type == "float64":
+		return `"type": "number"`
+	case f.Type == "bool":
+		return `"type": "boolean"`
+	case f.IsTime():
+		return `"type": "string",
+			"format": "date-time"`
+	case f.Required:
+		return `"type": "string",
+			"minLength": 1`
+	}
+	return `"type": "string"`
+}
+
+// SQLType column type in sql migration
+func (f entityField) SQLType(driver string) string {
+	switch f.Type {
+	case "int":
+		return "INTEGER"
+	case "int64":
+		return "BIGINT"
+	case "float64":
+		if driver == "mysql" {
+			return "DOUBLE"
+		}
+		return "DOUBLE PRECISION"
+	case "bool":
+		return "BOOLEAN"
+	case "time":
+		if driver == "mysql" {
+			return "TIMESTAMP NULL"
+		}
+		return "TIMESTAMPTZ(6)"
+	}
+	return "VARCHAR(255)"
+}
+
+// GormTag field tag for gorm
+func (f entityField) GormTag() string {
+	if f.Type == "string" {
+		return "column:" + f.Column() + ";type:varchar(255)"
+	}
+	return "column:" + f.Column()
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const grpcCommandUsage = `Usage: candi grpc <command> [flags] [args]
+
+Commands:
+  list                       list all services (or methods of service if service name given)
+  describe <symbol>          describe service, method, or message
+  call <service/method>      invoke method with JSON payload
+
+Flags:
+`
+
+type headerFlags []string
+
+func (h *headerFlags) String() string       { return strings.Join(*h, ", ") }
+func (h *headerFlags) Set(val string) error { *h = append(*h, val); return nil }
+
+// grpcCommand dynamic grpc client using server reflection (grpc server must register reflection service)
+func grpcCommand(args []string) error {
+	fs := flag.NewFlagSet("candi grpc", flag.ExitOnError)
+	var headers headerFlags
+	addr := fs.String("addr", "localhost:8002", "grpc server address")
+	data := fs.String("d", "{}", `request payload in JSON, use "@filename" for read from file or "@-" for read from stdin (multiple JSON objects for client stream)`)
+	useTLS := fs.Bool("tls", false, "use TLS connection")
+	skipVerify := fs.Bool("insecure", false, "skip TLS certificate verification")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	verbose := fs.Bool("v", false, "print response headers and trailers")
+	fs.Var(&headers, "H", `request metadata "key: value", can be repeated (example: -H "authorization: Bearer <token>")`)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), grpcCommandUsage)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		return errors.New("command is required")
+	}
+	command := args[0]
+	fs.Parse(args[1:])
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	md := metadata.MD{}
+	for _, header := range headers {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, must be \"key: value\"", header)
+		}
+		md.Append(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	creds := insecure.NewCredentials()
+	if *useTLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: *skipVerify})
+	}
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resolver, err := newGRPCReflectionResolver(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer resolver.close()
+
+	switch command {
+	case "list":
+		return resolver.list(fs.Arg(0))
+	case "describe":
+		if fs.Arg(0) == "" {
+			return errors.New("symbol is required")
+		}
+		return resolver.describe(fs.Arg(0))
+	case "call":
+		if fs.Arg(0) == "" {
+			return errors.New("method is required")
+		}
+		payload, err := readGRPCPayload(*data)
+		if err != nil {
+			return err
+		}
+		return resolver.call(ctx, conn, fs.Arg(0), payload, *verbose)
+	}
+	fs.Usage()
+	return fmt.Errorf("unknown command %q", command)
+}
+
+func readGRPCPayload(data string) ([]byte, error) {
+	switch {
+	case data == "@-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(data, "@"):
+		return os.ReadFile(data[1:])
+	}
+	return []byte(data), nil
+}
+
+type grpcReflectionResolver struct {
+	stream reflectionpb.ServerReflection_ServerReflectionInfoClient
+	files  map[string]*descriptorpb.FileDescriptorProto
+}
+
+func newGRPCReflectionResolver(ctx context.Context, conn *grpc.ClientConn) (*grpcReflectionResolver, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcReflectionResolver{stream: stream, files: make(map[string]*descriptorpb.FileDescriptorProto)}, nil
+}
+
+func (r *grpcReflectionResolver) close() {
+	r.stream.CloseSend()
+}
+
+func (r *grpcReflectionResolver) request(req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if err := r.stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := r.stream.Recv()
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			return nil, fmt.Errorf("server reflection: %s (make sure grpc server enable reflection)", st.Message())
+		}
+		return nil, err
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("server reflection: %s", errResp.GetErrorMessage())
+	}
+	return resp, nil
+}
+
+func (r *grpcReflectionResolver) services() ([]string, error) {
+	resp, err := r.request(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// resolve file descriptors contains symbol with all dependencies
+func (r *grpcReflectionResolver) resolve(symbol string) (protoreflect.Descriptor, error) {
+	resp, err := r.request(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.addFiles(resp.GetFileDescriptorResponse().GetFileDescriptorProto()); err != nil {
+		return nil, err
+	}
+
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	for _, file := range r.files {
+		fileSet.File = append(fileSet.File, file)
+	}
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, err
+	}
+	return files.FindDescriptorByName(protoreflect.FullName(symbol))
+}
+
+func (r *grpcReflectionResolver) addFiles(rawFiles [][]byte) error {
+	for _, raw := range rawFiles {
+		file := new(descriptorpb.FileDescriptorProto)
+		if err := proto.Unmarshal(raw, file); err != nil {
+			return err
+		}
+		if _, ok := r.files[file.GetName()]; ok {
+			continue
+		}
+		r.files[file.GetName()] = file
+
+		for _, dep := range file.GetDependency() {
+			if _, ok := r.files[dep]; ok {
+				continue
+			}
+			resp, err := r.request(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return err
+			}
+			if err := r.addFiles(resp.GetFileDescriptorResponse().GetFileDescriptorProto()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *grpcReflectionResolver) list(serviceName string) error {
+	if serviceName == "" {
+		services, err := r.services()
+		if err != nil {
+			return err
+		}
+		for _, service := range services {
+			fmt.Println(service)
+		}
+		return nil
+	}
+
+	desc, err := r.resolve(serviceName)
+	if err != nil {
+		return err
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return fmt.Errorf("%s is not a service", serviceName)
+	}
+	for i := 0; i < service.Methods().Len(); i++ {
+		fmt.Printf("%s/%s\n", service.FullName(), service.Methods().Get(i).Name())
+	}
+	return nil
+}
+
+func (r *grpcReflectionResolver) describe(symbol string) error {
+	symbol = strings.ReplaceAll(strings.TrimPrefix(symbol, "/"), "/", ".")
+	desc, err := r.resolve(symbol)
+	if err != nil {
+		return err
+	}
+
+	switch d := desc.(type) {
+	case protoreflect.ServiceDescriptor:
+		fmt.Printf("service %s {\n", d.FullName())
+		for i := 0; i < d.Methods().Len(); i++ {
+			fmt.Printf("  %s\n", formatGRPCMethod(d.Methods().Get(i)))
+		}
+		fmt.Println("}")
+	case protoreflect.MethodDescriptor:
+		fmt.Println(formatGRPCMethod(d))
+	case protoreflect.MessageDescriptor:
+		fmt.Printf("message %s {\n", d.FullName())
+		for i := 0; i < d.Fields().Len(); i++ {
+			field := d.Fields().Get(i)
+			typeName := field.Kind().String()
+			switch {
+			case field.Message() != nil:
+				typeName = string(field.Message().FullName())
+			case field.Enum() != nil:
+				typeName = string(field.Enum().FullName())
+			}
+			if field.IsList() {
+				typeName = "repeated " + typeName
+			}
+			fmt.Printf("  %s %s = %d;\n", typeName, field.Name(), field.Number())
+		}
+		fmt.Println("}")
+	default:
+		fmt.Println(desc.FullName())
+	}
+	return nil
+}
+
+func formatGRPCMethod(method protoreflect.MethodDescriptor) string {
+	stream := func(isStream bool) string {
+		if isStream {
+			return "stream "
+		}
+		return ""
+	}
+	return fmt.Sprintf("rpc %s(%s%s) returns (%s%s);", method.Name(),
+		stream(method.IsStreamingClient()), method.Input().FullName(),
+		stream(method.IsStreamingServer()), method.Output().FullName())
+}
+
+func (r *grpcReflectionResolver) call(ctx context.Context, conn *grpc.ClientConn, fullMethod string, payload []byte, verbose bool) error {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		idx := strings.LastIndex(fullMethod, ".")
+		if idx < 0 {
+			return fmt.Errorf("invalid method %q, must be \"package.Service/Method\"", fullMethod)
+		}
+		serviceName, methodName = fullMethod[:idx], fullMethod[idx+1:]
+	}
+	desc, err := r.resolve(serviceName)
+	if err != nil {
+		return err
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return fmt.Errorf("%s is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+
+	var requests []proto.Message
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid JSON payload: %w", err)
+		}
+		req := dynamicpb.NewMessage(method.Input())
+		if err := protojson.Unmarshal(raw, req); err != nil {
+			return fmt.Errorf("invalid payload for %s: %w", method.Input().FullName(), err)
+		}
+		requests = append(requests, req)
+	}
+	if len(requests) == 0 {
+		requests = append(requests, dynamicpb.NewMessage(method.Input()))
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName: methodName, ServerStreams: method.IsStreamingServer(), ClientStreams: method.IsStreamingClient(),
+	}, fmt.Sprintf("/%s/%s", service.FullName(), method.Name()))
+	if err != nil {
+		return err
+	}
+	for _, req := range requests {
+		if err := stream.SendMsg(req); err != nil {
+			return err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	if verbose {
+		if header, err := stream.Header(); err == nil {
+			printGRPCMetadata("Response headers", header)
+		}
+	}
+
+	marshaler := protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitUnpopulated: true}
+	for {
+		resp := dynamicpb.NewMessage(method.Output())
+		err := stream.RecvMsg(resp)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if verbose {
+				printGRPCMetadata("Response trailers", stream.Trailer())
+			}
+			st := status.Convert(err)
+			return fmt.Errorf("code: %s, message: %s", st.Code(), st.Message())
+		}
+		fmt.Println(marshaler.Format(resp))
+		if !method.IsStreamingServer() {
+			break
+		}
+	}
+	if verbose {
+		printGRPCMetadata("Response trailers", stream.Trailer())
+	}
+	return nil
+}
+
+func printGRPCMetadata(title string, md metadata.MD) {
+	fmt.Printf("\x1b[33;1m%s:\x1b[0m\n", title)
+	keys := make([]string, 0, len(md))
+	for key := range md {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %s: %s\n", key, strings.Join(md[key], ", "))
+	}
+}
@@ -12,7 +12,28 @@ import (
 	"github.com/golangid/candi/candihelper"
 )
 
+// subcommands command line subcommand (first argument) with its arguments
+var subcommands = map[string]func(args []string) error{
+	"grpc":     grpcCommand,
+	"migrate":  migrateCommand,
+	"seed":     seedCommand,
+	"replay":   replayCommand,
+	"ctl":      ctlCommand,
+	"import":   importCommand,
+	"contract": contractCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Printf(RedFormat, err.Error())
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	printBanner()
 
 	var flagParam flagParameter
@@ -28,6 +49,7 @@ func main() {
 	flag.BoolVar(&flagParam.withGoModFlag, "withgomod", true, "[project generator] generate go.mod or not")
 	flag.StringVar(&flagParam.protoOutputPkgFlag, "protooutputpkg", "", "[project generator] define generated proto output target (if using grpc), with prefix is your go.mod")
 	flag.StringVar(&flagParam.outputFlag, "output", "", "[project generator] directory to write project to (default is service name)")
+	flag.StringVar(&flagParam.entityFileFlag, "entity", "", "[project generator] entity definition file (yaml or proto), generate module(s) CRUD with entity fields")
 	flag.StringVar(&flagParam.libraryNameFlag, "libraryname", getDefaultPackageName(), "[project generator] define library name")
 
 	flag.BoolVar(&flagParam.run, "run", false, "[service runner] run selected service or all service in monorepo")
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"go/format"
 	"log"
 	"os"
 	"path/filepath"
@@ -326,7 +327,7 @@ func projectGenerator(flagParam flagParameter, scope string, srvConfig serviceCo
 		var newModuleImports, newModuleInits []string
 		for _, moduleName := range newModules {
 			newModuleImports = append(newModuleImports,
-				fmt.Sprintf(`"%s/internal/modules/%s"`, srvConfig.PackagePrefix, cleanSpecialChar.Replace(moduleName)),
+				fmt.Sprintf(`"%s/internal/modules/%s"`, srvConfig.PackagePrefix, cleanPathModule(moduleName)),
 			)
 			newModuleInits = append(newModuleInits, cleanSpecialChar.Replace(moduleName)+".NewModule(deps),")
 		}
@@ -419,6 +420,11 @@ func execGenerator(fl FileStructure) {
 		} else {
 			buff = []byte(fl.Source)
 		}
+		if strings.HasSuffix(fl.FileName, ".go") {
+			if formatted, err := format.Source(buff); err == nil {
+				buff = formatted
+			}
+		}
 		if _, err := os.Stat(fl.TargetDir + fl.FileName); err == nil && fl.SkipIfExist {
 			goto execChild
 		}
@@ -57,6 +57,11 @@ func loadTemplate(source string, sourceData any) []byte {
 	return byteBuff.Bytes()
 }
 
+// cleanPathModule module directory name
+func cleanPathModule(moduleName string) string {
+	return strings.ToLower(candihelper.ToDelimited(moduleName, '-'))
+}
+
 func formatTemplate() template.FuncMap {
 	return template.FuncMap{
 
@@ -75,15 +80,16 @@ func formatTemplate() template.FuncMap {
 		"kebab": func(v string) string {
 			return candihelper.ToDelimited(v, '-')
 		},
-		"cleanPathModule": func(v string) string {
-			return strings.ToLower(candihelper.ToDelimited(v, '-'))
-		},
+		"cleanPathModule": cleanPathModule,
 		"upper": func(str string) string {
 			return strings.Title(str)
 		},
 		"lower": func(str string) string {
 			return strings.ToLower(str)
 		},
+		"add": func(a, b int) int {
+			return a + b
+		},
 		"isActive": func(str string) string {
 			ok, _ := strconv.ParseBool(str)
 			if ok {
@@ -314,7 +320,7 @@ func getNeedFileUpdates(srvConfig *serviceConfig) (fileUpdates []fileUpdate) {
 		if module.Skip && !srvConfig.flag.addHandler {
 			continue
 		}
-		moduleName := cleanSpecialChar.Replace(module.ModuleName)
+		moduleName := cleanPathModule(module.ModuleName)
 		deliveryPackageDir := fmt.Sprintf(`"%s/internal/modules/%s/delivery`, module.PackagePrefix, moduleName)
 
 		if module.RestHandler {
@@ -377,7 +383,7 @@ func getNeedFileUpdates(srvConfig *serviceConfig) (fileUpdates []fileUpdate) {
 			if module.Skip && !srvConfig.flag.addHandler {
 				continue
 			}
-			moduleName := cleanSpecialChar.Replace(module.ModuleName)
+			moduleName := cleanPathModule(module.ModuleName)
 			deliveryPackageDir := fmt.Sprintf(`"%s/internal/modules/%s/delivery`, module.PackagePrefix, moduleName)
 			fileUpdates = append(fileUpdates, fileUpdate{
 				filepath:   rootDir + "internal/modules/" + moduleName + "/module.go",
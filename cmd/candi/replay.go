@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const replayCommandUsage = `Usage: candi replay [flags] <topic or queue>
+
+Replay message of Kafka topic or RabbitMQ stream queue through registered worker handler of running service,
+service must mount replay admin API (replay.NewReplayer(...).HTTPHandler()). Default is dry-run mode (only
+preview matched message), use "-live" for process message with handler.
+
+Flags:
+`
+
+// replayCommand send replay request to replay admin API of running service
+func replayCommand(args []string) error {
+	fs := flag.NewFlagSet("candi replay", flag.ExitOnError)
+	var headers headerFlags
+	addr := fs.String("addr", "http://localhost:8000/replay", "replay admin API url of running service")
+	source := fs.String("source", "kafka", `source worker type ("kafka" or "rabbitmq")`)
+	partitions := fs.String("partitions", "", "kafka partitions, separated by comma (default all partitions)")
+	fromOffset := fs.Int64("from-offset", -1, "start offset (default oldest)")
+	toOffset := fs.Int64("to-offset", -1, "end offset, inclusive (default latest)")
+	fromTime := fs.String("from-time", "", "start message timestamp in RFC3339")
+	toTime := fs.String("to-time", "", "end message timestamp in RFC3339")
+	key := fs.String("key", "", `filter message key, support glob pattern (example "order-*")`)
+	limit := fs.Int("limit", 0, "max matched message (default unlimited)")
+	live := fs.Bool("live", false, "process matched message with handler (default dry-run)")
+	stopOnError := fs.Bool("stop-on-error", false, "stop replay on first handler error")
+	auth := fs.String("auth", "", `Authorization header of admin API (example "Bearer <token>")`)
+	timeout := fs.Duration("timeout", 10*time.Minute, "replay timeout")
+	fs.Var(&headers, "header", `filter message header "key=value", value support glob pattern, can be repeated`)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), replayCommandUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("topic is required")
+	}
+
+	payload := map[string]any{
+		"source": *source, "topic": fs.Arg(0), "key": *key, "limit": *limit, "dry_run": !*live, "stop_on_error": *stopOnError,
+	}
+	if *partitions != "" {
+		var parts []int32
+		for _, p := range strings.Split(*partitions, ",") {
+			partition, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				return fmt.Errorf("invalid partition %q", p)
+			}
+			parts = append(parts, int32(partition))
+		}
+		payload["partitions"] = parts
+	}
+	if *fromOffset >= 0 {
+		payload["from_offset"] = *fromOffset
+	}
+	if *toOffset >= 0 {
+		payload["to_offset"] = *toOffset
+	}
+	for name, val := range map[string]string{"from_time": *fromTime, "to_time": *toTime} {
+		if val == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, val); err != nil {
+			return fmt.Errorf("invalid %s %q, must be RFC3339", name, val)
+		}
+		payload[name] = val
+	}
+	if len(headers) > 0 {
+		filter := make(map[string]string, len(headers))
+		for _, header := range headers {
+			k, v, ok := strings.Cut(header, "=")
+			if !ok {
+				return fmt.Errorf("invalid header filter %q, must be \"key=value\"", header)
+			}
+			filter[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		payload["header"] = filter
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *addr, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if *auth != "" {
+		req.Header.Set("Authorization", *auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("invalid response from %s (status %d): %w", *addr, resp.StatusCode, err)
+	}
+	var result struct {
+		Scanned   int               `json:"scanned"`
+		Matched   int               `json:"matched"`
+		Processed int               `json:"processed"`
+		Failed    int               `json:"failed"`
+		Messages  []json.RawMessage `json:"messages"`
+		Errors    []json.RawMessage `json:"errors"`
+	}
+	json.Unmarshal(response.Data, &result)
+
+	mode := "dry-run"
+	if *live {
+		mode = "live"
+	}
+	fmt.Printf("\x1b[1mreplay %s (%s):\x1b[0m scanned %d, matched %d, processed %d, failed %d\n",
+		fs.Arg(0), mode, result.Scanned, result.Matched, result.Processed, result.Failed)
+	for _, message := range result.Messages {
+		var out bytes.Buffer
+		json.Indent(&out, message, "", "  ")
+		fmt.Println(out.String())
+	}
+	for _, messageErr := range result.Errors {
+		fmt.Printf(RedFormat, string(messageErr))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.New(response.Message)
+	}
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golangid/candi/migration"
+	"github.com/joho/godotenv"
+)
+
+const seedCommandUsage = `Usage: candi seed [flags] <command> [names]
+
+Commands:
+  run [names]                run pending seed files (or only given seed names) allowed in environment
+  status                     print seed status
+
+Seed file (.sql for sql database, .json array of command for mongo) in root of seed directory is run in all
+environment except production, seed file in "{environment}/" directory is only run in that environment.
+Go func seeder (migration.RegisterSeeds) is run from service binary with "--seed" flag.
+
+Flags:
+`
+
+// seedCommand run seed files, database dsn and environment default from .env
+func seedCommand(args []string) error {
+	fs := flag.NewFlagSet("candi seed", flag.ExitOnError)
+	dir := fs.String("dir", "seeds", "seed files directory")
+	dsn := fs.String("database", "", `database dsn, default from SQL_DB_WRITE_DSN or MONGODB_HOST_WRITE in .env`)
+	environment := fs.String("env", "", "environment for seed guard, default from ENVIRONMENT in .env")
+	timeout := fs.Duration("timeout", 10*time.Minute, "seed timeout (include waiting lock from another process)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), seedCommandUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("command is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	driver, err := migrationDriver(ctx, *dsn)
+	if err != nil {
+		return err
+	}
+	if *environment == "" {
+		godotenv.Load(os.Getenv("WORKDIR") + ".env")
+		*environment = os.Getenv("ENVIRONMENT")
+	}
+	seeds, err := migration.FileSeeds(os.DirFS(*dir), driver)
+	if err != nil {
+		return err
+	}
+	seeder := migration.NewSeeder(driver.(migration.SeedDriver), *environment, seeds...)
+
+	switch fs.Arg(0) {
+	case "run":
+		applied, err := seeder.Run(ctx, fs.Args()[1:]...)
+		fmt.Printf("seeded %d seed(s) in %q environment\n", len(applied), *environment)
+		return err
+
+	case "status":
+		status, err := seeder.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			state := "\x1b[33;1mpending\x1b[0m"
+			if s.SeededAt != nil {
+				state = "\x1b[32;1mseeded at " + s.SeededAt.Format(time.RFC3339) + "\x1b[0m"
+			} else if !s.Allowed {
+				state = "skipped (not allowed in environment)"
+			}
+			fmt.Printf("%-40s %s\n", s.Name, state)
+		}
+		return nil
+
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+}
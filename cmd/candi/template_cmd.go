@@ -135,13 +135,13 @@ func GetMigrateTables() []any {
 -- +goose StatementBegin
 {{if eq .SQLDriver "mysql"}}CREATE TABLE IF NOT EXISTS ` + "`" + `{{plural .ModuleName}}` + "`" + ` (
 	` + "`" + `id` + "`" + ` SERIAL NOT NULL PRIMARY KEY,
-	` + "`" + `field` + "`" + ` VARCHAR(255),
-	` + "`" + `created_at` + "`" + ` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+{{range .EntityFields}}	` + "`" + `{{.Column}}` + "`" + ` {{.SQLType $.SQLDriver}},
+{{end}}	` + "`" + `created_at` + "`" + ` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 	` + "`" + `updated_at` + "`" + ` TIMESTAMP NULL
 );{{else}}CREATE TABLE IF NOT EXISTS {{plural .ModuleName}} (
 	"id" SERIAL NOT NULL PRIMARY KEY,
-	"field" VARCHAR(255),
-	"created_at" TIMESTAMPTZ(6),
+{{range .EntityFields}}	"{{.Column}}" {{.SQLType $.SQLDriver}},
+{{end}}	"created_at" TIMESTAMPTZ(6),
 	"updated_at" TIMESTAMPTZ(6)
 );{{end}}
 -- +goose StatementEnd
@@ -41,7 +41,8 @@ func LoadServiceConfigs(baseCfg *config.Config) (deps dependency.Dependency) {
 		jaeger := tracer.InitJaeger(baseCfg.ServiceName)
 		{{if not .RedisDeps}}// {{end}}redisDeps := database.InitRedis()
 		{{if not .SQLDeps}}// {{end}}sqlDeps := database.InitSQLDatabase()
-		{{if not .MongoDeps}}// {{end}}mongoDeps := database.InitMongoDB(ctx)` + `{{if .ArangoDeps}}
+		{{if not .MongoDeps}}// {{end}}mongoDeps := database.InitMongoDB(ctx)
+		// boltDeps := database.InitBolt() // embedded key-value store, use as cache & locker without redis` + `{{if .ArangoDeps}}
 		arangoDeps := arango.InitArangoDB(ctx, sharedEnv.DbArangoReadHost, sharedEnv.DbArangoWriteHost){{end}}` + `
 ` + "{{ if .IsMonorepo }}\n		sdk.SetGlobalSDK(\n			// init service client sdk\n		)\n{{end}}" + `
 		locker := {{if not .RedisDeps}}&candiutils.NoopLocker{}{{else}}candiutils.NewRedisLocker(redisDeps.WritePool()){{end}}
@@ -63,7 +64,8 @@ func LoadServiceConfigs(baseCfg *config.Config) (deps dependency.Dependency) {
 			dependency.SetLocker(locker),
 			{{if not .RedisDeps}}// {{end}}dependency.SetRedisPool(redisDeps),
 			{{if not .SQLDeps}}// {{end}}dependency.SetSQLDatabase(sqlDeps),
-			{{if not .MongoDeps}}// {{end}}dependency.SetMongoDatabase(mongoDeps),{{if .ArangoDeps}}
+			{{if not .MongoDeps}}// {{end}}dependency.SetMongoDatabase(mongoDeps),
+			// dependency.SetBoltDatabase(boltDeps),{{if .ArangoDeps}}
 			dependency.AddExtended("arangodb", arangoDeps),{{end}}
 			// ... add more dependencies
 		)
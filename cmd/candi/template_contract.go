@@ -0,0 +1,175 @@
+package main
+
+const (
+	templateContractDomain = `// {{.Header}}
+// Source: {{.Source}}
+
+package domain
+{{- if .UseTime}}
+
+import "time"
+{{- end}}
+{{range .Types}}
+// {{.Name}} {{if .Doc}}{{.Doc}}{{else}}model{{end}}
+type {{.Name}} {{if .Underlying}}{{.Underlying}}{{else}}struct {
+{{- range .Embeds}}
+	{{.}}
+{{- end}}
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`{{.Tag}}`" + `
+{{- end}}
+}{{end}}
+{{end}}`
+
+	templateContractUsecase = `package usecase
+
+import (
+	"context"
+{{if or .Operation.Request .Operation.Response}}
+	"{{$.PackagePrefix}}/internal/modules/{{cleanPathModule .ModuleName}}/domain"
+{{end}}
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/tracer"
+)
+{{with .Operation}}
+// {{.Name}} {{if .Summary}}{{.Summary}}{{else}}usecase{{end}}
+func (uc *{{camel $.ModuleName}}UsecaseImpl) {{.Signature}} {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "{{upper (camel $.ModuleName)}}Usecase:{{.Name}}")
+	defer trace.Finish()
+
+	return {{if .Response}}resp, {{end}}candierrors.Unimplemented("{{.Name}} is not implemented")
+}
+{{end}}`
+
+	templateContractREST = `// {{.Header}}
+// Source: {{.Source}}
+
+package resthandler
+
+import (
+{{- if .NeedImport "json"}}
+	"encoding/json"
+{{- end}}
+{{- if .NeedImport "io"}}
+	"io"
+{{- end}}
+	"net/http"
+{{- if .NeedImport "strconv"}}
+	"strconv"
+{{- end}}
+{{if .NeedImport "domain"}}
+	"{{$.PackagePrefix}}/internal/modules/{{cleanPathModule .ModuleName}}/domain"
+{{end}}
+{{- if .NeedImport "restserver"}}
+	restserver "github.com/golangid/candi/codebase/app/rest_server"
+{{- end}}
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/tracer"
+	"github.com/golangid/candi/wrapper"
+)
+
+// mount{{.Name}} mount routes from {{.Source}}
+func (h *RestHandler) mount{{.Name}}(root interfaces.RESTRouter) {
+{{- range .Operations}}
+	root.{{.Method}}("{{.Path}}", h.{{camel .Name}}{{range .Middlewares}}, h.mw.{{.}}{{end}})
+{{- end}}
+}
+{{range .Operations}}
+// {{camel .Name}} handler of {{.Method}} {{.Path}}{{if .Summary}}, {{.Summary}}{{end}}
+func (h *RestHandler) {{camel .Name}}(rw http.ResponseWriter, req *http.Request) {
+	trace, ctx := tracer.StartTraceWithContext(req.Context(), "{{upper (camel $.ModuleName)}}DeliveryREST:{{.Name}}")
+	defer trace.Finish()
+{{if .Request}}
+	var payload domain.{{.Request}}
+{{- if .Body}}
+	body, _ := io.ReadAll(req.Body)
+	if err := json.Unmarshal(body, &payload{{.BodyField}}); err != nil{{if not .BodyRequired}} && len(body) > 0{{end}} {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, err.Error()).JSON(rw)
+		return
+	}
+{{- end}}
+{{- range .Params}}
+	{{.Assign}}
+{{- end}}
+{{end}}
+	{{if .Response}}res, {{end}}err := h.uc.{{upper (camel $.ModuleName)}}().{{.Name}}(ctx{{if .Request}}, &payload{{end}})
+	if err != nil {
+		wrapper.NewHTTPResponseFromError(http.StatusBadRequest, err.Error(), err).JSON(rw)
+		return
+	}
+{{if .Response}}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader({{.Status}})
+	json.NewEncoder(rw).Encode(res)
+{{- else}}
+	rw.WriteHeader({{.Status}})
+{{- end}}
+}
+{{end}}`
+
+	templateContractGRPC = `// {{.Header}}
+// Source: {{.Source}}
+
+package grpchandler
+
+import (
+	"context"
+
+	pb "{{.ProtoPackage}}"
+	"{{$.PackagePrefix}}/internal/modules/{{cleanPathModule .ModuleName}}/domain"
+
+	"google.golang.org/grpc"
+{{- range .ProtoImports}}
+	"{{.}}"
+{{- end}}
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/tracer"
+)
+{{range $service := .Services}}
+type {{.Server}} struct {
+	pb.Unimplemented{{.Name}}Server
+	*GRPCHandler
+}
+
+// register{{.Name}} register {{.Name}} server from {{$.Source}}
+func (h *GRPCHandler) register{{.Name}}(server *grpc.Server) {
+	pb.Register{{.Name}}Server(server, &{{.Server}}{GRPCHandler: h})
+}
+{{range .Methods}}
+// {{.Name}} rpc method
+func (s *{{$service.Server}}) {{.Name}}(ctx context.Context, req *{{.PBRequest}}) (*{{.PBResponse}}, error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "{{upper (camel $.ModuleName)}}DeliveryGRPC:{{.Name}}")
+	defer trace.Finish()
+
+	{{if .Response}}result, {{end}}err := s.uc.{{upper (camel $.ModuleName)}}().{{.Name}}(ctx{{if .Request}}, {{.FromProto}}(req){{end}})
+	if err != nil {
+		return nil, candierrors.ToGRPCStatus(err)
+	}
+	return {{if .Response}}{{.ToProto}}(&result){{else}}&emptypb.Empty{}{{end}}, nil
+}
+{{end}}{{end}}
+{{- range .Converters}}
+func {{.FromProto}}(m *pb.{{.PB}}) *domain.{{.DTO}} {
+	if m == nil {
+		return nil
+	}
+	v := new(domain.{{.DTO}})
+{{- range .From}}
+	{{.}}
+{{- end}}
+	return v
+}
+
+func {{.ToProto}}(v *domain.{{.DTO}}) *pb.{{.PB}} {
+	if v == nil {
+		return nil
+	}
+	m := new(pb.{{.PB}})
+{{- range .To}}
+	{{.}}
+{{- end}}
+	return m
+}
+{{end}}`
+)
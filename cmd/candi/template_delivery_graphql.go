@@ -253,14 +253,14 @@ type {{upper (camel .ModuleName)}}ListResolver {
 
 type {{upper (camel .ModuleName)}}Resolver {
 	id: {{if and .MongoDeps (not .SQLDeps)}}String{{else}}Int{{end}}!
-	field: String!
-	createdAt: String!
+{{range .EntityFields}}	{{.JSONName}}: {{.GraphQLType}}!
+{{end}}	createdAt: String!
 	updatedAt: String!
 }
 
 input {{upper (camel .ModuleName)}}InputResolver {
-	field: String!
-}
+{{range .EntityFields}}	{{.JSONName}}: {{.GraphQLType}}!
+{{end}}}
 `
 
 	templateGraphqlCommon = `# {{.Header}}
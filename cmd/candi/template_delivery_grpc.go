@@ -83,7 +83,7 @@ func (h *GRPCHandler) GetAll{{upper (camel .ModuleName)}}(ctx context.Context, r
 
 	for _, d := range result.Data {
 		data := &proto.{{upper (camel .ModuleName)}}Model{
-			Id: {{if and .MongoDeps (not .SQLDeps)}}d.ID{{else}}int64(d.ID){{end}}, Field: d.Field, CreatedAt: d.CreatedAt, UpdatedAt: d.UpdatedAt,
+			Id: {{if and .MongoDeps (not .SQLDeps)}}d.ID{{else}}int64(d.ID){{end}},{{range .EntityFields}} {{.GoName}}: {{.ToProto (printf "d.%s" .GoName)}},{{end}} CreatedAt: d.CreatedAt, UpdatedAt: d.UpdatedAt,
 		}
 		resp.Data = append(resp.Data, data)
 	}
@@ -104,7 +104,7 @@ func (h *GRPCHandler) GetDetail{{upper (camel .ModuleName)}}(ctx context.Context
 	}
 
 	resp := &proto.{{upper (camel .ModuleName)}}Model{
-		Id: {{if and .MongoDeps (not .SQLDeps)}}data.ID{{else}}int64(data.ID){{end}}, Field: data.Field, CreatedAt: data.CreatedAt, UpdatedAt: data.UpdatedAt,
+		Id: {{if and .MongoDeps (not .SQLDeps)}}data.ID{{else}}int64(data.ID){{end}},{{range .EntityFields}} {{.GoName}}: {{.ToProto (printf "data.%s" .GoName)}},{{end}} CreatedAt: data.CreatedAt, UpdatedAt: data.UpdatedAt,
 	}
 	return resp, nil
 }
@@ -117,8 +117,8 @@ func (h *GRPCHandler) Create{{upper (camel .ModuleName)}}(ctx context.Context, r
 	// tokenClaim := candishared.ParseTokenClaimFromContext(ctx) // must using GRPCBearerAuth in middleware for this handler
 
 	var payload domain.Request{{upper (camel .ModuleName)}}
-	payload.Field = req.Field
-	if err := h.validator.ValidateDocument("{{cleanPathModule .ModuleName}}/save", payload); err != nil {
+{{range .EntityFields}}	payload.{{.GoName}} = {{.FromProto (printf "req.%s" .GoName)}}
+{{end}}	if err := h.validator.ValidateDocument("{{cleanPathModule .ModuleName}}/save", payload); err != nil {
 		return nil,  status.Errorf(codes.InvalidArgument, err.Error())
 	}
 	data, err := h.uc.{{upper (camel .ModuleName)}}().Create{{upper (camel .ModuleName)}}(ctx, &payload)
@@ -127,7 +127,7 @@ func (h *GRPCHandler) Create{{upper (camel .ModuleName)}}(ctx context.Context, r
 	}
 
 	resp = &proto.{{upper (camel .ModuleName)}}Model{
-		Id: {{if and .MongoDeps (not .SQLDeps)}}data.ID{{else}}int64(data.ID){{end}}, Field: data.Field, CreatedAt: data.CreatedAt, UpdatedAt: data.UpdatedAt,
+		Id: {{if and .MongoDeps (not .SQLDeps)}}data.ID{{else}}int64(data.ID){{end}},{{range .EntityFields}} {{.GoName}}: {{.ToProto (printf "data.%s" .GoName)}},{{end}} CreatedAt: data.CreatedAt, UpdatedAt: data.UpdatedAt,
 	}
 	return resp, nil
 }
@@ -141,8 +141,8 @@ func (h *GRPCHandler) Update{{upper (camel .ModuleName)}}(ctx context.Context, r
 
 	var payload domain.Request{{upper (camel .ModuleName)}}
 	payload.ID = {{if and .MongoDeps (not .SQLDeps)}}req.Id{{else}}int(req.Id){{end}}
-	payload.Field = req.Field
-	if err := h.validator.ValidateDocument("{{cleanPathModule .ModuleName}}/save", payload); err != nil {
+{{range .EntityFields}}	payload.{{.GoName}} = {{.FromProto (printf "req.%s" .GoName)}}
+{{end}}	if err := h.validator.ValidateDocument("{{cleanPathModule .ModuleName}}/save", payload); err != nil {
 		return nil,  status.Errorf(codes.InvalidArgument, err.Error())
 	}
 	if err := h.uc.{{upper (camel .ModuleName)}}().Update{{upper (camel .ModuleName)}}(ctx, &payload); err != nil {
@@ -212,14 +212,14 @@ message GetDetail{{upper (camel .ModuleName)}}Request {
 
 message Request{{upper (camel .ModuleName)}}Model {
 	{{if and .MongoDeps (not .SQLDeps)}}string{{else}}int64{{end}} id=1;
-	string field=2;
-}
+{{range $i, $field := .EntityFields}}	{{$field.ProtoType}} {{$field.JSONName}}={{add $i 2}};
+{{end}}}
 
 message {{upper (camel .ModuleName)}}Model {
 	{{if and .MongoDeps (not .SQLDeps)}}string{{else}}int64{{end}} id=1;
-	string field=2;
-	string createdAt=3;
-	string updatedAt=4;
+{{range $i, $field := .EntityFields}}	{{$field.ProtoType}} {{$field.JSONName}}={{add $i 2}};
+{{end}}	string createdAt={{add (len .EntityFields) 2}};
+	string updatedAt={{add (len .EntityFields) 3}};
 }
 
 message BaseResponse {
@@ -12,8 +12,8 @@ import (
 // {{upper (camel .ModuleName)}} model
 type {{upper (camel .ModuleName)}} struct {
 	ID         {{if and .MongoDeps (not .SQLDeps)}}primitive.ObjectID{{else}}int{{end}}    ` + "`" + `{{if .SQLUseGORM}}gorm:"column:id;primary_key" {{else}}sql:"id" {{end}}` + `{{if .MongoDeps}}bson:"_id" {{end}}` + `json:"id"` + "`" + `
-	Field      string    ` + "`" + `{{if .SQLUseGORM}}gorm:"column:field;type:varchar(255)" {{else}}sql:"field" {{end}}` + `{{if .MongoDeps}}bson:"field" {{end}}` + `json:"field"` + "`" + `
-	CreatedAt  time.Time ` + "`" + `{{if .SQLUseGORM}}gorm:"column:created_at" {{else}}sql:"created_at" {{end}}` + `{{if .MongoDeps}}bson:"created_at" {{end}}` + `json:"created_at"` + "`" + `
+{{range .EntityFields}}	{{.GoName}} {{.GoType}} ` + "`" + `{{if $.SQLUseGORM}}gorm:"{{.GormTag}}" {{else}}sql:"{{.Column}}" {{end}}` + `{{if $.MongoDeps}}bson:"{{.Column}}" {{end}}` + `json:"{{.Column}}"` + "`" + `
+{{end}}	CreatedAt  time.Time ` + "`" + `{{if .SQLUseGORM}}gorm:"column:created_at" {{else}}sql:"created_at" {{end}}` + `{{if .MongoDeps}}bson:"created_at" {{end}}` + `json:"created_at"` + "`" + `
 	UpdatedAt  time.Time ` + "`" + `{{if .SQLUseGORM}}gorm:"column:updated_at" {{else}}sql:"updated_at" {{end}}` + `{{if .MongoDeps}}bson:"updated_at" {{end}}` + `json:"updated_at"` + "`" + `
 }
 {{if .SQLUseGORM}}
@@ -43,19 +43,20 @@ type Filter{{upper (camel .ModuleName)}} struct {
 	templateModuleRequestDomain = `package domain
 
 import (
-	shareddomain "{{$.PackagePrefix}}/pkg/shared/domain"
+	shareddomain "{{$.PackagePrefix}}/pkg/shared/domain"{{if .HasTimeField}}
+	"time"{{end}}
 )
 
 // Request{{upper (camel .ModuleName)}} model
 type Request{{upper (camel .ModuleName)}} struct {
 	ID    {{if and .MongoDeps (not .SQLDeps)}}string{{else}}int{{end}} ` + "`json:\"id\"`" + `
-	Field string ` + "`json:\"field\"`" + `
-}
+{{range .EntityFields}}	{{.GoName}} {{.PayloadType}} ` + "`json:\"{{.JSONName}}\"`" + `
+{{end}}}
 
 // Deserialize to db model
 func (r *Request{{upper (camel .ModuleName)}}) Deserialize() (res shareddomain.{{upper (camel .ModuleName)}}) {
-	res.Field = r.Field
-	return
+{{range .EntityFields}}	{{if .IsTime}}res.{{.GoName}}, _ = time.Parse(time.RFC3339, r.{{.GoName}}){{else}}res.{{.GoName}} = r.{{.GoName}}{{end}}
+{{end}}	return
 }
 `
 	templateModuleResponseDomain = `package domain
@@ -76,16 +77,16 @@ type Response{{upper (camel .ModuleName)}}List struct {
 // Response{{upper (camel .ModuleName)}} model
 type Response{{upper (camel .ModuleName)}} struct {
 	ID        {{if and .MongoDeps (not .SQLDeps)}}string{{else}}int{{end}} ` + "`json:\"id\"`" + `
-	Field     string ` + "`json:\"field\"`" + `
-	CreatedAt string ` + "`json:\"createdAt\"`" + `
+{{range .EntityFields}}	{{.GoName}} {{.PayloadType}} ` + "`json:\"{{.JSONName}}\"`" + `
+{{end}}	CreatedAt string ` + "`json:\"createdAt\"`" + `
 	UpdatedAt string ` + "`json:\"updatedAt\"`" + `
 }
 
 // Serialize from db model
 func (r *Response{{upper (camel .ModuleName)}}) Serialize(source *shareddomain.{{upper (camel .ModuleName)}}) {
 	r.ID = source.ID{{if and .MongoDeps (not .SQLDeps)}}.Hex(){{end}}
-	r.Field = source.Field
-	r.CreatedAt = source.CreatedAt.Format(time.RFC3339)
+{{range .EntityFields}}	{{if .IsTime}}r.{{.GoName}} = source.{{.GoName}}.Format(time.RFC3339){{else}}r.{{.GoName}} = source.{{.GoName}}{{end}}
+{{end}}	r.CreatedAt = source.CreatedAt.Format(time.RFC3339)
 	r.UpdatedAt = source.UpdatedAt.Format(time.RFC3339)
 }
 `
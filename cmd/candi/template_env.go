@@ -22,6 +22,18 @@ USE_RABBITMQ_CONSUMER={{.RabbitMQHandler}} # event driven handler and dynamic sc
 USE_SHARED_LISTENER=false
 HTTP_PORT=8000
 GRPC_PORT=8002
+# zero downtime restart with SIGUSR2 (bare-metal/VM): handoff (pass listener to new process) or reuseport, empty to disable
+GRACEFUL_RESTART=
+# apply database migration (golang-migrate format) from MIGRATION_DIR when service start
+MIGRATION_ON_STARTUP=false
+MIGRATION_DIR=migrations
+# per environment enable/disable worker type or handler (yaml/json), default worker_matrix.yaml if exist
+WORKER_MATRIX_FILE=
+# seed files directory, run seeder with "--seed" flag or POST /seed endpoint
+SEED_DIR=seeds
+# message contract directory (published/ and consumed/), strict mode reject consumed message violating contract
+CONTRACT_DIR=contracts
+CONTRACT_STRICT=false
 
 TASK_QUEUE_DASHBOARD_PORT=8080
 TASK_QUEUE_DASHBOARD_MAX_CLIENT=5
@@ -113,7 +113,7 @@ coverage.txt
 		},
 		"orderBy": {
 			"type": "string",
-			"enum": ["id", "field", "createdAt", "updatedAt"]
+			"enum": ["id",{{range .EntityFields}} "{{.JSONName}}",{{end}} "createdAt", "updatedAt"]
 		},
 		"sort": {
 			"type": "string",
@@ -159,12 +159,12 @@ coverage.txt
 		"id": {
 			"type": "{{if and .MongoDeps (not .SQLDeps)}}string{{else}}integer{{end}}"
 		},
-		"field": {
-			"type": "string",
-			"minLength": 1
-		}
+{{range $i, $field := .EntityFields}}{{if $i}},
+{{end}}		"{{$field.JSONName}}": {
+			{{$field.JSONSchema}}
+		}{{end}}
 	},
-	"required": [ "field" ],
+	"required": [{{range $i, $field := .RequiredFields}}{{if $i}},{{end}} "{{$field.JSONName}}"{{end}} ],
 	"additionalProperties": false
 }
 `
@@ -486,10 +486,10 @@ func (r *{{camel .ModuleName}}RepoMongo) setFilter{{upper (camel .ModuleName)}}(
 	if filter.ID != nil {
 		{{if not .SQLDeps}}query["_id"], _ = primitive.ObjectIDFromHex(*filter.ID){{else}}query["_id"] = *filter.ID{{end}}
 	}
-	if filter.Search != "" {
-		query["field"] = bson.M{"$regex": filter.Search}
+{{with .SearchField}}	if filter.Search != "" {
+		query["{{.}}"] = bson.M{"$regex": filter.Search}
 	}
-
+{{end}}
 	return query
 }
 `
@@ -621,7 +621,7 @@ func (r *{{camel .ModuleName}}RepoSQL) FetchAll(ctx context.Context, filter *dom
 	if len(args) > 0 {
 		where = " WHERE " + where
 	}
-	query := fmt.Sprintf("SELECT id, field, created_at, updated_at FROM {{plural .ModuleName}}%s ORDER BY %s %s LIMIT %d OFFSET %d",
+	query := fmt.Sprintf("SELECT id, {{.SQLColumns}}, created_at, updated_at FROM {{plural .ModuleName}}%s ORDER BY %s %s LIMIT %d OFFSET %d",
 		where, filter.OrderBy, filter.Sort, filter.Limit, filter.CalculateOffset())
 	trace.Log("query", query)
 	rows, err := r.readDB.Query(query, args...)
@@ -631,7 +631,7 @@ func (r *{{camel .ModuleName}}RepoSQL) FetchAll(ctx context.Context, filter *dom
 	defer rows.Close()
 	for rows.Next() {
 		var res shareddomain.{{upper (camel .ModuleName)}}
-		if err := rows.Scan(&res.ID, &res.Field, &res.CreatedAt, &res.UpdatedAt); err != nil {
+		if err := rows.Scan(&res.ID, {{.StructFields "&res."}}, &res.CreatedAt, &res.UpdatedAt); err != nil {
 			return nil, err
 		}
 		data = append(data, res)
@@ -664,11 +664,11 @@ func (r *{{camel .ModuleName}}RepoSQL) Find(ctx context.Context, filter *domain.
 
 	{{if .SQLUseGORM}}err = r.setFilter{{upper (camel .ModuleName)}}({{ if .IsMonorepo }}global{{end}}shared.SetSpanToGorm(ctx, r.readDB), filter).First(&result).Error
 	{{else}}where, args := r.setFilter{{upper (camel .ModuleName)}}(filter)
-	query := "SELECT id, field, created_at, updated_at FROM {{plural .ModuleName}} WHERE " + where + " LIMIT 1"
+	query := "SELECT id, {{.SQLColumns}}, created_at, updated_at FROM {{plural .ModuleName}} WHERE " + where + " LIMIT 1"
 	trace.Log("query", query)
 	trace.Log("args", args)
 	err = r.readDB.QueryRow(query, args...).
-		Scan(&result.ID, &result.Field, &result.CreatedAt, &result.UpdatedAt)
+		Scan(&result.ID, {{.StructFields "&result."}}, &result.CreatedAt, &result.UpdatedAt)
 	{{end}}return
 }
 
@@ -697,8 +697,8 @@ func (r *{{camel .ModuleName}}RepoSQL) Save(ctx context.Context, data *shareddom
 		data.CreatedAt = time.Now()
 	}
 	if data.ID == 0 {
-		query = "INSERT INTO {{plural .ModuleName}} (field, created_at, updated_at) VALUES ({{if eq .SQLDriver "postgres"}}$1,$2,$3{{else}}?,?,?{{end}})"
-		args = []any{data.Field, data.CreatedAt, data.UpdatedAt}
+		query = "INSERT INTO {{plural .ModuleName}} ({{.SQLColumns}}, created_at, updated_at) VALUES ({{.SQLInsertPlaceholders}})"
+		args = []any{ {{.StructFields "data."}}, data.CreatedAt, data.UpdatedAt}
 	} else {
 		var updatedFields []string{{if eq .SQLDriver "postgres"}}
 		i := 1{{end}}
@@ -769,10 +769,10 @@ func (r *{{camel .ModuleName}}RepoSQL) setFilter{{upper (camel .ModuleName)}}({{
 		{{if .SQLUseGORM}}db = db.Where("id = ?", *filter.ID){{else}}wheres = append(wheres, {{if eq .SQLDriver "postgres"}}fmt.Sprintf("id = $%d", len(args)+1){{else}}"id = ?"{{end}})
 		args = append(args, *filter.ID){{end}}
 	}
-	if filter.Search != "" {
-		{{if .SQLUseGORM}}db = db.Where("(field ILIKE '%%' || ? || '%%')", filter.Search){{else}}wheres = append(wheres, {{if eq .SQLDriver "postgres"}}fmt.Sprintf("field ILIKE '%%%%' || $%d || '%%%%'", len(args)+1){{else}}"field ILIKE '%%' || ? || '%%'"{{end}})
+{{with .SearchField}}	if filter.Search != "" {
+		{{if $.SQLUseGORM}}db = db.Where("({{.}} ILIKE '%%' || ? || '%%')", filter.Search){{else}}wheres = append(wheres, {{if eq $.SQLDriver "postgres"}}fmt.Sprintf("{{.}} ILIKE '%%%%' || $%d || '%%%%'", len(args)+1){{else}}"{{.}} ILIKE '%%' || ? || '%%'"{{end}})
 		args = append(args, filter.Search){{end}}
-	}{{if .SQLUseGORM}}
+	}{{end}}{{if .SQLUseGORM}}
 
 	for _, preload := range filter.Preloads {
 		db = db.Preload(preload)
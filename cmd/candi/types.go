@@ -50,6 +50,7 @@ var (
 type flagParameter struct {
 	scopeFlag, packagePrefixFlag, protoOutputPkgFlag, outputFlag, libraryNameFlag string
 	withGoModFlag                                                                 bool
+	entityFileFlag                                                                string
 	run, all                                                                      bool
 	initService, addModule, addHandler, initMonorepo, version, isMonorepo         bool
 	serviceName, moduleName, monorepoProjectName                                  string
@@ -85,6 +86,14 @@ func (f *flagParameter) validateModuleName(moduleName string) (err error) {
 	return
 }
 
+func (f *flagParameter) getModulePath(moduleName string) string {
+	path := "internal/modules/" + moduleName
+	if f.serviceName != "" {
+		path = f.outputFlag + f.serviceName + "/" + path
+	}
+	return path
+}
+
 func (f *flagParameter) getFullModuleChildDir(paths ...string) string {
 	paths = append([]string{f.moduleName}, paths...)
 	return strings.TrimPrefix(f.outputFlag+f.serviceName+"/internal/modules/"+strings.Join(paths, "/"), "/")
@@ -167,7 +176,8 @@ type moduleConfig struct {
 	configHeader `json:"-"`
 	config       `json:"-"`
 	ModuleName   string
-	Skip         bool `json:"-"`
+	Fields       []entityField `json:",omitempty"`
+	Skip         bool          `json:"-"`
 }
 
 func (m *moduleConfig) constructModuleWorkerActivation() (workerActivations []string) {
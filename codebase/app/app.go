@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
+	"github.com/golangid/candi/candiutils/graceful"
 	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/config/env"
 )
 
 // Option app option
@@ -45,6 +49,10 @@ type App struct {
 	quitSignal         chan os.Signal
 	quitSignalTriggers []os.Signal
 	service            factory.ServiceFactory
+	selfTest           bool
+	selfTestChecks     []SelfTestCheck
+	migrationSource    fs.FS
+	seed               bool
 }
 
 // New init new service app
@@ -55,6 +63,8 @@ func New(service factory.ServiceFactory, opts ...Option) *App {
 		shutdownTimeout:    1 * time.Minute,
 		quitSignal:         make(chan os.Signal, 1),
 		quitSignalTriggers: []os.Signal{os.Interrupt, syscall.SIGTERM},
+		selfTest:           slices.Contains(os.Args[1:], SelfTestFlag),
+		seed:               slices.Contains(os.Args[1:], SeedFlag),
 	}
 	for _, opt := range opts {
 		opt(app)
@@ -64,6 +74,20 @@ func New(service factory.ServiceFactory, opts ...Option) *App {
 
 // Run start app
 func (a *App) Run() {
+	if a.selfTest {
+		a.runSelfTest()
+		return
+	}
+
+	if env.BaseEnv().MigrationOnStartup {
+		if err := a.migrate(); err != nil {
+			log.Panicf("Migration: %v", err)
+		}
+	}
+	if a.seed {
+		a.runSeed()
+		return
+	}
 	if err := a.checkRequired(); err != nil {
 		log.Panic(err)
 	}
@@ -86,15 +110,45 @@ func (a *App) Run() {
 	}
 
 	signal.Notify(a.quitSignal, a.quitSignalTriggers...)
+	restartSignal := make(chan os.Signal, 1)
+	if graceful.Enabled() && len(graceful.RestartSignals()) > 0 {
+		signal.Notify(restartSignal, graceful.RestartSignals()...)
+	}
 
 	log.Printf("Application \x1b[32;1m%s\x1b[0m ready to run\n\n", a.service.Name())
+	if err := graceful.Ready(); err != nil {
+		log.Printf("\x1b[31;1mGraceful restart: failed notify ready: %v\x1b[0m", err)
+	}
 
-	select {
-	case e := <-errServe:
-		log.Panic(e)
-	case <-a.quitSignal:
-		a.shutdown()
+	for {
+		select {
+		case e := <-errServe:
+			log.Panic(e)
+		case <-a.quitSignal:
+			a.shutdown()
+			return
+		case <-restartSignal:
+			if a.restart() {
+				a.shutdown()
+				return
+			}
+		}
+	}
+}
+
+// restart start new process of current binary (graceful restart), old process is drained when new process ready
+func (a *App) restart() bool {
+	fmt.Printf("\x1b[34;1mGraceful restart (%s mode)... starting new process\x1b[0m\n", graceful.Mode())
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+	pid, err := graceful.Restart(ctx)
+	if err != nil {
+		log.Printf("\x1b[31;1mGraceful restart failed, keep running current process: %v\x1b[0m", err)
+		return false
 	}
+	log.Printf("\x1b[32;1mNew process (pid %d) ready, draining current process\x1b[0m", pid)
+	return true
 }
 
 // Shutdown for manual trigger for shutdown
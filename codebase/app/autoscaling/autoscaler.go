@@ -0,0 +1,236 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/logger"
+)
+
+type (
+	// Limiter concurrency limiter with adjustable limit
+	Limiter struct {
+		mu      sync.Mutex
+		limit   int
+		running int
+		release chan struct{}
+	}
+
+	// AutoscalerOptionFunc type
+	AutoscalerOptionFunc func(*Autoscaler)
+
+	// Autoscaler in-process autoscaler, adjust concurrency limit of handler between min and max based on backlog
+	// of handler route from source, limit is ceil(backlog / target backlog per goroutine). Implement
+	// factory.AppServerFactory, so it can be added to application server list
+	Autoscaler struct {
+		source         Source
+		minLimit       int
+		maxLimit       int
+		targetBacklog  int64
+		interval       time.Duration
+		scaleDownDelay time.Duration
+
+		mu       sync.Mutex
+		limiters map[string]*Limiter
+		scaledUp map[string]time.Time
+		ctx      context.Context
+		cancel   context.CancelFunc
+	}
+)
+
+// NewLimiter constructor
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{limit: max(limit, 1), release: make(chan struct{})}
+}
+
+// Acquire wait until running count below limit
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.running < l.limit {
+			l.running++
+			l.mu.Unlock()
+			return nil
+		}
+		release := l.release
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-release:
+		}
+	}
+}
+
+// Release running slot
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	l.running = max(l.running-1, 0)
+	l.notify()
+	l.mu.Unlock()
+}
+
+// SetLimit change limit, waiting acquirer is woken up when limit increased
+func (l *Limiter) SetLimit(limit int) {
+	l.mu.Lock()
+	l.limit = max(limit, 1)
+	l.notify()
+	l.mu.Unlock()
+}
+
+// Limit current limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *Limiter) notify() {
+	close(l.release)
+	l.release = make(chan struct{})
+}
+
+// AutoscalerSetLimit option func, min and max concurrency per handler route (default 1 and 10),
+// max should not exceed max goroutines of worker
+func AutoscalerSetLimit(minLimit, maxLimit int) AutoscalerOptionFunc {
+	return func(a *Autoscaler) {
+		a.minLimit, a.maxLimit = minLimit, maxLimit
+	}
+}
+
+// AutoscalerSetTargetBacklog option func, target backlog per goroutine (default 10)
+func AutoscalerSetTargetBacklog(target int64) AutoscalerOptionFunc {
+	return func(a *Autoscaler) {
+		a.targetBacklog = target
+	}
+}
+
+// AutoscalerSetInterval option func, interval of backlog check (default 10 seconds)
+func AutoscalerSetInterval(interval time.Duration) AutoscalerOptionFunc {
+	return func(a *Autoscaler) {
+		a.interval = interval
+	}
+}
+
+// AutoscalerSetScaleDownDelay option func, minimum duration after last scale up before scale down (default 1 minute)
+func AutoscalerSetScaleDownDelay(delay time.Duration) AutoscalerOptionFunc {
+	return func(a *Autoscaler) {
+		a.scaleDownDelay = delay
+	}
+}
+
+// NewAutoscaler constructor, source item name must be handler route (example NewTaskQueueSource or NewKafkaLagSource)
+func NewAutoscaler(source Source, opts ...AutoscalerOptionFunc) *Autoscaler {
+	a := &Autoscaler{
+		source:         source,
+		minLimit:       1,
+		maxLimit:       10,
+		targetBacklog:  10,
+		interval:       10 * time.Second,
+		scaleDownDelay: time.Minute,
+		limiters:       make(map[string]*Limiter),
+		scaledUp:       make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.minLimit = max(a.minLimit, 1)
+	a.maxLimit = max(a.maxLimit, a.minLimit)
+	a.targetBacklog = max(a.targetBacklog, 1)
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	return a
+}
+
+// Limiter get concurrency limiter of handler route, start from min limit
+func (a *Autoscaler) Limiter(name string) *Limiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	limiter, ok := a.limiters[name]
+	if !ok {
+		limiter = NewLimiter(a.minLimit)
+		a.limiters[name] = limiter
+	}
+	return limiter
+}
+
+// Wrap limit concurrency of handler with limiter of handler route
+func (a *Autoscaler) Wrap(handlerFunc types.WorkerHandlerFunc) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		limiter := a.Limiter(eventContext.HandlerRoute())
+		if err := limiter.Acquire(eventContext.Context()); err != nil {
+			return err
+		}
+		defer limiter.Release()
+		return handlerFunc(eventContext)
+	}
+}
+
+// Apply limit concurrency of main handler of all handler in group, call after all handler added in MountHandlers
+func (a *Autoscaler) Apply(group *types.WorkerHandlerGroup) {
+	for i := range group.Handlers {
+		a.Limiter(group.Handlers[i].Pattern)
+		if len(group.Handlers[i].HandlerFuncs) > 0 {
+			group.Handlers[i].HandlerFuncs[0] = a.Wrap(group.Handlers[i].HandlerFuncs[0])
+		}
+	}
+}
+
+// Evaluate adjust limit of all handler route from current backlog
+func (a *Autoscaler) Evaluate(ctx context.Context) error {
+	backlog, err := a.source.Backlog(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for name, limiter := range a.limiters {
+		desired := int((backlog[name] + a.targetBacklog - 1) / a.targetBacklog)
+		desired = min(max(desired, a.minLimit), a.maxLimit)
+		current := limiter.Limit()
+		switch {
+		case desired > current:
+			a.scaledUp[name] = now
+		case desired < current && now.Sub(a.scaledUp[name]) >= a.scaleDownDelay:
+		default:
+			continue
+		}
+		limiter.SetLimit(desired)
+		logger.LogI(fmt.Sprintf("autoscaling: %s concurrency %d -> %d (backlog %d)", name, current, desired, backlog[name]))
+	}
+	return nil
+}
+
+// Serve run autoscaler until shutdown
+func (a *Autoscaler) Serve() {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(a.ctx, a.interval)
+			if err := a.Evaluate(ctx); err != nil {
+				logger.LogE("autoscaling: " + err.Error())
+			}
+			cancel()
+		}
+	}
+}
+
+// Shutdown stop autoscaler
+func (a *Autoscaler) Shutdown(ctx context.Context) {
+	a.cancel()
+}
+
+// Name of autoscaler
+func (a *Autoscaler) Name() string {
+	return "autoscaler:" + a.source.Name()
+}
@@ -0,0 +1,197 @@
+// Package autoscaling export backlog signal (task queue backlog, kafka consumer lag, rabbitmq queue depth) and handler
+// activity (in-flight count, processing latency) for external scaler (KEDA metrics-api scaler, prometheus adapter, etc),
+// so worker replicas can autoscale on real backlog. Autoscaler adjust handler concurrency in process based on backlog.
+package autoscaling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/logger"
+)
+
+type (
+	// Source backlog source
+	Source interface {
+		// Name of source, example: "task_queue", "kafka:consumer-group"
+		Name() string
+		// Backlog get backlog count per item (task name, topic, queue name)
+		Backlog(ctx context.Context) (map[string]int64, error)
+	}
+
+	// Signal autoscaling signal from all source
+	Signal struct {
+		Total     int64                   `json:"total"`
+		Sources   map[string]SourceSignal `json:"sources"`
+		Activity  map[string]Activity     `json:"activity,omitempty"`
+		Timestamp time.Time               `json:"timestamp"`
+	}
+
+	// SourceSignal backlog signal from source
+	SourceSignal struct {
+		Total int64            `json:"total"`
+		Items map[string]int64 `json:"items,omitempty"`
+		Error string           `json:"error,omitempty"`
+	}
+
+	// ExporterOptionFunc type
+	ExporterOptionFunc func(*Exporter)
+
+	// Exporter collect backlog from all source and expose as http handler
+	Exporter struct {
+		sources  []Source
+		tracker  *Tracker
+		timeout  time.Duration
+		cacheTTL time.Duration
+
+		mu         sync.Mutex
+		lastSignal *Signal
+	}
+)
+
+// ExporterSetTimeout option func, timeout for collect backlog from all source (default 5 seconds)
+func ExporterSetTimeout(timeout time.Duration) ExporterOptionFunc {
+	return func(e *Exporter) {
+		e.timeout = timeout
+	}
+}
+
+// ExporterSetCacheTTL option func, cache signal for reduce load to broker/persistent when scaler polling frequently
+func ExporterSetCacheTTL(ttl time.Duration) ExporterOptionFunc {
+	return func(e *Exporter) {
+		e.cacheTTL = ttl
+	}
+}
+
+// ExporterSetTracker option func, export in-flight count and processing latency per handler route from tracker
+func ExporterSetTracker(tracker *Tracker) ExporterOptionFunc {
+	return func(e *Exporter) {
+		e.tracker = tracker
+	}
+}
+
+// NewExporter constructor
+func NewExporter(sources []Source, opts ...ExporterOptionFunc) *Exporter {
+	e := &Exporter{
+		sources: sources,
+		timeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Collect backlog signal from all source concurrently
+func (e *Exporter) Collect(ctx context.Context) Signal {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lastSignal != nil && time.Since(e.lastSignal.Timestamp) < e.cacheTTL {
+		return *e.lastSignal
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	signal := Signal{Sources: make(map[string]SourceSignal, len(e.sources)), Timestamp: time.Now()}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, source := range e.sources {
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+
+			var sourceSignal SourceSignal
+			items, err := source.Backlog(ctx)
+			if err != nil {
+				sourceSignal.Error = err.Error()
+				logger.LogE(fmt.Sprintf("autoscaling: source %s: %s", source.Name(), err.Error()))
+			}
+			sourceSignal.Items = items
+			for _, count := range items {
+				sourceSignal.Total += count
+			}
+
+			mu.Lock()
+			signal.Sources[source.Name()] = sourceSignal
+			signal.Total += sourceSignal.Total
+			mu.Unlock()
+		}(source)
+	}
+	wg.Wait()
+	if e.tracker != nil {
+		signal.Activity = e.tracker.Snapshot()
+	}
+
+	e.lastSignal = &signal
+	return signal
+}
+
+// ServeHTTP expose backlog signal, compatible with KEDA metrics-api scaler (valueLocation: "total" or
+// "sources.<source name>.total"). Query param "source" for filter source, "format=prometheus" for prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	signal := e.Collect(req.Context())
+	if source := req.URL.Query().Get("source"); source != "" {
+		selected, ok := signal.Sources[source]
+		if !ok {
+			http.Error(w, fmt.Sprintf("source '%s' not found", source), http.StatusNotFound)
+			return
+		}
+		signal.Sources = map[string]SourceSignal{source: selected}
+		signal.Total = selected.Total
+	}
+
+	if req.URL.Query().Get("format") == "prometheus" {
+		w.Header().Set(candihelper.HeaderContentType, "text/plain; version=0.0.4")
+		w.Write([]byte(signal.Prometheus()))
+		return
+	}
+
+	w.Header().Set(candihelper.HeaderContentType, candihelper.HeaderMIMEApplicationJSON)
+	json.NewEncoder(w).Encode(signal)
+}
+
+// Prometheus format signal to prometheus text exposition format
+func (s Signal) Prometheus() string {
+	var b strings.Builder
+	b.WriteString("# HELP candi_autoscaling_backlog Backlog count for autoscaling worker.\n")
+	b.WriteString("# TYPE candi_autoscaling_backlog gauge\n")
+
+	sources := candihelper.ToKeyMapSlice(s.Sources)
+	sort.Strings(sources)
+	for _, source := range sources {
+		items := candihelper.ToKeyMapSlice(s.Sources[source].Items)
+		sort.Strings(items)
+		for _, item := range items {
+			fmt.Fprintf(&b, "candi_autoscaling_backlog{source=%q,name=%q} %d\n", source, item, s.Sources[source].Items[item])
+		}
+	}
+	fmt.Fprintf(&b, "# HELP candi_autoscaling_backlog_total Total backlog count from all source.\n")
+	fmt.Fprintf(&b, "# TYPE candi_autoscaling_backlog_total gauge\n")
+	fmt.Fprintf(&b, "candi_autoscaling_backlog_total %d\n", s.Total)
+
+	if len(s.Activity) == 0 {
+		return b.String()
+	}
+	names := candihelper.ToKeyMapSlice(s.Activity)
+	sort.Strings(names)
+	b.WriteString("# HELP candi_autoscaling_inflight Number of message being processed by handler.\n")
+	b.WriteString("# TYPE candi_autoscaling_inflight gauge\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "candi_autoscaling_inflight{name=%q} %d\n", name, s.Activity[name].InFlight)
+	}
+	b.WriteString("# HELP candi_autoscaling_latency_seconds Moving average of handler processing latency.\n")
+	b.WriteString("# TYPE candi_autoscaling_latency_seconds gauge\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "candi_autoscaling_latency_seconds{name=%q} %g\n", name, s.Activity[name].LatencyMs/1000)
+	}
+	return b.String()
+}
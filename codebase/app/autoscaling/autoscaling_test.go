@@ -0,0 +1,136 @@
+package autoscaling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter(t *testing.T) {
+	exporter := NewExporter([]Source{
+		SourceFunc("task_queue", func(ctx context.Context) (map[string]int64, error) {
+			return map[string]int64{"send-email": 10, "generate-report": 5}, nil
+		}),
+		SourceFunc("kafka:group", func(ctx context.Context) (map[string]int64, error) {
+			return nil, errors.New("broker down")
+		}),
+	})
+
+	signal := exporter.Collect(context.Background())
+	assert.Equal(t, int64(15), signal.Total)
+	assert.Equal(t, "broker down", signal.Sources["kafka:group"].Error)
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/autoscaling?source=task_queue", nil))
+	var resp Signal
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, int64(15), resp.Total)
+	assert.Len(t, resp.Sources, 1)
+
+	rec = httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/autoscaling?format=prometheus", nil))
+	assert.True(t, strings.Contains(rec.Body.String(), `candi_autoscaling_backlog{source="task_queue",name="send-email"} 10`))
+	assert.True(t, strings.Contains(rec.Body.String(), "candi_autoscaling_backlog_total 15"))
+
+	rec = httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/autoscaling?source=unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	var group types.WorkerHandlerGroup
+	group.Add("send-email", func(eventContext *candishared.EventContext) error {
+		assert.Equal(t, int64(1), tracker.Snapshot()["send-email"].InFlight)
+		return nil
+	})
+	tracker.Apply(&group)
+
+	eventContext := candishared.NewEventContext(&bytes.Buffer{})
+	eventContext.SetHandlerRoute("send-email")
+	assert.NoError(t, group.Handlers[0].HandlerFuncs[0](eventContext))
+	activity := tracker.Snapshot()["send-email"]
+	assert.Equal(t, int64(0), activity.InFlight)
+	assert.Equal(t, int64(1), activity.Processed)
+
+	exporter := NewExporter(nil, ExporterSetTracker(tracker))
+	assert.Contains(t, exporter.Collect(context.Background()).Prometheus(), `candi_autoscaling_inflight{name="send-email"} 0`)
+}
+
+func TestAutoscaler(t *testing.T) {
+	backlog := map[string]int64{"orders": 35}
+	autoscaler := NewAutoscaler(SourceFunc("kafka", func(ctx context.Context) (map[string]int64, error) {
+		return backlog, nil
+	}), AutoscalerSetLimit(1, 3), AutoscalerSetTargetBacklog(10), AutoscalerSetScaleDownDelay(time.Hour))
+
+	var group types.WorkerHandlerGroup
+	group.Add("orders", func(eventContext *candishared.EventContext) error { return nil })
+	autoscaler.Apply(&group)
+	limiter := autoscaler.Limiter("orders")
+	assert.Equal(t, 1, limiter.Limit())
+
+	assert.NoError(t, autoscaler.Evaluate(context.Background()))
+	assert.Equal(t, 3, limiter.Limit())
+
+	// scale down is delayed
+	backlog["orders"] = 0
+	assert.NoError(t, autoscaler.Evaluate(context.Background()))
+	assert.Equal(t, 3, limiter.Limit())
+	autoscaler.scaledUp["orders"] = time.Now().Add(-time.Hour)
+	assert.NoError(t, autoscaler.Evaluate(context.Background()))
+	assert.Equal(t, 1, limiter.Limit())
+
+	// acquire block until limit increased
+	assert.NoError(t, limiter.Acquire(context.Background()))
+	acquired := make(chan struct{})
+	go func() {
+		limiter.Acquire(context.Background())
+		close(acquired)
+	}()
+	limiter.SetLimit(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire is not woken up")
+	}
+}
+
+func TestKafkaLagSource(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()).SetLeader("orders", 1, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders", 0, sarama.OffsetNewest, 150).SetOffset("orders", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders", 1, sarama.OffsetNewest, 500).SetOffset("orders", 1, sarama.OffsetOldest, 420),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).SetCoordinator(sarama.CoordinatorGroup, "order-consumer", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("order-consumer", "orders", 0, 100, "", sarama.ErrNoError).
+			SetOffset("order-consumer", "orders", 1, -1, "", sarama.ErrNoError),
+	})
+
+	for initial, expected := range map[int64]int64{sarama.OffsetOldest: 50 + 80, sarama.OffsetNewest: 50} {
+		cfg := sarama.NewConfig()
+		cfg.Consumer.Offsets.Initial = initial
+		client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+		require.NoError(t, err)
+
+		backlog, err := NewKafkaLagSource(client, "order-consumer", "orders").Backlog(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"orders": expected}, backlog, "lag of partition without committed offset follow initial offset")
+		client.Close()
+	}
+}
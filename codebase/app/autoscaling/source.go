@@ -0,0 +1,127 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/IBM/sarama"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// SourceFunc create source from func
+func SourceFunc(name string, backlog func(ctx context.Context) (map[string]int64, error)) Source {
+	return &sourceFunc{name: name, backlog: backlog}
+}
+
+type sourceFunc struct {
+	name    string
+	backlog func(ctx context.Context) (map[string]int64, error)
+}
+
+func (s *sourceFunc) Name() string { return s.name }
+func (s *sourceFunc) Backlog(ctx context.Context) (map[string]int64, error) {
+	return s.backlog(ctx)
+}
+
+// NewTaskQueueSource backlog from task queue worker (queueing + retrying job per task),
+// must be used in the same runtime with task queue worker
+func NewTaskQueueSource(taskNames ...string) Source {
+	return SourceFunc("task_queue", func(ctx context.Context) (map[string]int64, error) {
+		persistent := taskqueueworker.GetPersistent()
+		if persistent == nil {
+			return nil, errors.New("task queue worker is not running")
+		}
+
+		backlog := make(map[string]int64)
+		for _, summary := range persistent.Summary().FindAllSummary(ctx, &taskqueueworker.Filter{}) {
+			if len(taskNames) > 0 && !slices.Contains(taskNames, summary.TaskName) {
+				continue
+			}
+			backlog[summary.TaskName] = int64(max(summary.Queueing, 0) + max(summary.Retrying, 0))
+		}
+		return backlog, nil
+	})
+}
+
+// NewKafkaLagSource backlog from kafka consumer group lag (high water mark - committed offset) per topic,
+// lag of partition without committed offset follow client config Consumer.Offsets.Initial (same as consumer group)
+func NewKafkaLagSource(client sarama.Client, consumerGroup string, topics ...string) Source {
+	return SourceFunc("kafka:"+consumerGroup, func(ctx context.Context) (map[string]int64, error) {
+		coordinator, err := client.Coordinator(consumerGroup)
+		if err != nil {
+			return nil, err
+		}
+
+		request := &sarama.OffsetFetchRequest{ConsumerGroup: consumerGroup, Version: 1}
+		highWaterMarks := make(map[string]map[int32]int64, len(topics))
+		for _, topic := range topics {
+			partitions, err := client.Partitions(topic)
+			if err != nil {
+				return nil, fmt.Errorf("topic %s: %w", topic, err)
+			}
+			highWaterMarks[topic] = make(map[int32]int64, len(partitions))
+			for _, partition := range partitions {
+				hwm, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+				if err != nil {
+					return nil, fmt.Errorf("topic %s partition %d: %w", topic, partition, err)
+				}
+				highWaterMarks[topic][partition] = hwm
+				request.AddPartition(topic, partition)
+			}
+		}
+
+		response, err := coordinator.FetchOffset(request)
+		if err != nil {
+			return nil, err
+		}
+
+		lag := make(map[string]int64, len(topics))
+		for topic, partitions := range highWaterMarks {
+			for partition, hwm := range partitions {
+				committed := int64(-1)
+				if block := response.GetBlock(topic, partition); block != nil && block.Err == sarama.ErrNoError {
+					committed = block.Offset
+				}
+				if committed < 0 {
+					// no committed offset, consumer start from initial offset: no backlog if newest,
+					// all retained message (not truncated by retention) if oldest
+					if client.Config().Consumer.Offsets.Initial == sarama.OffsetNewest {
+						continue
+					}
+					oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+					if err != nil {
+						return nil, fmt.Errorf("topic %s partition %d: %w", topic, partition, err)
+					}
+					lag[topic] += max(hwm-oldest, 0)
+					continue
+				}
+				lag[topic] += max(hwm-committed, 0)
+			}
+		}
+		return lag, nil
+	})
+}
+
+// NewRabbitMQSource backlog from rabbitmq queue depth (ready messages)
+func NewRabbitMQSource(conn *amqp.Connection, queues ...string) Source {
+	return SourceFunc("rabbitmq", func(ctx context.Context) (map[string]int64, error) {
+		depth := make(map[string]int64, len(queues))
+		for _, queue := range queues {
+			// use new channel for each queue, passive declare close the channel if queue not found
+			ch, err := conn.Channel()
+			if err != nil {
+				return depth, err
+			}
+			q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+			ch.Close()
+			if err != nil {
+				return depth, fmt.Errorf("queue %s: %w", queue, err)
+			}
+			depth[queue] = int64(q.Messages)
+		}
+		return depth, nil
+	})
+}
@@ -0,0 +1,87 @@
+package autoscaling
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+)
+
+// weight of latest handler latency in moving average
+const latencyWeight = 0.2
+
+type (
+	// Tracker track in-flight count and processing latency per handler route (task name, topic, queue name)
+	Tracker struct {
+		mu    sync.Mutex
+		items map[string]*Activity
+	}
+
+	// Activity handler activity of handler route
+	Activity struct {
+		InFlight int64 `json:"in_flight"`
+		// LatencyMs moving average of processing latency in millisecond
+		LatencyMs float64 `json:"latency_ms"`
+		Processed int64   `json:"processed"`
+	}
+)
+
+// NewTracker constructor
+func NewTracker() *Tracker {
+	return &Tracker{items: make(map[string]*Activity)}
+}
+
+// Begin mark handler start processing, call returned func when done
+func (t *Tracker) Begin(name string) (done func()) {
+	startAt := time.Now()
+	t.mu.Lock()
+	activity, ok := t.items[name]
+	if !ok {
+		activity = &Activity{}
+		t.items[name] = activity
+	}
+	activity.InFlight++
+	t.mu.Unlock()
+
+	return func() {
+		latency := float64(time.Since(startAt)) / float64(time.Millisecond)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		activity.InFlight--
+		activity.Processed++
+		if activity.Processed == 1 {
+			activity.LatencyMs = latency
+		} else {
+			activity.LatencyMs = latencyWeight*latency + (1-latencyWeight)*activity.LatencyMs
+		}
+	}
+}
+
+// Wrap track handler with name of handler route
+func (t *Tracker) Wrap(handlerFunc types.WorkerHandlerFunc) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		defer t.Begin(eventContext.HandlerRoute())()
+		return handlerFunc(eventContext)
+	}
+}
+
+// Apply track main handler of all handler in group, call after all handler added in MountHandlers
+func (t *Tracker) Apply(group *types.WorkerHandlerGroup) {
+	for i := range group.Handlers {
+		if len(group.Handlers[i].HandlerFuncs) > 0 {
+			group.Handlers[i].HandlerFuncs[0] = t.Wrap(group.Handlers[i].HandlerFuncs[0])
+		}
+	}
+}
+
+// Snapshot activity per handler route
+func (t *Tracker) Snapshot() map[string]Activity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := make(map[string]Activity, len(t.items))
+	for name, activity := range t.items {
+		snapshot[name] = *activity
+	}
+	return snapshot
+}
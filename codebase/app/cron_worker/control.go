@@ -0,0 +1,155 @@
+package cronworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golangid/candi/control"
+	"github.com/golangid/candi/logger"
+)
+
+// JobState state of cron job, result of "cron.jobs" control command
+type JobState struct {
+	HandlerName string    `json:"handler_name"`
+	Interval    string    `json:"interval"`
+	Params      string    `json:"params,omitempty"`
+	NextRunAt   time.Time `json:"next_run_at,omitempty"`
+	Running     int       `json:"running"`
+	Paused      bool      `json:"paused"`
+	Disabled    bool      `json:"disabled"`
+}
+
+// execInLoop run fn in serve loop goroutine (job state is only accessed by serve loop), wait until fn is done
+func (c *cronWorker) execInLoop(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	c.scheduleMu.Lock()
+	c.controlFuncs = append(c.controlFuncs, func() { fn(); close(done) })
+	c.scheduleMu.Unlock()
+	c.refreshWorker()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("cron worker is not running: " + ctx.Err().Error())
+	}
+}
+
+// runControlFuncs must be called in serve loop goroutine
+func (c *cronWorker) runControlFuncs() {
+	c.scheduleMu.Lock()
+	funcs := c.controlFuncs
+	c.controlFuncs = nil
+	c.scheduleMu.Unlock()
+	for _, fn := range funcs {
+		fn()
+	}
+}
+
+func (c *cronWorker) jobStates() []JobState {
+	states := make([]JobState, 0, len(c.activeJobs))
+	for _, job := range c.activeJobs {
+		state := JobState{
+			HandlerName: job.HandlerName, Interval: job.Interval, Params: job.Params,
+			Running: len(c.semaphore[job.WorkerIndex-2]), Paused: job.paused, Disabled: job.disabled && !job.paused,
+		}
+		if !job.disabled {
+			state.NextRunAt = job.nextRunAt
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// findJobs active job with handler name, error if not found
+func (c *cronWorker) findJobs(handlerName string) (jobs []*Job, err error) {
+	for _, job := range c.activeJobs {
+		if job.HandlerName == handlerName {
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("%w: job %s is not found", control.ErrInvalidArgument, handlerName)
+	}
+	return jobs, nil
+}
+
+// pauseJob stop ticker of running schedule, paused job is skipped by select until resumed
+func (c *cronWorker) pauseJob(handlerName string) error {
+	jobs, err := c.findJobs(handlerName)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if !job.disabled {
+			c.disableJob(job)
+			job.paused = true
+		}
+	}
+	c.register()
+	return nil
+}
+
+// resumeJob restart schedule of paused job from now
+func (c *cronWorker) resumeJob(handlerName string) error {
+	jobs, err := c.findJobs(handlerName)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if !job.paused {
+			continue
+		}
+		if err := job.initSchedule(c.now()); err != nil {
+			return err
+		}
+		job.disabled, job.paused = false, false
+		c.workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
+	}
+	c.register()
+	return nil
+}
+
+// runJob run job immediately, next schedule is not changed
+func (c *cronWorker) runJob(handlerName string) error {
+	jobs, err := c.findJobs(handlerName)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if !c.dispatch(job, c.now()) {
+			return fmt.Errorf("job %s reach max goroutines", handlerName)
+		}
+	}
+	return nil
+}
+
+func (c *cronWorker) controlCommands() []control.Command {
+	jobCommand := func(action string, fn func(handlerName string) error) func(context.Context, map[string]string) (any, error) {
+		return func(ctx context.Context, args map[string]string) (any, error) {
+			var err error
+			if loopErr := c.execInLoop(ctx, func() { err = fn(args["job"]) }); loopErr != nil {
+				return nil, loopErr
+			}
+			if err != nil {
+				return nil, err
+			}
+			logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (job name): "%s" %s by control command`, args["job"], action))
+			return map[string]string{"job": args["job"], "action": action}, nil
+		}
+	}
+
+	return []control.Command{
+		{Name: "cron.jobs", Description: "List cron jobs with next run time and state",
+			Handler: func(ctx context.Context, args map[string]string) (result any, err error) {
+				err = c.execInLoop(ctx, func() { result = c.jobStates() })
+				return result, err
+			}},
+		{Name: "cron.run", Description: "Run cron job immediately", Args: []string{"job"}, Handler: jobCommand("executed", c.runJob)},
+		{Name: "cron.pause", Description: "Pause schedule of cron job", Args: []string{"job"}, Handler: jobCommand("paused", c.pauseJob)},
+		{Name: "cron.resume", Description: "Resume schedule of paused cron job", Args: []string{"job"}, Handler: jobCommand("resumed", c.resumeJob)},
+	}
+}
@@ -15,9 +15,10 @@ import (
 
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
-	cronexpr "github.com/golangid/candi/candiutils/cronparser"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/control"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
 )
@@ -33,6 +34,13 @@ type cronWorker struct {
 	semaphore                    []chan struct{}
 	wg                           sync.WaitGroup
 	activeJobs                   []*Job
+	metrics                      metrics
+	instance                     string
+
+	handlers         map[string]types.WorkerHandler
+	scheduleMu       sync.Mutex
+	pendingSchedules *[]ScheduleDefinition
+	controlFuncs     []func()
 }
 
 // NewWorker create new cron worker
@@ -43,6 +51,8 @@ func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 
 		refreshWorkerNotif: make(chan struct{}),
 		shutdown:           make(chan struct{}),
+		instance:           registryInstance(),
+		handlers:           make(map[string]types.WorkerHandler),
 	}
 
 	for _, opt := range opts {
@@ -58,13 +68,20 @@ func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 		Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.refreshWorkerNotif),
 	})
 
+	c.metrics = newMetrics(c.opt.meterProvider)
 	c.opt.locker.Reset(fmt.Sprintf(lockPattern, c.service.Name(), "*"))
 	for _, m := range service.GetModules() {
 		if h := m.WorkerHandler(types.Scheduler); h != nil {
 			var handlerGroup types.WorkerHandlerGroup
 			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(types.Scheduler, &handlerGroup)
 			for _, handler := range handlerGroup.Handlers {
 				funcName, args, interval := ParseCronJobKey(handler.Pattern)
+				c.handlers[funcName] = handler
+				if interval == "" && c.opt.scheduleProvider != nil {
+					logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (job name): %s (every): <schedule provider>  --> (module): "%s"`, `"`+funcName+`"`, m.Name()))
+					continue
+				}
 
 				var job Job
 				job.HandlerName = funcName
@@ -80,6 +97,18 @@ func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 			}
 		}
 	}
+	if c.opt.scheduleProvider != nil {
+		if c.opt.scheduleRefreshInterval <= 0 {
+			c.opt.scheduleRefreshInterval = time.Minute
+		}
+		if schedules, err := c.opt.scheduleProvider.Schedules(context.Background()); err != nil {
+			logger.LogRed("cron_worker > load schedules from provider: " + err.Error())
+		} else {
+			c.applySchedules(schedules)
+		}
+	}
+	c.register()
+	control.Register(c.controlCommands()...)
 	fmt.Printf("\x1b[34;1m⇨ Cron worker running with %d jobs\x1b[0m\n\n", len(c.activeJobs))
 
 	c.ctx, c.ctxCancelFunc = context.WithCancel(context.Background())
@@ -89,7 +118,12 @@ func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 func (c *cronWorker) Serve() {
 
 	for _, job := range c.activeJobs {
-		c.workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
+		if !job.disabled {
+			c.workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
+		}
+	}
+	if c.opt.scheduleProvider != nil {
+		go c.watchSchedules()
 	}
 
 	// run worker
@@ -106,33 +140,42 @@ func (c *cronWorker) Serve() {
 
 		// notify for refresh worker
 		if chosen == 1 {
+			c.applyPendingSchedules()
+			c.runControlFuncs()
 			continue
 		}
 
 		chosen = chosen - 2
 		job := c.activeJobs[chosen]
+		scheduledAt := job.fired(c.now())
 		c.registerNextInterval(job)
+		c.dispatch(job, scheduledAt)
+	}
 
-		if len(c.semaphore[job.WorkerIndex-2]) >= c.opt.maxGoroutines {
-			continue
-		}
-
-		c.semaphore[job.WorkerIndex-2] <- struct{}{}
-		c.wg.Add(1)
-		go func(j *Job) {
-			defer func() {
-				c.wg.Done()
-				<-c.semaphore[j.WorkerIndex-2]
-			}()
-			if c.ctx.Err() != nil {
-				logger.LogRed("cron_scheduler > ctx root err: " + c.ctx.Err().Error())
-				return
-			}
+}
 
-			c.processJob(j)
-		}(job)
+// dispatch run job in new goroutine, return false when running job reach max goroutines
+func (c *cronWorker) dispatch(job *Job, scheduledAt time.Time) bool {
+	if len(c.semaphore[job.WorkerIndex-2]) >= c.opt.maxGoroutines {
+		c.metrics.recordSkipped(c.ctx, job, "saturated")
+		return false
 	}
 
+	c.semaphore[job.WorkerIndex-2] <- struct{}{}
+	c.wg.Add(1)
+	go func(j *Job) {
+		defer func() {
+			c.wg.Done()
+			<-c.semaphore[j.WorkerIndex-2]
+		}()
+		if c.ctx.Err() != nil {
+			logger.LogRed("cron_scheduler > ctx root err: " + c.ctx.Err().Error())
+			return
+		}
+
+		c.processJob(j, scheduledAt)
+	}(job)
+	return true
 }
 
 func (c *cronWorker) Shutdown(ctx context.Context) {
@@ -141,7 +184,7 @@ func (c *cronWorker) Shutdown(ctx context.Context) {
 			time.Now().Format(candihelper.TimeFormatLogger), strings.Repeat(" ", 20))
 	}()
 
-	if len(c.activeJobs) == 0 {
+	if len(c.activeJobs) == 0 && c.opt.scheduleProvider == nil {
 		return
 	}
 
@@ -166,7 +209,7 @@ func (c *cronWorker) Name() string {
 	return string(types.Scheduler)
 }
 
-func (c *cronWorker) processJob(job *Job) {
+func (c *cronWorker) processJob(job *Job, scheduledAt time.Time) {
 	ctx := c.ctx
 	if job.Handler.DisableTrace {
 		ctx = tracer.SkipTraceContext(ctx)
@@ -175,26 +218,52 @@ func (c *cronWorker) processJob(job *Job) {
 	// lock for multiple worker (if running on multiple pods/instance)
 	if c.opt.locker.IsLocked(c.getLockKey(job.HandlerName)) {
 		logger.LogYellow("cron_worker > job " + job.HandlerName + " is locked")
+		c.metrics.recordSkipped(ctx, job, "locked")
 		return
 	}
 	defer c.opt.locker.Unlock(c.getLockKey(job.HandlerName))
 
+	// fleet wide lock for conflicting job in another services
+	if conflictKey, ttl := getConflictKey(job.Handler); c.opt.registry != nil && conflictKey != "" {
+		holder, acquired, err := c.opt.registry.AcquireConflict(ctx, conflictKey, c.conflictOwner(job), ttl)
+		if err != nil || !acquired {
+			logger.LogYellow(fmt.Sprintf("cron_worker > job %s conflict with %s (key: %s, err: %v)", job.HandlerName, holder, conflictKey, err))
+			c.metrics.recordSkipped(ctx, job, "conflict")
+			now := time.Now()
+			c.recordRun(job, RegistryRun{Status: RunStatusConflict, Error: "conflict key held by " + holder, StartAt: now, EndAt: now})
+			return
+		}
+		defer c.opt.registry.ReleaseConflict(context.Background(), conflictKey, c.conflictOwner(job))
+	}
+
 	var err error
+	startAt := time.Now()
 	trace, ctx := tracer.StartTraceFromHeader(ctx, "CronScheduler", make(map[string]string, 0))
 	defer func() {
 		if r := recover(); r != nil {
 			trace.SetTag("panic", true)
-			err = fmt.Errorf("%v", r)
+			err = candiutils.ReportPanic(ctx, "CronScheduler", r, map[string]any{
+				"job_name": job.HandlerName, "interval": job.Interval, "params": job.Params,
+			})
 		}
 		trace.Finish(tracer.FinishWithError(err))
+		run := RegistryRun{Status: RunStatusSuccess, StartAt: startAt, EndAt: time.Now()}
+		if err != nil {
+			run.Status, run.Error = RunStatusFailure, err.Error()
+		}
+		c.recordRun(job, run)
 	}()
 
 	trace.SetTag("cron_expr", job.Interval)
 	trace.SetTag("job_name", job.HandlerName)
 	trace.Log("job_param", job.Params)
 
+	drift := c.now().Sub(scheduledAt)
+	c.metrics.recordDrift(ctx, job, drift)
+	trace.SetTag("schedule_drift", drift.String())
+
 	if c.opt.debugMode {
-		log.Printf("\x1b[35;3mCron Scheduler: executing task '%s' (interval: %s)\x1b[0m", job.HandlerName, job.Interval)
+		log.Printf("\x1b[35;3mCron Scheduler: executing task '%s' (interval: %s, drift: %s)\x1b[0m", job.HandlerName, job.Interval, drift)
 	}
 
 	eventContext := candishared.NewEventContext(bytes.NewBuffer(make([]byte, 256)))
@@ -217,6 +286,41 @@ func (c *cronWorker) getLockKey(handlerName string) string {
 	return fmt.Sprintf("%s:cron-worker-lock:%s", c.service.Name(), handlerName)
 }
 
+// register job schedules to fleet registry
+func (c *cronWorker) register() {
+	if c.opt.registry == nil || len(c.activeJobs) == 0 {
+		return
+	}
+	jobs := make([]RegistryJob, 0, len(c.activeJobs))
+	for _, job := range c.activeJobs {
+		if job.disabled {
+			continue
+		}
+		conflictKey, _ := getConflictKey(job.Handler)
+		jobs = append(jobs, RegistryJob{
+			Service: string(c.service.Name()), HandlerName: job.HandlerName, Interval: job.Interval, ConflictKey: conflictKey,
+			Instance: c.instance, NextRunAt: job.nextRunAt, UpdatedAt: time.Now(),
+		})
+	}
+	if err := c.opt.registry.Register(context.Background(), jobs...); err != nil {
+		logger.LogRed("cron_worker > register to registry: " + err.Error())
+	}
+}
+
+func (c *cronWorker) recordRun(job *Job, run RegistryRun) {
+	if c.opt.registry == nil {
+		return
+	}
+	run.Instance = c.instance
+	if err := c.opt.registry.RecordRun(context.Background(), string(c.service.Name()), job.HandlerName, run, job.nextRunAt); err != nil {
+		logger.LogRed("cron_worker > record run to registry: " + err.Error())
+	}
+}
+
+func (c *cronWorker) conflictOwner(job *Job) string {
+	return registryJobKey(string(c.service.Name()), job.HandlerName) + "@" + c.instance
+}
+
 func (c *cronWorker) refreshWorker() {
 	go func() { c.refreshWorkerNotif <- struct{}{} }()
 }
@@ -230,26 +334,15 @@ func (c *cronWorker) stopAllJob() {
 func (c *cronWorker) registerNextInterval(j *Job) {
 	if j.schedule != nil {
 		j.ticker.Stop()
-		// Calculate the next execution time based on the cron schedule
-		nextTime := j.schedule.Next(time.Now())
-		if !nextTime.IsZero() {
-			duration := time.Until(nextTime)
-			// Ensure minimum duration to prevent immediate re-execution
-			if duration < time.Second {
-				// If next time is too soon, calculate the one after that
-				nextTime = j.schedule.Next(nextTime.Add(time.Second))
-				duration = time.Until(nextTime)
-			}
-			j.ticker = time.NewTicker(duration)
-		} else {
-			// Fallback to original behavior if Next() returns zero time
-			j.ticker = time.NewTicker(j.schedule.NextInterval(time.Now()))
-		}
+		var duration time.Duration
+		j.nextRunAt, duration = calculateNextTime(j.schedule, c.now())
+		j.ticker = time.NewTicker(duration)
 		c.workers[j.WorkerIndex].Chan = reflect.ValueOf(j.ticker.C)
 
 	} else if j.nextDuration != nil {
 		j.ticker.Stop()
 		j.ticker = time.NewTicker(*j.nextDuration)
+		j.period, j.nextRunAt = *j.nextDuration, c.now().Add(*j.nextDuration)
 		c.workers[j.WorkerIndex].Chan = reflect.ValueOf(j.ticker.C)
 		j.nextDuration = nil
 	}
@@ -257,6 +350,14 @@ func (c *cronWorker) registerNextInterval(j *Job) {
 	c.refreshWorker()
 }
 
+// now current time of scheduler clock
+func (c *cronWorker) now() time.Time {
+	if c.opt.clock == nil {
+		return time.Now()
+	}
+	return c.opt.clock.Now()
+}
+
 // addJob to cron worker
 func (c *cronWorker) addJob(job *Job) (err error) {
 	if len(job.Handler.HandlerFuncs) == 0 {
@@ -266,32 +367,9 @@ func (c *cronWorker) addJob(job *Job) (err error) {
 		return errors.New("handler name cannot empty")
 	}
 
-	duration, nextDuration, err := candihelper.ParseDurationExpression(job.Interval)
-	if err != nil {
-		job.schedule, err = cronexpr.Parse(job.Interval)
-		if err != nil {
-			return err
-		}
-		// Calculate the next execution time for cron expressions
-		nextTime := job.schedule.Next(time.Now())
-		if !nextTime.IsZero() {
-			duration = time.Until(nextTime)
-			// Ensure minimum duration to prevent immediate execution
-			if duration < time.Second {
-				nextTime = job.schedule.Next(nextTime.Add(time.Second))
-				duration = time.Until(nextTime)
-			}
-		} else {
-			// Fallback to original behavior
-			duration = job.schedule.NextInterval(time.Now())
-		}
+	if err := job.initSchedule(c.now()); err != nil {
+		return err
 	}
-
-	if nextDuration > 0 {
-		job.nextDuration = &nextDuration
-	}
-
-	job.ticker = time.NewTicker(duration)
 	job.WorkerIndex = len(c.workers)
 
 	c.activeJobs = append(c.activeJobs, job)
@@ -1,11 +1,19 @@
 package cronworker
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	cronexpr "github.com/golangid/candi/candiutils/cronparser"
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -131,21 +139,261 @@ func TestMinimumDurationSafetyCheck(t *testing.T) {
 	// Simulate being very close to the next execution time
 	now := time.Date(2025, 11, 7, 14, 5, 59, 900000000, time.UTC) // 14:05:59.9 (100ms before next minute)
 
-	nextTime := schedule.Next(now)
-	duration := time.Until(nextTime)
+	nextTime, duration := calculateNextTime(schedule, now)
+	assert.Equal(t, time.Date(2025, 11, 7, 14, 7, 0, 0, time.UTC), nextTime,
+		"Next execution less than 1 second from now should be skipped")
+	assert.Equal(t, time.Minute+100*time.Millisecond, duration)
 
-	// If duration is less than 1 second, we should get the next occurrence
-	if duration < time.Second {
-		nextNextTime := schedule.Next(nextTime.Add(time.Second))
-		nextDuration := time.Until(nextNextTime)
+	nextTime, duration = calculateNextTime(schedule, now.Add(-30*time.Second))
+	assert.Equal(t, time.Date(2025, 11, 7, 14, 6, 0, 0, time.UTC), nextTime)
+	assert.Equal(t, 30*time.Second+100*time.Millisecond, duration)
+}
+
+// fakeClock testkit.FakeClock cannot be imported here (import cycle)
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time                  { return c.now }
+func (c *fakeClock) Since(t time.Time) time.Duration { return c.now.Sub(t) }
+func (c *fakeClock) Until(t time.Time) time.Duration { return t.Sub(c.now) }
+func (c *fakeClock) Advance(d time.Duration)         { c.now = c.now.Add(d) }
+
+func TestInitScheduleWithClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 11, 7, 14, 5, 30, 0, time.UTC)}
+
+	job := &Job{Interval: testMinuteCron}
+	require.NoError(t, job.initSchedule(clock.Now()))
+	job.ticker.Stop()
+	assert.Equal(t, time.Date(2025, 11, 7, 14, 6, 0, 0, time.UTC), job.nextRunAt)
+
+	clock.Advance(time.Hour)
+	job = &Job{Interval: "10m"}
+	require.NoError(t, job.initSchedule(clock.Now()))
+	job.ticker.Stop()
+	assert.Equal(t, 10*time.Minute, job.period)
+	assert.Equal(t, time.Date(2025, 11, 7, 15, 15, 30, 0, time.UTC), job.nextRunAt)
+
+	clock.Advance(10*time.Minute + 3*time.Second)
+	c := &cronWorker{opt: option{clock: clock}}
+	assert.Equal(t, 3*time.Second, c.now().Sub(job.fired(c.now())))
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	preview, err := PreviewSchedule("0 0 9 * * 1-5 *", "Asia/Jakarta", from, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Jakarta", preview.Timezone)
+	assert.Equal(t, "At 09:00:00, on Monday, Tuesday, Wednesday, Thursday, and Friday", preview.Description)
+	assert.Len(t, preview.Next, 3)
+	// 2024-01-01 10:00 UTC is 17:00 in Jakarta (Monday), next is Tuesday 09:00
+	assert.Equal(t, "2024-01-02T09:00:00+07:00", preview.Next[0].Format(time.RFC3339))
+	assert.Equal(t, "2024-01-04T09:00:00+07:00", preview.Next[2].Format(time.RFC3339))
+
+	preview, err = PreviewSchedule(CreateCronJobKey("job", "", "23:00@weekly"), "UTC", from, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "At 23:00, then every 168h0m0s", preview.Description)
+	assert.Equal(t, []time.Time{time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 23, 0, 0, 0, time.UTC)}, preview.Next)
+
+	preview, err = PreviewSchedule("10m", "", from, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Every 10m0s", preview.Description)
+	assert.Equal(t, from.Add(20*time.Minute), preview.Next[1].UTC())
+
+	_, err = PreviewSchedule("* * *", "", from, 2)
+	assert.Error(t, err)
+	_, err = PreviewSchedule("10m", "Mars/Olympus", from, 2)
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	HTTPHandlerSchedulePreview(rec, httptest.NewRequest(http.MethodGet, "/cronexpr?expression=@daily&from=2024-01-01T10:00:00Z&timezone=UTC&n=2", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"2024-01-02T00:00:00Z","2024-01-03T00:00:00Z"`)
+}
+
+func TestJobFired(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := &Job{period: time.Minute, nextRunAt: start}
+
+	// fired late 40 seconds, drift measured from intended fire time
+	assert.Equal(t, start, job.fired(start.Add(40*time.Second)))
+	assert.Equal(t, start.Add(time.Minute), job.nextRunAt)
+
+	// ticks dropped while saturated, intended fire time aligned to latest missed period
+	assert.Equal(t, start.Add(3*time.Minute), job.fired(start.Add(3*time.Minute+5*time.Second)))
+	assert.Equal(t, start.Add(4*time.Minute), job.nextRunAt)
+
+	// cron expression job, next intended fire time registered from schedule
+	cronJob := &Job{nextRunAt: start}
+	assert.Equal(t, start, cronJob.fired(start.Add(time.Second)))
+	assert.Equal(t, start, cronJob.nextRunAt)
+}
+
+func TestRegistryConflictKey(t *testing.T) {
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("billing-service"))
+	registry := NewMemoryRegistry()
+	c := &cronWorker{
+		ctx: context.Background(), service: service, instance: "pod-1",
+		opt: option{locker: &candiutils.NoopLocker{}, registry: registry},
+	}
+
+	var executed int
+	job := &Job{HandlerName: "reconcile", Interval: "1h", Handler: types.WorkerHandler{
+		HandlerFuncs: []types.WorkerHandlerFunc{func(*candishared.EventContext) error { executed++; return nil }},
+	}}
+	WorkerHandlerOptionConflictKey("reconciliation", time.Minute)(&job.Handler)
+	c.activeJobs = append(c.activeJobs, job)
+	c.register()
+
+	// conflict key held by job in another service
+	_, acquired, _ := registry.AcquireConflict(context.Background(), "reconciliation", "payment-service:reconcile@pod-9", time.Minute)
+	require.True(t, acquired)
+	c.processJob(job, time.Now())
+	assert.Equal(t, 0, executed)
+
+	jobs, err := registry.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "reconciliation", jobs[0].ConflictKey)
+	assert.Equal(t, RunStatusConflict, jobs[0].LastRun.Status)
+	assert.Contains(t, jobs[0].LastRun.Error, "payment-service:reconcile@pod-9")
+
+	require.NoError(t, registry.ReleaseConflict(context.Background(), "reconciliation", "payment-service:reconcile@pod-9"))
+	c.processJob(job, time.Now())
+	assert.Equal(t, 1, executed)
 
-		assert.GreaterOrEqual(t, nextDuration, time.Second,
-			"Safety check should ensure at least 1 second before next execution")
+	jobs, _ = registry.List(context.Background())
+	assert.Equal(t, RunStatusSuccess, jobs[0].LastRun.Status)
+	assert.Equal(t, "pod-1", jobs[0].LastRun.Instance)
 
-		// The next execution should be 1 minute later than the immediate next
-		expectedDiff := time.Minute
-		actualDiff := nextNextTime.Sub(nextTime)
-		assert.Equal(t, expectedDiff, actualDiff,
-			"Next execution after safety check should be exactly 1 minute later")
+	// conflict key released after job finished
+	_, acquired, _ = registry.AcquireConflict(context.Background(), "reconciliation", "payment-service:reconcile@pod-9", time.Minute)
+	assert.True(t, acquired)
+
+	rec := httptest.NewRecorder()
+	HTTPHandlerRegistry(registry)(rec, httptest.NewRequest(http.MethodGet, "/cron-registry?service=billing-service", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"handler_name":"reconcile"`)
+}
+
+func TestScheduleProvider(t *testing.T) {
+	handler := types.WorkerHandler{
+		HandlerFuncs: []types.WorkerHandlerFunc{func(*candishared.EventContext) error { return nil }},
+	}
+	c := &cronWorker{
+		opt:      option{maxGoroutines: 1},
+		workers:  make([]reflect.SelectCase, 2),
+		handlers: map[string]types.WorkerHandler{"sync-product": handler},
 	}
+	defer c.stopAllJob()
+
+	c.applySchedules([]ScheduleDefinition{
+		{Name: "sync-product-hourly", HandlerName: "sync-product", Interval: "1h", Params: `{"page":1}`, Enabled: true},
+		{HandlerName: "sync-product", Interval: "1m", Enabled: false},
+		{HandlerName: "unknown", Interval: "1m", Enabled: true},
+	})
+	require.Len(t, c.activeJobs, 1)
+	hourly := c.activeJobs[0]
+	assert.Equal(t, 2, hourly.WorkerIndex)
+	assert.Equal(t, `{"page":1}`, hourly.Params)
+	assert.True(t, c.workers[2].Chan.IsValid())
+
+	// changed schedule replace job in same worker slot, new schedule added
+	c.applySchedules([]ScheduleDefinition{
+		{Name: "sync-product-hourly", HandlerName: "sync-product", Interval: "30m", Params: `{"page":1}`, Enabled: true},
+		{HandlerName: "sync-product", Interval: "1m", Enabled: true},
+	})
+	require.Len(t, c.activeJobs, 2)
+	assert.NotSame(t, hourly, c.activeJobs[0])
+	assert.Equal(t, "30m", c.activeJobs[0].Interval)
+	assert.Equal(t, 30*time.Minute, c.activeJobs[0].period)
+	assert.Equal(t, "1m", c.activeJobs[1].Interval)
+	assert.Len(t, c.semaphore, 2)
+
+	// removed and disabled schedule is ignored by select
+	c.applySchedules([]ScheduleDefinition{
+		{HandlerName: "sync-product", Interval: "1m", Enabled: false},
+	})
+	assert.True(t, c.activeJobs[0].disabled)
+	assert.True(t, c.activeJobs[1].disabled)
+	assert.False(t, c.workers[2].Chan.IsValid())
+	assert.False(t, c.workers[3].Chan.IsValid())
+
+	// enabled again
+	c.applySchedules([]ScheduleDefinition{
+		{HandlerName: "sync-product", Interval: "1m", Enabled: true},
+	})
+	assert.False(t, c.activeJobs[1].disabled)
+	assert.True(t, c.workers[3].Chan.IsValid())
+	assert.Len(t, c.activeJobs, 2)
+}
+
+func TestDSTSchedule(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	nextTimes := func(schedule cronexpr.Schedule, from time.Time, n int) (result []string) {
+		for range n {
+			from = schedule.Next(from)
+			result = append(result, from.Format("01-02 15:04 MST"))
+		}
+		return result
+	}
+
+	// 2024-03-10 02:00 EST spring forward to 03:00 EDT, skipped time roll forward to transition
+	springForward := time.Date(2024, 3, 9, 12, 0, 0, 0, loc)
+	assert.Equal(t, []string{"03-10 03:00 EDT", "03-11 02:30 EDT"}, nextTimes(cronexpr.MustParse("30 2 * * *"), springForward, 2))
+	assert.Equal(t, []string{"03-11 02:30 EDT"}, nextTimes(cronexpr.MustParse("30 2 * * *", cronexpr.SkipNonexistentTime()), springForward, 1))
+	assert.Equal(t, []string{"03-10 01:45 EST", "03-10 03:00 EDT", "03-10 03:15 EDT"},
+		nextTimes(cronexpr.MustParse("*/15 * * * *"), springForward.Add(13*time.Hour+40*time.Minute), 3))
+
+	// 2024-11-03 02:00 EDT fall back to 01:00 EST, repeated time fire once
+	fallBack := time.Date(2024, 11, 3, 0, 40, 0, 0, loc)
+	assert.Equal(t, []string{"11-03 01:00 EDT", "11-03 01:30 EDT", "11-03 02:00 EST"},
+		nextTimes(cronexpr.MustParse("*/30 * * * *"), fallBack, 3))
+	assert.Equal(t, []string{"11-03 01:00 EDT", "11-03 01:30 EDT", "11-03 01:00 EST", "11-03 01:30 EST", "11-03 02:00 EST"},
+		nextTimes(cronexpr.MustParse("*/30 * * * *", cronexpr.RepeatAmbiguousTime()), fallBack, 5))
+	assert.Equal(t, []string{"11-03 01:30 EDT", "11-04 01:30 EST"}, nextTimes(cronexpr.MustParse("30 1 * * *"), fallBack, 2))
+
+	job := &Job{Interval: "30 1 * * *"}
+	WorkerHandlerOptionDST(cronexpr.RepeatAmbiguousTime())(&job.Handler)
+	require.NoError(t, job.initSchedule(fallBack))
+	job.ticker.Stop()
+	assert.Equal(t, []string{"11-03 01:30 EDT", "11-03 01:30 EST"}, nextTimes(job.schedule, fallBack, 2))
+}
+
+func TestControlCommand(t *testing.T) {
+	var executed int
+	done := make(chan struct{}, 1)
+	handler := types.WorkerHandler{
+		HandlerFuncs: []types.WorkerHandlerFunc{func(*candishared.EventContext) error { executed++; done <- struct{}{}; return nil }},
+	}
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("test"))
+	c := &cronWorker{
+		opt:      option{maxGoroutines: 1, locker: &candiutils.NoopLocker{}},
+		workers:  make([]reflect.SelectCase, 2),
+		handlers: map[string]types.WorkerHandler{"sync-product": handler},
+		service:  service,
+	}
+	c.ctx, c.ctxCancelFunc = context.WithCancel(context.Background())
+	defer c.stopAllJob()
+	c.applySchedules([]ScheduleDefinition{{HandlerName: "sync-product", Interval: "1h", Enabled: true}})
+
+	require.NoError(t, c.pauseJob("sync-product"))
+	assert.False(t, c.workers[2].Chan.IsValid())
+	assert.Equal(t, []JobState{{HandlerName: "sync-product", Interval: "1h", Paused: true}}, c.jobStates())
+
+	// unchanged schedule from provider does not resume paused job
+	c.applySchedules([]ScheduleDefinition{{HandlerName: "sync-product", Interval: "1h", Enabled: true}})
+	assert.True(t, c.activeJobs[0].paused)
+
+	require.NoError(t, c.resumeJob("sync-product"))
+	assert.True(t, c.workers[2].Chan.IsValid())
+	assert.False(t, c.jobStates()[0].NextRunAt.IsZero())
+
+	require.NoError(t, c.runJob("sync-product"))
+	<-done
+	c.wg.Wait()
+	assert.Equal(t, 1, executed)
+	assert.ErrorContains(t, c.pauseJob("unknown"), "job unknown is not found")
 }
@@ -3,10 +3,22 @@ package cronworker
 import (
 	"time"
 
+	"github.com/golangid/candi/candihelper"
 	cronexpr "github.com/golangid/candi/candiutils/cronparser"
 	"github.com/golangid/candi/codebase/factory/types"
 )
 
+// ConfigDSTOptions handler config key for daylight saving time behavior of cron expression schedule
+const ConfigDSTOptions = "cron_dst_options"
+
+// WorkerHandlerOptionDST option func, set daylight saving time behavior of cron expression schedule in zone with DST
+// (default roll forward skipped time and fire once at repeated time), example:
+//
+//	cronworker.WorkerHandlerOptionDST(cronexpr.SkipNonexistentTime(), cronexpr.RepeatAmbiguousTime())
+func WorkerHandlerOptionDST(opts ...cronexpr.ParseOption) types.WorkerHandlerOptionFunc {
+	return types.WorkerHandlerOptionAddConfig(ConfigDSTOptions, opts)
+}
+
 // Job model
 type Job struct {
 	HandlerName  string              `json:"handler_name"`
@@ -17,4 +29,65 @@ type Job struct {
 	ticker       *time.Ticker        `json:"-"`
 	schedule     cronexpr.Schedule   `json:"-"`
 	nextDuration *time.Duration      `json:"-"`
+	period       time.Duration       // repeat duration of ticker for interval job, zero for cron expression
+	nextRunAt    time.Time           // intended fire time of next tick
+	definition   *ScheduleDefinition // schedule from provider, nil for static schedule
+	disabled     bool
+	paused       bool // paused with control command until resumed or schedule from provider is changed
+}
+
+// fired get intended fire time of current tick (fired at now) and advance intended fire time of next tick,
+// ticker drop tick when receiver is slow so intended fire time is aligned to latest missed period
+func (j *Job) fired(now time.Time) (scheduledAt time.Time) {
+	scheduledAt = j.nextRunAt
+	if j.period > 0 {
+		if late := now.Sub(scheduledAt); late >= j.period {
+			scheduledAt = scheduledAt.Add(late / j.period * j.period)
+		}
+		j.nextRunAt = scheduledAt.Add(j.period)
+	}
+	return scheduledAt
+}
+
+// initSchedule parse interval and start ticker of next schedule from now
+func (j *Job) initSchedule(now time.Time) (err error) {
+	j.schedule, j.nextDuration, j.period, j.nextRunAt = nil, nil, 0, time.Time{}
+	duration, nextDuration, err := candihelper.ParseDurationExpression(j.Interval)
+	if err != nil {
+		dstOptions, _ := j.Handler.Configs[ConfigDSTOptions].([]cronexpr.ParseOption)
+		j.schedule, err = cronexpr.Parse(j.Interval, dstOptions...)
+		if err != nil {
+			return err
+		}
+		j.nextRunAt, duration = calculateNextTime(j.schedule, now)
+	}
+
+	if nextDuration > 0 {
+		j.nextDuration = &nextDuration
+	}
+
+	j.ticker = time.NewTicker(duration)
+	if j.nextRunAt.IsZero() {
+		j.nextRunAt = now.Add(duration)
+	}
+	if j.schedule == nil && j.nextDuration == nil {
+		j.period = duration
+	}
+	return nil
+}
+
+// calculateNextTime next execution time of cron schedule after now and duration until next execution time,
+// next execution time less than 1 second from now is skipped to prevent immediate re-execution
+func calculateNextTime(schedule cronexpr.Schedule, now time.Time) (nextTime time.Time, duration time.Duration) {
+	nextTime = schedule.Next(now)
+	if nextTime.IsZero() {
+		// fallback if Next() returns zero time
+		duration = schedule.NextInterval(now)
+		return now.Add(duration), duration
+	}
+	if duration = nextTime.Sub(now); duration < time.Second {
+		nextTime = schedule.Next(nextTime.Add(time.Second))
+		duration = nextTime.Sub(now)
+	}
+	return nextTime, duration
 }
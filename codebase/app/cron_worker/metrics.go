@@ -0,0 +1,48 @@
+package cronworker
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/golangid/candi/codebase/app/cron_worker"
+
+// scheduler metrics, scheduling accuracy (drift between intended fire time and actual handler start) and skipped job
+type metrics struct {
+	drift   metric.Float64Histogram
+	skipped metric.Int64Counter
+}
+
+func newMetrics(provider metric.MeterProvider) (m metrics) {
+	meter := provider.Meter(meterName)
+	m.drift, _ = meter.Float64Histogram("candi.cron.schedule.drift",
+		metric.WithDescription("Difference between intended fire time and actual handler start of cron job"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300),
+	)
+	m.skipped, _ = meter.Int64Counter("candi.cron.job.skipped",
+		metric.WithDescription("Number of cron job fire skipped, reason: saturated (max goroutines reached), locked (running in another instance) or conflict (conflict key held in fleet registry)"),
+	)
+	return m
+}
+
+func (m metrics) recordDrift(ctx context.Context, job *Job, drift time.Duration) {
+	if m.drift == nil {
+		return
+	}
+	m.drift.Record(ctx, drift.Seconds(), metric.WithAttributes(
+		attribute.String("job_name", job.HandlerName), attribute.String("interval", job.Interval),
+	))
+}
+
+func (m metrics) recordSkipped(ctx context.Context, job *Job, reason string) {
+	if m.skipped == nil {
+		return
+	}
+	m.skipped.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("job_name", job.HandlerName), attribute.String("reason", reason),
+	))
+}
@@ -1,9 +1,14 @@
 package cronworker
 
 import (
+	"time"
+
+	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/interfaces"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type (
@@ -11,6 +16,12 @@ type (
 		maxGoroutines int
 		debugMode     bool
 		locker        interfaces.Locker
+		meterProvider metric.MeterProvider
+		registry      Registry
+		clock         candihelper.Clock
+
+		scheduleProvider        ScheduleProvider
+		scheduleRefreshInterval time.Duration
 	}
 
 	// OptionFunc type
@@ -21,9 +32,13 @@ func getDefaultOption(service factory.ServiceFactory) option {
 	opt := option{
 		maxGoroutines: 10,
 		debugMode:     true,
+		meterProvider: otel.GetMeterProvider(),
+		clock:         candihelper.SystemClock,
 	}
 	if redisPool := service.GetDependency().GetRedisPool(); redisPool != nil {
 		opt.locker = candiutils.NewRedisLocker(redisPool.WritePool())
+	} else if boltDB := service.GetDependency().GetBoltDatabase(); boltDB != nil {
+		opt.locker = candiutils.NewBoltLocker(boltDB.DB())
 	} else {
 		opt.locker = &candiutils.NoopLocker{}
 	}
@@ -50,3 +65,34 @@ func SetLocker(locker interfaces.Locker) OptionFunc {
 		o.locker = locker
 	}
 }
+
+// SetMeterProvider option func, set OpenTelemetry meter provider for scheduler metrics (default global meter provider)
+func SetMeterProvider(meterProvider metric.MeterProvider) OptionFunc {
+	return func(o *option) {
+		o.meterProvider = meterProvider
+	}
+}
+
+// SetClock option func, set clock for calculate next schedule and drift (default system clock)
+func SetClock(clock candihelper.Clock) OptionFunc {
+	return func(o *option) {
+		o.clock = clock
+	}
+}
+
+// SetRegistry option func, set fleet wide registry for register job schedules and last runs,
+// and prevent job with same conflict key (see WorkerHandlerOptionConflictKey) running simultaneously in fleet
+func SetRegistry(registry Registry) OptionFunc {
+	return func(o *option) {
+		o.registry = registry
+	}
+}
+
+// SetScheduleProvider option func, load job schedules from provider (database table, config service, etc) in addition to
+// static schedule in handler pattern, schedules refreshed every refreshInterval (default 1 minute)
+func SetScheduleProvider(provider ScheduleProvider, refreshInterval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.scheduleProvider = provider
+		o.scheduleRefreshInterval = refreshInterval
+	}
+}
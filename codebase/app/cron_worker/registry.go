@@ -0,0 +1,197 @@
+package cronworker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/wrapper"
+)
+
+// Fleet registry, optional central registry shared by all candi services. Each cron worker register its job schedules
+// and last runs, and job with same conflict key cannot run simultaneously in whole fleet (even in different services).
+
+const (
+	// ConfigConflictKey handler config key for fleet wide conflict key
+	ConfigConflictKey = "cron_conflict_key"
+	// ConfigConflictTTL handler config key for max hold duration of conflict key
+	ConfigConflictTTL = "cron_conflict_ttl"
+
+	// RunStatusSuccess job run success
+	RunStatusSuccess = "SUCCESS"
+	// RunStatusFailure job run return error
+	RunStatusFailure = "FAILURE"
+	// RunStatusConflict job run skipped because conflict key is held by another job
+	RunStatusConflict = "CONFLICT"
+
+	defaultConflictTTL = 30 * time.Minute
+)
+
+type (
+	// Registry fleet wide cron job registry
+	Registry interface {
+		// Register upsert job schedules of service
+		Register(ctx context.Context, jobs ...RegistryJob) error
+		// RecordRun save last run of job
+		RecordRun(ctx context.Context, service, handlerName string, run RegistryRun, nextRunAt time.Time) error
+		// AcquireConflict hold conflict key for owner until released or ttl expired, return current holder when not acquired
+		AcquireConflict(ctx context.Context, key, owner string, ttl time.Duration) (holder string, acquired bool, err error)
+		// ReleaseConflict release conflict key if still held by owner
+		ReleaseConflict(ctx context.Context, key, owner string) error
+		// List all registered jobs in fleet
+		List(ctx context.Context) ([]RegistryJob, error)
+	}
+
+	// RegistryJob registered cron job
+	RegistryJob struct {
+		Service     string       `json:"service"`
+		HandlerName string       `json:"handler_name"`
+		Interval    string       `json:"interval"`
+		ConflictKey string       `json:"conflict_key,omitempty"`
+		Instance    string       `json:"instance"`
+		NextRunAt   time.Time    `json:"next_run_at"`
+		LastRun     *RegistryRun `json:"last_run,omitempty"`
+		UpdatedAt   time.Time    `json:"updated_at"`
+	}
+
+	// RegistryRun job run result
+	RegistryRun struct {
+		Instance string    `json:"instance"`
+		Status   string    `json:"status"`
+		Error    string    `json:"error,omitempty"`
+		StartAt  time.Time `json:"start_at"`
+		EndAt    time.Time `json:"end_at"`
+	}
+)
+
+// WorkerHandlerOptionConflictKey set fleet wide conflict key of job, job with same key never run simultaneously
+// (need registry in cron worker option). Key is held until job finished or ttl expired (default 30 minutes)
+func WorkerHandlerOptionConflictKey(key string, ttl ...time.Duration) types.WorkerHandlerOptionFunc {
+	return func(wh *types.WorkerHandler) {
+		types.WorkerHandlerOptionAddConfig(ConfigConflictKey, key)(wh)
+		if len(ttl) > 0 {
+			types.WorkerHandlerOptionAddConfig(ConfigConflictTTL, ttl[0])(wh)
+		}
+	}
+}
+
+func getConflictKey(handler types.WorkerHandler) (key string, ttl time.Duration) {
+	key, _ = handler.Configs[ConfigConflictKey].(string)
+	ttl, _ = handler.Configs[ConfigConflictTTL].(time.Duration)
+	if ttl <= 0 {
+		ttl = defaultConflictTTL
+	}
+	return key, ttl
+}
+
+func registryInstance() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
+
+func registryJobKey(service, handlerName string) string {
+	return service + ":" + handlerName
+}
+
+func sortRegistryJobs(jobs []RegistryJob) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Service != jobs[j].Service {
+			return jobs[i].Service < jobs[j].Service
+		}
+		return jobs[i].HandlerName < jobs[j].HandlerName
+	})
+}
+
+// HTTPHandlerRegistry admin handler for fleet wide view of registered cron jobs and last runs,
+// query param "service" for filter by service name
+func HTTPHandlerRegistry(registry Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobs, err := registry.List(r.Context())
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusInternalServerError, err.Error()).JSON(w)
+			return
+		}
+		if service := r.URL.Query().Get("service"); service != "" {
+			filtered := jobs[:0]
+			for _, job := range jobs {
+				if job.Service == service {
+					filtered = append(filtered, job)
+				}
+			}
+			jobs = filtered
+		}
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", jobs).JSON(w)
+	}
+}
+
+type memoryRegistry struct {
+	mu       sync.Mutex
+	jobs     map[string]RegistryJob
+	conflict map[string]memoryConflict
+}
+
+type memoryConflict struct {
+	owner     string
+	expiredAt time.Time
+}
+
+// NewMemoryRegistry in memory registry, only shared by cron workers in one runtime (for test or development only)
+func NewMemoryRegistry() Registry {
+	return &memoryRegistry{jobs: make(map[string]RegistryJob), conflict: make(map[string]memoryConflict)}
+}
+
+func (r *memoryRegistry) Register(ctx context.Context, jobs ...RegistryJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range jobs {
+		key := registryJobKey(job.Service, job.HandlerName)
+		job.LastRun = r.jobs[key].LastRun
+		r.jobs[key] = job
+	}
+	return nil
+}
+
+func (r *memoryRegistry) RecordRun(ctx context.Context, service, handlerName string, run RegistryRun, nextRunAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := registryJobKey(service, handlerName)
+	job := r.jobs[key]
+	job.Service, job.HandlerName = service, handlerName
+	job.LastRun, job.NextRunAt, job.UpdatedAt = &run, nextRunAt, time.Now()
+	r.jobs[key] = job
+	return nil
+}
+
+func (r *memoryRegistry) AcquireConflict(ctx context.Context, key, owner string, ttl time.Duration) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.conflict[key]; ok && current.owner != owner && time.Now().Before(current.expiredAt) {
+		return current.owner, false, nil
+	}
+	r.conflict[key] = memoryConflict{owner: owner, expiredAt: time.Now().Add(ttl)}
+	return owner, true, nil
+}
+
+func (r *memoryRegistry) ReleaseConflict(ctx context.Context, key, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conflict[key].owner == owner {
+		delete(r.conflict, key)
+	}
+	return nil
+}
+
+func (r *memoryRegistry) List(ctx context.Context) (jobs []RegistryJob, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.jobs {
+		jobs = append(jobs, job)
+	}
+	sortRegistryJobs(jobs)
+	return jobs, nil
+}
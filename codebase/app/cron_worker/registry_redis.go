@@ -0,0 +1,129 @@
+package cronworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	redisRegistryJobs     = "candi:cron-registry:jobs"
+	redisRegistryConflict = "candi:cron-registry:conflict:"
+)
+
+// release conflict key only if still held by owner
+var redisReleaseConflict = redis.NewScript(1, `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+type redisRegistry struct {
+	pool *redis.Pool
+}
+
+// NewRedisRegistry registry in redis, use same redis in all services of fleet
+func NewRedisRegistry(pool *redis.Pool) Registry {
+	return &redisRegistry{pool: pool}
+}
+
+func (r *redisRegistry) Register(ctx context.Context, jobs ...RegistryJob) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, job := range jobs {
+		key := registryJobKey(job.Service, job.HandlerName)
+		if current, err := r.get(conn, key); err == nil {
+			job.LastRun = current.LastRun
+		}
+		b, _ := json.Marshal(job)
+		if _, err := conn.Do("HSET", redisRegistryJobs, key, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *redisRegistry) RecordRun(ctx context.Context, service, handlerName string, run RegistryRun, nextRunAt time.Time) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := registryJobKey(service, handlerName)
+	job, err := r.get(conn, key)
+	if err != nil && !errors.Is(err, redis.ErrNil) {
+		return err
+	}
+	job.Service, job.HandlerName = service, handlerName
+	job.LastRun, job.NextRunAt, job.UpdatedAt = &run, nextRunAt, time.Now()
+	b, _ := json.Marshal(job)
+	_, err = conn.Do("HSET", redisRegistryJobs, key, b)
+	return err
+}
+
+func (r *redisRegistry) AcquireConflict(ctx context.Context, key, owner string, ttl time.Duration) (string, bool, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer conn.Close()
+
+	_, err = redis.String(conn.Do("SET", redisRegistryConflict+key, owner, "NX", "PX", ttl.Milliseconds()))
+	if err == nil {
+		return owner, true, nil
+	}
+	if !errors.Is(err, redis.ErrNil) {
+		return "", false, err
+	}
+	holder, err := redis.String(conn.Do("GET", redisRegistryConflict+key))
+	if errors.Is(err, redis.ErrNil) {
+		// released between SET and GET, acquire in next schedule
+		return "", false, nil
+	}
+	return holder, holder == owner, err
+}
+
+func (r *redisRegistry) ReleaseConflict(ctx context.Context, key, owner string) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = redisReleaseConflict.Do(conn, redisRegistryConflict+key, owner)
+	return err
+}
+
+func (r *redisRegistry) List(ctx context.Context) (jobs []RegistryJob, err error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	values, err := redis.StringMap(conn.Do("HGETALL", redisRegistryJobs))
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		var job RegistryJob
+		if err := json.Unmarshal([]byte(value), &job); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	sortRegistryJobs(jobs)
+	return jobs, nil
+}
+
+func (r *redisRegistry) get(conn redis.Conn, key string) (job RegistryJob, err error) {
+	b, err := redis.Bytes(conn.Do("HGET", redisRegistryJobs, key))
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal(b, &job)
+	return job, err
+}
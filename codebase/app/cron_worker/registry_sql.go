@@ -0,0 +1,136 @@
+package cronworker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+)
+
+type sqlRegistry struct {
+	db          *sql.DB
+	table       string
+	placeholder func(argIndex int) string
+}
+
+// NewSQLRegistry registry in sql table (postgres, mysql or sqlite3), use same database in all services of fleet.
+// Table (default "cron_registry") and conflict table ("{table}_conflict") are created if not exist
+func NewSQLRegistry(db *sql.DB, table string) (Registry, error) {
+	if table == "" {
+		table = "cron_registry"
+	}
+	r := &sqlRegistry{db: db, table: table, placeholder: candishared.SQLPlaceholderOf(db)}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+		service VARCHAR(255) NOT NULL,
+		handler_name VARCHAR(255) NOT NULL,
+		job TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (service, handler_name)
+	)`); err != nil {
+		return nil, fmt.Errorf("create cron registry table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + `_conflict (
+		conflict_key VARCHAR(255) NOT NULL PRIMARY KEY,
+		owner VARCHAR(255) NOT NULL,
+		expired_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("create cron registry conflict table: %w", err)
+	}
+	return r, nil
+}
+
+func (r *sqlRegistry) Register(ctx context.Context, jobs ...RegistryJob) error {
+	for _, job := range jobs {
+		current, err := r.get(ctx, job.Service, job.HandlerName)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		job.LastRun = current.LastRun
+		if err := r.save(ctx, job, err == nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqlRegistry) RecordRun(ctx context.Context, service, handlerName string, run RegistryRun, nextRunAt time.Time) error {
+	job, err := r.get(ctx, service, handlerName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	job.Service, job.HandlerName = service, handlerName
+	job.LastRun, job.NextRunAt, job.UpdatedAt = &run, nextRunAt, time.Now()
+	return r.save(ctx, job, err == nil)
+}
+
+func (r *sqlRegistry) AcquireConflict(ctx context.Context, key, owner string, ttl time.Duration) (string, bool, error) {
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, candishared.SQLRebind(`DELETE FROM `+r.table+`_conflict WHERE conflict_key = ? AND expired_at < ?`, r.placeholder),
+		key, now); err != nil {
+		return "", false, err
+	}
+	// insert fail with unique violation when conflict key is held
+	_, insertErr := r.db.ExecContext(ctx, candishared.SQLRebind(`INSERT INTO `+r.table+`_conflict (conflict_key, owner, expired_at) VALUES (?, ?, ?)`, r.placeholder),
+		key, owner, now.Add(ttl))
+	if insertErr == nil {
+		return owner, true, nil
+	}
+
+	var holder string
+	err := r.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT owner FROM `+r.table+`_conflict WHERE conflict_key = ?`, r.placeholder), key).Scan(&holder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, insertErr
+	}
+	return holder, holder == owner, err
+}
+
+func (r *sqlRegistry) ReleaseConflict(ctx context.Context, key, owner string) error {
+	_, err := r.db.ExecContext(ctx, candishared.SQLRebind(`DELETE FROM `+r.table+`_conflict WHERE conflict_key = ? AND owner = ?`, r.placeholder), key, owner)
+	return err
+}
+
+func (r *sqlRegistry) List(ctx context.Context) (jobs []RegistryJob, err error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT job FROM `+r.table+` ORDER BY service, handler_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b string
+		if err := rows.Scan(&b); err != nil {
+			return nil, err
+		}
+		var job RegistryJob
+		if err := json.Unmarshal([]byte(b), &job); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, rows.Err()
+}
+
+func (r *sqlRegistry) get(ctx context.Context, service, handlerName string) (job RegistryJob, err error) {
+	var b string
+	err = r.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT job FROM `+r.table+` WHERE service = ? AND handler_name = ?`, r.placeholder),
+		service, handlerName).Scan(&b)
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal([]byte(b), &job)
+	return job, err
+}
+
+func (r *sqlRegistry) save(ctx context.Context, job RegistryJob, exist bool) (err error) {
+	b, _ := json.Marshal(job)
+	if exist {
+		_, err = r.db.ExecContext(ctx, candishared.SQLRebind(`UPDATE `+r.table+` SET job = ?, updated_at = ? WHERE service = ? AND handler_name = ?`, r.placeholder),
+			string(b), time.Now(), job.Service, job.HandlerName)
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, candishared.SQLRebind(`INSERT INTO `+r.table+` (service, handler_name, job, updated_at) VALUES (?, ?, ?, ?)`, r.placeholder),
+		job.Service, job.HandlerName, string(b), time.Now())
+	return err
+}
@@ -0,0 +1,153 @@
+package cronworker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	cronexpr "github.com/golangid/candi/candiutils/cronparser"
+	"github.com/golangid/candi/wrapper"
+)
+
+const maxSchedulePreview = 100
+
+// SchedulePreview result of schedule preview
+type SchedulePreview struct {
+	Expression  string      `json:"expression"`
+	Timezone    string      `json:"timezone"`
+	From        time.Time   `json:"from"`
+	Description string      `json:"description"`
+	Next        []time.Time `json:"next"`
+}
+
+// NextSchedules calculate next n occurrences of interval (cron expression, duration, or custom start time, see CreateCronJobKey)
+// after given time, using the same parser and scheduling rule with cron worker
+func NextSchedules(interval string, from time.Time, n int) (next []time.Time, err error) {
+	duration, nextDuration, err := candihelper.ParseDurationExpressionFrom(interval, from)
+	if err == nil {
+		if duration <= 0 {
+			return nil, errors.New("interval must be positive")
+		}
+		t := from.Add(duration)
+		for len(next) < n {
+			next = append(next, t)
+			if nextDuration > 0 {
+				t = t.Add(nextDuration)
+			} else {
+				t = t.Add(duration)
+			}
+		}
+		return next, nil
+	}
+
+	schedule, err := cronexpr.Parse(interval)
+	if err != nil {
+		return nil, err
+	}
+	t := from
+	for len(next) < n {
+		nextTime := schedule.Next(t)
+		if nextTime.IsZero() {
+			break
+		}
+		// same as worker, ensure minimum duration to prevent immediate re-execution
+		if nextTime.Sub(t) < time.Second {
+			if nextTime = schedule.Next(nextTime.Add(time.Second)); nextTime.IsZero() {
+				break
+			}
+		}
+		next = append(next, nextTime)
+		t = nextTime
+	}
+	return next, nil
+}
+
+// DescribeSchedule human-readable description of interval
+func DescribeSchedule(interval string) (string, error) {
+	if duration, err := time.ParseDuration(interval); err == nil {
+		return "Every " + duration.String(), nil
+	}
+	if _, nextDuration, err := candihelper.ParseDurationExpression(interval); err == nil {
+		at, _, _ := strings.Cut(interval, "@")
+		return fmt.Sprintf("At %s, then every %s", at, nextDuration), nil
+	}
+	return cronexpr.Describe(interval)
+}
+
+// PreviewSchedule preview next n occurrences of interval (or cron job key) in timezone after reference time
+func PreviewSchedule(interval, timezone string, from time.Time, n int) (preview SchedulePreview, err error) {
+	if _, _, keyInterval := ParseCronJobKey(interval); keyInterval != "" {
+		interval = keyInterval
+	}
+	loc := time.Local
+	if timezone != "" {
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return preview, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+	n = min(max(n, 1), maxSchedulePreview)
+
+	preview.Expression, preview.Timezone, preview.From = interval, loc.String(), from.In(loc)
+	if preview.Description, err = DescribeSchedule(interval); err != nil {
+		return preview, err
+	}
+	preview.Next, err = NextSchedules(interval, preview.From, n)
+	return preview, err
+}
+
+// HTTPHandlerSchedulePreview admin handler for verify schedule with the exact parser used by cron worker.
+// Query param (GET) or JSON body (POST): "expression", "timezone" (IANA name, default server timezone),
+// "from" (RFC3339 reference time, default now), and "n" (number of next occurrences, default 5)
+func HTTPHandlerSchedulePreview(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Expression string `json:"expression"`
+		Timezone   string `json:"timezone"`
+		From       string `json:"from"`
+		N          int    `json:"n"`
+	}
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Expression, req.Timezone, req.From = query.Get("expression"), query.Get("timezone"), query.Get("from")
+		req.N, _ = strconv.Atoi(query.Get("n"))
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid payload", err).JSON(w)
+			return
+		}
+	default:
+		wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(w)
+		return
+	}
+
+	if req.Expression == "" {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "Expression is required").JSON(w)
+		return
+	}
+	if req.N == 0 {
+		req.N = 5
+	}
+	var from time.Time
+	if req.From != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, req.From); err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid reference time", err).JSON(w)
+			return
+		}
+	}
+
+	preview, err := PreviewSchedule(req.Expression, req.Timezone, from, req.N)
+	if err != nil {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid schedule", err).JSON(w)
+		return
+	}
+	wrapper.NewHTTPResponse(http.StatusOK, "Schedule preview", preview).JSON(w)
+}
@@ -0,0 +1,231 @@
+package cronworker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/logger"
+)
+
+type (
+	// ScheduleDefinition job schedule loaded from schedule provider, handler name must be registered in
+	// cron worker handler (use HandlerKey for register handler without static schedule)
+	ScheduleDefinition struct {
+		// Name unique name of schedule, default is handler name (one handler can have multiple schedules with different name)
+		Name        string `json:"name"`
+		HandlerName string `json:"handler_name"`
+		Interval    string `json:"interval"`
+		Params      string `json:"params"`
+		Enabled     bool   `json:"enabled"`
+	}
+
+	// ScheduleProvider source of job schedules (database table, config service, etc), schedules are loaded when worker
+	// started and periodically refreshed, schedule removed or disabled from provider is stopped
+	ScheduleProvider interface {
+		Schedules(ctx context.Context) ([]ScheduleDefinition, error)
+	}
+
+	// ScheduleProviderFunc adapter of function as ScheduleProvider
+	ScheduleProviderFunc func(ctx context.Context) ([]ScheduleDefinition, error)
+
+	sqlScheduleProvider struct {
+		db             *sql.DB
+		table          string
+		service        string
+		intervalColumn string
+		postgres       bool
+	}
+)
+
+// Schedules implement ScheduleProvider
+func (f ScheduleProviderFunc) Schedules(ctx context.Context) ([]ScheduleDefinition, error) {
+	return f(ctx)
+}
+
+// HandlerKey helper, handler pattern for register handler without static schedule, scheduled by schedule provider
+func HandlerKey(handlerName string) string {
+	return CreateCronJobKey(handlerName, "", "")
+}
+
+func (d *ScheduleDefinition) key() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.HandlerName
+}
+
+// NewSQLScheduleProvider schedule provider from sql table (postgres, mysql or sqlite3), load enabled and disabled schedules
+// of service (empty service column is loaded by all services). Table (default "cron_schedules") is created if not exist
+func NewSQLScheduleProvider(db *sql.DB, table, service string) (ScheduleProvider, error) {
+	if table == "" {
+		table = "cron_schedules"
+	}
+	dbDriverType := fmt.Sprintf("%T", db.Driver())
+	p := &sqlScheduleProvider{
+		db: db, table: table, service: service, intervalColumn: `"interval"`, // interval is reserved word
+		postgres: dbDriverType == "*pq.Driver" || dbDriverType == "*stdlib.Driver",
+	}
+	if dbDriverType == "*mysql.MySQLDriver" {
+		p.intervalColumn = "`interval`"
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+		name VARCHAR(255) NOT NULL PRIMARY KEY,
+		service VARCHAR(255) NOT NULL DEFAULT '',
+		handler_name VARCHAR(255) NOT NULL,
+		` + p.intervalColumn + ` VARCHAR(255) NOT NULL,
+		params TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT true
+	)`); err != nil {
+		return nil, fmt.Errorf("create cron schedule table: %w", err)
+	}
+	return p, nil
+}
+
+func (p *sqlScheduleProvider) Schedules(ctx context.Context) ([]ScheduleDefinition, error) {
+	query := `SELECT name, handler_name, ` + p.intervalColumn + `, params, enabled FROM ` + p.table +
+		` WHERE service = '' OR service = ?`
+	if p.postgres {
+		query = strings.Replace(query, "?", "$1", 1)
+	}
+	rows, err := p.db.QueryContext(ctx, query, p.service)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []ScheduleDefinition
+	for rows.Next() {
+		var schedule ScheduleDefinition
+		if err := rows.Scan(&schedule.Name, &schedule.HandlerName, &schedule.Interval, &schedule.Params, &schedule.Enabled); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// loadSchedules get schedules from provider, schedules applied in serve loop (refresh worker notification)
+func (c *cronWorker) loadSchedules(ctx context.Context) {
+	schedules, err := c.opt.scheduleProvider.Schedules(ctx)
+	if err != nil {
+		logger.LogRed("cron_worker > load schedules from provider: " + err.Error())
+		return
+	}
+	c.scheduleMu.Lock()
+	c.pendingSchedules = &schedules
+	c.scheduleMu.Unlock()
+	c.refreshWorker()
+}
+
+func (c *cronWorker) watchSchedules() {
+	ticker := time.NewTicker(c.opt.scheduleRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.loadSchedules(c.ctx)
+		}
+	}
+}
+
+// applyPendingSchedules apply latest loaded schedules, must be called in serve loop goroutine
+func (c *cronWorker) applyPendingSchedules() {
+	c.scheduleMu.Lock()
+	pending := c.pendingSchedules
+	c.pendingSchedules = nil
+	c.scheduleMu.Unlock()
+	if pending != nil {
+		c.applySchedules(*pending)
+	}
+}
+
+// applySchedules add new, update changed, and disable removed schedules from provider
+func (c *cronWorker) applySchedules(schedules []ScheduleDefinition) {
+	current := make(map[string]*Job)
+	for _, job := range c.activeJobs {
+		if job.definition != nil {
+			current[job.definition.key()] = job
+		}
+	}
+
+	seen := make(map[string]struct{}, len(schedules))
+	for _, schedule := range schedules {
+		key := schedule.key()
+		seen[key] = struct{}{}
+		job, exist := current[key]
+		if exist && *job.definition == schedule && (!job.disabled || job.paused) == schedule.Enabled {
+			continue
+		}
+		if exist {
+			c.disableJob(job)
+		}
+		if !schedule.Enabled {
+			if exist {
+				job.definition, job.paused = &schedule, false
+				logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (schedule): "%s" disabled`, key))
+			}
+			continue
+		}
+
+		handler, ok := c.handlers[schedule.HandlerName]
+		if !ok {
+			logger.LogRed(fmt.Sprintf(`cron_worker > schedule "%s": handler "%s" is not registered`, key, schedule.HandlerName))
+			continue
+		}
+		// running job keep previous job instance, schedule changes applied to new job instance in same worker slot
+		newJob := &Job{
+			HandlerName: schedule.HandlerName, Handler: handler, Interval: schedule.Interval, Params: schedule.Params,
+			definition: &schedule,
+		}
+		if err := c.enableJob(job, newJob); err != nil {
+			logger.LogRed(fmt.Sprintf(`cron_worker > schedule "%s": invalid interval "%s": %v`, key, schedule.Interval, err))
+			if exist {
+				job.definition = &schedule
+			}
+			continue
+		}
+		logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (schedule): "%s" (job name): "%s" (every): %s`, key, newJob.HandlerName, newJob.Interval))
+	}
+
+	for key, job := range current {
+		if _, ok := seen[key]; !ok && (!job.disabled || job.paused) {
+			c.disableJob(job)
+			job.paused = false
+			logger.LogYellow(fmt.Sprintf(`[CRON-WORKER] (schedule): "%s" removed`, key))
+		}
+	}
+	c.register()
+}
+
+// enableJob add new job, or replace previous job (nil if not exist) in same worker slot
+func (c *cronWorker) enableJob(previous, job *Job) error {
+	if previous == nil {
+		if err := c.addJob(job); err != nil {
+			return err
+		}
+		c.semaphore = append(c.semaphore, make(chan struct{}, c.opt.maxGoroutines))
+		return nil
+	}
+
+	if err := job.initSchedule(c.now()); err != nil {
+		return err
+	}
+	job.WorkerIndex = previous.WorkerIndex
+	c.activeJobs[job.WorkerIndex-2] = job
+	c.workers[job.WorkerIndex].Chan = reflect.ValueOf(job.ticker.C)
+	return nil
+}
+
+func (c *cronWorker) disableJob(job *Job) {
+	if job.ticker != nil {
+		job.ticker.Stop()
+	}
+	job.disabled = true
+	c.workers[job.WorkerIndex].Chan = reflect.Value{} // ignored by select
+}
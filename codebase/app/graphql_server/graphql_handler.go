@@ -3,6 +3,7 @@ package graphqlserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -14,8 +15,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/golangid/candi/apiversion"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/app/graphql_server/ws"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/logger"
@@ -81,14 +84,18 @@ func ConstructHandlerFromService(service factory.ServiceFactory, opt Option) Han
 		"auth":          service.GetDependency().GetMiddleware().GraphQLAuth,
 		"permissionACL": service.GetDependency().GetMiddleware().GraphQLPermissionACL,
 	}
+	if mw := service.GetDependency().GetMiddleware(); mw != nil && opt.subscriptionAuth != nil && opt.subscriptionAuth.validate == nil {
+		opt.subscriptionAuth.validate = mw.Bearer
+	}
 	for directive, dirFunc := range opt.directiveFuncs {
 		directiveFuncs[directive] = dirFunc
 	}
 
+	gqlTracer := &graphqlTracer{}
 	schemaOpts := []graphql.SchemaOpt{
 		graphql.UseStringDescriptions(),
 		graphql.UseFieldResolvers(),
-		graphql.Tracer(&graphqlTracer{}),
+		graphql.Tracer(gqlTracer),
 		graphql.Logger(&panicLogger{}),
 		graphql.DirectiveFuncs(directiveFuncs),
 	}
@@ -102,15 +109,19 @@ func ConstructHandlerFromService(service factory.ServiceFactory, opt Option) Han
 	logger.LogYellow(fmt.Sprintf("[GraphQL] playground (with explorer)\t: http://127.0.0.1:%d%s/playground?explorer=true", opt.httpPort, opt.RootPath))
 	logger.LogYellow(fmt.Sprintf("[GraphQL] voyager\t\t\t: http://127.0.0.1:%d%s/voyager", opt.httpPort, opt.RootPath))
 
+	schema := graphql.MustParseSchema(string(opt.schemaSource), &resolver, schemaOpts...)
+	gqlTracer.deprecations = apiversion.GraphQLDeprecations(schema.ASTSchema())
 	return &handlerImpl{
-		schema: graphql.MustParseSchema(string(opt.schemaSource), &resolver, schemaOpts...),
+		schema: schema,
 		option: opt,
+		tracer: gqlTracer,
 	}
 }
 
 type handlerImpl struct {
 	schema *graphql.Schema
 	option Option
+	tracer *graphqlTracer
 }
 
 // NewHandler init new graphql http handler
@@ -122,25 +133,49 @@ func NewHandler(schema *graphql.Schema, opt Option) Handler {
 }
 
 func (s *handlerImpl) ServeGraphQL() http.HandlerFunc {
-	return ws.NewHandlerFunc(s.schema, http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
-		var params struct {
-			Query         string                 `json:"query"`
-			OperationName string                 `json:"operationName"`
-			Variables     map[string]any `json:"variables"`
-		}
-		body, err := io.ReadAll(req.Body)
-		if err != nil {
-			http.Error(resp, err.Error(), http.StatusBadRequest)
-			return
-		}
-		if err := json.Unmarshal(body, &params); err != nil {
-			params.Query = string(body)
+	var handler http.Handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		var params graphqlRequest
+		if strings.HasPrefix(req.Header.Get(candihelper.HeaderContentType), candihelper.HeaderMIMEMultipartForm) {
+			var err error
+			params, err = parseMultipartRequest(resp, req, s.uploadOption())
+			defer candihelper.RemoveUploadTempFiles(req)
+			if errors.Is(err, candihelper.ErrUploadTooLarge) {
+				http.Error(resp, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(resp, err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				http.Error(resp, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := json.Unmarshal(body, &params); err != nil {
+				params.Query = string(body)
+			}
 		}
 
 		req.Header.Set(candihelper.HeaderXRealIP, extractRealIPHeader(req))
 
 		ctx := context.WithValue(req.Context(), candishared.ContextKeyHTTPHeader, req.Header)
+		if s.tracer != nil && len(s.tracer.deprecations) > 0 {
+			ctx = apiversion.WithCollector(ctx)
+		}
 		response := s.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+		if deprecations := apiversion.Collected(ctx); len(deprecations) > 0 {
+			// notify client of used deprecated field
+			for i := range deprecations {
+				apiversion.RecordUsage(ctx, apiversion.KindGraphQL, deprecations[i].Name, apiversion.ClientName(req))
+				deprecations[i].SetHeader(resp.Header())
+			}
+			if response.Extensions == nil {
+				response.Extensions = make(map[string]any)
+			}
+			response.Extensions["deprecations"] = deprecations
+		}
 		responseJSON, err := json.Marshal(response)
 		if err != nil {
 			http.Error(resp, err.Error(), http.StatusInternalServerError)
@@ -149,7 +184,22 @@ func (s *handlerImpl) ServeGraphQL() http.HandlerFunc {
 
 		resp.Header().Set(candihelper.HeaderContentType, candihelper.HeaderMIMEApplicationJSON)
 		resp.Write(responseJSON)
-	}))
+	})
+	for i := len(s.option.httpMiddlewares) - 1; i >= 0; i-- {
+		handler = s.option.httpMiddlewares[i](handler)
+	}
+	var wsOptions []ws.Option
+	if auth := s.option.subscriptionAuth; auth != nil && auth.validate != nil {
+		wsOptions = append(wsOptions, ws.Auth(auth.validate, auth.policy, auth.opts...))
+	}
+	return ws.NewHandlerFunc(s.schema, handler, wsOptions...)
+}
+
+func (s *handlerImpl) uploadOption() *candihelper.UploadOption {
+	if s.option.uploadOption == nil {
+		return candihelper.NewUploadOption()
+	}
+	return s.option.uploadOption
 }
 
 func (s *handlerImpl) ServePlayground(resp http.ResponseWriter, req *http.Request) {
@@ -312,6 +362,7 @@ func (l *panicLogger) LogPanic(ctx context.Context, value any) {
 	}
 	log.Printf("\x1b[31;5m%s\x1b[0m", strings.Join(append([]string{fmt.Sprintf("%v", value)}, stackTraces...), "\n"))
 	tracer.Log(ctx, "stacktraces", strings.Join(stackTraces, "\n"))
+	candiutils.ReportPanic(ctx, "GraphQL-Server", value, nil)
 }
 
 func extractRealIPHeader(req *http.Request) string {
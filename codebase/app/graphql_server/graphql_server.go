@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/golangid/candi/candiutils/graceful"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/wrapper"
@@ -31,6 +32,7 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 	for _, opt := range opts {
 		opt(&server.opt)
 	}
+	server.opt.httpMiddlewares = append([]func(http.Handler) http.Handler{wrapper.HTTPMiddlewareRequestID}, server.opt.httpMiddlewares...)
 
 	httpHandler := ConstructHandlerFromService(service, server.opt)
 
@@ -52,26 +54,25 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 
 	if server.opt.sharedListener != nil {
 		server.listener = server.opt.sharedListener.Match(cmux.HTTP1Fast(http.MethodPatch))
+	} else {
+		// bind when construct, so port is ready to accept before notify graceful restart
+		var err error
+		server.listener, err = graceful.Listen("tcp", httpEngine.Addr)
+		if err != nil {
+			log.Panicf("GraphQL TCP Listener: Unexpected Error: %v", err)
+		}
 	}
 
 	return server
 }
 
 func (s *graphqlServer) Serve() {
-	var err error
-	if s.listener == nil {
-		s.listener, err = net.Listen("tcp", s.httpEngine.Addr)
-		if err != nil {
-			log.Panicf("GraphQL TCP Listener: Unexpected Error: %v", err)
-		}
-	}
-
 	if s.opt.tlsConfig != nil {
 		s.httpEngine.TLSConfig = s.opt.tlsConfig
 		s.listener = tls.NewListener(s.listener, s.opt.tlsConfig)
 	}
 
-	err = s.httpEngine.Serve(s.listener)
+	err := s.httpEngine.Serve(s.listener)
 	switch err.(type) {
 	case *net.OpError:
 		log.Panicf("GraphQL Server: Unexpected Error: %v", err)
@@ -1,10 +1,14 @@
 package graphqlserver
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 	"strings"
 
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/app/graphql_server/ws"
 	"github.com/golangid/candi/codebase/interfaces"
 	"github.com/golangid/candi/wrapper"
 	"github.com/golangid/graphql-go/types"
@@ -26,10 +30,19 @@ type (
 		directiveFuncs      map[string]types.DirectiveFunc
 		tlsConfig           *tls.Config
 		schemaSource        []byte
+		httpMiddlewares     []func(http.Handler) http.Handler
+		uploadOption        *candihelper.UploadOption
+		subscriptionAuth    *subscriptionAuth
 	}
 
 	// OptionFunc type
 	OptionFunc func(*Option)
+
+	subscriptionAuth struct {
+		validate func(ctx context.Context, token string) (*candishared.TokenClaim, error)
+		policy   ws.ExpiryPolicy
+		opts     []candishared.ConnectionAuthOption
+	}
 )
 
 func getDefaultOption() Option {
@@ -130,3 +143,27 @@ func AddSchema(schema []byte) OptionFunc {
 		o.schemaSource = append(o.schemaSource, schema...)
 	}
 }
+
+// AddHTTPMiddlewares option func, add middlewares to GraphQL HTTP endpoint (not applied to websocket subscription)
+func AddHTTPMiddlewares(middlewares ...func(http.Handler) http.Handler) OptionFunc {
+	return func(o *Option) {
+		o.httpMiddlewares = append(o.httpMiddlewares, middlewares...)
+	}
+}
+
+// SetUploadOption option func, limit of file upload with multipart request (default 10MB per file and 32MB per request),
+// declare "scalar Upload" in schema and use *candihelper.UploadFile as argument type of resolver
+func SetUploadOption(opts ...candihelper.UploadOptionFunc) OptionFunc {
+	return func(o *Option) {
+		o.uploadOption = candihelper.NewUploadOption(opts...)
+	}
+}
+
+// SetSubscriptionAuth option func, authenticate websocket subscription connection with token of connection_init
+// payload and allow refresh token mid-connection with connection_auth message, expired connection is closed or
+// degraded by policy. Validate default is Bearer of service middleware
+func SetSubscriptionAuth(policy ws.ExpiryPolicy, validate func(ctx context.Context, token string) (*candishared.TokenClaim, error), opts ...candishared.ConnectionAuthOption) OptionFunc {
+	return func(o *Option) {
+		o.subscriptionAuth = &subscriptionAuth{validate: validate, policy: policy, opts: opts}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/golangid/candi/apiversion"
 	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/config/env"
 	"github.com/golangid/candi/tracer"
@@ -27,6 +28,7 @@ var gqlTypeNotShowLog = map[string]bool{
 
 // graphqlTracer struct
 type graphqlTracer struct {
+	deprecations map[string]apiversion.Deprecation
 }
 
 // TraceQuery method, intercept incoming query and add tracing
@@ -65,6 +67,10 @@ func (t *graphqlTracer) TraceQuery(ctx context.Context, queryString string, oper
 
 // TraceField method, intercept field per query and check middleware
 func (t *graphqlTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]any) (context.Context, gqltrace.FieldFinishFunc) {
+	if deprecation, ok := t.deprecations[typeName+"."+fieldName]; ok && !apiversion.Collect(ctx, deprecation) {
+		apiversion.RecordUsage(ctx, apiversion.KindGraphQL, deprecation.Name, "")
+	}
+
 	start := time.Now()
 	return ctx, func(data []byte, err *gqlerrors.QueryError) {
 		end := time.Now()
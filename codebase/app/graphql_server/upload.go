@@ -0,0 +1,94 @@
+package graphqlserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+)
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// parseMultipartRequest parse GraphQL multipart request (https://github.com/jaydenseric/graphql-multipart-request-spec),
+// "operations" field is request payload and each file in "map" field is set to variable path as *candihelper.UploadFile
+func parseMultipartRequest(w http.ResponseWriter, req *http.Request, opt *candihelper.UploadOption) (params graphqlRequest, err error) {
+	form, err := candihelper.ParseMultipartUpload(w, req, opt)
+	if err != nil {
+		return params, err
+	}
+	if err := json.Unmarshal([]byte(firstValue(form.Value["operations"])), &params); err != nil {
+		return params, fmt.Errorf("invalid operations field (batch operation is not supported): %w", err)
+	}
+
+	var fileMap map[string][]string
+	if err := json.Unmarshal([]byte(firstValue(form.Value["map"])), &fileMap); err != nil {
+		return params, fmt.Errorf("invalid map field: %w", err)
+	}
+	for key, paths := range fileMap {
+		files := form.File[key]
+		if len(files) == 0 {
+			return params, fmt.Errorf("%w: %s", candihelper.ErrUploadMissingFile, key)
+		}
+		file, err := candihelper.NewUploadFile(files[0], opt)
+		if err != nil {
+			return params, err
+		}
+		for _, path := range paths {
+			if err := setVariable(params.Variables, path, file); err != nil {
+				return params, err
+			}
+		}
+	}
+	return params, nil
+}
+
+// setVariable replace value of object path (example: "variables.input.files.0") with file
+func setVariable(variables map[string]any, path string, file *candihelper.UploadFile) error {
+	keys := strings.Split(path, ".")
+	if len(keys) < 2 || keys[0] != "variables" {
+		return fmt.Errorf("invalid map path %q", path)
+	}
+
+	var current any = variables
+	for i, key := range keys[1:] {
+		last := i == len(keys)-2
+		switch node := current.(type) {
+		case map[string]any:
+			if _, ok := node[key]; !ok {
+				return fmt.Errorf("invalid map path %q: %s not found", path, key)
+			}
+			if last {
+				node[key] = file
+				return nil
+			}
+			current = node[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return fmt.Errorf("invalid map path %q: index %s out of range", path, key)
+			}
+			if last {
+				node[index] = file
+				return nil
+			}
+			current = node[index]
+		default:
+			return fmt.Errorf("invalid map path %q", path)
+		}
+	}
+	return fmt.Errorf("invalid map path %q", path)
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,142 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+)
+
+const (
+	// typeConnectionAuth client refresh token of connection, payload is same as connection_init
+	typeConnectionAuth operationMessageType = "connection_auth"
+	// typeConnectionAuthAck server accept refreshed token
+	typeConnectionAuthAck operationMessageType = "connection_auth_ack"
+	// typeCloseConnection internal message, close connection after previous message is written
+	typeCloseConnection operationMessageType = "close"
+)
+
+var errMissingToken = errors.New("Missing token")
+
+// ExpiryPolicy action when token of authenticated connection is expired
+type ExpiryPolicy int
+
+const (
+	// ExpiryClose send connection_error and close connection
+	ExpiryClose ExpiryPolicy = iota
+	// ExpiryDegrade stop running operations with error, connection is kept open for refresh token
+	// with connection_auth message and start operation again
+	ExpiryDegrade
+)
+
+// authMessagePayload token in payload of connection_init and connection_auth message
+type authMessagePayload struct {
+	Authorization string `json:"Authorization"`
+	AuthToken     string `json:"authToken"`
+}
+
+func (p authMessagePayload) token() string {
+	if p.AuthToken != "" {
+		return p.AuthToken
+	}
+	if authType, token, ok := strings.Cut(p.Authorization, " "); ok && strings.EqualFold(authType, "Bearer") {
+		return token
+	}
+	return p.Authorization
+}
+
+// Auth authenticate connection with bearer token in payload of connection_init ("authToken" or "Authorization"
+// field, fallback to Authorization header of upgrade request), connection without token is anonymous. Token can be
+// refreshed with connection_auth message, and expired connection is closed or degraded by policy. Subscription
+// resolver get principal and change hook with candishared.ConnectionAuthFromContext
+func Auth(validate func(ctx context.Context, token string) (*candishared.TokenClaim, error), policy ExpiryPolicy, opts ...candishared.ConnectionAuthOption) func(conn *connection) {
+	return func(conn *connection) {
+		conn.auth = candishared.NewConnectionAuth(validate, opts...)
+		conn.expiryPolicy = policy
+	}
+}
+
+func (conn *connection) authenticate(ctx context.Context, payload authMessagePayload, fromHeader bool) error {
+	if conn.auth == nil {
+		return nil
+	}
+	token := payload.token()
+	if token == "" && fromHeader {
+		if header, ok := ctx.Value(candishared.ContextKeyHTTPHeader).(http.Header); ok {
+			token = authMessagePayload{Authorization: header.Get(candihelper.HeaderAuthorization)}.token()
+		}
+	}
+	if token == "" {
+		if fromHeader {
+			return nil
+		}
+		return errMissingToken
+	}
+	_, err := conn.auth.Authenticate(ctx, token)
+	return err
+}
+
+// operationContext context of operation with current token in authorization header (for @auth directive),
+// token claim and connection auth
+func (conn *connection) operationContext(ctx context.Context) context.Context {
+	if conn.auth == nil {
+		return ctx
+	}
+	ctx = candishared.SetConnectionAuthToContext(ctx, conn.auth)
+	token, claim := conn.auth.Token(), conn.auth.Claim()
+	if token == "" || claim == nil {
+		return ctx
+	}
+
+	header := make(http.Header)
+	if h, ok := ctx.Value(candishared.ContextKeyHTTPHeader).(http.Header); ok {
+		header = h.Clone()
+	}
+	header.Set(candihelper.HeaderAuthorization, "Bearer "+token)
+	ctx = candishared.SetToContext(ctx, candishared.ContextKeyHTTPHeader, header)
+	return candishared.SetToContext(ctx, candishared.ContextKeyTokenClaim, claim)
+}
+
+// watchExpiry enforce expiry policy when token of connection is expired
+func (conn *connection) watchExpiry(ctx context.Context, send sendFunc) {
+	renewed := make(chan struct{}, 1)
+	defer conn.auth.OnChange(func(prev, next *candishared.TokenClaim) {
+		select {
+		case renewed <- struct{}{}:
+		default:
+		}
+	})()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-conn.auth.Expired():
+		}
+
+		if conn.expiryPolicy == ExpiryClose {
+			send("", typeConnectionError, errPayload(candishared.ErrConnectionAuthExpired))
+			send("", typeCloseConnection, nil)
+			return
+		}
+
+		conn.mu.Lock()
+		operations := conn.operations
+		conn.operations = make(map[string]func())
+		conn.mu.Unlock()
+		for id, cancel := range operations {
+			cancel()
+			send(id, typeError, errPayload(candishared.ErrConnectionAuthExpired))
+			send(id, typeComplete, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-renewed:
+		}
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
+
+	"github.com/golangid/candi/candishared"
 )
 
 type operationMessageType string
@@ -47,8 +50,6 @@ type startMessagePayload struct {
 	Variables     map[string]any `json:"variables"`
 }
 
-type initMessagePayload struct{}
-
 // GraphQLService interface
 type GraphQLService interface {
 	Subscribe(ctx context.Context, document string, operationName string, variableValues map[string]any) (payloads <-chan any, err error)
@@ -59,8 +60,16 @@ type connection struct {
 	service      GraphQLService
 	writeTimeout time.Duration
 	ws           wsConnection
+	auth         *candishared.ConnectionAuth
+	expiryPolicy ExpiryPolicy
+
+	mu         sync.Mutex
+	operations map[string]func()
 }
 
+// Option connection option (ReadLimit, WriteTimeout, Auth)
+type Option = func(conn *connection)
+
 // ReadLimit limits the maximum size of incoming messages
 func ReadLimit(limit int64) func(conn *connection) {
 	return func(conn *connection) {
@@ -79,8 +88,9 @@ func WriteTimeout(d time.Duration) func(conn *connection) {
 // https://github.com/apollographql/subscriptions-transport-ws/blob/v0.9.4/PROTOCOL.md
 func Connect(ctx context.Context, ws wsConnection, service GraphQLService, options ...func(conn *connection)) func() {
 	conn := &connection{
-		service: service,
-		ws:      ws,
+		service:    service,
+		ws:         ws,
+		operations: make(map[string]func()),
 	}
 
 	defaultOpts := []func(conn *connection){
@@ -94,7 +104,11 @@ func Connect(ctx context.Context, ws wsConnection, service GraphQLService, optio
 
 	ctx, cancel := context.WithCancel(ctx)
 	conn.cancel = cancel
-	conn.readLoop(ctx, conn.writeLoop(ctx))
+	send := conn.writeLoop(ctx)
+	if conn.auth != nil {
+		go conn.watchExpiry(ctx, send)
+	}
+	conn.readLoop(ctx, send)
 
 	return cancel
 }
@@ -120,6 +134,9 @@ func (conn *connection) writeLoop(ctx context.Context) sendFunc {
 			case <-ctx.Done():
 				return
 			case msg := <-out:
+				if msg.Type == typeCloseConnection {
+					return
+				}
 				select {
 				case <-ctx.Done():
 					return
@@ -144,12 +161,14 @@ func (conn *connection) writeLoop(ctx context.Context) sendFunc {
 func (conn *connection) close() {
 	conn.cancel()
 	conn.ws.Close()
+	if conn.auth != nil {
+		conn.auth.Close()
+	}
 }
 
 func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 	defer conn.close()
 
-	opDone := map[string]func(){}
 	for {
 		var msg operationMessage
 		err := conn.ws.ReadJSON(&msg)
@@ -159,13 +178,33 @@ func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 
 		switch msg.Type {
 		case typeConnectionInit:
-			var initMsg initMessagePayload
-			if err := json.Unmarshal(msg.Payload, &initMsg); err != nil {
+			var initMsg authMessagePayload
+			if len(msg.Payload) > 0 {
+				if err := json.Unmarshal(msg.Payload, &initMsg); err != nil {
+					ep := errPayload(fmt.Errorf("invalid payload for type: %s", msg.Type))
+					send("", typeConnectionError, ep)
+					continue
+				}
+			}
+			if err := conn.authenticate(ctx, initMsg, true); err != nil {
+				send("", typeConnectionError, errPayload(err))
+				send("", typeCloseConnection, nil)
+				return
+			}
+			send("", typeConnectionAck, nil)
+
+		case typeConnectionAuth:
+			var authMsg authMessagePayload
+			if err := json.Unmarshal(msg.Payload, &authMsg); err != nil {
 				ep := errPayload(fmt.Errorf("invalid payload for type: %s", msg.Type))
 				send("", typeConnectionError, ep)
 				continue
 			}
-			send("", typeConnectionAck, nil)
+			if err := conn.authenticate(ctx, authMsg, false); err != nil {
+				send("", typeConnectionError, errPayload(err))
+				continue
+			}
+			send("", typeConnectionAuthAck, nil)
 
 		case typeStart:
 			// TODO: check an operation with the same ID hasn't been started already
@@ -182,7 +221,7 @@ func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 				continue
 			}
 
-			opCtx, cancel := context.WithCancel(ctx)
+			opCtx, cancel := context.WithCancel(conn.operationContext(ctx))
 			// TODO: timeout this call, to guard against poor clients
 			c, err := conn.service.Subscribe(opCtx, osp.Query, osp.OperationName, osp.Variables)
 			if err != nil {
@@ -192,7 +231,9 @@ func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 				continue
 			}
 
-			opDone[msg.ID] = cancel
+			conn.mu.Lock()
+			conn.operations[msg.ID] = cancel
+			conn.mu.Unlock()
 
 			go func() {
 				defer cancel()
@@ -217,9 +258,11 @@ func (conn *connection) readLoop(ctx context.Context, send sendFunc) {
 			}()
 
 		case typeStop:
-			onDone, ok := opDone[msg.ID]
+			conn.mu.Lock()
+			onDone, ok := conn.operations[msg.ID]
+			delete(conn.operations, msg.ID)
+			conn.mu.Unlock()
 			if ok {
-				delete(opDone, msg.ID)
 				onDone()
 			}
 			send(msg.ID, typeComplete, nil)
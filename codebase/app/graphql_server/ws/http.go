@@ -19,8 +19,8 @@ var upgrader = websocket.Upgrader{
 	Subprotocols: []string{protocolGraphQLWS},
 }
 
-// NewHandlerFunc returns an http.HandlerFunc that supports GraphQL over websockets
-func NewHandlerFunc(svc GraphQLService, httpHandler http.Handler) http.HandlerFunc {
+// NewHandlerFunc returns an http.HandlerFunc that supports GraphQL over websockets, options is applied to each connection
+func NewHandlerFunc(svc GraphQLService, httpHandler http.Handler, options ...func(conn *connection)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		// handle cors
@@ -45,7 +45,7 @@ func NewHandlerFunc(svc GraphQLService, httpHandler http.Handler) http.HandlerFu
 				}
 
 				ctx := candishared.SetToContext(context.Background(), candishared.ContextKeyHTTPHeader, r.Header)
-				go Connect(ctx, ws, svc)
+				go Connect(ctx, ws, svc, options...)
 				return
 			}
 		}
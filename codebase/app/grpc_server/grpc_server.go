@@ -7,11 +7,15 @@ import (
 	"log"
 	"net"
 
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candiutils/graceful"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
 	"github.com/golangid/candi/logger"
 	"github.com/soheilhy/cmux"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 type grpcServer struct {
@@ -23,31 +27,18 @@ type grpcServer struct {
 
 // NewServer create new GRPC server
 func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppServerFactory {
-	intercept := new(interceptor)
-	serverOpt := getDefaultOption()
-	for _, opt := range opts {
-		opt(&serverOpt)
-	}
-	serverOpt.serverOptions = append(serverOpt.serverOptions,
-		grpc.UnaryInterceptor(chainUnaryServer(
-			intercept.unaryTracerInterceptor,
-			intercept.unaryMiddlewareInterceptor,
-		)),
-		grpc.StreamInterceptor(chainStreamServer(
-			intercept.streamTracerInterceptor,
-			intercept.streamMiddlewareInterceptor,
-		)))
-
 	server := &grpcServer{
-		serverEngine: grpc.NewServer(serverOpt.serverOptions...),
-		service:      service,
-		opt:          serverOpt,
+		service: service,
+		opt:     getDefaultOption(),
+	}
+	for _, opt := range opts {
+		opt(&server.opt)
 	}
 
 	grpcPort := server.opt.tcpPort
 	if server.opt.sharedListener == nil {
 		var err error
-		server.listener, err = net.Listen("tcp", grpcPort)
+		server.listener, err = graceful.Listen("tcp", grpcPort)
 		if err != nil {
 			panic(err)
 		}
@@ -64,13 +55,13 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 	}
 
 	// register all module
-	intercept.middleware = make(types.MiddlewareGroup)
-	intercept.opt = &server.opt
+	var handlers []interfaces.GRPCHandler
 	for _, m := range service.GetModules() {
 		if h := m.GRPCHandler(); h != nil {
-			h.Register(server.serverEngine, &intercept.middleware)
+			handlers = append(handlers, h)
 		}
 	}
+	server.serverEngine = newServerEngine(&server.opt, handlers)
 
 	for root, info := range server.serverEngine.GetServiceInfo() {
 		for _, method := range info.Methods {
@@ -82,6 +73,42 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 	return server
 }
 
+// NewServerEngine create grpc server engine with candi interceptors (tracer, middleware group, and error handling)
+// and register handlers, without listener (example for serve handler in test with bufconn)
+func NewServerEngine(handlers []interfaces.GRPCHandler, opts ...OptionFunc) *grpc.Server {
+	serverOpt := getDefaultOption()
+	for _, opt := range opts {
+		opt(&serverOpt)
+	}
+	return newServerEngine(&serverOpt, handlers)
+}
+
+func newServerEngine(opt *option, handlers []interfaces.GRPCHandler) *grpc.Server {
+	intercept := &interceptor{
+		middleware: make(types.MiddlewareGroup), opt: opt, methodMaxRecvSize: candihelper.NewPatternMatcher(opt.methodMaxRecvSize),
+	}
+	serverOptions := append(opt.serverOptions[:len(opt.serverOptions):len(opt.serverOptions)],
+		grpc.UnaryInterceptor(chainUnaryServer(append([]grpc.UnaryServerInterceptor{
+			intercept.unaryPayloadLimitInterceptor,
+			intercept.unaryTracerInterceptor,
+			intercept.unaryMiddlewareInterceptor,
+		}, opt.unaryInterceptors...)...)),
+		grpc.StreamInterceptor(chainStreamServer(
+			intercept.streamPayloadLimitInterceptor,
+			intercept.streamTracerInterceptor,
+			intercept.streamMiddlewareInterceptor,
+		)))
+
+	serverEngine := grpc.NewServer(serverOptions...)
+	for _, h := range handlers {
+		h.Register(serverEngine, &intercept.middleware)
+	}
+	if opt.reflection {
+		reflection.Register(serverEngine)
+	}
+	return serverEngine
+}
+
 func (s *grpcServer) Serve() {
 	if err := s.serverEngine.Serve(s.listener); err != nil {
 		log.Println("GRPC: Unexpected Error", err)
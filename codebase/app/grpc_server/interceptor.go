@@ -7,19 +7,24 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/i18n"
 	"github.com/golangid/candi/tracer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type interceptor struct {
-	middleware types.MiddlewareGroup
-	opt        *option
+	middleware        types.MiddlewareGroup
+	opt               *option
+	methodMaxRecvSize *candihelper.PatternMatcher[int]
 }
 
 // for unary server
@@ -70,7 +75,9 @@ func (i *interceptor) unaryTracerInterceptor(ctx context.Context, req any, info
 	defer func() {
 		if rec := recover(); rec != nil {
 			trace.SetTag("panic", true)
-			err = status.Errorf(codes.Aborted, "%v", rec)
+			err = status.Error(codes.Aborted, candiutils.ReportPanic(ctx, "GRPC-Server", rec, map[string]any{
+				"method": info.FullMethod, "metadata": candiutils.RedactHeader(meta),
+			}).Error())
 		}
 		i.logInterceptor(start, err, info.FullMethod, "GRPC")
 		trace.Finish(tracer.FinishWithError(err))
@@ -96,11 +103,11 @@ func (i *interceptor) unaryTracerInterceptor(ctx context.Context, req any, info
 func (i *interceptor) unaryMiddlewareInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 	ctx, err = i.middlewareInterceptor(ctx, info.FullMethod)
 	if err != nil {
-		return nil, err
+		return nil, candierrors.ToGRPCStatus(err)
 	}
 
 	resp, err = handler(ctx, req)
-	return
+	return resp, candierrors.ToGRPCStatus(err)
 }
 
 // for stream server
@@ -153,7 +160,9 @@ func (i *interceptor) streamTracerInterceptor(srv any, stream grpc.ServerStream,
 	defer func() {
 		if rec := recover(); rec != nil {
 			trace.SetTag("panic", true)
-			err = status.Errorf(codes.Aborted, "%v", rec)
+			err = status.Error(codes.Aborted, candiutils.ReportPanic(ctx, "GRPC-STREAM", rec, map[string]any{
+				"method": info.FullMethod, "metadata": candiutils.RedactHeader(meta),
+			}).Error())
 		}
 		i.logInterceptor(start, err, info.FullMethod, "GRPC-STREAM")
 		trace.Finish(tracer.FinishWithError(err))
@@ -168,15 +177,31 @@ func (i *interceptor) streamTracerInterceptor(srv any, stream grpc.ServerStream,
 func (i *interceptor) streamMiddlewareInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
 	ctx, err := i.middlewareInterceptor(stream.Context(), info.FullMethod)
 	if err != nil {
-		return err
+		return candierrors.ToGRPCStatus(err)
 	}
 
-	return handler(srv, &wrappedServerStream{ServerStream: stream, wrappedContext: ctx})
+	return candierrors.ToGRPCStatus(handler(srv, &wrappedServerStream{ServerStream: stream, wrappedContext: ctx}))
 }
 
 func (i *interceptor) middlewareInterceptor(ctx context.Context, fullMethod string) (context.Context, error) {
 	var err error
 
+	if meta, ok := metadata.FromIncomingContext(ctx); ok {
+		if acceptLanguage := meta.Get(candihelper.HeaderAcceptLanguage); len(acceptLanguage) > 0 {
+			ctx = i18n.SetLocaleFromAcceptLanguage(ctx, acceptLanguage[0])
+		}
+		if baggage := meta.Get(candishared.HeaderBaggage); len(baggage) > 0 {
+			ctx = candishared.ExtractBaggage(ctx, map[string]string{candishared.HeaderBaggage: baggage[0]})
+		}
+	}
+	var requestID string
+	if meta, _ := metadata.FromIncomingContext(ctx); len(meta.Get(candihelper.HeaderXRequestID)) > 0 {
+		requestID = meta.Get(candihelper.HeaderXRequestID)[0]
+	}
+	ctx, requestID = candishared.ExtractRequestID(ctx, map[string]string{candihelper.HeaderXRequestID: requestID})
+	grpc.SetHeader(ctx, metadata.Pairs(candihelper.HeaderXRequestID, requestID))
+	tracer.Log(ctx, "request_id", requestID)
+
 	if middFunc, ok := i.middleware[fullMethod]; ok {
 		for _, mw := range middFunc {
 			ctx, err = mw(ctx)
@@ -216,6 +241,49 @@ func (i *interceptor) logInterceptor(startTime time.Time, err error, fullMethod
 	)
 }
 
+// checkPayloadSize reject message larger than max receive size of method
+func (i *interceptor) checkPayloadSize(fullMethod string, msg any) error {
+	maxSize, ok := i.methodMaxRecvSize.Match(fullMethod)
+	if !ok || maxSize <= 0 {
+		return nil
+	}
+	if m, ok := msg.(proto.Message); ok {
+		if size := proto.Size(m); size > maxSize {
+			return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)", size, maxSize)
+		}
+	}
+	return nil
+}
+
+func (i *interceptor) unaryPayloadLimitInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := i.checkPayloadSize(info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *interceptor) streamPayloadLimitInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if _, ok := i.methodMaxRecvSize.Match(info.FullMethod); !ok {
+		return handler(srv, stream)
+	}
+	return handler(srv, &payloadLimitServerStream{ServerStream: stream, fullMethod: info.FullMethod, intercept: i})
+}
+
+// payloadLimitServerStream check size of each received stream message
+type payloadLimitServerStream struct {
+	grpc.ServerStream
+	fullMethod string
+	intercept  *interceptor
+}
+
+// RecvMsg receive message and check size
+func (s *payloadLimitServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.intercept.checkPayloadSize(s.fullMethod, m)
+}
+
 // wrappedServerStream for inject custom context and wrap stream server
 type wrappedServerStream struct {
 	grpc.ServerStream
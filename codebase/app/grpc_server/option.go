@@ -19,6 +19,9 @@ type (
 		sharedListener      cmux.CMux
 		serverOptions       []grpc.ServerOption
 		tlsConfig           *tls.Config
+		reflection          bool
+		unaryInterceptors   []grpc.UnaryServerInterceptor
+		methodMaxRecvSize   map[string]int
 	}
 
 	// OptionFunc type
@@ -86,3 +89,36 @@ func SetTLSConfig(tlsConfig *tls.Config) OptionFunc {
 		o.tlsConfig = tlsConfig
 	}
 }
+
+// SetReflection option func, register grpc server reflection service for dynamic client
+// (example: "candi grpc call"), default disabled because reflection expose all service schema
+func SetReflection(enable bool) OptionFunc {
+	return func(o *option) {
+		o.reflection = enable
+	}
+}
+
+// AddUnaryInterceptors option func, add unary interceptors after candi interceptors (tracer and middleware group),
+// example: deadline.GRPCUnaryInterceptor
+func AddUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) OptionFunc {
+	return func(o *option) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// SetMaxRecvMsgSize option func, max size of received message in bytes for all method (default 200MB),
+// larger message is rejected with ResourceExhausted before decoded
+func SetMaxRecvMsgSize(size int) OptionFunc {
+	return func(o *option) {
+		o.serverOptions = append(o.serverOptions, grpc.MaxRecvMsgSize(size))
+	}
+}
+
+// SetMethodMaxRecvMsgSize option func, max size of received message in bytes of method matched with glob pattern of
+// full method (example: "/payment.PaymentService/*"), lower than SetMaxRecvMsgSize for restrict method. Larger message
+// (each message for stream) is rejected with ResourceExhausted before handler called, longest pattern is matched first
+func SetMethodMaxRecvMsgSize(methodMaxRecvSize map[string]int) OptionFunc {
+	return func(o *option) {
+		o.methodMaxRecvSize = methodMaxRecvSize
+	}
+}
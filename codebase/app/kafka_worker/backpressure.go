@@ -0,0 +1,112 @@
+package kafkaworker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golangid/candi/logger"
+)
+
+const (
+	backpressureCheckInterval = time.Second
+	backpressureMinPause      = 5 * time.Second
+	// weight of latest handler latency in moving average
+	backpressureLatencyWeight = 0.2
+)
+
+// backpressure pause fetching of all claimed partitions when in-flight handler or handler latency (moving average)
+// exceed threshold, resumed when in-flight handler drop to half of threshold after minimum pause duration
+type backpressure struct {
+	maxInFlight int64
+	maxLatency  time.Duration
+
+	inFlight atomic.Int64
+	mu       sync.Mutex
+	latency  time.Duration
+	paused   bool
+	pausedAt time.Time
+}
+
+type partitionPauser interface {
+	PauseAll()
+	ResumeAll()
+}
+
+func (b *backpressure) enabled() bool {
+	return b.maxInFlight > 0 || b.maxLatency > 0
+}
+
+func (b *backpressure) begin() {
+	b.inFlight.Add(1)
+}
+
+func (b *backpressure) done(latency time.Duration) {
+	b.inFlight.Add(-1)
+	b.mu.Lock()
+	if b.latency == 0 {
+		b.latency = latency
+	} else {
+		b.latency = time.Duration(backpressureLatencyWeight*float64(latency) + (1-backpressureLatencyWeight)*float64(b.latency))
+	}
+	b.mu.Unlock()
+}
+
+// evaluate decide pause or resume at now, reason is not empty when should pause
+func (b *backpressure) evaluate(now time.Time) (pause, resume bool, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inFlight := b.inFlight.Load()
+
+	if !b.paused {
+		switch {
+		case b.maxInFlight > 0 && inFlight >= b.maxInFlight:
+			reason = "inflight"
+		case b.maxLatency > 0 && b.latency > b.maxLatency:
+			reason = "latency"
+		default:
+			return false, false, ""
+		}
+		b.paused, b.pausedAt = true, now
+		return true, false, reason
+	}
+
+	if now.Sub(b.pausedAt) < backpressureMinPause || (b.maxInFlight > 0 && inFlight > b.maxInFlight/2) {
+		return false, false, ""
+	}
+	// latency re-measured from next handled messages
+	b.paused, b.latency = false, 0
+	return false, true, ""
+}
+
+func (b *backpressure) run(ctx context.Context, pauser partitionPauser, metrics *consumerMetrics, workerType string) {
+	ticker := time.NewTicker(backpressureCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			pause, resume, reason := b.evaluate(now)
+			switch {
+			case pause:
+				pauser.PauseAll()
+				metrics.recordPaused(ctx, reason)
+				logger.LogYellow(fmt.Sprintf("Kafka Consumer%s: backpressure (%s), pause fetching", workerType, reason))
+			case resume:
+				pauser.ResumeAll()
+				logger.LogYellow(fmt.Sprintf("Kafka Consumer%s: backpressure released, resume fetching", workerType))
+			default:
+				b.mu.Lock()
+				paused := b.paused
+				b.mu.Unlock()
+				if paused {
+					// partition claimed after rebalance is not paused
+					pauser.PauseAll()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package kafkaworker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func TestBackpressure(t *testing.T) {
+	bp := &backpressure{maxInFlight: 4, maxLatency: 100 * time.Millisecond}
+	now := time.Now()
+
+	for range 4 {
+		bp.begin()
+	}
+	pause, _, reason := bp.evaluate(now)
+	assert.True(t, pause)
+	assert.Equal(t, "inflight", reason)
+
+	bp.done(10 * time.Millisecond)
+	_, resume, _ := bp.evaluate(now.Add(backpressureMinPause))
+	assert.False(t, resume, "in-flight still above half of threshold")
+	bp.done(10 * time.Millisecond)
+	_, resume, _ = bp.evaluate(now.Add(backpressureMinPause - time.Second))
+	assert.False(t, resume, "minimum pause duration")
+	_, resume, _ = bp.evaluate(now.Add(backpressureMinPause))
+	assert.True(t, resume)
+
+	bp.begin()
+	bp.done(time.Second)
+	pause, _, reason = bp.evaluate(now.Add(backpressureMinPause))
+	assert.True(t, pause)
+	assert.Equal(t, "latency", reason)
+
+	m := newConsumerMetrics(noop.NewMeterProvider(), "group", bp)
+	m.recordLag("topic", 0, 10, 4)
+	m.recordLag("topic", 1, 10, 9)
+	assert.Equal(t, map[string]map[int32]int64{"topic": {0: 5, 1: 0}}, m.lag())
+	m.removeLag("topic", 0)
+	assert.Equal(t, map[string]map[int32]int64{"topic": {1: 0}}, m.lag())
+}
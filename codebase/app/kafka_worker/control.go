@@ -0,0 +1,146 @@
+package kafkaworker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/control"
+	"github.com/golangid/candi/logger"
+)
+
+// topicPause pause fetching of topic with control command, pause is re-applied to partition claimed after rebalance
+// and after backpressure resume fetching
+type topicPause struct {
+	consumer sarama.ConsumerGroup
+	topics   map[string]bool
+
+	mu     sync.Mutex
+	paused map[string]bool
+	claims map[string]map[int32]int
+}
+
+func newTopicPause(consumer sarama.ConsumerGroup, topics []string) *topicPause {
+	p := &topicPause{
+		consumer: consumer,
+		topics:   make(map[string]bool, len(topics)),
+		paused:   make(map[string]bool),
+		claims:   make(map[string]map[int32]int),
+	}
+	for _, topic := range topics {
+		p.topics[topic] = true
+	}
+	return p
+}
+
+// claim track partition claimed by current session, claimed partition of paused topic is paused
+func (p *topicPause) claim(topic string, partition int32) (release func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.claims[topic] == nil {
+		p.claims[topic] = make(map[int32]int)
+	}
+	p.claims[topic][partition]++
+	if p.paused[topic] {
+		p.consumer.Pause(map[string][]int32{topic: {partition}})
+	}
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.claims[topic][partition]--; p.claims[topic][partition] <= 0 {
+			delete(p.claims[topic], partition)
+		}
+	}
+}
+
+func (p *topicPause) claimedPartitions(topic string) map[string][]int32 {
+	partitions := make([]int32, 0, len(p.claims[topic]))
+	for partition := range p.claims[topic] {
+		partitions = append(partitions, partition)
+	}
+	return map[string][]int32{topic: partitions}
+}
+
+func (p *topicPause) pause(topic string) error {
+	if !p.topics[topic] {
+		return fmt.Errorf("%w: topic %s is not consumed", control.ErrInvalidArgument, topic)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused[topic] = true
+	p.consumer.Pause(p.claimedPartitions(topic))
+	return nil
+}
+
+func (p *topicPause) resume(topic string) error {
+	if !p.topics[topic] {
+		return fmt.Errorf("%w: topic %s is not consumed", control.ErrInvalidArgument, topic)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.paused, topic)
+	p.consumer.Resume(p.claimedPartitions(topic))
+	return nil
+}
+
+// PauseAll implement partitionPauser
+func (p *topicPause) PauseAll() {
+	p.consumer.PauseAll()
+}
+
+// ResumeAll implement partitionPauser, topic paused with control command is kept paused
+func (p *topicPause) ResumeAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.consumer.ResumeAll()
+	for topic := range p.paused {
+		p.consumer.Pause(p.claimedPartitions(topic))
+	}
+}
+
+type topicState struct {
+	Topic      string  `json:"topic"`
+	Paused     bool    `json:"paused"`
+	Partitions []int32 `json:"partitions"`
+}
+
+func (p *topicPause) list() []topicState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	states := make([]topicState, 0, len(p.topics))
+	for topic := range p.topics {
+		partitions := p.claimedPartitions(topic)[topic]
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+		states = append(states, topicState{Topic: topic, Paused: p.paused[topic], Partitions: partitions})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Topic < states[j].Topic })
+	return states
+}
+
+// commands control command of consumer, prefixed with worker type (example "kafka.pause")
+func (p *topicPause) commands(prefix, workerTypeLog string) []control.Command {
+	return []control.Command{
+		{Name: prefix + ".topics", Description: "List consumed topic with pause state and claimed partitions",
+			Handler: func(ctx context.Context, args map[string]string) (any, error) {
+				return p.list(), nil
+			}},
+		{Name: prefix + ".pause", Description: "Pause consumer of topic", Args: []string{"topic"},
+			Handler: func(ctx context.Context, args map[string]string) (any, error) {
+				if err := p.pause(args["topic"]); err != nil {
+					return nil, err
+				}
+				logger.LogYellow(fmt.Sprintf("Kafka Consumer%s: topic %s paused by control command", workerTypeLog, args["topic"]))
+				return topicState{Topic: args["topic"], Paused: true}, nil
+			}},
+		{Name: prefix + ".resume", Description: "Resume consumer of paused topic", Args: []string{"topic"},
+			Handler: func(ctx context.Context, args map[string]string) (any, error) {
+				if err := p.resume(args["topic"]); err != nil {
+					return nil, err
+				}
+				logger.LogYellow(fmt.Sprintf("Kafka Consumer%s: topic %s resumed by control command", workerTypeLog, args["topic"]))
+				return topicState{Topic: args["topic"], Paused: false}, nil
+			}},
+	}
+}
@@ -0,0 +1,53 @@
+package kafkaworker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/control"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConsumerGroup struct {
+	sarama.ConsumerGroup
+	calls []string
+}
+
+func (f *fakeConsumerGroup) Pause(partitions map[string][]int32) {
+	f.calls = append(f.calls, fmt.Sprint("pause ", partitions))
+}
+func (f *fakeConsumerGroup) Resume(partitions map[string][]int32) {
+	f.calls = append(f.calls, fmt.Sprint("resume ", partitions))
+}
+func (f *fakeConsumerGroup) PauseAll()  { f.calls = append(f.calls, "pause all") }
+func (f *fakeConsumerGroup) ResumeAll() { f.calls = append(f.calls, "resume all") }
+
+func TestTopicPause(t *testing.T) {
+	consumer := &fakeConsumerGroup{}
+	p := newTopicPause(consumer, []string{"orders", "payments"})
+	control.Register(p.commands("kafka", "")...)
+
+	release := p.claim("orders", 0)
+	_, err := control.Exec(context.Background(), "kafka.pause", map[string]string{"topic": "orders"})
+	require.NoError(t, err)
+	_, err = control.Exec(context.Background(), "kafka.pause", map[string]string{"topic": "unknown"})
+	assert.ErrorIs(t, err, control.ErrInvalidArgument)
+
+	// partition claimed after rebalance and backpressure resume keep paused topic paused
+	release()
+	p.claim("orders", 1)
+	p.ResumeAll()
+	assert.Equal(t, []string{"pause map[orders:[0]]", "pause map[orders:[1]]", "resume all", "pause map[orders:[1]]"}, consumer.calls)
+
+	result, err := control.Exec(context.Background(), "kafka.topics", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []topicState{{Topic: "orders", Paused: true, Partitions: []int32{1}}, {Topic: "payments", Partitions: []int32{}}}, result)
+
+	consumer.calls = nil
+	require.NoError(t, p.resume("orders"))
+	p.claim("orders", 2)
+	assert.Equal(t, []string{"resume map[orders:[1]]"}, consumer.calls)
+}
@@ -1,7 +1,6 @@
 package kafkaworker
 
 import (
-	"fmt"
 	"log"
 	"strconv"
 	"strings"
@@ -10,7 +9,9 @@ import (
 
 	"github.com/IBM/sarama"
 	"github.com/golangid/candi/broker"
+	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/tracer"
 )
@@ -23,10 +24,15 @@ type consumerHandler struct {
 	handlerFuncs map[string]types.WorkerHandler
 	ready        chan struct{}
 	messagePool  sync.Pool
+	slowStart    *candiutils.SlowStart
+	backpressure *backpressure
+	metrics      *consumerMetrics
+	topicPause   *topicPause
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim
 func (c *consumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	c.slowStart.Reset()
 	close(c.ready)
 	return nil
 }
@@ -38,10 +44,24 @@ func (c *consumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
 
 // ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages().
 func (c *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	defer c.metrics.removeLag(claim.Topic(), claim.Partition())
+	if c.topicPause != nil {
+		defer c.topicPause.claim(claim.Topic(), claim.Partition())()
+	}
 	for {
 		select {
 		case message := <-claim.Messages():
+			if err := c.slowStart.Acquire(session.Context()); err != nil {
+				return nil
+			}
+			c.backpressure.begin()
+			start := time.Now()
 			c.processMessage(session, message)
+			c.backpressure.done(time.Since(start))
+			c.slowStart.Release()
+			if message != nil {
+				c.metrics.recordLag(message.Topic, message.Partition, claim.HighWaterMarkOffset(), message.Offset)
+			}
 
 		case <-session.Context().Done():
 			return nil
@@ -72,13 +92,20 @@ func (c *consumerHandler) processMessage(session sarama.ConsumerGroupSession, me
 	for _, val := range message.Headers {
 		header[string(val.Key)] = string(val.Value)
 	}
+	if tenantID := header[candihelper.HeaderXTenantID]; tenantID != "" {
+		ctx = candishared.SetTenantToContext(ctx, tenantID)
+	}
+	ctx = candishared.ExtractBaggage(ctx, header)
+	ctx, _ = candishared.ExtractRequestID(ctx, header)
 
 	var err error
 	trace, ctx := tracer.StartTraceFromHeader(ctx, "KafkaConsumer", header)
 	defer func() {
 		if r := recover(); r != nil {
 			trace.SetTag("panic", true)
-			err = fmt.Errorf("%v", r)
+			err = candiutils.ReportPanic(ctx, "KafkaConsumer", r, map[string]any{
+				"topic": message.Topic, "partition": message.Partition, "offset": message.Offset, "key": string(message.Key),
+			})
 		}
 		if handler.AutoACK {
 			session.MarkMessage(message, "")
@@ -108,7 +135,12 @@ func (c *consumerHandler) processMessage(session sarama.ConsumerGroupSession, me
 	eventContext.SetHandlerRoute(message.Topic)
 	eventContext.SetHeader(header)
 	eventContext.SetKey(string(message.Key))
-	eventContext.Write(message.Value)
+	if cloudEvent, data, ok := candishared.ParseCloudEvent(header, message.Value); ok {
+		eventContext.SetCloudEvent(cloudEvent)
+		eventContext.Write(data)
+	} else {
+		eventContext.Write(message.Value)
+	}
 
 	for _, handlerFunc := range handler.HandlerFuncs {
 		err = handlerFunc(eventContext)
@@ -13,9 +13,12 @@ import (
 	"github.com/golangid/candi/broker"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/contract"
+	"github.com/golangid/candi/control"
 	"github.com/golangid/candi/logger"
 )
 
@@ -60,6 +63,8 @@ func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...Opt
 		if h := m.WorkerHandler(worker.bk.WorkerType); h != nil {
 			var handlerGroup types.WorkerHandlerGroup
 			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(worker.bk.WorkerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
 			for _, handler := range handlerGroup.Handlers {
 				if _, ok := consumerHandler.handlerFuncs[handler.Pattern]; ok {
 					logger.LogYellow(fmt.Sprintf("Kafka: warning, topic %s has been used in another module, overwrite handler func", handler.Pattern))
@@ -75,12 +80,22 @@ func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...Opt
 
 	consumerHandler.ready = make(chan struct{})
 	consumerHandler.opt = &worker.opt
+	if worker.opt.slowStartWindow > 0 {
+		consumerHandler.slowStart = candiutils.NewSlowStart(worker.opt.slowStartInitial, worker.opt.maxGoroutines, worker.opt.slowStartWindow)
+	}
+	consumerHandler.backpressure = &backpressure{
+		maxInFlight: int64(worker.opt.backpressureMaxInFlight), maxLatency: worker.opt.backpressureMaxLatency,
+	}
+	consumerHandler.metrics = newConsumerMetrics(worker.opt.meterProvider, worker.opt.consumerGroup, consumerHandler.backpressure)
 	consumerHandler.messagePool = sync.Pool{
 		New: func() any {
 			return candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, 256)))
 		},
 	}
 
+	consumerHandler.topicPause = newTopicPause(consumerEngine, consumerHandler.topics)
+	control.Register(consumerHandler.topicPause.commands(string(kafkaBroker.WorkerType), getWorkerTypeLog(kafkaBroker.WorkerType))...)
+
 	worker.engine = consumerEngine
 	worker.consumerHandler = &consumerHandler
 	return worker
@@ -90,6 +105,10 @@ func (h *kafkaWorker) Serve() {
 	ctx, cancel := context.WithCancel(context.Background())
 	h.cancelFunc = cancel
 
+	if h.consumerHandler.backpressure.enabled() {
+		go h.consumerHandler.backpressure.run(ctx, h.consumerHandler.topicPause, h.consumerHandler.metrics, getWorkerTypeLog(h.bk.WorkerType))
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -0,0 +1,94 @@
+package kafkaworker
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/golangid/candi/codebase/app/kafka_worker"
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// consumerMetrics consumer lag per claimed topic/partition, in-flight handler and backpressure pause state
+type consumerMetrics struct {
+	consumerGroup string
+	mu            sync.RWMutex
+	lags          map[topicPartition]int64
+	backpressure  *backpressure
+	paused        metric.Int64Counter
+}
+
+func newConsumerMetrics(provider metric.MeterProvider, consumerGroup string, bp *backpressure) *consumerMetrics {
+	m := &consumerMetrics{consumerGroup: consumerGroup, lags: make(map[topicPartition]int64), backpressure: bp}
+	meter := provider.Meter(meterName)
+	meter.Int64ObservableGauge("candi.kafka.consumer.lag",
+		metric.WithDescription("Number of messages behind high water mark of claimed topic partition"),
+		metric.WithInt64Callback(m.observeLag),
+	)
+	meter.Int64ObservableGauge("candi.kafka.consumer.inflight",
+		metric.WithDescription("Number of message being processed by handler"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(bp.inFlight.Load(), metric.WithAttributes(attribute.String("consumer_group", consumerGroup)))
+			return nil
+		}),
+	)
+	m.paused, _ = meter.Int64Counter("candi.kafka.consumer.backpressure.paused",
+		metric.WithDescription("Number of consumer fetching paused by backpressure, reason: inflight or latency"),
+	)
+	return m
+}
+
+func (m *consumerMetrics) observeLag(ctx context.Context, o metric.Int64Observer) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for tp, lag := range m.lags {
+		o.Observe(lag, metric.WithAttributes(
+			attribute.String("consumer_group", m.consumerGroup), attribute.String("topic", tp.topic), attribute.Int("partition", int(tp.partition)),
+		))
+	}
+	return nil
+}
+
+// recordLag lag of partition after message with offset has been consumed
+func (m *consumerMetrics) recordLag(topic string, partition int32, highWaterMark, offset int64) {
+	lag := max(highWaterMark-offset-1, 0)
+	m.mu.Lock()
+	m.lags[topicPartition{topic, partition}] = lag
+	m.mu.Unlock()
+}
+
+// removeLag remove lag of partition no longer claimed by this consumer (rebalance)
+func (m *consumerMetrics) removeLag(topic string, partition int32) {
+	m.mu.Lock()
+	delete(m.lags, topicPartition{topic, partition})
+	m.mu.Unlock()
+}
+
+// lag snapshot of consumer lag per claimed partition
+func (m *consumerMetrics) lag() map[string]map[int32]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	lags := make(map[string]map[int32]int64)
+	for tp, lag := range m.lags {
+		if lags[tp.topic] == nil {
+			lags[tp.topic] = make(map[int32]int64)
+		}
+		lags[tp.topic][tp.partition] = lag
+	}
+	return lags
+}
+
+func (m *consumerMetrics) recordPaused(ctx context.Context, reason string) {
+	if m.paused == nil {
+		return
+	}
+	m.paused.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("consumer_group", m.consumerGroup), attribute.String("reason", reason),
+	))
+}
@@ -1,10 +1,24 @@
 package kafkaworker
 
+import (
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
 type (
 	option struct {
 		consumerGroup string
 		maxGoroutines int
 		debugMode     bool
+
+		slowStartInitial int
+		slowStartWindow  time.Duration
+
+		meterProvider           metric.MeterProvider
+		backpressureMaxInFlight int
+		backpressureMaxLatency  time.Duration
 	}
 
 	// OptionFunc type
@@ -15,6 +29,7 @@ func getDefaultOption() option {
 	return option{
 		maxGoroutines: 10,
 		debugMode:     true,
+		meterProvider: otel.GetMeterProvider(),
 	}
 }
 
@@ -38,3 +53,28 @@ func SetConsumerGroup(consumerGroup string) OptionFunc {
 		o.consumerGroup = consumerGroup
 	}
 }
+
+// SetSlowStart option func, ramp concurrency from initial to max goroutines over warm-up window after startup or consumer
+// group rebalance, concurrency shared by all claimed partitions
+func SetSlowStart(initial int, window time.Duration) OptionFunc {
+	return func(o *option) {
+		o.slowStartInitial, o.slowStartWindow = initial, window
+	}
+}
+
+// SetMeterProvider option func, set OpenTelemetry meter provider for consumer lag, in-flight and backpressure metrics
+// (default global meter provider)
+func SetMeterProvider(meterProvider metric.MeterProvider) OptionFunc {
+	return func(o *option) {
+		o.meterProvider = meterProvider
+	}
+}
+
+// SetBackpressure option func, pause fetching of all claimed partitions when number of in-flight handler reach maxInFlight
+// or moving average of handler latency exceed maxLatency (zero value disable the threshold), fetching resumed automatically
+// after in-flight handler drop to half of maxInFlight
+func SetBackpressure(maxInFlight int, maxLatency time.Duration) OptionFunc {
+	return func(o *option) {
+		o.backpressureMaxInFlight, o.backpressureMaxLatency = maxInFlight, maxLatency
+	}
+}
@@ -0,0 +1,316 @@
+package kafkaworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/logger"
+)
+
+type (
+	// TableStore pluggable key-value storage for compacted topic table, must be safe for concurrent use
+	TableStore interface {
+		Get(key string) ([]byte, bool)
+		Set(key string, value []byte)
+		Delete(key string)
+		Range(fn func(key string, value []byte) bool)
+		Len() int
+	}
+
+	// TableChangeFunc callback when key in table changed, newValue is nil if key deleted (tombstone message)
+	TableChangeFunc func(key string, oldValue, newValue []byte)
+
+	// TableOptionFunc type
+	TableOptionFunc func(*Table)
+
+	// Table materialize compacted topic into key-value table, message key as table key
+	// and message value as table value, message with nil value (tombstone) delete key from table
+	Table struct {
+		mu        sync.RWMutex
+		client    sarama.Client
+		consumer  sarama.Consumer
+		topic     string
+		store     TableStore
+		onChanges []TableChangeFunc
+		offsets   map[int32]int64
+		ready     chan struct{}
+		readyOnce sync.Once
+		cancel    context.CancelFunc
+		wg        sync.WaitGroup
+	}
+
+	tableSnapshot struct {
+		Topic   string            `json:"topic"`
+		Offsets map[int32]int64   `json:"offsets"`
+		Entries map[string][]byte `json:"entries"`
+	}
+)
+
+// TableOptionStore option func, set custom table store (default in memory)
+func TableOptionStore(store TableStore) TableOptionFunc {
+	return func(t *Table) {
+		t.store = store
+	}
+}
+
+// TableOptionOnChange option func, add change callback
+func TableOptionOnChange(onChange TableChangeFunc) TableOptionFunc {
+	return func(t *Table) {
+		t.onChanges = append(t.onChanges, onChange)
+	}
+}
+
+// NewTable create compacted topic table, call Start to consume topic
+func NewTable(client sarama.Client, topic string, opts ...TableOptionFunc) *Table {
+	t := &Table{
+		client:  client,
+		topic:   topic,
+		store:   NewInMemTableStore(),
+		offsets: make(map[int32]int64),
+		ready:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Start consume all partition of topic in background, continue from restored snapshot offset (if any) or oldest offset
+func (t *Table) Start(ctx context.Context) (err error) {
+	t.consumer, err = sarama.NewConsumerFromClient(t.client)
+	if err != nil {
+		return err
+	}
+	ctx, t.cancel = context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			// stop partition consumer which already started
+			t.cancel()
+			t.wg.Wait()
+			t.consumer.Close()
+			t.cancel = nil
+		}
+	}()
+
+	partitions, err := t.client.Partitions(t.topic)
+	if err != nil {
+		return err
+	}
+
+	var pending sync.WaitGroup
+	for _, partition := range partitions {
+		highWaterMark, err := t.client.GetOffset(t.topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return err
+		}
+		oldest, err := t.client.GetOffset(t.topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return err
+		}
+
+		t.mu.RLock()
+		offset, ok := t.offsets[partition]
+		t.mu.RUnlock()
+		if !ok {
+			offset = sarama.OffsetOldest
+		}
+
+		partitionConsumer, err := t.consumer.ConsumePartition(t.topic, partition, offset)
+		if err != nil {
+			return err
+		}
+
+		caughtUp := partitionCaughtUp(offset, oldest, highWaterMark)
+		if !caughtUp {
+			pending.Add(1)
+		}
+		t.wg.Add(1)
+		go t.consumePartition(ctx, partitionConsumer, highWaterMark, caughtUp, pending.Done)
+	}
+
+	go func() {
+		pending.Wait()
+		t.readyOnce.Do(func() { close(t.ready) })
+	}()
+	return nil
+}
+
+// partitionCaughtUp check partition has no message to consume until high water mark, partition is empty when
+// oldest offset same as high water mark (include compacted or retention truncated partition)
+func partitionCaughtUp(offset, oldest, highWaterMark int64) bool {
+	return oldest >= highWaterMark || (offset >= 0 && offset >= highWaterMark)
+}
+
+func (t *Table) consumePartition(ctx context.Context, pc sarama.PartitionConsumer, highWaterMark int64, caughtUp bool, done func()) {
+	defer func() {
+		pc.Close()
+		t.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-pc.Errors():
+			if ok {
+				logger.LogRed(fmt.Sprintf("Kafka table %s: %s", t.topic, err.Error()))
+			}
+		case msg, ok := <-pc.Messages():
+			if !ok {
+				return
+			}
+			t.apply(msg)
+			if !caughtUp && msg.Offset+1 >= highWaterMark {
+				caughtUp = true
+				done()
+			}
+		}
+	}
+}
+
+func (t *Table) apply(msg *sarama.ConsumerMessage) {
+	t.mu.Lock()
+	key := string(msg.Key)
+	oldValue, _ := t.store.Get(key)
+	if msg.Value == nil {
+		t.store.Delete(key)
+	} else {
+		t.store.Set(key, msg.Value)
+	}
+	t.offsets[msg.Partition] = msg.Offset + 1
+	t.mu.Unlock()
+
+	for _, onChange := range t.onChanges {
+		onChange(key, oldValue, msg.Value)
+	}
+}
+
+// WaitReady wait until table caught up with latest offset when started
+func (t *Table) WaitReady(ctx context.Context) error {
+	select {
+	case <-t.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get value by key
+func (t *Table) Get(key string) ([]byte, bool) {
+	return t.store.Get(key)
+}
+
+// GetJSON get value by key and unmarshal json value to target
+func (t *Table) GetJSON(key string, target any) error {
+	value, ok := t.store.Get(key)
+	if !ok {
+		return fmt.Errorf("key '%s' not found in table %s", key, t.topic)
+	}
+	return json.Unmarshal(value, target)
+}
+
+// Range iterate all key in table, stop iteration if fn return false
+func (t *Table) Range(fn func(key string, value []byte) bool) {
+	t.store.Range(fn)
+}
+
+// Len count key in table
+func (t *Table) Len() int {
+	return t.store.Len()
+}
+
+// Snapshot write table entries and consumed offsets to writer (json format)
+func (t *Table) Snapshot(w io.Writer) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshot := tableSnapshot{Topic: t.topic, Offsets: t.offsets, Entries: make(map[string][]byte, t.store.Len())}
+	t.store.Range(func(key string, value []byte) bool {
+		snapshot.Entries[key] = value
+		return true
+	})
+	return json.NewEncoder(w).Encode(snapshot)
+}
+
+// Restore table entries and offsets from snapshot, must be called before Start
+// so consumer continue from snapshot offsets
+func (t *Table) Restore(r io.Reader) error {
+	var snapshot tableSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return err
+	}
+	if snapshot.Topic != t.topic {
+		return fmt.Errorf("snapshot topic '%s' is different with table topic '%s'", snapshot.Topic, t.topic)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, value := range snapshot.Entries {
+		t.store.Set(key, value)
+	}
+	if snapshot.Offsets != nil {
+		t.offsets = snapshot.Offsets
+	}
+	return nil
+}
+
+// Close stop consume topic
+func (t *Table) Close() error {
+	if t.cancel == nil {
+		return errors.New("table not started")
+	}
+	t.cancel()
+	t.wg.Wait()
+	return t.consumer.Close()
+}
+
+type inMemTableStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewInMemTableStore in memory table store
+func NewInMemTableStore() TableStore {
+	return &inMemTableStore{data: make(map[string][]byte)}
+}
+
+func (s *inMemTableStore) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.data[key]
+	return value, ok
+}
+
+func (s *inMemTableStore) Set(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+func (s *inMemTableStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+}
+
+func (s *inMemTableStore) Range(fn func(key string, value []byte) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for key, value := range s.data {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+func (s *inMemTableStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.data)
+}
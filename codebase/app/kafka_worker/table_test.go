@@ -0,0 +1,46 @@
+package kafkaworker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	var changes []string
+	table := NewTable(nil, "reference-data", TableOptionOnChange(func(key string, oldValue, newValue []byte) {
+		changes = append(changes, key+":"+string(oldValue)+"->"+string(newValue))
+	}))
+
+	table.apply(&sarama.ConsumerMessage{Partition: 0, Offset: 0, Key: []byte("ID"), Value: []byte(`{"name":"Indonesia"}`)})
+	table.apply(&sarama.ConsumerMessage{Partition: 0, Offset: 1, Key: []byte("SG"), Value: []byte(`{"name":"Singapore"}`)})
+	table.apply(&sarama.ConsumerMessage{Partition: 1, Offset: 0, Key: []byte("SG"), Value: nil})
+
+	var country struct{ Name string }
+	assert.NoError(t, table.GetJSON("ID", &country))
+	assert.Equal(t, "Indonesia", country.Name)
+	_, ok := table.Get("SG")
+	assert.False(t, ok)
+	assert.Equal(t, []string{`ID:->{"name":"Indonesia"}`, `SG:->{"name":"Singapore"}`, `SG:{"name":"Singapore"}->`}, changes)
+
+	var buf bytes.Buffer
+	assert.NoError(t, table.Snapshot(&buf))
+	snapshot := buf.Bytes()
+
+	restored := NewTable(nil, "reference-data")
+	assert.NoError(t, restored.Restore(bytes.NewReader(snapshot)))
+	assert.Equal(t, 1, restored.Len())
+	assert.Equal(t, map[int32]int64{0: 2, 1: 1}, restored.offsets)
+
+	assert.Error(t, NewTable(nil, "other").Restore(bytes.NewReader(snapshot)))
+}
+
+func TestPartitionCaughtUp(t *testing.T) {
+	assert.True(t, partitionCaughtUp(sarama.OffsetOldest, 0, 0), "new empty partition")
+	assert.True(t, partitionCaughtUp(sarama.OffsetOldest, 120, 120), "retention truncated empty partition")
+	assert.True(t, partitionCaughtUp(150, 100, 150), "restored offset at high water mark")
+	assert.False(t, partitionCaughtUp(sarama.OffsetOldest, 100, 150))
+	assert.False(t, partitionCaughtUp(120, 100, 150))
+}
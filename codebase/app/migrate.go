@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/migration"
+)
+
+// SeedFlag command line flag for run pending seeds (allowed in current environment) instead of serve applications,
+// optional seed names after flag (example: "--seed 001_users staging/002_products")
+const SeedFlag = "--seed"
+
+// SetMigrationSource option, set migration files source for run migration on startup (default from MIGRATION_DIR environment),
+// can use embed.FS for bundle migration files in binary
+func SetMigrationSource(source fs.FS) Option {
+	return func(a *App) {
+		a.migrationSource = source
+	}
+}
+
+// migrate apply up migration to primary sql and/or mongo database when MIGRATION_ON_STARTUP is enabled,
+// wait for migration lock if another replica is applying migration
+func (a *App) migrate() error {
+	source := a.migrationSource
+	if source == nil {
+		source = os.DirFS(env.BaseEnv().MigrationDir)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	deps := a.service.GetDependency()
+	if deps == nil {
+		return nil
+	}
+	if sqlDB := deps.GetSQLDatabase(); sqlDB != nil {
+		driver, err := migration.NewSQLDriver(sqlDB.WriteDB())
+		if err != nil {
+			return err
+		}
+		if err := migration.Run(ctx, driver, source); err != nil {
+			return err
+		}
+	}
+	if mongoDB := deps.GetMongoDatabase(); mongoDB != nil {
+		if err := migration.Run(ctx, migration.NewMongoDriver(mongoDB.WriteDB()), source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSeed run seeders from command line arguments after seed flag
+func (a *App) runSeed() {
+	var names []string
+	for i, arg := range os.Args {
+		if arg == SeedFlag {
+			names = os.Args[i+1:]
+			break
+		}
+	}
+
+	seeder, err := migration.NewServiceSeeder(a.service.GetDependency())
+	if err != nil {
+		log.Panicf("Seed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+	applied, err := seeder.Run(ctx, names...)
+	if err != nil {
+		log.Panicf("Seed: %v", err)
+	}
+	fmt.Printf("\x1b[32;1mSeeded %d seed(s) in %s environment\x1b[0m\n", len(applied), env.BaseEnv().Environment)
+}
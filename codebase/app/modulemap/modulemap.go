@@ -0,0 +1,299 @@
+// Package modulemap describe app composition at runtime (modules, REST routes, gRPC services, GraphQL fields,
+// worker handlers, and middleware chains), so service is self-documenting for platform catalogs.
+package modulemap
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	cronworker "github.com/golangid/candi/codebase/app/cron_worker"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/wrapper"
+	"google.golang.org/grpc"
+)
+
+var (
+	// WorkerTypes worker types to be described
+	WorkerTypes = []types.Worker{
+		types.Kafka, types.RedisSubscriber, types.RabbitMQ, types.Scheduler, types.TaskQueue, types.PostgresListener,
+		types.MongoChangeStream, types.PostgresNotify, types.SearchIndexer,
+	}
+
+	anonymousFuncSuffix = regexp.MustCompile(`(\.func\d+|\.gowrap\d+)+$`)
+)
+
+type (
+	// ServiceMap composition of service
+	ServiceMap struct {
+		Service      string    `json:"service"`
+		Applications []string  `json:"applications"`
+		Modules      []Module  `json:"modules"`
+		GeneratedAt  time.Time `json:"generatedAt"`
+	}
+
+	// Module composition of module
+	Module struct {
+		Name    string                     `json:"name"`
+		REST    []RESTRoute                `json:"rest,omitempty"`
+		GRPC    []GRPCService              `json:"grpc,omitempty"`
+		GraphQL *GraphQLFields             `json:"graphql,omitempty"`
+		Workers map[string][]WorkerHandler `json:"workers,omitempty"`
+	}
+
+	// RESTRoute rest route with middleware chain (module level, root middleware from rest server option not included)
+	RESTRoute struct {
+		Method      string   `json:"method"`
+		Path        string   `json:"path"`
+		Handler     string   `json:"handler"`
+		Middlewares []string `json:"middlewares,omitempty"`
+	}
+
+	// GRPCService grpc service with methods
+	GRPCService struct {
+		Name    string       `json:"name"`
+		Methods []GRPCMethod `json:"methods"`
+	}
+
+	// GRPCMethod grpc method with middleware chain
+	GRPCMethod struct {
+		Name            string   `json:"name"`
+		ClientStreaming bool     `json:"clientStreaming,omitempty"`
+		ServerStreaming bool     `json:"serverStreaming,omitempty"`
+		Middlewares     []string `json:"middlewares,omitempty"`
+	}
+
+	// GraphQLFields root fields of graphql resolver
+	GraphQLFields struct {
+		Query        []string `json:"query,omitempty"`
+		Mutation     []string `json:"mutation,omitempty"`
+		Subscription []string `json:"subscription,omitempty"`
+	}
+
+	// WorkerHandler worker handler with pattern (topic, channel, queue, task name, or cron job key)
+	WorkerHandler struct {
+		Pattern       string   `json:"pattern"`
+		Handler       string   `json:"handler"`
+		AfterHandlers []string `json:"afterHandlers,omitempty"`
+		Schedule      string   `json:"schedule,omitempty"`
+		DisableTrace  bool     `json:"disableTrace,omitempty"`
+		AutoACK       bool     `json:"autoAck"`
+		Configs       []string `json:"configs,omitempty"`
+	}
+)
+
+// Build describe service composition from all registered modules
+func Build(service factory.ServiceFactory) ServiceMap {
+	serviceMap := ServiceMap{
+		Service: string(service.Name()), Applications: []string{}, Modules: []Module{}, GeneratedAt: time.Now(),
+	}
+	for _, app := range service.GetApplications() {
+		serviceMap.Applications = append(serviceMap.Applications, app.Name())
+	}
+	for _, m := range service.GetModules() {
+		serviceMap.Modules = append(serviceMap.Modules, DescribeModule(m))
+	}
+	return serviceMap
+}
+
+// DescribeModule describe single module composition
+func DescribeModule(m factory.ModuleFactory) Module {
+	module := Module{Name: string(m.Name())}
+
+	if h := m.RESTHandler(); h != nil {
+		recorder := &routeRecorder{routes: &module.REST}
+		h.Mount(recorder)
+		sort.SliceStable(module.REST, func(i, j int) bool { return module.REST[i].Path < module.REST[j].Path })
+	}
+	if h := m.GRPCHandler(); h != nil {
+		module.GRPC = describeGRPC(h)
+	}
+	if h := m.GraphQLHandler(); h != nil {
+		module.GraphQL = &GraphQLFields{
+			Query: resolverFields(h.Query()), Mutation: resolverFields(h.Mutation()), Subscription: resolverFields(h.Subscription()),
+		}
+	}
+	for _, workerType := range WorkerTypes {
+		h := m.WorkerHandler(workerType)
+		if h == nil || factory.IsWorkerDisabled(workerType) {
+			continue
+		}
+		var group types.WorkerHandlerGroup
+		h.MountHandlers(&group)
+		group.Handlers = slices.DeleteFunc(group.Handlers, func(handler types.WorkerHandler) bool {
+			return factory.IsWorkerHandlerDisabled(workerType, handler.Pattern)
+		})
+		if len(group.Handlers) == 0 {
+			continue
+		}
+		if module.Workers == nil {
+			module.Workers = make(map[string][]WorkerHandler)
+		}
+		module.Workers[string(workerType)] = describeWorkerHandlers(workerType, group.Handlers)
+	}
+	return module
+}
+
+// HTTPHandler admin handler serve service map in JSON, map is built once on first request
+// (mount handlers is not called again on every request)
+func HTTPHandler(service factory.ServiceFactory) http.HandlerFunc {
+	build := sync.OnceValue(func() ServiceMap { return Build(service) })
+	return func(w http.ResponseWriter, r *http.Request) {
+		wrapper.NewHTTPResponse(http.StatusOK, "Module map", build()).JSON(w)
+	}
+}
+
+func describeGRPC(h interfaces.GRPCHandler) (services []GRPCService) {
+	server := grpc.NewServer()
+	mwGroup := types.MiddlewareGroup{}
+	h.Register(server, &mwGroup)
+
+	for serviceName, info := range server.GetServiceInfo() {
+		service := GRPCService{Name: serviceName}
+		for _, method := range info.Methods {
+			service.Methods = append(service.Methods, GRPCMethod{
+				Name: method.Name, ClientStreaming: method.IsClientStream, ServerStreaming: method.IsServerStream,
+				Middlewares: funcNames(mwGroup["/"+serviceName+"/"+method.Name]),
+			})
+		}
+		sort.Slice(service.Methods, func(i, j int) bool { return service.Methods[i].Name < service.Methods[j].Name })
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// resolverFields graphql root fields from exported resolver methods (first letter in lower case)
+func resolverFields(resolver any) (fields []string) {
+	if resolver == nil {
+		return nil
+	}
+	typ := reflect.TypeOf(resolver)
+	for i := 0; i < typ.NumMethod(); i++ {
+		name := typ.Method(i).Name
+		r, size := utf8.DecodeRuneInString(name)
+		fields = append(fields, string(unicode.ToLower(r))+name[size:])
+	}
+	return fields
+}
+
+func describeWorkerHandlers(workerType types.Worker, handlers []types.WorkerHandler) (result []WorkerHandler) {
+	for _, handler := range handlers {
+		wh := WorkerHandler{
+			Pattern: handler.Pattern, DisableTrace: handler.DisableTrace, AutoACK: handler.AutoACK,
+		}
+		if len(handler.HandlerFuncs) > 0 {
+			wh.Handler = funcName(handler.HandlerFuncs[0])
+			wh.AfterHandlers = funcNames(handler.HandlerFuncs[1:])
+		}
+		for key := range handler.Configs {
+			wh.Configs = append(wh.Configs, key)
+		}
+		sort.Strings(wh.Configs)
+		if workerType == types.Scheduler {
+			_, _, interval := cronworker.ParseCronJobKey(handler.Pattern)
+			if desc, err := cronworker.DescribeSchedule(interval); err == nil {
+				wh.Schedule = interval + " (" + desc + ")"
+			} else {
+				wh.Schedule = interval
+			}
+		}
+		result = append(result, wh)
+	}
+	return result
+}
+
+func funcNames[T any](funcs []T) (names []string) {
+	for _, fn := range funcs {
+		names = append(names, funcName(fn))
+	}
+	return names
+}
+
+// funcName readable function name without package path, example: "RestHandler.getAll" or "Middleware.HTTPBearerAuth"
+func funcName(fn any) string {
+	val := reflect.ValueOf(fn)
+	if val.Kind() != reflect.Func || val.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(val.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	name = anonymousFuncSuffix.ReplaceAllString(strings.TrimSuffix(name, "-fm"), "")
+	if _, after, ok := strings.Cut(name, "."); ok {
+		name = after
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// routeRecorder record all rest route with middleware chain
+type routeRecorder struct {
+	prefix      string
+	middlewares []string
+	routes      *[]RESTRoute
+}
+
+func (r *routeRecorder) add(method, pattern string, h http.HandlerFunc, middlewares []func(http.Handler) http.Handler) {
+	*r.routes = append(*r.routes, RESTRoute{
+		Method:      method,
+		Path:        "/" + strings.Trim(r.prefix+"/"+strings.Trim(pattern, "/"), "/"),
+		Handler:     funcName(h),
+		Middlewares: append(append([]string(nil), r.middlewares...), funcNames(middlewares)...),
+	})
+}
+
+func (r *routeRecorder) Use(middlewares ...func(http.Handler) http.Handler) {
+	r.middlewares = append(r.middlewares, funcNames(middlewares)...)
+}
+func (r *routeRecorder) Group(pattern string, middlewares ...func(http.Handler) http.Handler) interfaces.RESTRouter {
+	return &routeRecorder{
+		prefix:      r.prefix + "/" + strings.Trim(pattern, "/"),
+		middlewares: append(append([]string(nil), r.middlewares...), funcNames(middlewares)...),
+		routes:      r.routes,
+	}
+}
+func (r *routeRecorder) HandleFunc(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add("*", pattern, h, middlewares)
+}
+func (r *routeRecorder) CONNECT(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodConnect, pattern, h, middlewares)
+}
+func (r *routeRecorder) DELETE(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodDelete, pattern, h, middlewares)
+}
+func (r *routeRecorder) GET(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodGet, pattern, h, middlewares)
+}
+func (r *routeRecorder) HEAD(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodHead, pattern, h, middlewares)
+}
+func (r *routeRecorder) OPTIONS(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodOptions, pattern, h, middlewares)
+}
+func (r *routeRecorder) PATCH(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodPatch, pattern, h, middlewares)
+}
+func (r *routeRecorder) POST(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodPost, pattern, h, middlewares)
+}
+func (r *routeRecorder) PUT(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodPut, pattern, h, middlewares)
+}
+func (r *routeRecorder) TRACE(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodTrace, pattern, h, middlewares)
+}
@@ -0,0 +1,104 @@
+package modulemap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	cronworker "github.com/golangid/candi/codebase/app/cron_worker"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+type testModule struct{}
+
+func (testModule) RESTHandler() interfaces.RESTHandler       { return testRESTHandler{} }
+func (testModule) GRPCHandler() interfaces.GRPCHandler       { return nil }
+func (testModule) GraphQLHandler() interfaces.GraphQLHandler { return testGraphQLHandler{} }
+func (testModule) WorkerHandler(workerType types.Worker) interfaces.WorkerHandler {
+	if workerType == types.Scheduler {
+		return testWorkerHandler{}
+	}
+	return nil
+}
+func (testModule) Name() types.Module                                             { return "user" }
+func (testModule) ServerHandler(serverType types.Server) interfaces.ServerHandler { return nil }
+
+type testRESTHandler struct{}
+
+var mountCount int
+
+func (h testRESTHandler) Mount(root interfaces.RESTRouter) {
+	mountCount++
+	v1 := root.Group("/v1/user", authMiddleware)
+	v1.GET("/", h.getAllUser, aclMiddleware("getAllUser"))
+	v1.POST("/", h.createUser)
+}
+func (testRESTHandler) getAllUser(w http.ResponseWriter, r *http.Request) {}
+func (testRESTHandler) createUser(w http.ResponseWriter, r *http.Request) {}
+
+func authMiddleware(next http.Handler) http.Handler { return next }
+func aclMiddleware(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return next }
+}
+
+type testGraphQLHandler struct{}
+
+func (testGraphQLHandler) Query() any        { return &testQueryResolver{} }
+func (testGraphQLHandler) Mutation() any     { return nil }
+func (testGraphQLHandler) Subscription() any { return nil }
+func (testGraphQLHandler) Schema() string    { return "" }
+
+type testQueryResolver struct{}
+
+func (testQueryResolver) GetAllUser() []string  { return nil }
+func (testQueryResolver) GetDetailUser() string { return "" }
+
+type testWorkerHandler struct{}
+
+func (h testWorkerHandler) MountHandlers(group *types.WorkerHandlerGroup) {
+	group.Add(cronworker.CreateCronJobKey("sync-user", "", "@daily"), h.syncUser, types.WorkerHandlerOptionAddConfig("timeout", "1m"))
+	group.Add(cronworker.CreateCronJobKey("send-report", "", "@hourly"), h.syncUser)
+}
+func (testWorkerHandler) syncUser(eventContext *candishared.EventContext) error { return nil }
+
+func TestDescribeModule(t *testing.T) {
+	factory.SetWorkerMatrixRule(factory.WorkerMatrixRule{Disable: []string{string(types.Scheduler) + ":send-report"}})
+	defer factory.SetWorkerMatrixRule(factory.WorkerMatrixRule{})
+
+	module := DescribeModule(testModule{})
+	assert.Equal(t, "user", module.Name)
+	assert.Equal(t, []RESTRoute{
+		{Method: http.MethodGet, Path: "/v1/user", Handler: "testRESTHandler.getAllUser", Middlewares: []string{"authMiddleware", "aclMiddleware"}},
+		{Method: http.MethodPost, Path: "/v1/user", Handler: "testRESTHandler.createUser", Middlewares: []string{"authMiddleware"}},
+	}, module.REST)
+	assert.Nil(t, module.GRPC)
+	assert.Equal(t, &GraphQLFields{Query: []string{"getAllUser", "getDetailUser"}}, module.GraphQL)
+	assert.Equal(t, map[string][]WorkerHandler{
+		string(types.Scheduler): {{
+			Pattern: cronworker.CreateCronJobKey("sync-user", "", "@daily"), Handler: "testWorkerHandler.syncUser", AutoACK: true, Configs: []string{"timeout"},
+			Schedule: "@daily (At 00:00:00)",
+		}},
+	}, module.Workers)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("user-service")).Once()
+	service.On("GetApplications").Return([]factory.AppServerFactory{}).Once()
+	service.On("GetModules").Return([]factory.ModuleFactory{testModule{}}).Once()
+
+	mountCount = 0
+	handler := HTTPHandler(service)
+	for range 3 {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/modules", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "user-service")
+	}
+	assert.Equal(t, 1, mountCount, "service map is built once")
+}
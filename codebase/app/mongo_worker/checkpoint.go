@@ -0,0 +1,74 @@
+package mongoworker
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// checkpointTracker track resume token of in flight event, checkpoint only advance to token of event which all
+// previous event has been processed, so no event is lost when resumed after restart
+type checkpointTracker struct {
+	mu      sync.Mutex
+	pending []*pendingToken
+	latest  bson.Raw
+	dirty   bool
+}
+
+type pendingToken struct {
+	token bson.Raw
+	done  bool
+}
+
+func newCheckpointTracker(latest bson.Raw) *checkpointTracker {
+	return &checkpointTracker{latest: latest}
+}
+
+// add token of received event, in stream order
+func (t *checkpointTracker) add(token bson.Raw) *pendingToken {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := &pendingToken{token: token}
+	t.pending = append(t.pending, p)
+	return p
+}
+
+// done mark event processed, advance latest token to last event of processed prefix
+func (t *checkpointTracker) done(p *pendingToken) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p.done = true
+	for len(t.pending) > 0 && t.pending[0].done {
+		t.latest, t.dirty = t.pending[0].token, true
+		t.pending = t.pending[1:]
+	}
+}
+
+// resumeToken latest processed token, for reopen change stream
+func (t *checkpointTracker) resumeToken() bson.Raw {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.latest
+}
+
+// flush latest token if changed since last flush
+func (t *checkpointTracker) flush() (token bson.Raw, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed, t.dirty = t.dirty, false
+	return t.latest, changed
+}
+
+// discard latest token, change stream is reopened from current time
+func (t *checkpointTracker) discard() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latest, t.dirty = nil, true
+}
+
+// markDirty latest token is saved again in next flush (example: previous save is failed)
+func (t *checkpointTracker) markDirty() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dirty = true
+}
@@ -0,0 +1,135 @@
+package mongoworker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// OperationInsert change event operation type
+	OperationInsert = "insert"
+	// OperationUpdate change event operation type
+	OperationUpdate = "update"
+	// OperationReplace change event operation type
+	OperationReplace = "replace"
+	// OperationDelete change event operation type
+	OperationDelete = "delete"
+)
+
+type (
+	// ChangeEvent change event message of handler, document field is MongoDB extended JSON (relaxed)
+	ChangeEvent struct {
+		EventID                  string          `json:"eventId"`
+		Operation                string          `json:"operation"`
+		Database                 string          `json:"database"`
+		Collection               string          `json:"collection"`
+		DocumentKey              json.RawMessage `json:"documentKey,omitempty"`
+		FullDocument             json.RawMessage `json:"fullDocument,omitempty"`
+		FullDocumentBeforeChange json.RawMessage `json:"fullDocumentBeforeChange,omitempty"`
+		UpdatedFields            json.RawMessage `json:"updatedFields,omitempty"`
+		RemovedFields            []string        `json:"removedFields,omitempty"`
+		ClusterTime              time.Time       `json:"clusterTime"`
+	}
+
+	// changeDocument raw change stream document
+	changeDocument struct {
+		ID            bson.Raw            `bson:"_id"`
+		OperationType string              `bson:"operationType"`
+		ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+		NS            struct {
+			DB   string `bson:"db"`
+			Coll string `bson:"coll"`
+		} `bson:"ns"`
+		DocumentKey              bson.Raw `bson:"documentKey"`
+		FullDocument             bson.Raw `bson:"fullDocument"`
+		FullDocumentBeforeChange bson.Raw `bson:"fullDocumentBeforeChange"`
+		UpdateDescription        struct {
+			UpdatedFields bson.Raw `bson:"updatedFields"`
+			RemovedFields []string `bson:"removedFields"`
+		} `bson:"updateDescription"`
+	}
+)
+
+// ParseChangeEvent parse change event from handler event context
+func ParseChangeEvent(eventContext *candishared.EventContext) (event ChangeEvent, err error) {
+	err = json.Unmarshal(eventContext.Message(), &event)
+	return event, err
+}
+
+// DecodeFullDocument decode full document (after change) to target, example target is bson tagged model
+func (e *ChangeEvent) DecodeFullDocument(target any) error {
+	return decodeExtJSON(e.FullDocument, target)
+}
+
+// DecodeFullDocumentBeforeChange decode full document before change to target
+func (e *ChangeEvent) DecodeFullDocumentBeforeChange(target any) error {
+	return decodeExtJSON(e.FullDocumentBeforeChange, target)
+}
+
+// DecodeDocumentKey decode document key (contains _id and shard key) to target
+func (e *ChangeEvent) DecodeDocumentKey(target any) error {
+	return decodeExtJSON(e.DocumentKey, target)
+}
+
+// GetID document _id of change event in string (hex for ObjectID)
+func (e *ChangeEvent) GetID() string {
+	var key struct {
+		ID any `bson:"_id"`
+	}
+	if e.DecodeDocumentKey(&key) != nil {
+		return ""
+	}
+	if oid, ok := key.ID.(primitive.ObjectID); ok {
+		return oid.Hex()
+	}
+	return fmt.Sprint(key.ID)
+}
+
+func decodeExtJSON(data json.RawMessage, target any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("mongo change event: document is empty")
+	}
+	return bson.UnmarshalExtJSON(data, false, target)
+}
+
+// toChangeEvent convert raw change stream document to change event message
+func (d *changeDocument) toChangeEvent() (event ChangeEvent, err error) {
+	event = ChangeEvent{
+		EventID:       d.eventID(),
+		Operation:     d.OperationType,
+		Database:      d.NS.DB,
+		Collection:    d.NS.Coll,
+		RemovedFields: d.UpdateDescription.RemovedFields,
+		ClusterTime:   time.Unix(int64(d.ClusterTime.T), 0).UTC(),
+	}
+	for _, field := range []struct {
+		raw    bson.Raw
+		target *json.RawMessage
+	}{
+		{d.DocumentKey, &event.DocumentKey},
+		{d.FullDocument, &event.FullDocument},
+		{d.FullDocumentBeforeChange, &event.FullDocumentBeforeChange},
+		{d.UpdateDescription.UpdatedFields, &event.UpdatedFields},
+	} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if *field.target, err = bson.MarshalExtJSON(field.raw, false, false); err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
+
+// eventID resume token data of change event, unique for each event
+func (d *changeDocument) eventID() string {
+	if data, ok := d.ID.Lookup("_data").StringValueOK(); ok {
+		return data
+	}
+	return d.ID.String()
+}
@@ -0,0 +1,362 @@
+package mongoworker
+
+// MongoDB change stream worker codebase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/contract"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// error code of resume token no longer in oplog
+const (
+	errCodeChangeStreamHistoryLost = 286
+	errCodeChangeStreamFatalError  = 280
+)
+
+type (
+	mongoWorker struct {
+		ctx           context.Context
+		ctxCancelFunc func()
+		streamCtx     context.Context
+		streamCancel  func()
+		opt           option
+
+		service     factory.ServiceFactory
+		handlers    map[string]types.WorkerHandler
+		semaphores  map[string]chan struct{}
+		tracker     *checkpointTracker
+		wg          sync.WaitGroup
+		serveDone   chan struct{}
+		messagePool sync.Pool
+	}
+)
+
+/*
+NewWorker create MongoDB change stream worker, watch change of collection in database (replica set or sharded cluster
+is required) and pass change event to module worker handler with pattern is collection name.
+Resume token of processed event is saved to checkpoint store, change stream is resumed from checkpoint after restart
+*/
+func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppServerFactory {
+	worker := &mongoWorker{
+		service:    service,
+		opt:        getDefaultOption(service),
+		handlers:   make(map[string]types.WorkerHandler),
+		semaphores: make(map[string]chan struct{}),
+		serveDone:  make(chan struct{}),
+		messagePool: sync.Pool{
+			New: func() any {
+				return candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, 256)))
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(&worker.opt)
+	}
+	if len(worker.opt.operationTypes) == 0 {
+		worker.opt.operationTypes = []string{OperationInsert, OperationUpdate, OperationReplace, OperationDelete}
+	}
+
+	for _, m := range service.GetModules() {
+		if h := m.WorkerHandler(worker.opt.workerType); h != nil {
+			var handlerGroup types.WorkerHandlerGroup
+			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(worker.opt.workerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
+			for _, handler := range handlerGroup.Handlers {
+				logger.LogYellow(fmt.Sprintf(`[MONGO-CHANGE-STREAM]%s (collection): %-15s  --> (module): "%s"`,
+					getWorkerTypeLog(worker.opt.workerType), `"`+handler.Pattern+`"`, m.Name()))
+				worker.handlers[handler.Pattern] = handler
+				worker.semaphores[handler.Pattern] = make(chan struct{}, max(worker.opt.maxGoroutines, 1))
+			}
+		}
+	}
+
+	worker.ctx, worker.ctxCancelFunc = context.WithCancel(context.Background())
+	worker.streamCtx, worker.streamCancel = context.WithCancel(worker.ctx)
+	if len(worker.handlers) == 0 {
+		log.Printf("mongo change stream%s: no collection provided", getWorkerTypeLog(worker.opt.workerType))
+		worker.tracker = newCheckpointTracker(nil)
+		return worker
+	}
+
+	if worker.opt.db == nil {
+		log.Panicf("[MONGO-CHANGE-STREAM]%s: mongo database is not set", getWorkerTypeLog(worker.opt.workerType))
+	}
+	if worker.opt.checkpoint == nil {
+		worker.opt.checkpoint = candiutils.NewMongoCheckpoint(worker.opt.db, "")
+	}
+	resumeToken, err := worker.loadCheckpoint()
+	if err != nil {
+		log.Panicf("[MONGO-CHANGE-STREAM]%s: failed when load checkpoint: %s", getWorkerTypeLog(worker.opt.workerType), err)
+	}
+	worker.tracker = newCheckpointTracker(resumeToken)
+	worker.opt.locker.Reset(fmt.Sprintf("%s:mongo-worker-lock:*", service.Name()))
+
+	fmt.Printf("\x1b[34;1m⇨ Mongo Change Stream worker%s running with %d collections\x1b[0m\n\n",
+		getWorkerTypeLog(worker.opt.workerType), len(worker.handlers))
+	return worker
+}
+
+func (m *mongoWorker) Serve() {
+	defer close(m.serveDone)
+	if len(m.handlers) == 0 {
+		return
+	}
+
+	go m.checkpointLoop()
+	for {
+		err := m.watch()
+		// wait in flight event, so change stream is reopened from last processed event
+		m.wg.Wait()
+		if m.streamCtx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.LogRed("mongo_change_stream > " + err.Error())
+			if m.opt.onErrorFunc != nil {
+				m.opt.onErrorFunc(err)
+			}
+		}
+
+		select {
+		case <-m.streamCtx.Done():
+			return
+		case <-time.After(m.opt.reconnectInterval):
+		}
+	}
+}
+
+func (m *mongoWorker) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mStopping Mongo Change Stream%s:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), getWorkerTypeLog(m.opt.workerType), strings.Repeat(" ", 20))
+	}()
+
+	if len(m.handlers) == 0 {
+		return
+	}
+
+	runningJob := 0
+	for _, sem := range m.semaphores {
+		runningJob += len(sem)
+	}
+	waitingJob := "... "
+	if runningJob != 0 {
+		waitingJob = fmt.Sprintf("waiting %d job until done... ", runningJob)
+	}
+	fmt.Printf("\r%s \x1b[33;1mStopping Mongo Change Stream%s:\x1b[0m %s",
+		time.Now().Format(candihelper.TimeFormatLogger), getWorkerTypeLog(m.opt.workerType), waitingJob)
+
+	m.streamCancel()
+	select {
+	case <-m.serveDone:
+	case <-ctx.Done():
+	}
+	m.saveCheckpoint(ctx)
+	m.ctxCancelFunc()
+	m.opt.locker.Reset(fmt.Sprintf("%s:mongo-worker-lock:*", m.service.Name()))
+}
+
+func (m *mongoWorker) Name() string {
+	return string(m.opt.workerType)
+}
+
+// watch open change stream from last processed event and dispatch event until stream is closed or failed
+func (m *mongoWorker) watch() error {
+	opts := options.ChangeStream().SetFullDocument(m.opt.fullDocument)
+	if m.opt.fullDocumentBeforeChange != "" {
+		opts.SetFullDocumentBeforeChange(m.opt.fullDocumentBeforeChange)
+	}
+	resumeToken := m.tracker.resumeToken()
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	}
+
+	stream, err := m.opt.db.Watch(m.streamCtx, m.pipeline(), opts)
+	if err != nil {
+		var serverErr mongo.ServerError
+		if resumeToken != nil && errors.As(err, &serverErr) &&
+			(serverErr.HasErrorCode(errCodeChangeStreamHistoryLost) || serverErr.HasErrorCode(errCodeChangeStreamFatalError)) {
+			logger.LogRed("mongo_change_stream > resume token is no longer in oplog, change stream is restarted from current time")
+			m.tracker.discard()
+		}
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(m.streamCtx) {
+		var doc changeDocument
+		if err := stream.Decode(&doc); err != nil {
+			return err
+		}
+
+		pending := m.tracker.add(doc.ID)
+		handler, ok := m.handlers[doc.NS.Coll]
+		if !ok {
+			m.tracker.done(pending)
+			continue
+		}
+
+		select {
+		case m.semaphores[doc.NS.Coll] <- struct{}{}:
+		case <-m.streamCtx.Done():
+			return nil
+		}
+		m.wg.Add(1)
+		go func() {
+			defer func() {
+				m.tracker.done(pending)
+				<-m.semaphores[doc.NS.Coll]
+				m.wg.Done()
+			}()
+			m.execEvent(handler, &doc)
+		}()
+	}
+	return stream.Err()
+}
+
+func (m *mongoWorker) pipeline() mongo.Pipeline {
+	collections := make([]string, 0, len(m.handlers))
+	for collection := range m.handlers {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"ns.coll": bson.M{"$in": collections}, "operationType": bson.M{"$in": m.opt.operationTypes},
+	}}}}
+}
+
+func (m *mongoWorker) execEvent(handler types.WorkerHandler, doc *changeDocument) {
+	// lock for multiple worker (if running on multiple pods/instance)
+	lockKey := fmt.Sprintf("%s:mongo-worker-lock:%s", m.service.Name(), doc.eventID())
+	if m.opt.locker.IsLocked(lockKey) {
+		return
+	}
+	defer m.opt.locker.Unlock(lockKey)
+
+	ctx := m.ctx
+	if handler.DisableTrace {
+		ctx = tracer.SkipTraceContext(ctx)
+	}
+
+	var err error
+	trace, ctx := tracer.StartTraceFromHeader(ctx, "MongoChangeStream", make(map[string]string, 0))
+	defer func() {
+		if r := recover(); r != nil {
+			trace.SetTag("panic", true)
+			err = candiutils.ReportPanic(ctx, "MongoChangeStream", r, map[string]any{
+				"collection": doc.NS.Coll, "operation": doc.OperationType,
+			})
+		}
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+
+	trace.SetTag("database", doc.NS.DB)
+	trace.SetTag("collection", doc.NS.Coll)
+	trace.SetTag("operation", doc.OperationType)
+	if m.opt.workerType != types.MongoChangeStream {
+		trace.SetTag("worker_type", string(m.opt.workerType))
+	}
+
+	event, err := doc.toChangeEvent()
+	if err != nil {
+		return
+	}
+	trace.Log("payload", event)
+
+	if m.opt.debugMode {
+		log.Printf("\x1b[35;3mMongo Change Stream%s: executing event from collection: '%s' and operation: '%s'\x1b[0m",
+			getWorkerTypeLog(m.opt.workerType), event.Collection, event.Operation)
+	}
+
+	eventContext := m.messagePool.Get().(*candishared.EventContext)
+	defer m.releaseMessagePool(eventContext)
+	eventContext.SetContext(ctx)
+	eventContext.SetWorkerType(string(m.opt.workerType))
+	eventContext.SetHandlerRoute(event.Collection)
+	eventContext.SetKey(event.GetID())
+	eventContext.SetHeader(map[string]string{
+		"event_id": event.EventID, "operation": event.Operation, "database": event.Database,
+	})
+
+	message, _ := json.Marshal(event)
+	eventContext.Write(message)
+
+	for _, handlerFunc := range handler.HandlerFuncs {
+		if err = handlerFunc(eventContext); err != nil {
+			eventContext.SetError(err)
+		}
+	}
+}
+
+func (m *mongoWorker) checkpointLoop() {
+	ticker := time.NewTicker(max(m.opt.checkpointInterval, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.streamCtx.Done():
+			return
+		case <-ticker.C:
+			m.saveCheckpoint(m.streamCtx)
+		}
+	}
+}
+
+func (m *mongoWorker) loadCheckpoint() (bson.Raw, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key, err := m.opt.checkpoint.Load(ctx, m.opt.checkpointName)
+	if err != nil || key == "" {
+		return nil, err
+	}
+	var resumeToken bson.Raw
+	return resumeToken, bson.UnmarshalExtJSON([]byte(key), true, &resumeToken)
+}
+
+func (m *mongoWorker) saveCheckpoint(ctx context.Context) {
+	resumeToken, changed := m.tracker.flush()
+	if !changed {
+		return
+	}
+	var key string
+	if resumeToken != nil {
+		b, _ := bson.MarshalExtJSON(resumeToken, true, false)
+		key = string(b)
+	}
+	if err := m.opt.checkpoint.Save(context.WithoutCancel(ctx), m.opt.checkpointName, key); err != nil {
+		m.tracker.markDirty()
+		logger.LogRed("mongo_change_stream > failed save checkpoint: " + err.Error())
+	}
+}
+
+func (m *mongoWorker) releaseMessagePool(eventContext *candishared.EventContext) {
+	eventContext.Reset()
+	m.messagePool.Put(eventContext)
+}
+
+func getWorkerTypeLog(name types.Worker) (workerType string) {
+	if name != types.MongoChangeStream {
+		workerType = " [worker_type: " + string(name) + "]"
+	}
+	return
+}
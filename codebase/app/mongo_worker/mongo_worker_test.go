@@ -0,0 +1,122 @@
+package mongoworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type workerHandler func(group *types.WorkerHandlerGroup)
+
+func (w workerHandler) MountHandlers(group *types.WorkerHandlerGroup) { w(group) }
+
+type order struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Status string             `bson:"status"`
+	Total  float64            `bson:"total"`
+}
+
+func TestChangeEvent(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(bson.M{
+		"_id":           bson.M{"_data": "8264A1"},
+		"operationType": OperationUpdate,
+		"clusterTime":   primitive.Timestamp{T: 1700000000, I: 1},
+		"ns":            bson.M{"db": "shop", "coll": "orders"},
+		"documentKey":   bson.M{"_id": id},
+		"fullDocument":  bson.M{"_id": id, "status": "paid", "total": 10.5},
+		"updateDescription": bson.M{
+			"updatedFields": bson.M{"status": "paid"}, "removedFields": bson.A{"note"},
+		},
+		"fullDocumentBeforeChange": nil,
+	})
+	require.NoError(t, err)
+
+	var doc changeDocument
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+	event, err := doc.toChangeEvent()
+	require.NoError(t, err)
+	assert.Equal(t, "8264A1", event.EventID)
+	assert.Equal(t, "orders", event.Collection)
+	assert.Equal(t, id.Hex(), event.GetID())
+	assert.Equal(t, []string{"note"}, event.RemovedFields)
+	assert.JSONEq(t, `{"status":"paid"}`, string(event.UpdatedFields))
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), event.ClusterTime)
+
+	var o order
+	require.NoError(t, event.DecodeFullDocument(&o))
+	assert.Equal(t, order{ID: id, Status: "paid", Total: 10.5}, o)
+	assert.Error(t, event.DecodeFullDocumentBeforeChange(&o))
+}
+
+func TestCheckpointTracker(t *testing.T) {
+	tracker := newCheckpointTracker(nil)
+	first, second := tracker.add(bson.Raw("1")), tracker.add(bson.Raw("2"))
+
+	tracker.done(second)
+	_, changed := tracker.flush()
+	assert.False(t, changed, "checkpoint must not advance before previous event is processed")
+
+	tracker.done(first)
+	token, changed := tracker.flush()
+	assert.True(t, changed)
+	assert.Equal(t, bson.Raw("2"), token)
+	_, changed = tracker.flush()
+	assert.False(t, changed)
+}
+
+func TestMongoWorker(t *testing.T) {
+	var received []string
+	module := &mockfactory.ModuleFactory{}
+	module.On("Name").Return(types.Module("order"))
+	module.On("WorkerHandler", types.MongoChangeStream).Return(workerHandler(func(group *types.WorkerHandlerGroup) {
+		group.Add("orders", func(eventContext *candishared.EventContext) error {
+			event, err := ParseChangeEvent(eventContext)
+			if err != nil {
+				return err
+			}
+			received = append(received, eventContext.HandlerRoute()+"/"+event.Operation+"/"+eventContext.Key())
+			return nil
+		})
+	}))
+	service := &mockfactory.ServiceFactory{}
+	service.On("GetDependency").Return(nil)
+	service.On("Name").Return(types.Service("test"))
+	service.On("GetModules").Return([]factory.ModuleFactory{module})
+
+	// client is not connected until first operation
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+
+	checkpoint := candiutils.NewMemoryCheckpoint()
+	resumeToken, _ := bson.Marshal(bson.M{"_data": "8264A2"})
+	worker := NewWorker(service, SetMongoDatabase(client.Database("shop")), SetCheckpoint(checkpoint),
+		SetLocker(testkit.NewLocker()), SetDebugMode(false)).(*mongoWorker)
+	assert.Equal(t, bson.M{"$in": []string{"orders"}}, worker.pipeline()[0][0].Value.(bson.M)["ns.coll"])
+
+	doc := changeDocument{ID: resumeToken, OperationType: OperationInsert}
+	doc.NS.DB, doc.NS.Coll = "shop", "orders"
+	doc.DocumentKey, _ = bson.Marshal(bson.M{"_id": "order-1"})
+	worker.execEvent(worker.handlers["orders"], &doc)
+	assert.Equal(t, []string{"orders/insert/order-1"}, received)
+
+	worker.tracker.done(worker.tracker.add(resumeToken))
+	worker.saveCheckpoint(context.Background())
+	loaded, err := worker.loadCheckpoint()
+	require.NoError(t, err)
+	assert.Equal(t, bson.Raw(resumeToken), loaded)
+}
@@ -0,0 +1,152 @@
+package mongoworker
+
+import (
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	option struct {
+		workerType               types.Worker
+		db                       *mongo.Database
+		maxGoroutines            int
+		debugMode                bool
+		locker                   interfaces.Locker
+		checkpoint               candiutils.Checkpoint
+		checkpointName           string
+		checkpointInterval       time.Duration
+		fullDocument             options.FullDocument
+		fullDocumentBeforeChange options.FullDocument
+		operationTypes           []string
+		reconnectInterval        time.Duration
+		onErrorFunc              func(error)
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+func getDefaultOption(service factory.ServiceFactory) option {
+	opt := option{
+		workerType:         types.MongoChangeStream,
+		maxGoroutines:      1,
+		debugMode:          true,
+		checkpointName:     string(service.Name()) + ":" + string(types.MongoChangeStream),
+		checkpointInterval: time.Second,
+		fullDocument:       options.UpdateLookup,
+		reconnectInterval:  time.Second,
+	}
+	opt.locker = &candiutils.NoopLocker{}
+	if deps := service.GetDependency(); deps != nil {
+		if mongoDB := deps.GetMongoDatabase(); mongoDB != nil {
+			opt.db = mongoDB.WriteDB()
+		}
+		if redisPool := deps.GetRedisPool(); redisPool != nil {
+			opt.locker = candiutils.NewRedisLocker(redisPool.WritePool())
+		} else if boltDB := deps.GetBoltDatabase(); boltDB != nil {
+			opt.locker = candiutils.NewBoltLocker(boltDB.DB())
+		}
+	}
+	return opt
+}
+
+// SetWorkerType option func
+func SetWorkerType(wt types.Worker) OptionFunc {
+	return func(o *option) {
+		o.workerType = wt
+	}
+}
+
+// SetMongoDatabase option func, database to be watched (default write database of dependency)
+func SetMongoDatabase(db *mongo.Database) OptionFunc {
+	return func(o *option) {
+		o.db = db
+	}
+}
+
+// SetMaxGoroutines option func, max concurrent event per collection (default 1, event is processed in order)
+func SetMaxGoroutines(maxGoroutines int) OptionFunc {
+	return func(o *option) {
+		o.maxGoroutines = maxGoroutines
+	}
+}
+
+// SetDebugMode option func
+func SetDebugMode(debugMode bool) OptionFunc {
+	return func(o *option) {
+		o.debugMode = debugMode
+	}
+}
+
+// SetLocker option func
+func SetLocker(locker interfaces.Locker) OptionFunc {
+	return func(o *option) {
+		o.locker = locker
+	}
+}
+
+// SetCheckpoint option func, store of last processed resume token (default collection "candi_checkpoints" in watched
+// database), change stream is resumed from checkpoint after restart
+func SetCheckpoint(checkpoint candiutils.Checkpoint) OptionFunc {
+	return func(o *option) {
+		o.checkpoint = checkpoint
+	}
+}
+
+// SetCheckpointName option func, name of resume token in checkpoint store (default "{service name}:mongo_change_stream"),
+// use different name for each worker watching different database
+func SetCheckpointName(name string) OptionFunc {
+	return func(o *option) {
+		o.checkpointName = name
+	}
+}
+
+// SetCheckpointInterval option func, interval of saving resume token to checkpoint store (default 1 second)
+func SetCheckpointInterval(interval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.checkpointInterval = interval
+	}
+}
+
+// SetFullDocument option func, full document lookup of update event (default options.UpdateLookup),
+// options.Default for only send updated fields
+func SetFullDocument(fullDocument options.FullDocument) OptionFunc {
+	return func(o *option) {
+		o.fullDocument = fullDocument
+	}
+}
+
+// SetFullDocumentBeforeChange option func, document before change (options.WhenAvailable or options.Required),
+// collection must be enabled changeStreamPreAndPostImages (MongoDB 6.0+)
+func SetFullDocumentBeforeChange(fullDocument options.FullDocument) OptionFunc {
+	return func(o *option) {
+		o.fullDocumentBeforeChange = fullDocument
+	}
+}
+
+// SetOperationTypes option func, only watch selected operation (default insert, update, replace and delete)
+func SetOperationTypes(operationTypes ...string) OptionFunc {
+	return func(o *option) {
+		o.operationTypes = operationTypes
+	}
+}
+
+// SetReconnectInterval option func, wait interval before reopen change stream after error (default 1 second)
+func SetReconnectInterval(interval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.reconnectInterval = interval
+	}
+}
+
+// SetOnErrorCallback option func, called when change stream is failed (before reconnect)
+func SetOnErrorCallback(callback func(error)) OptionFunc {
+	return func(o *option) {
+		o.onErrorFunc = callback
+	}
+}
@@ -0,0 +1,43 @@
+package postgresnotifyworker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// MaxPayloadSize max size of notification payload in bytes (limit of postgres server)
+const MaxPayloadSize = 8000
+
+// ErrPayloadTooLarge returned when notification payload exceed MaxPayloadSize
+var ErrPayloadTooLarge = errors.New("postgres notify: payload too large, must be less than 8000 bytes")
+
+// Execer abstraction of *sql.DB, *sql.Tx or *sql.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Notify send notification to channel, payload string or []byte is sent as is and other type is marshaled to JSON.
+// When db is transaction, notification is delivered to listener after transaction committed
+func Notify(ctx context.Context, db Execer, channel string, payload any) error {
+	var message string
+	switch p := payload.(type) {
+	case string:
+		message = p
+	case []byte:
+		message = string(p)
+	default:
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		message = string(b)
+	}
+	if len(message) >= MaxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+
+	_, err := db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, message)
+	return err
+}
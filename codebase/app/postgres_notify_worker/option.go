@@ -0,0 +1,101 @@
+package postgresnotifyworker
+
+import (
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/config/env"
+)
+
+type (
+	option struct {
+		workerType            types.Worker
+		dsn                   string
+		maxGoroutines         int
+		debugMode             bool
+		minReconnectInterval  time.Duration
+		maxReconnectInterval  time.Duration
+		pingInterval          time.Duration
+		onErrorConnectionFunc func(error)
+		onReconnectFunc       func()
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+func getDefaultOption() option {
+	return option{
+		workerType:           types.PostgresNotify,
+		dsn:                  env.BaseEnv().DbSQLWriteDSN,
+		maxGoroutines:        1,
+		debugMode:            true,
+		minReconnectInterval: 500 * time.Millisecond,
+		maxReconnectInterval: time.Minute,
+		pingInterval:         90 * time.Second,
+	}
+}
+
+// SetWorkerType option func
+func SetWorkerType(wt types.Worker) OptionFunc {
+	return func(o *option) {
+		o.workerType = wt
+	}
+}
+
+// SetPostgresDSN option func (default SQL write DSN from environment)
+func SetPostgresDSN(dsn string) OptionFunc {
+	return func(o *option) {
+		o.dsn = dsn
+	}
+}
+
+// SetMaxGoroutines option func, max concurrent notification per channel (default 1, notification is processed in order)
+func SetMaxGoroutines(maxGoroutines int) OptionFunc {
+	return func(o *option) {
+		o.maxGoroutines = maxGoroutines
+	}
+}
+
+// SetDebugMode option func
+func SetDebugMode(debugMode bool) OptionFunc {
+	return func(o *option) {
+		o.debugMode = debugMode
+	}
+}
+
+// SetMinReconnectInterval option func, first wait interval before reconnect after connection is lost (default 500ms)
+func SetMinReconnectInterval(minReconnectInterval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.minReconnectInterval = minReconnectInterval
+	}
+}
+
+// SetMaxReconnectInterval option func, wait interval is doubled on each failed reconnect up to this value (default 1 minute)
+func SetMaxReconnectInterval(maxReconnectInterval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.maxReconnectInterval = maxReconnectInterval
+	}
+}
+
+// SetPingInterval option func, ping idle connection for detect broken connection early (default 90 seconds)
+func SetPingInterval(pingInterval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.pingInterval = pingInterval
+	}
+}
+
+// SetOnErrorConnectionCallback option func for add error connection callback
+func SetOnErrorConnectionCallback(callback func(error)) OptionFunc {
+	return func(o *option) {
+		o.onErrorConnectionFunc = callback
+	}
+}
+
+// SetOnReconnectCallback option func, called after connection is re-established, notification sent while
+// disconnected is lost so use this callback for resync state from database
+func SetOnReconnectCallback(callback func()) OptionFunc {
+	return func(o *option) {
+		o.onReconnectFunc = callback
+	}
+}
@@ -0,0 +1,271 @@
+package postgresnotifyworker
+
+// Postgres LISTEN/NOTIFY worker codebase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/config/database"
+	"github.com/golangid/candi/contract"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"github.com/lib/pq"
+)
+
+type (
+	postgresNotifyWorker struct {
+		ctx           context.Context
+		ctxCancelFunc func()
+		opt           option
+		listener      *pq.Listener
+		shutdown      chan struct{}
+
+		service     factory.ServiceFactory
+		handlers    map[string]types.WorkerHandler
+		semaphores  map[string]chan struct{}
+		wg          sync.WaitGroup
+		messagePool sync.Pool
+	}
+)
+
+/*
+NewWorker create postgres LISTEN/NOTIFY worker, listen channel from module worker handler pattern and pass
+notification payload to handler of the channel. Connection is reconnected automatically when lost.
+Notification is delivered to every listening instance and is not persisted (lost when no listener is connected),
+use kafka or other durable broker for event that must not be lost
+*/
+func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppServerFactory {
+	worker := &postgresNotifyWorker{
+		service:    service,
+		opt:        getDefaultOption(),
+		shutdown:   make(chan struct{}, 1),
+		handlers:   make(map[string]types.WorkerHandler),
+		semaphores: make(map[string]chan struct{}),
+		messagePool: sync.Pool{
+			New: func() any {
+				return candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, 256)))
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(&worker.opt)
+	}
+
+	for _, m := range service.GetModules() {
+		if h := m.WorkerHandler(worker.opt.workerType); h != nil {
+			var handlerGroup types.WorkerHandlerGroup
+			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(worker.opt.workerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
+			for _, handler := range handlerGroup.Handlers {
+				if _, ok := worker.handlers[handler.Pattern]; ok {
+					log.Panicf("[POSTGRES-NOTIFY]%s: channel '%s' is registered more than once (in module '%s')",
+						getWorkerTypeLog(worker.opt.workerType), handler.Pattern, m.Name())
+				}
+				logger.LogYellow(fmt.Sprintf(`[POSTGRES-NOTIFY]%s (channel): %-15s  --> (module): "%s"`,
+					getWorkerTypeLog(worker.opt.workerType), `"`+handler.Pattern+`"`, m.Name()))
+				worker.handlers[handler.Pattern] = handler
+				worker.semaphores[handler.Pattern] = make(chan struct{}, max(worker.opt.maxGoroutines, 1))
+			}
+		}
+	}
+
+	worker.ctx, worker.ctxCancelFunc = context.WithCancel(context.Background())
+	if len(worker.handlers) == 0 {
+		log.Printf("postgres notify%s: no channel provided", getWorkerTypeLog(worker.opt.workerType))
+		return worker
+	}
+
+	_, dsn := database.ParseSQLDSN(worker.opt.dsn)
+	worker.listener = pq.NewListener(dsn, worker.opt.minReconnectInterval, worker.opt.maxReconnectInterval, worker.onListenerEvent)
+
+	fmt.Printf("\x1b[34;1m⇨ Postgres Notify worker%s running with %d channels\x1b[0m\n\n",
+		getWorkerTypeLog(worker.opt.workerType), len(worker.handlers))
+	return worker
+}
+
+func (p *postgresNotifyWorker) Serve() {
+	if p.listener == nil {
+		return
+	}
+
+	for _, channel := range p.channels() {
+		// block until connected, return error when listener is closed
+		if err := p.listener.Listen(channel); err != nil && err != pq.ErrChannelAlreadyOpen {
+			logger.LogRed("postgres_notify > failed listen channel " + channel + ": " + err.Error())
+			return
+		}
+	}
+
+	pingTicker := time.NewTicker(max(p.opt.pingInterval, time.Second))
+	defer pingTicker.Stop()
+	for {
+		select {
+		case <-p.shutdown:
+			return
+
+		case <-pingTicker.C:
+			// broken connection is detected and reconnected by listener
+			p.listener.Ping()
+
+		case notification, ok := <-p.listener.Notify:
+			if !ok {
+				return
+			}
+			if notification == nil {
+				// sent by listener after reconnected
+				if p.opt.onReconnectFunc != nil {
+					p.opt.onReconnectFunc()
+				}
+				continue
+			}
+
+			handler, ok := p.handlers[notification.Channel]
+			if !ok {
+				continue
+			}
+			select {
+			case p.semaphores[notification.Channel] <- struct{}{}:
+			case <-p.shutdown:
+				return
+			}
+			p.wg.Add(1)
+			go func() {
+				defer func() { <-p.semaphores[notification.Channel]; p.wg.Done() }()
+				p.execNotification(handler, notification)
+			}()
+		}
+	}
+}
+
+func (p *postgresNotifyWorker) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mStopping Postgres Notify%s:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), getWorkerTypeLog(p.opt.workerType), strings.Repeat(" ", 20))
+	}()
+
+	if p.listener == nil {
+		return
+	}
+
+	runningJob := 0
+	for _, sem := range p.semaphores {
+		runningJob += len(sem)
+	}
+	waitingJob := "... "
+	if runningJob != 0 {
+		waitingJob = fmt.Sprintf("waiting %d job until done... ", runningJob)
+	}
+	fmt.Printf("\r%s \x1b[33;1mStopping Postgres Notify%s:\x1b[0m %s",
+		time.Now().Format(candihelper.TimeFormatLogger), getWorkerTypeLog(p.opt.workerType), waitingJob)
+
+	p.shutdown <- struct{}{}
+	p.listener.Close()
+
+	done := make(chan struct{})
+	go func() { p.wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	p.ctxCancelFunc()
+}
+
+func (p *postgresNotifyWorker) Name() string {
+	return string(p.opt.workerType)
+}
+
+func (p *postgresNotifyWorker) channels() []string {
+	channels := make([]string, 0, len(p.handlers))
+	for channel := range p.handlers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (p *postgresNotifyWorker) onListenerEvent(ev pq.ListenerEventType, err error) {
+	switch ev {
+	case pq.ListenerEventConnected:
+		logger.LogYellow("[POSTGRES-NOTIFY] Ready to receive notification")
+	case pq.ListenerEventReconnected:
+		logger.LogYellow("[POSTGRES-NOTIFY] Reconnected, notification sent while disconnected is lost")
+	}
+	if err != nil {
+		logger.LogRed("[POSTGRES-NOTIFY] ERROR when listening: " + err.Error())
+		if p.opt.onErrorConnectionFunc != nil {
+			p.opt.onErrorConnectionFunc(err)
+		}
+	}
+}
+
+func (p *postgresNotifyWorker) execNotification(handler types.WorkerHandler, notification *pq.Notification) {
+	ctx := p.ctx
+	if handler.DisableTrace {
+		ctx = tracer.SkipTraceContext(ctx)
+	}
+
+	var err error
+	trace, ctx := tracer.StartTraceFromHeader(ctx, "PostgresNotify", make(map[string]string, 0))
+	defer func() {
+		if r := recover(); r != nil {
+			trace.SetTag("panic", true)
+			err = candiutils.ReportPanic(ctx, "PostgresNotify", r, map[string]any{
+				"channel": notification.Channel,
+			})
+		}
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+
+	trace.SetTag("channel", notification.Channel)
+	if p.opt.workerType != types.PostgresNotify {
+		trace.SetTag("worker_type", string(p.opt.workerType))
+	}
+	trace.Log("payload", notification.Extra)
+
+	if p.opt.debugMode {
+		log.Printf("\x1b[35;3mPostgres Notify%s: executing notification from channel: '%s'\x1b[0m",
+			getWorkerTypeLog(p.opt.workerType), notification.Channel)
+	}
+
+	eventContext := p.messagePool.Get().(*candishared.EventContext)
+	defer p.releaseMessagePool(eventContext)
+	eventContext.SetContext(ctx)
+	eventContext.SetWorkerType(string(p.opt.workerType))
+	eventContext.SetHandlerRoute(notification.Channel)
+	eventContext.SetHeader(map[string]string{
+		"channel": notification.Channel, "pid": strconv.Itoa(notification.BePid),
+	})
+	eventContext.WriteString(notification.Extra)
+
+	for _, handlerFunc := range handler.HandlerFuncs {
+		if err = handlerFunc(eventContext); err != nil {
+			eventContext.SetError(err)
+		}
+	}
+}
+
+func (p *postgresNotifyWorker) releaseMessagePool(eventContext *candishared.EventContext) {
+	eventContext.Reset()
+	p.messagePool.Put(eventContext)
+}
+
+func getWorkerTypeLog(name types.Worker) (workerType string) {
+	if name != types.PostgresNotify {
+		workerType = " [worker_type: " + string(name) + "]"
+	}
+	return
+}
@@ -20,6 +20,8 @@ type (
 		maxReconnectInterval  time.Duration
 		onErrorConnectionFunc func(error)
 		dbOption              func(*sql.DB)
+		slowStartInitial      int
+		slowStartWindow       time.Duration
 
 		sources map[string]*PostgresSource
 	}
@@ -39,6 +41,8 @@ func getDefaultOption(service factory.ServiceFactory) option {
 	}
 	if redisPool := service.GetDependency().GetRedisPool(); redisPool != nil {
 		opt.locker = candiutils.NewRedisLocker(redisPool.WritePool())
+	} else if boltDB := service.GetDependency().GetBoltDatabase(); boltDB != nil {
+		opt.locker = candiutils.NewBoltLocker(boltDB.DB())
 	} else {
 		opt.locker = &candiutils.NoopLocker{}
 	}
@@ -116,3 +120,11 @@ func SetDBOption(dbOption func(*sql.DB)) OptionFunc {
 		o.dbOption = dbOption
 	}
 }
+
+// SetSlowStart option func, ramp concurrency from initial to max goroutines over warm-up window after startup,
+// concurrency shared by all table (max is max goroutines multiplied by table count)
+func SetSlowStart(initial int, window time.Duration) OptionFunc {
+	return func(o *option) {
+		o.slowStartInitial, o.slowStartWindow = initial, window
+	}
+}
@@ -13,9 +13,11 @@ import (
 
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/contract"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
 	"github.com/lib/pq"
@@ -32,6 +34,7 @@ type (
 		ctxCancelFunc func()
 		opt           option
 		semaphores    map[string]chan struct{}
+		slowStart     *candiutils.SlowStart
 		shutdown      chan struct{}
 
 		workerSourceIndex []string
@@ -86,6 +89,8 @@ func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 		if h := m.WorkerHandler(worker.opt.workerType); h != nil {
 			var handlerGroup types.WorkerHandlerGroup
 			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(worker.opt.workerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
 			for _, handler := range handlerGroup.Handlers {
 				sourceName, tableName := ParseHandlerRoute(handler.Pattern)
 				postgresSource, ok := worker.opt.sources[sourceName]
@@ -115,6 +120,11 @@ func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 		fmt.Printf("\x1b[34;1m⇨ Postgres Event Listener%s running with %d handlers\x1b[0m\n\n", getWorkerTypeLog(worker.opt.workerType), len(worker.semaphores))
 	}
 
+	if worker.opt.slowStartWindow > 0 {
+		worker.slowStart = candiutils.NewSlowStart(worker.opt.slowStartInitial,
+			worker.opt.maxGoroutines*len(worker.semaphores), worker.opt.slowStartWindow)
+	}
+
 	worker.ctx, worker.ctxCancelFunc = context.WithCancel(context.Background())
 	return worker
 }
@@ -144,9 +154,13 @@ func (p *postgresWorker) Serve() {
 			json.Unmarshal([]byte(e.Extra), &payload)
 
 			p.semaphores[payload.Table] <- struct{}{}
+			if err := p.slowStart.Acquire(p.ctx); err != nil {
+				<-p.semaphores[payload.Table]
+				continue
+			}
 			p.wg.Add(1)
 			go func(data *EventPayload, workerIndex int) {
-				defer func() { p.wg.Done(); <-p.semaphores[data.Table] }()
+				defer func() { p.wg.Done(); p.slowStart.Release(); <-p.semaphores[data.Table] }()
 
 				if p.ctx.Err() != nil {
 					logger.LogRed("postgres_listener > ctx root err: " + p.ctx.Err().Error())
@@ -220,7 +234,9 @@ func (p *postgresWorker) execEvent(workerIndex int, data *EventPayload) {
 	defer func() {
 		if r := recover(); r != nil {
 			trace.SetTag("panic", true)
-			err = fmt.Errorf("%v", r)
+			err = candiutils.ReportPanic(ctx, "PostgresEventListener", r, map[string]any{
+				"table": data.Table, "action": data.Action,
+			})
 		}
 		trace.Finish(tracer.FinishWithError(err))
 	}()
@@ -1,10 +1,15 @@
 package rabbitmqworker
 
+import "time"
+
 type (
 	option struct {
 		consumerGroup string
 		maxGoroutines int
 		debugMode     bool
+
+		slowStartInitial int
+		slowStartWindow  time.Duration
 	}
 
 	// OptionFunc type
@@ -38,3 +43,11 @@ func SetConsumerGroup(consumerGroup string) OptionFunc {
 		o.consumerGroup = consumerGroup
 	}
 }
+
+// SetSlowStart option func, ramp concurrency from initial to max goroutines over warm-up window after startup,
+// concurrency shared by all queue
+func SetSlowStart(initial int, window time.Duration) OptionFunc {
+	return func(o *option) {
+		o.slowStartInitial, o.slowStartWindow = initial, window
+	}
+}
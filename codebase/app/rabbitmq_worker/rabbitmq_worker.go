@@ -2,6 +2,7 @@ package rabbitmqworker
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	"log"
@@ -13,9 +14,11 @@ import (
 	"github.com/golangid/candi/broker"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/contract"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -31,6 +34,7 @@ type rabbitmqWorker struct {
 	shutdown   chan struct{}
 	isShutdown bool
 	semaphore  []chan struct{}
+	slowStart  *candiutils.SlowStart
 	wg         sync.WaitGroup
 	receiver   []reflect.SelectCase
 	handlers   map[string]types.WorkerHandler
@@ -60,6 +64,8 @@ func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...Opt
 		if h := m.WorkerHandler(rabbitMQBroker.WorkerType); h != nil {
 			var handlerGroup types.WorkerHandlerGroup
 			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(rabbitMQBroker.WorkerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
 			for _, handler := range handlerGroup.Handlers {
 				logger.LogYellow(fmt.Sprintf(`[RABBITMQ-CONSUMER]%s (queue): %-15s  --> (module): "%s"`, getWorkerTypeLog(rabbitMQBroker.WorkerType), `"`+handler.Pattern+`"`, m.Name()))
 				queueChan, err := setupQueueConfig(worker.bk.Channel, worker.opt.consumerGroup, rabbitMQBroker.Exchange, handler.Pattern)
@@ -76,6 +82,10 @@ func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...Opt
 		}
 	}
 
+	if worker.opt.slowStartWindow > 0 {
+		worker.slowStart = candiutils.NewSlowStart(worker.opt.slowStartInitial, len(worker.receiver), worker.opt.slowStartWindow)
+	}
+
 	fmt.Printf("\x1b[34;1m⇨ RabbitMQ consumer%s running with %d queue. Broker: %s\x1b[0m\n\n", getWorkerTypeLog(rabbitMQBroker.WorkerType), len(worker.receiver),
 		candihelper.MaskingPasswordURL(rabbitMQBroker.BrokerHost))
 
@@ -102,11 +112,16 @@ func (r *rabbitmqWorker) Serve() {
 			if r.isShutdown {
 				return
 			}
+			if err := r.slowStart.Acquire(r.ctx); err != nil {
+				<-r.semaphore[chosen]
+				return
+			}
 
 			r.wg.Add(1)
 			go func(message amqp.Delivery, idx int) {
 				defer func() {
 					r.wg.Done()
+					r.slowStart.Release()
 					<-r.semaphore[idx]
 				}()
 				r.processMessage(message)
@@ -159,13 +174,20 @@ func (r *rabbitmqWorker) processMessage(message amqp.Delivery) {
 	for key, val := range message.Headers {
 		header[key] = string(candihelper.ToBytes(val))
 	}
+	if tenantID := header[candihelper.HeaderXTenantID]; tenantID != "" {
+		ctx = candishared.SetTenantToContext(ctx, tenantID)
+	}
+	ctx = candishared.ExtractBaggage(ctx, header)
+	ctx, _ = candishared.ExtractRequestID(ctx, header)
 
 	var err error
 	trace, ctx := tracer.StartTraceFromHeader(ctx, "RabbitMQConsumer", header)
 	defer func() {
 		if r := recover(); r != nil {
 			trace.SetTag("panic", true)
-			err = fmt.Errorf("%v", r)
+			err = candiutils.ReportPanic(ctx, "RabbitMQConsumer", r, map[string]any{
+				"exchange": message.Exchange, "routing_key": message.RoutingKey, "message_id": message.MessageId,
+			})
 		}
 		if selectedHandler.AutoACK {
 			message.Ack(false)
@@ -192,7 +214,16 @@ func (r *rabbitmqWorker) processMessage(message amqp.Delivery) {
 	eventContext.SetHandlerRoute(message.RoutingKey)
 	eventContext.SetHeader(header)
 	eventContext.SetKey(message.Exchange)
-	eventContext.Write(message.Body)
+	if cloudEvent, data, ok := candishared.ParseCloudEvent(map[string]string{"content-type": message.ContentType}, message.Body); ok {
+		eventContext.SetCloudEvent(cloudEvent)
+		eventContext.Write(data)
+	} else if cloudEvent, data, ok := candishared.ParseCloudEvent(header, message.Body); ok {
+		cloudEvent.DataContentType = cmp.Or(cloudEvent.DataContentType, message.ContentType)
+		eventContext.SetCloudEvent(cloudEvent)
+		eventContext.Write(data)
+	} else {
+		eventContext.Write(message.Body)
+	}
 
 	for _, handlerFunc := range selectedHandler.HandlerFuncs {
 		if err = handlerFunc(eventContext); err != nil {
@@ -1,12 +1,19 @@
 package redisworker
 
-import "github.com/golangid/candi/codebase/interfaces"
+import (
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
 
 type (
 	option struct {
 		maxGoroutines int
 		locker        interfaces.Locker
 		debugMode     bool
+
+		slowStartInitial int
+		slowStartWindow  time.Duration
 	}
 
 	// OptionFunc type
@@ -33,3 +40,11 @@ func SetDebugMode(debugMode bool) OptionFunc {
 		o.debugMode = debugMode
 	}
 }
+
+// SetSlowStart option func, ramp concurrency from initial to max goroutines over warm-up window after startup,
+// concurrency shared by all handler (max is max goroutines multiplied by handler count)
+func SetSlowStart(initial int, window time.Duration) OptionFunc {
+	return func(o *option) {
+		o.slowStartInitial, o.slowStartWindow = initial, window
+	}
+}
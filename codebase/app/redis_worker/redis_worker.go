@@ -18,6 +18,7 @@ import (
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/contract"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
 	"github.com/gomodule/redigo/redis"
@@ -35,6 +36,7 @@ type (
 		handlers    map[string]types.WorkerHandler
 		wg          sync.WaitGroup
 		semaphore   map[string]chan struct{}
+		slowStart   *candiutils.SlowStart
 		messagePool sync.Pool
 	}
 )
@@ -69,6 +71,8 @@ func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...Opt
 		if h := m.WorkerHandler(workerInstance.bk.WorkerType); h != nil {
 			var handlerGroup types.WorkerHandlerGroup
 			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(workerInstance.bk.WorkerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
 			for _, handler := range handlerGroup.Handlers {
 				logger.LogYellow(fmt.Sprintf(`[REDIS-SUBSCRIBER]%s (key prefix): %-15s  --> (module): "%s"`, getWorkerTypeLog(workerInstance.bk.WorkerType), `"`+handler.Pattern+`"`, m.Name()))
 				workerInstance.semaphore[handler.Pattern] = make(chan struct{}, workerInstance.opt.maxGoroutines)
@@ -84,6 +88,10 @@ func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...Opt
 	}
 
 	workerInstance.handlers = handlers
+	if workerInstance.opt.slowStartWindow > 0 {
+		workerInstance.slowStart = candiutils.NewSlowStart(workerInstance.opt.slowStartInitial,
+			workerInstance.opt.maxGoroutines*len(handlers), workerInstance.opt.slowStartWindow)
+	}
 	workerInstance.isHaveJob = len(handlers) != 0
 	workerInstance.ctx, workerInstance.ctxCancelFunc = context.WithCancel(context.Background())
 
@@ -102,10 +110,15 @@ func (r *redisWorker) Serve() {
 			redisMessage := broker.ParseRedisPubSubKeyTopic(msg.Data)
 			if _, ok := r.handlers[redisMessage.HandlerName]; ok {
 				r.semaphore[redisMessage.HandlerName] <- struct{}{}
+				if err := r.slowStart.Acquire(r.ctx); err != nil {
+					<-r.semaphore[redisMessage.HandlerName]
+					continue
+				}
 				r.wg.Add(1)
 				go func(message broker.RedisMessage) {
 					defer func() {
 						r.wg.Done()
+						r.slowStart.Release()
 						<-r.semaphore[message.HandlerName]
 					}()
 
@@ -186,7 +199,9 @@ func (r *redisWorker) processMessage(param broker.RedisMessage) {
 	defer func() {
 		if r := recover(); r != nil {
 			trace.SetTag("panic", true)
-			err = fmt.Errorf("%v", r)
+			err = candiutils.ReportPanic(ctx, "RedisSubscriber", r, map[string]any{
+				"handler_name": param.HandlerName, "event_id": param.EventID,
+			})
 		}
 		trace.Finish(tracer.FinishWithError(err))
 	}()
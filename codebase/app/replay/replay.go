@@ -0,0 +1,280 @@
+// Package replay re-consume message of Kafka topic or RabbitMQ stream queue between offset/timestamp, filter by key/header
+// and feed them through registered worker handler (live mode) or only preview matched message (dry-run mode).
+// Used for recovering data after bad deploy
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/contract"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"github.com/golangid/candi/wrapper"
+)
+
+// HeaderReplay header set in event context of replayed message, handler can check it to skip side effect
+const HeaderReplay = "x-replay"
+
+type (
+	// Request replay request
+	Request struct {
+		// Source worker type of registered source (example "kafka" or "rabbitmq")
+		Source string `json:"source"`
+		// Topic topic or queue name, must be handler route of worker
+		Topic      string    `json:"topic"`
+		Partitions []int32   `json:"partitions,omitempty"`
+		FromOffset *int64    `json:"from_offset,omitempty"`
+		ToOffset   *int64    `json:"to_offset,omitempty"`
+		FromTime   time.Time `json:"from_time,omitempty"`
+		ToTime     time.Time `json:"to_time,omitempty"`
+		// Key filter message key, support glob pattern (example "order-*")
+		Key string `json:"key,omitempty"`
+		// Header filter message header, all header must match, value support glob pattern
+		Header map[string]string `json:"header,omitempty"`
+		// Limit max matched message, zero is unlimited
+		Limit       int  `json:"limit,omitempty"`
+		DryRun      bool `json:"dry_run"`
+		StopOnError bool `json:"stop_on_error,omitempty"`
+	}
+
+	// Result replay result
+	Result struct {
+		DryRun    bool `json:"dry_run"`
+		Scanned   int  `json:"scanned"`
+		Matched   int  `json:"matched"`
+		Processed int  `json:"processed"`
+		Failed    int  `json:"failed"`
+		// Messages preview of matched message in dry-run mode
+		Messages []Message      `json:"messages,omitempty"`
+		Errors   []MessageError `json:"errors,omitempty"`
+	}
+
+	// MessageError failed replayed message
+	MessageError struct {
+		Partition int32  `json:"partition"`
+		Offset    int64  `json:"offset"`
+		Key       string `json:"key"`
+		Error     string `json:"error"`
+	}
+
+	// OptionFunc type
+	OptionFunc func(*Replayer)
+
+	// Replayer message replayer
+	Replayer struct {
+		service    factory.ServiceFactory
+		sources    map[types.Worker]Source
+		maxPreview int
+		maxErrors  int
+
+		mu       sync.Mutex
+		handlers map[types.Worker]map[string]types.WorkerHandler
+	}
+)
+
+// AddSource option func, register source of worker type, message is replayed to worker handler of that worker type
+func AddSource(workerType types.Worker, source Source) OptionFunc {
+	return func(r *Replayer) {
+		r.sources[workerType] = source
+	}
+}
+
+// SetMaxPreview option func, max message in dry-run result (default 100)
+func SetMaxPreview(maxPreview int) OptionFunc {
+	return func(r *Replayer) {
+		r.maxPreview = maxPreview
+	}
+}
+
+// SetMaxErrors option func, max failed message detail in result (default 100)
+func SetMaxErrors(maxErrors int) OptionFunc {
+	return func(r *Replayer) {
+		r.maxErrors = maxErrors
+	}
+}
+
+// NewReplayer create message replayer
+func NewReplayer(service factory.ServiceFactory, opts ...OptionFunc) *Replayer {
+	r := &Replayer{
+		service:    service,
+		sources:    make(map[types.Worker]Source),
+		maxPreview: 100,
+		maxErrors:  100,
+		handlers:   make(map[types.Worker]map[string]types.WorkerHandler),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Replay read message from source in range of request and process matched message
+func (r *Replayer) Replay(ctx context.Context, req *Request) (*Result, error) {
+	source, ok := r.sources[types.Worker(req.Source)]
+	if !ok {
+		return nil, fmt.Errorf("replay source '%s' is not registered", req.Source)
+	}
+	handler, ok := r.getHandlers(types.Worker(req.Source))[req.Topic]
+	if !ok && !req.DryRun {
+		return nil, fmt.Errorf("handler of topic '%s' in worker '%s' not found", req.Topic, req.Source)
+	}
+
+	result := &Result{DryRun: req.DryRun}
+	var errStop error
+	err := source.Fetch(ctx, req, func(message *Message) bool {
+		result.Scanned++
+		if !req.match(message) {
+			return true
+		}
+		result.Matched++
+
+		if req.DryRun {
+			if len(result.Messages) < r.maxPreview {
+				result.Messages = append(result.Messages, *message)
+			}
+		} else if err := r.processMessage(ctx, types.Worker(req.Source), handler, message); err != nil {
+			result.Failed++
+			if len(result.Errors) < r.maxErrors {
+				result.Errors = append(result.Errors, MessageError{
+					Partition: message.Partition, Offset: message.Offset, Key: message.Key, Error: err.Error(),
+				})
+			}
+			if req.StopOnError {
+				errStop = err
+				return false
+			}
+		} else {
+			result.Processed++
+		}
+		return req.Limit <= 0 || result.Matched < req.Limit
+	})
+	logger.LogI(fmt.Sprintf("replay: %s '%s' (dry run: %t) scanned %d, matched %d, processed %d, failed %d",
+		req.Source, req.Topic, req.DryRun, result.Scanned, result.Matched, result.Processed, result.Failed))
+	return result, errors.Join(err, errStop)
+}
+
+// HTTPHandler admin http handler, POST request body is Request and response is Result
+func (r *Replayer) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(rw)
+			return
+		}
+		var payload Request
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid request body", err).JSON(rw)
+			return
+		}
+		result, err := r.Replay(req.Context(), &payload)
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, err.Error(), result).JSON(rw)
+			return
+		}
+		wrapper.NewHTTPResponse(http.StatusOK, "Success replay message", result).JSON(rw)
+	})
+}
+
+// getHandlers mount worker handler of all module lazily, so replayer can be created before module is ready
+func (r *Replayer) getHandlers(workerType types.Worker) map[string]types.WorkerHandler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if handlers, ok := r.handlers[workerType]; ok {
+		return handlers
+	}
+
+	handlers := make(map[string]types.WorkerHandler)
+	for _, m := range r.service.GetModules() {
+		if h := m.WorkerHandler(workerType); h != nil {
+			var handlerGroup types.WorkerHandlerGroup
+			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(workerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
+			for _, handler := range handlerGroup.Handlers {
+				handlers[handler.Pattern] = handler
+			}
+		}
+	}
+	r.handlers[workerType] = handlers
+	return handlers
+}
+
+func (r *Replayer) processMessage(ctx context.Context, workerType types.Worker, handler types.WorkerHandler, message *Message) (err error) {
+	header := make(map[string]string, len(message.Header)+1)
+	for key, val := range message.Header {
+		header[key] = val
+	}
+	header[HeaderReplay] = "true"
+	if tenantID := header[candihelper.HeaderXTenantID]; tenantID != "" {
+		ctx = candishared.SetTenantToContext(ctx, tenantID)
+	}
+	ctx = candishared.ExtractBaggage(ctx, header)
+	ctx, _ = candishared.ExtractRequestID(ctx, header)
+	if handler.DisableTrace {
+		ctx = tracer.SkipTraceContext(ctx)
+	}
+
+	trace, ctx := tracer.StartTraceFromHeader(ctx, "Replay", header)
+	defer func() {
+		if rec := recover(); rec != nil {
+			trace.SetTag("panic", true)
+			err = candiutils.ReportPanic(ctx, "Replay", rec, map[string]any{
+				"topic": message.Topic, "partition": message.Partition, "offset": message.Offset, "key": message.Key,
+			})
+		}
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+	trace.SetTag("worker_type", string(workerType))
+	trace.SetTag("topic", message.Topic)
+	trace.SetTag("key", message.Key)
+	trace.Log("header", header)
+	trace.Log("message", message.Value)
+
+	eventContext := candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, len(message.Value))))
+	eventContext.SetContext(ctx)
+	eventContext.SetWorkerType(string(workerType))
+	eventContext.SetHandlerRoute(message.Topic)
+	eventContext.SetHeader(header)
+	eventContext.SetKey(message.Key)
+	if cloudEvent, data, ok := candishared.ParseCloudEvent(header, message.Value); ok {
+		eventContext.SetCloudEvent(cloudEvent)
+		eventContext.Write(data)
+	} else {
+		eventContext.Write(message.Value)
+	}
+
+	var errs []error
+	for _, handlerFunc := range handler.HandlerFuncs {
+		if err := handlerFunc(eventContext); err != nil {
+			eventContext.SetError(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (req *Request) match(message *Message) bool {
+	if req.Key != "" {
+		if ok, _ := path.Match(req.Key, message.Key); !ok {
+			return false
+		}
+	}
+	for key, pattern := range req.Header {
+		if ok, _ := path.Match(pattern, message.Header[key]); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+type workerHandler func(group *types.WorkerHandlerGroup)
+
+func (w workerHandler) MountHandlers(group *types.WorkerHandlerGroup) { w(group) }
+
+type sourceFunc func(ctx context.Context, req *Request, yield func(*Message) bool) error
+
+func (s sourceFunc) Fetch(ctx context.Context, req *Request, yield func(*Message) bool) error {
+	return s(ctx, req, yield)
+}
+
+func TestReplayer(t *testing.T) {
+	var processed []string
+	module := &mockfactory.ModuleFactory{}
+	module.On("Name").Return(types.Module("order"))
+	module.On("WorkerHandler", types.Kafka).Return(workerHandler(func(group *types.WorkerHandlerGroup) {
+		group.Add("orders", func(eventContext *candishared.EventContext) error {
+			assert.Equal(t, "true", eventContext.Header()[HeaderReplay])
+			if eventContext.Key() == "order-3" {
+				return errors.New("invalid order")
+			}
+			processed = append(processed, eventContext.Key()+":"+string(eventContext.Message()))
+			return nil
+		})
+	}))
+	service := &mockfactory.ServiceFactory{}
+	service.On("GetModules").Return([]factory.ModuleFactory{module})
+
+	source := sourceFunc(func(ctx context.Context, req *Request, yield func(*Message) bool) error {
+		regions := []string{"id", "id", "id", "sg"}
+		for i, key := range []string{"order-1", "invoice-2", "order-3", "order-4"} {
+			if !yield(&Message{
+				Topic: req.Topic, Offset: int64(i), Key: key, Value: []byte(`{"id":` + key[len(key)-1:] + `}`),
+				Header: map[string]string{"region": regions[i]},
+			}) {
+				return nil
+			}
+		}
+		return nil
+	})
+	replayer := NewReplayer(service, AddSource(types.Kafka, source))
+
+	result, err := replayer.Replay(context.Background(), &Request{Source: "kafka", Topic: "orders", Key: "order-*", DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Scanned)
+	assert.Equal(t, 3, result.Matched)
+	assert.Len(t, result.Messages, 3)
+	assert.Empty(t, processed)
+
+	result, err = replayer.Replay(context.Background(), &Request{Source: "kafka", Topic: "orders", Key: "order-*", Header: map[string]string{"region": "id"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 1, result.Processed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "invalid order", result.Errors[0].Error)
+	assert.Equal(t, []string{`order-1:{"id":1}`}, processed)
+
+	result, err = replayer.Replay(context.Background(), &Request{Source: "kafka", Topic: "orders", Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Scanned)
+
+	rec := httptest.NewRecorder()
+	replayer.HTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/replay",
+		strings.NewReader(`{"source":"kafka","topic":"orders","key":"order-3","stop_on_error":true}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"failed":1`)
+
+	_, err = replayer.Replay(context.Background(), &Request{Source: "rabbitmq", Topic: "orders"})
+	assert.Error(t, err)
+}
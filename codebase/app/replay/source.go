@@ -0,0 +1,232 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/candihelper"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type (
+	// Message replayed message
+	Message struct {
+		Topic     string            `json:"topic"`
+		Partition int32             `json:"partition"`
+		Offset    int64             `json:"offset"`
+		Key       string            `json:"key"`
+		Header    map[string]string `json:"header"`
+		Value     []byte            `json:"value"`
+		Timestamp time.Time         `json:"timestamp"`
+	}
+
+	// Source read message of topic/queue in range of request, stop reading when yield return false
+	Source interface {
+		Fetch(ctx context.Context, req *Request, yield func(*Message) bool) error
+	}
+)
+
+// MarshalJSON encode value as string for readable preview
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	return json.Marshal(struct {
+		message
+		Value string `json:"value"`
+	}{message: message(m), Value: string(m.Value)})
+}
+
+type kafkaSource struct {
+	client sarama.Client
+}
+
+// NewKafkaSource read message of topic partitions from kafka client (example broker.KafkaBroker.Client),
+// partitions are read one by one in offset order
+func NewKafkaSource(client sarama.Client) Source {
+	return &kafkaSource{client: client}
+}
+
+func (s *kafkaSource) Fetch(ctx context.Context, req *Request, yield func(*Message) bool) error {
+	partitions := req.Partitions
+	if len(partitions) == 0 {
+		var err error
+		if partitions, err = s.client.Partitions(req.Topic); err != nil {
+			return err
+		}
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(s.client)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	for _, partition := range partitions {
+		start, end, err := s.offsetRange(req, partition)
+		if err != nil {
+			return err
+		}
+		if start > end {
+			continue
+		}
+		stop, err := s.fetchPartition(ctx, consumer, req, partition, start, end, yield)
+		if err != nil || stop {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *kafkaSource) offsetRange(req *Request, partition int32) (start, end int64, err error) {
+	if start, err = s.client.GetOffset(req.Topic, partition, sarama.OffsetOldest); err != nil {
+		return 0, 0, err
+	}
+	newest, err := s.client.GetOffset(req.Topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, err
+	}
+	end = newest - 1
+
+	if req.FromOffset != nil {
+		start = max(start, *req.FromOffset)
+	}
+	if !req.FromTime.IsZero() {
+		offset, err := s.client.GetOffset(req.Topic, partition, req.FromTime.UnixMilli())
+		if err != nil {
+			return 0, 0, err
+		}
+		if offset == sarama.OffsetNewest {
+			// no message after from time
+			return 1, 0, nil
+		}
+		start = max(start, offset)
+	}
+	if req.ToOffset != nil {
+		end = min(end, *req.ToOffset)
+	}
+	return start, end, nil
+}
+
+func (s *kafkaSource) fetchPartition(ctx context.Context, consumer sarama.Consumer, req *Request, partition int32, start, end int64,
+	yield func(*Message) bool) (stop bool, err error) {
+
+	partitionConsumer, err := consumer.ConsumePartition(req.Topic, partition, start)
+	if err != nil {
+		return false, err
+	}
+	defer partitionConsumer.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+
+		case err := <-partitionConsumer.Errors():
+			return true, err
+
+		case msg := <-partitionConsumer.Messages():
+			if msg == nil {
+				return false, nil
+			}
+			if !req.ToTime.IsZero() && msg.Timestamp.After(req.ToTime) {
+				return false, nil
+			}
+
+			header := map[string]string{
+				"offset":    strconv.Itoa(int(msg.Offset)),
+				"partition": strconv.Itoa(int(msg.Partition)),
+				"timestamp": msg.Timestamp.Format(time.RFC3339),
+			}
+			for _, val := range msg.Headers {
+				header[string(val.Key)] = string(val.Value)
+			}
+			if !yield(&Message{
+				Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset, Key: string(msg.Key),
+				Header: header, Value: msg.Value, Timestamp: msg.Timestamp,
+			}) {
+				return true, nil
+			}
+			if msg.Offset >= end {
+				return false, nil
+			}
+		}
+	}
+}
+
+type rabbitMQSource struct {
+	conn        *amqp.Connection
+	idleTimeout time.Duration
+}
+
+// NewRabbitMQSource read message of RabbitMQ stream queue (x-queue-type=stream) from offset or timestamp, classic queue
+// cannot be replayed because consumed message is removed. Reading is finished when no message received in idle timeout
+func NewRabbitMQSource(conn *amqp.Connection, idleTimeout time.Duration) Source {
+	return &rabbitMQSource{conn: conn, idleTimeout: idleTimeout}
+}
+
+func (s *rabbitMQSource) Fetch(ctx context.Context, req *Request, yield func(*Message) bool) error {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	// consumer prefetch is required for stream queue
+	if err := ch.Qos(100, 0, false); err != nil {
+		return err
+	}
+
+	args := amqp.Table{"x-stream-offset": "first"}
+	if req.FromOffset != nil {
+		args["x-stream-offset"] = *req.FromOffset
+	}
+	if !req.FromTime.IsZero() {
+		args["x-stream-offset"] = req.FromTime
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	deliveries, err := ch.ConsumeWithContext(ctx, req.Topic, fmt.Sprintf("candi-replay-%d", time.Now().UnixNano()),
+		false, true, false, false, args)
+	if err != nil {
+		return err
+	}
+
+	idle := time.NewTimer(s.idleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-idle.C:
+			return nil
+
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return nil
+			}
+			delivery.Ack(false)
+			offset, _ := delivery.Headers["x-stream-offset"].(int64)
+			if req.ToOffset != nil && offset > *req.ToOffset {
+				return nil
+			}
+			if !req.ToTime.IsZero() && delivery.Timestamp.After(req.ToTime) {
+				return nil
+			}
+
+			header := make(map[string]string, len(delivery.Headers))
+			for key, val := range delivery.Headers {
+				header[key] = string(candihelper.ToBytes(val))
+			}
+			if !yield(&Message{
+				Topic: req.Topic, Offset: offset, Key: delivery.MessageId,
+				Header: header, Value: delivery.Body, Timestamp: delivery.Timestamp,
+			}) {
+				return nil
+			}
+			idle.Reset(s.idleTimeout)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/config/env"
 	"github.com/golangid/candi/logger"
@@ -97,6 +98,43 @@ func HTTPMiddlewareCORS(
 	}
 }
 
+// HTTPMiddlewareBaggage middleware for rehydrate propagated baggage header to request context
+func HTTPMiddlewareBaggage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if baggage := req.Header.Get(candishared.HeaderBaggage); baggage != "" {
+			req = req.WithContext(candishared.ExtractBaggage(req.Context(), map[string]string{candishared.HeaderBaggage: baggage}))
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// HTTPMiddlewareBodyLimit middleware for limit request body size, maxBodySize for all route and routeMaxBodySize for
+// route matched with glob pattern "{method} {path}" (zero for unlimited). Request with larger Content-Length is rejected
+// with 413 Request Entity Too Large, and streamed body read beyond limit return *http.MaxBytesError
+func HTTPMiddlewareBodyLimit(maxBodySize int64, routeMaxBodySize map[string]int64) func(http.Handler) http.Handler {
+	routeMatcher := candihelper.NewPatternMatcher(routeMaxBodySize)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			limit := maxBodySize
+			if routeLimit, ok := routeMatcher.Match(req.Method + " " + req.URL.Path); ok {
+				limit = routeLimit
+			}
+			if limit <= 0 || req.Body == nil || req.Body == http.NoBody {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			if req.ContentLength > limit {
+				wrapper.NewHTTPResponse(http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("Request body too large, max %d bytes", limit)).JSON(rw)
+				return
+			}
+			req.Body = http.MaxBytesReader(rw, req.Body, limit)
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
 // HTTPMiddlewareTracer middleware wrapper for tracer
 func HTTPMiddlewareTracer() func(http.Handler) http.Handler {
 	bPool := candiutils.NewSyncPool(func() *bytes.Buffer {
@@ -128,7 +166,9 @@ func HTTPMiddlewareTracer() func(http.Handler) http.Handler {
 			defer func() {
 				if rec := recover(); rec != nil {
 					trace.SetTag("panic", true)
-					trace.SetError(fmt.Errorf("%v", rec))
+					trace.SetError(candiutils.ReportPanic(ctx, "REST-Server", rec, map[string]any{
+						"method": req.Method, "url": candiutils.RedactURL(req.URL), "remote_addr": req.RemoteAddr,
+					}))
 					wrapper.NewHTTPResponse(http.StatusInternalServerError, "Something error").JSON(rw)
 				}
 				trace.Finish()
@@ -139,6 +179,9 @@ func HTTPMiddlewareTracer() func(http.Handler) http.Handler {
 			trace.SetTag("http.host", req.Host)
 			trace.SetTag("http.url_path", req.URL.Path)
 			trace.SetTag("http.method", req.Method)
+			if requestID := candishared.GetRequestID(ctx); requestID != "" {
+				trace.SetTag("request_id", requestID)
+			}
 
 			if contentLength, err := strconv.Atoi(req.Header.Get("Content-Length")); err == nil {
 				if contentLength < maxLogSize {
@@ -180,7 +223,7 @@ func HTTPMiddlewareTracer() func(http.Handler) http.Handler {
 			logBuff.WriteString(`{"time":"`)
 			logBuff.WriteString(time.Now().Format(time.RFC3339Nano))
 
-			if id := req.Header.Get("X-Request-ID"); id != "" {
+			if id := candishared.GetRequestID(ctx); id != "" {
 				logBuff.WriteString(`","id":"`)
 				logBuff.WriteString(id)
 			}
@@ -4,10 +4,13 @@ import (
 	"crypto/tls"
 	"net/http"
 	"strings"
+	"time"
 
 	graphqlserver "github.com/golangid/candi/codebase/app/graphql_server"
+	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/interfaces"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/i18n"
 	"github.com/golangid/candi/wrapper"
 	"github.com/soheilhy/cmux"
 )
@@ -27,6 +30,12 @@ type (
 		sharedListener      cmux.CMux
 		graphqlOption       graphqlserver.Option
 		tlsConfig           *tls.Config
+		maxBodySize         int64
+		routeMaxBodySize    map[string]int64
+		readHeaderTimeout   time.Duration
+		readTimeout         time.Duration
+		writeTimeout        time.Duration
+		idleTimeout         time.Duration
 	}
 
 	// OptionFunc type
@@ -39,14 +48,20 @@ var (
 
 func getDefaultOption() option {
 	return option{
-		httpPort:  8000,
-		rootPath:  "/",
-		debugMode: true,
+		httpPort:          8000,
+		rootPath:          "/",
+		debugMode:         true,
+		readHeaderTimeout: 10 * time.Second,
+		idleTimeout:       2 * time.Minute,
 		rootMiddlewares: []func(http.Handler) http.Handler{
+			wrapper.HTTPMiddlewareRequestID,
 			HTTPMiddlewareCORS(
 				env.BaseEnv().CORSAllowMethods, env.BaseEnv().CORSAllowHeaders,
 				env.BaseEnv().CORSAllowOrigins, nil, env.BaseEnv().CORSAllowCredential,
 			),
+			i18n.HTTPMiddleware,
+			HTTPMiddlewareBaggage,
+			factory.HTTPMiddlewareScope,
 		},
 		traceMiddleware: HTTPMiddlewareTracer(),
 		rootHandler:     http.HandlerFunc(wrapper.HTTPHandlerDefaultRoot),
@@ -150,3 +165,48 @@ func AddMountRouter(fn func(interfaces.RESTRouter)) OptionFunc {
 		o.routerFuncs = append(o.routerFuncs, fn)
 	}
 }
+
+// SetMaxBodySize option func, max request body size in bytes (default 0, unlimited). Request with larger Content-Length
+// is rejected with 413 before handler called, and body read beyond max size return error (see http.MaxBytesReader)
+func SetMaxBodySize(maxBodySize int64) OptionFunc {
+	return func(o *option) {
+		o.maxBodySize = maxBodySize
+	}
+}
+
+// SetRouteMaxBodySize option func, max request body size of route matched with glob pattern "{method} {path}"
+// (example: "POST /v1/upload/*"), override SetMaxBodySize for large upload route. Zero for unlimited body size of
+// matched route, longest pattern is matched first
+func SetRouteMaxBodySize(routeMaxBodySize map[string]int64) OptionFunc {
+	return func(o *option) {
+		o.routeMaxBodySize = routeMaxBodySize
+	}
+}
+
+// SetReadHeaderTimeout option func, max duration for reading request header for slow-loris protection (default 10 seconds)
+func SetReadHeaderTimeout(timeout time.Duration) OptionFunc {
+	return func(o *option) {
+		o.readHeaderTimeout = timeout
+	}
+}
+
+// SetReadTimeout option func, max duration for reading entire request include body (default 0, no timeout)
+func SetReadTimeout(timeout time.Duration) OptionFunc {
+	return func(o *option) {
+		o.readTimeout = timeout
+	}
+}
+
+// SetWriteTimeout option func, max duration before timing out writes of the response (default 0, no timeout)
+func SetWriteTimeout(timeout time.Duration) OptionFunc {
+	return func(o *option) {
+		o.writeTimeout = timeout
+	}
+}
+
+// SetIdleTimeout option func, max duration to wait for next request when keep-alive enabled (default 2 minutes)
+func SetIdleTimeout(timeout time.Duration) OptionFunc {
+	return func(o *option) {
+		o.idleTimeout = timeout
+	}
+}
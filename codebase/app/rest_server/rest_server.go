@@ -11,10 +11,14 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candiutils/graceful"
+	cronworker "github.com/golangid/candi/codebase/app/cron_worker"
 	graphqlserver "github.com/golangid/candi/codebase/app/graphql_server"
+	"github.com/golangid/candi/codebase/app/modulemap"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/migration"
 	"github.com/golangid/candi/wrapper"
 	"github.com/soheilhy/cmux"
 )
@@ -34,6 +38,10 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 	for _, opt := range opts {
 		opt(&server.opt)
 	}
+	if server.opt.maxBodySize > 0 || len(server.opt.routeMaxBodySize) > 0 {
+		server.opt.rootMiddlewares = append(server.opt.rootMiddlewares,
+			HTTPMiddlewareBodyLimit(server.opt.maxBodySize, server.opt.routeMaxBodySize))
+	}
 	if server.opt.traceMiddleware != nil {
 		server.opt.rootMiddlewares = append(server.opt.rootMiddlewares, server.opt.traceMiddleware)
 	}
@@ -45,6 +53,22 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 		r.Use(service.GetDependency().GetMiddleware().HTTPBasicAuth)
 		r.Get("/", http.HandlerFunc(wrapper.HTTPHandlerMemstats))
 	})
+	mux.Route("/loglevel", func(r chi.Router) {
+		r.Use(service.GetDependency().GetMiddleware().HTTPBasicAuth)
+		r.HandleFunc("/", http.HandlerFunc(wrapper.HTTPHandlerLogLevel))
+	})
+	mux.Route("/cronexpr", func(r chi.Router) {
+		r.Use(service.GetDependency().GetMiddleware().HTTPBasicAuth)
+		r.HandleFunc("/", http.HandlerFunc(cronworker.HTTPHandlerSchedulePreview))
+	})
+	mux.Route("/modules", func(r chi.Router) {
+		r.Use(service.GetDependency().GetMiddleware().HTTPBasicAuth)
+		r.Get("/", modulemap.HTTPHandler(service))
+	})
+	mux.Route("/seed", func(r chi.Router) {
+		r.Use(service.GetDependency().GetMiddleware().HTTPBasicAuth)
+		r.HandleFunc("/", migration.HTTPHandlerSeed(service.GetDependency()))
+	})
 	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
 		wrapper.NewHTTPResponse(http.StatusNotFound, fmt.Sprintf(`Resource "%s %s" not found`, r.Method, r.URL.Path)).JSON(w)
 	})
@@ -62,7 +86,7 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 
 	countRoute, maxLogRoute := 0, 20
 	chi.Walk(mux, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
-		if candihelper.StringInSlice(route, []string{"/", "/memstats/"}) {
+		if candihelper.StringInSlice(route, []string{"/", "/memstats/", "/loglevel/", "/cronexpr/", "/modules/", "/seed/"}) {
 			return nil
 		}
 
@@ -90,6 +114,10 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 
 	server.httpEngine.Addr = fmt.Sprintf(":%d", server.opt.httpPort)
 	server.httpEngine.Handler = mux
+	server.httpEngine.ReadHeaderTimeout = server.opt.readHeaderTimeout
+	server.httpEngine.ReadTimeout = server.opt.readTimeout
+	server.httpEngine.WriteTimeout = server.opt.writeTimeout
+	server.httpEngine.IdleTimeout = server.opt.idleTimeout
 
 	var httpOrHttps string = "HTTP"
 	if server.opt.tlsConfig != nil {
@@ -99,25 +127,24 @@ func NewServer(service factory.ServiceFactory, opts ...OptionFunc) factory.AppSe
 
 	if server.opt.sharedListener != nil {
 		server.listener = server.opt.sharedListener.Match(cmux.HTTP1Fast(http.MethodPatch))
+	} else {
+		// bind when construct, so port is ready to accept before notify graceful restart
+		var err error
+		server.listener, err = graceful.Listen("tcp", server.httpEngine.Addr)
+		if err != nil {
+			log.Panicf("REST TCP Listener: Unexpected Error: %v", err)
+		}
 	}
 
 	return server
 }
 
 func (s *restServer) Serve() {
-	var err error
-	if s.listener == nil {
-		s.listener, err = net.Listen("tcp", s.httpEngine.Addr)
-		if err != nil {
-			log.Panicf("REST TCP Listener: Unexpected Error: %v", err)
-		}
-	}
-
 	if s.opt.tlsConfig != nil {
 		s.httpEngine.TLSConfig = s.opt.tlsConfig
 		s.listener = tls.NewListener(s.listener, s.opt.tlsConfig)
 	}
-	err = s.httpEngine.Serve(s.listener)
+	err := s.httpEngine.Serve(s.listener)
 
 	switch err.(type) {
 	case *net.OpError:
@@ -17,6 +17,11 @@ type routeWrapper struct {
 	router chi.Router
 }
 
+// NewRouter wrap chi router as candi REST router (same route pattern transform as REST server), example for mount handler in test
+func NewRouter(router chi.Router) interfaces.RESTRouter {
+	return &routeWrapper{router: router}
+}
+
 func (r *routeWrapper) Use(middlewares ...func(http.Handler) http.Handler) {
 	r.router.Use(middlewares...)
 }
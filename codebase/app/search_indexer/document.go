@@ -0,0 +1,47 @@
+package searchindexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// ErrOutsideIndexer error when document func is called outside search indexer handler
+var ErrOutsideIndexer = errors.New("search indexer: context is not from search indexer handler")
+
+type documentsKey struct{}
+
+// IndexDocument create or replace document of index (alias) in handler, document is written in next bulk request
+func IndexDocument(ctx context.Context, index, id string, document any) error {
+	return addItem(ctx, interfaces.SearchBulkItem{Action: candiutils.SearchActionIndex, Index: index, ID: id, Document: document})
+}
+
+// UpdateDocument partial update document of index (alias) in handler
+func UpdateDocument(ctx context.Context, index, id string, partialDocument any) error {
+	return addItem(ctx, interfaces.SearchBulkItem{Action: candiutils.SearchActionUpdate, Index: index, ID: id, Document: partialDocument})
+}
+
+// DeleteDocument delete document of index (alias) in handler
+func DeleteDocument(ctx context.Context, index, id string) error {
+	return addItem(ctx, interfaces.SearchBulkItem{Action: candiutils.SearchActionDelete, Index: index, ID: id})
+}
+
+func addItem(ctx context.Context, item interfaces.SearchBulkItem) error {
+	items, ok := ctx.Value(documentsKey{}).(*[]interfaces.SearchBulkItem)
+	if !ok {
+		return ErrOutsideIndexer
+	}
+	// message buffer of event context is reused after handler returned
+	switch document := item.Document.(type) {
+	case []byte:
+		item.Document = bytes.Clone(document)
+	case json.RawMessage:
+		item.Document = bytes.Clone(document)
+	}
+	*items = append(*items, item)
+	return nil
+}
@@ -0,0 +1,109 @@
+package searchindexer
+
+import (
+	"time"
+
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/config/env"
+)
+
+type (
+	option struct {
+		workerType    types.Worker
+		consumerGroup string
+		engine        interfaces.SearchEngine
+		debugMode     bool
+		batchSize     int
+		flushInterval time.Duration
+		maxRetries    int
+		retryBackoff  time.Duration
+		indices       []indexDefinition
+		onItemError   func(interfaces.SearchBulkItem, interfaces.SearchBulkResult)
+	}
+
+	indexDefinition struct {
+		alias      string
+		definition any
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+func getDefaultOption(service factory.ServiceFactory) option {
+	opt := option{
+		workerType:    types.SearchIndexer,
+		consumerGroup: env.BaseEnv().Kafka.ConsumerGroup + "-search-indexer",
+		debugMode:     true,
+		batchSize:     500,
+		flushInterval: time.Second,
+		maxRetries:    5,
+		retryBackoff:  200 * time.Millisecond,
+	}
+	if deps := service.GetDependency(); deps != nil {
+		opt.engine = deps.GetSearchEngine()
+	}
+	return opt
+}
+
+// SetWorkerType option func
+func SetWorkerType(wt types.Worker) OptionFunc {
+	return func(o *option) {
+		o.workerType = wt
+	}
+}
+
+// SetConsumerGroup option func, must be different with kafka worker consumer group so both receive all message
+// (default "{KAFKA_CONSUMER_GROUP}-search-indexer")
+func SetConsumerGroup(consumerGroup string) OptionFunc {
+	return func(o *option) {
+		o.consumerGroup = consumerGroup
+	}
+}
+
+// SetSearchEngine option func (default search engine of dependency)
+func SetSearchEngine(engine interfaces.SearchEngine) OptionFunc {
+	return func(o *option) {
+		o.engine = engine
+	}
+}
+
+// SetDebugMode option func
+func SetDebugMode(debugMode bool) OptionFunc {
+	return func(o *option) {
+		o.debugMode = debugMode
+	}
+}
+
+// SetBatch option func, document of consumed message is written in one bulk request when message count reach size
+// (default 500) or every interval (default 1 second), message offset is committed after bulk request success
+func SetBatch(size int, interval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.batchSize, o.flushInterval = size, interval
+	}
+}
+
+// SetRetry option func, max retry of failed bulk request (default 5) and wait interval before first retry, doubled on
+// each retry (default 200ms). When still failed, partition is consumed again from last committed offset
+func SetRetry(maxRetries int, backoff time.Duration) OptionFunc {
+	return func(o *option) {
+		o.maxRetries, o.retryBackoff = maxRetries, backoff
+	}
+}
+
+// AddIndex option func, manage index of alias with definition (settings and mappings) before consume message,
+// when definition is changed documents is reindexed to new index and alias is moved (see candiutils.EnsureSearchIndex)
+func AddIndex(alias string, definition any) OptionFunc {
+	return func(o *option) {
+		o.indices = append(o.indices, indexDefinition{alias: alias, definition: definition})
+	}
+}
+
+// SetOnItemError option func, callback for document failed with non retriable error (example: mapping error)
+func SetOnItemError(onItemError func(item interfaces.SearchBulkItem, result interfaces.SearchBulkResult)) OptionFunc {
+	return func(o *option) {
+		o.onItemError = onItemError
+	}
+}
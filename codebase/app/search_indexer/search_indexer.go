@@ -0,0 +1,315 @@
+package searchindexer
+
+// Search indexer worker codebase, maintain search index from kafka topic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/broker"
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/contract"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+)
+
+type (
+	searchIndexer struct {
+		ctx           context.Context
+		ctxCancelFunc func()
+		bk            *broker.KafkaBroker
+		opt           option
+		consumer      sarama.ConsumerGroup
+		service       factory.ServiceFactory
+		handlers      map[string]types.WorkerHandler
+		topics        []string
+		messagePool   sync.Pool
+	}
+)
+
+/*
+NewWorker create search indexer worker, consume kafka topic from module worker handler (pattern is topic name) with own
+consumer group, and handler project message to search document with IndexDocument, UpdateDocument or DeleteDocument.
+Document of consumed message is written to search engine in bulk request and offset is committed after bulk request
+success, so message is delivered at least once and projection must be idempotent (use stable document id)
+*/
+func NewWorker(service factory.ServiceFactory, bk interfaces.Broker, opts ...OptionFunc) factory.AppServerFactory {
+	kafkaBroker, ok := bk.(*broker.KafkaBroker)
+	if !ok {
+		panic("Missing Kafka broker configuration")
+	}
+
+	worker := &searchIndexer{
+		bk:       kafkaBroker,
+		service:  service,
+		opt:      getDefaultOption(service),
+		handlers: make(map[string]types.WorkerHandler),
+		messagePool: sync.Pool{
+			New: func() any {
+				return candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, 256)))
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(&worker.opt)
+	}
+
+	for _, m := range service.GetModules() {
+		if h := m.WorkerHandler(worker.opt.workerType); h != nil {
+			var handlerGroup types.WorkerHandlerGroup
+			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(worker.opt.workerType, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
+			for _, handler := range handlerGroup.Handlers {
+				if _, ok := worker.handlers[handler.Pattern]; ok {
+					log.Panicf("[SEARCH-INDEXER]%s: topic '%s' is registered more than once (in module '%s')",
+						getWorkerTypeLog(worker.opt.workerType), handler.Pattern, m.Name())
+				}
+				worker.handlers[handler.Pattern] = handler
+				worker.topics = append(worker.topics, handler.Pattern)
+				logger.LogYellow(fmt.Sprintf(`[SEARCH-INDEXER]%s (topic): %-15s  --> (module): "%s"`,
+					getWorkerTypeLog(worker.opt.workerType), `"`+handler.Pattern+`"`, m.Name()))
+			}
+		}
+	}
+
+	worker.ctx, worker.ctxCancelFunc = context.WithCancel(context.Background())
+	if len(worker.topics) == 0 {
+		log.Printf("search indexer%s: no topic provided", getWorkerTypeLog(worker.opt.workerType))
+		return worker
+	}
+	if worker.opt.engine == nil {
+		log.Panicf("[SEARCH-INDEXER]%s: search engine is not set", getWorkerTypeLog(worker.opt.workerType))
+	}
+
+	consumer, err := sarama.NewConsumerGroupFromClient(worker.opt.consumerGroup, kafkaBroker.Client)
+	if err != nil {
+		log.Panicf("Error creating kafka consumer group client: %v", err)
+	}
+	worker.consumer = consumer
+
+	fmt.Printf("\x1b[34;1m⇨ Search indexer%s running with %d topics and %d managed indices. Consumer group: %s\x1b[0m\n\n",
+		getWorkerTypeLog(worker.opt.workerType), len(worker.topics), len(worker.opt.indices), worker.opt.consumerGroup)
+	return worker
+}
+
+func (w *searchIndexer) Serve() {
+	if w.consumer == nil {
+		return
+	}
+
+	// index must be ready before projection write document to alias
+	for _, index := range w.opt.indices {
+		for {
+			physicalIndex, err := candiutils.EnsureSearchIndex(w.ctx, w.opt.engine, index.alias, index.definition)
+			if err == nil {
+				logger.LogYellow(fmt.Sprintf(`[SEARCH-INDEXER] index alias "%s" --> "%s"`, index.alias, physicalIndex))
+				break
+			}
+			logger.LogRed("search_indexer > ensure index " + index.alias + ": " + err.Error())
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+
+	for {
+		if err := w.consumer.Consume(w.ctx, w.topics, w); err != nil {
+			logger.LogRed(fmt.Sprintf("Error from search indexer%s: %s", getWorkerTypeLog(w.opt.workerType), err.Error()))
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (w *searchIndexer) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mStopping Search Indexer%s:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), getWorkerTypeLog(w.opt.workerType), strings.Repeat(" ", 20))
+	}()
+
+	fmt.Printf("\r%s \x1b[33;1mStopping Search Indexer%s:\x1b[0m ... ", time.Now().Format(candihelper.TimeFormatLogger), getWorkerTypeLog(w.opt.workerType))
+	w.ctxCancelFunc()
+	if w.consumer != nil {
+		w.consumer.Close()
+	}
+}
+
+func (w *searchIndexer) Name() string {
+	return string(w.opt.workerType)
+}
+
+// Setup is run at the beginning of a new session, before ConsumeClaim
+func (w *searchIndexer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited
+func (w *searchIndexer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim project message to document and write in batch, offset of last message in batch is marked after
+// bulk request success. Return error (session is restarted from last committed offset) when bulk request failed
+func (w *searchIndexer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	indexer := candiutils.NewSearchBulkIndexer(w.opt.engine,
+		candiutils.SearchBulkIndexerSetFlushItems(0), candiutils.SearchBulkIndexerSetFlushInterval(0),
+		candiutils.SearchBulkIndexerSetRetry(w.opt.maxRetries, w.opt.retryBackoff),
+		candiutils.SearchBulkIndexerSetOnError(w.onItemError),
+	)
+	ticker := time.NewTicker(max(w.opt.flushInterval, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	var last *sarama.ConsumerMessage
+	var pending int
+	flush := func() error {
+		if last == nil {
+			return nil
+		}
+		if err := indexer.Flush(session.Context()); err != nil {
+			return fmt.Errorf("search indexer: flush topic %s partition %d: %w", claim.Topic(), claim.Partition(), err)
+		}
+		session.MarkMessage(last, "")
+		last, pending = nil, 0
+		return nil
+	}
+
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return flush()
+			}
+			indexer.Add(session.Context(), w.processMessage(session.Context(), message)...)
+			last = message
+			pending++
+			if pending >= w.opt.batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+
+		case <-session.Context().Done():
+			// unmarked message is consumed again by next session
+			return nil
+		}
+	}
+}
+
+// processMessage exec handler of topic and return document collected from handler
+func (w *searchIndexer) processMessage(ctx context.Context, message *sarama.ConsumerMessage) (items []interfaces.SearchBulkItem) {
+	handler, ok := w.handlers[message.Topic]
+	if !ok {
+		return nil
+	}
+	if handler.DisableTrace {
+		ctx = tracer.SkipTraceContext(ctx)
+	}
+
+	header := map[string]string{
+		"offset":    strconv.Itoa(int(message.Offset)),
+		"partition": strconv.Itoa(int(message.Partition)),
+		"timestamp": message.Timestamp.Format(time.RFC3339),
+	}
+	for _, val := range message.Headers {
+		header[string(val.Key)] = string(val.Value)
+	}
+	if tenantID := header[candihelper.HeaderXTenantID]; tenantID != "" {
+		ctx = candishared.SetTenantToContext(ctx, tenantID)
+	}
+	ctx = candishared.ExtractBaggage(ctx, header)
+	ctx, _ = candishared.ExtractRequestID(ctx, header)
+
+	var err error
+	trace, ctx := tracer.StartTraceFromHeader(ctx, "SearchIndexer", header)
+	defer func() {
+		if r := recover(); r != nil {
+			trace.SetTag("panic", true)
+			err = candiutils.ReportPanic(ctx, "SearchIndexer", r, map[string]any{
+				"topic": message.Topic, "partition": message.Partition, "offset": message.Offset, "key": string(message.Key),
+			})
+		}
+		if err != nil {
+			// skip document of failed projection
+			items = nil
+		}
+		trace.SetTag("documents", len(items))
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+
+	trace.SetTag("topic", message.Topic)
+	trace.SetTag("key", message.Key)
+	trace.SetTag("consumer_group", w.opt.consumerGroup)
+	if w.opt.workerType != types.SearchIndexer {
+		trace.SetTag("worker_type", string(w.opt.workerType))
+	}
+	trace.Log("header", header)
+	trace.Log("message", message.Value)
+
+	if w.opt.debugMode {
+		log.Printf("\x1b[35;3mSearch Indexer%s: message consumed, timestamp = %v, topic = %s, partition = %d, offset = %d\x1b[0m",
+			getWorkerTypeLog(w.opt.workerType), message.Timestamp, message.Topic, message.Partition, message.Offset)
+	}
+
+	eventContext := w.messagePool.Get().(*candishared.EventContext)
+	defer w.releaseMessagePool(eventContext)
+	eventContext.SetContext(context.WithValue(ctx, documentsKey{}, &items))
+	eventContext.SetWorkerType(string(w.opt.workerType))
+	eventContext.SetHandlerRoute(message.Topic)
+	eventContext.SetHeader(header)
+	eventContext.SetKey(string(message.Key))
+	if cloudEvent, data, ok := candishared.ParseCloudEvent(header, message.Value); ok {
+		eventContext.SetCloudEvent(cloudEvent)
+		eventContext.Write(data)
+	} else {
+		eventContext.Write(message.Value)
+	}
+
+	for _, handlerFunc := range handler.HandlerFuncs {
+		if err = handlerFunc(eventContext); err != nil {
+			eventContext.SetError(err)
+			logger.LogRed(fmt.Sprintf("search_indexer > topic %s offset %d: %s", message.Topic, message.Offset, err.Error()))
+			return nil
+		}
+	}
+	return items
+}
+
+func (w *searchIndexer) onItemError(item interfaces.SearchBulkItem, result interfaces.SearchBulkResult) {
+	logger.LogRed(fmt.Sprintf("search_indexer > %s %s/%s: %s", item.Action, item.Index, item.ID, result.Error))
+	if w.opt.onItemError != nil {
+		w.opt.onItemError(item, result)
+	}
+}
+
+func (w *searchIndexer) releaseMessagePool(eventContext *candishared.EventContext) {
+	eventContext.Reset()
+	w.messagePool.Put(eventContext)
+}
+
+func getWorkerTypeLog(name types.Worker) (workerType string) {
+	if name != types.SearchIndexer {
+		workerType = " [worker_type: " + string(name) + "]"
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package searchindexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return "products" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func newTestIndexer(engine interfaces.SearchEngine) *searchIndexer {
+	var handlerGroup types.WorkerHandlerGroup
+	handlerGroup.Add("products", func(eventContext *candishared.EventContext) error {
+		var product struct {
+			ID      string `json:"id"`
+			Deleted bool   `json:"deleted"`
+		}
+		if err := json.Unmarshal(eventContext.Message(), &product); err != nil {
+			return err
+		}
+		if product.Deleted {
+			return DeleteDocument(eventContext.Context(), "products", product.ID)
+		}
+		return IndexDocument(eventContext.Context(), "products", product.ID, eventContext.Message())
+	})
+	return &searchIndexer{
+		opt: option{
+			workerType: types.SearchIndexer, engine: engine, batchSize: 10, flushInterval: time.Hour,
+		},
+		handlers: map[string]types.WorkerHandler{"products": handlerGroup.Handlers[0]},
+		messagePool: sync.Pool{
+			New: func() any {
+				return candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, 256)))
+			},
+		},
+	}
+}
+
+func consumeMessages(w *searchIndexer, values ...string) (*fakeSession, error) {
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(values))}
+	for i, value := range values {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "products", Offset: int64(i + 1), Value: []byte(value)}
+	}
+	close(claim.messages)
+	session := &fakeSession{ctx: context.Background()}
+	return session, w.ConsumeClaim(session, claim)
+}
+
+func TestSearchIndexer(t *testing.T) {
+	engine := mockinterfaces.NewSearchEngine(t)
+	engine.On("Bulk", mock.Anything, []interfaces.SearchBulkItem{
+		{Action: "index", Index: "products", ID: "1", Document: []byte(`{"id":"1"}`)},
+		{Action: "delete", Index: "products", ID: "2"},
+	}).Return([]interfaces.SearchBulkResult{{Status: 201}, {Status: 200}}, nil).Once()
+
+	session, err := consumeMessages(newTestIndexer(engine), `{"id":"1"}`, `invalid`, `{"id":"2","deleted":true}`)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, session.marked, "only last message of batch is marked")
+
+	assert.ErrorIs(t, IndexDocument(context.Background(), "products", "1", nil), ErrOutsideIndexer)
+}
+
+func TestSearchIndexerBulkFailed(t *testing.T) {
+	engine := mockinterfaces.NewSearchEngine(t)
+	engine.On("Bulk", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+
+	session, err := consumeMessages(newTestIndexer(engine), `{"id":"1"}`)
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Empty(t, session.marked, "offset must not be committed when bulk failed")
+}
@@ -0,0 +1,332 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	cronexpr "github.com/golangid/candi/candiutils/cronparser"
+	cronworker "github.com/golangid/candi/codebase/app/cron_worker"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"google.golang.org/grpc"
+)
+
+// SelfTestFlag command line flag for run app in self test mode
+const SelfTestFlag = "--selftest"
+
+type (
+	// SelfTestCheck additional check in self test mode
+	SelfTestCheck struct {
+		Name  string
+		Check func(ctx context.Context) error
+	}
+
+	// SelfTestReport machine-readable report of self test
+	SelfTestReport struct {
+		Service  string                `json:"service"`
+		Success  bool                  `json:"success"`
+		StartAt  time.Time             `json:"start_at"`
+		Duration string                `json:"duration"`
+		Checks   []SelfTestCheckResult `json:"checks"`
+	}
+
+	// SelfTestCheckResult result of each check
+	SelfTestCheckResult struct {
+		Name     string `json:"name"`
+		Success  bool   `json:"success"`
+		Error    string `json:"error,omitempty"`
+		Duration string `json:"duration"`
+	}
+)
+
+// SetSelfTest option, run self test instead of serve applications (default true if app run with "--selftest" flag)
+func SetSelfTest(selfTest bool) Option {
+	return func(a *App) {
+		a.selfTest = selfTest
+	}
+}
+
+// AddSelfTestChecks option, add custom check in self test mode
+func AddSelfTestChecks(checks ...SelfTestCheck) Option {
+	return func(a *App) {
+		a.selfTestChecks = append(a.selfTestChecks, checks...)
+	}
+}
+
+// SelfTest verify all handlers registered without conflict, validate cron expressions & json schemas,
+// and ping all dependencies
+func (a *App) SelfTest(ctx context.Context) (report SelfTestReport) {
+	report.Service = string(a.service.Name())
+	report.StartAt = time.Now()
+	report.Success = true
+
+	checks := []SelfTestCheck{
+		{Name: "applications", Check: a.checkApplications},
+		{Name: "rest_routes", Check: a.checkRESTRoutes},
+		{Name: "grpc_services", Check: a.checkGRPCServices},
+	}
+	for _, workerType := range []types.Worker{
+		types.Kafka, types.RedisSubscriber, types.RabbitMQ, types.Scheduler, types.TaskQueue, types.PostgresListener,
+		types.MongoChangeStream, types.PostgresNotify, types.SearchIndexer,
+	} {
+		checks = append(checks, SelfTestCheck{Name: "worker_handlers:" + string(workerType), Check: a.checkWorkerHandlers(workerType)})
+	}
+	checks = append(checks, SelfTestCheck{Name: "validator", Check: a.checkValidator})
+	checks = append(checks, a.dependencyChecks()...)
+	checks = append(checks, a.selfTestChecks...)
+
+	for _, check := range checks {
+		start := time.Now()
+		result := SelfTestCheckResult{Name: check.Name, Success: true}
+		if err := runSelfTestCheck(ctx, check); err != nil {
+			result.Success, result.Error = false, err.Error()
+			report.Success = false
+		}
+		result.Duration = time.Since(start).String()
+		report.Checks = append(report.Checks, result)
+	}
+
+	report.Duration = time.Since(report.StartAt).String()
+	return report
+}
+
+func (a *App) runSelfTest() {
+	os.Exit(a.writeSelfTest(context.Background(), os.Stdout))
+}
+
+// writeSelfTest write json report of self test to w, return process exit code (1 if any check failed)
+func (a *App) writeSelfTest(ctx context.Context, w io.Writer) (exitCode int) {
+	report := a.SelfTest(ctx)
+	json.NewEncoder(w).Encode(report)
+	if !report.Success {
+		return 1
+	}
+	return 0
+}
+
+func runSelfTestCheck(ctx context.Context, check SelfTestCheck) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return check.Check(ctx)
+}
+
+func (a *App) checkApplications(context.Context) error {
+	if err := a.checkRequired(); err != nil {
+		return err
+	}
+	exist := make(map[string]struct{})
+	for _, app := range a.service.GetApplications() {
+		if _, ok := exist[app.Name()]; ok {
+			return fmt.Errorf("application %s has been registered", app.Name())
+		}
+		exist[app.Name()] = struct{}{}
+	}
+	return nil
+}
+
+func (a *App) checkRESTRoutes(context.Context) error {
+	routes := make(map[string]string)
+	multiErr := candihelper.NewMultiError()
+	for _, m := range a.service.GetModules() {
+		h := m.RESTHandler()
+		if h == nil {
+			continue
+		}
+		recorder := &routeRecorder{module: string(m.Name()), routes: routes, multiErr: multiErr}
+		h.Mount(recorder)
+	}
+	if multiErr.HasError() {
+		return multiErr
+	}
+	return nil
+}
+
+func (a *App) checkGRPCServices(context.Context) error {
+	services := make(map[string]types.Module)
+	multiErr := candihelper.NewMultiError()
+	for _, m := range a.service.GetModules() {
+		h := m.GRPCHandler()
+		if h == nil {
+			continue
+		}
+		server := grpc.NewServer()
+		h.Register(server, &types.MiddlewareGroup{})
+		for serviceName := range server.GetServiceInfo() {
+			if module, ok := services[serviceName]; ok {
+				multiErr.Append(serviceName, fmt.Errorf("registered in module %s and %s", module, m.Name()))
+				continue
+			}
+			services[serviceName] = m.Name()
+		}
+	}
+	if multiErr.HasError() {
+		return multiErr
+	}
+	return nil
+}
+
+func (a *App) checkWorkerHandlers(workerType types.Worker) func(context.Context) error {
+	return func(context.Context) error {
+		patterns := make(map[string]types.Module)
+		multiErr := candihelper.NewMultiError()
+		for _, m := range a.service.GetModules() {
+			h := m.WorkerHandler(workerType)
+			if h == nil {
+				continue
+			}
+			var group types.WorkerHandlerGroup
+			h.MountHandlers(&group)
+			for _, handler := range group.Handlers {
+				if len(handler.HandlerFuncs) == 0 || handler.HandlerFuncs[0] == nil {
+					multiErr.Append(handler.Pattern, errors.New("handler func cannot empty"))
+				}
+				if module, ok := patterns[handler.Pattern]; ok {
+					multiErr.Append(handler.Pattern, fmt.Errorf("registered in module %s and %s", module, m.Name()))
+					continue
+				}
+				patterns[handler.Pattern] = m.Name()
+
+				if workerType == types.Scheduler {
+					if err := validateCronPattern(handler.Pattern); err != nil {
+						multiErr.Append(handler.Pattern, err)
+					}
+				}
+			}
+		}
+		if multiErr.HasError() {
+			return multiErr
+		}
+		return nil
+	}
+}
+
+func validateCronPattern(pattern string) error {
+	jobName, _, interval := cronworker.ParseCronJobKey(pattern)
+	if jobName == "" {
+		return errors.New("handler name cannot empty")
+	}
+	if _, _, err := candihelper.ParseDurationExpression(interval); err == nil {
+		return nil
+	}
+	_, err := cronexpr.Parse(interval)
+	return err
+}
+
+func (a *App) checkValidator(context.Context) error {
+	deps := a.service.GetDependency()
+	if deps == nil {
+		return nil
+	}
+	if v, ok := deps.GetValidator().(interface{ SelfTest() error }); ok {
+		return v.SelfTest()
+	}
+	return nil
+}
+
+func (a *App) dependencyChecks() (checks []SelfTestCheck) {
+	deps := a.service.GetDependency()
+	if deps == nil {
+		return nil
+	}
+
+	healthCheck := func(name string, health func() map[string]error) {
+		checks = append(checks, SelfTestCheck{Name: "dependency:" + name, Check: func(context.Context) error {
+			return healthError(health())
+		}})
+	}
+	if db := deps.GetSQLDatabase(); db != nil {
+		healthCheck("sql", db.Health)
+	}
+	if db := deps.GetMongoDatabase(); db != nil {
+		healthCheck("mongo", db.Health)
+	}
+	if db := deps.GetRedisPool(); db != nil {
+		healthCheck("redis", db.Health)
+	}
+	if db := deps.GetBoltDatabase(); db != nil {
+		healthCheck("bolt", db.Health)
+	}
+	deps.FetchBroker(func(workerType types.Worker, broker interfaces.Broker) {
+		healthCheck("broker:"+string(workerType), broker.Health)
+	})
+	return checks
+}
+
+func healthError(health map[string]error) error {
+	names := candihelper.ToKeyMapSlice(health)
+	sort.Strings(names)
+	var errs []string
+	for _, name := range names {
+		if err := health[name]; err != nil {
+			errs = append(errs, name+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// routeRecorder record all rest route for detect conflict route
+type routeRecorder struct {
+	module   string
+	prefix   string
+	routes   map[string]string
+	multiErr candihelper.MultiError
+}
+
+func (r *routeRecorder) add(method, pattern string) {
+	route := method + " " + "/" + strings.Trim(r.prefix+"/"+strings.Trim(pattern, "/"), "/")
+	if module, ok := r.routes[route]; ok {
+		r.multiErr.Append(route, fmt.Errorf("registered in module %s and %s", module, r.module))
+		return
+	}
+	r.routes[route] = r.module
+}
+
+func (r *routeRecorder) Use(middlewares ...func(http.Handler) http.Handler) {}
+func (r *routeRecorder) Group(pattern string, middlewares ...func(http.Handler) http.Handler) interfaces.RESTRouter {
+	return &routeRecorder{module: r.module, prefix: r.prefix + "/" + strings.Trim(pattern, "/"), routes: r.routes, multiErr: r.multiErr}
+}
+func (r *routeRecorder) HandleFunc(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add("*", pattern)
+}
+func (r *routeRecorder) CONNECT(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodConnect, pattern)
+}
+func (r *routeRecorder) DELETE(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodDelete, pattern)
+}
+func (r *routeRecorder) GET(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodGet, pattern)
+}
+func (r *routeRecorder) HEAD(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodHead, pattern)
+}
+func (r *routeRecorder) OPTIONS(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodOptions, pattern)
+}
+func (r *routeRecorder) PATCH(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodPatch, pattern)
+}
+func (r *routeRecorder) POST(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodPost, pattern)
+}
+func (r *routeRecorder) PUT(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodPut, pattern)
+}
+func (r *routeRecorder) TRACE(pattern string, h http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	r.add(http.MethodTrace, pattern)
+}
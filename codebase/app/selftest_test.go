@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	mockdeps "github.com/golangid/candi/mocks/codebase/factory/dependency"
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newSelfTestApp(t *testing.T, sqlHealth map[string]error, checks ...SelfTestCheck) *App {
+	restServer := mockfactory.NewAppServerFactory(t)
+	restServer.On("Name").Return(string(types.REST))
+
+	sqlDB := mockinterfaces.NewSQLDatabase(t)
+	sqlDB.On("Health").Return(sqlHealth)
+
+	deps := mockdeps.NewDependency(t)
+	deps.On("GetSQLDatabase").Return(sqlDB)
+	deps.On("GetMongoDatabase").Return(nil)
+	deps.On("GetRedisPool").Return(nil)
+	deps.On("GetBoltDatabase").Return(nil)
+	deps.On("GetValidator").Return(nil)
+	deps.On("FetchBroker", mock.Anything).Return()
+
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("order-service"))
+	service.On("GetApplications").Return([]factory.AppServerFactory{restServer})
+	service.On("GetModules").Return([]factory.ModuleFactory{})
+	service.On("GetDependency").Return(deps)
+
+	return &App{service: service, selfTestChecks: checks}
+}
+
+func TestSelfTest(t *testing.T) {
+	t.Run("all check pass", func(t *testing.T) {
+		a := newSelfTestApp(t, map[string]error{"sql_read": nil, "sql_write": nil},
+			SelfTestCheck{Name: "config", Check: func(ctx context.Context) error { return nil }})
+
+		var buf bytes.Buffer
+		assert.Equal(t, 0, a.writeSelfTest(context.Background(), &buf))
+		var report SelfTestReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.True(t, report.Success)
+		assert.Equal(t, "order-service", report.Service)
+		for _, check := range report.Checks {
+			assert.True(t, check.Success, check.Name)
+		}
+	})
+
+	t.Run("failed dependency and custom check fail the report", func(t *testing.T) {
+		a := newSelfTestApp(t, map[string]error{"sql_read": nil, "sql_write": errors.New("connection refused")},
+			SelfTestCheck{Name: "config", Check: func(ctx context.Context) error { return errors.New("missing API key") }},
+			SelfTestCheck{Name: "panic", Check: func(ctx context.Context) error { panic("boom") }})
+
+		var buf bytes.Buffer
+		assert.Equal(t, 1, a.writeSelfTest(context.Background(), &buf))
+		var report SelfTestReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.False(t, report.Success)
+
+		failed := map[string]string{}
+		for _, check := range report.Checks {
+			if !check.Success {
+				failed[check.Name] = check.Error
+			}
+		}
+		assert.Equal(t, map[string]string{
+			"dependency:sql": "sql_write: connection refused",
+			"config":         "missing API key",
+			"panic":          "panic: boom",
+		}, failed)
+	})
+}
@@ -0,0 +1,293 @@
+// Package supervisor track error rate (error and panic) per worker handler route, handler exceeding failure threshold
+// is quarantined (paused) and probed with exponential backoff, so poison message or broken dependency
+// does not burn CPU in retry loop. Alert hook is called when handler quarantined and recovered.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/wrapper"
+)
+
+// State of handler
+type State string
+
+const (
+	// StateHealthy handler is running normally
+	StateHealthy State = "HEALTHY"
+	// StateQuarantined handler is paused until quarantine end
+	StateQuarantined State = "QUARANTINED"
+	// StateProbing single probe message is running after quarantine end
+	StateProbing State = "PROBING"
+)
+
+type (
+	// Stats statistic of handler route
+	Stats struct {
+		Route            string    `json:"route"`
+		State            State     `json:"state"`
+		Total            int64     `json:"total"`
+		Failures         int64     `json:"failures"`
+		ErrorRate        float64   `json:"error_rate"`
+		LastError        string    `json:"last_error,omitempty"`
+		Quarantines      int       `json:"quarantines"`
+		QuarantinedUntil time.Time `json:"quarantined_until,omitempty"`
+	}
+
+	// OptionFunc type
+	OptionFunc func(*Supervisor)
+
+	// Supervisor handler error budget supervisor
+	Supervisor struct {
+		window         time.Duration
+		threshold      float64
+		minRequests    int64
+		baseQuarantine time.Duration
+		maxQuarantine  time.Duration
+		alertFunc      func(ctx context.Context, stats Stats)
+
+		mu       sync.Mutex
+		handlers map[string]*handlerState
+	}
+
+	handlerState struct {
+		Stats
+		windowStart time.Time
+		changed     chan struct{}
+	}
+)
+
+// SetWindow option func, error rate is measured in fixed window (default 1 minute)
+func SetWindow(window time.Duration) OptionFunc {
+	return func(s *Supervisor) {
+		s.window = window
+	}
+}
+
+// SetThreshold option func, handler is quarantined when error rate in window reach threshold (0-1) with
+// at least minRequests processed message (default 0.5 and 10)
+func SetThreshold(threshold float64, minRequests int64) OptionFunc {
+	return func(s *Supervisor) {
+		s.threshold, s.minRequests = threshold, minRequests
+	}
+}
+
+// SetQuarantine option func, quarantine duration doubled from base on each failed probe capped at max
+// (default 10 seconds and 10 minutes)
+func SetQuarantine(base, max time.Duration) OptionFunc {
+	return func(s *Supervisor) {
+		s.baseQuarantine, s.maxQuarantine = base, max
+	}
+}
+
+// SetAlertFunc option func, called when handler quarantined (stats state is StateQuarantined) and recovered
+// (stats state is StateHealthy)
+func SetAlertFunc(alertFunc func(ctx context.Context, stats Stats)) OptionFunc {
+	return func(s *Supervisor) {
+		s.alertFunc = alertFunc
+	}
+}
+
+// New create supervisor
+func New(opts ...OptionFunc) *Supervisor {
+	s := &Supervisor{
+		window:         time.Minute,
+		threshold:      0.5,
+		minRequests:    10,
+		baseQuarantine: 10 * time.Second,
+		maxQuarantine:  10 * time.Minute,
+		alertFunc: func(ctx context.Context, stats Stats) {
+			logger.LogYellow(fmt.Sprintf("supervisor: handler '%s' is %s (error rate %.2f, last error: %s)",
+				stats.Route, stats.State, stats.ErrorRate, stats.LastError))
+		},
+		handlers: make(map[string]*handlerState),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Wrap supervise handler with handler route as key
+func (s *Supervisor) Wrap(handlerFunc types.WorkerHandlerFunc) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) (err error) {
+		ctx, route := eventContext.Context(), eventContext.HandlerRoute()
+		if err := s.wait(ctx, route); err != nil {
+			return err
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				s.record(ctx, route, fmt.Errorf("panic: %v", r))
+				panic(r)
+			}
+			s.record(ctx, route, err)
+		}()
+		return handlerFunc(eventContext)
+	}
+}
+
+// Apply supervise main handler of all handler in group, call after all handler added in MountHandlers
+func (s *Supervisor) Apply(group *types.WorkerHandlerGroup) {
+	for i := range group.Handlers {
+		if len(group.Handlers[i].HandlerFuncs) > 0 {
+			group.Handlers[i].HandlerFuncs[0] = s.Wrap(group.Handlers[i].HandlerFuncs[0])
+		}
+	}
+}
+
+// Stats get statistic of all supervised handler
+func (s *Supervisor) Stats() (stats []Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range s.handlers {
+		stats = append(stats, h.Stats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Route < stats[j].Route })
+	return stats
+}
+
+// Release lift quarantine of handler route manually
+func (s *Supervisor) Release(route string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h, ok := s.handlers[route]; ok && h.State != StateHealthy {
+		h.reset(time.Now())
+		h.Quarantines = 0
+	}
+}
+
+// HTTPHandler http handler for get all handler stats (GET) and release quarantine (POST with query param "release={route}").
+// Handler has no authentication, mount it behind auth middleware (example: HTTPBasicAuth) so only operator can release quarantine
+func (s *Supervisor) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			wrapper.NewHTTPResponse(http.StatusOK, "Supervisor stats", s.Stats()).JSON(rw)
+		case http.MethodPost:
+			route := req.URL.Query().Get("release")
+			s.Release(route)
+			wrapper.NewHTTPResponse(http.StatusOK, "Success release "+route).JSON(rw)
+		default:
+			wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(rw)
+		}
+	})
+}
+
+func (s *Supervisor) get(route string) *handlerState {
+	h, ok := s.handlers[route]
+	if !ok {
+		h = &handlerState{Stats: Stats{Route: route, State: StateHealthy}, windowStart: time.Now(), changed: make(chan struct{})}
+		s.handlers[route] = h
+	}
+	return h
+}
+
+// wait block while handler is quarantined or probing, caller after quarantine end become the probe
+func (s *Supervisor) wait(ctx context.Context, route string) error {
+	for {
+		s.mu.Lock()
+		h := s.get(route)
+		wait := time.Hour // probing, wait until probe done
+		switch h.State {
+		case StateHealthy:
+			s.mu.Unlock()
+			return nil
+		case StateQuarantined:
+			if wait = time.Until(h.QuarantinedUntil); wait <= 0 {
+				h.State = StateProbing
+				s.mu.Unlock()
+				return nil
+			}
+		}
+		changed := h.changed
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (s *Supervisor) record(ctx context.Context, route string, err error) {
+	s.mu.Lock()
+	now := time.Now()
+	h := s.get(route)
+	var alert *Stats
+	switch h.State {
+	case StateProbing:
+		if err == nil {
+			h.reset(now)
+			h.Quarantines = 0
+			stats := h.Stats
+			alert = &stats
+			break
+		}
+		h.LastError = err.Error()
+		alert = s.quarantine(h, now)
+
+	case StateHealthy:
+		if now.Sub(h.windowStart) > s.window {
+			h.windowStart, h.Total, h.Failures = now, 0, 0
+		}
+		h.Total++
+		if err != nil {
+			h.Failures++
+			h.LastError = err.Error()
+		}
+		h.ErrorRate = float64(h.Failures) / float64(h.Total)
+		if h.Total >= s.minRequests && h.ErrorRate >= s.threshold {
+			alert = s.quarantine(h, now)
+		}
+	}
+	s.mu.Unlock()
+
+	if alert != nil && s.alertFunc != nil {
+		s.alertFunc(ctx, *alert)
+	}
+}
+
+// quarantineDuration base duration doubled n times capped at max, stop doubling before overflow
+func (s *Supervisor) quarantineDuration(n int) time.Duration {
+	duration := s.baseQuarantine
+	for ; n > 0; n-- {
+		if duration > s.maxQuarantine/2 {
+			return s.maxQuarantine
+		}
+		duration *= 2
+	}
+	return min(duration, s.maxQuarantine)
+}
+
+// quarantine must be called with lock held
+func (s *Supervisor) quarantine(h *handlerState, now time.Time) *Stats {
+	h.Quarantines++
+	h.State, h.QuarantinedUntil = StateQuarantined, now.Add(s.quarantineDuration(h.Quarantines-1))
+	h.notify()
+	stats := h.Stats
+	return &stats
+}
+
+func (h *handlerState) reset(now time.Time) {
+	h.State, h.QuarantinedUntil = StateHealthy, time.Time{}
+	h.windowStart, h.Total, h.Failures, h.ErrorRate = now, 0, 0, 0
+	h.notify()
+}
+
+func (h *handlerState) notify() {
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
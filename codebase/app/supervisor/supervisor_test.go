@@ -0,0 +1,82 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervisor(t *testing.T) {
+	var alerts []State
+	supervisor := New(SetThreshold(0.5, 2), SetQuarantine(20*time.Millisecond, time.Second),
+		SetAlertFunc(func(ctx context.Context, stats Stats) { alerts = append(alerts, stats.State) }))
+
+	fail := true
+	var group types.WorkerHandlerGroup
+	group.Add("orders", func(eventContext *candishared.EventContext) error {
+		if fail {
+			return errors.New("database down")
+		}
+		return nil
+	})
+	supervisor.Apply(&group)
+	handler := group.Handlers[0].HandlerFuncs[0]
+
+	eventContext := candishared.NewEventContext(&bytes.Buffer{})
+	eventContext.SetContext(context.Background())
+	eventContext.SetHandlerRoute("orders")
+
+	assert.Error(t, handler(eventContext))
+	assert.Error(t, handler(eventContext))
+	stats := supervisor.Stats()[0]
+	assert.Equal(t, StateQuarantined, stats.State)
+	assert.Equal(t, "database down", stats.LastError)
+
+	// paused while quarantined
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	eventContext.SetContext(ctx)
+	assert.ErrorIs(t, handler(eventContext), context.DeadlineExceeded)
+
+	// failed probe double quarantine duration
+	eventContext.SetContext(context.Background())
+	assert.Error(t, handler(eventContext))
+	stats = supervisor.Stats()[0]
+	assert.Equal(t, 2, stats.Quarantines)
+	assert.WithinDuration(t, time.Now().Add(40*time.Millisecond), stats.QuarantinedUntil, 15*time.Millisecond)
+
+	fail = false
+	assert.NoError(t, handler(eventContext))
+	assert.Equal(t, StateHealthy, supervisor.Stats()[0].State)
+	assert.Equal(t, []State{StateQuarantined, StateQuarantined, StateHealthy}, alerts)
+}
+
+func TestQuarantineDuration(t *testing.T) {
+	supervisor := New(SetQuarantine(10*time.Second, 10*time.Minute))
+	assert.Equal(t, 10*time.Second, supervisor.quarantineDuration(0))
+	assert.Equal(t, 80*time.Second, supervisor.quarantineDuration(3))
+	for _, n := range []int{6, 31, 40, 1000} {
+		assert.Equal(t, 10*time.Minute, supervisor.quarantineDuration(n), "capped at max without overflow")
+	}
+}
+
+func TestSupervisorPanic(t *testing.T) {
+	supervisor := New(SetThreshold(1, 1), SetAlertFunc(nil))
+	handler := supervisor.Wrap(func(eventContext *candishared.EventContext) error { panic("nil pointer") })
+
+	eventContext := candishared.NewEventContext(&bytes.Buffer{})
+	eventContext.SetContext(context.Background())
+	eventContext.SetHandlerRoute("report")
+	assert.Panics(t, func() { handler(eventContext) })
+	assert.Equal(t, "panic: nil pointer", supervisor.Stats()[0].LastError)
+	assert.Equal(t, StateQuarantined, supervisor.Stats()[0].State)
+
+	supervisor.Release("report")
+	assert.Equal(t, StateHealthy, supervisor.Stats()[0].State)
+}
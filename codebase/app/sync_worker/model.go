@@ -0,0 +1,61 @@
+package syncworker
+
+import (
+	"context"
+	"time"
+)
+
+// Operation change event operation
+type Operation string
+
+const (
+	// OperationUpsert insert or update
+	OperationUpsert Operation = "upsert"
+	// OperationDelete delete
+	OperationDelete Operation = "delete"
+)
+
+type (
+	// ChangeEvent change data from source
+	ChangeEvent struct {
+		Key       string
+		Operation Operation
+		Data      []byte
+		// Timestamp when change happened in source, used for calculate lag
+		Timestamp time.Time
+		// Position resume position in source (mongo resume token, kafka partition offsets)
+		Position string
+	}
+
+	// Source change event source (mongo change stream, kafka cdc topic, etc)
+	Source interface {
+		// Stream tail change event start from position (empty position for start from latest change), blocking until context done or error
+		Stream(ctx context.Context, position string, handler func(ChangeEvent) error) error
+		// Snapshot read all current data from source for rebuild target from scratch,
+		// return position to continue stream after snapshot
+		Snapshot(ctx context.Context, handler func(ChangeEvent) error) (position string, err error)
+	}
+
+	// Sink target for maintain warm standby data (cache, secondary read model)
+	Sink interface {
+		Upsert(ctx context.Context, key string, data []byte) error
+		Delete(ctx context.Context, key string) error
+		// Clear all data in sink before rebuild
+		Clear(ctx context.Context) error
+	}
+
+	// Stats sync statistic
+	Stats struct {
+		Name            string    `json:"name"`
+		Running         bool      `json:"running"`
+		Rebuilding      bool      `json:"rebuilding"`
+		ProcessedEvents int64     `json:"processed_events"`
+		FailedEvents    int64     `json:"failed_events"`
+		Lag             string    `json:"lag"`
+		LagSeconds      float64   `json:"lag_seconds"`
+		LastEventAt     time.Time `json:"last_event_at"`
+		LastPosition    string    `json:"last_position,omitempty"`
+		LastError       string    `json:"last_error,omitempty"`
+		LastRebuildAt   time.Time `json:"last_rebuild_at"`
+	}
+)
@@ -0,0 +1,76 @@
+package syncworker
+
+import (
+	"time"
+
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+type (
+	option struct {
+		positionStore          interfaces.Cache
+		retryInterval          time.Duration
+		rebuildOnEmptyPosition bool
+		debugMode              bool
+		syncs                  []*syncer
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+func getDefaultOption(service factory.ServiceFactory) option {
+	opt := option{
+		retryInterval:          5 * time.Second,
+		rebuildOnEmptyPosition: true,
+		debugMode:              true,
+	}
+	if deps := service.GetDependency(); deps != nil {
+		if redisPool := deps.GetRedisPool(); redisPool != nil {
+			opt.positionStore = redisPool.Cache()
+		} else if boltDB := deps.GetBoltDatabase(); boltDB != nil {
+			opt.positionStore = boltDB.Cache()
+		}
+	}
+	return opt
+}
+
+// AddSync option func, add sync from source to sink with unique name
+func AddSync(name string, source Source, sink Sink) OptionFunc {
+	return func(o *option) {
+		o.syncs = append(o.syncs, &syncer{
+			name: name, source: source, sink: sink,
+			rebuildRequest: make(chan chan error),
+		})
+	}
+}
+
+// SetPositionStore option func, store for save last position of each sync for resume after restart
+// (default using redis cache if exist, else position only kept in memory)
+func SetPositionStore(store interfaces.Cache) OptionFunc {
+	return func(o *option) {
+		o.positionStore = store
+	}
+}
+
+// SetRetryInterval option func, interval for restart stream when error (default 5 seconds)
+func SetRetryInterval(retryInterval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.retryInterval = retryInterval
+	}
+}
+
+// SetRebuildOnEmptyPosition option func, rebuild sink from source snapshot when no last position (default true)
+func SetRebuildOnEmptyPosition(rebuild bool) OptionFunc {
+	return func(o *option) {
+		o.rebuildOnEmptyPosition = rebuild
+	}
+}
+
+// SetDebugMode option func
+func SetDebugMode(debugMode bool) OptionFunc {
+	return func(o *option) {
+		o.debugMode = debugMode
+	}
+}
@@ -0,0 +1,56 @@
+package syncworker
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+type cacheSink struct {
+	cache     interfaces.Cache
+	keyPrefix string
+	expire    time.Duration
+}
+
+// NewCacheSink maintain warm standby cache, key stored with prefix
+func NewCacheSink(cache interfaces.Cache, keyPrefix string, expire time.Duration) Sink {
+	return &cacheSink{cache: cache, keyPrefix: keyPrefix, expire: expire}
+}
+
+func (c *cacheSink) Upsert(ctx context.Context, key string, data []byte) error {
+	return c.cache.Set(ctx, c.keyPrefix+key, data, c.expire)
+}
+
+func (c *cacheSink) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, c.keyPrefix+key)
+}
+
+func (c *cacheSink) Clear(ctx context.Context) error {
+	return c.cache.Delete(ctx, c.keyPrefix+"*")
+}
+
+// SinkFunc sink from funcs, can be used for secondary read model (example: upsert to another database)
+type SinkFunc struct {
+	UpsertFunc func(ctx context.Context, key string, data []byte) error
+	DeleteFunc func(ctx context.Context, key string) error
+	ClearFunc  func(ctx context.Context) error
+}
+
+// Upsert method
+func (s SinkFunc) Upsert(ctx context.Context, key string, data []byte) error {
+	return s.UpsertFunc(ctx, key, data)
+}
+
+// Delete method
+func (s SinkFunc) Delete(ctx context.Context, key string) error {
+	return s.DeleteFunc(ctx, key)
+}
+
+// Clear method
+func (s SinkFunc) Clear(ctx context.Context) error {
+	if s.ClearFunc == nil {
+		return nil
+	}
+	return s.ClearFunc(ctx)
+}
@@ -0,0 +1,196 @@
+package syncworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// CDCDecoder decode kafka message from cdc topic to change event
+type CDCDecoder func(msg *sarama.ConsumerMessage) (ChangeEvent, error)
+
+type kafkaCDCSource struct {
+	client  sarama.Client
+	topic   string
+	decoder CDCDecoder
+}
+
+// NewKafkaCDCSource tail change from kafka cdc topic (Debezium, etc), if decoder is nil using DebeziumDecoder.
+// Position is next offset per partition, snapshot read topic from oldest offset (use compacted topic for full rebuild)
+func NewKafkaCDCSource(client sarama.Client, topic string, decoder CDCDecoder) Source {
+	if decoder == nil {
+		decoder = DebeziumDecoder
+	}
+	return &kafkaCDCSource{client: client, topic: topic, decoder: decoder}
+}
+
+// DebeziumDecoder decode debezium change event (with or without schema envelope),
+// tombstone message (empty value) decoded as delete, message without "op" field decoded as upsert of raw value
+func DebeziumDecoder(msg *sarama.ConsumerMessage) (event ChangeEvent, err error) {
+	event.Key, event.Timestamp = string(msg.Key), msg.Timestamp
+	if len(msg.Value) == 0 {
+		event.Operation = OperationDelete
+		return event, nil
+	}
+
+	var payload struct {
+		Payload *json.RawMessage `json:"payload"`
+		Op      string           `json:"op"`
+		After   json.RawMessage  `json:"after"`
+		TsMs    int64            `json:"ts_ms"`
+	}
+	if err := json.Unmarshal(msg.Value, &payload); err != nil {
+		return event, err
+	}
+	if payload.Payload != nil {
+		if err := json.Unmarshal(*payload.Payload, &payload); err != nil {
+			return event, err
+		}
+	}
+	if payload.TsMs > 0 {
+		event.Timestamp = time.UnixMilli(payload.TsMs)
+	}
+
+	switch payload.Op {
+	case "":
+		event.Operation, event.Data = OperationUpsert, msg.Value
+	case "d":
+		event.Operation = OperationDelete
+	default: // c (create), u (update), r (read/snapshot)
+		event.Operation, event.Data = OperationUpsert, payload.After
+	}
+	return event, nil
+}
+
+type kafkaPosition map[int32]int64
+
+func (p kafkaPosition) String() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func (k *kafkaCDCSource) Stream(ctx context.Context, position string, handler func(ChangeEvent) error) error {
+	offsets := make(kafkaPosition)
+	if position != "" {
+		if err := json.Unmarshal([]byte(position), &offsets); err != nil {
+			return errors.New("invalid kafka position: " + err.Error())
+		}
+	}
+	return k.consume(ctx, offsets, nil, handler)
+}
+
+func (k *kafkaCDCSource) Snapshot(ctx context.Context, handler func(ChangeEvent) error) (position string, err error) {
+	partitions, err := k.client.Partitions(k.topic)
+	if err != nil {
+		return "", err
+	}
+
+	offsets, highWaterMarks := make(kafkaPosition), make(kafkaPosition)
+	for _, partition := range partitions {
+		if offsets[partition], err = k.client.GetOffset(k.topic, partition, sarama.OffsetOldest); err != nil {
+			return "", err
+		}
+		if highWaterMarks[partition], err = k.client.GetOffset(k.topic, partition, sarama.OffsetNewest); err != nil {
+			return "", err
+		}
+	}
+	if err := k.consume(ctx, offsets, highWaterMarks, handler); err != nil {
+		return "", err
+	}
+	return highWaterMarks.String(), nil
+}
+
+// consume all partition from given offsets, if until is not nil stop when all partition reach until offsets
+func (k *kafkaCDCSource) consume(ctx context.Context, offsets, until kafkaPosition, handler func(ChangeEvent) error) error {
+	partitions, err := k.client.Partitions(k.topic)
+	if err != nil {
+		return err
+	}
+	consumer, err := sarama.NewConsumerFromClient(k.client)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	messages, errs := make(chan *sarama.ConsumerMessage), make(chan error, len(partitions))
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	remaining := 0
+	for _, partition := range partitions {
+		offset, ok := offsets[partition]
+		if !ok {
+			offset = sarama.OffsetNewest
+		}
+		if until != nil {
+			if offset >= until[partition] {
+				continue
+			}
+			remaining++
+		}
+
+		pc, err := consumer.ConsumePartition(k.topic, partition, offset)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer pc.AsyncClose()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case err := <-pc.Errors():
+					if err != nil {
+						errs <- err
+						return
+					}
+				case msg := <-pc.Messages():
+					select {
+					case messages <- msg:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+	if until != nil && remaining == 0 {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case msg := <-messages:
+			event, err := k.decoder(msg)
+			if err != nil {
+				return errors.New("decode message partition " + strconv.Itoa(int(msg.Partition)) +
+					" offset " + strconv.FormatInt(msg.Offset, 10) + ": " + err.Error())
+			}
+			offsets[msg.Partition] = msg.Offset + 1
+			event.Position = offsets.String()
+			if err := handler(event); err != nil {
+				return err
+			}
+			if until != nil && offsets[msg.Partition] == until[msg.Partition] {
+				if remaining--; remaining == 0 {
+					return nil
+				}
+			}
+		}
+	}
+}
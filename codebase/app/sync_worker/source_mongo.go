@@ -0,0 +1,128 @@
+package syncworker
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoChangeStreamSource struct {
+	collection *mongo.Collection
+	pipeline   mongo.Pipeline
+}
+
+// NewMongoChangeStreamSource tail change from mongo collection using change stream (require replica set),
+// data sent to sink is full document in relaxed extended json, key is document _id
+func NewMongoChangeStreamSource(collection *mongo.Collection, pipeline ...bson.D) Source {
+	return &mongoChangeStreamSource{collection: collection, pipeline: pipeline}
+}
+
+type mongoChangeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID any `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument bson.Raw            `bson:"fullDocument"`
+	ClusterTime  primitive.Timestamp `bson:"clusterTime"`
+}
+
+func (m *mongoChangeStreamSource) Stream(ctx context.Context, position string, handler func(ChangeEvent) error) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if position != "" {
+		token, err := base64.StdEncoding.DecodeString(position)
+		if err != nil {
+			return fmt.Errorf("invalid resume token: %w", err)
+		}
+		opts.SetResumeAfter(bson.Raw(token))
+	}
+
+	stream, err := m.collection.Watch(ctx, m.pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var event mongoChangeEvent
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+
+		change := ChangeEvent{
+			Key:       mongoDocumentKey(event.DocumentKey.ID),
+			Timestamp: time.Unix(int64(event.ClusterTime.T), 0),
+			Position:  base64.StdEncoding.EncodeToString(stream.ResumeToken()),
+		}
+		switch event.OperationType {
+		case "insert", "update", "replace":
+			if event.FullDocument == nil { // document has been deleted before lookup
+				continue
+			}
+			change.Operation = OperationUpsert
+			if change.Data, err = bson.MarshalExtJSON(event.FullDocument, false, false); err != nil {
+				return err
+			}
+		case "delete":
+			change.Operation = OperationDelete
+		default: // drop, rename, invalidate
+			continue
+		}
+
+		if err := handler(change); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+func (m *mongoChangeStreamSource) Snapshot(ctx context.Context, handler func(ChangeEvent) error) (position string, err error) {
+	// open change stream before read collection for get resume token, so no change missed while snapshot
+	stream, err := m.collection.Watch(ctx, m.pipeline)
+	if err != nil {
+		return "", err
+	}
+	position = base64.StdEncoding.EncodeToString(stream.ResumeToken())
+	stream.Close(ctx)
+
+	cur, err := m.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return "", err
+	}
+	defer cur.Close(ctx)
+
+	now := time.Now()
+	for cur.Next(ctx) {
+		data, err := bson.MarshalExtJSON(cur.Current, false, false)
+		if err != nil {
+			return "", err
+		}
+		if err := handler(ChangeEvent{
+			Key: mongoDocumentKey(cur.Current.Lookup("_id")), Operation: OperationUpsert, Data: data, Timestamp: now,
+		}); err != nil {
+			return "", err
+		}
+	}
+	return position, cur.Err()
+}
+
+func mongoDocumentKey(id any) string {
+	switch val := id.(type) {
+	case primitive.ObjectID:
+		return val.Hex()
+	case bson.RawValue:
+		if oid, ok := val.ObjectIDOK(); ok {
+			return oid.Hex()
+		}
+		if str, ok := val.StringValueOK(); ok {
+			return str
+		}
+		return val.String()
+	}
+	return fmt.Sprint(id)
+}
@@ -0,0 +1,333 @@
+package syncworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/wrapper"
+)
+
+/*
+Sync Worker
+Tail change event from source (mongo change stream, kafka cdc topic) and maintain warm standby
+cache or secondary read model in sink, with lag metrics and rebuild from scratch command
+*/
+
+// Worker sync worker
+type Worker struct {
+	ctx           context.Context
+	ctxCancelFunc func()
+	opt           option
+	service       factory.ServiceFactory
+	syncs         map[string]*syncer
+	wg            sync.WaitGroup
+}
+
+// NewWorker create new sync worker
+func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) *Worker {
+	worker := &Worker{
+		service: service,
+		opt:     getDefaultOption(service),
+		syncs:   make(map[string]*syncer),
+	}
+	for _, opt := range opts {
+		opt(&worker.opt)
+	}
+
+	for _, s := range worker.opt.syncs {
+		if _, ok := worker.syncs[s.name]; ok {
+			panic("sync worker: sync " + s.name + " has been registered")
+		}
+		s.worker = worker
+		s.stats.Name = s.name
+		worker.syncs[s.name] = s
+		logger.LogYellow(fmt.Sprintf(`[SYNC-WORKER] (sync): "%s"`, s.name))
+	}
+	fmt.Printf("\x1b[34;1m⇨ Sync Worker running with %d syncs\x1b[0m\n\n", len(worker.syncs))
+
+	worker.ctx, worker.ctxCancelFunc = context.WithCancel(context.Background())
+	return worker
+}
+
+// Serve run all sync
+func (w *Worker) Serve() {
+	for _, s := range w.opt.syncs {
+		w.wg.Add(1)
+		go func(s *syncer) {
+			defer w.wg.Done()
+			s.run(w.ctx)
+		}(s)
+	}
+	w.wg.Wait()
+}
+
+// Shutdown stop all sync
+func (w *Worker) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mSync Worker:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), strings.Repeat(" ", 20))
+	}()
+
+	w.ctxCancelFunc()
+	done := make(chan struct{})
+	go func() { w.wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// Name of worker
+func (w *Worker) Name() string {
+	return "sync_worker"
+}
+
+// Stats get statistic of all sync
+func (w *Worker) Stats() (stats []Stats) {
+	for _, s := range w.opt.syncs {
+		stats = append(stats, s.getStats())
+	}
+	return stats
+}
+
+// Rebuild clear sink and rebuild from source snapshot, then continue stream from snapshot position.
+// Blocking until rebuild done
+func (w *Worker) Rebuild(ctx context.Context, name string) error {
+	s, ok := w.syncs[name]
+	if !ok {
+		return fmt.Errorf("sync %s not found", name)
+	}
+
+	done := make(chan error, 1)
+	select {
+	case s.rebuildRequest <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.ctx.Done():
+		return errors.New("sync worker has been shutdown")
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HTTPHandler http handler for get all sync stats (GET) and rebuild sync (POST with query param "rebuild={sync name}")
+func (w *Worker) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			wrapper.NewHTTPResponse(http.StatusOK, "Sync stats", w.Stats()).JSON(rw)
+		case http.MethodPost:
+			name := req.URL.Query().Get("rebuild")
+			if err := w.Rebuild(req.Context(), name); err != nil {
+				wrapper.NewHTTPResponse(http.StatusBadRequest, "Failed rebuild "+name, err).JSON(rw)
+				return
+			}
+			wrapper.NewHTTPResponse(http.StatusOK, "Success rebuild "+name, w.syncs[name].getStats()).JSON(rw)
+		default:
+			wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(rw)
+		}
+	})
+}
+
+type syncer struct {
+	name           string
+	source         Source
+	sink           Sink
+	worker         *Worker
+	rebuildRequest chan chan error
+
+	mu       sync.RWMutex
+	stats    Stats
+	position string
+}
+
+func (s *syncer) run(ctx context.Context) {
+	var rebuildDone chan error
+	for {
+		position := s.loadPosition(ctx)
+		if rebuildDone != nil || (position == "" && s.worker.opt.rebuildOnEmptyPosition) {
+			var err error
+			position, err = s.rebuild(ctx)
+			if rebuildDone != nil {
+				rebuildDone <- err
+				rebuildDone = nil
+			}
+			if err != nil {
+				s.setError(err)
+				if rebuildDone = s.wait(ctx); ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+		}
+
+		s.setRunning(true)
+		streamCtx, cancel := context.WithCancel(ctx)
+		errStream := make(chan error, 1)
+		go func() {
+			errStream <- s.source.Stream(streamCtx, position, func(event ChangeEvent) error {
+				return s.apply(streamCtx, event, true)
+			})
+		}()
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-errStream
+			s.setRunning(false)
+			return
+
+		case rebuildDone = <-s.rebuildRequest:
+			cancel()
+			<-errStream
+
+		case err := <-errStream:
+			cancel()
+			if err == nil {
+				err = errors.New("stream closed")
+			}
+			s.setError(err)
+			if rebuildDone = s.wait(ctx); ctx.Err() != nil {
+				s.setRunning(false)
+				return
+			}
+		}
+		s.setRunning(false)
+	}
+}
+
+// wait retry interval, return early if receive rebuild request
+func (s *syncer) wait(ctx context.Context) chan error {
+	timer := time.NewTimer(s.worker.opt.retryInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	case done := <-s.rebuildRequest:
+		return done
+	}
+	return nil
+}
+
+func (s *syncer) rebuild(ctx context.Context) (position string, err error) {
+	s.mu.Lock()
+	s.stats.Rebuilding = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.stats.Rebuilding = false
+		if err == nil {
+			s.stats.LastRebuildAt = time.Now()
+		}
+		s.mu.Unlock()
+	}()
+
+	if s.worker.opt.debugMode {
+		logger.LogYellow(fmt.Sprintf("[SYNC-WORKER] (sync): %s rebuilding from snapshot...", s.name))
+	}
+	if err := s.sink.Clear(ctx); err != nil {
+		return "", fmt.Errorf("clear sink: %w", err)
+	}
+	position, err = s.source.Snapshot(ctx, func(event ChangeEvent) error {
+		return s.apply(ctx, event, false)
+	})
+	if err != nil {
+		return "", fmt.Errorf("snapshot: %w", err)
+	}
+	s.savePosition(ctx, position)
+	return position, nil
+}
+
+func (s *syncer) apply(ctx context.Context, event ChangeEvent, savePosition bool) (err error) {
+	switch event.Operation {
+	case OperationDelete:
+		err = s.sink.Delete(ctx, event.Key)
+	default:
+		err = s.sink.Upsert(ctx, event.Key, event.Data)
+	}
+
+	s.mu.Lock()
+	if err != nil {
+		s.stats.FailedEvents++
+		s.stats.LastError = err.Error()
+	} else {
+		s.stats.ProcessedEvents++
+		s.stats.LastEventAt = time.Now()
+		if !event.Timestamp.IsZero() {
+			lag := max(time.Since(event.Timestamp), 0)
+			s.stats.Lag, s.stats.LagSeconds = lag.String(), lag.Seconds()
+		}
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("apply %s key %s: %w", event.Operation, event.Key, err)
+	}
+	if savePosition && event.Position != "" {
+		s.savePosition(ctx, event.Position)
+	}
+	return nil
+}
+
+func (s *syncer) positionKey() string {
+	return fmt.Sprintf("%s:sync-worker:%s:position", s.worker.service.Name(), s.name)
+}
+
+func (s *syncer) loadPosition(ctx context.Context) string {
+	s.mu.RLock()
+	position := s.position
+	s.mu.RUnlock()
+	if position != "" || s.worker.opt.positionStore == nil {
+		return position
+	}
+
+	data, err := s.worker.opt.positionStore.Get(ctx, s.positionKey())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (s *syncer) savePosition(ctx context.Context, position string) {
+	s.mu.Lock()
+	s.position, s.stats.LastPosition = position, position
+	s.mu.Unlock()
+	if s.worker.opt.positionStore != nil {
+		if err := s.worker.opt.positionStore.Set(ctx, s.positionKey(), position, 0); err != nil {
+			logger.LogEf("sync worker: save position %s: %s", s.name, err)
+		}
+	}
+}
+
+func (s *syncer) setRunning(running bool) {
+	s.mu.Lock()
+	s.stats.Running = running
+	s.mu.Unlock()
+}
+
+func (s *syncer) setError(err error) {
+	s.mu.Lock()
+	s.stats.LastError = err.Error()
+	s.mu.Unlock()
+	if s.worker.opt.debugMode {
+		logger.LogRed(fmt.Sprintf("[SYNC-WORKER] (sync): %s error: %s", s.name, err))
+	}
+}
+
+func (s *syncer) getStats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
@@ -0,0 +1,117 @@
+package syncworker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	snapshot  map[string]string
+	events    chan ChangeEvent
+	snapshots int
+}
+
+func (f *fakeSource) Stream(ctx context.Context, position string, handler func(ChangeEvent) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-f.events:
+			if err := handler(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (f *fakeSource) Snapshot(ctx context.Context, handler func(ChangeEvent) error) (string, error) {
+	f.snapshots++
+	for key, data := range f.snapshot {
+		if err := handler(ChangeEvent{Key: key, Operation: OperationUpsert, Data: []byte(data)}); err != nil {
+			return "", err
+		}
+	}
+	return "snapshot", nil
+}
+
+type memSink struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (m *memSink) get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.data[key]
+	return val, ok
+}
+
+func (m *memSink) sink() Sink {
+	return SinkFunc{
+		UpsertFunc: func(ctx context.Context, key string, data []byte) error {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.data[key] = string(data)
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, key string) error {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.data, key)
+			return nil
+		},
+		ClearFunc: func(ctx context.Context) error {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.data = make(map[string]string)
+			return nil
+		},
+	}
+}
+
+func TestSyncWorker(t *testing.T) {
+	service := &mockfactory.ServiceFactory{}
+	service.On("GetDependency").Return(nil)
+	service.On("Name").Return("test")
+
+	source := &fakeSource{snapshot: map[string]string{"1": "a", "2": "b"}, events: make(chan ChangeEvent)}
+	sink := &memSink{data: map[string]string{"stale": "x"}}
+	worker := NewWorker(service, AddSync("user", source, sink.sink()), SetDebugMode(false))
+	go worker.Serve()
+	defer worker.Shutdown(context.Background())
+
+	// rebuild from snapshot on empty position, then stream
+	source.events <- ChangeEvent{Key: "3", Operation: OperationUpsert, Data: []byte("c"), Timestamp: time.Now().Add(-time.Second), Position: "p1"}
+	source.events <- ChangeEvent{Key: "1", Operation: OperationDelete, Position: "p2"}
+	source.events <- ChangeEvent{Key: "2", Operation: OperationUpsert, Data: []byte("b2"), Position: "p3"}
+
+	_, ok := sink.get("stale")
+	assert.False(t, ok)
+	_, ok = sink.get("1")
+	assert.False(t, ok)
+	val, _ := sink.get("3")
+	assert.Equal(t, "c", val)
+
+	stats := worker.Stats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "user", stats[0].Name)
+	assert.GreaterOrEqual(t, stats[0].ProcessedEvents, int64(4))
+	assert.GreaterOrEqual(t, stats[0].LagSeconds, 1.0)
+
+	assert.NoError(t, worker.Rebuild(context.Background(), "user"))
+	assert.Equal(t, 2, source.snapshots)
+	val, _ = sink.get("1")
+	assert.Equal(t, "a", val)
+	_, ok = sink.get("3")
+	assert.False(t, ok)
+	stats = worker.Stats()
+	assert.Equal(t, "snapshot", stats[0].LastPosition)
+	assert.False(t, stats[0].LastRebuildAt.IsZero())
+
+	assert.Error(t, worker.Rebuild(context.Background(), "unknown"))
+}
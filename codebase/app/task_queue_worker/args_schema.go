@@ -0,0 +1,158 @@
+package taskqueueworker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/gojsonschema"
+)
+
+// TaskArgsFormField form field for submit job from dashboard, generated from top level properties of task arguments json schema
+type TaskArgsFormField struct {
+	Name        string
+	Title       string
+	Description string
+	Type        string
+	Format      string
+	Required    bool
+	Enum        []string
+	Default     *string
+}
+
+// TaskArgsFormResolver resolver
+type TaskArgsFormResolver struct {
+	TaskName string
+	Schema   string
+	Fields   []TaskArgsFormField
+}
+
+// taskArgsSchemaStorage json schema storage (implement validator.Storage) for task arguments, schema id is task name
+type taskArgsSchemaStorage map[string]string
+
+func (s taskArgsSchemaStorage) Get(taskName string) (string, error) {
+	schema, ok := s[taskName]
+	if !ok {
+		return "", fmt.Errorf("task '%s' has no arguments schema", taskName)
+	}
+	return schema, nil
+}
+
+func (s taskArgsSchemaStorage) Store(taskName string, schema string) error {
+	if _, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema)); err != nil {
+		return err
+	}
+	s[taskName] = schema
+	return nil
+}
+
+// validateArgs validate job arguments with json schema from task option TaskOptionArgumentsSchema,
+// proto arguments (see AddJobRequest.ProtoArgs) is not validated
+func (t *taskQueueWorker) validateArgs(taskName string, args []byte) error {
+	if _, ok := t.argsSchemas[taskName]; !ok || candishared.IsProtoPayload(args) {
+		return nil
+	}
+	if len(bytes.TrimSpace(args)) == 0 {
+		args = []byte("null")
+	}
+	return t.argsValidator.ValidateDocument(taskName, args)
+}
+
+func (t *taskQueueWorker) getTaskArgsForm(taskName string) (res TaskArgsFormResolver, err error) {
+	if _, ok := t.registeredTaskWorkerIndex[taskName]; !ok {
+		return res, fmt.Errorf("task '%s' unregistered", taskName)
+	}
+
+	res.TaskName = taskName
+	res.Fields = []TaskArgsFormField{}
+	res.Schema = t.argsSchemas[taskName]
+	if res.Schema == "" {
+		return res, nil
+	}
+
+	var schema struct {
+		Properties json.RawMessage `json:"properties"`
+		Required   []string        `json:"required"`
+	}
+	if err := json.Unmarshal([]byte(res.Schema), &schema); err != nil {
+		return res, err
+	}
+	var properties map[string]struct {
+		Title       string            `json:"title"`
+		Description string            `json:"description"`
+		Type        any               `json:"type"`
+		Format      string            `json:"format"`
+		Enum        []json.RawMessage `json:"enum"`
+		Default     json.RawMessage   `json:"default"`
+	}
+	json.Unmarshal(schema.Properties, &properties)
+
+	for _, name := range jsonObjectKeys(schema.Properties) {
+		prop := properties[name]
+		field := TaskArgsFormField{
+			Name: name, Title: prop.Title, Description: prop.Description, Format: prop.Format,
+			Required: candihelper.StringInSlice(name, schema.Required), Enum: []string{},
+		}
+		if field.Title == "" {
+			field.Title = name
+		}
+
+		// type can be list (example: ["string", "null"]), take first non null type
+		switch typ := prop.Type.(type) {
+		case string:
+			field.Type = typ
+		case []any:
+			for _, s := range typ {
+				if s, ok := s.(string); ok && s != "null" {
+					field.Type = s
+					break
+				}
+			}
+		}
+		if field.Type == "" {
+			field.Type = "string"
+		}
+
+		for _, e := range prop.Enum {
+			field.Enum = append(field.Enum, rawJSONString(e))
+		}
+		if len(prop.Default) > 0 {
+			field.Default = candihelper.ToStringPtr(rawJSONString(prop.Default))
+		}
+		res.Fields = append(res.Fields, field)
+	}
+	return res, nil
+}
+
+// jsonObjectKeys return keys of json object in source order
+func jsonObjectKeys(raw json.RawMessage) (keys []string) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		keys = append(keys, fmt.Sprint(tok))
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+// rawJSONString render json value as form value, string value is unquoted
+func rawJSONString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,75 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/logger"
+	"github.com/google/uuid"
+)
+
+type inMemAuditLog struct {
+	mu        sync.RWMutex
+	auditLogs []AuditLog
+	retention int
+}
+
+// NewInMemAuditLog audit log persistent in memory, keep latest retention audit log
+func NewInMemAuditLog(retention int) AuditLogPersistent {
+	return &inMemAuditLog{retention: retention}
+}
+
+func (i *inMemAuditLog) SaveAuditLog(ctx context.Context, auditLog *AuditLog) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.auditLogs = append(i.auditLogs, *auditLog)
+	if over := len(i.auditLogs) - i.retention; i.retention > 0 && over > 0 {
+		i.auditLogs = append([]AuditLog{}, i.auditLogs[over:]...)
+	}
+	return nil
+}
+
+func (i *inMemAuditLog) FindAllAuditLog(ctx context.Context, filter *Filter) (auditLogs []AuditLog, count int, err error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var matched []AuditLog
+	for idx := len(i.auditLogs) - 1; idx >= 0; idx-- {
+		if matchAuditLogSearch(&i.auditLogs[idx], filter.Search) {
+			matched = append(matched, i.auditLogs[idx])
+		}
+	}
+	return paginateAuditLog(matched, filter), len(matched), nil
+}
+
+func (t *taskQueueWorker) saveAuditLog(ctx context.Context, auditLog *AuditLog) {
+	auditLog.ID = uuid.NewString()
+	auditLog.CreatedAt = time.Now()
+	if err := t.auditLog.SaveAuditLog(context.WithoutCancel(ctx), auditLog); err != nil {
+		logger.LogE("Task Queue Worker: save audit log: " + err.Error())
+	}
+	if !auditLog.Allowed {
+		logger.LogYellow(fmt.Sprintf("[TASK-QUEUE-WORKER] dashboard user %q is not allowed to %s %s", auditLog.Username, auditLog.Action, auditLog.Target))
+	}
+}
+
+func matchAuditLogSearch(auditLog *AuditLog, search *string) bool {
+	if search == nil || *search == "" {
+		return true
+	}
+	return strings.Contains(auditLog.Username, *search) || strings.Contains(auditLog.Action, *search) ||
+		strings.Contains(auditLog.Target, *search)
+}
+
+func paginateAuditLog(auditLogs []AuditLog, filter *Filter) []AuditLog {
+	if filter.ShowAll {
+		return auditLogs
+	}
+	offset := filter.CalculateOffset()
+	if offset < 0 || offset >= len(auditLogs) {
+		return nil
+	}
+	return auditLogs[offset:min(offset+filter.Limit, len(auditLogs))]
+}
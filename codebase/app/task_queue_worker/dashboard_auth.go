@@ -0,0 +1,200 @@
+package taskqueueworker
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golangid/candi/middleware/oidc"
+)
+
+// DashboardPermission permission of dashboard action
+type DashboardPermission string
+
+const (
+	// DashboardPermissionView view task, job, configuration and audit log
+	DashboardPermissionView DashboardPermission = "view"
+	// DashboardPermissionRetry add, retry and run queued job
+	DashboardPermissionRetry DashboardPermission = "retry"
+	// DashboardPermissionDelete stop, clean and delete job
+	DashboardPermissionDelete DashboardPermission = "delete"
+	// DashboardPermissionManage set configuration, hold task, recalculate summary, restore and manage client subscriber
+	DashboardPermissionManage DashboardPermission = "manage"
+
+	// DashboardRoleAdmin default role with all permission
+	DashboardRoleAdmin = "admin"
+	// DashboardRoleOperator default role with view and retry permission
+	DashboardRoleOperator = "operator"
+	// DashboardRoleViewer default role with view permission
+	DashboardRoleViewer = "viewer"
+)
+
+type (
+	// DashboardUser authenticated dashboard user
+	DashboardUser struct {
+		Username string
+		Roles    []string
+	}
+
+	// DashboardAuth authenticator of dashboard request
+	DashboardAuth interface {
+		// Authenticate get user of request, if not authenticated write response (unauthorized or redirect to login) and return nil
+		Authenticate(w http.ResponseWriter, req *http.Request) *DashboardUser
+	}
+
+	// DashboardBasicAuthUser user of dashboard basic auth
+	DashboardBasicAuthUser struct {
+		Username string
+		Password string
+		Roles    []string
+	}
+
+	dashboardBasicAuth struct {
+		users map[string]DashboardBasicAuthUser
+	}
+
+	dashboardOIDCAuth struct {
+		rp        *oidc.RelyingParty
+		roleClaim string
+	}
+
+	dashboardUserContextKey       struct{}
+	dashboardRemoteAddrContextKey struct{}
+)
+
+var defaultDashboardRoles = map[string][]DashboardPermission{
+	DashboardRoleAdmin:    {DashboardPermissionView, DashboardPermissionRetry, DashboardPermissionDelete, DashboardPermissionManage},
+	DashboardRoleOperator: {DashboardPermissionView, DashboardPermissionRetry},
+	DashboardRoleViewer:   {DashboardPermissionView},
+}
+
+// NewDashboardBasicAuth dashboard authentication with HTTP basic auth, user without roles has admin role
+func NewDashboardBasicAuth(users ...DashboardBasicAuthUser) DashboardAuth {
+	auth := &dashboardBasicAuth{users: make(map[string]DashboardBasicAuthUser, len(users))}
+	for _, user := range users {
+		if len(user.Roles) == 0 {
+			user.Roles = []string{DashboardRoleAdmin}
+		}
+		auth.users[user.Username] = user
+	}
+	return auth
+}
+
+func (a *dashboardBasicAuth) Authenticate(w http.ResponseWriter, req *http.Request) *DashboardUser {
+	username, password, ok := req.BasicAuth()
+	if ok {
+		user, found := a.users[username]
+		if found && subtle.ConstantTimeCompare([]byte(password), []byte(user.Password)) == 1 {
+			return &DashboardUser{Username: user.Username, Roles: user.Roles}
+		}
+	}
+
+	w.Header().Set("WWW-Authenticate", `Basic realm=""`)
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write([]byte("Invalid authorization"))
+	return nil
+}
+
+// NewDashboardOIDCAuth dashboard authentication with OpenID Connect login session, redirect url of relying party
+// must be served by dashboard port (example: "http://localhost:8080/auth/callback").
+// Roles of user is taken from roleClaim in ID token (string or array of string)
+func NewDashboardOIDCAuth(rp *oidc.RelyingParty, roleClaim string) DashboardAuth {
+	return &dashboardOIDCAuth{rp: rp, roleClaim: roleClaim}
+}
+
+func (a *dashboardOIDCAuth) Authenticate(w http.ResponseWriter, req *http.Request) *DashboardUser {
+	var session *oidc.Session
+	a.rp.RequireLogin(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		session, _ = oidc.SessionFromContext(req.Context())
+	})).ServeHTTP(w, req)
+	if session == nil {
+		return nil
+	}
+
+	user := &DashboardUser{Username: session.Email}
+	if user.Username == "" {
+		user.Username = session.Subject
+	}
+	switch roles := session.Claims[a.roleClaim].(type) {
+	case string:
+		user.Roles = strings.Fields(roles)
+	case []any:
+		for _, role := range roles {
+			user.Roles = append(user.Roles, fmt.Sprint(role))
+		}
+	}
+	return user
+}
+
+func (a *dashboardOIDCAuth) mount(mux *http.ServeMux) {
+	a.rp.MountServeMux(mux)
+}
+
+// GetDashboardUserFromContext get authenticated dashboard user from context
+func GetDashboardUserFromContext(ctx context.Context) *DashboardUser {
+	user, _ := ctx.Value(dashboardUserContextKey{}).(*DashboardUser)
+	return user
+}
+
+func (o *option) dashboardAuthMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "/_next") {
+			req.URL.Path = strings.TrimPrefix(req.URL.Path, "/task")
+			req.URL.Path = strings.TrimPrefix(req.URL.Path, "/job")
+			req.URL.Path = strings.TrimPrefix(req.URL.Path, "/expired")
+		}
+		ctx := context.WithValue(req.Context(), dashboardRemoteAddrContextKey{}, req.RemoteAddr)
+		if o.dashboardAuth != nil {
+			user := o.dashboardAuth.Authenticate(w, req)
+			if user == nil {
+				return
+			}
+			if !o.hasDashboardPermission(user, DashboardPermissionView) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte("Forbidden"))
+				return
+			}
+			ctx = context.WithValue(ctx, dashboardUserContextKey{}, user)
+		}
+		next.ServeHTTP(w, req.WithContext(ctx))
+	}
+}
+
+func (o *option) hasDashboardPermission(user *DashboardUser, permission DashboardPermission) bool {
+	if o.dashboardAuth == nil {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	for _, role := range user.Roles {
+		if slices.Contains(o.dashboardRoles[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize check permission of dashboard user in context and record the action to audit log
+func (t *taskQueueWorker) authorize(ctx context.Context, permission DashboardPermission, action, target string) error {
+	user := GetDashboardUserFromContext(ctx)
+	allowed := t.opt.hasDashboardPermission(user, permission)
+
+	auditLog := AuditLog{Action: action, Target: target, Permission: string(permission), Allowed: allowed}
+	if user != nil {
+		auditLog.Username = user.Username
+	}
+	if remoteAddr, ok := ctx.Value(dashboardRemoteAddrContextKey{}).(string); ok {
+		auditLog.RemoteAddr, _, _ = net.SplitHostPort(remoteAddr)
+	}
+	t.saveAuditLog(ctx, &auditLog)
+
+	if !allowed {
+		return fmt.Errorf("forbidden: %s permission is required for %s", permission, action)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package taskqueueworker
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDashboardAuthWorker() *taskQueueWorker {
+	opt := &option{
+		dashboardAuth: NewDashboardBasicAuth(
+			DashboardBasicAuthUser{Username: "admin", Password: "secret"},
+			DashboardBasicAuthUser{Username: "operator", Password: "secret", Roles: []string{DashboardRoleOperator}},
+			DashboardBasicAuthUser{Username: "viewer", Password: "secret", Roles: []string{DashboardRoleViewer}},
+		),
+		dashboardRoles: maps.Clone(defaultDashboardRoles),
+	}
+	return &taskQueueWorker{opt: opt, auditLog: NewInMemAuditLog(10)}
+}
+
+func TestHasDashboardPermission(t *testing.T) {
+	opt := newTestDashboardAuthWorker().opt
+	admin := &DashboardUser{Username: "admin", Roles: []string{DashboardRoleAdmin}}
+	operator := &DashboardUser{Username: "operator", Roles: []string{DashboardRoleOperator}}
+	viewer := &DashboardUser{Username: "viewer", Roles: []string{DashboardRoleViewer}}
+	unknown := &DashboardUser{Username: "guest", Roles: []string{"guest"}}
+
+	for _, permission := range []DashboardPermission{DashboardPermissionView, DashboardPermissionRetry, DashboardPermissionDelete, DashboardPermissionManage} {
+		assert.True(t, opt.hasDashboardPermission(admin, permission), permission)
+		assert.False(t, opt.hasDashboardPermission(unknown, permission), permission)
+		assert.False(t, opt.hasDashboardPermission(nil, permission), permission)
+	}
+	assert.True(t, opt.hasDashboardPermission(operator, DashboardPermissionView))
+	assert.True(t, opt.hasDashboardPermission(operator, DashboardPermissionRetry))
+	assert.False(t, opt.hasDashboardPermission(operator, DashboardPermissionDelete))
+	assert.False(t, opt.hasDashboardPermission(operator, DashboardPermissionManage))
+	assert.True(t, opt.hasDashboardPermission(viewer, DashboardPermissionView))
+	assert.False(t, opt.hasDashboardPermission(viewer, DashboardPermissionRetry))
+	assert.False(t, opt.hasDashboardPermission(viewer, DashboardPermissionDelete))
+	assert.False(t, opt.hasDashboardPermission(viewer, DashboardPermissionManage))
+
+	SetDashboardRole("guest", DashboardPermissionView)(opt)
+	assert.True(t, opt.hasDashboardPermission(unknown, DashboardPermissionView))
+
+	assert.True(t, (&option{}).hasDashboardPermission(nil, DashboardPermissionManage), "dashboard without auth allow all")
+}
+
+func TestDashboardAuthorize(t *testing.T) {
+	worker := newTestDashboardAuthWorker()
+	ctxOf := func(user *DashboardUser) context.Context {
+		ctx := context.WithValue(context.Background(), dashboardRemoteAddrContextKey{}, "10.0.0.1:5000")
+		return context.WithValue(ctx, dashboardUserContextKey{}, user)
+	}
+
+	assert.NoError(t, worker.authorize(ctxOf(&DashboardUser{Username: "operator", Roles: []string{DashboardRoleOperator}}),
+		DashboardPermissionRetry, "retry_job", "job-1"))
+	assert.EqualError(t, worker.authorize(ctxOf(&DashboardUser{Username: "viewer", Roles: []string{DashboardRoleViewer}}),
+		DashboardPermissionDelete, "delete_job", "job-1"), "forbidden: delete permission is required for delete_job")
+	assert.Error(t, worker.authorize(context.Background(), DashboardPermissionManage, "set_configuration", "max_client"))
+
+	auditLogs, count, err := worker.auditLog.FindAllAuditLog(context.Background(), &Filter{ShowAll: true})
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	assert.Equal(t, AuditLog{ID: auditLogs[2].ID, Username: "operator", Action: "retry_job", Target: "job-1",
+		Permission: "retry", Allowed: true, RemoteAddr: "10.0.0.1", CreatedAt: auditLogs[2].CreatedAt}, auditLogs[2])
+	assert.Equal(t, AuditLog{ID: auditLogs[1].ID, Username: "viewer", Action: "delete_job", Target: "job-1",
+		Permission: "delete", Allowed: false, RemoteAddr: "10.0.0.1", CreatedAt: auditLogs[1].CreatedAt}, auditLogs[1])
+	assert.Empty(t, auditLogs[0].Username, "request without user is recorded")
+	assert.False(t, auditLogs[0].Allowed)
+}
+
+func TestDashboardAuthMiddleware(t *testing.T) {
+	opt := newTestDashboardAuthWorker().opt
+	SetDashboardRole("disabled")(opt)
+	opt.dashboardAuth.(*dashboardBasicAuth).users["disabled"] = DashboardBasicAuthUser{Username: "disabled", Password: "secret", Roles: []string{"disabled"}}
+
+	var user *DashboardUser
+	handler := opt.dashboardAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user = GetDashboardUserFromContext(req.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "missing credential")
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.SetBasicAuth("viewer", "wrong")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.SetBasicAuth("disabled", "secret")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "role without view permission")
+
+	req = httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.SetBasicAuth("viewer", "secret")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, user)
+	assert.Equal(t, "viewer", user.Username)
+}
@@ -0,0 +1,112 @@
+package taskqueueworker
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var taskArgsFormTemplate = template.Must(template.New("form").Funcs(template.FuncMap{
+	"deref": func(s *string) string { return *s },
+	"inputType": func(field TaskArgsFormField) string {
+		switch {
+		case field.Type == "integer" || field.Type == "number":
+			return "number"
+		case field.Format == "date" || field.Format == "email":
+			return field.Format
+		}
+		return "text"
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Add Job - {{.TaskName}}</title>
+<style>
+body { font-family: sans-serif; max-width: 640px; margin: 32px auto; color: #222; }
+label { display: block; margin-top: 14px; font-weight: bold; }
+small { display: block; color: #777; font-weight: normal; }
+input, select, textarea { width: 100%; box-sizing: border-box; padding: 6px; margin-top: 4px; }
+input[type=checkbox] { width: auto; }
+.error { color: #c0392b; font-size: 13px; }
+#result { margin-top: 16px; white-space: pre-wrap; }
+button { margin-top: 20px; padding: 8px 20px; }
+</style>
+</head>
+<body>
+<h2>Add Job: {{.TaskName}}</h2>
+<form id="form">
+{{- range .Fields}}
+<label>{{.Title}}{{if .Required}} *{{end}}{{if .Description}}<small>{{.Description}}</small>{{end}}
+{{- if .Enum}}
+<select name="{{.Name}}" data-type="{{.Type}}">{{if not .Required}}<option value=""></option>{{end}}{{$def := .Default}}{{range .Enum}}<option{{if and $def (eq . (deref $def))}} selected{{end}}>{{.}}</option>{{end}}</select>
+{{- else if eq .Type "boolean"}}
+<input type="checkbox" name="{{.Name}}" data-type="boolean"{{if and .Default (eq (deref .Default) "true")}} checked{{end}}>
+{{- else if or (eq .Type "object") (eq .Type "array")}}
+<textarea name="{{.Name}}" data-type="{{.Type}}" rows="4" placeholder="JSON {{.Type}}">{{if .Default}}{{deref .Default}}{{end}}</textarea>
+{{- else}}
+<input name="{{.Name}}" data-type="{{.Type}}" type="{{inputType .}}"{{if eq .Type "number"}} step="any"{{end}}{{if .Default}} value="{{deref .Default}}"{{end}}{{if .Required}} required{{end}}>
+{{- end}}
+<div class="error" data-error="{{.Name}}"></div>
+</label>
+{{- else}}
+<label>Arguments<textarea name="" data-type="raw" rows="10" placeholder="Job arguments"></textarea></label>
+{{- end}}
+<label>Max Retry<input id="max_retry" type="number" min="1" value="5" required></label>
+<button type="submit">Submit</button>
+</form>
+<div id="result"></div>
+<script>
+const taskName = {{.TaskName}};
+document.getElementById("form").addEventListener("submit", async (e) => {
+	e.preventDefault();
+	document.querySelectorAll("[data-error]").forEach((el) => el.textContent = "");
+	const result = document.getElementById("result");
+	let args = {}, raw = null;
+	try {
+		for (const el of document.querySelectorAll("[data-type]")) {
+			const type = el.dataset.type;
+			if (type === "raw") { raw = el.value; continue; }
+			if (type === "boolean") { args[el.name] = el.checked; continue; }
+			if (el.value === "") continue;
+			if (type === "integer" || type === "number") args[el.name] = Number(el.value);
+			else if (type === "object" || type === "array") args[el.name] = JSON.parse(el.value);
+			else args[el.name] = el.value;
+		}
+	} catch (err) {
+		result.textContent = "Invalid JSON: " + err.message;
+		return;
+	}
+	const resp = await fetch("/graphql", {
+		method: "POST", headers: {"Content-Type": "application/json"},
+		body: JSON.stringify({
+			query: "mutation addJob($param: AddJobInputResolver!) { add_job(param: $param) }",
+			variables: {param: {task_name: taskName, max_retry: Number(document.getElementById("max_retry").value), args: raw !== null ? raw : JSON.stringify(args)}},
+		}),
+	}).then((r) => r.json());
+	if (resp.errors && resp.errors.length) {
+		const fieldErrors = (resp.errors[0].extensions || {}).errors || {};
+		for (const [field, message] of Object.entries(fieldErrors)) {
+			const el = document.querySelector("[data-error='" + field.split(".")[0] + "']");
+			if (el) el.textContent = message;
+		}
+		result.textContent = resp.errors[0].message;
+		return;
+	}
+	result.textContent = "Job added with id " + resp.data.add_job;
+});
+</script>
+</body>
+</html>
+`))
+
+// serveTaskArgsForm render form for submit job, form fields generated from task arguments json schema
+// (see TaskOptionArgumentsSchema), fallback to raw arguments input if task has no schema
+func (t *taskQueueWorker) serveTaskArgsForm(w http.ResponseWriter, req *http.Request) {
+	form, err := t.getTaskArgsForm(req.URL.Query().Get("task_name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	taskArgsFormTemplate.Execute(w, form)
+}
@@ -3,14 +3,17 @@ package taskqueueworker
 import (
 	"bytes"
 	"context"
+	"maps"
 	"reflect"
 	"sync"
 	"time"
 
+	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/validator"
 )
 
 var (
@@ -28,8 +31,13 @@ func initEngine(service factory.ServiceFactory, opts ...OptionFunc) *taskQueueWo
 	opt.autoRemoveClientInterval = 30 * time.Minute
 	opt.dashboardPort = 8080
 	opt.debugMode = true
+	opt.dashboardRoles = maps.Clone(defaultDashboardRoles)
+	opt.auditLogRetention = 1000
+	opt.clock = candihelper.SystemClock
 	if redisPool := service.GetDependency().GetRedisPool(); redisPool != nil {
 		opt.locker = candiutils.NewRedisLocker(redisPool.WritePool())
+	} else if boltDB := service.GetDependency().GetBoltDatabase(); boltDB != nil {
+		opt.locker = candiutils.NewBoltLocker(boltDB.DB())
 	} else {
 		opt.locker = &candiutils.NoopLocker{}
 	}
@@ -51,6 +59,8 @@ func initEngine(service factory.ServiceFactory, opts ...OptionFunc) *taskQueueWo
 			opt.persistent = NewMongoPersistent(mongoDB.WriteDB())
 		} else if sqlDB := service.GetDependency().GetSQLDatabase(); sqlDB != nil {
 			opt.persistent = NewSQLPersistent(sqlDB.WriteDB())
+		} else if boltDB := service.GetDependency().GetBoltDatabase(); boltDB != nil {
+			opt.persistent = NewBoltPersistent(boltDB.DB())
 		} else {
 			opt.persistent = NewNoopPersistent()
 		}
@@ -59,6 +69,8 @@ func initEngine(service factory.ServiceFactory, opts ...OptionFunc) *taskQueueWo
 	if opt.queue == nil {
 		if redisPool := service.GetDependency().GetRedisPool(); redisPool != nil {
 			opt.queue = NewRedisQueue(redisPool.WritePool())
+		} else if boltDB := service.GetDependency().GetBoltDatabase(); boltDB != nil {
+			opt.queue = NewBoltQueue(boltDB.DB())
 		} else {
 			opt.queue = NewInMemQueue()
 		}
@@ -72,6 +84,7 @@ func initEngine(service factory.ServiceFactory, opts ...OptionFunc) *taskQueueWo
 		configuration:             initConfiguration(&opt),
 		registeredTaskWorkerIndex: make(map[string]int),
 		runningWorkerIndexTask:    make(map[int]*Task),
+		argsSchemas:               make(taskArgsSchemaStorage),
 		globalSemaphore:           make(chan struct{}, env.BaseEnv().MaxGoroutines),
 		messagePool: sync.Pool{
 			New: func() any {
@@ -82,7 +95,18 @@ func initEngine(service factory.ServiceFactory, opts ...OptionFunc) *taskQueueWo
 			},
 		},
 	}
+	engine.argsValidator = validator.NewJSONSchemaValidator(validator.SetSchemaStorageJSONSchemaValidatorOption(engine.argsSchemas))
 	engine.subscriber = initSubscriber(engine.configuration, &opt)
+	if redisPool := service.GetDependency().GetRedisPool(); redisPool != nil {
+		engine.jobResult = newJobResultNotifier(redisPool.WritePool(), string(service.Name()))
+	} else {
+		engine.jobResult = newJobResultNotifier(nil, string(service.Name()))
+	}
+	if auditLog, ok := opt.persistent.(AuditLogPersistent); ok {
+		engine.auditLog = auditLog
+	} else {
+		engine.auditLog = NewInMemAuditLog(opt.auditLogRetention)
+	}
 	engine.ctx, engine.ctxCancelFunc = context.WithCancel(context.Background())
 
 	// add refresh worker channel to first index
@@ -95,5 +119,16 @@ func initEngine(service factory.ServiceFactory, opts ...OptionFunc) *taskQueueWo
 
 // GetPersistent get active persistent for manage job data
 func GetPersistent() Persistent {
+	if engine == nil {
+		return nil
+	}
 	return engine.opt.persistent
 }
+
+// now current time of engine clock, system time if engine not initialized
+func now() time.Time {
+	if engine == nil || engine.opt.clock == nil {
+		return time.Now()
+	}
+	return engine.opt.clock.Now()
+}
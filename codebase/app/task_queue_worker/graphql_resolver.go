@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"net"
+	"math"
 	"net/http"
 	"runtime"
 	"sort"
@@ -19,6 +19,7 @@ import (
 	"github.com/golangid/candi/candishared"
 	cronexpr "github.com/golangid/candi/candiutils/cronparser"
 	graphqlserver "github.com/golangid/candi/codebase/app/graphql_server"
+	restserver "github.com/golangid/candi/codebase/app/rest_server"
 	"github.com/golangid/candi/config/env"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/graphql-go"
@@ -33,29 +34,27 @@ func (t *taskQueueWorker) serveGraphQLAPI() {
 	gqlHandler := graphqlserver.NewHandler(schema, graphqlserver.Option{RootPath: "/graphql"})
 
 	mux := http.NewServeMux()
-	mux.Handle("/", t.opt.basicAuth(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
-	mux.Handle("/task", t.opt.basicAuth(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
-	mux.Handle("/job", t.opt.basicAuth(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
-	mux.Handle("/expired", t.opt.basicAuth(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
-	mux.HandleFunc("/graphql", gqlHandler.ServeGraphQL())
-	mux.HandleFunc("/playground", gqlHandler.ServePlayground)
-	mux.HandleFunc("/voyager", gqlHandler.ServeVoyager)
+	mux.Handle("/", t.opt.dashboardAuthMiddleware(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
+	mux.Handle("/task", t.opt.dashboardAuthMiddleware(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
+	mux.Handle("/job", t.opt.dashboardAuthMiddleware(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
+	mux.Handle("/expired", t.opt.dashboardAuthMiddleware(http.StripPrefix("/", http.FileServer(dashboard.Dashboard))))
+	mux.Handle("/task/form", t.opt.dashboardAuthMiddleware(http.HandlerFunc(t.serveTaskArgsForm)))
+	mux.Handle("/graphql", t.opt.dashboardAuthMiddleware(restserver.HTTPMiddlewareBaggage(gqlHandler.ServeGraphQL())))
+	mux.Handle("/playground", t.opt.dashboardAuthMiddleware(http.HandlerFunc(gqlHandler.ServePlayground)))
+	mux.Handle("/voyager", t.opt.dashboardAuthMiddleware(http.HandlerFunc(gqlHandler.ServeVoyager)))
+	if auth, ok := t.opt.dashboardAuth.(interface{ mount(*http.ServeMux) }); ok {
+		auth.mount(mux)
+	}
 
 	httpEngine := new(http.Server)
 	httpEngine.Handler = mux
 
-	var err error
+	listener := t.dashboardListener
 	if t.opt.tlsConfig != nil {
 		httpEngine.TLSConfig = t.opt.tlsConfig
-		listener, errListen := net.Listen("tcp", fmt.Sprintf(":%d", t.opt.dashboardPort))
-		if errListen != nil {
-			log.Panicf("Task Queue Worker Dashboard, Listen TCP Error: %v", err)
-		}
-		err = httpEngine.Serve(tls.NewListener(listener, t.opt.tlsConfig))
-	} else {
-		httpEngine.Addr = fmt.Sprintf(":%d", t.opt.dashboardPort)
-		err = httpEngine.ListenAndServe()
+		listener = tls.NewListener(listener, t.opt.tlsConfig)
 	}
+	err := httpEngine.Serve(listener)
 
 	if err != nil {
 		log.Panicf("Task Queue Worker Dashboard: %v", err)
@@ -133,7 +132,26 @@ func (r *rootResolver) GetDetailJob(ctx context.Context, input struct {
 	return
 }
 
+func (r *rootResolver) WaitJobResult(ctx context.Context, input struct {
+	JobID   string
+	Timeout string
+}) (res JobResolver, err error) {
+	timeout, err := time.ParseDuration(input.Timeout)
+	if err != nil {
+		return res, err
+	}
+	job, err := r.engine.waitJobResult(ctx, input.JobID, timeout)
+	if err != nil {
+		return res, err
+	}
+	res.ParseFromJob(&job, -1)
+	return
+}
+
 func (r *rootResolver) DeleteJob(ctx context.Context, input struct{ JobID string }) (ok string, err error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionDelete, "delete_job", input.JobID); err != nil {
+		return "", err
+	}
 	job, err := r.engine.opt.persistent.DeleteJob(ctx, input.JobID)
 	if err != nil {
 		return "", err
@@ -146,6 +164,9 @@ func (r *rootResolver) DeleteJob(ctx context.Context, input struct{ JobID string
 }
 
 func (r *rootResolver) AddJob(ctx context.Context, input struct{ Param AddJobInputResolver }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionRetry, "add_job", input.Param.TaskName); err != nil {
+		return "", err
+	}
 	job := AddJobRequest{
 		TaskName: input.Param.TaskName,
 		MaxRetry: int(input.Param.MaxRetry),
@@ -167,18 +188,27 @@ func (r *rootResolver) AddJob(ctx context.Context, input struct{ Param AddJobInp
 func (r *rootResolver) StopJob(ctx context.Context, input struct {
 	JobID string
 }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionDelete, "stop_job", input.JobID); err != nil {
+		return "", err
+	}
 	return "Success stop job " + input.JobID, StopJob(r.engine.ctx, input.JobID)
 }
 
 func (r *rootResolver) RetryJob(ctx context.Context, input struct {
 	JobID string
 }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionRetry, "retry_job", input.JobID); err != nil {
+		return "", err
+	}
 	return "Success retry job " + input.JobID, RetryJob(r.engine.ctx, input.JobID)
 }
 
 func (r *rootResolver) StopAllJob(ctx context.Context, input struct {
 	TaskName string
 }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionDelete, "stop_all_job", input.TaskName); err != nil {
+		return "", err
+	}
 	if _, ok := r.engine.registeredTaskWorkerIndex[input.TaskName]; !ok {
 		return "", fmt.Errorf("task '%s' unregistered, task must one of [%s]",
 			input.TaskName, strings.Join(r.engine.tasks, ", "))
@@ -224,6 +254,9 @@ func (r *rootResolver) StopAllJob(ctx context.Context, input struct {
 func (r *rootResolver) RetryAllJob(ctx context.Context, input struct {
 	Filter FilterMutateJobInputResolver
 }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionRetry, "retry_all_job", string(candihelper.ToBytes(input.Filter))); err != nil {
+		return "", err
+	}
 	go func(ctx context.Context, req *FilterMutateJobInputResolver) {
 		summary := r.engine.opt.persistent.Summary().FindDetailSummary(ctx, req.TaskName)
 
@@ -278,6 +311,9 @@ func (r *rootResolver) RetryAllJob(ctx context.Context, input struct {
 func (r *rootResolver) CleanJob(ctx context.Context, input struct {
 	Filter FilterMutateJobInputResolver
 }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionDelete, "clean_job", string(candihelper.ToBytes(input.Filter))); err != nil {
+		return "", err
+	}
 	go func(ctx context.Context, req *FilterMutateJobInputResolver) {
 		filter := req.ToFilter()
 		r.engine.subscriber.broadcastWhenChangeAllJob(ctx, filter.TaskName, true, "Cleaning...")
@@ -306,12 +342,18 @@ func (r *rootResolver) CleanJob(ctx context.Context, input struct {
 }
 
 func (r *rootResolver) RecalculateSummary(ctx context.Context) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionManage, "recalculate_summary", ""); err != nil {
+		return "", err
+	}
 	RecalculateSummary(ctx)
 	r.engine.subscriber.broadcastAllToSubscribers(r.engine.ctx)
 	return "Success recalculate summary", nil
 }
 
 func (r *rootResolver) ClearAllClientSubscriber(ctx context.Context) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionManage, "clear_all_client_subscriber", ""); err != nil {
+		return "", err
+	}
 	go func() {
 		for k := range r.engine.subscriber.clientTaskSubscribers {
 			r.engine.subscriber.removeTaskListSubscriber(k)
@@ -331,6 +373,9 @@ func (r *rootResolver) ClearAllClientSubscriber(ctx context.Context) (string, er
 }
 
 func (r *rootResolver) KillClientSubscriber(ctx context.Context, input struct{ ClientID string }) (string, error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionManage, "kill_client_subscriber", input.ClientID); err != nil {
+		return "", err
+	}
 	taskSubs, ok := r.engine.subscriber.clientTaskSubscribers[input.ClientID]
 	if ok {
 		taskSubs.c <- TaskListResolver{Meta: MetaTaskResolver{IsCloseSession: true}}
@@ -420,6 +465,9 @@ func (r *rootResolver) GetDetailConfiguration(ctx context.Context, input struct{
 func (r *rootResolver) SetConfiguration(ctx context.Context, input struct {
 	Config ConfigurationResolver
 }) (res string, err error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionManage, "set_configuration", input.Config.Key); err != nil {
+		return res, err
+	}
 	if err := r.engine.configuration.setConfiguration(&Configuration{
 		Key: input.Config.Key, Name: input.Config.Name, Value: input.Config.Value, IsActive: input.Config.IsActive,
 	}); err != nil {
@@ -431,12 +479,18 @@ func (r *rootResolver) SetConfiguration(ctx context.Context, input struct {
 func (r *rootResolver) RunQueuedJob(ctx context.Context, input struct {
 	TaskName string
 }) (res string, err error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionRetry, "run_queued_job", input.TaskName); err != nil {
+		return res, err
+	}
 	r.engine.unlockTask(input.TaskName)
 	r.engine.registerNextJob(true, input.TaskName)
 	return "Success", nil
 }
 
 func (r *rootResolver) RestoreFromSecondary(ctx context.Context) (res RestoreSecondaryResolver, err error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionManage, "restore_from_secondary", ""); err != nil {
+		return res, err
+	}
 	filter := &Filter{
 		Sort:                "created_at",
 		secondaryPersistent: true,
@@ -461,6 +515,41 @@ func (r *rootResolver) RestoreFromSecondary(ctx context.Context) (res RestoreSec
 	return
 }
 
+func (r *rootResolver) GetTaskArgsForm(ctx context.Context, input struct{ TaskName string }) (TaskArgsFormResolver, error) {
+	return r.engine.getTaskArgsForm(input.TaskName)
+}
+
+func (r *rootResolver) GetAuditLogs(ctx context.Context, input struct {
+	Page, Limit int
+	Search      *string
+}) (res AuditLogListResolver, err error) {
+	if input.Page <= 0 {
+		input.Page = 1
+	}
+	if input.Limit <= 0 {
+		input.Limit = 10
+	}
+	filter := &Filter{Page: input.Page, Limit: input.Limit, Search: input.Search}
+	auditLogs, count, err := r.engine.auditLog.FindAllAuditLog(ctx, filter)
+	if err != nil {
+		return res, err
+	}
+
+	res.Meta = AuditLogMetaResolver{
+		Page: filter.Page, Limit: filter.Limit, TotalRecords: count,
+		TotalPages: int(math.Ceil(float64(count) / float64(filter.Limit))),
+	}
+	res.Data = make([]AuditLogResolver, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		res.Data = append(res.Data, AuditLogResolver{
+			ID: auditLog.ID, Username: auditLog.Username, Action: auditLog.Action, Target: auditLog.Target,
+			Permission: auditLog.Permission, Allowed: auditLog.Allowed, RemoteAddr: auditLog.RemoteAddr,
+			CreatedAt: auditLog.CreatedAt.In(candihelper.AsiaJakartaLocalTime).Format(time.RFC3339),
+		})
+	}
+	return
+}
+
 func (r *rootResolver) ListenTaskDashboard(ctx context.Context, input struct {
 	Page, Limit int
 	Search      *string
@@ -618,6 +707,9 @@ func (r *rootResolver) HoldJobTask(ctx context.Context, input struct {
 	SwitchInterval *string
 	FirstSwitch    *string
 }) (res string, err error) {
+	if err := r.engine.authorize(ctx, DashboardPermissionManage, "hold_job_task", input.TaskName); err != nil {
+		return res, err
+	}
 	summaryTask := r.engine.opt.persistent.Summary().FindDetailSummary(ctx, input.TaskName)
 	if summaryTask.TaskName == "" {
 		return res, errors.New("Task not found")
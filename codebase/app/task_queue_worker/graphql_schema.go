@@ -15,6 +15,9 @@ type Query {
 	get_all_configuration(): [ConfigurationResolver!]!
 	get_detail_configuration(key: String!): ConfigurationResolver!
 	parse_cron_expression(expr: String!): [String!]!
+	get_task_args_form(task_name: String!): TaskArgsFormResolver!
+	get_audit_logs(page: Int!, limit: Int!, search: String): AuditLogListResolver!
+	wait_job_result(job_id: String!, timeout: String!): JobResolver!
 }
 
 type Mutation {
@@ -109,9 +112,27 @@ type TaskResolver {
 	is_loading: Boolean!
 	loading_message: String!
 	is_hold: Boolean!
+	args_schema: String
 	detail: TaskDetailResolver!
 }
 
+type TaskArgsFormResolver {
+	task_name: String!
+	schema: String!
+	fields: [TaskArgsFormField!]!
+}
+
+type TaskArgsFormField {
+	name: String!
+	title: String!
+	description: String!
+	type: String!
+	format: String!
+	required: Boolean!
+	enum: [String!]!
+	default: String
+}
+
 type TaskListResolver {
 	meta: MetaTaskResolver!
 	data: [TaskResolver!]!
@@ -232,6 +253,29 @@ type RestoreSecondaryResolver {
 	message: String!
 }
 
+type AuditLogListResolver {
+	meta: AuditLogMetaResolver!
+	data: [AuditLogResolver!]!
+}
+
+type AuditLogMetaResolver {
+	page: Int!
+	limit: Int!
+	total_pages: Int!
+	total_records: Int!
+}
+
+type AuditLogResolver {
+	id: String!
+	username: String!
+	action: String!
+	target: String!
+	permission: String!
+	allowed: Boolean!
+	remote_addr: String!
+	created_at: String!
+}
+
 input FilterMutateJobInputResolver {
 	task_name: String!
 	search: String
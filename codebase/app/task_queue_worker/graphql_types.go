@@ -54,6 +54,7 @@ type (
 		IsLoading      bool
 		LoadingMessage string
 		IsHold         bool
+		ArgsSchema     *string
 		Detail         SummaryDetail
 	}
 	// TaskListResolver resolver
@@ -67,6 +68,32 @@ type (
 		Message   string
 	}
 
+	// AuditLogListResolver resolver
+	AuditLogListResolver struct {
+		Meta AuditLogMetaResolver
+		Data []AuditLogResolver
+	}
+
+	// AuditLogMetaResolver resolver
+	AuditLogMetaResolver struct {
+		Page         int
+		Limit        int
+		TotalPages   int
+		TotalRecords int
+	}
+
+	// AuditLogResolver resolver
+	AuditLogResolver struct {
+		ID         string
+		Username   string
+		Action     string
+		Target     string
+		Permission string
+		Allowed    bool
+		RemoteAddr string
+		CreatedAt  string
+	}
+
 	// MetaJobList resolver
 	MetaJobList struct {
 		Page              int
@@ -256,20 +283,20 @@ func (j *JobResolver) ParseFromJob(job *Job, maxArgsLength int) {
 	j.ID = job.ID
 	j.TaskName = job.TaskName
 
-	j.Arguments = job.Arguments
+	j.Arguments = renderProtoPayload(job.Arguments)
 	j.Error = job.Error
-	j.Result = job.Result
+	j.Result = renderProtoPayload(job.Result)
 	if maxArgsLength > 0 {
-		if len(job.Arguments) > maxArgsLength {
-			j.Arguments = job.Arguments[:maxArgsLength]
+		if len(j.Arguments) > maxArgsLength {
+			j.Arguments = j.Arguments[:maxArgsLength]
 			j.Meta.IsShowMoreArgs = true
 		}
 		if len(job.Error) > maxArgsLength {
 			j.Error = job.Error[:maxArgsLength]
 			j.Meta.IsShowMoreError = true
 		}
-		if len(job.Result) > maxArgsLength {
-			j.Result = job.Result[:maxArgsLength]
+		if len(j.Result) > maxArgsLength {
+			j.Result = j.Result[:maxArgsLength]
 			j.Meta.IsShowMoreResult = true
 		}
 	}
@@ -0,0 +1,145 @@
+package taskqueueworker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/logger"
+)
+
+// minHeartbeatInterval heartbeat more frequent than this interval is not persisted
+const minHeartbeatInterval = time.Second
+
+// heartbeatFunc heartbeat of running job, update job "updated_at" used by stuck job reaper
+func (t *taskQueueWorker) heartbeatFunc(jobID string) func() error {
+	var mu sync.Mutex
+	lastHeartbeat := time.Now()
+	return func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(lastHeartbeat) < minHeartbeatInterval {
+			return nil
+		}
+		lastHeartbeat = time.Now()
+		_, _, err := t.opt.persistent.UpdateJob(t.ctx, &Filter{
+			JobID: &jobID, Status: candihelper.WrapPtr(StatusRetrying.String()),
+		}, map[string]any{})
+		return err
+	}
+}
+
+// getVisibilityTimeout heartbeat timeout of running job in task, zero if disabled
+func (t *taskQueueWorker) getVisibilityTimeout(task *Task) time.Duration {
+	if timeout, ok := task.handler.Configs[TaskOptionVisibilityTimeout].(time.Duration); ok {
+		return timeout
+	}
+	return t.opt.jobVisibilityTimeout
+}
+
+// runStuckJobReaper check stuck job periodically (half of smallest visibility timeout)
+func (t *taskQueueWorker) runStuckJobReaper() {
+	var interval time.Duration
+	for _, taskName := range t.tasks {
+		timeout := t.getVisibilityTimeout(t.runningWorkerIndexTask[t.registeredTaskWorkerIndex[taskName]])
+		if timeout > 0 && (interval == 0 || timeout/2 < interval) {
+			interval = timeout / 2
+		}
+	}
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(max(interval, minHeartbeatInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			t.reapStuckJobs(now())
+		}
+	}
+}
+
+// reapStuckJobs requeue (or fail if max retry reached) running job without heartbeat within visibility timeout
+func (t *taskQueueWorker) reapStuckJobs(now time.Time) (reaped int) {
+	lockKey := t.getLockKey("internal_task:stuck_job_reaper")
+	if t.opt.locker.IsLocked(lockKey) {
+		return 0
+	}
+	defer t.opt.locker.Unlock(lockKey)
+
+	for _, taskName := range t.tasks {
+		timeout := t.getVisibilityTimeout(t.runningWorkerIndexTask[t.registeredTaskWorkerIndex[taskName]])
+		if timeout <= 0 {
+			continue
+		}
+
+		var stuckJobs []Job
+		StreamAllJob(t.ctx, &Filter{
+			TaskName: taskName, Sort: "created_at", Status: candihelper.WrapPtr(StatusRetrying.String()),
+		}, func(_, _ int, job *Job) {
+			if !job.UpdatedAt.IsZero() && now.Sub(job.UpdatedAt) > timeout {
+				stuckJobs = append(stuckJobs, *job)
+			}
+		})
+		for i := range stuckJobs {
+			if t.reapJob(&stuckJobs[i], timeout) {
+				reaped++
+			}
+		}
+		if len(stuckJobs) > 0 {
+			t.unlockTask(taskName)
+			t.registerNextJob(false, taskName)
+		}
+	}
+	if reaped > 0 {
+		t.subscriber.broadcastAllToSubscribers(t.ctx)
+	}
+	return reaped
+}
+
+func (t *taskQueueWorker) reapJob(job *Job, timeout time.Duration) bool {
+	statusBefore := job.Status
+	job.Status = StatusFailure.String()
+	job.Error = fmt.Sprintf("No heartbeat within visibility timeout (%s), worker may have died or handler hang", timeout)
+	job.FinishedAt = time.Now()
+	updated := map[string]any{
+		"status": job.Status, "error": job.Error, "finished_at": job.FinishedAt, "next_running_at": nil,
+	}
+	if job.Retries < job.MaxRetry {
+		job.Status = StatusQueueing.String()
+		job.NextRunningAt = time.Time{}
+		job.ParseNextRunningInterval()
+		updated["status"], updated["next_running_at"] = job.Status, job.NextRunningAt
+	}
+
+	matchedCount, affectedCount, err := t.opt.persistent.UpdateJob(t.ctx, &Filter{
+		JobID: &job.ID, Status: &statusBefore,
+	}, updated, RetryHistory{
+		Status: StatusFailure.String(), Error: job.Error, TraceID: job.TraceID, StartAt: job.UpdatedAt, EndAt: job.FinishedAt,
+	})
+	if err != nil || affectedCount == 0 {
+		logger.LogIfError(err)
+		return false
+	}
+	t.opt.persistent.Summary().IncrementSummary(t.ctx, job.TaskName, map[string]int64{
+		job.Status: affectedCount, strings.ToLower(statusBefore): -matchedCount,
+	})
+	logger.LogYellow("TaskQueueWorker: reaped stuck job '" + job.ID + "' of task '" + job.TaskName + "' to " + job.Status)
+
+	// stop handler if stuck job is running in this runtime
+	if cancel, ok := t.runningJobs.Load(job.ID); ok {
+		cancel.(context.CancelFunc)()
+	}
+	if job.Status == StatusQueueing.String() {
+		t.opt.queue.PushJob(t.ctx, job)
+	} else {
+		t.jobResult.notify(job)
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package taskqueueworker
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePersistent in memory job store, filter only by job id, task name and status
+type fakePersistent struct {
+	noopPersistent
+	mu             sync.Mutex
+	jobs           map[string]*Job
+	retryHistories map[string][]RetryHistory
+	beforeUpdate   func(job *Job)
+}
+
+func newFakePersistent(jobs ...Job) *fakePersistent {
+	f := &fakePersistent{
+		noopPersistent: noopPersistent{summary: &inMemSummary{values: make(map[string]*TaskSummary)}},
+		jobs:           make(map[string]*Job), retryHistories: make(map[string][]RetryHistory),
+	}
+	for i := range jobs {
+		f.jobs[jobs[i].ID] = &jobs[i]
+	}
+	return f
+}
+
+func (f *fakePersistent) match(job *Job, filter *Filter) bool {
+	return (filter.JobID == nil || *filter.JobID == job.ID) &&
+		(filter.TaskName == "" || filter.TaskName == job.TaskName) &&
+		(filter.Status == nil || *filter.Status == job.Status)
+}
+
+func (f *fakePersistent) FindAllJob(ctx context.Context, filter *Filter) (jobs []Job) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, job := range f.jobs {
+		if f.match(job, filter) {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs
+}
+
+func (f *fakePersistent) CountAllJob(ctx context.Context, filter *Filter) int {
+	return len(f.FindAllJob(ctx, filter))
+}
+
+func (f *fakePersistent) FindJobByID(ctx context.Context, id string, filter *Filter) (job Job, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if j, ok := f.jobs[id]; ok {
+		job = *j
+	}
+	return job, nil
+}
+
+func (f *fakePersistent) UpdateJob(ctx context.Context, filter *Filter, updated map[string]any, retryHistories ...RetryHistory) (matchedCount, affectedRow int64, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, job := range f.jobs {
+		if f.beforeUpdate != nil {
+			f.beforeUpdate(job)
+		}
+		if !f.match(job, filter) {
+			continue
+		}
+		matchedCount++
+		if status, ok := updated["status"].(string); ok {
+			job.Status = status
+		}
+		if errMsg, ok := updated["error"].(string); ok {
+			job.Error = errMsg
+		}
+		f.retryHistories[job.ID] = append(f.retryHistories[job.ID], retryHistories...)
+		affectedRow++
+	}
+	return matchedCount, affectedRow, nil
+}
+
+func newTestReaperWorker(persistent Persistent) *taskQueueWorker {
+	return &taskQueueWorker{
+		ctx:                       context.Background(),
+		refreshWorkerNotif:        make(chan struct{}, 1),
+		opt:                       &option{persistent: persistent, queue: NewInMemQueue(), locker: &candiutils.NoopLocker{}},
+		jobResult:                 newJobResultNotifier(nil, "test"),
+		subscriber:                &subscriber{},
+		registeredTaskWorkerIndex: map[string]int{"sync-user": 1},
+		runningWorkerIndexTask:    map[int]*Task{1: {taskName: "sync-user", workerIndex: 1}},
+		workerChannels:            make([]reflect.SelectCase, 2),
+		tasks:                     []string{"sync-user"},
+	}
+}
+
+func TestReapJob(t *testing.T) {
+	t.Run("stuck job with remaining retry is requeued", func(t *testing.T) {
+		persistent := newFakePersistent(Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), Retries: 1, MaxRetry: 3, Interval: "1s"})
+		worker := newTestReaperWorker(persistent)
+
+		assert.True(t, worker.reapJob(&Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), Retries: 1, MaxRetry: 3, Interval: "1s"}, time.Minute))
+		assert.Equal(t, StatusQueueing.String(), persistent.jobs["1"].Status)
+		assert.Equal(t, StatusFailure.String(), persistent.retryHistories["1"][0].Status)
+		assert.Equal(t, "1", worker.opt.queue.NextJob(worker.ctx, "sync-user"))
+	})
+
+	t.Run("stuck job at max retry is failed", func(t *testing.T) {
+		persistent := newFakePersistent(Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), Retries: 3, MaxRetry: 3})
+		worker := newTestReaperWorker(persistent)
+		result := worker.jobResult.subscribe("1")
+
+		assert.True(t, worker.reapJob(&Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), Retries: 3, MaxRetry: 3}, time.Minute))
+		assert.Equal(t, StatusFailure.String(), persistent.jobs["1"].Status)
+		assert.Contains(t, persistent.jobs["1"].Error, "No heartbeat within visibility timeout (1m0s)")
+		assert.Empty(t, worker.opt.queue.NextJob(worker.ctx, "sync-user"))
+		select {
+		case job := <-result:
+			assert.Equal(t, StatusFailure.String(), job.Status)
+		default:
+			t.Fatal("job result waiter is not notified")
+		}
+	})
+
+	t.Run("job status changed concurrently is not reaped", func(t *testing.T) {
+		persistent := newFakePersistent(Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), MaxRetry: 3})
+		persistent.beforeUpdate = func(job *Job) { job.Status = StatusSuccess.String() }
+		worker := newTestReaperWorker(persistent)
+
+		assert.False(t, worker.reapJob(&Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), MaxRetry: 3}, time.Minute))
+		assert.Equal(t, StatusSuccess.String(), persistent.jobs["1"].Status)
+		assert.Empty(t, persistent.retryHistories["1"])
+		assert.Empty(t, worker.opt.queue.NextJob(worker.ctx, "sync-user"))
+	})
+
+	t.Run("handler of stuck job running in this runtime is cancelled", func(t *testing.T) {
+		persistent := newFakePersistent(Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), Retries: 3, MaxRetry: 3})
+		worker := newTestReaperWorker(persistent)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		worker.runningJobs.Store("1", cancel)
+
+		assert.True(t, worker.reapJob(&Job{ID: "1", TaskName: "sync-user", Status: StatusRetrying.String(), Retries: 3, MaxRetry: 3}, time.Minute))
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
+
+func TestReapStuckJobs(t *testing.T) {
+	current := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	persistent := newFakePersistent(
+		Job{ID: "stuck", TaskName: "sync-user", Status: StatusRetrying.String(), MaxRetry: 3, Interval: "1s", UpdatedAt: current.Add(-2 * time.Minute)},
+		Job{ID: "alive", TaskName: "sync-user", Status: StatusRetrying.String(), MaxRetry: 3, Interval: "1s", UpdatedAt: current.Add(-10 * time.Second)},
+		Job{ID: "queued", TaskName: "sync-user", Status: StatusQueueing.String(), UpdatedAt: current.Add(-time.Hour)},
+	)
+	worker := newTestReaperWorker(persistent)
+	worker.opt.jobVisibilityTimeout = time.Minute
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("test-service"))
+	worker.service = service
+
+	prevEngine := engine
+	engine = worker
+	defer func() { engine = prevEngine }()
+
+	assert.Equal(t, 1, worker.reapStuckJobs(current))
+	assert.Equal(t, StatusQueueing.String(), persistent.jobs["stuck"].Status)
+	assert.Equal(t, StatusRetrying.String(), persistent.jobs["alive"].Status, "job with recent heartbeat is not reaped")
+	assert.Equal(t, StatusQueueing.String(), persistent.jobs["queued"].Status)
+	if task := worker.runningWorkerIndexTask[1]; task.activeInterval != nil {
+		task.activeInterval.Stop()
+	}
+}
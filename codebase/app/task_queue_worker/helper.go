@@ -1,6 +1,10 @@
 package taskqueueworker
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/golangid/candi/candishared"
+)
 
 var (
 	errClientLimitExceeded = errors.New("client limit exceeded, please try again later")
@@ -21,3 +25,9 @@ func normalizeCount(count int) int {
 	}
 	return count
 }
+
+// renderProtoPayload render proto payload in job arguments/result as json for dashboard, message type must be
+// registered in proto registry (imported generated proto package)
+func renderProtoPayload(payload string) string {
+	return string(candishared.ProtoPayloadToJSON([]byte(payload)))
+}
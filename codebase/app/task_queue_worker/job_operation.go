@@ -18,6 +18,7 @@ import (
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
+	"google.golang.org/protobuf/proto"
 )
 
 type (
@@ -30,6 +31,10 @@ type (
 		StartAt        time.Time     `json:"start_at"`
 		CronExpression string        `json:"cron_expression"`
 
+		// ProtoArgs set args from proto message (stored with type url, see candishared.MarshalProtoPayload),
+		// bind in handler with candishared.BindMessage
+		ProtoArgs proto.Message `json:"-"`
+
 		direct   bool              `json:"-"`
 		schedule cronexpr.Schedule `json:"-"`
 	}
@@ -37,6 +42,14 @@ type (
 
 // Validate method
 func (a *AddJobRequest) Validate() error {
+	if a.ProtoArgs != nil {
+		args, err := candishared.MarshalProtoPayload(a.ProtoArgs)
+		if err != nil {
+			return err
+		}
+		a.Args, a.ProtoArgs = args, nil
+	}
+
 	if a.CronExpression != "" {
 		schedule, err := cronexpr.Parse(a.CronExpression)
 		if err != nil {
@@ -88,6 +101,9 @@ func AddJob(ctx context.Context, req *AddJobRequest) (jobID string, err error) {
 		return jobID, fmt.Errorf("task '%s' unregistered, task must one of [%s]",
 			req.TaskName, strings.Join(engine.tasks, ", "))
 	}
+	if err = engine.validateArgs(req.TaskName, req.Args); err != nil {
+		return jobID, err
+	}
 
 	var newJob Job
 	newJob.TaskName = req.TaskName
@@ -109,13 +125,19 @@ func AddJob(ctx context.Context, req *AddJobRequest) (jobID string, err error) {
 		}
 		newJob.Interval = req.CronExpression
 		if req.StartAt.IsZero() {
-			req.StartAt = time.Now()
+			req.StartAt = now()
 		}
 		newJob.NextRunningAt = req.schedule.Next(req.StartAt)
 	}
 	newJob.Status = string(StatusQueueing)
-	newJob.CreatedAt = time.Now()
+	newJob.CreatedAt = now()
 	newJob.direct = req.direct
+	metadata := make(map[string]string)
+	candishared.InjectBaggage(ctx, metadata)
+	candishared.InjectRequestID(ctx, metadata)
+	if len(metadata) > 0 {
+		newJob.Metadata = metadata
+	}
 
 	ctx = context.WithoutCancel(ctx)
 	summary := engine.opt.persistent.Summary().FindDetailSummary(ctx, req.TaskName)
@@ -308,6 +330,9 @@ func StopJob(ctx context.Context, jobID string) error {
 	})
 	engine.subscriber.broadcastAllToSubscribers(ctx)
 	engine.registerNextJob(false, job.TaskName)
+	if countAffected > 0 {
+		engine.jobResult.notify(&job)
+	}
 
 	return nil
 }
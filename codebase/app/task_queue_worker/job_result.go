@@ -0,0 +1,235 @@
+package taskqueueworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrWaitJobResultTimeout job not finished until wait timeout
+var ErrWaitJobResultTimeout = errors.New("timeout when waiting job result")
+
+const waitJobResultPollInterval = 2 * time.Second
+
+// jobResultNotifier notify waiter when job finished, in process and across instances with redis pubsub (if redis available)
+type jobResultNotifier struct {
+	mu        sync.Mutex
+	waiters   map[string]map[chan Job]struct{}
+	redisPool *redis.Pool
+	channel   string
+}
+
+func newJobResultNotifier(redisPool *redis.Pool, serviceName string) *jobResultNotifier {
+	return &jobResultNotifier{
+		waiters:   make(map[string]map[chan Job]struct{}),
+		redisPool: redisPool,
+		channel:   serviceName + ":task-queue-worker:job-result:",
+	}
+}
+
+func (n *jobResultNotifier) subscribe(jobID string) chan Job {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ch := make(chan Job, 1)
+	if n.waiters[jobID] == nil {
+		n.waiters[jobID] = make(map[chan Job]struct{})
+	}
+	n.waiters[jobID][ch] = struct{}{}
+	return ch
+}
+
+func (n *jobResultNotifier) unsubscribe(jobID string, ch chan Job) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.waiters[jobID], ch)
+	if len(n.waiters[jobID]) == 0 {
+		delete(n.waiters, jobID)
+	}
+}
+
+func (n *jobResultNotifier) notify(job *Job) {
+	if !job.IsFinished() {
+		return
+	}
+
+	n.mu.Lock()
+	for ch := range n.waiters[job.ID] {
+		select {
+		case ch <- *job:
+		default:
+		}
+	}
+	n.mu.Unlock()
+
+	if n.redisPool != nil {
+		conn := n.redisPool.Get()
+		defer conn.Close()
+		if _, err := conn.Do("PUBLISH", n.channel+job.ID, candihelper.ToBytes(job)); err != nil {
+			logger.LogE("Task Queue Worker: publish job result: " + err.Error())
+		}
+	}
+}
+
+// listenRedis forward job result published by another instance to waiter channel until context done
+func (n *jobResultNotifier) listenRedis(ctx context.Context, jobID string, ch chan Job) {
+	if n.redisPool == nil {
+		return
+	}
+
+	conn := n.redisPool.Get()
+	psc := &redis.PubSubConn{Conn: conn}
+	if err := psc.Subscribe(n.channel + jobID); err != nil {
+		conn.Close()
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		psc.Unsubscribe()
+		conn.Close()
+	}()
+	go func() {
+		for {
+			switch msg := psc.Receive().(type) {
+			case redis.Message:
+				var job Job
+				if json.Unmarshal(msg.Data, &job) == nil {
+					select {
+					case ch <- job:
+					default:
+					}
+				}
+			case error:
+				return
+			}
+		}
+	}()
+}
+
+// IsFinished job has been finished (success, failure without remaining retry, or stopped)
+func (job *Job) IsFinished() bool {
+	switch JobStatusEnum(job.Status) {
+	case StatusSuccess, StatusFailure, StatusStopped:
+		return true
+	}
+	return false
+}
+
+// WaitJobResult api for wait job finished and return the job with result payload (written by handler with
+// eventContext.WriteResult), return ErrWaitJobResultTimeout with latest job state if job not finished until timeout.
+// If external worker host is set, wait via HTTP long-poll to the worker
+func WaitJobResult(ctx context.Context, jobID string, timeout time.Duration) (job Job, err error) {
+	if externalWorkerHost != "" {
+		return WaitJobResultViaHTTPRequest(ctx, externalWorkerHost, jobID, timeout)
+	}
+	if engine == nil {
+		return job, errWorkerInactive
+	}
+	return engine.waitJobResult(ctx, jobID, timeout)
+}
+
+func (t *taskQueueWorker) waitJobResult(ctx context.Context, jobID string, timeout time.Duration) (job Job, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// subscribe before check current job state for prevent missing notification
+	ch := t.jobResult.subscribe(jobID)
+	defer t.jobResult.unsubscribe(jobID, ch)
+	listenCtx, cancelListen := context.WithCancel(ctx)
+	defer cancelListen()
+	t.jobResult.listenRedis(listenCtx, jobID, ch)
+
+	job, err = t.opt.persistent.FindJobByID(ctx, jobID, nil)
+	if err != nil {
+		return job, err
+	}
+
+	// poll persistent as fallback when job executed by another instance without redis
+	ticker := time.NewTicker(waitJobResultPollInterval)
+	defer ticker.Stop()
+	for !job.IsFinished() {
+		select {
+		case job = <-ch:
+			return job, nil
+
+		case <-ticker.C:
+			latest, err := t.opt.persistent.FindJobByID(ctx, jobID, nil)
+			if err != nil {
+				return job, err
+			}
+			job = latest
+
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return job, ErrWaitJobResultTimeout
+			}
+			return job, ctx.Err()
+		}
+	}
+	return job, nil
+}
+
+// WaitJobResultViaHTTPRequest api for wait job result from worker host with HTTP long-poll (GraphQL query wait_job_result)
+func WaitJobResultViaHTTPRequest(ctx context.Context, workerHost string, jobID string, timeout time.Duration) (job Job, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "TaskQueueWorker:WaitJobResultViaHTTPRequest")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+	trace.SetTag("job_id", jobID)
+
+	httpClient := &http.Client{}
+	if timeout > 0 {
+		httpClient.Timeout = timeout + 30*time.Second
+	}
+	httpReq := candiutils.NewHTTPRequest(
+		candiutils.HTTPRequestSetBreakerName("task_queue_worker_wait_job_result"),
+		candiutils.HTTPRequestSetClient(httpClient),
+	)
+	header := map[string]string{
+		candihelper.HeaderContentType: candihelper.HeaderMIMEApplicationJSON,
+	}
+	reqBody := map[string]any{
+		"operationName": "waitJobResult",
+		"variables": map[string]any{
+			"job_id": jobID, "timeout": timeout.String(),
+		},
+		"query": `query waitJobResult($job_id: String!, $timeout: String!) { wait_job_result(job_id: $job_id, timeout: $timeout) ` +
+			`{ id task_name arguments retries max_retry status error result trace_id } }`,
+	}
+	httpResp, err := httpReq.DoRequest(ctx, http.MethodPost, strings.Trim(workerHost, "/")+"/graphql", candihelper.ToBytes(reqBody), header)
+	if err != nil {
+		return job, err
+	}
+
+	var respPayload struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		Data struct {
+			WaitJobResult struct {
+				ID string `json:"id"`
+				Job
+			} `json:"wait_job_result"`
+		} `json:"data"`
+	}
+	json.Unmarshal(httpResp.Bytes(), &respPayload)
+	if len(respPayload.Errors) > 0 {
+		if respPayload.Errors[0].Message == ErrWaitJobResultTimeout.Error() {
+			return job, ErrWaitJobResultTimeout
+		}
+		return job, errors.New(respPayload.Errors[0].Message)
+	}
+	job = respPayload.Data.WaitJobResult.Job
+	job.ID = respPayload.Data.WaitJobResult.ID
+	return job, nil
+}
@@ -2,11 +2,9 @@ package taskqueueworker
 
 import (
 	"crypto/tls"
-	"encoding/base64"
-	"net/http"
-	"strings"
 	"time"
 
+	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/codebase/interfaces"
 )
 
@@ -19,10 +17,14 @@ type (
 		autoRemoveClientInterval time.Duration
 		dashboardBanner          string
 		dashboardPort            uint16
-		dashboardAuthKey         string
+		dashboardAuth            DashboardAuth
+		dashboardRoles           map[string][]DashboardPermission
+		auditLogRetention        int
 		debugMode                bool
 		locker                   interfaces.Locker
 		tlsConfig                *tls.Config
+		jobVisibilityTimeout     time.Duration
+		clock                    candihelper.Clock
 	}
 
 	// OptionFunc type
@@ -100,42 +102,31 @@ func SetExternalWorkerHost(host string) OptionFunc {
 	}
 }
 
-// SetDashboardBasicAuth option func
+// SetDashboardBasicAuth option func, dashboard basic auth with single admin user
 func SetDashboardBasicAuth(username, password string) OptionFunc {
 	return func(o *option) {
-		o.dashboardAuthKey = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		o.dashboardAuth = NewDashboardBasicAuth(DashboardBasicAuthUser{Username: username, Password: password})
 	}
 }
 
-func (o *option) basicAuth(next http.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if strings.Contains(r.URL.Path, "/_next") {
-			r.URL.Path = strings.TrimPrefix(r.URL.Path, "/task")
-			r.URL.Path = strings.TrimPrefix(r.URL.Path, "/job")
-			r.URL.Path = strings.TrimPrefix(r.URL.Path, "/expired")
-		}
-		if o.dashboardAuthKey == "" {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		w.Header().Set("WWW-Authenticate", `Basic realm=""`)
-
-		auth := r.Header.Get("Authorization")
-		const prefix = "Basic "
-		if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("Invalid authorization"))
-			return
-		}
+// SetDashboardAuth option func, set dashboard authentication (NewDashboardBasicAuth or NewDashboardOIDCAuth)
+func SetDashboardAuth(auth DashboardAuth) OptionFunc {
+	return func(o *option) {
+		o.dashboardAuth = auth
+	}
+}
 
-		if auth[len(prefix):] != o.dashboardAuthKey {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("Invalid authorization"))
-			return
-		}
+// SetDashboardRole option func, set permissions of dashboard role (override default role "admin", "operator", and "viewer")
+func SetDashboardRole(role string, permissions ...DashboardPermission) OptionFunc {
+	return func(o *option) {
+		o.dashboardRoles[role] = permissions
+	}
+}
 
-		next.ServeHTTP(w, r)
+// SetAuditLogRetention option func, max audit log kept in memory when persistent not support audit log (default 1000)
+func SetAuditLogRetention(size int) OptionFunc {
+	return func(o *option) {
+		o.auditLogRetention = size
 	}
 }
 
@@ -145,3 +136,19 @@ func SetTLSConfig(tlsConfig *tls.Config) OptionFunc {
 		o.tlsConfig = tlsConfig
 	}
 }
+
+// SetJobVisibilityTimeout option func, running job without heartbeat (job started, eventContext.Heartbeat or
+// UpdateProgressJob) within timeout is considered stuck (worker died or handler hang) and requeued (or failed
+// if max retry reached). Default 0 (disabled), override per task with TaskOptionVisibilityTimeout
+func SetJobVisibilityTimeout(timeout time.Duration) OptionFunc {
+	return func(o *option) {
+		o.jobVisibilityTimeout = timeout
+	}
+}
+
+// SetClock option func, set clock for schedule next running and retry of job and reap stuck job (default system clock)
+func SetClock(clock candihelper.Clock) OptionFunc {
+	return func(o *option) {
+		o.clock = clock
+	}
+}
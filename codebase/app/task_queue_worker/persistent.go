@@ -24,6 +24,13 @@ type (
 		SetConfiguration(cfg *Configuration) (err error)
 	}
 
+	// AuditLogPersistent optional abstraction of Persistent for store dashboard audit log alongside the jobs,
+	// audit log is kept in memory if persistent not implement this interface
+	AuditLogPersistent interface {
+		SaveAuditLog(ctx context.Context, auditLog *AuditLog) error
+		FindAllAuditLog(ctx context.Context, filter *Filter) (auditLogs []AuditLog, count int, err error)
+	}
+
 	noopPersistent struct {
 		summary *inMemSummary
 	}
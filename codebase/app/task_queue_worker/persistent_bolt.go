@@ -0,0 +1,438 @@
+package taskqueueworker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/logger"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltPersistent persistent using embedded key-value store (bbolt),
+// for single instance service without external database
+type BoltPersistent struct {
+	db      *bolt.DB
+	summary Summary
+}
+
+// NewBoltPersistent init new persistent using bbolt
+func NewBoltPersistent(db *bolt.DB) *BoltPersistent {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{jobModelName, configurationModelName, auditLogModelName} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		panic("Task Queue Worker: init bolt persistent: " + err.Error())
+	}
+
+	return &BoltPersistent{
+		db:      db,
+		summary: NewInMemSummary(),
+	}
+}
+
+func (b *BoltPersistent) Ping(ctx context.Context) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(jobModelName)) == nil {
+			return errors.New("bolt ping: bucket " + jobModelName + " not found")
+		}
+		return nil
+	})
+}
+func (b *BoltPersistent) SetSummary(summary Summary) {
+	b.summary = summary
+}
+func (b *BoltPersistent) Summary() Summary {
+	return b.summary
+}
+func (b *BoltPersistent) FindAllJob(ctx context.Context, filter *Filter) (jobs []Job) {
+	if filter.Sort == "" {
+		filter.Sort = "-created_at"
+	}
+
+	b.db.View(func(tx *bolt.Tx) error {
+		return b.forEachJob(tx, filter, func(job *Job) error {
+			job.RetryHistories = nil
+			jobs = append(jobs, *job)
+			return nil
+		})
+	})
+
+	sortBoltJobs(jobs, filter.Sort)
+	if !filter.ShowAll {
+		offset := filter.CalculateOffset()
+		if offset < 0 || offset >= len(jobs) {
+			return nil
+		}
+		jobs = jobs[offset:min(offset+filter.Limit, len(jobs))]
+	}
+	return
+}
+func (b *BoltPersistent) FindJobByID(ctx context.Context, id string, filterHistory *Filter) (job Job, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(jobModelName)).Get([]byte(id))
+		if raw == nil {
+			return errors.New("job not found")
+		}
+		return json.Unmarshal(raw, &job)
+	})
+	if err != nil {
+		return job, err
+	}
+
+	if filterHistory == nil {
+		job.RetryHistories = make([]RetryHistory, 0)
+		return job, nil
+	}
+
+	filterHistory.Count = len(job.RetryHistories)
+	offset := filterHistory.CalculateOffset()
+	if offset < 0 || offset >= len(job.RetryHistories) {
+		job.RetryHistories = make([]RetryHistory, 0)
+		return job, nil
+	}
+	job.RetryHistories = job.RetryHistories[offset:min(offset+filterHistory.Limit, len(job.RetryHistories))]
+	return job, nil
+}
+func (b *BoltPersistent) CountAllJob(ctx context.Context, filter *Filter) (count int) {
+	b.db.View(func(tx *bolt.Tx) error {
+		return b.forEachJob(tx, filter, func(job *Job) error {
+			count++
+			return nil
+		})
+	})
+	return
+}
+func (b *BoltPersistent) AggregateAllTaskJob(ctx context.Context, filter *Filter) (result []TaskSummary) {
+	mapSummary := make(map[string]TaskSummary)
+	b.db.View(func(tx *bolt.Tx) error {
+		return b.forEachJob(tx, filter, func(job *Job) error {
+			summary := mapSummary[job.TaskName]
+			switch job.Status {
+			case string(StatusSuccess):
+				summary.Success++
+			case string(StatusQueueing):
+				summary.Queueing++
+			case string(StatusRetrying):
+				summary.Retrying++
+			case string(StatusFailure):
+				summary.Failure++
+			case string(StatusStopped):
+				summary.Stopped++
+			case string(StatusHold):
+				summary.Hold++
+			}
+			mapSummary[job.TaskName] = summary
+			return nil
+		})
+	})
+
+	for taskName, summary := range mapSummary {
+		summary.TaskName = taskName
+		summary.ID = taskName
+		result = append(result, summary)
+	}
+	return
+}
+func (b *BoltPersistent) SaveJob(ctx context.Context, job *Job, retryHistories ...RetryHistory) (err error) {
+	job.UpdatedAt = time.Now()
+	if job.ID == "" {
+		job.ID = uuid.NewString()
+		job.CreatedAt = time.Now()
+	}
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(jobModelName))
+
+		var existing Job
+		if raw := bucket.Get([]byte(job.ID)); raw != nil {
+			json.Unmarshal(raw, &existing)
+		}
+		saved := *job
+		saved.RetryHistories = prependRetryHistories(existing.RetryHistories, retryHistories)
+		raw, err := json.Marshal(saved)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(job.ID), raw)
+	})
+	if err != nil {
+		logger.LogE(err.Error())
+	}
+	return err
+}
+func (b *BoltPersistent) UpdateJob(ctx context.Context, filter *Filter, updated map[string]any, retryHistories ...RetryHistory) (matchedCount, affectedRow int64, err error) {
+	updated["updated_at"] = time.Now()
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(jobModelName))
+
+		var matchedJobs []Job
+		if err := b.forEachJob(tx, filter, func(job *Job) error {
+			matchedJobs = append(matchedJobs, *job)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, job := range matchedJobs {
+			matchedCount++
+			newJob, err := applyBoltJobUpdate(job, updated)
+			if err != nil {
+				return err
+			}
+			newJob.RetryHistories = prependRetryHistories(job.RetryHistories, retryHistories)
+			raw, err := json.Marshal(newJob)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(job.ID), raw); err != nil {
+				return err
+			}
+			affectedRow++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogE(err.Error())
+	}
+	return
+}
+func (b *BoltPersistent) CleanJob(ctx context.Context, filter *Filter) (affectedRow int64) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		var ids []string
+		b.forEachJob(tx, filter, func(job *Job) error {
+			ids = append(ids, job.ID)
+			return nil
+		})
+		bucket := tx.Bucket([]byte(jobModelName))
+		for _, id := range ids {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return err
+			}
+			affectedRow++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogE(err.Error())
+	}
+	return
+}
+func (b *BoltPersistent) DeleteJob(ctx context.Context, id string) (job Job, err error) {
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(jobModelName))
+		raw := bucket.Get([]byte(id))
+		if raw == nil {
+			return errors.New("job not found")
+		}
+		if err := json.Unmarshal(raw, &job); err != nil {
+			return err
+		}
+		return bucket.Delete([]byte(id))
+	})
+	return
+}
+func (b *BoltPersistent) Type() string {
+	return "Bolt Embedded Persistent, file: " + b.db.Path()
+}
+func (b *BoltPersistent) GetAllConfiguration(ctx context.Context) (cfg []Configuration, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(configurationModelName)).ForEach(func(k, v []byte) error {
+			var c Configuration
+			if err := json.Unmarshal(v, &c); err != nil {
+				return err
+			}
+			cfg = append(cfg, c)
+			return nil
+		})
+	})
+	return
+}
+func (b *BoltPersistent) GetConfiguration(key string) (cfg Configuration, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(configurationModelName)).Get([]byte(key))
+		if raw == nil {
+			return errors.New("configuration not found")
+		}
+		return json.Unmarshal(raw, &cfg)
+	})
+	return
+}
+func (b *BoltPersistent) SetConfiguration(cfg *Configuration) (err error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(configurationModelName)).Put([]byte(cfg.Key), raw)
+	})
+}
+
+func (b *BoltPersistent) forEachJob(tx *bolt.Tx, filter *Filter, fn func(job *Job) error) error {
+	if filter.JobID != nil && *filter.JobID != "" {
+		raw := tx.Bucket([]byte(jobModelName)).Get([]byte(*filter.JobID))
+		if raw == nil {
+			return nil
+		}
+		var job Job
+		if err := json.Unmarshal(raw, &job); err != nil {
+			return err
+		}
+		if !matchBoltJobFilter(&job, filter) {
+			return nil
+		}
+		return fn(&job)
+	}
+
+	return tx.Bucket([]byte(jobModelName)).ForEach(func(k, v []byte) error {
+		var job Job
+		if err := json.Unmarshal(v, &job); err != nil {
+			return err
+		}
+		if !matchBoltJobFilter(&job, filter) {
+			return nil
+		}
+		return fn(&job)
+	})
+}
+
+func matchBoltJobFilter(job *Job, f *Filter) bool {
+	if f.TaskName != "" {
+		if job.TaskName != f.TaskName {
+			return false
+		}
+	} else if len(f.TaskNameList) > 0 {
+		if !candihelper.StringInSlice(job.TaskName, f.TaskNameList) {
+			return false
+		}
+	} else if len(f.ExcludeTaskNameList) > 0 {
+		if candihelper.StringInSlice(job.TaskName, f.ExcludeTaskNameList) {
+			return false
+		}
+	}
+
+	if f.JobID != nil && *f.JobID != "" && job.ID != *f.JobID {
+		return false
+	}
+	if f.Search != nil && *f.Search != "" &&
+		!strings.Contains(job.Arguments, *f.Search) && !strings.Contains(job.Error, *f.Search) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !candihelper.StringInSlice(job.Status, f.Statuses) {
+		return false
+	}
+	if f.Status != nil && job.Status != *f.Status {
+		return false
+	}
+	if len(f.ExcludeStatus) > 0 && candihelper.StringInSlice(job.Status, f.ExcludeStatus) {
+		return false
+	}
+	if startDate, endDate := f.ParseStartEndDate(); !startDate.IsZero() && !endDate.IsZero() &&
+		(job.CreatedAt.Before(startDate) || job.CreatedAt.After(endDate)) {
+		return false
+	}
+	if f.BeforeCreatedAt != nil && !f.BeforeCreatedAt.IsZero() && job.CreatedAt.After(*f.BeforeCreatedAt) {
+		return false
+	}
+	if f.MaxRetry != nil && job.MaxRetry != *f.MaxRetry {
+		return false
+	}
+	return true
+}
+
+func sortBoltJobs(jobs []Job, sortBy string) {
+	desc := strings.HasPrefix(sortBy, "-")
+	less := func(a, b *Job) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	switch strings.TrimPrefix(sortBy, "-") {
+	case "updated_at":
+		less = func(a, b *Job) bool { return a.UpdatedAt.Before(b.UpdatedAt) }
+	case "finished_at":
+		less = func(a, b *Job) bool { return a.FinishedAt.Before(b.FinishedAt) }
+	case "next_running_at":
+		less = func(a, b *Job) bool { return a.NextRunningAt.Before(b.NextRunningAt) }
+	case "task_name":
+		less = func(a, b *Job) bool { return a.TaskName < b.TaskName }
+	case "status":
+		less = func(a, b *Job) bool { return a.Status < b.Status }
+	case "retries":
+		less = func(a, b *Job) bool { return a.Retries < b.Retries }
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if desc {
+			return less(&jobs[j], &jobs[i])
+		}
+		return less(&jobs[i], &jobs[j])
+	})
+}
+
+// applyBoltJobUpdate set updated fields (with same key as json tag in Job model) to job
+func applyBoltJobUpdate(job Job, updated map[string]any) (newJob Job, err error) {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return newJob, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return newJob, err
+	}
+	for k, v := range updated {
+		fields[k] = v
+	}
+	raw, err = json.Marshal(fields)
+	if err != nil {
+		return newJob, err
+	}
+	err = json.Unmarshal(raw, &newJob)
+	return newJob, err
+}
+
+func prependRetryHistories(current, newHistories []RetryHistory) []RetryHistory {
+	if len(newHistories) == 0 {
+		return current
+	}
+	histories := append(append([]RetryHistory{}, newHistories...), current...)
+	sort.SliceStable(histories, func(i, j int) bool {
+		return histories[i].StartAt.After(histories[j].StartAt)
+	})
+	return histories
+}
+
+func (b *BoltPersistent) SaveAuditLog(ctx context.Context, auditLog *AuditLog) error {
+	raw, err := json.Marshal(auditLog)
+	if err != nil {
+		return err
+	}
+	// key prefixed with created time for iterate in chronological order
+	key := fmt.Sprintf("%020d-%s", auditLog.CreatedAt.UnixNano(), auditLog.ID)
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(auditLogModelName)).Put([]byte(key), raw)
+	})
+}
+func (b *BoltPersistent) FindAllAuditLog(ctx context.Context, filter *Filter) (auditLogs []AuditLog, count int, err error) {
+	var matched []AuditLog
+	err = b.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(auditLogModelName)).Cursor()
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var auditLog AuditLog
+			if err := json.Unmarshal(v, &auditLog); err != nil {
+				return err
+			}
+			if matchAuditLogSearch(&auditLog, filter.Search) {
+				matched = append(matched, auditLog)
+			}
+		}
+		return nil
+	})
+	return paginateAuditLog(matched, filter), len(matched), err
+}
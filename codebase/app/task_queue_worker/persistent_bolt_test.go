@@ -0,0 +1,171 @@
+package taskqueueworker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestBoltDB(t *testing.T) *bolt.DB {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "task_queue.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestBoltPersistentJob(t *testing.T) {
+	ctx := context.Background()
+	persistent := NewBoltPersistent(newTestBoltDB(t))
+	require.NoError(t, persistent.Ping(ctx))
+
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i, job := range []Job{
+		{ID: "1", TaskName: "sync-user", Status: StatusSuccess.String(), Arguments: `{"user_id":1}`},
+		{ID: "2", TaskName: "sync-user", Status: StatusFailure.String(), Arguments: `{"user_id":2}`, Error: "timeout"},
+		{ID: "3", TaskName: "sync-user", Status: StatusQueueing.String(), Arguments: `{"user_id":3}`},
+		{ID: "4", TaskName: "send-email", Status: StatusRetrying.String(), Arguments: `{"email":"a@b.c"}`, MaxRetry: 3},
+	} {
+		job.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, persistent.SaveJob(ctx, &job))
+	}
+	newJob := Job{TaskName: "send-email", Status: StatusQueueing.String()}
+	require.NoError(t, persistent.SaveJob(ctx, &newJob))
+	assert.NotEmpty(t, newJob.ID, "id is generated for new job")
+
+	t.Run("find and count with filter", func(t *testing.T) {
+		jobs := persistent.FindAllJob(ctx, &Filter{TaskName: "sync-user", Page: 1, Limit: 2})
+		require.Len(t, jobs, 2)
+		assert.Equal(t, []string{"3", "2"}, []string{jobs[0].ID, jobs[1].ID}, "default sort is newest created")
+		jobs = persistent.FindAllJob(ctx, &Filter{TaskName: "sync-user", Page: 2, Limit: 2})
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "1", jobs[0].ID)
+		assert.Empty(t, persistent.FindAllJob(ctx, &Filter{TaskName: "sync-user", Page: 3, Limit: 2}))
+
+		jobs = persistent.FindAllJob(ctx, &Filter{ShowAll: true, Sort: "created_at", TaskNameList: []string{"sync-user", "send-email"}})
+		assert.Len(t, jobs, 5)
+		assert.Equal(t, "1", jobs[0].ID)
+
+		assert.Equal(t, 4, persistent.CountAllJob(ctx, &Filter{ExcludeStatus: []string{StatusSuccess.String()}}))
+		assert.Equal(t, 2, persistent.CountAllJob(ctx, &Filter{Statuses: []string{StatusSuccess.String(), StatusFailure.String()}}))
+		assert.Equal(t, 1, persistent.CountAllJob(ctx, &Filter{Search: candihelper.ToStringPtr("timeout")}))
+		assert.Equal(t, 1, persistent.CountAllJob(ctx, &Filter{Search: candihelper.ToStringPtr("a@b.c")}))
+		assert.Equal(t, 1, persistent.CountAllJob(ctx, &Filter{MaxRetry: candihelper.ToIntPtr(3)}))
+		assert.Equal(t, 2, persistent.CountAllJob(ctx, &Filter{ExcludeTaskNameList: []string{"sync-user"}}))
+		assert.Equal(t, 0, persistent.CountAllJob(ctx, &Filter{JobID: candihelper.ToStringPtr("2"), TaskName: "send-email"}))
+		assert.Equal(t, 2, persistent.CountAllJob(ctx, &Filter{
+			StartDate: createdAt.Add(30 * time.Second).Format(time.RFC3339), EndDate: createdAt.Add(150 * time.Second).Format(time.RFC3339),
+		}))
+	})
+
+	t.Run("update job and retry histories", func(t *testing.T) {
+		startAt := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+		matched, affected, err := persistent.UpdateJob(ctx, &Filter{JobID: candihelper.ToStringPtr("2")},
+			map[string]any{"status": StatusRetrying.String(), "retries": 1},
+			RetryHistory{Status: StatusFailure.String(), Error: "timeout", StartAt: startAt})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), matched)
+		assert.Equal(t, int64(1), affected)
+		_, _, err = persistent.UpdateJob(ctx, &Filter{JobID: candihelper.ToStringPtr("2")}, map[string]any{"retries": 2},
+			RetryHistory{Status: StatusFailure.String(), Error: "connection refused", StartAt: startAt.Add(time.Minute)})
+		require.NoError(t, err)
+
+		job, err := persistent.FindJobByID(ctx, "2", &Filter{Page: 1, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, StatusRetrying.String(), job.Status)
+		assert.Equal(t, 2, job.Retries)
+		require.Len(t, job.RetryHistories, 1)
+		assert.Equal(t, "connection refused", job.RetryHistories[0].Error, "newest retry history first")
+
+		job, err = persistent.FindJobByID(ctx, "2", nil)
+		require.NoError(t, err)
+		assert.Empty(t, job.RetryHistories)
+		assert.Empty(t, persistent.FindAllJob(ctx, &Filter{JobID: candihelper.ToStringPtr("2"), ShowAll: true})[0].RetryHistories)
+
+		matched, _, err = persistent.UpdateJob(ctx, &Filter{TaskName: "unknown"}, map[string]any{"status": StatusStopped.String()})
+		require.NoError(t, err)
+		assert.Zero(t, matched)
+		_, err = persistent.FindJobByID(ctx, "unknown", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("aggregate and summary counter", func(t *testing.T) {
+		summaries := make(map[string]TaskSummary)
+		for _, summary := range persistent.AggregateAllTaskJob(ctx, &Filter{}) {
+			summaries[summary.TaskName] = summary
+		}
+		assert.Equal(t, TaskSummary{ID: "sync-user", TaskName: "sync-user", Success: 1, Retrying: 1, Queueing: 1}, summaries["sync-user"])
+		assert.Equal(t, TaskSummary{ID: "send-email", TaskName: "send-email", Retrying: 1, Queueing: 1}, summaries["send-email"])
+
+		persistent.Summary().IncrementSummary(ctx, "sync-user", map[string]int64{StatusSuccess.String(): 2, StatusQueueing.String(): 1})
+		persistent.Summary().IncrementSummary(ctx, "sync-user", map[string]int64{StatusQueueing.String(): -1})
+		detail := persistent.Summary().FindDetailSummary(ctx, "sync-user")
+		assert.Equal(t, 2, detail.Success)
+		assert.Equal(t, 0, detail.Queueing)
+	})
+
+	t.Run("clean and delete job", func(t *testing.T) {
+		assert.Equal(t, int64(1), persistent.CleanJob(ctx, &Filter{TaskName: "sync-user", Statuses: []string{StatusSuccess.String()}}))
+		assert.Equal(t, 2, persistent.CountAllJob(ctx, &Filter{TaskName: "sync-user"}))
+
+		job, err := persistent.DeleteJob(ctx, "4")
+		require.NoError(t, err)
+		assert.Equal(t, "send-email", job.TaskName)
+		_, err = persistent.DeleteJob(ctx, "4")
+		assert.Error(t, err)
+		assert.Equal(t, 3, persistent.CountAllJob(ctx, &Filter{}))
+	})
+}
+
+func TestBoltPersistentConfigurationAndAuditLog(t *testing.T) {
+	ctx := context.Background()
+	persistent := NewBoltPersistent(newTestBoltDB(t))
+
+	require.NoError(t, persistent.SetConfiguration(&Configuration{Key: "max_client", Name: "Max Client", Value: "10", IsActive: true}))
+	require.NoError(t, persistent.SetConfiguration(&Configuration{Key: "max_client", Name: "Max Client", Value: "20", IsActive: true}))
+	cfg, err := persistent.GetConfiguration("max_client")
+	require.NoError(t, err)
+	assert.Equal(t, "20", cfg.Value)
+	configurations, err := persistent.GetAllConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configurations, 1)
+	_, err = persistent.GetConfiguration("unknown")
+	assert.Error(t, err)
+
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, persistent.SaveAuditLog(ctx, &AuditLog{ID: "a", Username: "admin", Action: "retry_job", CreatedAt: createdAt}))
+	require.NoError(t, persistent.SaveAuditLog(ctx, &AuditLog{ID: "b", Username: "operator", Action: "delete_job", CreatedAt: createdAt.Add(time.Second)}))
+	auditLogs, count, err := persistent.FindAllAuditLog(ctx, &Filter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "b", auditLogs[0].ID, "newest audit log first")
+}
+
+func TestBoltPersistentWithQueueLength(t *testing.T) {
+	ctx := context.Background()
+	db := newTestBoltDB(t)
+	persistent, queue := NewBoltPersistent(db), NewBoltQueue(db)
+
+	for _, id := range []string{"1", "2", "3"} {
+		job := &Job{ID: id, TaskName: "sync-user", Status: StatusQueueing.String()}
+		require.NoError(t, persistent.SaveJob(ctx, job))
+		queue.PushJob(ctx, job)
+	}
+	assert.Equal(t, "1", queue.PopJob(ctx, "sync-user"))
+	assert.Equal(t, int64(3), queue.PushJob(ctx, &Job{ID: "4", TaskName: "sync-user"}), "length counter decremented by pop")
+
+	// clean all job of task (as dashboard clean queue)
+	assert.Equal(t, int64(3), persistent.CleanJob(ctx, &Filter{TaskName: "sync-user"}))
+	queue.Clear(ctx, "sync-user")
+	assert.Equal(t, 0, persistent.CountAllJob(ctx, &Filter{TaskName: "sync-user"}))
+	assert.Empty(t, queue.NextJob(ctx, "sync-user"))
+	assert.Equal(t, int64(1), queue.PushJob(ctx, &Job{ID: "5", TaskName: "sync-user"}), "length counter reset by clear")
+
+	// counter is persisted in bolt file, not in queue instance
+	assert.Equal(t, int64(2), NewBoltQueue(db).PushJob(ctx, &Job{ID: "6", TaskName: "sync-user"}))
+}
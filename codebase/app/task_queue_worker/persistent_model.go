@@ -13,6 +13,7 @@ const (
 	jobSummaryModelName    = "task_queue_worker_job_summaries"
 	jobHistoryModel        = "task_queue_worker_job_histories"
 	configurationModelName = "task_queue_worker_configurations"
+	auditLogModelName      = "task_queue_worker_audit_logs"
 )
 
 // Filter type
@@ -105,6 +106,9 @@ func (s *TaskSummary) ToTaskResolver() (res TaskResolver) {
 		IsHold:         s.IsHold,
 		LoadingMessage: s.LoadingMessage,
 	}
+	if schema, ok := engine.argsSchemas[s.TaskName]; ok {
+		res.ArgsSchema = &schema
+	}
 	res.Detail = s.ToSummaryDetail()
 	return
 }
@@ -182,6 +186,9 @@ type Job struct {
 	MaxProgress     int64          `bson:"max_progress" json:"max_progress"`
 	RetryHistories  []RetryHistory `bson:"retry_histories" json:"retry_histories"`
 
+	// Metadata propagated context (baggage) of job creator, rehydrated to handler context
+	Metadata map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
+
 	direct   bool              `bson:"-" json:"-"`
 	schedule cronexpr.Schedule `bson:"-" json:"-"`
 }
@@ -218,8 +225,9 @@ func (j *Job) IsCronMode() bool {
 }
 
 func (j *Job) ParseNextRunningInterval() (interval time.Duration, err error) {
-	if !j.NextRunningAt.IsZero() && j.NextRunningAt.After(time.Now()) {
-		interval = j.NextRunningAt.Sub(time.Now())
+	currentTime := now()
+	if !j.NextRunningAt.IsZero() && j.NextRunningAt.After(currentTime) {
+		interval = j.NextRunningAt.Sub(currentTime)
 		return
 	}
 	interval, err = time.ParseDuration(j.Interval)
@@ -228,9 +236,9 @@ func (j *Job) ParseNextRunningInterval() (interval time.Duration, err error) {
 		if err != nil {
 			return interval, err
 		}
-		interval = schedule.NextInterval(time.Now())
+		interval = schedule.NextInterval(currentTime)
 	}
-	j.NextRunningAt = time.Now().Add(interval)
+	j.NextRunningAt = currentTime.Add(interval)
 	return interval, nil
 }
 
@@ -244,6 +252,18 @@ type Configuration struct {
 
 type Configurations []Configuration
 
+// AuditLog model, record of dashboard action
+type AuditLog struct {
+	ID         string    `bson:"_id" json:"id"`
+	Username   string    `bson:"username" json:"username"`
+	Action     string    `bson:"action" json:"action"`
+	Target     string    `bson:"target" json:"target"`
+	Permission string    `bson:"permission" json:"permission"`
+	Allowed    bool      `bson:"allowed" json:"allowed"`
+	RemoteAddr string    `bson:"remote_addr" json:"remote_addr"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+}
+
 // ToMap method
 func (c Configurations) ToMap() map[string]string {
 	mp := make(map[string]string, len(c))
@@ -3,6 +3,7 @@ package taskqueueworker
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -638,3 +639,34 @@ func (s *MongoPersistent) SetConfiguration(cfg *Configuration) (err error) {
 	)
 	return
 }
+
+func (s *MongoPersistent) SaveAuditLog(ctx context.Context, auditLog *AuditLog) error {
+	_, err := s.db.Collection(auditLogModelName).InsertOne(ctx, auditLog)
+	return err
+}
+
+func (s *MongoPersistent) FindAllAuditLog(ctx context.Context, filter *Filter) (auditLogs []AuditLog, count int, err error) {
+	query := bson.M{}
+	if filter.Search != nil && *filter.Search != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(*filter.Search), "$options": "i"}
+		query["$or"] = []bson.M{{"username": pattern}, {"action": pattern}, {"target": pattern}}
+	}
+	total, err := s.db.Collection(auditLogModelName).CountDocuments(ctx, query)
+	if err != nil {
+		return auditLogs, count, err
+	}
+
+	findOptions := &options.FindOptions{}
+	findOptions.SetSort(bson.M{"created_at": -1})
+	if !filter.ShowAll {
+		findOptions.SetLimit(int64(filter.Limit))
+		findOptions.SetSkip(int64(filter.CalculateOffset()))
+	}
+	cur, err := s.db.Collection(auditLogModelName).Find(ctx, query, findOptions)
+	if err != nil {
+		return auditLogs, int(total), err
+	}
+	defer cur.Close(ctx)
+	err = cur.All(ctx, &auditLogs)
+	return auditLogs, int(total), err
+}
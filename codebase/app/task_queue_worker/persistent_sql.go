@@ -64,7 +64,7 @@ func (s *SQLPersistent) FindAllJob(ctx context.Context, filter *Filter) (jobs []
 		sort = "DESC"
 	}
 	query := "SELECT " +
-		s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "finished_at", "status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at") +
+		s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "updated_at", "finished_at", "status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at", "metadata") +
 		" FROM " + jobModelName + " " + where + " ORDER BY " + s.formatColumnName(strings.TrimPrefix(filter.Sort, "-")) + " " + sort
 	if !filter.ShowAll {
 		query += fmt.Sprintf(` LIMIT %d OFFSET %d `, filter.Limit, filter.CalculateOffset())
@@ -78,44 +78,48 @@ func (s *SQLPersistent) FindAllJob(ctx context.Context, filter *Filter) (jobs []
 
 	for rows.Next() {
 		var job Job
-		var createdAt string
-		var finishedAt, result, nextRunningAt sql.NullString
+		var createdAt, updatedAt string
+		var finishedAt, result, nextRunningAt, metadata sql.NullString
 		if err := rows.Scan(
-			&job.ID, &job.TaskName, &job.Arguments, &job.Retries, &job.MaxRetry, &job.Interval, &createdAt,
+			&job.ID, &job.TaskName, &job.Arguments, &job.Retries, &job.MaxRetry, &job.Interval, &createdAt, &updatedAt,
 			&finishedAt, &job.Status, &job.Error, &result, &job.TraceID, &job.CurrentProgress, &job.MaxProgress,
-			&nextRunningAt,
+			&nextRunningAt, &metadata,
 		); err != nil {
 			logger.LogE(err.Error())
 			return
 		}
 		job.CreatedAt = s.parseDateString(createdAt).Time
+		job.UpdatedAt = s.parseDateString(updatedAt).Time
 		job.FinishedAt = s.parseDateString(finishedAt.String).Time
 		job.NextRunningAt = s.parseDateString(nextRunningAt.String).Time
 		job.Result = result.String
+		job.Metadata = s.parseMetadata(metadata.String)
 		jobs = append(jobs, job)
 	}
 
 	return
 }
 func (s *SQLPersistent) FindJobByID(ctx context.Context, id string, filterHistory *Filter) (job Job, err error) {
-	var finishedAt, result, nextRunningAt sql.NullString
-	var createdAt string
+	var finishedAt, result, nextRunningAt, metadata sql.NullString
+	var createdAt, updatedAt string
 	err = s.db.QueryRowContext(ctx, `SELECT `+
-		s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "finished_at", "status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at")+
+		s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "updated_at", "finished_at", "status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at", "metadata")+
 		` FROM `+jobModelName+` WHERE id='`+id+`'`).
 		Scan(
-			&job.ID, &job.TaskName, &job.Arguments, &job.Retries, &job.MaxRetry, &job.Interval, &createdAt,
+			&job.ID, &job.TaskName, &job.Arguments, &job.Retries, &job.MaxRetry, &job.Interval, &createdAt, &updatedAt,
 			&finishedAt, &job.Status, &job.Error, &result, &job.TraceID, &job.CurrentProgress, &job.MaxProgress,
-			&nextRunningAt,
+			&nextRunningAt, &metadata,
 		)
 	if err != nil {
 		logger.LogE(err.Error())
 		return job, err
 	}
 	job.CreatedAt = s.parseDateString(createdAt).Time
+	job.UpdatedAt = s.parseDateString(updatedAt).Time
 	job.FinishedAt = s.parseDateString(finishedAt.String).Time
 	job.NextRunningAt = s.parseDateString(nextRunningAt.String).Time
 	job.Result = result.String
+	job.Metadata = s.parseMetadata(metadata.String)
 
 	if filterHistory != nil {
 		query := `SELECT ` + s.formatColumnName("error_stack", "status", "error", "result", "trace_id", "start_at", "end_at") +
@@ -199,9 +203,10 @@ func (s *SQLPersistent) SaveJob(ctx context.Context, job *Job, retryHistories ..
 		args = []any{
 			job.ID, job.TaskName, job.Arguments, job.Retries, job.MaxRetry, job.Interval, s.parseDate(job.CreatedAt), s.parseDate(time.Now()), s.parseDate(job.FinishedAt),
 			job.Status, job.Error, job.Result, job.TraceID, job.CurrentProgress, job.MaxProgress, job.NextRunningAt,
+			s.formatMetadata(job.Metadata),
 		}
 		query = "INSERT INTO " + jobModelName + " (" +
-			s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "updated_at", "finished_at", "status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at") +
+			s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "updated_at", "finished_at", "status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at", "metadata") +
 			") VALUES (" + s.parameterize(len(args)) + ")"
 	} else {
 		args = []any{
@@ -292,20 +297,21 @@ func (s *SQLPersistent) CleanJob(ctx context.Context, filter *Filter) (affectedR
 	return
 }
 func (s *SQLPersistent) DeleteJob(ctx context.Context, id string) (job Job, err error) {
-	var createdAt, finishedAt, result, nextRunningAt sql.NullString
+	var createdAt, finishedAt, result, nextRunningAt, metadata sql.NullString
 	err = s.db.QueryRowContext(ctx, `SELECT `+
 		s.formatColumnName("id", "task_name", "arguments", "retries", "max_retry", "interval", "created_at", "finished_at",
-			"status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at")+
+			"status", "error", "result", "trace_id", "current_progress", "max_progress", "next_running_at", "metadata")+
 		` FROM `+jobModelName+` WHERE id=`+s.parameterize(1), id).
 		Scan(
 			&job.ID, &job.TaskName, &job.Arguments, &job.Retries, &job.MaxRetry, &job.Interval, &createdAt,
 			&finishedAt, &job.Status, &job.Error, &result, &job.TraceID, &job.CurrentProgress, &job.MaxProgress,
-			&nextRunningAt,
+			&nextRunningAt, &metadata,
 		)
 	job.CreatedAt = s.parseDateString(createdAt.String).Time
 	job.FinishedAt = s.parseDateString(finishedAt.String).Time
 	job.NextRunningAt = s.parseDateString(nextRunningAt.String).Time
 	job.Result = result.String
+	job.Metadata = s.parseMetadata(metadata.String)
 	logger.LogIfError(err)
 	_, err = s.db.Exec(`DELETE FROM ` + jobModelName + ` WHERE id='` + id + `'`)
 	logger.LogIfError(err)
@@ -543,3 +549,48 @@ func (s *SQLPersistent) SetConfiguration(cfg *Configuration) (err error) {
 	}
 	return nil
 }
+
+func (s *SQLPersistent) SaveAuditLog(ctx context.Context, auditLog *AuditLog) error {
+	args := []any{
+		auditLog.ID, auditLog.Username, auditLog.Action, auditLog.Target, auditLog.Permission, auditLog.Allowed,
+		auditLog.RemoteAddr, s.parseDate(auditLog.CreatedAt),
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO `+auditLogModelName+` (`+
+		s.formatColumnName("id", "username", "action", "target", "permission", "allowed", "remote_addr", "created_at")+
+		`) VALUES (`+s.parameterize(len(args))+`)`, args...)
+	return err
+}
+
+func (s *SQLPersistent) FindAllAuditLog(ctx context.Context, filter *Filter) (auditLogs []AuditLog, count int, err error) {
+	var where string
+	if filter.Search != nil && *filter.Search != "" {
+		search := s.queryReplacer.Replace(*filter.Search)
+		where = " WHERE " + s.formatColumnName("username") + " LIKE '%" + search + "%' OR " +
+			s.formatColumnName("action") + " LIKE '%" + search + "%' OR " + s.formatColumnName("target") + " LIKE '%" + search + "%'"
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+auditLogModelName+where).Scan(&count); err != nil {
+		return auditLogs, count, err
+	}
+
+	query := "SELECT " + s.formatColumnName("id", "username", "action", "target", "permission", "allowed", "remote_addr", "created_at") +
+		" FROM " + auditLogModelName + where + " ORDER BY " + s.formatColumnName("created_at") + " DESC"
+	if !filter.ShowAll {
+		query += fmt.Sprintf(` LIMIT %d OFFSET %d `, filter.Limit, filter.CalculateOffset())
+	}
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return auditLogs, count, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var auditLog AuditLog
+		var createdAt string
+		if err := rows.Scan(&auditLog.ID, &auditLog.Username, &auditLog.Action, &auditLog.Target, &auditLog.Permission,
+			&auditLog.Allowed, &auditLog.RemoteAddr, &createdAt); err != nil {
+			return auditLogs, count, err
+		}
+		auditLog.CreatedAt = s.parseDateString(createdAt).Time
+		auditLogs = append(auditLogs, auditLog)
+	}
+	return auditLogs, count, rows.Err()
+}
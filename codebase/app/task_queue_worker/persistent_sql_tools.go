@@ -2,6 +2,7 @@ package taskqueueworker
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -99,6 +100,18 @@ func (s *SQLPersistent) initTable(db *sql.DB) {
 				value VARCHAR(255) NOT NULL DEFAULT '',
 				is_active BOOLEAN DEFAULT false
 			);`,
+
+			auditLogModelName: `CREATE TABLE IF NOT EXISTS ` + auditLogModelName + ` (
+				id VARCHAR(255) PRIMARY KEY NOT NULL DEFAULT '',
+				username VARCHAR(255) NOT NULL DEFAULT '',
+				action VARCHAR(255) NOT NULL DEFAULT '',
+				target TEXT NOT NULL DEFAULT '',
+				permission VARCHAR(255) NOT NULL DEFAULT '',
+				allowed BOOLEAN DEFAULT false,
+				remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+			CREATE INDEX IF NOT EXISTS idx_created_at_audit_log ON ` + auditLogModelName + ` (created_at);`,
 		}
 
 	case "mysql":
@@ -149,6 +162,16 @@ func (s *SQLPersistent) initTable(db *sql.DB) {
 				"`name` VARCHAR(255) NOT NULL," +
 				"`value` VARCHAR(255) NOT NULL," +
 				"`is_active` BOOLEAN DEFAULT false) ENGINE=InnoDB DEFAULT CHARSET=utf8 DEFAULT COLLATE utf8_unicode_ci;",
+			auditLogModelName: "CREATE TABLE IF NOT EXISTS " + auditLogModelName + " " +
+				"(`id` VARCHAR(255) PRIMARY KEY NOT NULL," +
+				"`username` VARCHAR(255) NOT NULL," +
+				"`action` VARCHAR(255) NOT NULL," +
+				"`target` TEXT NOT NULL," +
+				"`permission` VARCHAR(255) NOT NULL," +
+				"`allowed` BOOLEAN DEFAULT false," +
+				"`remote_addr` VARCHAR(255) NOT NULL," +
+				"`created_at` DATETIME(3) NOT NULL," +
+				`INDEX (created_at)) ENGINE=InnoDB DEFAULT CHARSET=utf8 DEFAULT COLLATE utf8_unicode_ci;`,
 		}
 	}
 
@@ -167,6 +190,7 @@ func (s *SQLPersistent) initTable(db *sql.DB) {
 		generateAdditionalColumnQuery(s.driverName, jobSummaryModelName, "is_hold", "BOOLEAN"),
 		generateAdditionalColumnQuery(s.driverName, jobSummaryModelName, "hold", "INTEGER"),
 		generateAdditionalColumnQuery(s.driverName, jobModelName, "next_running_at", "TIMESTAMPTZ"),
+		generateAdditionalColumnQuery(s.driverName, jobModelName, "metadata", "TEXT"),
 	}
 	for _, q := range extraQueries {
 		if q.conditionQuery != "" {
@@ -225,6 +249,21 @@ func (s *SQLPersistent) parseDateString(date string) (t sql.NullTime) {
 	return t
 }
 
+func (s *SQLPersistent) parseMetadata(metadata string) (res map[string]string) {
+	if metadata != "" {
+		json.Unmarshal([]byte(metadata), &res)
+	}
+	return res
+}
+
+func (s *SQLPersistent) formatMetadata(metadata map[string]string) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(metadata)
+	return string(b)
+}
+
 func (s *SQLPersistent) parseDate(t time.Time) (res *string) {
 	date := candihelper.ParseTimeToString(t, time.RFC3339Nano)
 	if date == "" {
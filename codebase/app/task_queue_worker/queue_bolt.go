@@ -0,0 +1,127 @@
+package taskqueueworker
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	boltQueueBucket       = "task_queue_worker_queues"
+	boltQueueLengthBucket = "task_queue_worker_queue_lengths"
+)
+
+// boltQueue queue
+type boltQueue struct {
+	db *bolt.DB
+}
+
+// NewBoltQueue init queue using embedded key-value store (bbolt)
+func NewBoltQueue(db *bolt.DB) QueueStorage {
+	if db == nil {
+		panic("Task queue backend require bolt db")
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(boltQueueBucket)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists([]byte(boltQueueLengthBucket))
+		return err
+	}); err != nil {
+		panic("Task Queue Worker: init bolt queue: " + err.Error())
+	}
+	return &boltQueue{db: db}
+}
+
+func (b *boltQueue) PushJob(ctx context.Context, job *Job) (n int64) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket([]byte(boltQueueBucket)).CreateBucketIfNotExists([]byte(job.TaskName))
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := bucket.Put(key, []byte(job.ID)); err != nil {
+			return err
+		}
+		n, err = b.addLength(tx, job.TaskName, bucket, 1)
+		return err
+	})
+	return
+}
+func (b *boltQueue) PopJob(ctx context.Context, taskName string) (jobID string) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(boltQueueBucket)).Bucket([]byte(taskName))
+		if bucket == nil {
+			return nil
+		}
+		cur := bucket.Cursor()
+		k, v := cur.First()
+		if k == nil {
+			return nil
+		}
+		jobID = string(v)
+		if err := cur.Delete(); err != nil {
+			return err
+		}
+		_, err := b.addLength(tx, taskName, bucket, -1)
+		return err
+	})
+	return
+}
+func (b *boltQueue) NextJob(ctx context.Context, taskName string) (jobID string) {
+	b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(boltQueueBucket)).Bucket([]byte(taskName))
+		if bucket == nil {
+			return nil
+		}
+		if _, v := bucket.Cursor().First(); v != nil {
+			jobID = string(v)
+		}
+		return nil
+	})
+	return
+}
+func (b *boltQueue) Clear(ctx context.Context, taskName string) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket([]byte(boltQueueBucket)).DeleteBucket([]byte(taskName))
+		if err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		return tx.Bucket([]byte(boltQueueLengthBucket)).Delete([]byte(taskName))
+	})
+}
+func (b *boltQueue) Ping() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(boltQueueBucket)) == nil {
+			return errors.New("bolt ping: bucket " + boltQueueBucket + " not found")
+		}
+		return nil
+	})
+}
+func (b *boltQueue) Type() string {
+	return "Bolt Embedded Queue"
+}
+
+// addLength add queue length counter of task after queue bucket is modified, counter is initialized once by counting
+// queue bucket if not exist (queue created before length counter)
+func (b *boltQueue) addLength(tx *bolt.Tx, taskName string, queueBucket *bolt.Bucket, delta int64) (int64, error) {
+	lengths := tx.Bucket([]byte(boltQueueLengthBucket))
+	var length int64
+	if v := lengths.Get([]byte(taskName)); v != nil {
+		length = int64(binary.BigEndian.Uint64(v)) + delta
+	} else {
+		queueBucket.ForEach(func(k, v []byte) error {
+			length++
+			return nil
+		})
+	}
+	length = max(length, 0)
+	return length, lengths.Put([]byte(taskName), binary.BigEndian.AppendUint64(nil, uint64(length)))
+}
@@ -0,0 +1,46 @@
+package taskqueueworker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltQueue(t *testing.T) {
+	ctx := context.Background()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "queue.db"), 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// queue created before length counter
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte(boltQueueBucket))
+		if err != nil {
+			return err
+		}
+		bucket, err := root.CreateBucket([]byte("sync-user"))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte{0, 0, 0, 0, 0, 0, 0, 0}, []byte("0"))
+	}))
+
+	queue := NewBoltQueue(db)
+	assert.Equal(t, int64(2), queue.PushJob(ctx, &Job{ID: "1", TaskName: "sync-user"}))
+	assert.Equal(t, int64(3), queue.PushJob(ctx, &Job{ID: "2", TaskName: "sync-user"}))
+	assert.Equal(t, int64(1), queue.PushJob(ctx, &Job{ID: "3", TaskName: "send-email"}))
+
+	assert.Equal(t, "0", queue.NextJob(ctx, "sync-user"))
+	assert.Equal(t, "0", queue.PopJob(ctx, "sync-user"))
+	assert.Equal(t, "1", queue.PopJob(ctx, "sync-user"))
+	assert.Equal(t, int64(2), queue.PushJob(ctx, &Job{ID: "4", TaskName: "sync-user"}))
+
+	queue.Clear(ctx, "sync-user")
+	assert.Empty(t, queue.PopJob(ctx, "sync-user"))
+	assert.Equal(t, int64(1), queue.PushJob(ctx, &Job{ID: "5", TaskName: "sync-user"}))
+	assert.NoError(t, queue.Ping())
+}
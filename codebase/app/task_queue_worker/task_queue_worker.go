@@ -3,6 +3,8 @@ package taskqueueworker
 import (
 	"context"
 	"fmt"
+	"log"
+	"net"
 	"reflect"
 	"strings"
 	"sync"
@@ -10,9 +12,12 @@ import (
 
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils/graceful"
 	"github.com/golangid/candi/codebase/factory"
 	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/contract"
 	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/validator"
 )
 
 type taskQueueWorker struct {
@@ -29,14 +34,21 @@ type taskQueueWorker struct {
 
 	configuration *configurationUsecase
 	subscriber    *subscriber
+	auditLog      AuditLogPersistent
+	jobResult     *jobResultNotifier
 	opt           *option
 
 	registeredTaskWorkerIndex map[string]int
 	runningWorkerIndexTask    map[int]*Task
 	tasks                     []string
+	argsSchemas               taskArgsSchemaStorage
+	argsValidator             *validator.JSONSchemaValidator
 
 	globalSemaphore chan struct{}
 	messagePool     sync.Pool
+	runningJobs     sync.Map // job id -> context.CancelFunc of running job in this runtime
+
+	dashboardListener net.Listener
 }
 
 // NewTaskQueueWorker create new task queue worker
@@ -47,10 +59,17 @@ func NewTaskQueueWorker(service factory.ServiceFactory, opts ...OptionFunc) fact
 		if h := m.WorkerHandler(types.TaskQueue); h != nil {
 			var handlerGroup types.WorkerHandlerGroup
 			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(types.TaskQueue, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
 			for _, handler := range handlerGroup.Handlers {
 				if _, ok := e.registeredTaskWorkerIndex[handler.Pattern]; ok {
 					panic("Task Queue Worker: task \"" + handler.Pattern + "\" has been registered")
 				}
+				if schema, ok := handler.Configs[TaskOptionArgumentsSchema]; ok {
+					if err := e.argsSchemas.Store(handler.Pattern, string(candihelper.ToBytes(schema))); err != nil {
+						panic("Task Queue Worker: task \"" + handler.Pattern + "\" has invalid arguments schema: " + err.Error())
+					}
+				}
 
 				workerIndex := len(e.workerChannels)
 				e.registeredTaskWorkerIndex[handler.Pattern] = workerIndex
@@ -67,6 +86,13 @@ func NewTaskQueueWorker(service factory.ServiceFactory, opts ...OptionFunc) fact
 		}
 	}
 
+	// bind when construct, so dashboard port is claimed before notify graceful restart
+	var err error
+	e.dashboardListener, err = graceful.Listen("tcp", fmt.Sprintf(":%d", e.opt.dashboardPort))
+	if err != nil {
+		log.Panicf("Task Queue Worker Dashboard, Listen TCP Error: %v", err)
+	}
+
 	go e.prepare()
 
 	var protocol string = "http"
@@ -176,6 +202,7 @@ func (t *taskQueueWorker) prepare() {
 func (t *taskQueueWorker) Serve() {
 	// serve graphql api for communication to dashboard
 	go t.serveGraphQLAPI()
+	go t.runStuckJobReaper()
 
 	// run worker
 	for {
@@ -9,8 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
 	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/logger"
 	"github.com/golangid/candi/tracer"
@@ -46,7 +48,7 @@ func (t *taskQueueWorker) triggerTask(workerIndex int) {
 		ctx, task.cancel = context.WithCancel(t.ctx)
 		defer func() {
 			if r := recover(); r != nil {
-				logger.LogRed(fmt.Sprintf("task_queue_worker > panic: %v", r))
+				candiutils.ReportPanic(ctx, "TaskQueueWorker", r, map[string]any{"task_name": task.taskName})
 			}
 
 			t.wg.Done()
@@ -113,6 +115,8 @@ func (t *taskQueueWorker) execJob(ctx context.Context, runningTask *Task) {
 	})
 	t.subscriber.broadcastAllToSubscribers(t.ctx)
 	statusBefore = strings.ToLower(job.Status)
+	t.runningJobs.Store(job.ID, runningTask.cancel)
+	defer t.runningJobs.Delete(job.ID)
 
 	if t.opt.debugMode {
 		log.Printf("\x1b[35;3mTask Queue Worker: executing task '%s' (job id: %s)\x1b[0m", job.TaskName, job.ID)
@@ -128,12 +132,16 @@ func (t *taskQueueWorker) execJob(ctx context.Context, runningTask *Task) {
 	go func(ctx context.Context, job Job) {
 		result := jobResult{}
 
+		ctx = candishared.ExtractBaggage(ctx, job.Metadata)
+		ctx, requestID := candishared.ExtractRequestID(ctx, job.Metadata)
 		trace, ctx := tracer.StartTraceFromHeader(ctx, "TaskQueueWorker", make(map[string]string, 0))
 		defer func() {
 			if r := recover(); r != nil {
 				trace.SetTag("panic", true)
-				result.err = fmt.Errorf("%v", r)
 				result.stackTrace = string(debug.Stack())
+				result.err = candiutils.ReportPanic(ctx, "TaskQueueWorker", r, map[string]any{
+					"job_id": job.ID, "task_name": job.TaskName, "retries": job.Retries,
+				})
 			}
 			eventResultChan <- result
 			close(eventResultChan)
@@ -142,6 +150,7 @@ func (t *taskQueueWorker) execJob(ctx context.Context, runningTask *Task) {
 
 		result.traceID = tracer.GetTraceID(ctx)
 		trace.SetTag("job_id", job.ID)
+		trace.SetTag("request_id", requestID)
 		trace.SetTag("task_name", job.TaskName)
 		trace.SetTag("retries", job.Retries)
 		trace.SetTag("max_retry", job.MaxRetry)
@@ -152,14 +161,20 @@ func (t *taskQueueWorker) execJob(ctx context.Context, runningTask *Task) {
 		eventContext.SetContext(ctx)
 		eventContext.SetWorkerType(string(types.TaskQueue))
 		eventContext.SetHandlerRoute(job.TaskName)
-		eventContext.SetHeader(map[string]string{
+		header := map[string]string{
 			HeaderRetries:         strconv.Itoa(job.Retries),
 			HeaderMaxRetries:      strconv.Itoa(job.MaxRetry),
 			HeaderInterval:        job.Interval,
 			HeaderCurrentProgress: strconv.FormatInt(job.CurrentProgress, 10),
 			HeaderMaxProgress:     strconv.FormatInt(job.MaxProgress, 10),
-		})
+		}
+		for key, value := range job.Metadata {
+			header[key] = value
+		}
+		header[candihelper.HeaderXRequestID] = requestID
+		eventContext.SetHeader(header)
 		eventContext.SetKey(job.ID)
+		eventContext.SetHeartbeatFunc(t.heartbeatFunc(job.ID))
 		eventContext.WriteString(job.Arguments)
 
 		for i, h := range selectedHandler.HandlerFuncs {
@@ -230,6 +245,22 @@ func (t *taskQueueWorker) execJob(ctx context.Context, runningTask *Task) {
 			} else {
 				logger.LogRed("TaskQueueWorker: Still error for task '" + job.TaskName + "' (job id: " + job.ID + ")")
 			}
+
+		default:
+			// retry error explicitly classified as transient or throttled (candierrors.Transient, candierrors.Throttled, or registered classifier)
+			class, ok := candierrors.Classify(eventResult.err)
+			if !ok || (class != candierrors.ClassTransient && class != candierrors.ClassThrottled) {
+				break
+			}
+			if job.Retries < job.MaxRetry {
+				if retryAfter := candierrors.RetryAfter(eventResult.err); retryAfter > 0 {
+					job.Interval = retryAfter.String()
+				}
+				job.Status = string(StatusQueueing)
+				t.opt.queue.PushJob(ctx, &job)
+			} else {
+				logger.LogRed("TaskQueueWorker: Still error for task '" + job.TaskName + "' (job id: " + job.ID + ")")
+			}
 		}
 	}
 
@@ -286,6 +317,7 @@ FINISH:
 	}
 	t.opt.persistent.Summary().IncrementSummary(ctx, job.TaskName, incr)
 	t.subscriber.broadcastAllToSubscribers(t.ctx)
+	t.jobResult.notify(&job)
 }
 
 func (t *taskQueueWorker) getLockKey(jobID string) string {
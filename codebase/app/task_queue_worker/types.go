@@ -75,4 +75,10 @@ const (
 
 	// TaskOptionDeleteJobAfterSuccess const
 	TaskOptionDeleteJobAfterSuccess = "delAfterSuccess"
+	// TaskOptionArgumentsSchema const, json schema (string, []byte, or map) of job arguments,
+	// used for validate arguments when add job and render form for submit job in dashboard
+	TaskOptionArgumentsSchema = "argsSchema"
+	// TaskOptionVisibilityTimeout const, heartbeat timeout (time.Duration) of running job in task,
+	// override SetJobVisibilityTimeout option
+	TaskOptionVisibilityTimeout = "visibilityTimeout"
 )
@@ -0,0 +1,114 @@
+package webhookreceiver
+
+import (
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+type (
+	option struct {
+		httpPort          uint16
+		rootPath          string
+		maxBodySize       int64
+		debugMode         bool
+		locker            interfaces.Locker
+		dedupTTL          time.Duration
+		processingLockTTL time.Duration
+		publisher         interfaces.Publisher
+		topicPrefix       string
+		providers         map[string]Provider
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+func getDefaultOption(service factory.ServiceFactory) option {
+	opt := option{
+		httpPort:          8090,
+		rootPath:          "/webhooks",
+		maxBodySize:       1 << 20,
+		debugMode:         true,
+		dedupTTL:          24 * time.Hour,
+		processingLockTTL: 5 * time.Minute,
+		providers:         make(map[string]Provider),
+		locker:            &candiutils.NoopLocker{},
+	}
+	if deps := service.GetDependency(); deps != nil {
+		if redisPool := deps.GetRedisPool(); redisPool != nil {
+			opt.locker = candiutils.NewRedisLocker(redisPool.WritePool())
+		} else if boltDB := deps.GetBoltDatabase(); boltDB != nil {
+			opt.locker = candiutils.NewBoltLocker(boltDB.DB())
+		}
+	}
+	return opt
+}
+
+// SetHTTPPort option func
+func SetHTTPPort(port uint16) OptionFunc {
+	return func(o *option) {
+		o.httpPort = port
+	}
+}
+
+// SetRootPath option func, default "/webhooks" (provider route is POST /webhooks/{route})
+func SetRootPath(rootPath string) OptionFunc {
+	return func(o *option) {
+		o.rootPath = rootPath
+	}
+}
+
+// SetMaxBodySize option func, default 1MB
+func SetMaxBodySize(maxBodySize int64) OptionFunc {
+	return func(o *option) {
+		o.maxBodySize = maxBodySize
+	}
+}
+
+// SetDebugMode option func
+func SetDebugMode(debugMode bool) OptionFunc {
+	return func(o *option) {
+		o.debugMode = debugMode
+	}
+}
+
+// SetLocker option func, locker for deduplication by event id, default redis locker if redis dependency
+// exist (deduplication is disabled without redis)
+func SetLocker(locker interfaces.Locker) OptionFunc {
+	return func(o *option) {
+		o.locker = locker
+	}
+}
+
+// SetDedupTTL option func, how long successfully processed event id is remembered for deduplication, default 24 hours
+func SetDedupTTL(ttl time.Duration) OptionFunc {
+	return func(o *option) {
+		o.dedupTTL = ttl
+	}
+}
+
+// SetProcessingLockTTL option func, max time event id is locked while processed (delivery of same event id
+// respond 409 meanwhile), lock is released when processing done, default 5 minutes
+func SetProcessingLockTTL(ttl time.Duration) OptionFunc {
+	return func(o *option) {
+		o.processingLockTTL = ttl
+	}
+}
+
+// SetPublisher option func, publish verified event to broker with topic "<topicPrefix><route>",
+// key is event id and header "event_type" is event type
+func SetPublisher(publisher interfaces.Publisher, topicPrefix string) OptionFunc {
+	return func(o *option) {
+		o.publisher, o.topicPrefix = publisher, topicPrefix
+	}
+}
+
+// AddProvider option func, receive webhook at POST {rootPath}/{route} verified by provider
+func AddProvider(route string, provider Provider) OptionFunc {
+	return func(o *option) {
+		o.providers[route] = provider
+	}
+}
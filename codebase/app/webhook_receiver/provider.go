@@ -0,0 +1,169 @@
+package webhookreceiver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/webhook"
+)
+
+var (
+	// ErrInvalidSignature signature of webhook request is invalid
+	ErrInvalidSignature = errors.New("webhook receiver: invalid signature")
+	// ErrExpiredTimestamp timestamp of webhook request is outside tolerance
+	ErrExpiredTimestamp = errors.New("webhook receiver: timestamp outside tolerance")
+	// ErrInvalidCloudEvent request of CloudEvents provider is not valid CloudEvents
+	ErrInvalidCloudEvent = errors.New("webhook receiver: request is not valid cloudevents")
+)
+
+type (
+	// Event verified inbound webhook event
+	Event struct {
+		// ID unique event id from provider, used for deduplication (empty for no deduplication)
+		ID      string
+		Type    string
+		Payload []byte
+		// CloudEvent context attributes if webhook is CloudEvents (binary or structured mode), ID and Type is
+		// the "id" and "type" attribute and Payload is the event data
+		CloudEvent *candishared.CloudEvent
+	}
+
+	// Provider verify inbound webhook request and extract event
+	Provider interface {
+		Verify(req *http.Request, body []byte) (*Event, error)
+	}
+
+	// ProviderFunc adapter of function as Provider
+	ProviderFunc func(req *http.Request, body []byte) (*Event, error)
+)
+
+// Verify implement Provider
+func (f ProviderFunc) Verify(req *http.Request, body []byte) (*Event, error) {
+	return f(req, body)
+}
+
+// NewStripeProvider verify "Stripe-Signature" header (t=<timestamp>,v1=<hex HMAC-SHA256 of timestamp.body>)
+// with endpoint signing secret, tolerance is maximum age of timestamp (zero for no check)
+func NewStripeProvider(secret string, tolerance time.Duration) Provider {
+	return ProviderFunc(func(req *http.Request, body []byte) (*Event, error) {
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(req.Header.Get("Stripe-Signature"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || len(signatures) == 0 {
+			return nil, ErrInvalidSignature
+		}
+		if tolerance > 0 && time.Since(time.Unix(ts, 0)).Abs() > tolerance {
+			return nil, ErrExpiredTimestamp
+		}
+		expected := hmacSHA256(secret, []byte(timestamp+"."), body)
+		for _, signature := range signatures {
+			if hmac.Equal([]byte(signature), []byte(expected)) {
+				var payload struct {
+					ID   string `json:"id"`
+					Type string `json:"type"`
+				}
+				json.Unmarshal(body, &payload)
+				return &Event{ID: payload.ID, Type: payload.Type, Payload: body}, nil
+			}
+		}
+		return nil, ErrInvalidSignature
+	})
+}
+
+// NewGitHubProvider verify "X-Hub-Signature-256" header (sha256=<hex HMAC-SHA256 of body>) with webhook secret,
+// event id from "X-GitHub-Delivery" and event type from "X-GitHub-Event" header
+func NewGitHubProvider(secret string) Provider {
+	return ProviderFunc(func(req *http.Request, body []byte) (*Event, error) {
+		if !hmac.Equal([]byte(req.Header.Get("X-Hub-Signature-256")), []byte("sha256="+hmacSHA256(secret, body))) {
+			return nil, ErrInvalidSignature
+		}
+		return &Event{ID: req.Header.Get("X-GitHub-Delivery"), Type: req.Header.Get("X-GitHub-Event"), Payload: body}, nil
+	})
+}
+
+// NewMidtransProvider verify "signature_key" field of notification body (hex SHA512 of
+// order_id+status_code+gross_amount+server key), event type is transaction status and event id is
+// "<order_id>:<transaction_status>" so each status change of order is processed once
+func NewMidtransProvider(serverKey string) Provider {
+	return ProviderFunc(func(req *http.Request, body []byte) (*Event, error) {
+		var payload struct {
+			OrderID           string `json:"order_id"`
+			StatusCode        string `json:"status_code"`
+			GrossAmount       string `json:"gross_amount"`
+			SignatureKey      string `json:"signature_key"`
+			TransactionStatus string `json:"transaction_status"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, ErrInvalidSignature
+		}
+		sum := sha512.Sum512([]byte(payload.OrderID + payload.StatusCode + payload.GrossAmount + serverKey))
+		if !hmac.Equal([]byte(payload.SignatureKey), []byte(hex.EncodeToString(sum[:]))) {
+			return nil, ErrInvalidSignature
+		}
+		return &Event{ID: payload.OrderID + ":" + payload.TransactionStatus, Type: payload.TransactionStatus, Payload: body}, nil
+	})
+}
+
+// NewSignatureProvider verify webhook sent by candi webhook package (see webhook.Sign), event id from
+// webhook.HeaderID and event type from webhook.HeaderEvent header
+func NewSignatureProvider(secret string, tolerance time.Duration) Provider {
+	return ProviderFunc(func(req *http.Request, body []byte) (*Event, error) {
+		if !webhook.VerifySignature(secret, req.Header, body, tolerance) {
+			return nil, ErrInvalidSignature
+		}
+		return &Event{ID: req.Header.Get(webhook.HeaderID), Type: req.Header.Get(webhook.HeaderEvent), Payload: body}, nil
+	})
+}
+
+// NewCloudEventsProvider receive CloudEvents webhook (binary mode "ce-*" header or structured mode
+// "application/cloudevents+json" body), verify is optional authentication of request (nil for no verification)
+func NewCloudEventsProvider(verify func(req *http.Request, body []byte) error) Provider {
+	return ProviderFunc(func(req *http.Request, body []byte) (*Event, error) {
+		if verify != nil {
+			if err := verify(req, body); err != nil {
+				return nil, err
+			}
+		}
+		event := &Event{Payload: body}
+		if !event.applyCloudEvent(req.Header) {
+			return nil, ErrInvalidCloudEvent
+		}
+		return event, nil
+	})
+}
+
+// applyCloudEvent set event id, type and payload from CloudEvents attributes, false if payload is not CloudEvents
+func (e *Event) applyCloudEvent(header http.Header) bool {
+	cloudEvent, data, ok := candishared.ParseCloudEventHTTP(header, e.Payload)
+	if !ok {
+		return false
+	}
+	e.ID, e.Type, e.Payload, e.CloudEvent = cloudEvent.ID, cloudEvent.Type, data, cloudEvent
+	return true
+}
+
+func hmacSHA256(secret string, contents ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, content := range contents {
+		mac.Write(content)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
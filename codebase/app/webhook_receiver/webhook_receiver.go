@@ -0,0 +1,220 @@
+package webhookreceiver
+
+// Inbound webhook receiver worker codebase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/candiutils/graceful"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/contract"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"github.com/golangid/candi/wrapper"
+)
+
+type webhookReceiver struct {
+	opt        option
+	service    factory.ServiceFactory
+	handlers   map[string]types.WorkerHandler
+	httpServer *http.Server
+	listener   net.Listener
+}
+
+// NewWorker create inbound webhook receiver, each provider route receive webhook at POST {rootPath}/{route}.
+// Verified event is passed to module worker handler with pattern "{route}" (all event) or "{route}:{event type}"
+// (CloudEvents webhook is routed and deduplicated by "type" and "id" attribute),
+// then published to broker if publisher is set. Handler error respond 500 so provider will retry the webhook,
+// delivery of event id still being processed respond 409 and event id is remembered only after success
+func NewWorker(service factory.ServiceFactory, opts ...OptionFunc) factory.AppServerFactory {
+	receiver := &webhookReceiver{
+		service:  service,
+		opt:      getDefaultOption(service),
+		handlers: make(map[string]types.WorkerHandler),
+	}
+	for _, opt := range opts {
+		opt(&receiver.opt)
+	}
+	receiver.opt.rootPath = "/" + strings.Trim(receiver.opt.rootPath, "/")
+
+	for _, m := range service.GetModules() {
+		if h := m.WorkerHandler(types.WebhookReceiver); h != nil {
+			var handlerGroup types.WorkerHandlerGroup
+			h.MountHandlers(&handlerGroup)
+			factory.ApplyWorkerMatrix(types.WebhookReceiver, &handlerGroup)
+			contract.ApplyStrictMode(&handlerGroup)
+			for _, handler := range handlerGroup.Handlers {
+				route, _, _ := strings.Cut(handler.Pattern, ":")
+				if _, ok := receiver.opt.providers[route]; !ok {
+					panic(fmt.Sprintf(`webhook receiver: provider for route "%s" is not registered`, route))
+				}
+				logger.LogYellow(fmt.Sprintf(`[WEBHOOK-RECEIVER] (route): %-15s  --> (module): "%s"`, `"`+handler.Pattern+`"`, m.Name()))
+				receiver.handlers[handler.Pattern] = handler
+			}
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(receiver.opt.rootPath+"/", receiver)
+	receiver.httpServer = &http.Server{
+		Addr:              fmt.Sprintf(":%d", receiver.opt.httpPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// bind when construct, so port is ready to accept before notify graceful restart
+	var err error
+	receiver.listener, err = graceful.Listen("tcp", receiver.httpServer.Addr)
+	if err != nil {
+		log.Panicf("Webhook Receiver TCP Listener: Unexpected Error: %v", err)
+	}
+	fmt.Printf("\x1b[34;1m⇨ Webhook receiver running with %d providers on port :%d\x1b[0m\n\n", len(receiver.opt.providers), receiver.opt.httpPort)
+	return receiver
+}
+
+func (w *webhookReceiver) Serve() {
+	if err := w.httpServer.Serve(w.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Panicf("Webhook Receiver: Unexpected Error: %v", err)
+	}
+}
+
+func (w *webhookReceiver) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mStopping Webhook Receiver:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), strings.Repeat(" ", 20))
+	}()
+	w.httpServer.Shutdown(ctx)
+	if w.listener != nil {
+		w.listener.Close()
+	}
+}
+
+func (w *webhookReceiver) Name() string {
+	return string(types.WebhookReceiver)
+}
+
+// ServeHTTP receive webhook of provider route
+func (w *webhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(rw)
+		return
+	}
+	route := strings.Trim(strings.TrimPrefix(req.URL.Path, w.opt.rootPath), "/")
+	provider, ok := w.opt.providers[route]
+	if !ok {
+		wrapper.NewHTTPResponse(http.StatusNotFound, "Webhook route not found").JSON(rw)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, w.opt.maxBodySize))
+	if err != nil {
+		wrapper.NewHTTPResponse(http.StatusRequestEntityTooLarge, "Failed read request body", err).JSON(rw)
+		return
+	}
+	event, err := provider.Verify(req, body)
+	if err != nil {
+		wrapper.NewHTTPResponse(http.StatusUnauthorized, err.Error()).JSON(rw)
+		return
+	}
+	if event.CloudEvent == nil {
+		// verified webhook of any provider may be CloudEvents, deduplicate and route by its id and type
+		event.applyCloudEvent(req.Header)
+	}
+
+	var doneKey string
+	if event.ID != "" {
+		lockKey := fmt.Sprintf("%s:webhook-receiver:%s:%s", w.service.Name(), route, event.ID)
+		if w.opt.locker.IsLockedTTL(lockKey, w.opt.processingLockTTL) {
+			// respond non-2xx so provider retry the delivery, first delivery may still fail
+			wrapper.NewHTTPResponse(http.StatusConflict, "Event "+event.ID+" is being processed").JSON(rw)
+			return
+		}
+		defer w.opt.locker.Unlock(lockKey)
+
+		doneKey = lockKey + ":done"
+		if w.opt.locker.HasBeenLocked(doneKey) {
+			wrapper.NewHTTPResponse(http.StatusOK, "Duplicate event "+event.ID).JSON(rw)
+			return
+		}
+	}
+
+	if err = w.processEvent(req.Context(), route, event); err != nil {
+		wrapper.NewHTTPResponse(http.StatusInternalServerError, "Failed process event", err).JSON(rw)
+		return
+	}
+	if doneKey != "" {
+		w.opt.locker.IsLockedTTL(doneKey, w.opt.dedupTTL)
+	}
+	wrapper.NewHTTPResponse(http.StatusOK, "Success").JSON(rw)
+}
+
+func (w *webhookReceiver) processEvent(ctx context.Context, route string, event *Event) (err error) {
+	handler, ok := w.handlers[route+":"+event.Type]
+	if !ok {
+		handler, ok = w.handlers[route]
+	}
+	if handler.DisableTrace {
+		ctx = tracer.SkipTraceContext(ctx)
+	}
+
+	trace, ctx := tracer.StartTraceWithContext(ctx, "WebhookReceiver")
+	defer func() {
+		if r := recover(); r != nil {
+			trace.SetTag("panic", true)
+			err = candiutils.ReportPanic(ctx, "WebhookReceiver", r, map[string]any{
+				"route": route, "event_id": event.ID,
+			})
+		}
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+	trace.SetTag("route", route)
+	trace.SetTag("event_id", event.ID)
+	trace.SetTag("event_type", event.Type)
+	trace.Log("payload", event.Payload)
+
+	if w.opt.debugMode {
+		log.Printf("\x1b[35;3mWebhook Receiver: executing event '%s' from route '%s'\x1b[0m", event.Type, route)
+	}
+
+	if ok {
+		eventContext := candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, len(event.Payload))))
+		eventContext.SetContext(ctx)
+		eventContext.SetWorkerType(string(types.WebhookReceiver))
+		eventContext.SetHandlerRoute(handler.Pattern)
+		eventContext.SetKey(event.ID)
+		eventContext.SetCloudEvent(event.CloudEvent)
+		eventContext.SetHeader(map[string]string{
+			"route": route, "event_id": event.ID, "event_type": event.Type,
+		})
+		eventContext.Write(event.Payload)
+		var errs []error
+		for _, handlerFunc := range handler.HandlerFuncs {
+			if err := handlerFunc(eventContext); err != nil {
+				eventContext.SetError(err)
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+	if w.opt.publisher != nil {
+		return w.opt.publisher.PublishMessage(ctx, &candishared.PublisherArgument{
+			Topic: w.opt.topicPrefix + route, Key: event.ID, Message: event.Payload,
+			Header: map[string]any{"event_type": event.Type}, CloudEvent: event.CloudEvent,
+		})
+	}
+	return nil
+}
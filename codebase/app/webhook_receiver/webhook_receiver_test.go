@@ -0,0 +1,195 @@
+package webhookreceiver
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	"github.com/golangid/candi/testkit"
+	"github.com/golangid/candi/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+type workerHandler func(group *types.WorkerHandlerGroup)
+
+func (w workerHandler) MountHandlers(group *types.WorkerHandlerGroup) { w(group) }
+
+func TestProviders(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"charge.succeeded"}`)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Stripe-Signature", "t="+ts+",v1=invalid,v1="+hmacSHA256("stripe", []byte(ts+"."), body))
+	event, err := NewStripeProvider("stripe", time.Minute).Verify(req, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, "charge.succeeded", event.Type)
+	_, err = NewStripeProvider("other", time.Minute).Verify(req, body)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hmacSHA256("github", body))
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	req.Header.Set("X-GitHub-Event", "push")
+	event, err = NewGitHubProvider("github").Verify(req, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "delivery-1", event.ID)
+	assert.Equal(t, "push", event.Type)
+
+	sum := sha512.Sum512([]byte("order-1" + "200" + "10000.00" + "server-key"))
+	midtransBody := []byte(`{"order_id":"order-1","status_code":"200","gross_amount":"10000.00","transaction_status":"settlement","signature_key":"` +
+		hex.EncodeToString(sum[:]) + `"}`)
+	event, err = NewMidtransProvider("server-key").Verify(req, midtransBody)
+	assert.NoError(t, err)
+	assert.Equal(t, "order-1:settlement", event.ID)
+	_, err = NewMidtransProvider("other").Verify(req, midtransBody)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(webhook.HeaderTimestamp, ts)
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign("secret", time.Now().Unix(), body))
+	req.Header.Set(webhook.HeaderID, "delivery-2")
+	event, err = NewSignatureProvider("secret", time.Minute).Verify(req, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "delivery-2", event.ID)
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	var received []string
+	failNext := true
+	module := &mockfactory.ModuleFactory{}
+	module.On("Name").Return(types.Module("payment"))
+	module.On("WorkerHandler", types.WebhookReceiver).Return(workerHandler(func(group *types.WorkerHandlerGroup) {
+		group.Add("github:push", func(eventContext *candishared.EventContext) error {
+			if failNext {
+				failNext = false
+				return errors.New("failed")
+			}
+			received = append(received, eventContext.Key()+"/"+eventContext.Header()["event_type"])
+			return nil
+		})
+	}))
+	service := &mockfactory.ServiceFactory{}
+	service.On("GetDependency").Return(nil)
+	service.On("Name").Return(types.Service("test"))
+	service.On("GetModules").Return([]factory.ModuleFactory{module})
+
+	bk := testkit.NewBroker(types.Kafka)
+	receiver := NewWorker(service, AddProvider("github", NewGitHubProvider("secret")), SetLocker(testkit.NewLocker()),
+		SetPublisher(bk, "webhook-"), SetDebugMode(false), SetHTTPPort(0)).(http.Handler)
+
+	send := func(eventType, signature string) int {
+		return sendGitHubEvent(receiver, "delivery-"+eventType, eventType, signature)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("push", "invalid"))
+	assert.Equal(t, http.StatusInternalServerError, send("push", "secret"))
+	assert.Equal(t, http.StatusOK, send("push", "secret"))
+	assert.Equal(t, http.StatusOK, send("push", "secret")) // duplicate
+	assert.Equal(t, http.StatusOK, send("issues", "secret"))
+	assert.Equal(t, []string{"delivery-push/push"}, received)
+	assert.Len(t, bk.Messages("webhook-github"), 2)
+
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWebhookReceiverConcurrentDuplicate(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int64
+	module := &mockfactory.ModuleFactory{}
+	module.On("Name").Return(types.Module("payment"))
+	module.On("WorkerHandler", types.WebhookReceiver).Return(workerHandler(func(group *types.WorkerHandlerGroup) {
+		group.Add("github", func(eventContext *candishared.EventContext) error {
+			if calls.Add(1) == 1 {
+				close(started)
+				<-release
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}))
+	service := &mockfactory.ServiceFactory{}
+	service.On("GetDependency").Return(nil)
+	service.On("Name").Return(types.Service("test"))
+	service.On("GetModules").Return([]factory.ModuleFactory{module})
+
+	receiver := NewWorker(service, AddProvider("github", NewGitHubProvider("secret")), SetLocker(testkit.NewLocker()),
+		SetDebugMode(false), SetHTTPPort(0)).(http.Handler)
+
+	firstDelivery := make(chan int)
+	go func() { firstDelivery <- sendGitHubEvent(receiver, "delivery-1", "push", "secret") }()
+	<-started
+	assert.Equal(t, http.StatusConflict, sendGitHubEvent(receiver, "delivery-1", "push", "secret"),
+		"retry while first delivery is running must not be acknowledged")
+	close(release)
+	assert.Equal(t, http.StatusInternalServerError, <-firstDelivery)
+
+	assert.Equal(t, http.StatusOK, sendGitHubEvent(receiver, "delivery-1", "push", "secret"), "event is not lost")
+	assert.Equal(t, http.StatusOK, sendGitHubEvent(receiver, "delivery-1", "push", "secret"))
+	assert.Equal(t, int64(2), calls.Load(), "processed event is deduplicated")
+}
+
+func sendGitHubEvent(receiver http.Handler, deliveryID, eventType, signature string) int {
+	body := `{"ref":"main"}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hmacSHA256(signature, []byte(body)))
+	req.Header.Set("X-GitHub-Delivery", deliveryID)
+	req.Header.Set("X-GitHub-Event", eventType)
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWebhookReceiverCloudEvents(t *testing.T) {
+	var received []string
+	module := &mockfactory.ModuleFactory{}
+	module.On("Name").Return(types.Module("order"))
+	module.On("WorkerHandler", types.WebhookReceiver).Return(workerHandler(func(group *types.WorkerHandlerGroup) {
+		group.Add("events:order.created", func(eventContext *candishared.EventContext) error {
+			received = append(received, eventContext.Key()+"/"+eventContext.CloudEvent().Source+"/"+string(eventContext.Message()))
+			return nil
+		})
+	}))
+	service := &mockfactory.ServiceFactory{}
+	service.On("GetDependency").Return(nil)
+	service.On("Name").Return(types.Service("test"))
+	service.On("GetModules").Return([]factory.ModuleFactory{module})
+
+	receiver := NewWorker(service, AddProvider("events", NewCloudEventsProvider(nil)), SetLocker(testkit.NewLocker()),
+		SetDebugMode(false), SetHTTPPort(0)).(http.Handler)
+
+	send := func(header map[string]string, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/events", strings.NewReader(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	binaryHeader := map[string]string{"ce-id": "evt-1", "ce-source": "checkout", "ce-type": "order.created",
+		"ce-specversion": "1.0", "Content-Type": "application/json"}
+	assert.Equal(t, http.StatusOK, send(binaryHeader, `{"order_id":"1"}`))
+	assert.Equal(t, http.StatusOK, send(binaryHeader, `{"order_id":"1"}`)) // duplicate ce-id
+
+	structured := `{"specversion":"1.0","id":"evt-2","source":"checkout","type":"order.created","data":{"order_id":"2"}}`
+	assert.Equal(t, http.StatusOK, send(map[string]string{"Content-Type": candishared.CloudEventsContentType}, structured))
+	assert.Equal(t, http.StatusUnauthorized, send(map[string]string{"Content-Type": "application/json"}, `{"order_id":"3"}`),
+		"request which is not CloudEvents is rejected")
+
+	assert.Equal(t, []string{`evt-1/checkout/{"order_id":"1"}`, `evt-2/checkout/{"order_id":"2"}`}, received)
+}
@@ -1,8 +1,12 @@
 package appfactory
 
 import (
+	"fmt"
+
 	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
 )
 
 /*
@@ -31,27 +35,44 @@ USE_TASK_QUEUE_WORKER=[bool]
 USE_POSTGRES_LISTENER_WORKER=[bool]
 
 USE_RABBITMQ_CONSUMER=[bool] # event driven handler and dynamic scheduler
+
+USE_MONGO_CHANGE_STREAM_WORKER=[bool]
+
+USE_POSTGRES_NOTIFY_WORKER=[bool]
+
+USE_SEARCH_INDEXER=[bool] # kafka topic projection to search engine
+
+Worker type or specific handler can be disabled per environment with WORKER_MATRIX_FILE (see factory.WorkerMatrix)
 */
 func NewAppFromEnvironmentConfig(service factory.ServiceFactory) (apps []factory.AppServerFactory) {
 
-	if env.BaseEnv().UseKafkaConsumer {
+	if env.BaseEnv().UseKafkaConsumer && !isWorkerDisabled(types.Kafka) {
 		apps = append(apps, SetupKafkaWorker(service))
 	}
-	if env.BaseEnv().UseCronScheduler {
+	if env.BaseEnv().UseCronScheduler && !isWorkerDisabled(types.Scheduler) {
 		apps = append(apps, SetupCronWorker(service))
 	}
-	if env.BaseEnv().UseTaskQueueWorker {
+	if env.BaseEnv().UseTaskQueueWorker && !isWorkerDisabled(types.TaskQueue) {
 		apps = append(apps, SetupTaskQueueWorker(service))
 	}
-	if env.BaseEnv().UseRedisSubscriber {
+	if env.BaseEnv().UseRedisSubscriber && !isWorkerDisabled(types.RedisSubscriber) {
 		apps = append(apps, SetupRedisWorker(service))
 	}
-	if env.BaseEnv().UsePostgresListenerWorker {
+	if env.BaseEnv().UsePostgresListenerWorker && !isWorkerDisabled(types.PostgresListener) {
 		apps = append(apps, SetupPostgresWorker(service))
 	}
-	if env.BaseEnv().UseRabbitMQWorker {
+	if env.BaseEnv().UseRabbitMQWorker && !isWorkerDisabled(types.RabbitMQ) {
 		apps = append(apps, SetupRabbitMQWorker(service))
 	}
+	if env.BaseEnv().UseMongoChangeStreamWorker && !isWorkerDisabled(types.MongoChangeStream) {
+		apps = append(apps, SetupMongoWorker(service))
+	}
+	if env.BaseEnv().UsePostgresNotifyWorker && !isWorkerDisabled(types.PostgresNotify) {
+		apps = append(apps, SetupPostgresNotifyWorker(service))
+	}
+	if env.BaseEnv().UseSearchIndexer && !isWorkerDisabled(types.SearchIndexer) {
+		apps = append(apps, SetupSearchIndexer(service))
+	}
 
 	if env.BaseEnv().UseREST {
 		apps = append(apps, SetupRESTServer(service))
@@ -65,3 +86,11 @@ func NewAppFromEnvironmentConfig(service factory.ServiceFactory) (apps []factory
 
 	return
 }
+
+func isWorkerDisabled(workerType types.Worker) bool {
+	if factory.IsWorkerDisabled(workerType) {
+		logger.LogRed(fmt.Sprintf(`[WORKER-MATRIX] %s worker is disabled in "%s" environment`, workerType, env.BaseEnv().Environment))
+		return true
+	}
+	return false
+}
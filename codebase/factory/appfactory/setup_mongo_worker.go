@@ -0,0 +1,16 @@
+package appfactory
+
+import (
+	mongoworker "github.com/golangid/candi/codebase/app/mongo_worker"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/config/env"
+)
+
+// SetupMongoWorker setup mongo change stream worker with default config
+func SetupMongoWorker(service factory.ServiceFactory, opts ...mongoworker.OptionFunc) factory.AppServerFactory {
+	mongoOptions := []mongoworker.OptionFunc{
+		mongoworker.SetDebugMode(env.BaseEnv().DebugMode),
+	}
+	mongoOptions = append(mongoOptions, opts...)
+	return mongoworker.NewWorker(service, mongoOptions...)
+}
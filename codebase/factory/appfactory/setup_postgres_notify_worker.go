@@ -0,0 +1,16 @@
+package appfactory
+
+import (
+	postgresnotifyworker "github.com/golangid/candi/codebase/app/postgres_notify_worker"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/config/env"
+)
+
+// SetupPostgresNotifyWorker setup postgres LISTEN/NOTIFY worker with default config
+func SetupPostgresNotifyWorker(service factory.ServiceFactory, opts ...postgresnotifyworker.OptionFunc) factory.AppServerFactory {
+	notifyOptions := []postgresnotifyworker.OptionFunc{
+		postgresnotifyworker.SetDebugMode(env.BaseEnv().DebugMode),
+	}
+	notifyOptions = append(notifyOptions, opts...)
+	return postgresnotifyworker.NewWorker(service, notifyOptions...)
+}
@@ -0,0 +1,17 @@
+package appfactory
+
+import (
+	searchindexer "github.com/golangid/candi/codebase/app/search_indexer"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/config/env"
+)
+
+// SetupSearchIndexer setup search indexer with default config, consume from kafka broker of dependency
+func SetupSearchIndexer(service factory.ServiceFactory, opts ...searchindexer.OptionFunc) factory.AppServerFactory {
+	indexerOpts := []searchindexer.OptionFunc{
+		searchindexer.SetDebugMode(env.BaseEnv().DebugMode),
+	}
+	indexerOpts = append(indexerOpts, opts...)
+	return searchindexer.NewWorker(service, service.GetDependency().GetBroker(types.Kafka), indexerOpts...)
+}
@@ -0,0 +1,17 @@
+package appfactory
+
+import (
+	webhookreceiver "github.com/golangid/candi/codebase/app/webhook_receiver"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/config/env"
+)
+
+// SetupWebhookReceiver setup webhook receiver with default config, provider must be added with webhookreceiver.AddProvider
+func SetupWebhookReceiver(service factory.ServiceFactory, opts ...webhookreceiver.OptionFunc) factory.AppServerFactory {
+	webhookReceiverOptions := []webhookreceiver.OptionFunc{
+		webhookreceiver.SetHTTPPort(env.BaseEnv().WebhookReceiverPort),
+		webhookreceiver.SetDebugMode(env.BaseEnv().DebugMode),
+	}
+	webhookReceiverOptions = append(webhookReceiverOptions, opts...)
+	return webhookreceiver.NewWorker(service, webhookReceiverOptions...)
+}
@@ -0,0 +1,221 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// Lifetime of resolved dependency
+type Lifetime int
+
+const (
+	// Singleton constructed once per container
+	Singleton Lifetime = iota
+	// Scoped constructed once per scope (request), constructed on every resolve when context has no scope
+	Scoped
+	// Transient constructed on every resolve
+	Transient
+)
+
+var (
+	// ErrNotRegistered no constructor registered for type
+	ErrNotRegistered = errors.New("dependency is not registered")
+)
+
+type (
+	// Container lightweight dependency injection container, constructor resolve its dependencies with Resolve
+	Container struct {
+		mu        sync.RWMutex
+		providers map[reflect.Type]*provider
+	}
+
+	provider struct {
+		lifetime    Lifetime
+		construct   func(ctx context.Context) (any, error)
+		once        sync.Mutex
+		constructed bool
+		instance    any
+	}
+
+	// Scope cache of scoped dependencies, closed at end of request
+	Scope struct {
+		mu        sync.Mutex
+		instances map[reflect.Type]any
+		order     []any
+	}
+
+	containerContextKey struct{}
+	scopeContextKey     struct{}
+	resolvingContextKey struct{}
+)
+
+var defaultContainer = NewContainer()
+
+// NewContainer create empty container
+func NewContainer() *Container {
+	return &Container{providers: make(map[reflect.Type]*provider)}
+}
+
+// DefaultContainer get global container
+func DefaultContainer() *Container {
+	return defaultContainer
+}
+
+// WithContainer set container to context, Resolve use global container if context has no container
+func WithContainer(ctx context.Context, c *Container) context.Context {
+	return context.WithValue(ctx, containerContextKey{}, c)
+}
+
+// NewScope set new scope to context, scoped dependencies resolved with returned context are cached in scope
+// until scope closed
+func NewScope(ctx context.Context) (context.Context, *Scope) {
+	scope := &Scope{instances: make(map[reflect.Type]any)}
+	return context.WithValue(ctx, scopeContextKey{}, scope), scope
+}
+
+// Close close scoped dependencies (implement io.Closer or interfaces.Closer) in reverse construct order
+func (s *Scope) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for i := len(s.order) - 1; i >= 0; i-- {
+		switch instance := s.order[i].(type) {
+		case interfaces.Closer:
+			errs = append(errs, instance.Disconnect(ctx))
+		case io.Closer:
+			errs = append(errs, instance.Close())
+		}
+	}
+	s.instances, s.order = make(map[reflect.Type]any), nil
+	return errors.Join(errs...)
+}
+
+// HTTPMiddlewareScope create dependency scope for each request, scope closed after request finished
+func HTTPMiddlewareScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, scope := NewScope(req.Context())
+		defer scope.Close(context.WithoutCancel(ctx))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// Provide register constructor of T to global container
+func Provide[T any](lifetime Lifetime, constructor func(ctx context.Context) (T, error)) {
+	ProvideTo(defaultContainer, lifetime, constructor)
+}
+
+// ProvideValue register singleton value of T to global container
+func ProvideValue[T any](value T) {
+	ProvideTo(defaultContainer, Singleton, func(context.Context) (T, error) { return value, nil })
+}
+
+// ProvideTo register constructor of T to container, replace previous constructor of T
+func ProvideTo[T any](c *Container, lifetime Lifetime, constructor func(ctx context.Context) (T, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers[typeOf[T]()] = &provider{
+		lifetime:  lifetime,
+		construct: func(ctx context.Context) (any, error) { return constructor(ctx) },
+	}
+}
+
+// Resolve get instance of T from container in context (or global container), constructor of dependency can resolve
+// its dependencies with given context. Return error if T not registered or has circular dependency
+func Resolve[T any](ctx context.Context) (instance T, err error) {
+	c, ok := ctx.Value(containerContextKey{}).(*Container)
+	if !ok {
+		c = defaultContainer
+	}
+	value, err := c.resolve(ctx, typeOf[T]())
+	if err != nil {
+		return instance, err
+	}
+	instance, _ = value.(T)
+	return instance, nil
+}
+
+// MustResolve get instance of T, panic if error
+func MustResolve[T any](ctx context.Context) T {
+	instance, err := Resolve[T](ctx)
+	if err != nil {
+		panic(err)
+	}
+	return instance
+}
+
+func (c *Container) resolve(ctx context.Context, t reflect.Type) (any, error) {
+	c.mu.RLock()
+	p, ok := c.providers[t]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegistered, t)
+	}
+
+	resolving, _ := ctx.Value(resolvingContextKey{}).([]reflect.Type)
+	for i, r := range resolving {
+		if r == t {
+			chain := make([]string, 0, len(resolving)-i+1)
+			for _, r := range resolving[i:] {
+				chain = append(chain, r.String())
+			}
+			return nil, fmt.Errorf("circular dependency: %s -> %s", strings.Join(chain, " -> "), t)
+		}
+	}
+	constructCtx := context.WithValue(ctx, resolvingContextKey{}, append(resolving[:len(resolving):len(resolving)], t))
+
+	switch p.lifetime {
+	case Singleton:
+		p.once.Lock()
+		defer p.once.Unlock()
+		if !p.constructed {
+			instance, err := p.construct(constructCtx)
+			if err != nil {
+				return nil, fmt.Errorf("construct %s: %w", t, err)
+			}
+			p.instance, p.constructed = instance, true
+		}
+		return p.instance, nil
+
+	case Scoped:
+		scope, ok := ctx.Value(scopeContextKey{}).(*Scope)
+		if !ok {
+			break
+		}
+		scope.mu.Lock()
+		instance, ok := scope.instances[t]
+		scope.mu.Unlock()
+		if ok {
+			return instance, nil
+		}
+		instance, err := p.construct(constructCtx)
+		if err != nil {
+			return nil, fmt.Errorf("construct %s: %w", t, err)
+		}
+		scope.mu.Lock()
+		defer scope.mu.Unlock()
+		if existing, ok := scope.instances[t]; ok {
+			return existing, nil
+		}
+		scope.instances[t] = instance
+		scope.order = append(scope.order, instance)
+		return instance, nil
+	}
+
+	instance, err := p.construct(constructCtx)
+	if err != nil {
+		return nil, fmt.Errorf("construct %s: %w", t, err)
+	}
+	return instance, nil
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
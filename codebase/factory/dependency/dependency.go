@@ -27,6 +27,8 @@ type Dependency interface {
 	GetMongoDatabase() interfaces.MongoDatabase
 	// get primary redis pool
 	GetRedisPool() interfaces.RedisPool
+	// get embedded key-value store
+	GetBoltDatabase() interfaces.BoltDatabase
 
 	GetSQLDatabaseByKey(key string) interfaces.SQLDatabase
 	GetMongoDatabaseByKey(key string) interfaces.MongoDatabase
@@ -41,6 +43,15 @@ type Dependency interface {
 	GetLocker() interfaces.Locker
 	SetLocker(v interfaces.Locker)
 
+	GetStorage() interfaces.Storage
+	SetStorage(s interfaces.Storage)
+
+	GetGRPCClient() interfaces.GRPCClient
+	SetGRPCClient(c interfaces.GRPCClient)
+
+	GetSearchEngine() interfaces.SearchEngine
+	SetSearchEngine(s interfaces.SearchEngine)
+
 	GetExtended(key string) any
 	AddExtended(key string, value any)
 
@@ -121,6 +132,14 @@ func AddRedisPool(key string, db interfaces.RedisPool) Option {
 	}
 }
 
+// SetBoltDatabase option func, set embedded key-value store instance (used as default cache, locker,
+// and task queue worker persistent when redis/mongo/sql is not set)
+func SetBoltDatabase(db interfaces.BoltDatabase) Option {
+	return func(d *deps) {
+		d.boltDB = db
+	}
+}
+
 // SetKey option func
 func SetKey(key interfaces.RSAKey) Option {
 	return func(d *deps) {
@@ -142,6 +161,42 @@ func SetLocker(lock interfaces.Locker) Option {
 	}
 }
 
+// SetStorage option func, set object storage instance
+func SetStorage(storage interfaces.Storage) Option {
+	return func(d *deps) {
+		d.storage = storage
+	}
+}
+
+// SetGRPCClient option func, set managed gRPC client of named targets
+func SetGRPCClient(client interfaces.GRPCClient) Option {
+	return func(d *deps) {
+		d.grpc = client
+	}
+}
+
+// SetSearchEngine option func, set search engine (Elasticsearch/OpenSearch) client
+func SetSearchEngine(search interfaces.SearchEngine) Option {
+	return func(d *deps) {
+		d.search = search
+	}
+}
+
+// SetLoader option func, set loaded providers (see Loader), value of ready provider is applied with Provider.Apply
+// and value of other provider can be get with GetExtended (lazy provider is initialized on first call)
+func SetLoader(l *Loader) Option {
+	return func(d *deps) {
+		d.loader = l
+		for _, name := range l.names {
+			s := l.providers[name]
+			if s.Apply != nil && !s.Lazy && s.status.State == ProviderReady && !s.applied {
+				s.Apply(s.value)(d)
+				s.applied = true
+			}
+		}
+	}
+}
+
 // SetExtended option func
 func SetExtended(ext map[string]any) Option {
 	return func(d *deps) {
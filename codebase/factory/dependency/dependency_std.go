@@ -2,6 +2,7 @@ package dependency
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/golangid/candi/codebase/factory/types"
@@ -15,11 +16,16 @@ type deps struct {
 	sqlDB     map[string]interfaces.SQLDatabase
 	mongoDB   map[string]interfaces.MongoDatabase
 	redisPool map[string]interfaces.RedisPool
+	boltDB    interfaces.BoltDatabase
 
 	key       interfaces.RSAKey
 	validator interfaces.Validator
 	locker    interfaces.Locker
+	storage   interfaces.Storage
+	grpc      interfaces.GRPCClient
+	search    interfaces.SearchEngine
 	extended  map[string]any
+	loader    *Loader
 }
 
 var stdDeps = new(deps)
@@ -72,6 +78,10 @@ func (d *deps) GetRedisPool() interfaces.RedisPool {
 	return d.redisPool[primary]
 }
 
+func (d *deps) GetBoltDatabase() interfaces.BoltDatabase {
+	return d.boltDB
+}
+
 func (d *deps) GetSQLDatabaseByKey(key string) interfaces.SQLDatabase {
 	db, ok := d.sqlDB[key]
 	if !ok {
@@ -120,8 +130,39 @@ func (d *deps) SetLocker(v interfaces.Locker) {
 	d.locker = v
 }
 
+func (d *deps) GetStorage() interfaces.Storage {
+	return d.storage
+}
+
+func (d *deps) SetStorage(s interfaces.Storage) {
+	d.storage = s
+}
+
+func (d *deps) GetGRPCClient() interfaces.GRPCClient {
+	return d.grpc
+}
+
+func (d *deps) SetGRPCClient(c interfaces.GRPCClient) {
+	d.grpc = c
+}
+
+func (d *deps) GetSearchEngine() interfaces.SearchEngine {
+	return d.search
+}
+
+func (d *deps) SetSearchEngine(s interfaces.SearchEngine) {
+	d.search = s
+}
+
 func (d *deps) GetExtended(key string) any {
-	return d.extended[key]
+	if ext, ok := d.extended[key]; ok || d.loader == nil {
+		return ext
+	}
+	value, err := d.loader.Get(context.Background(), key)
+	if err != nil && !errors.Is(err, ErrProviderNotRegistered) && !errors.Is(err, ErrProviderDisabled) {
+		log.Printf("\x1b[31;1m[dependency.GetExtended] %s: %s\x1b[0m\n", key, err.Error())
+	}
+	return value
 }
 
 func (d *deps) AddExtended(key string, value any) {
@@ -134,6 +175,8 @@ func (d *deps) Disconnect(ctx context.Context) error {
 		safeClose(ctx, bk)
 	}
 	safeClose(ctx, d.locker)
+	safeClose(ctx, d.grpc)
+	safeClose(ctx, d.search)
 	for _, sqlDeps := range d.sqlDB {
 		safeClose(ctx, sqlDeps)
 	}
@@ -143,11 +186,15 @@ func (d *deps) Disconnect(ctx context.Context) error {
 	for _, redisDeps := range d.redisPool {
 		safeClose(ctx, redisDeps)
 	}
+	safeClose(ctx, d.boltDB)
 	for _, ext := range d.extended {
 		if cl, ok := ext.(interfaces.Closer); ok {
 			safeClose(ctx, cl)
 		}
 	}
+	if d.loader != nil {
+		d.loader.Disconnect(ctx)
+	}
 	return nil
 }
 
@@ -176,6 +223,11 @@ func GetRedisPool() interfaces.RedisPool {
 	return stdDeps.redisPool[primary]
 }
 
+// GetBoltDatabase public function for get embedded key-value store
+func GetBoltDatabase() interfaces.BoltDatabase {
+	return stdDeps.boltDB
+}
+
 // GetKey public function for get key (RSA)
 func GetKey() interfaces.RSAKey {
 	return stdDeps.key
@@ -191,6 +243,21 @@ func GetLocker() interfaces.Locker {
 	return stdDeps.locker
 }
 
+// GetStorage public function for get object storage
+func GetStorage() interfaces.Storage {
+	return stdDeps.storage
+}
+
+// GetGRPCClient public function for get managed gRPC client
+func GetGRPCClient() interfaces.GRPCClient {
+	return stdDeps.grpc
+}
+
+// GetSearchEngine public function for get search engine client
+func GetSearchEngine() interfaces.SearchEngine {
+	return stdDeps.search
+}
+
 // GetExtended public function for get extended
 func GetExtended(key string) any {
 	return stdDeps.GetExtended(key)
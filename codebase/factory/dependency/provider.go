@@ -0,0 +1,345 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/logger"
+)
+
+// ProviderState initialization state of provider
+type ProviderState string
+
+const (
+	// ProviderPending provider is not initialized yet (lazy provider before first Get)
+	ProviderPending ProviderState = "pending"
+	// ProviderReady provider is initialized and ready
+	ProviderReady ProviderState = "ready"
+	// ProviderFailed init or readiness check of provider failed
+	ProviderFailed ProviderState = "failed"
+	// ProviderBlocked provider is not initialized because one of its dependencies is not ready
+	ProviderBlocked ProviderState = "blocked"
+	// ProviderDisabled provider is skipped because Enabled return false
+	ProviderDisabled ProviderState = "disabled"
+)
+
+var (
+	// ErrProviderNotRegistered provider name is not registered in loader
+	ErrProviderNotRegistered = errors.New("dependency provider is not registered")
+	// ErrProviderDisabled provider is disabled
+	ErrProviderDisabled = errors.New("dependency provider is disabled")
+)
+
+type (
+	// Provider declare dependency with initialization order, readiness check and initialization mode
+	Provider struct {
+		Name string
+		// DependsOn name of providers must be ready before this provider is initialized
+		DependsOn []string
+		// Init construct dependency (example: connect to database or run migration), value of other provider can be
+		// get with Loader.Get (must be declared in DependsOn)
+		Init func(ctx context.Context, l *Loader) (any, error)
+		// Ready readiness check after Init, retried until ready or context done. Also used in Loader.Health
+		Ready func(ctx context.Context, value any) error
+		// Enabled provider is skipped when return false (example: connect to kafka only when kafka worker is enabled)
+		Enabled func() bool
+		// Optional failed provider does not fail Loader.Load, dependency value is nil
+		Optional bool
+		// Lazy provider is initialized on first Loader.Get instead of on Loader.Load
+		Lazy bool
+		// Apply set initialized value to dependency with option (example: SetSQLDatabase) when loader is set with
+		// SetLoader, applied value is closed by dependency. Ignored for lazy provider
+		Apply func(value any) Option
+	}
+
+	// ProviderStatus initialization result of provider
+	ProviderStatus struct {
+		Name     string
+		State    ProviderState
+		Optional bool
+		Duration time.Duration
+		Err      error
+	}
+
+	// LoadError aggregated report of required providers which are not ready
+	LoadError struct {
+		Failed []ProviderStatus
+	}
+
+	// Loader initialize providers in dependency order, independent providers are initialized concurrently
+	Loader struct {
+		providers     map[string]*providerState
+		names         []string
+		readyInterval time.Duration
+		lazyTimeout   time.Duration
+
+		mu          sync.Mutex
+		initialized []*providerState
+	}
+
+	// LoaderOption option func type
+	LoaderOption func(*Loader)
+
+	providerState struct {
+		Provider
+		once    sync.Once
+		value   any
+		status  ProviderStatus
+		applied bool
+	}
+)
+
+// LoaderSetReadyInterval option func, wait interval before retry readiness check (default 500ms)
+func LoaderSetReadyInterval(interval time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.readyInterval = interval
+	}
+}
+
+// LoaderSetLazyTimeout option func, max duration of lazy provider initialization (include readiness check)
+// on first Get, so provider which never ready does not block caller forever (default 30 seconds)
+func LoaderSetLazyTimeout(timeout time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.lazyTimeout = timeout
+	}
+}
+
+// NewLoader construct loader, panic when provider name is duplicated, dependency is not registered,
+// or dependencies have cycle
+func NewLoader(providers []Provider, opts ...LoaderOption) *Loader {
+	l := &Loader{
+		providers:     make(map[string]*providerState, len(providers)),
+		readyInterval: 500 * time.Millisecond,
+		lazyTimeout:   30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	for _, p := range providers {
+		if p.Name == "" || p.Init == nil {
+			panic("dependency provider: name and init func is required")
+		}
+		if _, ok := l.providers[p.Name]; ok {
+			panic(fmt.Sprintf("dependency provider: '%s' has been registered", p.Name))
+		}
+		l.providers[p.Name] = &providerState{
+			Provider: p, status: ProviderStatus{Name: p.Name, State: ProviderPending, Optional: p.Optional},
+		}
+		l.names = append(l.names, p.Name)
+	}
+	for _, name := range l.names {
+		for _, dep := range l.providers[name].DependsOn {
+			if _, ok := l.providers[dep]; !ok {
+				panic(fmt.Sprintf("dependency provider: '%s' depends on '%s' which is not registered", name, dep))
+			}
+		}
+	}
+	visited := make(map[string]int)
+	for _, name := range l.names {
+		if path := l.findCycle(name, visited, nil); path != nil {
+			panic("dependency provider: cycle detected " + strings.Join(path, " -> "))
+		}
+	}
+	return l
+}
+
+// findCycle depth first search, visited state 1 is in current path and 2 is done
+func (l *Loader) findCycle(name string, visited map[string]int, path []string) []string {
+	path = append(path, name)
+	switch visited[name] {
+	case 1:
+		return path
+	case 2:
+		return nil
+	}
+	visited[name] = 1
+	for _, dep := range l.providers[name].DependsOn {
+		if cycle := l.findCycle(dep, visited, path); cycle != nil {
+			return cycle
+		}
+	}
+	visited[name] = 2
+	return nil
+}
+
+// Load initialize all non lazy providers and wait until ready, return *LoadError contains report of
+// required providers which are failed, blocked by failed dependency, or depends on disabled provider
+func (l *Loader) Load(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, name := range l.names {
+		if s := l.providers[name]; !s.Lazy {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				l.init(ctx, s)
+			}()
+		}
+	}
+	wg.Wait()
+
+	loadErr := &LoadError{}
+	for _, status := range l.Report() {
+		switch status.State {
+		case ProviderReady:
+			logger.LogGreen(fmt.Sprintf("dependency > %s: ready (%s)", status.Name, status.Duration.Round(time.Millisecond)))
+		case ProviderDisabled, ProviderPending:
+			logger.LogYellow(fmt.Sprintf("dependency > %s: %s", status.Name, status.State))
+		default:
+			logger.LogRed(fmt.Sprintf("dependency > %s: %s: %v", status.Name, status.State, status.Err))
+			if !status.Optional {
+				loadErr.Failed = append(loadErr.Failed, status)
+			}
+		}
+	}
+	if len(loadErr.Failed) > 0 {
+		return loadErr
+	}
+	return nil
+}
+
+// Get value of provider, lazy provider (and its dependencies) is initialized on first call with lazy timeout
+func (l *Loader) Get(ctx context.Context, name string) (any, error) {
+	s, ok := l.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotRegistered, name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, l.lazyTimeout)
+	defer cancel()
+	l.init(ctx, s)
+	return s.value, s.status.Err
+}
+
+// Report status of all providers in registration order
+func (l *Loader) Report() []ProviderStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	report := make([]ProviderStatus, 0, len(l.names))
+	for _, name := range l.names {
+		report = append(report, l.providers[name].status)
+	}
+	return report
+}
+
+// Health readiness check of initialized providers
+func (l *Loader) Health() map[string]error {
+	l.mu.Lock()
+	initialized := append([]*providerState{}, l.initialized...)
+	l.mu.Unlock()
+
+	health := make(map[string]error, len(initialized))
+	for _, s := range initialized {
+		health[s.Name] = s.status.Err
+		if s.status.State == ProviderReady && s.Ready != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			health[s.Name] = s.Ready(ctx, s.value)
+			cancel()
+		}
+	}
+	return health
+}
+
+// Disconnect close value of initialized providers (implement interfaces.Closer) in reverse initialization order,
+// value applied to dependency is closed by dependency
+func (l *Loader) Disconnect(ctx context.Context) error {
+	l.mu.Lock()
+	initialized := append([]*providerState{}, l.initialized...)
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(initialized) - 1; i >= 0; i-- {
+		s := initialized[i]
+		if cl, ok := s.value.(interfaces.Closer); ok && !s.applied {
+			if err := cl.Disconnect(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *Loader) init(ctx context.Context, s *providerState) {
+	s.once.Do(func() {
+		status := ProviderStatus{Name: s.Name, Optional: s.Optional}
+		defer func() {
+			l.mu.Lock()
+			s.status = status
+			if status.State == ProviderReady || status.State == ProviderFailed {
+				l.initialized = append(l.initialized, s)
+			}
+			l.mu.Unlock()
+		}()
+
+		if s.Enabled != nil && !s.Enabled() {
+			status.State, status.Err = ProviderDisabled, ErrProviderDisabled
+			return
+		}
+		for _, dep := range s.DependsOn {
+			depState := l.providers[dep]
+			l.init(ctx, depState)
+			if depState.status.State != ProviderReady {
+				status.State = ProviderBlocked
+				status.Err = fmt.Errorf("depends on '%s' which is %s", dep, depState.status.State)
+				return
+			}
+		}
+
+		start := time.Now()
+		s.value, status.Err = l.initProvider(ctx, s)
+		status.Duration = time.Since(start)
+		if status.Err != nil {
+			status.State = ProviderFailed
+			return
+		}
+		status.State = ProviderReady
+	})
+}
+
+func (l *Loader) initProvider(ctx context.Context, s *providerState) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if value, err = s.Init(ctx, l); err != nil || s.Ready == nil {
+		return value, err
+	}
+	for {
+		if err = s.Ready(ctx, value); err == nil {
+			return value, nil
+		}
+		select {
+		case <-ctx.Done():
+			if cl, ok := value.(interfaces.Closer); ok {
+				cl.Disconnect(context.Background())
+			}
+			return nil, fmt.Errorf("not ready: %w", err)
+		case <-time.After(l.readyInterval):
+		}
+	}
+}
+
+// Error render aggregated report
+func (e *LoadError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "dependency: %d required dependencies are not ready", len(e.Failed))
+	for _, status := range e.Failed {
+		fmt.Fprintf(&b, "\n  - %s (%s): %v", status.Name, status.State, status.Err)
+	}
+	return b.String()
+}
+
+// Unwrap errors of failed providers
+func (e *LoadError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, status := range e.Failed {
+		errs = append(errs, status.Err)
+	}
+	return errs
+}
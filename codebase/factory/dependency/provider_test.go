@@ -0,0 +1,133 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func(ctx context.Context) error
+
+func (f closerFunc) Disconnect(ctx context.Context) error { return f(ctx) }
+
+func TestLoaderOrdering(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		order  []string
+		closed []string
+	)
+	provider := func(name string, dependsOn ...string) Provider {
+		return Provider{Name: name, DependsOn: dependsOn, Init: func(ctx context.Context, l *Loader) (any, error) {
+			for _, dep := range dependsOn {
+				if value, err := l.Get(ctx, dep); err != nil || value == nil {
+					return nil, errors.New("dependency not initialized")
+				}
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return closerFunc(func(ctx context.Context) error {
+				closed = append(closed, name)
+				return nil
+			}), nil
+		}}
+	}
+
+	l := NewLoader([]Provider{provider("migration", "sql"), provider("sql", "vault"), provider("vault"), provider("redis")})
+	require.NoError(t, l.Load(context.Background()))
+	assert.Less(t, slices.Index(order, "vault"), slices.Index(order, "sql"))
+	assert.Less(t, slices.Index(order, "sql"), slices.Index(order, "migration"))
+	for _, status := range l.Report() {
+		assert.Equal(t, ProviderReady, status.State, status.Name)
+	}
+
+	require.NoError(t, l.Disconnect(context.Background()))
+	assert.Less(t, slices.Index(closed, "migration"), slices.Index(closed, "sql"), "closed in reverse order")
+	assert.Less(t, slices.Index(closed, "sql"), slices.Index(closed, "vault"))
+}
+
+func TestLoaderValidation(t *testing.T) {
+	noop := func(ctx context.Context, l *Loader) (any, error) { return nil, nil }
+	assert.PanicsWithValue(t, "dependency provider: cycle detected a -> b -> c -> a", func() {
+		NewLoader([]Provider{
+			{Name: "a", DependsOn: []string{"b"}, Init: noop},
+			{Name: "b", DependsOn: []string{"c"}, Init: noop},
+			{Name: "c", DependsOn: []string{"a"}, Init: noop},
+		})
+	})
+	assert.Panics(t, func() { NewLoader([]Provider{{Name: "a", DependsOn: []string{"unknown"}, Init: noop}}) })
+	assert.Panics(t, func() { NewLoader([]Provider{{Name: "a", Init: noop}, {Name: "a", Init: noop}}) })
+}
+
+func TestLoaderFailure(t *testing.T) {
+	errConnect := errors.New("connection refused")
+	failed := func(ctx context.Context, l *Loader) (any, error) { return nil, errConnect }
+	ok := func(ctx context.Context, l *Loader) (any, error) { return "ok", nil }
+
+	l := NewLoader([]Provider{
+		{Name: "tracer", Init: failed, Optional: true},
+		{Name: "sql", Init: failed},
+		{Name: "repository", DependsOn: []string{"sql"}, Init: ok},
+		{Name: "kafka", Init: ok, Enabled: func() bool { return false }},
+		{Name: "redis", Init: ok},
+	})
+	err := l.Load(context.Background())
+	var loadErr *LoadError
+	require.ErrorAs(t, err, &loadErr)
+	assert.ErrorIs(t, err, errConnect)
+
+	failedNames := map[string]ProviderState{}
+	for _, status := range loadErr.Failed {
+		failedNames[status.Name] = status.State
+	}
+	assert.Equal(t, map[string]ProviderState{"sql": ProviderFailed, "repository": ProviderBlocked}, failedNames,
+		"optional provider does not fail load")
+
+	value, err := l.Get(context.Background(), "tracer")
+	assert.Nil(t, value)
+	assert.ErrorIs(t, err, errConnect)
+	_, err = l.Get(context.Background(), "kafka")
+	assert.ErrorIs(t, err, ErrProviderDisabled)
+	value, err = l.Get(context.Background(), "redis")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", value)
+	_, err = l.Get(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrProviderNotRegistered)
+}
+
+func TestLoaderLazy(t *testing.T) {
+	var initCount int
+	l := NewLoader([]Provider{
+		{Name: "search", Lazy: true, Init: func(ctx context.Context, l *Loader) (any, error) {
+			initCount++
+			return "client", nil
+		}},
+		{Name: "never-ready", Lazy: true,
+			Init:  func(ctx context.Context, l *Loader) (any, error) { return "client", nil },
+			Ready: func(ctx context.Context, value any) error { return errors.New("not ready") },
+		},
+	}, LoaderSetReadyInterval(time.Millisecond), LoaderSetLazyTimeout(50*time.Millisecond))
+
+	require.NoError(t, l.Load(context.Background()))
+	assert.Equal(t, 0, initCount, "lazy provider is not initialized on load")
+	assert.Equal(t, ProviderPending, l.Report()[0].State)
+
+	for range 2 {
+		value, err := l.Get(context.Background(), "search")
+		assert.NoError(t, err)
+		assert.Equal(t, "client", value)
+	}
+	assert.Equal(t, 1, initCount)
+
+	start := time.Now()
+	_, err := l.Get(context.Background(), "never-ready")
+	assert.ErrorContains(t, err, "not ready")
+	assert.Less(t, time.Since(start), 5*time.Second, "lazy init is bounded by lazy timeout")
+	assert.Equal(t, ProviderFailed, l.Report()[1].State)
+}
@@ -26,4 +26,12 @@ const (
 	TaskQueue Worker = "task_queue"
 	// PostgresListener worker
 	PostgresListener Worker = "postgres_listener"
+	// WebhookReceiver worker
+	WebhookReceiver Worker = "webhook_receiver"
+	// MongoChangeStream worker
+	MongoChangeStream Worker = "mongo_change_stream"
+	// PostgresNotify worker
+	PostgresNotify Worker = "postgres_notify"
+	// SearchIndexer worker
+	SearchIndexer Worker = "search_indexer"
 )
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+
 	"github.com/golangid/candi/candishared"
 )
 
@@ -78,3 +80,21 @@ func WorkerHandlerOptionAddHandlers(handlerFuncs ...WorkerHandlerFunc) WorkerHan
 		wh.HandlerFuncs = append(wh.HandlerFuncs, handlerFuncs...)
 	}
 }
+
+// TypedWorkerHandlerFunc create worker handler func with typed payload, message is bound to T using
+// candishared.BindMessage (unmarshal & validate) before handler running
+func TypedWorkerHandlerFunc[T any](handler func(ctx context.Context, payload T) error, validators ...func(any) error) WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		payload, err := candishared.BindMessage[T](eventContext, validators...)
+		if err != nil {
+			return err
+		}
+		return handler(eventContext.Context(), payload)
+	}
+}
+
+// AddTypedHandler add handler with typed payload to worker handler group,
+// use TypedWorkerHandlerFunc with group.Add for custom validators
+func AddTypedHandler[T any](group *WorkerHandlerGroup, patternRoute string, handler func(ctx context.Context, payload T) error, opts ...WorkerHandlerOptionFunc) {
+	group.Add(patternRoute, TypedWorkerHandlerFunc(handler), opts...)
+}
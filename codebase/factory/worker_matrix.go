@@ -0,0 +1,147 @@
+package factory
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+WorkerMatrix declarative enable/disable worker handlers per environment, loaded from WORKER_MATRIX_FILE (yaml or json).
+Rule is worker type (disable whole worker) or "{worker type}:{handler pattern}" (support wildcard "*"),
+handler pattern for cron job is job name. Rule in enable list override disable list.
+
+	staging:
+	  disable:
+	    - rabbit_mq                 # disable whole rabbitmq worker
+	    - kafka:third-party-sync-*  # disable handler with topic prefix
+	    - scheduler:send-email      # disable cron job
+	  enable:
+	    - kafka:third-party-sync-payment
+*/
+type WorkerMatrix map[string]WorkerMatrixRule
+
+// WorkerMatrixRule enable/disable rule of environment
+type WorkerMatrixRule struct {
+	Disable []string `json:"disable" yaml:"disable"`
+	Enable  []string `json:"enable" yaml:"enable"`
+}
+
+var (
+	workerMatrix     WorkerMatrixRule
+	workerMatrixOnce sync.Once
+)
+
+// LoadWorkerMatrix parse worker matrix file (yaml or json)
+func LoadWorkerMatrix(filename string) (matrix WorkerMatrix, err error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(filename, ".json") {
+		err = json.Unmarshal(b, &matrix)
+	} else {
+		err = yaml.Unmarshal(b, &matrix)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid worker matrix %s: %w", filename, err)
+	}
+	return matrix, nil
+}
+
+// SetWorkerMatrixRule set worker matrix rule for current process (override rule from WORKER_MATRIX_FILE)
+func SetWorkerMatrixRule(rule WorkerMatrixRule) {
+	workerMatrixOnce.Do(func() {})
+	workerMatrix = rule
+}
+
+func getWorkerMatrixRule() WorkerMatrixRule {
+	workerMatrixOnce.Do(func() {
+		filename := env.BaseEnv().WorkerMatrixFile
+		if filename == "" {
+			return
+		}
+		matrix, err := LoadWorkerMatrix(filename)
+		if err != nil {
+			log.Panicf("Worker matrix: %v", err)
+		}
+		workerMatrix = matrix[env.BaseEnv().Environment]
+	})
+	return workerMatrix
+}
+
+// IsWorkerDisabled check whole worker type is disabled in current environment
+func IsWorkerDisabled(workerType types.Worker) bool {
+	rule := getWorkerMatrixRule()
+	if !matchWorkerRule(rule.Disable, workerType, "") {
+		return false
+	}
+	for _, enable := range rule.Enable {
+		if worker, _, _ := strings.Cut(enable, ":"); worker == string(workerType) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsWorkerHandlerDisabled check worker handler is disabled in current environment
+func IsWorkerHandlerDisabled(workerType types.Worker, pattern string) bool {
+	rule := getWorkerMatrixRule()
+	name := workerHandlerName(pattern)
+	return matchWorkerRule(rule.Disable, workerType, name) && !matchWorkerRule(rule.Enable, workerType, name)
+}
+
+// ApplyWorkerMatrix remove handlers disabled in current environment from handler group, call after mount handlers
+func ApplyWorkerMatrix(workerType types.Worker, group *types.WorkerHandlerGroup) {
+	handlers := group.Handlers[:0]
+	for _, handler := range group.Handlers {
+		if IsWorkerHandlerDisabled(workerType, handler.Pattern) {
+			logger.LogRed(fmt.Sprintf(`[WORKER-MATRIX] %s handler "%s" is disabled in "%s" environment`,
+				workerType, workerHandlerName(handler.Pattern), env.BaseEnv().Environment))
+			continue
+		}
+		handlers = append(handlers, handler)
+	}
+	group.Handlers = handlers
+}
+
+func matchWorkerRule(rules []string, workerType types.Worker, name string) bool {
+	for _, rule := range rules {
+		worker, pattern, hasPattern := strings.Cut(rule, ":")
+		if worker != string(workerType) {
+			continue
+		}
+		if !hasPattern || pattern == "*" {
+			return true
+		}
+		if name == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// workerHandlerName cron job pattern is json key, use job name
+func workerHandlerName(pattern string) string {
+	if strings.HasPrefix(pattern, "{") {
+		var cronKey struct {
+			JobName string `json:"jobName"`
+		}
+		if json.Unmarshal([]byte(pattern), &cronKey) == nil && cronKey.JobName != "" {
+			return cronKey.JobName
+		}
+	}
+	return pattern
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 
 	"github.com/gomodule/redigo/redis"
+	bolt "go.etcd.io/bbolt"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -31,3 +32,11 @@ type RedisPool interface {
 	Cache() Cache
 	Closer
 }
+
+// BoltDatabase embedded key-value store (bbolt) abstraction
+type BoltDatabase interface {
+	DB() *bolt.DB
+	Health() map[string]error
+	Cache() Cache
+	Closer
+}
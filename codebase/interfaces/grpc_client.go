@@ -0,0 +1,10 @@
+package interfaces
+
+import "google.golang.org/grpc"
+
+// GRPCClient abstraction of managed gRPC client connections by target name
+type GRPCClient interface {
+	// Conn get (or create) client connection of named target, use for create generated client
+	Conn(name string) (*grpc.ClientConn, error)
+	Closer
+}
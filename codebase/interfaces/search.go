@@ -0,0 +1,37 @@
+package interfaces
+
+import "context"
+
+type (
+	// SearchEngine abstract search engine client (Elasticsearch, OpenSearch)
+	SearchEngine interface {
+		// Do send request to search engine, body is encoded to JSON ([]byte sent as is) and response is decoded to result,
+		// body and result can be nil
+		Do(ctx context.Context, method, path string, body, result any) error
+		Index(ctx context.Context, index, id string, document any) error
+		Delete(ctx context.Context, index, id string) error
+		Search(ctx context.Context, index string, query, result any) error
+		// Bulk execute multiple operation in one request, result of each item is in same order with items
+		Bulk(ctx context.Context, items []SearchBulkItem) ([]SearchBulkResult, error)
+		Health() map[string]error
+		Closer
+	}
+
+	// SearchBulkItem operation of bulk request
+	SearchBulkItem struct {
+		// Action is index, create, update or delete
+		Action string
+		Index  string
+		ID     string
+		// Document source for index and create, partial document for update, and ignored for delete
+		Document any
+	}
+
+	// SearchBulkResult result of bulk operation item
+	SearchBulkResult struct {
+		Index  string `json:"_index"`
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+)
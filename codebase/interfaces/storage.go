@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type (
+	// Storage abstract object storage interface (S3, GCS, MinIO, local filesystem)
+	Storage interface {
+		Put(ctx context.Context, key string, body io.Reader, contentType string) error
+		Get(ctx context.Context, key string) (io.ReadCloser, error)
+		Delete(ctx context.Context, key string) error
+		// SignedURL generate temporary url for access object without credential, method is http method (GET or PUT)
+		SignedURL(ctx context.Context, key, method string, expire time.Duration) (string, error)
+		List(ctx context.Context, prefix string) ([]StorageObject, error)
+	}
+
+	// StorageObject object metadata
+	StorageObject struct {
+		Key          string    `json:"key"`
+		Size         int64     `json:"size"`
+		ETag         string    `json:"etag,omitempty"`
+		LastModified time.Time `json:"lastModified"`
+	}
+)
@@ -4,13 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net"
 	"os"
 	"os/signal"
 	"runtime/debug"
 	"syscall"
 	"time"
 
+	"github.com/golangid/candi/candiutils/graceful"
 	"github.com/golangid/candi/codebase/interfaces"
 	"github.com/golangid/candi/config/env"
 	"github.com/soheilhy/cmux"
@@ -32,7 +32,7 @@ func Init(serviceName string) *Config {
 
 	// setup shared listener with cmux
 	if env.BaseEnv().UseSharedListener {
-		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", env.BaseEnv().HTTPPort))
+		listener, err := graceful.Listen("tcp", fmt.Sprintf(":%d", env.BaseEnv().HTTPPort))
 		if err != nil {
 			panic(err)
 		}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangid/candi/cache"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltInstance embedded key-value store instance, can be used as cache, locker,
+// and task queue worker persistent for single instance service (zero external infrastructure)
+type BoltInstance struct {
+	Bolt   *bolt.DB
+	ICache interfaces.Cache
+}
+
+// DB method
+func (b *BoltInstance) DB() *bolt.DB {
+	return b.Bolt
+}
+
+// Cache method
+func (b *BoltInstance) Cache() interfaces.Cache {
+	return b.ICache
+}
+
+// Locker method
+func (b *BoltInstance) Locker(opts ...candiutils.LockerOption) interfaces.Locker {
+	return candiutils.NewBoltLocker(b.Bolt, opts...)
+}
+
+// Health method
+func (b *BoltInstance) Health() map[string]error {
+	return map[string]error{
+		"bolt": b.Bolt.View(func(tx *bolt.Tx) error { return nil }),
+	}
+}
+
+// Disconnect method
+func (b *BoltInstance) Disconnect(ctx context.Context) error {
+	defer logger.LogWithDefer("\x1b[33;5mbolt\x1b[0m: disconnect...")()
+
+	return b.Bolt.Close()
+}
+
+// InitBolt open embedded key-value store file from environment:
+// BOLT_DB_PATH (default "candi.db" in working directory), register with dependency.SetBoltDatabase
+func InitBolt(opts ...func(*bolt.Options)) *BoltInstance {
+	defer logger.LogWithDefer("Load Bolt embedded store...")()
+
+	db := ConnectBolt(env.BaseEnv().DbBoltPath, opts...)
+	return &BoltInstance{
+		Bolt: db, ICache: cache.NewBoltCache(db),
+	}
+}
+
+// ConnectBolt open bolt db file with given path
+func ConnectBolt(path string, opts ...func(*bolt.Options)) *bolt.DB {
+	boltOpts := &bolt.Options{Timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(boltOpts)
+	}
+
+	db, err := bolt.Open(path, 0600, boltOpts)
+	if err != nil {
+		panic("bolt: " + err.Error() + ", path: " + path)
+	}
+	return db
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+)
+
+// InitSearchEngine init Elasticsearch/OpenSearch client from environment:
+// SEARCH_ENGINE_ADDRESSES (comma separated node url), SEARCH_ENGINE_USERNAME, SEARCH_ENGINE_PASSWORD and SEARCH_ENGINE_API_KEY
+func InitSearchEngine() interfaces.SearchEngine {
+	defer logger.LogWithDefer("Load search engine connection...")()
+
+	cfg := env.BaseEnv().SearchEngine
+	engine, err := candiutils.NewElasticsearch(candiutils.ElasticsearchConfig{
+		Addresses: cfg.Addresses, Username: cfg.Username, Password: cfg.Password, APIKey: cfg.APIKey,
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := engine.Health()["elasticsearch"]; err != nil {
+		panic(err)
+	}
+	return engine
+}
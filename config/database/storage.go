@@ -0,0 +1,38 @@
+package database
+
+import (
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+)
+
+// InitStorage init object storage from environment:
+// STORAGE_DRIVER (local, s3, minio, or gcs, default local), STORAGE_BUCKET, STORAGE_ENDPOINT, STORAGE_REGION,
+// STORAGE_ACCESS_KEY, STORAGE_SECRET_KEY (HMAC key for gcs), and STORAGE_LOCAL_PATH, STORAGE_BASE_URL for local driver
+func InitStorage() interfaces.Storage {
+	defer logger.LogWithDefer("Load object storage...")()
+
+	cfg := env.BaseEnv().Storage
+	var storage interfaces.Storage
+	var err error
+	switch cfg.Driver {
+	case "", "local":
+		storage = candiutils.NewLocalStorage(cfg.LocalPath,
+			candiutils.LocalStorageSetBaseURL(cfg.BaseURL), candiutils.LocalStorageSetSecret(cfg.SecretKey))
+	case "s3":
+		storage, err = candiutils.NewS3Storage(candiutils.S3StorageConfig{
+			Endpoint: cfg.Endpoint, Region: cfg.Region, Bucket: cfg.Bucket, AccessKey: cfg.AccessKey, SecretKey: cfg.SecretKey,
+		})
+	case "minio":
+		storage, err = candiutils.NewMinIOStorage(cfg.Endpoint, cfg.Bucket, cfg.AccessKey, cfg.SecretKey)
+	case "gcs":
+		storage, err = candiutils.NewGCSStorage(cfg.Bucket, cfg.AccessKey, cfg.SecretKey)
+	default:
+		panic("storage: unsupported driver " + cfg.Driver)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return storage
+}
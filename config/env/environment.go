@@ -25,6 +25,20 @@ type Env struct {
 
 	useSQL, useMongo, useRedis, useRSAKey bool
 	UseSharedListener                     bool
+	// GracefulRestart mode for zero downtime restart triggered by SIGUSR2, "handoff" (pass listener to new process)
+	// or "reuseport" (new process bind same port with SO_REUSEPORT), disabled if empty
+	GracefulRestart string
+	// MigrationOnStartup run database migration (up) from MigrationDir when app start, locked so only one replica applies migration
+	MigrationOnStartup bool
+	MigrationDir       string
+	// WorkerMatrixFile per environment enable/disable worker handler config file (yaml or json)
+	WorkerMatrixFile string
+	// SeedDir seed files directory (.sql and .json), file in "{environment}/" subdirectory only run in that environment
+	SeedDir string
+	// ContractDir message contract directory, "published/" for recorded publisher contract and "consumed/" for consumer contract
+	ContractDir string
+	// ContractStrict reject consumed message violating declared consumer contract
+	ContractStrict bool
 
 	// UseREST env
 	UseREST bool
@@ -44,6 +58,14 @@ type Env struct {
 	UsePostgresListenerWorker bool
 	// UseRabbitMQWorker env
 	UseRabbitMQWorker bool
+	// UseWebhookReceiver env
+	UseWebhookReceiver bool
+	// UseMongoChangeStreamWorker env
+	UseMongoChangeStreamWorker bool
+	// UsePostgresNotifyWorker env
+	UsePostgresNotifyWorker bool
+	// UseSearchIndexer env
+	UseSearchIndexer bool
 
 	DebugMode bool
 
@@ -58,6 +80,8 @@ type Env struct {
 	TaskQueueDashboardPort uint16
 	// TaskQueueDashboardMaxClientSubscribers Config
 	TaskQueueDashboardMaxClientSubscribers int
+	// WebhookReceiverPort Config
+	WebhookReceiverPort uint16
 
 	// BasicAuthUsername config
 	BasicAuthUsername string
@@ -89,6 +113,27 @@ type Env struct {
 	DbMongoWriteHost, DbMongoReadHost string
 	DbSQLWriteDSN, DbSQLReadDSN       string
 	DbRedisReadDSN, DbRedisWriteDSN   string
+	DbBoltPath                        string
+
+	// Object storage environment
+	Storage struct {
+		Driver    string
+		Endpoint  string
+		Region    string
+		Bucket    string
+		AccessKey string
+		SecretKey string
+		LocalPath string
+		BaseURL   string
+	}
+
+	// Search engine (Elasticsearch/OpenSearch) environment
+	SearchEngine struct {
+		Addresses []string
+		Username  string
+		Password  string
+		APIKey    string
+	}
 
 	// CORS Environment
 	CORSAllowOrigins, CORSAllowMethods, CORSAllowHeaders []string
@@ -144,6 +189,33 @@ func Load(serviceName string) {
 		}
 	}
 
+	env.GracefulRestart = strings.ToLower(os.Getenv("GRACEFUL_RESTART"))
+	if env.GracefulRestart != "" && env.GracefulRestart != "handoff" && env.GracefulRestart != "reuseport" {
+		mErrs.Append("GRACEFUL_RESTART", errors.New("GRACEFUL_RESTART environment must one of [handoff, reuseport]"))
+	}
+
+	env.MigrationOnStartup = parseBool("MIGRATION_ON_STARTUP")
+	env.MigrationDir = os.Getenv(candihelper.WORKDIR) + "migrations"
+	if dir, ok := os.LookupEnv("MIGRATION_DIR"); ok && dir != "" {
+		env.MigrationDir = dir
+	}
+	if env.WorkerMatrixFile = os.Getenv("WORKER_MATRIX_FILE"); env.WorkerMatrixFile != "" {
+		if _, err := os.Stat(env.WorkerMatrixFile); err != nil {
+			mErrs.Append("WORKER_MATRIX_FILE", err)
+		}
+	} else if _, err := os.Stat(os.Getenv(candihelper.WORKDIR) + "worker_matrix.yaml"); err == nil {
+		env.WorkerMatrixFile = os.Getenv(candihelper.WORKDIR) + "worker_matrix.yaml"
+	}
+	env.SeedDir = os.Getenv(candihelper.WORKDIR) + "seeds"
+	if dir, ok := os.LookupEnv("SEED_DIR"); ok && dir != "" {
+		env.SeedDir = dir
+	}
+	env.ContractDir = os.Getenv(candihelper.WORKDIR) + "contracts"
+	if dir, ok := os.LookupEnv("CONTRACT_DIR"); ok && dir != "" {
+		env.ContractDir = dir
+	}
+	env.ContractStrict = parseBool("CONTRACT_STRICT")
+
 	if env.UseTaskQueueWorker {
 		taskQueueDashboardPort, ok := os.LookupEnv("TASK_QUEUE_DASHBOARD_PORT")
 		if !ok {
@@ -159,6 +231,17 @@ func Load(serviceName string) {
 			env.TaskQueueDashboardMaxClientSubscribers = 10 // default
 		}
 	}
+	if env.UseWebhookReceiver {
+		webhookReceiverPort, ok := os.LookupEnv("WEBHOOK_RECEIVER_PORT")
+		if !ok {
+			webhookReceiverPort = "8090"
+		}
+		port, err := strconv.Atoi(webhookReceiverPort)
+		if err != nil {
+			mErrs.Append("WEBHOOK_RECEIVER_PORT", errors.New("WEBHOOK_RECEIVER_PORT environment must in integer format"))
+		}
+		env.WebhookReceiverPort = uint16(port)
+	}
 
 	// ------------------------------------
 	env.Environment = os.Getenv("ENVIRONMENT")
@@ -197,6 +280,8 @@ func Load(serviceName string) {
 
 	// Parse database environment
 	parseDatabaseEnv()
+	parseStorageEnv()
+	parseSearchEngineEnv()
 
 	// Parse CORS environment
 	parseCorsEnv()
@@ -270,6 +355,30 @@ func parseAppConfig() {
 	} else {
 		env.UseRabbitMQWorker, _ = strconv.ParseBool(useRabbitMQWorker)
 	}
+	useWebhookReceiver, ok := os.LookupEnv("USE_WEBHOOK_RECEIVER")
+	if !ok {
+		flag.BoolVar(&env.UseWebhookReceiver, "USE_WEBHOOK_RECEIVER", false, "USE WEBHOOK RECEIVER")
+	} else {
+		env.UseWebhookReceiver, _ = strconv.ParseBool(useWebhookReceiver)
+	}
+	useMongoChangeStream, ok := os.LookupEnv("USE_MONGO_CHANGE_STREAM_WORKER")
+	if !ok {
+		flag.BoolVar(&env.UseMongoChangeStreamWorker, "USE_MONGO_CHANGE_STREAM_WORKER", false, "USE MONGO CHANGE STREAM WORKER")
+	} else {
+		env.UseMongoChangeStreamWorker, _ = strconv.ParseBool(useMongoChangeStream)
+	}
+	usePostgresNotify, ok := os.LookupEnv("USE_POSTGRES_NOTIFY_WORKER")
+	if !ok {
+		flag.BoolVar(&env.UsePostgresNotifyWorker, "USE_POSTGRES_NOTIFY_WORKER", false, "USE POSTGRES NOTIFY WORKER")
+	} else {
+		env.UsePostgresNotifyWorker, _ = strconv.ParseBool(usePostgresNotify)
+	}
+	useSearchIndexer, ok := os.LookupEnv("USE_SEARCH_INDEXER")
+	if !ok {
+		flag.BoolVar(&env.UseSearchIndexer, "USE_SEARCH_INDEXER", false, "USE SEARCH INDEXER")
+	} else {
+		env.UseSearchIndexer, _ = strconv.ParseBool(useSearchIndexer)
+	}
 
 	flag.Usage = func() {
 		fmt.Println("	-USE_REST :=> Activate REST Server")
@@ -281,6 +390,10 @@ func parseAppConfig() {
 		fmt.Println("	-USE_TASK_QUEUE_WORKER :=> Activate Task Queue Worker")
 		fmt.Println("	-USE_POSTGRES_LISTENER_WORKER :=> Activate Postgres Event Worker")
 		fmt.Println("	-USE_RABBITMQ_CONSUMER :=> Activate Rabbit MQ Consumer")
+		fmt.Println("	-USE_WEBHOOK_RECEIVER :=> Activate Webhook Receiver")
+		fmt.Println("	-USE_MONGO_CHANGE_STREAM_WORKER :=> Activate Mongo Change Stream Worker")
+		fmt.Println("	-USE_POSTGRES_NOTIFY_WORKER :=> Activate Postgres LISTEN/NOTIFY Worker")
+		fmt.Println("	-USE_SEARCH_INDEXER :=> Activate Search Indexer (Kafka to Elasticsearch/OpenSearch)")
 	}
 	flag.Parse()
 }
@@ -315,6 +428,34 @@ func parseDatabaseEnv() {
 
 	env.DbRedisReadDSN = os.Getenv("REDIS_READ_DSN")
 	env.DbRedisWriteDSN = os.Getenv("REDIS_WRITE_DSN")
+
+	env.DbBoltPath = os.Getenv("BOLT_DB_PATH")
+	if env.DbBoltPath == "" {
+		env.DbBoltPath = os.Getenv(candihelper.WORKDIR) + "candi.db"
+	}
+}
+
+func parseStorageEnv() {
+	env.Storage.Driver = os.Getenv("STORAGE_DRIVER")
+	env.Storage.Endpoint = os.Getenv("STORAGE_ENDPOINT")
+	env.Storage.Region = os.Getenv("STORAGE_REGION")
+	env.Storage.Bucket = os.Getenv("STORAGE_BUCKET")
+	env.Storage.AccessKey = os.Getenv("STORAGE_ACCESS_KEY")
+	env.Storage.SecretKey = os.Getenv("STORAGE_SECRET_KEY")
+	env.Storage.BaseURL = os.Getenv("STORAGE_BASE_URL")
+	env.Storage.LocalPath = os.Getenv("STORAGE_LOCAL_PATH")
+	if env.Storage.LocalPath == "" {
+		env.Storage.LocalPath = os.Getenv(candihelper.WORKDIR) + "storage"
+	}
+}
+
+func parseSearchEngineEnv() {
+	if addresses := os.Getenv("SEARCH_ENGINE_ADDRESSES"); addresses != "" {
+		env.SearchEngine.Addresses = strings.Split(addresses, ",")
+	}
+	env.SearchEngine.Username = os.Getenv("SEARCH_ENGINE_USERNAME")
+	env.SearchEngine.Password = os.Getenv("SEARCH_ENGINE_PASSWORD")
+	env.SearchEngine.APIKey = os.Getenv("SEARCH_ENGINE_API_KEY")
 }
 
 func parseCorsEnv() {
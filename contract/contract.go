@@ -0,0 +1,270 @@
+// Package contract message contract testing between publisher and consumer (Pact-style).
+// Publisher record contract (json schema inferred from published message and examples) per topic with Recorder,
+// consumer declare expected contract per topic, then Verify check consumer contract against publisher contract
+// (run "candi contract check" in CI). Consumer handler can reject message violating declared contract in strict mode.
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/validator"
+	"github.com/golangid/gojsonschema"
+)
+
+const (
+	// PublishedDir subdirectory of contract directory for recorded publisher contract
+	PublishedDir = "published"
+	// ConsumedDir subdirectory of contract directory for declared consumer contract
+	ConsumedDir = "consumed"
+)
+
+// Contract message contract of topic, stored in "{topic}.json" file
+type Contract struct {
+	Topic       string            `json:"topic"`
+	Description string            `json:"description,omitempty"`
+	Schema      map[string]any    `json:"schema"`
+	Examples    []json.RawMessage `json:"examples,omitempty"`
+
+	compileOnce sync.Once
+	compiled    *gojsonschema.Schema
+	compileErr  error
+}
+
+// Validate validate message with contract schema, return *validator.ValidationError for invalid message
+func (c *Contract) Validate(message []byte) error {
+	c.compileOnce.Do(func() {
+		c.compiled, c.compileErr = gojsonschema.NewSchema(gojsonschema.NewGoLoader(c.Schema))
+	})
+	if c.compileErr != nil {
+		return fmt.Errorf("contract %s: invalid schema: %w", c.Topic, c.compileErr)
+	}
+
+	result, err := c.compiled.Validate(gojsonschema.NewBytesLoader(message))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+	validationErr := validator.NewValidationError()
+	for _, desc := range result.Errors() {
+		field := desc.Field()
+		if desc.Type() == "required" {
+			field = strings.TrimPrefix(fmt.Sprintf("%s.%s", field, desc.Details()["property"]), "(root).")
+		}
+		validationErr.Append(field, errors.New(desc.Description()))
+	}
+	return validationErr
+}
+
+// Load load all contract ("*.json" file) in directory, map key is topic
+func Load(dir string) (map[string]*Contract, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	contracts := make(map[string]*Contract, len(files))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var c Contract
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("contract %s: %w", file, err)
+		}
+		if c.Topic == "" {
+			c.Topic = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		if c.Schema == nil {
+			return nil, fmt.Errorf("contract %s: missing schema", file)
+		}
+		contracts[c.Topic] = &c
+	}
+	return contracts, nil
+}
+
+// Save write contracts to directory, one file for each topic
+func Save(dir string, contracts ...*Contract) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, c := range contracts {
+		b, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, fileName(c.Topic)), append(b, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileName(topic string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(topic) + ".json"
+}
+
+// InferSchema infer json schema from json message, object field is required and number is integer
+// if field always exist and integer in all given messages
+func InferSchema(messages ...[]byte) (schema map[string]any, err error) {
+	for _, message := range messages {
+		var v any
+		if err := json.Unmarshal(message, &v); err != nil {
+			return nil, err
+		}
+		schema = mergeSchema(schema, inferValue(v))
+	}
+	return schema, nil
+}
+
+func inferValue(v any) map[string]any {
+	switch val := v.(type) {
+	case map[string]any:
+		properties := make(map[string]any, len(val))
+		required := make([]string, 0, len(val))
+		for k, field := range val {
+			properties[k] = inferValue(field)
+			required = append(required, k)
+		}
+		sort.Strings(required)
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case []any:
+		schema := map[string]any{"type": "array"}
+		var items map[string]any
+		for _, item := range val {
+			items = mergeSchema(items, inferValue(item))
+		}
+		if items != nil {
+			schema["items"] = items
+		}
+		return schema
+	case string:
+		return map[string]any{"type": "string"}
+	case float64:
+		if val == math.Trunc(val) {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	}
+	return map[string]any{"type": "null"}
+}
+
+func mergeSchema(a, b map[string]any) map[string]any {
+	if a == nil {
+		return b
+	}
+	types := append(append([]string{}, schemaTypes(a)...), schemaTypes(b)...)
+	merged := map[string]any{}
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+	merged["type"] = normalizeTypes(types)
+
+	propsA, okA := a["properties"].(map[string]any)
+	propsB, okB := b["properties"].(map[string]any)
+	if okA && okB {
+		properties := make(map[string]any, len(propsA))
+		for k, v := range propsA {
+			properties[k] = v
+		}
+		for k, v := range propsB {
+			prop, _ := properties[k].(map[string]any)
+			properties[k] = mergeSchema(prop, v.(map[string]any))
+		}
+		merged["properties"] = properties
+
+		requiredB := stringSet(b["required"])
+		required := []string{}
+		for _, field := range toStrings(a["required"]) {
+			if _, ok := requiredB[field]; ok {
+				required = append(required, field)
+			}
+		}
+		delete(merged, "required")
+		if len(required) > 0 {
+			merged["required"] = required
+		}
+	}
+
+	itemsA, okA := a["items"].(map[string]any)
+	itemsB, okB := b["items"].(map[string]any)
+	if okA && okB {
+		merged["items"] = mergeSchema(itemsA, itemsB)
+	}
+	return merged
+}
+
+func normalizeTypes(types []string) any {
+	set := map[string]struct{}{}
+	for _, t := range types {
+		set[t] = struct{}{}
+	}
+	if _, ok := set["number"]; ok {
+		delete(set, "integer")
+	}
+	if len(set) == 1 {
+		for t := range set {
+			return t
+		}
+	}
+	list := make([]string, 0, len(set))
+	for t := range set {
+		list = append(list, t)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		return toStrings(t)
+	}
+	return nil
+}
+
+func toStrings(v any) (list []string) {
+	switch val := v.(type) {
+	case []string:
+		return val
+	case []any:
+		for _, s := range val {
+			if str, ok := s.(string); ok {
+				list = append(list, str)
+			}
+		}
+	}
+	return list
+}
+
+func stringSet(v any) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, s := range toStrings(v) {
+		set[s] = struct{}{}
+	}
+	return set
+}
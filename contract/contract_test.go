@@ -0,0 +1,70 @@
+package contract
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndVerify(t *testing.T) {
+	broker := testkit.NewBroker(types.Kafka)
+	recorder := NewRecorder()
+	publisher := recorder.Wrap(broker)
+
+	ctx := context.Background()
+	publisher.PublishMessage(ctx, &candishared.PublisherArgument{Topic: "order-created", Message: []byte(`{"id":1,"total":10.5,"items":[{"sku":"A"}],"note":"x"}`)})
+	publisher.PublishMessage(ctx, &candishared.PublisherArgument{Topic: "order-created", Message: []byte(`{"id":2,"total":20,"items":[]}`)})
+	assert.Len(t, broker.Messages("order-created"), 2)
+
+	dir := t.TempDir()
+	assert.NoError(t, recorder.Save(dir))
+	published, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, published["order-created"].Examples, 2)
+	assert.Equal(t, []any{"id", "items", "total"}, published["order-created"].Schema["required"])
+
+	consumed := map[string]*Contract{
+		"order-created": {Topic: "order-created", Schema: map[string]any{
+			"type":     "object",
+			"required": []any{"id", "total"},
+			"properties": map[string]any{
+				"id": map[string]any{"type": "integer"}, "total": map[string]any{"type": "number"},
+			},
+		}},
+	}
+	assert.Empty(t, Verify(published, consumed))
+
+	consumed["order-created"].Schema["required"] = []any{"id", "note"}
+	consumed["order-created"].Schema["properties"].(map[string]any)["id"] = map[string]any{"type": "string"}
+	consumed["payment-paid"] = &Contract{Topic: "payment-paid", Schema: map[string]any{"type": "object"}}
+	violations := Verify(published, consumed)
+	var messages []string
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	assert.Contains(t, messages, `order-created: note: required by consumer but optional in publisher`)
+	assert.Contains(t, messages, `order-created: id: published type "integer" is not accepted by consumer type string`)
+	assert.Contains(t, messages, `payment-paid: topic is not published`)
+}
+
+func TestStrict(t *testing.T) {
+	c := &Contract{Topic: "order-created", Schema: map[string]any{
+		"type": "object", "required": []any{"id"}, "properties": map[string]any{"id": map[string]any{"type": "integer"}},
+	}}
+
+	var called int
+	handler := StrictWorkerHandlerFunc(c, func(*candishared.EventContext) error { called++; return nil })
+	assert.NoError(t, handler(testkit.NewEventContext().WithMessage([]byte(`{"id":1}`)).Build()))
+	assert.Error(t, handler(testkit.NewEventContext().WithMessage([]byte(`{"id":"1"}`)).Build()))
+	assert.Equal(t, 1, called)
+
+	broker := testkit.NewBroker(types.Kafka)
+	publisher := NewStrictPublisher(broker, map[string]*Contract{c.Topic: c})
+	assert.Error(t, publisher.PublishMessage(context.Background(), &candishared.PublisherArgument{Topic: c.Topic, Message: []byte(`{}`)}))
+	assert.NoError(t, publisher.PublishMessage(context.Background(), &candishared.PublisherArgument{Topic: "other", Message: []byte(`{}`)}))
+	assert.Len(t, broker.Messages(), 1)
+}
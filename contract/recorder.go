@@ -0,0 +1,91 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// Recorder record published json message per topic as publisher contract, use in publisher test then save
+// recorded contract to "{CONTRACT_DIR}/published" directory (commit or publish it as CI artifact for consumer)
+type Recorder struct {
+	mu          sync.Mutex
+	maxExamples int
+	messages    map[string][][]byte
+	examples    map[string][]json.RawMessage
+}
+
+// NewRecorder create contract recorder, keep at most maxExamples distinct examples per topic (default 3)
+func NewRecorder(maxExamples ...int) *Recorder {
+	r := &Recorder{
+		maxExamples: 3,
+		messages:    make(map[string][][]byte),
+		examples:    make(map[string][]json.RawMessage),
+	}
+	if len(maxExamples) > 0 {
+		r.maxExamples = maxExamples[0]
+	}
+	return r
+}
+
+// Record record message of topic, non json message is ignored
+func (r *Recorder) Record(topic string, message []byte) {
+	if !json.Valid(message) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[topic] = append(r.messages[topic], append([]byte(nil), message...))
+	if len(r.examples[topic]) >= r.maxExamples {
+		return
+	}
+	for _, example := range r.examples[topic] {
+		if bytes.Equal(example, message) {
+			return
+		}
+	}
+	r.examples[topic] = append(r.examples[topic], json.RawMessage(append([]byte(nil), message...)))
+}
+
+// Wrap wrap publisher for record all published message
+func (r *Recorder) Wrap(publisher interfaces.Publisher) interfaces.Publisher {
+	return &recordPublisher{recorder: r, publisher: publisher}
+}
+
+// Contracts recorded contracts sorted by topic
+func (r *Recorder) Contracts() []*Contract {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contracts := make([]*Contract, 0, len(r.messages))
+	for topic, messages := range r.messages {
+		schema, _ := InferSchema(messages...)
+		contracts = append(contracts, &Contract{Topic: topic, Schema: schema, Examples: r.examples[topic]})
+	}
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i].Topic < contracts[j].Topic })
+	return contracts
+}
+
+// Save write recorded contracts to directory, existing contract of recorded topic is replaced
+func (r *Recorder) Save(dir string) error {
+	return Save(dir, r.Contracts()...)
+}
+
+type recordPublisher struct {
+	recorder  *Recorder
+	publisher interfaces.Publisher
+}
+
+func (p *recordPublisher) PublishMessage(ctx context.Context, args *candishared.PublisherArgument) error {
+	if err := p.publisher.PublishMessage(ctx, args); err != nil {
+		return err
+	}
+	p.recorder.Record(args.Topic, args.Message)
+	return nil
+}
@@ -0,0 +1,87 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+)
+
+var (
+	consumedContracts     map[string]*Contract
+	consumedContractsOnce sync.Once
+)
+
+// ConsumedContracts declared consumer contracts from "{CONTRACT_DIR}/consumed" directory, loaded once
+func ConsumedContracts() map[string]*Contract {
+	consumedContractsOnce.Do(func() {
+		contracts, err := Load(filepath.Join(env.BaseEnv().ContractDir, ConsumedDir))
+		if err != nil {
+			logger.LogRed("contract: " + err.Error())
+		}
+		consumedContracts = contracts
+	})
+	return consumedContracts
+}
+
+// ApplyStrictMode reject message violating declared consumer contract (from ConsumedContracts) in handler group
+// when CONTRACT_STRICT is enabled, call after mount handlers
+func ApplyStrictMode(group *types.WorkerHandlerGroup) {
+	if !env.BaseEnv().ContractStrict {
+		return
+	}
+	contracts := ConsumedContracts()
+	for i := range group.Handlers {
+		handler := &group.Handlers[i]
+		if c, ok := contracts[handler.Pattern]; ok && len(handler.HandlerFuncs) > 0 {
+			handler.HandlerFuncs[0] = StrictWorkerHandlerFunc(c, handler.HandlerFuncs[0])
+		}
+	}
+}
+
+// StrictWorkerHandlerFunc wrap worker handler func, message violating contract is rejected with validation error
+// without calling handler (after handlers still called with the error)
+func StrictWorkerHandlerFunc(c *Contract, handlerFunc types.WorkerHandlerFunc) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		if err := c.Validate(eventContext.Message()); err != nil {
+			return fmt.Errorf("message violates contract %s: %w", c.Topic, err)
+		}
+		return handlerFunc(eventContext)
+	}
+}
+
+// WorkerHandlerOptionStrict worker handler option for always reject message violating contract (regardless CONTRACT_STRICT),
+// use as last option in handler group Add
+func WorkerHandlerOptionStrict(c *Contract) types.WorkerHandlerOptionFunc {
+	return func(wh *types.WorkerHandler) {
+		if len(wh.HandlerFuncs) > 0 {
+			wh.HandlerFuncs[0] = StrictWorkerHandlerFunc(c, wh.HandlerFuncs[0])
+		}
+	}
+}
+
+// NewStrictPublisher wrap publisher for reject published message violating publisher contract of topic,
+// message of topic without contract is published as is
+func NewStrictPublisher(publisher interfaces.Publisher, contracts map[string]*Contract) interfaces.Publisher {
+	return &strictPublisher{publisher: publisher, contracts: contracts}
+}
+
+type strictPublisher struct {
+	publisher interfaces.Publisher
+	contracts map[string]*Contract
+}
+
+func (p *strictPublisher) PublishMessage(ctx context.Context, args *candishared.PublisherArgument) error {
+	if c, ok := p.contracts[args.Topic]; ok {
+		if err := c.Validate(args.Message); err != nil {
+			return fmt.Errorf("message violates contract %s: %w", c.Topic, err)
+		}
+	}
+	return p.publisher.PublishMessage(ctx, args)
+}
@@ -0,0 +1,115 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Violation incompatibility between consumer contract and publisher contract
+type Violation struct {
+	Topic   string
+	Field   string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return fmt.Sprintf("%s: %s", v.Topic, v.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", v.Topic, v.Field, v.Message)
+}
+
+// Verify verify consumer contracts against publisher contracts, consumer contract is compatible if all publisher
+// examples are valid with consumer schema, and every field required by consumer is always published with accepted type
+func Verify(published, consumed map[string]*Contract) (violations []Violation) {
+	topics := make([]string, 0, len(consumed))
+	for topic := range consumed {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	for _, topic := range topics {
+		consumer := consumed[topic]
+		publisher, ok := published[topic]
+		if !ok {
+			violations = append(violations, Violation{Topic: topic, Message: "topic is not published"})
+			continue
+		}
+
+		for i, example := range publisher.Examples {
+			if err := consumer.Validate(example); err != nil {
+				violations = append(violations, Violation{
+					Topic: topic, Message: fmt.Sprintf("published example #%d rejected by consumer: %s", i+1, err.Error()),
+				})
+			}
+		}
+		checkCompatible(topic, "", consumer.Schema, publisher.Schema, &violations)
+	}
+	return violations
+}
+
+func checkCompatible(topic, field string, consumer, publisher map[string]any, violations *[]Violation) {
+	if consumerTypes := schemaTypes(consumer); len(consumerTypes) > 0 {
+		accepted := make(map[string]struct{}, len(consumerTypes))
+		for _, t := range consumerTypes {
+			accepted[t] = struct{}{}
+		}
+		for _, t := range schemaTypes(publisher) {
+			_, ok := accepted[t]
+			if _, isNumber := accepted["number"]; t == "integer" && isNumber {
+				ok = true
+			}
+			if !ok {
+				*violations = append(*violations, Violation{
+					Topic: topic, Field: fieldName(field),
+					Message: fmt.Sprintf("published type %q is not accepted by consumer type %s", t, strings.Join(consumerTypes, "|")),
+				})
+			}
+		}
+	}
+
+	consumerProps, _ := consumer["properties"].(map[string]any)
+	publisherProps, _ := publisher["properties"].(map[string]any)
+	publisherRequired := stringSet(publisher["required"])
+	for _, name := range toStrings(consumer["required"]) {
+		if _, ok := publisherProps[name]; !ok {
+			*violations = append(*violations, Violation{Topic: topic, Field: joinField(field, name), Message: "required by consumer but never published"})
+		} else if _, ok := publisherRequired[name]; !ok {
+			*violations = append(*violations, Violation{Topic: topic, Field: joinField(field, name), Message: "required by consumer but optional in publisher"})
+		}
+	}
+
+	names := make([]string, 0, len(consumerProps))
+	for name := range consumerProps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		consumerProp, _ := consumerProps[name].(map[string]any)
+		publisherProp, ok := publisherProps[name].(map[string]any)
+		if ok && consumerProp != nil {
+			checkCompatible(topic, joinField(field, name), consumerProp, publisherProp, violations)
+		}
+	}
+
+	consumerItems, _ := consumer["items"].(map[string]any)
+	publisherItems, ok := publisher["items"].(map[string]any)
+	if ok && consumerItems != nil {
+		checkCompatible(topic, field+"[]", consumerItems, publisherItems, violations)
+	}
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func fieldName(field string) string {
+	if field == "" {
+		return "(root)"
+	}
+	return field
+}
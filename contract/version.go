@@ -0,0 +1,215 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+)
+
+const (
+	// HeaderSchemaVersion default header key of message schema version
+	HeaderSchemaVersion = "x-schema-version"
+	// FieldSchemaVersion default json field of message schema version, used when header is not set
+	FieldSchemaVersion = "schema_version"
+)
+
+type (
+	// ShimFunc convert message from a schema version to another
+	ShimFunc func(message []byte) ([]byte, error)
+
+	// VersionRouterOptionFunc type
+	VersionRouterOptionFunc func(*VersionRouter)
+
+	// VersionRouter route message of a topic to handler of message schema version (blue/green handler versioning),
+	// message of version without handler is converted through registered shim to nearest version with handler
+	VersionRouter struct {
+		headerKey      string
+		field          string
+		defaultVersion string
+
+		mu       sync.RWMutex
+		active   string
+		handlers map[string]types.WorkerHandlerFunc
+		shims    map[string]map[string]ShimFunc
+	}
+)
+
+// VersionRouterSetHeaderKey option func, header key of schema version (default "x-schema-version")
+func VersionRouterSetHeaderKey(headerKey string) VersionRouterOptionFunc {
+	return func(r *VersionRouter) {
+		r.headerKey = headerKey
+	}
+}
+
+// VersionRouterSetField option func, top level json field of schema version (default "schema_version"),
+// empty for disable reading version from message
+func VersionRouterSetField(field string) VersionRouterOptionFunc {
+	return func(r *VersionRouter) {
+		r.field = field
+	}
+}
+
+// VersionRouterSetDefault option func, version of message without version header/field (default first handled version)
+func VersionRouterSetDefault(version string) VersionRouterOptionFunc {
+	return func(r *VersionRouter) {
+		r.defaultVersion = version
+	}
+}
+
+// VersionRouterSetActive option func, see VersionRouter.SetActive
+func VersionRouterSetActive(version string) VersionRouterOptionFunc {
+	return func(r *VersionRouter) {
+		r.active = version
+	}
+}
+
+// NewVersionRouter create message schema version router
+func NewVersionRouter(opts ...VersionRouterOptionFunc) *VersionRouter {
+	r := &VersionRouter{
+		headerKey: HeaderSchemaVersion,
+		field:     FieldSchemaVersion,
+		handlers:  make(map[string]types.WorkerHandlerFunc),
+		shims:     make(map[string]map[string]ShimFunc),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Handle register handler of schema version
+func (r *VersionRouter) Handle(version string, handlerFunc types.WorkerHandlerFunc) *VersionRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.defaultVersion == "" {
+		r.defaultVersion = version
+	}
+	r.handlers[version] = handlerFunc
+	return r
+}
+
+// AddShim register compatibility shim for convert message from a version to another (upcast or downcast)
+func (r *VersionRouter) AddShim(from, to string, shim ShimFunc) *VersionRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.shims[from] == nil {
+		r.shims[from] = make(map[string]ShimFunc)
+	}
+	r.shims[from][to] = shim
+	return r
+}
+
+// SetActive switch active handler version at runtime, all message is converted through shim to active version
+// (fallback to handler of message version if no shim path), empty for route by message version
+func (r *VersionRouter) SetActive(version string) {
+	r.mu.Lock()
+	r.active = version
+	r.mu.Unlock()
+}
+
+// HandlerFunc worker handler func for handler group, example: group.Add("orders", router.HandlerFunc())
+func (r *VersionRouter) HandlerFunc() types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		version := r.messageVersion(eventContext)
+
+		r.mu.RLock()
+		active := r.active
+		path := r.findPath(version, func(v string) bool { return v == active })
+		if path == nil {
+			path = r.findPath(version, func(v string) bool { return r.handlers[v] != nil })
+		}
+		var handlerFunc types.WorkerHandlerFunc
+		var shims []ShimFunc
+		if path != nil {
+			handlerFunc = r.handlers[path[len(path)-1]]
+			for i := 1; i < len(path); i++ {
+				shims = append(shims, r.shims[path[i-1]][path[i]])
+			}
+		}
+		r.mu.RUnlock()
+		if handlerFunc == nil {
+			return fmt.Errorf("no handler for schema version %q of %s", version, eventContext.HandlerRoute())
+		}
+		if len(shims) == 0 {
+			return handlerFunc(eventContext)
+		}
+
+		message := eventContext.Message()
+		for i, shim := range shims {
+			converted, err := shim(message)
+			if err != nil {
+				return fmt.Errorf("shim schema version %q to %q: %w", path[i], path[i+1], err)
+			}
+			message = converted
+		}
+		return handlerFunc(r.convertedEventContext(eventContext, path[len(path)-1], message))
+	}
+}
+
+func (r *VersionRouter) messageVersion(eventContext *candishared.EventContext) string {
+	if version := eventContext.Header()[r.headerKey]; version != "" {
+		return version
+	}
+	if r.field != "" {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(eventContext.Message(), &fields) == nil {
+			if raw, ok := fields[r.field]; ok {
+				if version := strings.Trim(string(raw), `"`); version != "" && version != "null" {
+					return version
+				}
+			}
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultVersion
+}
+
+// findPath shortest shim path (include from version) to version accepted by target, must be called with read lock held
+func (r *VersionRouter) findPath(from string, target func(version string) bool) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		version := queue[0]
+		queue = queue[1:]
+		if target(version) && r.handlers[version] != nil {
+			path := []string{version}
+			for v := version; v != from; v = prev[v] {
+				path = append([]string{prev[v]}, path...)
+			}
+			return path
+		}
+		for next := range r.shims[version] {
+			if _, visited := prev[next]; !visited {
+				prev[next] = version
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *VersionRouter) convertedEventContext(eventContext *candishared.EventContext, version string, message []byte) *candishared.EventContext {
+	header := maps.Clone(eventContext.Header())
+	if header == nil {
+		header = make(map[string]string)
+	}
+	header[r.headerKey] = version
+
+	converted := candishared.NewEventContext(bytes.NewBuffer(make([]byte, 0, len(message))))
+	converted.SetContext(eventContext.Context())
+	converted.SetWorkerType(eventContext.WorkerType())
+	converted.SetHandlerRoute(eventContext.HandlerRoute())
+	converted.SetKey(eventContext.Key())
+	converted.SetHeader(header)
+	converted.SetCloudEvent(eventContext.CloudEvent())
+	converted.SetHeartbeatFunc(eventContext.Heartbeat)
+	converted.Write(message)
+	return converted
+}
@@ -0,0 +1,54 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionRouter(t *testing.T) {
+	var handled []string
+	router := NewVersionRouter().
+		Handle("1", func(eventContext *candishared.EventContext) error {
+			handled = append(handled, "v1:"+string(eventContext.Message()))
+			return nil
+		}).
+		Handle("2", func(eventContext *candishared.EventContext) error {
+			handled = append(handled, "v2:"+eventContext.Header()[HeaderSchemaVersion]+":"+string(eventContext.Message()))
+			return nil
+		}).
+		AddShim("1", "2", func(message []byte) ([]byte, error) {
+			return bytes.Replace(message, []byte("name"), []byte("full_name"), 1), nil
+		}).
+		AddShim("2", "1", func(message []byte) ([]byte, error) {
+			return bytes.Replace(message, []byte("full_name"), []byte("name"), 1), nil
+		}).
+		AddShim("0", "1", func(message []byte) ([]byte, error) { return nil, errors.New("unsupported") })
+	handlerFunc := router.HandlerFunc()
+
+	newEventContext := func(header map[string]string, message string) *candishared.EventContext {
+		eventContext := candishared.NewEventContext(&bytes.Buffer{})
+		eventContext.SetContext(context.Background())
+		eventContext.SetHandlerRoute("users")
+		eventContext.SetHeader(header)
+		eventContext.WriteString(message)
+		return eventContext
+	}
+
+	assert.NoError(t, handlerFunc(newEventContext(nil, `{"name":"a"}`)))
+	assert.NoError(t, handlerFunc(newEventContext(nil, `{"schema_version":2,"full_name":"b"}`)))
+	assert.NoError(t, handlerFunc(newEventContext(map[string]string{HeaderSchemaVersion: "1"}, `{"name":"c"}`)))
+	assert.Equal(t, []string{`v1:{"name":"a"}`, `v2::{"schema_version":2,"full_name":"b"}`, `v1:{"name":"c"}`}, handled)
+
+	handled = nil
+	router.SetActive("2")
+	assert.NoError(t, handlerFunc(newEventContext(nil, `{"name":"a"}`)))
+	assert.Equal(t, []string{`v2:2:{"full_name":"a"}`}, handled)
+
+	assert.ErrorContains(t, handlerFunc(newEventContext(map[string]string{HeaderSchemaVersion: "0"}, `{}`)), "unsupported")
+	assert.ErrorContains(t, handlerFunc(newEventContext(map[string]string{HeaderSchemaVersion: "3"}, `{}`)), `no handler for schema version "3"`)
+}
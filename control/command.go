@@ -0,0 +1,81 @@
+// Package control runtime control plane of running service, registered command (list cron job, pause kafka topic
+// consumer, flush cache namespace, toggle feature flag) is exposed in admin API over local unix socket and
+// invoked with "candi ctl" command, without redeploy or exposing public endpoint
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrCommandNotFound command is not registered
+	ErrCommandNotFound = errors.New("control: command not found")
+	// ErrInvalidArgument required argument is missing or argument value is invalid
+	ErrInvalidArgument = errors.New("control: invalid argument")
+
+	registryMu sync.RWMutex
+	registry   = make(map[string]Command)
+)
+
+// Command runtime admin command
+type Command struct {
+	// Name command name, prefixed with resource (example "kafka.pause")
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Args name of required arguments
+	Args    []string                                                                  `json:"args,omitempty"`
+	Handler func(ctx context.Context, args map[string]string) (result any, err error) `json:"-"`
+}
+
+// Register add command to global registry, replace registered command with the same name
+func Register(commands ...Command) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, cmd := range commands {
+		if cmd.Name == "" || cmd.Handler == nil {
+			panic("control: command name and handler is required")
+		}
+		registry[cmd.Name] = cmd
+	}
+}
+
+// Unregister remove command from global registry
+func Unregister(names ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, name := range names {
+		delete(registry, name)
+	}
+}
+
+// Commands list registered command sorted by name
+func Commands() []Command {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	commands := make([]Command, 0, len(registry))
+	for _, cmd := range registry {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+// Exec execute registered command, return ErrInvalidArgument when required argument is empty
+func Exec(ctx context.Context, name string, args map[string]string) (any, error) {
+	registryMu.RLock()
+	cmd, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCommandNotFound, name)
+	}
+	for _, arg := range cmd.Args {
+		if args[arg] == "" {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidArgument, arg)
+		}
+	}
+	return cmd.Handler(ctx, args)
+}
@@ -0,0 +1,149 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/codebase/factory/types"
+	mockfactory "github.com/golangid/candi/mocks/codebase/factory"
+	mockdeps "github.com/golangid/candi/mocks/codebase/factory/dependency"
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	Register(Command{Name: "test.echo", Args: []string{"value"}, Handler: func(ctx context.Context, args map[string]string) (any, error) {
+		return args["value"], nil
+	}})
+	defer Unregister("test.echo")
+
+	result, err := Exec(context.Background(), "test.echo", map[string]string{"value": "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+
+	_, err = Exec(context.Background(), "test.echo", nil)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = Exec(context.Background(), "test.unknown", nil)
+	assert.ErrorIs(t, err, ErrCommandNotFound)
+
+	DefineFlag("new-checkout", false, "use new checkout flow")
+	assert.False(t, FlagEnabled("new-checkout"))
+	_, err = Exec(context.Background(), "flag.set", map[string]string{"name": "new-checkout", "enabled": "true"})
+	require.NoError(t, err)
+	assert.True(t, FlagEnabled("new-checkout"))
+	DefineFlag("new-checkout", false, "use new checkout flow")
+	assert.True(t, FlagEnabled("new-checkout"), "value is kept when defined again")
+	assert.ErrorIs(t, SetFlag("unknown", true), ErrInvalidArgument)
+	assert.False(t, FlagEnabled("unknown"))
+}
+
+func TestServer(t *testing.T) {
+	cache := mockinterfaces.NewCache(t)
+	cache.On("GetKeys", mock.Anything, "product:").Return([]string{"product:1", "product:2"}, nil).Once()
+	cache.On("Delete", mock.Anything, mock.Anything).Return(nil).Twice()
+	redisPool := mockinterfaces.NewRedisPool(t)
+	redisPool.On("Cache").Return(cache)
+	deps := mockdeps.NewDependency(t)
+	deps.On("GetRedisPool").Return(redisPool)
+	deps.On("GetBoltDatabase").Return(nil)
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("test"))
+	service.On("GetDependency").Return(deps)
+
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "test.sock")
+	server := NewServer(service, SetSocketPath(socketPath))
+	go server.Serve()
+	defer server.Shutdown(context.Background())
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}, Timeout: 5 * time.Second}
+	exec := func(body string) (int, map[string]any) {
+		resp, err := client.Post("http://control/exec", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var response map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	resp, err := client.Get("http://control/commands")
+	require.NoError(t, err)
+	var commands struct{ Data []Command }
+	json.NewDecoder(resp.Body).Decode(&commands)
+	resp.Body.Close()
+	var names []string
+	for _, cmd := range commands.Data {
+		names = append(names, cmd.Name)
+	}
+	assert.Subset(t, names, []string{"cache.flush", "cache.list", "flag.list", "flag.set"})
+
+	status, response := exec(`{"command":"cache.flush","args":{"namespace":"product:"}}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"cache": "redis", "namespace": "product:", "deleted": float64(2)}, response["data"])
+
+	cache.On("GetKeys", mock.Anything, `\*`).Return(nil, nil).Once()
+	status, response = exec(`{"command":"cache.flush","args":{"namespace":"*"}}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(0), response["data"].(map[string]any)["deleted"], "glob in namespace matched literally")
+
+	status, _ = exec(`{"command":"cache.flush","args":{"namespace":"product:","cache":"unknown"}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = exec(`{"command":"unknown"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	Register(Command{Name: "test.fail", Handler: func(ctx context.Context, args map[string]string) (any, error) {
+		return nil, errors.New("broker unavailable")
+	}})
+	defer Unregister("test.fail")
+	status, response = exec(`{"command":"test.fail"}`)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "broker unavailable", response["message"])
+}
+
+func TestServerSocketHandoff(t *testing.T) {
+	service := mockfactory.NewServiceFactory(t)
+	service.On("Name").Return(types.Service("test"))
+	service.On("GetDependency").Return(nil)
+
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "test.sock")
+
+	parent := NewServer(service, SetSocketPath(socketPath))
+	go parent.Serve()
+	child := NewServer(service, SetSocketPath(socketPath))
+	go child.Serve()
+
+	// draining parent must not remove socket of new process
+	parent.Shutdown(context.Background())
+	_, err = os.Stat(socketPath)
+	assert.NoError(t, err)
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	conn.Close()
+
+	child.Shutdown(context.Background())
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,83 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Flag runtime feature flag
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+var (
+	flagMu sync.RWMutex
+	flags  = make(map[string]*Flag)
+)
+
+func init() {
+	Register(
+		Command{Name: "flag.list", Description: "List feature flags", Handler: func(ctx context.Context, args map[string]string) (any, error) {
+			return Flags(), nil
+		}},
+		Command{Name: "flag.set", Description: "Toggle feature flag", Args: []string{"name", "enabled"}, Handler: func(ctx context.Context, args map[string]string) (any, error) {
+			enabled, err := strconv.ParseBool(args["enabled"])
+			if err != nil {
+				return nil, fmt.Errorf("%w: enabled must be boolean", ErrInvalidArgument)
+			}
+			if err := SetFlag(args["name"], enabled); err != nil {
+				return nil, err
+			}
+			return Flag{Name: args["name"], Enabled: enabled}, nil
+		}},
+	)
+}
+
+// DefineFlag register feature flag with initial value, flag can be toggled at runtime with "flag.set" command.
+// Value of defined flag is kept when flag is defined again
+func DefineFlag(name string, enabled bool, description string) {
+	flagMu.Lock()
+	defer flagMu.Unlock()
+	if flag, ok := flags[name]; ok {
+		flag.Description = description
+		return
+	}
+	flags[name] = &Flag{Name: name, Description: description, Enabled: enabled}
+}
+
+// FlagEnabled current value of feature flag, false if flag is not defined
+func FlagEnabled(name string) bool {
+	flagMu.RLock()
+	defer flagMu.RUnlock()
+	flag, ok := flags[name]
+	return ok && flag.Enabled
+}
+
+// SetFlag set value of defined feature flag
+func SetFlag(name string, enabled bool) error {
+	flagMu.Lock()
+	defer flagMu.Unlock()
+	flag, ok := flags[name]
+	if !ok {
+		return fmt.Errorf("%w: flag %s is not defined", ErrInvalidArgument, name)
+	}
+	flag.Enabled = enabled
+	return nil
+}
+
+// Flags list defined feature flag sorted by name
+func Flags() []Flag {
+	flagMu.RLock()
+	defer flagMu.RUnlock()
+	list := make([]Flag, 0, len(flags))
+	for _, flag := range flags {
+		list = append(list, *flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,208 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	candicache "github.com/golangid/candi/cache"
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/wrapper"
+)
+
+type (
+	// ExecRequest request body of exec admin API
+	ExecRequest struct {
+		Command string            `json:"command"`
+		Args    map[string]string `json:"args,omitempty"`
+	}
+
+	// OptionFunc type
+	OptionFunc func(*Server)
+
+	// Server control plane admin API over unix socket, socket file is only accessible by owner of process
+	Server struct {
+		socketPath  string
+		caches      map[string]interfaces.Cache
+		execTimeout time.Duration
+
+		httpServer *http.Server
+		listener   net.Listener
+		socketFile os.FileInfo
+	}
+)
+
+// SetSocketPath option func, path of unix socket (default is DefaultSocketPath of service name)
+func SetSocketPath(socketPath string) OptionFunc {
+	return func(s *Server) {
+		s.socketPath = socketPath
+	}
+}
+
+// AddCache option func, add cache which namespace can be flushed with "cache.flush" command (default redis cache
+// of service dependency with name "redis")
+func AddCache(name string, cache interfaces.Cache) OptionFunc {
+	return func(s *Server) {
+		s.caches[name] = cache
+	}
+}
+
+// SetExecTimeout option func, timeout of command execution (default 30 seconds)
+func SetExecTimeout(timeout time.Duration) OptionFunc {
+	return func(s *Server) {
+		s.execTimeout = timeout
+	}
+}
+
+// DefaultSocketPath default unix socket path of service, used by "candi ctl -service {name}"
+func DefaultSocketPath(serviceName string) string {
+	return filepath.Join(os.TempDir(), "candi-"+serviceName+".sock")
+}
+
+// NewServer create control plane server and bind unix socket, socket file of previous process (stale or still
+// draining in graceful restart) is replaced
+func NewServer(service factory.ServiceFactory, opts ...OptionFunc) *Server {
+	s := &Server{
+		socketPath:  DefaultSocketPath(string(service.Name())),
+		caches:      make(map[string]interfaces.Cache),
+		execTimeout: 30 * time.Second,
+	}
+	if deps := service.GetDependency(); deps != nil && deps.GetRedisPool() != nil {
+		s.caches["redis"] = deps.GetRedisPool().Cache()
+	}
+	if deps := service.GetDependency(); deps != nil && deps.GetBoltDatabase() != nil {
+		s.caches["bolt"] = deps.GetBoltDatabase().Cache()
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.caches) > 0 {
+		Register(s.cacheCommands()...)
+	}
+
+	os.Remove(s.socketPath)
+	var err error
+	if s.listener, err = net.Listen("unix", s.socketPath); err != nil {
+		log.Panicf("Control Server: listen unix socket %s: %v", s.socketPath, err)
+	}
+	// socket path may be taken over by new process in graceful restart, only remove socket file created by this process
+	s.listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(s.socketPath, 0600); err != nil {
+		s.listener.Close()
+		log.Panicf("Control Server: chmod unix socket %s: %v", s.socketPath, err)
+	}
+	s.socketFile, _ = os.Stat(s.socketPath)
+	s.httpServer = &http.Server{Handler: s.HTTPHandler(), ReadHeaderTimeout: 10 * time.Second}
+	fmt.Printf("\x1b[34;1m⇨ Control server running with %d commands on unix socket %s\x1b[0m\n\n", len(Commands()), s.socketPath)
+	return s
+}
+
+// Serve admin API
+func (s *Server) Serve() {
+	if err := s.httpServer.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Panicf("Control Server: Unexpected Error: %v", err)
+	}
+}
+
+// Shutdown admin API and remove socket file if it has not been replaced by other process (graceful restart)
+func (s *Server) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mStopping Control Server:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), strings.Repeat(" ", 20))
+	}()
+	s.httpServer.Shutdown(ctx)
+	if current, err := os.Stat(s.socketPath); err == nil && s.socketFile != nil && os.SameFile(s.socketFile, current) {
+		os.Remove(s.socketPath)
+	}
+}
+
+// Name server name
+func (s *Server) Name() string {
+	return "control"
+}
+
+// SocketPath path of unix socket
+func (s *Server) SocketPath() string {
+	return s.socketPath
+}
+
+// HTTPHandler admin API, GET /commands list registered command and POST /exec execute command with ExecRequest body
+func (s *Server) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/commands", func(rw http.ResponseWriter, req *http.Request) {
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", Commands()).JSON(rw)
+	})
+	mux.HandleFunc("/exec", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(rw)
+			return
+		}
+		var payload ExecRequest
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Invalid request body", err).JSON(rw)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), s.execTimeout)
+		defer cancel()
+		result, err := Exec(ctx, payload.Command, payload.Args)
+		switch {
+		case errors.Is(err, ErrCommandNotFound):
+			wrapper.NewHTTPResponse(http.StatusNotFound, err.Error()).JSON(rw)
+		case errors.Is(err, ErrInvalidArgument):
+			wrapper.NewHTTPResponse(http.StatusBadRequest, err.Error()).JSON(rw)
+		case err != nil:
+			wrapper.NewHTTPResponse(http.StatusInternalServerError, err.Error()).JSON(rw)
+		default:
+			wrapper.NewHTTPResponse(http.StatusOK, "Success execute "+payload.Command, result).JSON(rw)
+		}
+	})
+	return mux
+}
+
+func (s *Server) cacheCommands() []Command {
+	names := make([]string, 0, len(s.caches))
+	for name := range s.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return []Command{
+		{Name: "cache.list", Description: "List cache name", Handler: func(ctx context.Context, args map[string]string) (any, error) {
+			return names, nil
+		}},
+		{Name: "cache.flush", Description: `Delete all key with namespace prefix (arg "cache" is cache name, default first cache)`,
+			Args: []string{"namespace"}, Handler: func(ctx context.Context, args map[string]string) (any, error) {
+				name := args["cache"]
+				if name == "" {
+					name = names[0]
+				}
+				cache, ok := s.caches[name]
+				if !ok {
+					return nil, fmt.Errorf("%w: cache %s is not registered", ErrInvalidArgument, name)
+				}
+				// namespace matched literally, glob character in namespace must not match other key
+				keys, err := cache.GetKeys(ctx, candicache.EscapePattern(args["namespace"]))
+				if err != nil {
+					return nil, err
+				}
+				for _, key := range keys {
+					if err := cache.Delete(ctx, key); err != nil {
+						return nil, err
+					}
+				}
+				return map[string]any{"cache": name, "namespace": args["namespace"], "deleted": len(keys)}, nil
+			}},
+	}
+}
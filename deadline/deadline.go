@@ -0,0 +1,179 @@
+// Package deadline request timeout and deadline propagation, timeout configured per route (REST) or method (gRPC),
+// remaining budget propagated to downstream HTTP call (header), gRPC call (native deadline) and published message
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/wrapper"
+	"google.golang.org/grpc"
+)
+
+const (
+	// HeaderBudget header of remaining budget in milliseconds for downstream HTTP call
+	HeaderBudget = "X-Request-Budget"
+	// HeaderDeadline header of absolute deadline in unix milliseconds for published message
+	HeaderDeadline = "X-Deadline"
+)
+
+// ErrBudgetExhausted deadline of request has been exceeded before handled
+var ErrBudgetExhausted = candierrors.New(candierrors.CodeTimeout, "request deadline exceeded")
+
+// Config declarative timeout config
+type Config struct {
+	// Default timeout of route without specific timeout, zero is no timeout (only incoming budget applied)
+	Default time.Duration
+	// Routes timeout per route, key is "METHOD /path" for REST (path support glob pattern, example "GET /v1/orders/*")
+	// or full method for gRPC (example "/order.OrderService/GetOrder" or "/order.OrderService/*")
+	Routes map[string]time.Duration
+}
+
+// Timeout get timeout of route key, exact match or longest matched glob pattern
+func (c *Config) Timeout(key string) time.Duration {
+	if timeout, ok := c.Routes[key]; ok {
+		return timeout
+	}
+	patterns := make([]string, 0, len(c.Routes))
+	for pattern := range c.Routes {
+		if strings.Contains(pattern, "*") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return c.Routes[pattern]
+		}
+	}
+	return c.Default
+}
+
+// Remaining get remaining budget of context deadline
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// WithTimeout derive context with the shortest of timeout and incoming budget, zero value is ignored
+func WithTimeout(ctx context.Context, timeout, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget != 0 && (timeout <= 0 || budget < timeout) {
+		timeout = budget
+	}
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// InjectHeader set remaining budget of context deadline in HTTP header
+func InjectHeader(ctx context.Context, header map[string]string) {
+	if remaining, ok := Remaining(ctx); ok {
+		header[HeaderBudget] = strconv.FormatInt(max(remaining.Milliseconds(), 1), 10)
+	}
+}
+
+// InjectMessageHeader set absolute deadline of context in message header, not override existing deadline
+func InjectMessageHeader(ctx context.Context, header map[string]any) {
+	if _, ok := header[HeaderDeadline]; ok {
+		return
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		header[HeaderDeadline] = strconv.FormatInt(deadline.UnixMilli(), 10)
+	}
+}
+
+// FromHeader get remaining budget from header (budget or absolute deadline), header key is case insensitive
+func FromHeader(header map[string]string) (budget time.Duration, ok bool) {
+	for key, value := range header {
+		switch {
+		case strings.EqualFold(key, HeaderBudget):
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return time.Duration(ms) * time.Millisecond, true
+			}
+		case strings.EqualFold(key, HeaderDeadline):
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return time.Until(time.UnixMilli(ms)), true
+			}
+		}
+	}
+	return 0, false
+}
+
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// HTTPMiddleware apply timeout of route and incoming budget (header X-Request-Budget) to request context, respond
+// 504 Gateway Timeout if budget already exhausted or handler return without response after deadline exceeded.
+// Handler must respect context cancellation
+func HTTPMiddleware(cfg Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var budget time.Duration
+			if value := req.Header.Get(HeaderBudget); value != "" {
+				ms, _ := strconv.ParseInt(value, 10, 64)
+				if ms <= 0 {
+					writeTimeout(w)
+					return
+				}
+				budget = time.Duration(ms) * time.Millisecond
+			}
+
+			ctx, cancel := WithTimeout(req.Context(), cfg.Timeout(req.Method+" "+req.URL.Path), budget)
+			defer cancel()
+
+			tw := &timeoutResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(tw, req.WithContext(ctx))
+			if ctx.Err() == context.DeadlineExceeded && !tw.wroteHeader {
+				writeTimeout(w)
+			}
+		})
+	}
+}
+
+func writeTimeout(w http.ResponseWriter) {
+	wrapper.NewHTTPResponseFromError(http.StatusGatewayTimeout, "Request timeout", ErrBudgetExhausted).JSON(w)
+}
+
+// GRPCUnaryInterceptor apply timeout of method to request context (incoming deadline from client is kept if shorter),
+// return DEADLINE_EXCEEDED if deadline exceeded
+func GRPCUnaryInterceptor(cfg Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if ctx.Err() != nil {
+			return nil, candierrors.ToGRPCStatus(ErrBudgetExhausted)
+		}
+		ctx, cancel := WithTimeout(ctx, cfg.Timeout(info.FullMethod), 0)
+		defer cancel()
+
+		resp, err = handler(ctx, req)
+		if err == nil && resp == nil && ctx.Err() == context.DeadlineExceeded {
+			err = ErrBudgetExhausted
+		}
+		return resp, candierrors.ToGRPCStatus(err)
+	}
+}
@@ -0,0 +1,83 @@
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfigTimeout(t *testing.T) {
+	cfg := Config{Default: 5 * time.Second, Routes: map[string]time.Duration{
+		"GET /v1/orders/*":                time.Second,
+		"GET /v1/orders/*/items":          2 * time.Second,
+		"POST /v1/orders":                 3 * time.Second,
+		"/order.OrderService/*":           4 * time.Second,
+		"/order.OrderService/CreateOrder": 10 * time.Second,
+	}}
+	assert.Equal(t, time.Second, cfg.Timeout("GET /v1/orders/1"))
+	assert.Equal(t, 2*time.Second, cfg.Timeout("GET /v1/orders/1/items"))
+	assert.Equal(t, 3*time.Second, cfg.Timeout("POST /v1/orders"))
+	assert.Equal(t, 4*time.Second, cfg.Timeout("/order.OrderService/GetOrder"))
+	assert.Equal(t, 10*time.Second, cfg.Timeout("/order.OrderService/CreateOrder"))
+	assert.Equal(t, 5*time.Second, cfg.Timeout("DELETE /v1/orders"))
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	var downstreamHeader map[string]string
+	handler := HTTPMiddleware(Config{Routes: map[string]time.Duration{"GET /slow": 20 * time.Millisecond}})(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			downstreamHeader = map[string]string{}
+			InjectHeader(req.Context(), downstreamHeader)
+			if req.URL.Path == "/slow" {
+				<-req.Context().Done()
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Contains(t, rec.Body.String(), "request deadline exceeded")
+
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	req.Header.Set(HeaderBudget, "1000")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	budget, _ := strconv.Atoi(downstreamHeader[HeaderBudget])
+	assert.True(t, budget > 900 && budget <= 1000)
+
+	req = httptest.NewRequest(http.MethodGet, "/fast", nil)
+	req.Header.Set(HeaderBudget, "0")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+}
+
+func TestGRPCUnaryInterceptor(t *testing.T) {
+	interceptor := GRPCUnaryInterceptor(Config{Default: 10 * time.Millisecond})
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/GetOrder"},
+		func(ctx context.Context, req any) (any, error) {
+			<-ctx.Done()
+			return nil, nil
+		})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	header := map[string]any{}
+	InjectMessageHeader(ctx, header)
+	budget, ok := FromHeader(map[string]string{"x-deadline": header[HeaderDeadline].(string)})
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute.Seconds(), budget.Seconds(), 1)
+}
@@ -0,0 +1,142 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/logger"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// GRPCScheme target scheme of gRPC resolver, example target "discovery:///payment-service"
+	GRPCScheme = "discovery"
+	// HTTPHostSuffix host suffix resolved by HTTP transport, example url "http://payment-service.discovery/v1/payment"
+	HTTPHostSuffix = ".discovery"
+)
+
+type grpcResolverBuilder struct {
+	registry        Registry
+	refreshInterval time.Duration
+}
+
+// NewGRPCResolver gRPC resolver of target "discovery:///{service}", resolved addresses refreshed every refreshInterval
+// (default 30 seconds). Use with grpc.WithResolvers dial option, example in managed gRPC client:
+//
+//	candiutils.GRPCClientOptionDialOptions(grpc.WithResolvers(discovery.NewGRPCResolver(registry, 0)))
+func NewGRPCResolver(registry Registry, refreshInterval time.Duration) resolver.Builder {
+	if refreshInterval <= 0 {
+		refreshInterval = 30 * time.Second
+	}
+	return &grpcResolverBuilder{registry: registry, refreshInterval: refreshInterval}
+}
+
+func (b *grpcResolverBuilder) Scheme() string {
+	return GRPCScheme
+}
+
+func (b *grpcResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &grpcResolver{
+		registry: b.registry, service: strings.TrimPrefix(target.Endpoint(), "/"), cc: cc,
+		cancel: cancel, resolveNow: make(chan struct{}, 1),
+	}
+	r.wg.Add(1)
+	go r.watch(ctx, b.refreshInterval)
+	return r, nil
+}
+
+type grpcResolver struct {
+	registry   Registry
+	service    string
+	cc         resolver.ClientConn
+	cancel     func()
+	resolveNow chan struct{}
+	wg         sync.WaitGroup
+}
+
+func (r *grpcResolver) watch(ctx context.Context, refreshInterval time.Duration) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		r.resolve(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+func (r *grpcResolver) resolve(ctx context.Context) {
+	instances, err := r.registry.Resolve(ctx, r.service)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.LogE("discovery: resolve " + r.service + ": " + err.Error())
+			r.cc.ReportError(err)
+		}
+		return
+	}
+	addresses := make([]resolver.Address, 0, len(instances))
+	for _, instance := range instances {
+		addresses = append(addresses, resolver.Address{Addr: instance.HostPort()})
+	}
+	if len(addresses) == 0 {
+		r.cc.ReportError(ErrNoInstance)
+		return
+	}
+	r.cc.UpdateState(resolver.State{Addresses: addresses})
+}
+
+func (r *grpcResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *grpcResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+type httpTransport struct {
+	base     http.RoundTripper
+	resolver *cachedResolver
+}
+
+// NewHTTPTransport http transport resolve request with host "{service}.discovery" to instance of service in registry
+// (round-robin, resolved instances cached for cacheTTL, default 10 seconds). Example:
+//
+//	httpClient := &http.Client{Transport: discovery.NewHTTPTransport(registry, nil, 0)}
+//	httpClient.Get("http://payment-service.discovery/v1/payment")
+func NewHTTPTransport(registry Registry, base http.RoundTripper, cacheTTL time.Duration) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = 10 * time.Second
+	}
+	return &httpTransport{base: base, resolver: newCachedResolver(registry, cacheTTL)}
+}
+
+func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, ok := strings.CutSuffix(req.URL.Hostname(), HTTPHostSuffix)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	instance, err := t.resolver.pick(req.Context(), service)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Host = instance.HostPort()
+	req.Host = service
+	return t.base.RoundTrip(req)
+}
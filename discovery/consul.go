@@ -0,0 +1,174 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	consulRegistry struct {
+		address    string
+		token      string
+		httpClient *http.Client
+
+		mu         sync.Mutex
+		registered map[string]struct{}
+	}
+
+	// ConsulOptionFunc option func of consul registry
+	ConsulOptionFunc func(*consulRegistry)
+)
+
+// ConsulSetToken option func, ACL token of consul
+func ConsulSetToken(token string) ConsulOptionFunc {
+	return func(c *consulRegistry) {
+		c.token = token
+	}
+}
+
+// ConsulSetHTTPClient option func
+func ConsulSetHTTPClient(httpClient *http.Client) ConsulOptionFunc {
+	return func(c *consulRegistry) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewConsul registry with Consul agent HTTP API, address example "http://127.0.0.1:8500"
+func NewConsul(address string, opts ...ConsulOptionFunc) Registry {
+	c := &consulRegistry{
+		address:    strings.TrimSuffix(address, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		registered: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *consulRegistry) Register(ctx context.Context, registration Registration) error {
+	id := registration.instanceID()
+	payload := map[string]any{
+		"ID": id, "Name": registration.Name, "Address": registration.Address, "Port": registration.Port,
+		"Tags": registration.Tags, "Meta": registration.Meta,
+	}
+	if registration.HealthCheckURL != "" {
+		interval := registration.HealthCheckInterval
+		if interval <= 0 {
+			interval = 10 * time.Second
+		}
+		payload["Check"] = map[string]any{
+			"HTTP": registration.HealthCheckURL, "Interval": interval.String(), "Timeout": "5s",
+			"DeregisterCriticalServiceAfter": "1m",
+		}
+	}
+	if err := c.do(ctx, http.MethodPut, "/v1/agent/service/register", payload, nil); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.registered[id] = struct{}{}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *consulRegistry) Deregister(ctx context.Context, id string) error {
+	if err := c.do(ctx, http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(id), nil, nil); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.registered, id)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *consulRegistry) Resolve(ctx context.Context, name string) ([]Instance, error) {
+	var entries []struct {
+		Node struct {
+			Address string `json:"Address"`
+		} `json:"Node"`
+		Service struct {
+			ID      string            `json:"ID"`
+			Service string            `json:"Service"`
+			Address string            `json:"Address"`
+			Port    int               `json:"Port"`
+			Tags    []string          `json:"Tags"`
+			Meta    map[string]string `json:"Meta"`
+		} `json:"Service"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/health/service/"+url.PathEscape(name)+"?passing=true", nil, &entries); err != nil {
+		return nil, err
+	}
+
+	instances := make([]Instance, 0, len(entries))
+	for _, entry := range entries {
+		instance := Instance{
+			ID: entry.Service.ID, Name: entry.Service.Service, Address: entry.Service.Address, Port: entry.Service.Port,
+			Tags: entry.Service.Tags, Meta: entry.Service.Meta,
+		}
+		if instance.Address == "" {
+			instance.Address = entry.Node.Address
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// Disconnect deregister all registered instance
+func (c *consulRegistry) Disconnect(ctx context.Context) error {
+	c.mu.Lock()
+	ids := make([]string, 0, len(c.registered))
+	for id := range c.registered {
+		ids = append(ids, id)
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for _, id := range ids {
+		if err := c.Deregister(ctx, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (c *consulRegistry) do(ctx context.Context, method, path string, payload, result any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, body)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("discovery: consul: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("discovery: consul: %s %s: %s %s", method, path, resp.Status, msg)
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package discovery service discovery integration, register running service to registry (Consul) or rely on
+// Kubernetes endpoints, and resolve target of gRPC client (resolver) and HTTP client (transport) from registry
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNoInstance no healthy instance of service found in registry
+var ErrNoInstance = errors.New("discovery: no healthy instance")
+
+type (
+	// Registration running service to register
+	Registration struct {
+		// ID unique id of instance, default is name-address-port
+		ID      string
+		Name    string
+		Address string
+		Port    int
+		Tags    []string
+		Meta    map[string]string
+		// HealthCheckURL http endpoint checked by registry, example "http://10.0.0.1:8000/health"
+		HealthCheckURL      string
+		HealthCheckInterval time.Duration
+	}
+
+	// Instance resolved instance of service
+	Instance struct {
+		ID      string
+		Name    string
+		Address string
+		Port    int
+		Tags    []string
+		Meta    map[string]string
+	}
+
+	// Registry service registry
+	Registry interface {
+		// Register running service instance, registered instance is deregistered on Disconnect
+		Register(ctx context.Context, registration Registration) error
+		Deregister(ctx context.Context, id string) error
+		// Resolve healthy instances of service
+		Resolve(ctx context.Context, name string) ([]Instance, error)
+		Disconnect(ctx context.Context) error
+	}
+)
+
+// HostPort address of instance in host:port format
+func (i *Instance) HostPort() string {
+	return net.JoinHostPort(i.Address, strconv.Itoa(i.Port))
+}
+
+func (r *Registration) instanceID() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return r.Name + "-" + r.Address + "-" + strconv.Itoa(r.Port)
+}
+
+// cachedResolver cache resolved instances for ttl and pick instance with round-robin
+type cachedResolver struct {
+	registry Registry
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cachedInstances
+}
+
+type cachedInstances struct {
+	instances []Instance
+	expiredAt time.Time
+	next      int
+}
+
+func newCachedResolver(registry Registry, ttl time.Duration) *cachedResolver {
+	return &cachedResolver{registry: registry, ttl: ttl, entries: make(map[string]*cachedInstances)}
+}
+
+// pick next instance of service
+func (c *cachedResolver) pick(ctx context.Context, name string) (Instance, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+
+	if !ok || time.Now().After(entry.expiredAt) {
+		instances, err := c.registry.Resolve(ctx, name)
+		if err != nil && !ok {
+			return Instance{}, err
+		}
+		if err == nil {
+			c.mu.Lock()
+			entry = &cachedInstances{instances: instances, expiredAt: time.Now().Add(c.ttl)}
+			c.entries[name] = entry
+			c.mu.Unlock()
+		}
+		// keep stale instances when registry unavailable
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(entry.instances) == 0 {
+		return Instance{}, ErrNoInstance
+	}
+	instance := entry.instances[entry.next%len(entry.instances)]
+	entry.next++
+	return instance, nil
+}
@@ -0,0 +1,97 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsul(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Host + req.URL.Path))
+	}))
+	defer backend.Close()
+	host, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	backendPort, _ := strconv.Atoi(port)
+
+	var mu sync.Mutex
+	services := map[string]map[string]any{}
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case req.URL.Path == "/v1/agent/service/register":
+			var payload map[string]any
+			json.NewDecoder(req.Body).Decode(&payload)
+			services[payload["ID"].(string)] = payload
+		case req.URL.Path == "/v1/agent/service/deregister/payment-1":
+			delete(services, "payment-1")
+		case req.URL.Path == "/v1/health/service/payment-service":
+			var entries []map[string]any
+			for _, service := range services {
+				entries = append(entries, map[string]any{"Service": map[string]any{
+					"ID": service["ID"], "Service": service["Name"], "Address": service["Address"], "Port": service["Port"],
+				}})
+			}
+			json.NewEncoder(w).Encode(entries)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer consul.Close()
+
+	ctx := context.Background()
+	registry := NewConsul(consul.URL)
+	require.NoError(t, registry.Register(ctx, Registration{
+		ID: "payment-1", Name: "payment-service", Address: host, Port: backendPort, HealthCheckURL: backend.URL + "/health",
+	}))
+	assert.Equal(t, "10s", services["payment-1"]["Check"].(map[string]any)["Interval"])
+
+	instances, err := registry.Resolve(ctx, "payment-service")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, backend.Listener.Addr().String(), instances[0].HostPort())
+
+	httpClient := &http.Client{Transport: NewHTTPTransport(registry, nil, 0)}
+	resp, err := httpClient.Get("http://payment-service.discovery/v1/payment")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var body [64]byte
+	n, _ := resp.Body.Read(body[:])
+	assert.Equal(t, "payment-service/v1/payment", string(body[:n]))
+
+	require.NoError(t, registry.Disconnect(ctx))
+	assert.Empty(t, services)
+}
+
+func TestKubernetes(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		if req.URL.Path != "/api/v1/namespaces/payment/endpoints/payment-service" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"subsets":[{"addresses":[{"ip":"10.0.0.1","targetRef":{"name":"payment-abc"}},{"ip":"10.0.0.2"}],
+			"ports":[{"name":"http","port":8000},{"name":"grpc","port":8002}]}]}`))
+	}))
+	defer apiServer.Close()
+
+	registry, err := NewKubernetes(KubernetesSetAPIServer(apiServer.URL, "token", nil), KubernetesSetPortName("grpc"))
+	require.NoError(t, err)
+	instances, err := registry.Resolve(context.Background(), "payment-service.payment")
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	assert.Equal(t, "payment-abc", instances[0].ID)
+	assert.Equal(t, "10.0.0.2:8002", instances[1].HostPort())
+
+	_, err = registry.Resolve(context.Background(), "payment-service")
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const kubernetesServiceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount/"
+
+type (
+	kubernetesRegistry struct {
+		apiServer  string
+		token      string
+		namespace  string
+		portName   string
+		httpClient *http.Client
+	}
+
+	// KubernetesOptionFunc option func of kubernetes registry
+	KubernetesOptionFunc func(*kubernetesRegistry)
+)
+
+// KubernetesSetNamespace option func, default namespace of pod (from service account)
+func KubernetesSetNamespace(namespace string) KubernetesOptionFunc {
+	return func(k *kubernetesRegistry) {
+		k.namespace = namespace
+	}
+}
+
+// KubernetesSetPortName option func, resolve endpoint port with name, default first port
+func KubernetesSetPortName(portName string) KubernetesOptionFunc {
+	return func(k *kubernetesRegistry) {
+		k.portName = portName
+	}
+}
+
+// KubernetesSetAPIServer option func, set api server address and bearer token (default in cluster config)
+func KubernetesSetAPIServer(apiServer, token string, httpClient *http.Client) KubernetesOptionFunc {
+	return func(k *kubernetesRegistry) {
+		k.apiServer, k.token, k.httpClient = strings.TrimSuffix(apiServer, "/"), token, httpClient
+	}
+}
+
+// NewKubernetes registry with Kubernetes endpoints API (service account need get permission of endpoints), service
+// registration is managed by Kubernetes (Register and Deregister is no-op). Resolve name "service" in default namespace
+// or "service.namespace"
+func NewKubernetes(opts ...KubernetesOptionFunc) (Registry, error) {
+	k := &kubernetesRegistry{}
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); host != "" {
+		k.apiServer = "https://" + net.JoinHostPort(host, port)
+		token, _ := os.ReadFile(kubernetesServiceAccountPath + "token")
+		k.token = strings.TrimSpace(string(token))
+		namespace, _ := os.ReadFile(kubernetesServiceAccountPath + "namespace")
+		k.namespace = strings.TrimSpace(string(namespace))
+		if ca, err := os.ReadFile(kubernetesServiceAccountPath + "ca.crt"); err == nil {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(ca)
+			k.httpClient = &http.Client{
+				Timeout:   10 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+			}
+		}
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	if k.apiServer == "" {
+		return nil, errors.New("discovery: kubernetes: not running in cluster and api server is not set")
+	}
+	if k.namespace == "" {
+		k.namespace = "default"
+	}
+	if k.httpClient == nil {
+		k.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return k, nil
+}
+
+func (k *kubernetesRegistry) Register(ctx context.Context, registration Registration) error {
+	return nil
+}
+
+func (k *kubernetesRegistry) Deregister(ctx context.Context, id string) error { return nil }
+
+func (k *kubernetesRegistry) Disconnect(ctx context.Context) error { return nil }
+
+func (k *kubernetesRegistry) Resolve(ctx context.Context, name string) ([]Instance, error) {
+	namespace := k.namespace
+	if service, ns, ok := strings.Cut(name, "."); ok {
+		name, namespace = service, ns
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.apiServer+"/api/v1/namespaces/"+namespace+"/endpoints/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: kubernetes: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery: kubernetes: get endpoints %s/%s: %s", namespace, name, resp.Status)
+	}
+
+	var endpoints struct {
+		Subsets []struct {
+			Addresses []struct {
+				IP        string `json:"ip"`
+				TargetRef struct {
+					Name string `json:"name"`
+				} `json:"targetRef"`
+			} `json:"addresses"`
+			Ports []struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			} `json:"ports"`
+		} `json:"subsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return nil, err
+	}
+
+	var instances []Instance
+	for _, subset := range endpoints.Subsets {
+		port := 0
+		for _, p := range subset.Ports {
+			if k.portName == "" || p.Name == k.portName {
+				port = p.Port
+				break
+			}
+		}
+		if port == 0 {
+			continue
+		}
+		for _, address := range subset.Addresses {
+			instances = append(instances, Instance{ID: address.TargetRef.Name, Name: name, Address: address.IP, Port: port})
+		}
+	}
+	return instances, nil
+}
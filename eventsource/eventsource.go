@@ -0,0 +1,94 @@
+// Package eventsource event sourcing building blocks for CQRS service: append only event store with optimistic concurrency
+// (memory, SQL and Mongo backend), and projection worker for maintain read models from event store (or kafka) with checkpoint.
+package eventsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// AnyVersion expected version for append without concurrency check
+const AnyVersion = -1
+
+var (
+	// ErrConcurrency stream version is not same with expected version (stream is appended by another process)
+	ErrConcurrency = errors.New("eventsource: stream version conflict")
+)
+
+// Event stored event
+type Event struct {
+	StreamID string `json:"stream_id"`
+	// Version version of event in stream, start from 1
+	Version int `json:"version"`
+	// Position global position in store, increased by append order
+	Position  int64             `json:"position"`
+	Type      string            `json:"type"`
+	Data      json.RawMessage   `json:"data"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// NewEvent create event with data marshaled to json, stream id and version is set when appended
+func NewEvent(eventType string, data any, metadata ...map[string]string) (event Event, err error) {
+	event.Type = eventType
+	if event.Data, err = json.Marshal(data); err != nil {
+		return event, err
+	}
+	if len(metadata) > 0 {
+		event.Metadata = metadata[0]
+	}
+	return event, nil
+}
+
+// Bind unmarshal event data to target
+func (e Event) Bind(target any) error {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, target)
+}
+
+// Store event store
+type Store interface {
+	// Append events to end of stream, expectedVersion is current version of stream (0 for new stream, AnyVersion for skip check),
+	// return ErrConcurrency if stream version is not same with expectedVersion. Return appended events with version and position
+	Append(ctx context.Context, streamID string, expectedVersion int, events ...Event) ([]Event, error)
+	// ReadStream events of stream with version greater than fromVersion
+	ReadStream(ctx context.Context, streamID string, fromVersion int) ([]Event, error)
+	// ReadAll events of all streams with position greater than fromPosition sorted by position
+	ReadAll(ctx context.Context, fromPosition int64, limit int) ([]Event, error)
+}
+
+// Load read all events of stream and apply to aggregate, return current version of stream (expected version for next append)
+func Load(ctx context.Context, store Store, streamID string, apply func(event Event) error) (version int, err error) {
+	events, err := store.ReadStream(ctx, streamID, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		if err := apply(event); err != nil {
+			return version, err
+		}
+		version = event.Version
+	}
+	return version, nil
+}
+
+// prepareEvents set stream id, version and position of appended events
+func prepareEvents(streamID string, version int, position int64, events []Event) []Event {
+	now := time.Now()
+	prepared := make([]Event, len(events))
+	for i, event := range events {
+		event.StreamID, event.Version, event.Position = streamID, version+i+1, position+int64(i)+1
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = now
+		}
+		prepared[i] = event
+	}
+	return prepared
+}
+
+// maxAppendAttempts retry append when global position is taken by concurrent append in another stream
+const maxAppendAttempts = 5
@@ -0,0 +1,128 @@
+package eventsource
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	Total int `json:"total"`
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	created, _ := NewEvent("OrderCreated", orderCreated{Total: 100})
+	paid, _ := NewEvent("OrderPaid", nil, map[string]string{"user": "admin"})
+	appended, err := store.Append(ctx, "order-1", 0, created, paid)
+	require.NoError(t, err)
+	assert.Equal(t, 2, appended[1].Version)
+	assert.Equal(t, int64(2), appended[1].Position)
+
+	// stale expected version
+	_, err = store.Append(ctx, "order-1", 1, paid)
+	assert.ErrorIs(t, err, ErrConcurrency)
+
+	other, _ := NewEvent("OrderCreated", orderCreated{Total: 50})
+	appended, err = store.Append(ctx, "order-2", AnyVersion, other)
+	require.NoError(t, err)
+	assert.Equal(t, 1, appended[0].Version)
+	assert.Equal(t, int64(3), appended[0].Position)
+
+	var total int
+	var eventTypes []string
+	version, err := Load(ctx, store, "order-1", func(event Event) error {
+		eventTypes = append(eventTypes, event.Type)
+		if event.Type == "OrderCreated" {
+			var data orderCreated
+			event.Bind(&data)
+			total = data.Total
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, 100, total)
+	assert.Equal(t, []string{"OrderCreated", "OrderPaid"}, eventTypes)
+
+	events, _ := store.ReadAll(ctx, 1, 1)
+	require.Len(t, events, 1)
+	assert.Equal(t, "OrderPaid", events[0].Type)
+	events, _ = store.ReadAll(ctx, 3, 10)
+	assert.Empty(t, events)
+}
+
+func TestProjectionWorker(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	checkpoint := candiutils.NewMemoryCheckpoint()
+
+	var mu sync.Mutex
+	totals := map[string]int{}
+	failOnce := true
+	projection := ProjectionFunc("order-total", func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if event.StreamID == "order-2" && failOnce {
+			failOnce = false
+			return assert.AnError
+		}
+		var data orderCreated
+		event.Bind(&data)
+		totals[event.StreamID] += data.Total
+		return nil
+	})
+
+	for _, id := range []string{"order-1", "order-2", "order-3"} {
+		event, _ := NewEvent("OrderCreated", orderCreated{Total: 10})
+		_, err := store.Append(ctx, id, 0, event)
+		require.NoError(t, err)
+	}
+
+	worker := NewProjectionWorker(store, checkpoint, []Projection{projection},
+		SetPollInterval(10*time.Millisecond), SetBatchSize(2), SetDebugMode(false))
+	go worker.Serve()
+	defer worker.Shutdown(ctx)
+
+	assert.Eventually(t, func() bool {
+		position, _ := checkpoint.Load(ctx, "projection:order-total")
+		return position == "3"
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	// failed event retried without reapply previous events
+	assert.Equal(t, map[string]int{"order-1": 10, "order-2": 10, "order-3": 10}, totals)
+	mu.Unlock()
+}
+
+func TestPublishingStore(t *testing.T) {
+	ctx := context.Background()
+	broker := testkit.NewBroker(types.Kafka)
+	store := NewPublishingStore(NewMemoryStore(), broker, "order-events")
+
+	event, _ := NewEvent("OrderCreated", orderCreated{Total: 25})
+	_, err := store.Append(ctx, "order-1", 0, event)
+	require.NoError(t, err)
+
+	messages := broker.Messages("order-events")
+	require.Len(t, messages, 1)
+	assert.Equal(t, "order-1", messages[0].Key)
+
+	var total int
+	handler := ProjectionHandler(ProjectionFunc("order-total", func(ctx context.Context, event Event) error {
+		var data orderCreated
+		event.Bind(&data)
+		total += data.Total
+		return nil
+	}))
+	require.NoError(t, handler(testkit.NewEventContext().WithMessage(messages[0].Message).Build()))
+	assert.Equal(t, 25, total)
+}
@@ -0,0 +1,261 @@
+package eventsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+)
+
+// Projection maintain read model from events
+type Projection interface {
+	// Name unique projection name, used as checkpoint name
+	Name() string
+	// Handle apply event to read model, event is delivered at least once in position order so handler must be idempotent
+	Handle(ctx context.Context, event Event) error
+}
+
+// ProjectionFunc create projection from function
+func ProjectionFunc(name string, handle func(ctx context.Context, event Event) error) Projection {
+	return &projectionFunc{name: name, handle: handle}
+}
+
+type projectionFunc struct {
+	name   string
+	handle func(ctx context.Context, event Event) error
+}
+
+func (p *projectionFunc) Name() string { return p.name }
+func (p *projectionFunc) Handle(ctx context.Context, event Event) error {
+	return p.handle(ctx, event)
+}
+
+type (
+	projectionOption struct {
+		pollInterval time.Duration
+		batchSize    int
+		debugMode    bool
+	}
+
+	// ProjectionOptionFunc type
+	ProjectionOptionFunc func(*projectionOption)
+)
+
+// SetPollInterval option func, interval for tail event store when no new event (default 1 second)
+func SetPollInterval(interval time.Duration) ProjectionOptionFunc {
+	return func(o *projectionOption) {
+		o.pollInterval = interval
+	}
+}
+
+// SetBatchSize option func, max events read in one poll and checkpoint is saved after each batch (default 100)
+func SetBatchSize(size int) ProjectionOptionFunc {
+	return func(o *projectionOption) {
+		o.batchSize = size
+	}
+}
+
+// SetDebugMode option func
+func SetDebugMode(debugMode bool) ProjectionOptionFunc {
+	return func(o *projectionOption) {
+		o.debugMode = debugMode
+	}
+}
+
+type projectionWorker struct {
+	ctx           context.Context
+	ctxCancelFunc func()
+	wg            sync.WaitGroup
+
+	store       Store
+	checkpoint  candiutils.Checkpoint
+	projections []Projection
+	opt         projectionOption
+}
+
+// NewProjectionWorker create worker which tail event store and apply new events to each projection, last position of
+// each projection is saved to checkpoint (name "projection:{name}") so projection resume after restart.
+// When handler return error, projection is stopped at failed event and retried in next poll
+func NewProjectionWorker(store Store, checkpoint candiutils.Checkpoint, projections []Projection, opts ...ProjectionOptionFunc) factory.AppServerFactory {
+	w := &projectionWorker{
+		store: store, checkpoint: checkpoint, projections: projections,
+		opt: projectionOption{pollInterval: time.Second, batchSize: 100, debugMode: true},
+	}
+	for _, opt := range opts {
+		opt(&w.opt)
+	}
+	for _, p := range projections {
+		logger.LogYellow(fmt.Sprintf(`[EVENTSOURCE-PROJECTION] (name): "%s"`, p.Name()))
+	}
+	fmt.Printf("\x1b[34;1m⇨ Projection worker running with %d projections\x1b[0m\n\n", len(projections))
+	w.ctx, w.ctxCancelFunc = context.WithCancel(context.Background())
+	return w
+}
+
+func (w *projectionWorker) Serve() {
+	for _, p := range w.projections {
+		w.wg.Add(1)
+		go func(p Projection) {
+			defer w.wg.Done()
+			w.run(p)
+		}(p)
+	}
+	w.wg.Wait()
+}
+
+func (w *projectionWorker) Shutdown(ctx context.Context) {
+	defer func() {
+		fmt.Printf("\r%s \x1b[33;1mStopping Projection Worker:\x1b[0m \x1b[32;1mSUCCESS\x1b[0m%s\n",
+			time.Now().Format(candihelper.TimeFormatLogger), strings.Repeat(" ", 20))
+	}()
+	w.ctxCancelFunc()
+	w.wg.Wait()
+}
+
+func (w *projectionWorker) Name() string {
+	return "Event Sourcing Projection"
+}
+
+func (w *projectionWorker) run(p Projection) {
+	checkpointName := "projection:" + p.Name()
+	var position int64
+	for {
+		saved, err := w.checkpoint.Load(w.ctx, checkpointName)
+		if err == nil {
+			position, _ = strconv.ParseInt(saved, 10, 64)
+			break
+		}
+		logger.LogRed("projection > " + p.Name() + " load checkpoint: " + err.Error())
+		if !w.sleep() {
+			return
+		}
+	}
+
+	for {
+		last, processed, err := w.poll(p, position)
+		if last != position {
+			position = last
+			if err := w.checkpoint.Save(context.WithoutCancel(w.ctx), checkpointName, strconv.FormatInt(position, 10)); err != nil {
+				logger.LogRed("projection > " + p.Name() + " save checkpoint: " + err.Error())
+			}
+		}
+		if err != nil {
+			logger.LogRed(fmt.Sprintf("projection > %s at position %d: %s", p.Name(), position+1, err.Error()))
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+		// read next batch immediately when catching up
+		if err == nil && processed == w.opt.batchSize {
+			continue
+		}
+		if !w.sleep() {
+			return
+		}
+	}
+}
+
+// poll apply next batch of events, return position of last applied event
+func (w *projectionWorker) poll(p Projection, position int64) (last int64, processed int, err error) {
+	last = position
+	events, err := w.store.ReadAll(w.ctx, position, w.opt.batchSize)
+	if err != nil {
+		return last, 0, err
+	}
+	for _, event := range events {
+		if w.ctx.Err() != nil {
+			return last, processed, nil
+		}
+		if err := w.apply(p, event); err != nil {
+			return last, processed, err
+		}
+		last = event.Position
+		processed++
+	}
+	return last, processed, nil
+}
+
+func (w *projectionWorker) apply(p Projection, event Event) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(w.ctx, "EventSourcingProjection")
+	defer func() {
+		if r := recover(); r != nil {
+			trace.SetTag("panic", true)
+			err = candiutils.ReportPanic(ctx, "EventSourcingProjection", r, map[string]any{
+				"projection": p.Name(), "position": event.Position,
+			})
+		}
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+	trace.SetTag("projection", p.Name())
+	trace.SetTag("stream_id", event.StreamID)
+	trace.SetTag("event_type", event.Type)
+	trace.SetTag("position", event.Position)
+
+	if w.opt.debugMode {
+		logger.LogI(fmt.Sprintf("projection > %s: apply %s (stream: %s, position: %d)", p.Name(), event.Type, event.StreamID, event.Position))
+	}
+	return p.Handle(ctx, event)
+}
+
+func (w *projectionWorker) sleep() bool {
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-time.After(w.opt.pollInterval):
+		return true
+	}
+}
+
+// ProjectionHandler worker handler (kafka or other broker) for apply event message published by PublishingStore to projection,
+// offset of message is checkpoint of projection
+func ProjectionHandler(p Projection) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		var event Event
+		if err := json.Unmarshal(eventContext.Message(), &event); err != nil {
+			return err
+		}
+		return p.Handle(eventContext.Context(), event)
+	}
+}
+
+type publishingStore struct {
+	Store
+	publisher interfaces.Publisher
+	topic     string
+}
+
+// NewPublishingStore wrap store for publish appended events to topic (key is stream id, so events of stream keep order in partition).
+// Event is published after append success, failed publish is returned as error and appended events can be republished
+// from store with ReadAll
+func NewPublishingStore(store Store, publisher interfaces.Publisher, topic string) Store {
+	return &publishingStore{Store: store, publisher: publisher, topic: topic}
+}
+
+func (s *publishingStore) Append(ctx context.Context, streamID string, expectedVersion int, events ...Event) ([]Event, error) {
+	appended, err := s.Store.Append(ctx, streamID, expectedVersion, events...)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range appended {
+		message, _ := json.Marshal(event)
+		if err := s.publisher.PublishMessage(ctx, &candishared.PublisherArgument{
+			Topic: s.topic, Key: event.StreamID, Message: message, ContentType: candihelper.HeaderMIMEApplicationJSON,
+			Header: map[string]any{"event_type": event.Type},
+		}); err != nil {
+			return appended, fmt.Errorf("publish event %s (position %d): %w", event.Type, event.Position, err)
+		}
+	}
+	return appended, nil
+}
@@ -0,0 +1,60 @@
+package eventsource
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	events  []Event
+	streams map[string][]int
+}
+
+// NewMemoryStore in memory event store, events are lost when restart (for test or development only)
+func NewMemoryStore() Store {
+	return &memoryStore{streams: make(map[string][]int)}
+}
+
+func (s *memoryStore) Append(ctx context.Context, streamID string, expectedVersion int, events ...Event) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version := len(s.streams[streamID])
+	if expectedVersion != AnyVersion && expectedVersion != version {
+		return nil, ErrConcurrency
+	}
+	appended := prepareEvents(streamID, version, int64(len(s.events)), events)
+	for _, event := range appended {
+		s.streams[streamID] = append(s.streams[streamID], len(s.events))
+		s.events = append(s.events, event)
+	}
+	return appended, nil
+}
+
+func (s *memoryStore) ReadStream(ctx context.Context, streamID string, fromVersion int) (events []Event, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, idx := range s.streams[streamID] {
+		if s.events[idx].Version > fromVersion {
+			events = append(events, s.events[idx])
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryStore) ReadAll(ctx context.Context, fromPosition int64, limit int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if fromPosition < 0 {
+		fromPosition = 0
+	}
+	if fromPosition >= int64(len(s.events)) {
+		return nil, nil
+	}
+	events := s.events[fromPosition:]
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return append([]Event(nil), events...), nil
+}
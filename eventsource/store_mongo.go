@@ -0,0 +1,140 @@
+package eventsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoStore struct {
+	coll *mongo.Collection
+}
+
+type mongoEvent struct {
+	Position  int64             `bson:"_id"`
+	StreamID  string            `bson:"stream_id"`
+	Version   int               `bson:"version"`
+	Type      string            `bson:"type"`
+	Data      string            `bson:"data"`
+	Metadata  map[string]string `bson:"metadata,omitempty"`
+	CreatedAt time.Time         `bson:"created_at"`
+}
+
+// NewMongoStore event store in mongo collection (default "events"), append use multi document transaction
+// so mongo must be replica set. Unique index of stream id and version is created if not exist
+func NewMongoStore(ctx context.Context, db *mongo.Database, collection string) (Store, error) {
+	if collection == "" {
+		collection = "events"
+	}
+	s := &mongoStore{coll: db.Collection(collection)}
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "stream_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *mongoStore) Append(ctx context.Context, streamID string, expectedVersion int, events ...Event) (appended []Event, err error) {
+	session, err := s.coll.Database().Client().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	for attempt := 1; attempt <= maxAppendAttempts; attempt++ {
+		var version int
+		_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+			var err error
+			if version, err = s.streamVersion(sc, streamID); err != nil {
+				return nil, err
+			}
+			if expectedVersion != AnyVersion && expectedVersion != version {
+				return nil, ErrConcurrency
+			}
+			position, err := s.lastPosition(sc)
+			if err != nil {
+				return nil, err
+			}
+			appended = prepareEvents(streamID, version, position, events)
+			docs := make([]any, len(appended))
+			for i, event := range appended {
+				docs[i] = mongoEvent{
+					Position: event.Position, StreamID: event.StreamID, Version: event.Version, Type: event.Type,
+					Data: string(event.Data), Metadata: event.Metadata, CreatedAt: event.CreatedAt,
+				}
+			}
+			_, err = s.coll.InsertMany(sc, docs)
+			return nil, err
+		})
+		if err == nil || errors.Is(err, ErrConcurrency) || !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+		// duplicate key: conflict if stream is appended, else position is taken by another stream
+		if expectedVersion != AnyVersion {
+			if current, verr := s.streamVersion(ctx, streamID); verr == nil && current != version {
+				return nil, ErrConcurrency
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return appended, nil
+}
+
+func (s *mongoStore) streamVersion(ctx context.Context, streamID string) (int, error) {
+	var last mongoEvent
+	err := s.coll.FindOne(ctx, bson.M{"stream_id": streamID}, options.FindOne().SetSort(bson.M{"version": -1})).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return last.Version, err
+}
+
+func (s *mongoStore) lastPosition(ctx context.Context) (int64, error) {
+	var last mongoEvent
+	err := s.coll.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"_id": -1})).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return last.Position, err
+}
+
+func (s *mongoStore) ReadStream(ctx context.Context, streamID string, fromVersion int) ([]Event, error) {
+	return s.find(ctx, bson.M{"stream_id": streamID, "version": bson.M{"$gt": fromVersion}},
+		options.Find().SetSort(bson.M{"version": 1}))
+}
+
+func (s *mongoStore) ReadAll(ctx context.Context, fromPosition int64, limit int) ([]Event, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	return s.find(ctx, bson.M{"_id": bson.M{"$gt": fromPosition}}, opts)
+}
+
+func (s *mongoStore) find(ctx context.Context, filter bson.M, opts *options.FindOptions) (events []Event, err error) {
+	cur, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []mongoEvent
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		events = append(events, Event{
+			StreamID: doc.StreamID, Version: doc.Version, Position: doc.Position, Type: doc.Type,
+			Data: json.RawMessage(doc.Data), Metadata: doc.Metadata, CreatedAt: doc.CreatedAt,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,133 @@
+package eventsource
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golangid/candi/candishared"
+)
+
+type sqlStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(argIndex int) string
+}
+
+// NewSQLStore event store in sql table (postgres, mysql or sqlite3), table (default "events") is created if not exist.
+// Global position is assigned in append transaction with unique constraint, so position is gapless and visible in order
+func NewSQLStore(db *sql.DB, table string) (Store, error) {
+	if table == "" {
+		table = "events"
+	}
+	s := &sqlStore{db: db, table: table, placeholder: candishared.SQLPlaceholderOf(db)}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+		position BIGINT NOT NULL PRIMARY KEY,
+		stream_id VARCHAR(255) NOT NULL,
+		version INTEGER NOT NULL,
+		type VARCHAR(255) NOT NULL,
+		data TEXT,
+		metadata TEXT,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE (stream_id, version)
+	)`); err != nil {
+		return nil, fmt.Errorf("create event table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *sqlStore) Append(ctx context.Context, streamID string, expectedVersion int, events ...Event) (appended []Event, err error) {
+	for attempt := 1; attempt <= maxAppendAttempts; attempt++ {
+		var version int
+		appended, version, err = s.append(ctx, streamID, expectedVersion, events)
+		if err == nil || errors.Is(err, ErrConcurrency) {
+			return appended, err
+		}
+		// insert failed by unique constraint: conflict if stream is appended, else position is taken by another stream
+		if expectedVersion != AnyVersion {
+			if current, verr := s.streamVersion(ctx, s.db, streamID); verr == nil && current != version {
+				return nil, ErrConcurrency
+			}
+		}
+	}
+	return nil, err
+}
+
+func (s *sqlStore) append(ctx context.Context, streamID string, expectedVersion int, events []Event) (appended []Event, version int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if version, err = s.streamVersion(ctx, tx, streamID); err != nil {
+		return nil, version, err
+	}
+	if expectedVersion != AnyVersion && expectedVersion != version {
+		return nil, version, ErrConcurrency
+	}
+	var position int64
+	if err = tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(position), 0) FROM `+s.table).Scan(&position); err != nil {
+		return nil, version, err
+	}
+
+	appended = prepareEvents(streamID, version, position, events)
+	query := candishared.SQLRebind(`INSERT INTO `+s.table+` (position, stream_id, version, type, data, metadata, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`, s.placeholder)
+	for _, event := range appended {
+		metadata, _ := json.Marshal(event.Metadata)
+		if _, err = tx.ExecContext(ctx, query, event.Position, event.StreamID, event.Version, event.Type,
+			string(event.Data), string(metadata), event.CreatedAt); err != nil {
+			return nil, version, err
+		}
+	}
+	return appended, version, tx.Commit()
+}
+
+func (s *sqlStore) streamVersion(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}, streamID string) (version int, err error) {
+	err = q.QueryRowContext(ctx, candishared.SQLRebind(`SELECT COALESCE(MAX(version), 0) FROM `+s.table+` WHERE stream_id = ?`, s.placeholder), streamID).Scan(&version)
+	return version, err
+}
+
+func (s *sqlStore) ReadStream(ctx context.Context, streamID string, fromVersion int) ([]Event, error) {
+	return s.query(ctx, `SELECT `+sqlColumns+` FROM `+s.table+` WHERE stream_id = ? AND version > ? ORDER BY version`, streamID, fromVersion)
+}
+
+func (s *sqlStore) ReadAll(ctx context.Context, fromPosition int64, limit int) ([]Event, error) {
+	query := `SELECT ` + sqlColumns + ` FROM ` + s.table + ` WHERE position > ? ORDER BY position`
+	if limit > 0 {
+		query += ` LIMIT ` + strconv.Itoa(limit)
+	}
+	return s.query(ctx, query, fromPosition)
+}
+
+const sqlColumns = `position, stream_id, version, type, data, metadata, created_at`
+
+func (s *sqlStore) query(ctx context.Context, query string, args ...any) (events []Event, err error) {
+	rows, err := s.db.QueryContext(ctx, candishared.SQLRebind(query, s.placeholder), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var event Event
+		var data, metadata sql.NullString
+		if err := rows.Scan(&event.Position, &event.StreamID, &event.Version, &event.Type, &data, &metadata, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Data = json.RawMessage(data.String)
+		if metadata.String != "" {
+			json.Unmarshal([]byte(metadata.String), &event.Metadata)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
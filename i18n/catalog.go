@@ -0,0 +1,136 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Catalog message catalog for all supported locale, message can be formatted with fmt verb (example: "%s is required")
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// NewCatalog constructor, default locale used when requested locale or message not found
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: NormalizeLocale(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+}
+
+// Add messages for locale, override existing message with same key
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = NormalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		c.messages[locale][key] = message
+	}
+}
+
+// LoadFS load catalog from json files in directory (can be embedded file system), file name is locale,
+// example: "en.json", "id.json" with content {"user_not_found": "User not found"}
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("i18n: %s: %v", entry.Name(), err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return fmt.Errorf("i18n: %s: %v", entry.Name(), err)
+		}
+		c.Add(strings.TrimSuffix(entry.Name(), ".json"), messages)
+	}
+	return nil
+}
+
+// LoadDir load catalog from json files in local directory
+func (c *Catalog) LoadDir(dir string) error {
+	return c.LoadFS(os.DirFS(dir), ".")
+}
+
+// DefaultLocale get default locale
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// Locales get all supported locale
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Lookup find message for locale, fallback to base language (example: "en-US" to "en") and default locale
+func (c *Catalog) Lookup(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locale = NormalizeLocale(locale)
+	base, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, base, c.defaultLocale} {
+		if message, ok := c.messages[l][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Translate message with key for locale, return key as is (not formatted with args) if message not found
+func (c *Catalog) Translate(locale, key string, args ...any) string {
+	message, ok := c.Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// HasLocale check exact locale is loaded in catalog, base language of locale is not checked (see MatchLocale)
+func (c *Catalog) HasLocale(locale string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.messages[NormalizeLocale(locale)]
+	return ok
+}
+
+// NormalizeLocale normalize locale tag, example: "en_us" to "en-US"
+func NormalizeLocale(locale string) string {
+	lang, region, ok := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	lang = strings.ToLower(lang)
+	if !ok || region == "" {
+		return lang
+	}
+	return lang + "-" + strings.ToUpper(region)
+}
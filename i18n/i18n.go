@@ -0,0 +1,113 @@
+// Package i18n provide message catalog and locale resolution for localize error and validation message
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+)
+
+var defaultCatalog = NewCatalog("en")
+
+// SetDefaultCatalog set global catalog
+func SetDefaultCatalog(c *Catalog) {
+	defaultCatalog = c
+}
+
+// DefaultCatalog get global catalog
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// T translate message with key using locale from context
+func T(ctx context.Context, key string, args ...any) string {
+	return defaultCatalog.Translate(Locale(ctx), key, args...)
+}
+
+// Locale resolve locale from context, priority: locale in token claim, locale from request
+// (Accept-Language header / grpc metadata), and default catalog locale
+func Locale(ctx context.Context) string {
+	if ctx == nil {
+		return defaultCatalog.DefaultLocale()
+	}
+	if claim, ok := candishared.GetValueFromContext(ctx, candishared.ContextKeyTokenClaim).(*candishared.TokenClaim); ok && claim != nil && claim.Locale != "" {
+		return NormalizeLocale(claim.Locale)
+	}
+	if locale, ok := candishared.GetValueFromContext(ctx, candishared.ContextKeyLocale).(string); ok && locale != "" {
+		return locale
+	}
+	return defaultCatalog.DefaultLocale()
+}
+
+// SetLocaleToContext set locale to context
+func SetLocaleToContext(ctx context.Context, locale string) context.Context {
+	return candishared.SetToContext(ctx, candishared.ContextKeyLocale, NormalizeLocale(locale))
+}
+
+// SetLocaleFromAcceptLanguage set matched locale from Accept-Language value to context
+func SetLocaleFromAcceptLanguage(ctx context.Context, acceptLanguage string) context.Context {
+	if locale := MatchLocale(acceptLanguage, defaultCatalog); locale != "" {
+		return SetLocaleToContext(ctx, locale)
+	}
+	return ctx
+}
+
+// HTTPMiddleware resolve locale from Accept-Language header into request context
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if acceptLanguage := req.Header.Get(candihelper.HeaderAcceptLanguage); acceptLanguage != "" {
+			req = req.WithContext(SetLocaleFromAcceptLanguage(req.Context(), acceptLanguage))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// ParseAcceptLanguage parse Accept-Language value, return locale ordered by quality value
+func ParseAcceptLanguage(acceptLanguage string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var list []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			list = append(list, weighted{locale: NormalizeLocale(locale), q: q})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].q > list[j].q })
+
+	locales := make([]string, len(list))
+	for i, w := range list {
+		locales[i] = w.locale
+	}
+	return locales
+}
+
+// MatchLocale find first supported locale (or base language) in catalog from Accept-Language value
+func MatchLocale(acceptLanguage string, catalog *Catalog) string {
+	for _, locale := range ParseAcceptLanguage(acceptLanguage) {
+		if catalog.HasLocale(locale) {
+			return locale
+		}
+		if base, _, ok := strings.Cut(locale, "-"); ok && catalog.HasLocale(base) {
+			return base
+		}
+	}
+	return ""
+}
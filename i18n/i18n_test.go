@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog := NewCatalog("en")
+	assert.NoError(t, catalog.LoadFS(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"not_found": "%s not found"}`)},
+		"locales/id.json": {Data: []byte(`{"not_found": "%s tidak ditemukan"}`)},
+	}, "locales"))
+
+	assert.Equal(t, []string{"en", "id"}, catalog.Locales())
+	assert.Equal(t, "User tidak ditemukan", catalog.Translate("id-ID", "not_found", "User"))
+	assert.Equal(t, "User not found", catalog.Translate("fr", "not_found", "User"))
+	assert.Equal(t, "unknown_key", catalog.Translate("id", "unknown_key"))
+	assert.Equal(t, "discount 100%", catalog.Translate("id", "discount 100%", "User"), "missing key is not formatted")
+	assert.True(t, catalog.HasLocale("id"))
+	assert.False(t, catalog.HasLocale("id-ID"), "base language is not checked")
+}
+
+func TestLocaleResolution(t *testing.T) {
+	prev := DefaultCatalog()
+	defer SetDefaultCatalog(prev)
+
+	catalog := NewCatalog("en")
+	catalog.Add("en", map[string]string{"hello": "Hello"})
+	catalog.Add("id", map[string]string{"hello": "Halo"})
+	catalog.Add("ja", map[string]string{"hello": "Konnichiwa"})
+	SetDefaultCatalog(catalog)
+
+	assert.Equal(t, []string{"fr-CH", "id-ID", "id", "en"}, ParseAcceptLanguage("id-ID;q=0.9, fr-CH, id;q=0.8, en;q=0.7, *;q=0.5"))
+	assert.Equal(t, "id", MatchLocale("fr-CH, id-ID;q=0.9", catalog))
+
+	var translated string
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		translated = T(r.Context(), "hello")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "id-ID,id;q=0.9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "Halo", translated)
+
+	// locale in token claim has higher priority
+	ctx := SetLocaleToContext(context.Background(), "id")
+	ctx = candishared.SetToContext(ctx, candishared.ContextKeyTokenClaim, &candishared.TokenClaim{Locale: "ja"})
+	assert.Equal(t, "Konnichiwa", T(ctx, "hello"))
+	assert.Equal(t, "Hello", T(context.Background(), "hello"))
+}
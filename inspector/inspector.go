@@ -0,0 +1,350 @@
+// Package inspector opt-in request inspector (debug toolbar) for local development, capture recent REST and GraphQL
+// request with SQL/Mongo query, cache access, published message and timing of each request, browsable at debug endpoint.
+// Inspector is only enabled when ENVIRONMENT is one of allowed environment (default "development" and "local"),
+// unset or unknown environment is disabled. Debug endpoint expose captured query and payload, mount it behind auth
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/tracer"
+	"github.com/golangid/candi/wrapper"
+)
+
+const (
+	// KindSQL event kind of SQL query
+	KindSQL = "sql"
+	// KindMongo event kind of MongoDB command
+	KindMongo = "mongo"
+	// KindCache event kind of cache access
+	KindCache = "cache"
+	// KindPublish event kind of published message
+	KindPublish = "publish"
+)
+
+type (
+	// Event operation captured in request
+	Event struct {
+		Kind     string        `json:"kind"`
+		Name     string        `json:"name"`
+		Detail   string        `json:"detail,omitempty"`
+		StartAt  time.Time     `json:"start_at"`
+		Duration time.Duration `json:"duration"`
+		Error    string        `json:"error,omitempty"`
+	}
+
+	// Request captured request
+	Request struct {
+		ID               string         `json:"id"`
+		Method           string         `json:"method"`
+		Path             string         `json:"path"`
+		Query            string         `json:"query,omitempty"`
+		GraphQLOperation string         `json:"graphql_operation,omitempty"`
+		Status           int            `json:"status"`
+		RequestID        string         `json:"request_id,omitempty"`
+		TraceID          string         `json:"trace_id,omitempty"`
+		StartAt          time.Time      `json:"start_at"`
+		Duration         time.Duration  `json:"duration"`
+		EventCounts      map[string]int `json:"event_counts"`
+		Events           []Event        `json:"events,omitempty"`
+	}
+
+	// OptionFunc type
+	OptionFunc func(*Inspector)
+
+	// Inspector request inspector
+	Inspector struct {
+		maxRequests   int
+		maxEvents     int
+		maxDetailSize int
+		alwaysEnabled bool
+		environments  []string
+		excludePaths  []string
+
+		mu       sync.Mutex
+		requests []*entry
+		seq      atomic.Int64
+	}
+)
+
+// SetMaxRequests option func, max recent request kept in memory (default 100)
+func SetMaxRequests(maxRequests int) OptionFunc {
+	return func(i *Inspector) {
+		i.maxRequests = maxRequests
+	}
+}
+
+// SetMaxEvents option func, max captured event per request (default 200)
+func SetMaxEvents(maxEvents int) OptionFunc {
+	return func(i *Inspector) {
+		i.maxEvents = maxEvents
+	}
+}
+
+// SetMaxDetailSize option func, max size of event detail (query, command) and captured GraphQL body (default 4KB)
+func SetMaxDetailSize(maxDetailSize int) OptionFunc {
+	return func(i *Inspector) {
+		i.maxDetailSize = maxDetailSize
+	}
+}
+
+// SetExcludePaths option func, request with path prefix is not captured (default debug endpoint "/debug")
+func SetExcludePaths(prefixes ...string) OptionFunc {
+	return func(i *Inspector) {
+		i.excludePaths = prefixes
+	}
+}
+
+// SetEnvironments option func, value of ENVIRONMENT which inspector is enabled (default "development" and "local")
+func SetEnvironments(environments ...string) OptionFunc {
+	return func(i *Inspector) {
+		i.environments = environments
+	}
+}
+
+// SetAlwaysEnabled option func, capture request in any environment (including production)
+func SetAlwaysEnabled(enabled bool) OptionFunc {
+	return func(i *Inspector) {
+		i.alwaysEnabled = enabled
+	}
+}
+
+/*
+New create request inspector, example in REST server and repository (debug endpoint must be mounted behind auth):
+
+	insp := inspector.New()
+	basicAuth := service.GetDependency().GetMiddleware().HTTPBasicAuth
+	restserver.NewServer(service,
+		restserver.AddRootMiddlewares(insp.HTTPMiddleware),
+		restserver.AddMountRouter(func(r interfaces.RESTRouter) { r.GET("/debug/requests", insp.HTTPHandler, basicAuth) }),
+	)
+	db, err := inspector.OpenSQL(database.ParseSQLDSN(dsn))
+	cache := inspector.WrapCache(redisCache)
+*/
+func New(opts ...OptionFunc) *Inspector {
+	i := &Inspector{
+		maxRequests:   100,
+		maxEvents:     200,
+		maxDetailSize: 4 << 10,
+		excludePaths:  []string{"/debug"},
+		environments:  []string{"development", "local"},
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Enabled check if inspector capture request in current environment, fail closed for unset or unknown environment
+func (i *Inspector) Enabled() bool {
+	return i.alwaysEnabled || slices.Contains(i.environments, strings.ToLower(os.Getenv("ENVIRONMENT")))
+}
+
+// HTTPMiddleware capture REST and GraphQL request, operation name of GraphQL request is taken from request body
+func (i *Inspector) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !i.Enabled() || i.isExcluded(req.URL.Path) {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		ctx := req.Context()
+		e := &entry{inspector: i, request: Request{
+			ID: strconv.FormatInt(i.seq.Add(1), 10), Method: req.Method, Path: req.URL.Path, Query: req.URL.RawQuery,
+			RequestID: candishared.GetRequestID(ctx), StartAt: time.Now(), EventCounts: make(map[string]int),
+		}}
+		if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/graphql") {
+			e.request.GraphQLOperation = i.graphQLOperation(req)
+		}
+		i.add(e)
+
+		resp := wrapper.NewWrapHTTPResponseWriter(new(bytes.Buffer), rw)
+		resp.SetMaxWriteSize(0)
+		ctx = context.WithValue(ctx, entryKey{}, e)
+		defer func() {
+			e.mu.Lock()
+			e.request.Status, e.request.Duration = resp.StatusCode(), time.Since(e.request.StartAt)
+			e.request.TraceID = tracer.GetTraceID(ctx)
+			e.mu.Unlock()
+		}()
+		next.ServeHTTP(resp, req.WithContext(ctx))
+	})
+}
+
+// Record add event to captured request in context, no-op if request is not captured
+func Record(ctx context.Context, event Event) {
+	e, ok := ctx.Value(entryKey{}).(*entry)
+	if !ok {
+		return
+	}
+	if len(event.Detail) > e.inspector.maxDetailSize {
+		event.Detail = event.Detail[:e.inspector.maxDetailSize] + "..."
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.request.EventCounts[event.Kind]++
+	if len(e.request.Events) < e.inspector.maxEvents {
+		e.request.Events = append(e.request.Events, event)
+	}
+}
+
+// Requests recent captured request (newest first) without events
+func (i *Inspector) Requests() []Request {
+	i.mu.Lock()
+	entries := slices.Clone(i.requests)
+	i.mu.Unlock()
+
+	requests := make([]Request, 0, len(entries))
+	for j := len(entries) - 1; j >= 0; j-- {
+		r := entries[j].snapshot()
+		r.Events = nil
+		requests = append(requests, r)
+	}
+	return requests
+}
+
+// Request captured request with events by id
+func (i *Inspector) Request(id string) (Request, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, e := range i.requests {
+		if e.request.ID == id {
+			return e.snapshot(), true
+		}
+	}
+	return Request{}, false
+}
+
+// Clear remove all captured request
+func (i *Inspector) Clear() {
+	i.mu.Lock()
+	i.requests = nil
+	i.mu.Unlock()
+}
+
+// HTTPHandler debug endpoint, list recent request (or request detail with query param "id") in HTML page,
+// or JSON if query param "format=json". Method DELETE clear captured request.
+// Handler has no authentication and serve captured SQL args, query string and payload, must be mounted behind auth
+// (example: middleware HTTPBasicAuth)
+func (i *Inspector) HTTPHandler(rw http.ResponseWriter, req *http.Request) {
+	if !i.Enabled() {
+		wrapper.NewHTTPResponse(http.StatusNotFound, "Request inspector is disabled").JSON(rw)
+		return
+	}
+	if req.Method == http.MethodDelete {
+		i.Clear()
+		wrapper.NewHTTPResponse(http.StatusOK, "Success clear captured request").JSON(rw)
+		return
+	}
+
+	var data any = i.Requests()
+	if id := req.URL.Query().Get("id"); id != "" {
+		r, ok := i.Request(id)
+		if !ok {
+			wrapper.NewHTTPResponse(http.StatusNotFound, "Request not found").JSON(rw)
+			return
+		}
+		data = []Request{r}
+	}
+	if req.URL.Query().Get("format") == "json" {
+		wrapper.NewHTTPResponse(http.StatusOK, "Captured request", data).JSON(rw)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pageTemplate.Execute(rw, map[string]any{"Path": req.URL.Path, "Requests": data})
+}
+
+func (i *Inspector) add(e *entry) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.requests = append(i.requests, e)
+	if over := len(i.requests) - max(i.maxRequests, 1); over > 0 {
+		i.requests = slices.Delete(i.requests, 0, over)
+	}
+}
+
+func (i *Inspector) isExcluded(path string) bool {
+	for _, prefix := range i.excludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLOperation peek operation name of GraphQL request body, body is restored for next handler
+func (i *Inspector) graphQLOperation(req *http.Request) string {
+	if req.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, int64(i.maxDetailSize)))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil {
+		return ""
+	}
+
+	var payload struct {
+		OperationName string `json:"operationName"`
+		Query         string `json:"query"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return ""
+	}
+	if payload.OperationName != "" {
+		return payload.OperationName
+	}
+	// anonymous operation, use first line of query
+	query, _, _ := strings.Cut(strings.TrimSpace(payload.Query), "\n")
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), "{"))
+}
+
+// entry captured request in memory, events is recorded concurrently
+type entry struct {
+	inspector *Inspector
+	mu        sync.Mutex
+	request   Request
+}
+
+type entryKey struct{}
+
+func (e *entry) snapshot() Request {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r := e.request
+	r.EventCounts, r.Events = maps.Clone(r.EventCounts), slices.Clone(r.Events)
+	return r
+}
+
+var pageTemplate = template.Must(template.New("inspector").Parse(`<!DOCTYPE html>
+<html><head><title>Request Inspector</title><style>
+body{font-family:monospace;margin:20px}table{border-collapse:collapse;width:100%}
+td,th{border:1px solid #ccc;padding:4px;text-align:left;vertical-align:top}pre{white-space:pre-wrap;margin:0}
+</style></head><body>
+<h2><a href="{{.Path}}">Request Inspector</a></h2>
+<table><tr><th>ID</th><th>Time</th><th>Request</th><th>Status</th><th>Duration</th><th>Events</th></tr>
+{{range .Requests}}<tr>
+<td><a href="{{$.Path}}?id={{.ID}}">{{.ID}}</a></td><td>{{.StartAt.Format "15:04:05.000"}}</td>
+<td>{{.Method}} {{.Path}}{{if .Query}}?{{.Query}}{{end}}{{if .GraphQLOperation}}<br>{{.GraphQLOperation}}{{end}}{{if .RequestID}}<br>request id: {{.RequestID}}{{end}}{{if .TraceID}}<br>trace id: {{.TraceID}}{{end}}</td>
+<td>{{.Status}}</td><td>{{.Duration}}</td><td>{{range $kind, $count := .EventCounts}}{{$kind}}: {{$count}}<br>{{end}}</td>
+</tr>{{if .Events}}<tr><td colspan="6"><table><tr><th>Kind</th><th>Name</th><th>Detail</th><th>Duration</th><th>Error</th></tr>
+{{range .Events}}<tr><td>{{.Kind}}</td><td>{{.Name}}</td><td><pre>{{.Detail}}</pre></td><td>{{.Duration}}</td><td>{{.Error}}</td></tr>{{end}}
+</table></td></tr>{{end}}{{end}}
+</table></body></html>`))
@@ -0,0 +1,168 @@
+package inspector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	mockinterfaces "github.com/golangid/candi/mocks/codebase/interfaces"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspector(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	insp := New(SetMaxRequests(2))
+	handler := insp.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		Record(req.Context(), Event{Kind: KindSQL, Name: "QUERY", Detail: "SELECT 1"})
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write(body)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders?page=1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/debug/requests", nil))
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/graphql",
+		strings.NewReader(`{"operationName":"GetOrder","query":"query GetOrder { order { id } }"}`)))
+	assert.Contains(t, resp.Body.String(), "GetOrder", "request body must be restored for next handler")
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql",
+		strings.NewReader(`{"query":"mutation {\n createOrder { id } }"}`)))
+
+	requests := insp.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "mutation", requests[0].GraphQLOperation)
+	assert.Equal(t, "GetOrder", requests[1].GraphQLOperation)
+	assert.Equal(t, http.StatusCreated, requests[1].Status)
+	assert.Equal(t, 1, requests[1].EventCounts[KindSQL])
+	assert.Empty(t, requests[1].Events)
+
+	detail, ok := insp.Request(requests[1].ID)
+	require.True(t, ok)
+	require.Len(t, detail.Events, 1)
+	assert.Equal(t, "SELECT 1", detail.Events[0].Detail)
+
+	resp = httptest.NewRecorder()
+	insp.HTTPHandler(resp, httptest.NewRequest(http.MethodGet, "/debug/requests?format=json&id="+detail.ID, nil))
+	var result struct {
+		Data []Request `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "/graphql", result.Data[0].Path)
+
+	resp = httptest.NewRecorder()
+	insp.HTTPHandler(resp, httptest.NewRequest(http.MethodGet, "/debug/requests", nil))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, resp.Body.String(), "GetOrder")
+
+	insp.HTTPHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/debug/requests", nil))
+	assert.Empty(t, insp.Requests())
+
+	for _, environment := range []string{"production", "prod", "staging", ""} {
+		t.Run("Disabled in environment "+environment, func(t *testing.T) {
+			t.Setenv("ENVIRONMENT", environment)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+			assert.Empty(t, insp.Requests())
+
+			resp := httptest.NewRecorder()
+			insp.HTTPHandler(resp, httptest.NewRequest(http.MethodGet, "/debug/requests", nil))
+			assert.Equal(t, http.StatusNotFound, resp.Code)
+		})
+	}
+
+	t.Run("Always enabled", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+		assert.True(t, New(SetAlwaysEnabled(true)).Enabled())
+		assert.False(t, New(SetEnvironments("staging")).Enabled())
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "local")
+	insp := New(SetMaxDetailSize(16))
+	var ctx context.Context
+	insp.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx = req.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cache := mockinterfaces.NewCache(t)
+	cache.On("Get", mock.Anything, "hit").Return([]byte("value"), nil)
+	cache.On("Get", mock.Anything, "miss").Return(nil, redis.ErrNil)
+	cache.On("Set", mock.Anything, "key", "value", mock.Anything).Return(nil)
+	recordedCache := WrapCache(cache)
+	recordedCache.Get(ctx, "hit")
+	recordedCache.Get(ctx, "miss")
+	recordedCache.Set(ctx, "key", "value", 0)
+	recordedCache.Get(context.Background(), "hit")
+
+	publisher := mockinterfaces.NewPublisher(t)
+	publisher.On("PublishMessage", mock.Anything, mock.Anything).Return(nil)
+	WrapPublisher(publisher).PublishMessage(ctx, &candishared.PublisherArgument{
+		Topic: "order-created", Key: "1", Message: []byte(`{"id":1,"items":[]}`),
+	})
+
+	request, ok := insp.Request(insp.Requests()[0].ID)
+	require.True(t, ok)
+	require.Len(t, request.Events, 4)
+	assert.Equal(t, "GET hit", request.Events[0].Name)
+	assert.Equal(t, "GET miss", request.Events[1].Name)
+	assert.Empty(t, request.Events[1].Error)
+	assert.Equal(t, "SET", request.Events[2].Name)
+	assert.Equal(t, "order-created", request.Events[3].Name)
+	assert.Equal(t, `key: 1`+"\n"+`{"id":1,"...`, request.Events[3].Detail)
+	assert.Equal(t, 3, request.EventCounts[KindCache])
+}
+
+func TestOpenSQL(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "local")
+	sql.Register("inspector-fake", fakeDriver{})
+	db, err := OpenSQL("inspector-fake", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	insp := New()
+	insp.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := db.ExecContext(req.Context(), "UPDATE orders SET status = ? WHERE id = ?", "paid", 1)
+		assert.NoError(t, err)
+		rows, err := db.QueryContext(req.Context(), "SELECT id FROM orders")
+		require.NoError(t, err)
+		rows.Close()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	request, ok := insp.Request(insp.Requests()[0].ID)
+	require.True(t, ok)
+	require.Len(t, request.Events, 2)
+	assert.Equal(t, "EXEC", request.Events[0].Name)
+	assert.Equal(t, "UPDATE orders SET status = ? WHERE id = ?\nargs: [paid, 1]", request.Events[0].Detail)
+	assert.Equal(t, "QUERY", request.Events[1].Name)
+}
+
+// fakeDriver sql driver without QueryerContext and ExecerContext, query is executed with prepared statement
+type (
+	fakeDriver struct{}
+	fakeConn   struct{}
+	fakeStmt   struct{}
+	fakeRows   struct{}
+)
+
+func (fakeDriver) Open(string) (driver.Conn, error)         { return fakeConn{}, nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error)        { return fakeStmt{}, nil }
+func (fakeConn) Close() error                               { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+func (fakeRows) Columns() []string                          { return []string{"id"} }
+func (fakeRows) Close() error                               { return nil }
+func (fakeRows) Next([]driver.Value) error                  { return io.EOF }
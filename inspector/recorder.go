@@ -0,0 +1,131 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/cache"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/gomodule/redigo/redis"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// WrapCache record cache access (hit or miss of Get) to captured request
+func WrapCache(c interfaces.Cache) interfaces.Cache {
+	return &cacheRecorder{Cache: c}
+}
+
+type cacheRecorder struct {
+	interfaces.Cache
+}
+
+func (c *cacheRecorder) Get(ctx context.Context, key string) (data []byte, err error) {
+	startAt := time.Now()
+	data, err = c.Cache.Get(ctx, key)
+	name := "GET hit"
+	if err != nil || data == nil {
+		name = "GET miss"
+	}
+	record(ctx, KindCache, name, key, startAt, func() error {
+		if errors.Is(err, redis.ErrNil) || errors.Is(err, cache.ErrBoltKeyNotFound) {
+			return nil
+		}
+		return err
+	})
+	return data, err
+}
+
+func (c *cacheRecorder) Set(ctx context.Context, key string, value any, expire time.Duration) (err error) {
+	defer record(ctx, KindCache, "SET", key, time.Now(), func() error { return err })
+	return c.Cache.Set(ctx, key, value, expire)
+}
+
+func (c *cacheRecorder) Exists(ctx context.Context, key string) (exists bool, err error) {
+	defer record(ctx, KindCache, "EXISTS", key, time.Now(), func() error { return err })
+	return c.Cache.Exists(ctx, key)
+}
+
+func (c *cacheRecorder) Delete(ctx context.Context, key string) (err error) {
+	defer record(ctx, KindCache, "DEL", key, time.Now(), func() error { return err })
+	return c.Cache.Delete(ctx, key)
+}
+
+func (c *cacheRecorder) DoCommand(ctx context.Context, isWrite bool, command string, args ...any) (reply any, err error) {
+	defer record(ctx, KindCache, command, fmt.Sprint(args...), time.Now(), func() error { return err })
+	return c.Cache.DoCommand(ctx, isWrite, command, args...)
+}
+
+// WrapPublisher record published message (topic, key and message) to captured request
+func WrapPublisher(publisher interfaces.Publisher) interfaces.Publisher {
+	return &publisherRecorder{publisher: publisher}
+}
+
+type publisherRecorder struct {
+	publisher interfaces.Publisher
+}
+
+func (p *publisherRecorder) PublishMessage(ctx context.Context, args *candishared.PublisherArgument) (err error) {
+	message := args.Message
+	if len(message) == 0 && args.Data != nil {
+		message = []byte(fmt.Sprint(args.Data))
+	}
+	defer record(ctx, KindPublish, args.Topic, "key: "+args.Key+"\n"+string(message), time.Now(), func() error { return err })
+	return p.publisher.PublishMessage(ctx, args)
+}
+
+// MongoMonitor MongoDB command monitor for record command to captured request, next monitor (can be nil) is still called,
+// example: options.Client().SetMonitor(inspector.MongoMonitor(nil))
+func MongoMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	var started sync.Map // request id -> started command
+	type command struct {
+		name, detail string
+		startAt      time.Time
+	}
+
+	finish := func(ctx context.Context, requestID int64, err error) {
+		if value, ok := started.LoadAndDelete(requestID); ok {
+			cmd := value.(command)
+			record(ctx, KindMongo, cmd.name, cmd.detail, cmd.startAt, func() error { return err })
+		}
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			if ctx.Value(entryKey{}) != nil {
+				started.Store(evt.RequestID, command{
+					name: evt.DatabaseName + "." + evt.CommandName, detail: evt.Command.String(), startAt: time.Now(),
+				})
+			}
+			if next != nil && next.Started != nil {
+				next.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			finish(ctx, evt.RequestID, nil)
+			if next != nil && next.Succeeded != nil {
+				next.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			finish(ctx, evt.RequestID, errors.New(evt.Failure))
+			if next != nil && next.Failed != nil {
+				next.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// record add event to captured request, errFunc is evaluated after operation finished
+func record(ctx context.Context, kind, name, detail string, startAt time.Time, errFunc func() error) {
+	if ctx.Value(entryKey{}) == nil {
+		return
+	}
+	evt := Event{Kind: kind, Name: name, Detail: detail, StartAt: startAt, Duration: time.Since(startAt)}
+	if err := errFunc(); err != nil {
+		evt.Error = err.Error()
+	}
+	Record(ctx, evt)
+}
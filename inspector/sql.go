@@ -0,0 +1,181 @@
+package inspector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OpenSQL open sql database with driver wrapped for record query to captured request, example:
+// db, err := inspector.OpenSQL(database.ParseSQLDSN(dsn))
+func OpenSQL(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+	return sql.OpenDB(&sqlConnector{driver: drv, dsn: dsn}), nil
+}
+
+type sqlConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var (
+		conn driver.Conn
+		err  error
+	)
+	if driverCtx, ok := c.driver.(driver.DriverContext); ok {
+		var connector driver.Connector
+		if connector, err = driverCtx.OpenConnector(c.dsn); err == nil {
+			conn, err = connector.Connect(ctx)
+		}
+	} else {
+		conn, err = c.driver.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type sqlConn struct {
+	driver.Conn
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	startAt := time.Now()
+	rows, err = queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		record(ctx, KindSQL, "QUERY", sqlDetail(query, args), startAt, func() error { return err })
+	}
+	return rows, err
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	startAt := time.Now()
+	result, err = execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		record(ctx, KindSQL, "EXEC", sqlDetail(query, args), startAt, func() error { return err })
+	}
+	return result, err
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	driver.Stmt
+	conn  *sqlConn
+	query string
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	defer record(ctx, KindSQL, "QUERY", sqlDetail(s.query, args), time.Now(), func() error { return err })
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	defer record(ctx, KindSQL, "EXEC", sqlDetail(s.query, args), time.Now(), func() error { return err })
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	// database/sql only check statement when statement implement checker, fallback to connection checker
+	return s.conn.CheckNamedValue(nv)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("inspector: driver does not support named parameter %s", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func sqlDetail(query string, args []driver.NamedValue) string {
+	query = strings.TrimSpace(query)
+	if len(args) == 0 {
+		return query
+	}
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprintf("%v", arg.Value)
+	}
+	return query + "\nargs: [" + strings.Join(values, ", ") + "]"
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelConfig runtime log level configuration
+type LevelConfig struct {
+	Level    string            `json:"level"`
+	Scopes   map[string]string `json:"scopes,omitempty"`
+	Sampling *SamplingConfig   `json:"sampling,omitempty"`
+}
+
+// SamplingConfig log sampling, log first N entries with same level & message in every tick,
+// and thereafter only log every M-th entries
+type SamplingConfig struct {
+	First      int    `json:"first"`
+	Thereafter int    `json:"thereafter"`
+	Tick       string `json:"tick"`
+}
+
+type levelController struct {
+	mu       sync.RWMutex
+	global   zapcore.Level
+	scopes   map[string]zapcore.Level
+	timers   map[string]*time.Timer
+	sampling *sampler
+}
+
+var controller = &levelController{
+	global: zapcore.DebugLevel,
+	scopes: make(map[string]zapcore.Level),
+	timers: make(map[string]*time.Timer),
+}
+
+// SetLevel change global log level at runtime, revert to previous level after revertAfter (if greater than zero)
+func SetLevel(level zapcore.Level, revertAfter time.Duration) {
+	controller.mu.Lock()
+	defer controller.mu.Unlock()
+
+	prev := controller.global
+	controller.global = level
+	controller.scheduleRevert("", revertAfter, func() { controller.global = prev })
+}
+
+// SetScopeLevel change log level for specific scope (matched by prefix of "context" or "scope" log field,
+// example: package or handler name) at runtime, revert to previous state after revertAfter (if greater than zero)
+func SetScopeLevel(scope string, level zapcore.Level, revertAfter time.Duration) {
+	controller.mu.Lock()
+	defer controller.mu.Unlock()
+
+	prev, exist := controller.scopes[scope]
+	controller.scopes[scope] = level
+	controller.scheduleRevert("scope:"+scope, revertAfter, func() {
+		if exist {
+			controller.scopes[scope] = prev
+		} else {
+			delete(controller.scopes, scope)
+		}
+	})
+}
+
+// ResetScopeLevel remove log level for specific scope, log with this scope will use global level
+func ResetScopeLevel(scope string) {
+	controller.mu.Lock()
+	defer controller.mu.Unlock()
+
+	controller.stopTimer("scope:" + scope)
+	delete(controller.scopes, scope)
+}
+
+// SetSampling set log sampling at runtime, disable sampling if first is zero,
+// revert to previous sampling after revertAfter (if greater than zero)
+func SetSampling(first, thereafter int, tick time.Duration, revertAfter time.Duration) {
+	controller.mu.Lock()
+	defer controller.mu.Unlock()
+
+	prev := controller.sampling
+	controller.sampling = nil
+	if first > 0 {
+		if tick <= 0 {
+			tick = time.Second
+		}
+		controller.sampling = newSampler(first, thereafter, tick)
+	}
+	controller.scheduleRevert("sampling", revertAfter, func() { controller.sampling = prev })
+}
+
+// GetLevelConfig get current runtime log level configuration
+func GetLevelConfig() LevelConfig {
+	controller.mu.RLock()
+	defer controller.mu.RUnlock()
+
+	cfg := LevelConfig{Level: controller.global.String()}
+	if len(controller.scopes) > 0 {
+		cfg.Scopes = make(map[string]string, len(controller.scopes))
+		for scope, level := range controller.scopes {
+			cfg.Scopes[scope] = level.String()
+		}
+	}
+	if s := controller.sampling; s != nil {
+		cfg.Sampling = &SamplingConfig{First: s.first, Thereafter: s.thereafter, Tick: s.tick.String()}
+	}
+	return cfg
+}
+
+// scheduleRevert must be called with lock held
+func (c *levelController) scheduleRevert(key string, revertAfter time.Duration, revert func()) {
+	c.stopTimer(key)
+	if revertAfter <= 0 {
+		return
+	}
+	c.timers[key] = time.AfterFunc(revertAfter, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.timers, key)
+		revert()
+	})
+}
+
+func (c *levelController) stopTimer(key string) {
+	if t, ok := c.timers[key]; ok {
+		t.Stop()
+		delete(c.timers, key)
+	}
+}
+
+// minLevel lowest enabled level from global and all scopes
+func (c *levelController) minLevel() zapcore.Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	min := c.global
+	for _, level := range c.scopes {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+func (c *levelController) enabled(ent zapcore.Entry, scopes []string) bool {
+	c.mu.RLock()
+	level, matchLen := c.global, -1
+	for scope, scopeLevel := range c.scopes {
+		for _, s := range scopes {
+			if strings.HasPrefix(s, scope) && len(scope) > matchLen {
+				level, matchLen = scopeLevel, len(scope)
+			}
+		}
+	}
+	sampling := c.sampling
+	c.mu.RUnlock()
+
+	if ent.Level < level {
+		return false
+	}
+	return sampling == nil || sampling.allow(ent)
+}
+
+type sampler struct {
+	mu                sync.Mutex
+	first, thereafter int
+	tick              time.Duration
+	resetAt           time.Time
+	counts            map[string]int
+}
+
+func newSampler(first, thereafter int, tick time.Duration) *sampler {
+	return &sampler{first: first, thereafter: thereafter, tick: tick, counts: make(map[string]int)}
+}
+
+func (s *sampler) allow(ent zapcore.Entry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ent.Time.After(s.resetAt) {
+		s.counts = make(map[string]int)
+		s.resetAt = ent.Time.Add(s.tick)
+	}
+
+	key := ent.Level.String() + ent.Message
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// controlledCore zap core with runtime level & sampling from level controller
+type controlledCore struct {
+	zapcore.Core
+	scopes []string
+}
+
+func (c *controlledCore) Enabled(level zapcore.Level) bool {
+	return level >= controller.minLevel()
+}
+
+func (c *controlledCore) With(fields []zapcore.Field) zapcore.Core {
+	scopes := append([]string{}, c.scopes...)
+	for _, f := range fields {
+		if f.Type == zapcore.StringType && (f.Key == "context" || f.Key == "scope") {
+			scopes = append(scopes, f.String)
+		}
+	}
+	return &controlledCore{Core: c.Core.With(fields), scopes: scopes}
+}
+
+func (c *controlledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !controller.enabled(ent, c.scopes) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
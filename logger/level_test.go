@@ -0,0 +1,40 @@
+package logger_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRuntimeLevel(t *testing.T) {
+	logOutput, _ := captureLogs()
+	logger.InitZap(logger.OptionSetWriter(io.MultiWriter(logOutput)))
+
+	logger.SetLevel(zapcore.ErrorLevel, 0)
+	defer logger.SetLevel(zapcore.DebugLevel, 0)
+	logger.LogI("info message")
+	assert.False(t, bytes.Contains(logOutput.Bytes(), []byte("info message")))
+
+	logger.SetScopeLevel("PaymentUsecase", zapcore.DebugLevel, 50*time.Millisecond)
+	logger.Log(zapcore.DebugLevel, "scoped debug", "PaymentUsecase:Charge", "charge")
+	logger.Log(zapcore.DebugLevel, "other debug", "UserUsecase:Save", "save")
+	assert.True(t, bytes.Contains(logOutput.Bytes(), []byte("scoped debug")))
+	assert.False(t, bytes.Contains(logOutput.Bytes(), []byte("other debug")))
+	assert.Equal(t, "debug", logger.GetLevelConfig().Scopes["PaymentUsecase"])
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, logger.GetLevelConfig().Scopes)
+
+	logger.SetLevel(zapcore.DebugLevel, 0)
+	logger.SetSampling(1, 0, time.Minute, 0)
+	defer logger.SetSampling(0, 0, 0, 0)
+	logOutput.Reset()
+	logger.LogI("sampled")
+	logger.LogI("sampled")
+	assert.Equal(t, 1, bytes.Count(logOutput.Bytes(), []byte("sampled")))
+}
@@ -1,10 +1,12 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"os"
 	"runtime"
 
+	"github.com/golangid/candi/candishared"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -39,7 +41,7 @@ func InitZap(opts ...OptionFunc) {
 	for _, w := range opt.MultiWriter {
 		coreOpt = append(coreOpt, zapcore.NewCore(encCfg, zapcore.AddSync(w), zapcore.DebugLevel))
 	}
-	core := zapcore.NewTee(coreOpt...)
+	core := &controlledCore{Core: zapcore.NewTee(coreOpt...)}
 
 	zapLog := zap.New(core, zap.AddCaller())
 	zap.ReplaceGlobals(zapLog)
@@ -71,6 +73,18 @@ func LogWithField(level zapcore.Level, fields map[string]any) {
 	setEntryType(level, entry, message)
 }
 
+// WithContext logger with request_id and tenant_id field from context, for correlate log line across a request chain
+func WithContext(ctx context.Context) *zap.SugaredLogger {
+	entry := zap.S()
+	if requestID := candishared.GetRequestID(ctx); requestID != "" {
+		entry = entry.With(zap.String("request_id", requestID))
+	}
+	if tenantID := candishared.GetTenantFromContext(ctx); tenantID != "" {
+		entry = entry.With(zap.String("tenant_id", tenantID))
+	}
+	return entry
+}
+
 // LogE error
 func LogE(message string) {
 	zap.S().Error(message)
@@ -2,9 +2,11 @@ package logger_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"testing"
 
+	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/logger"
 	"go.uber.org/zap/zapcore"
 )
@@ -57,6 +59,18 @@ func TestLog(t *testing.T) {
 	}
 }
 
+func TestWithContext(t *testing.T) {
+	logOutput, _ := captureLogs()
+	logger.InitZap(logger.OptionAddWriter(io.MultiWriter(logOutput)))
+
+	ctx := candishared.SetRequestIDToContext(context.Background(), "req-123")
+	logger.WithContext(ctx).Info("testing context log")
+
+	if !bytes.Contains(logOutput.Bytes(), []byte(`"request_id":"req-123"`)) {
+		t.Error("Expected request id not found")
+	}
+}
+
 func TestLogE(t *testing.T) {
 	logOutput, _ := captureLogs()
 	logger.InitZap(logger.OptionAddWriter(io.MultiWriter(logOutput)))
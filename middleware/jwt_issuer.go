@@ -0,0 +1,333 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+)
+
+// SigningKey key of JWT signature. Key is []byte secret for HMAC algorithm, private key (*rsa.PrivateKey or
+// *ecdsa.PrivateKey) for sign and verify, or public key for verify only key
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Key       any
+}
+
+// ParseSigningKey parse key from config, key is secret (minimum 32 bytes) for HS256/HS384/HS512 or PEM encoded
+// private key (public key for verify only) for RS*, PS* and ES* algorithm
+func ParseSigningKey(id, algorithm string, key []byte) (SigningKey, error) {
+	signingKey := SigningKey{ID: id, Algorithm: algorithm}
+	var err error
+	switch jwt.GetSigningMethod(algorithm).(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(key) < 32 {
+			return signingKey, errors.New("jwt: hmac secret must be at least 32 bytes")
+		}
+		signingKey.Key = key
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if signingKey.Key, err = jwt.ParseRSAPrivateKeyFromPEM(key); err != nil {
+			signingKey.Key, err = jwt.ParseRSAPublicKeyFromPEM(key)
+		}
+	case *jwt.SigningMethodECDSA:
+		if signingKey.Key, err = jwt.ParseECPrivateKeyFromPEM(key); err != nil {
+			signingKey.Key, err = jwt.ParseECPublicKeyFromPEM(key)
+		}
+	default:
+		return signingKey, fmt.Errorf("jwt: unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return signingKey, fmt.Errorf("jwt: parse key %s: %w", id, err)
+	}
+	return signingKey, nil
+}
+
+func (k SigningKey) verifyKey() any {
+	switch key := k.Key.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey
+	}
+	return k.Key
+}
+
+func (k SigningKey) canSign() bool {
+	switch k.Key.(type) {
+	case []byte, *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return true
+	}
+	return false
+}
+
+type (
+	// TokenPair issued access token and refresh token
+	TokenPair struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+	}
+
+	// TokenIssuer issue, refresh and validate signed JWT, implement interfaces.TokenValidator
+	TokenIssuer struct {
+		keys          []SigningKey
+		issuer        string
+		audience      string
+		accessTTL     time.Duration
+		refreshTTL    time.Duration
+		store         RefreshTokenStore
+		claimsBuilder func(ctx context.Context, subject string, claim *candishared.TokenClaim) error
+		clock         candihelper.Clock
+	}
+
+	// TokenIssuerOption option func type
+	TokenIssuerOption func(*TokenIssuer)
+)
+
+// TokenIssuerSetIssuer option func, "iss" claim of issued token and validated when not empty
+func TokenIssuerSetIssuer(issuer string) TokenIssuerOption {
+	return func(i *TokenIssuer) {
+		i.issuer = issuer
+	}
+}
+
+// TokenIssuerSetAudience option func, "aud" claim of issued token and validated when not empty
+func TokenIssuerSetAudience(audience string) TokenIssuerOption {
+	return func(i *TokenIssuer) {
+		i.audience = audience
+	}
+}
+
+// TokenIssuerSetTTL option func, lifetime of access token (default 15 minutes) and refresh token (default 7 days),
+// refresh token lifetime is extended on each refresh
+func TokenIssuerSetTTL(accessTTL, refreshTTL time.Duration) TokenIssuerOption {
+	return func(i *TokenIssuer) {
+		i.accessTTL, i.refreshTTL = accessTTL, refreshTTL
+	}
+}
+
+// TokenIssuerSetRefreshTokenStore option func, default in memory (use NewCacheRefreshTokenStore for multiple replicas)
+func TokenIssuerSetRefreshTokenStore(store RefreshTokenStore) TokenIssuerOption {
+	return func(i *TokenIssuer) {
+		i.store = store
+	}
+}
+
+// TokenIssuerSetClaimsBuilder option func, fill custom claim (role, locale, additional) of subject when issue and
+// refresh token, so refreshed token contains current data of subject. Return error for reject issue (example: user
+// is deactivated)
+func TokenIssuerSetClaimsBuilder(builder func(ctx context.Context, subject string, claim *candishared.TokenClaim) error) TokenIssuerOption {
+	return func(i *TokenIssuer) {
+		i.claimsBuilder = builder
+	}
+}
+
+// TokenIssuerSetClock option func, default candihelper.SystemClock
+func TokenIssuerSetClock(clock candihelper.Clock) TokenIssuerOption {
+	return func(i *TokenIssuer) {
+		i.clock = clock
+	}
+}
+
+/*
+NewTokenIssuer create token issuer, first key is active key for sign new token and the other keys is previous key
+only for verify token (key rotation: add new key in front and remove previous key after access token lifetime).
+Use issuer as token validator of middleware:
+
+	key, err := middleware.ParseSigningKey("2024-01", "RS256", []byte(env.JWTPrivateKey))
+	issuer, err := middleware.NewTokenIssuer([]middleware.SigningKey{key}, middleware.TokenIssuerSetIssuer("auth-service"))
+	mw := middleware.NewMiddlewareWithOption(middleware.SetTokenValidator(issuer))
+*/
+func NewTokenIssuer(keys []SigningKey, opts ...TokenIssuerOption) (*TokenIssuer, error) {
+	if len(keys) == 0 || !keys[0].canSign() {
+		return nil, errors.New("jwt: first key must be secret or private key for sign token")
+	}
+	i := &TokenIssuer{
+		keys:       keys,
+		accessTTL:  15 * time.Minute,
+		refreshTTL: 7 * 24 * time.Hour,
+		clock:      candihelper.SystemClock,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.store == nil {
+		i.store = newMemoryRefreshTokenStore(i.clock)
+	}
+	return i, nil
+}
+
+// Sign sign claim with active key, registered claim (iss, aud, iat, exp, jti) is set when empty
+func (i *TokenIssuer) Sign(claim *candishared.TokenClaim) (string, error) {
+	now := i.clock.Now()
+	if claim.Issuer == "" {
+		claim.Issuer = i.issuer
+	}
+	if claim.Audience == "" {
+		claim.Audience = i.audience
+	}
+	if claim.IssuedAt == 0 {
+		claim.IssuedAt = now.Unix()
+	}
+	if claim.ExpiresAt == 0 {
+		claim.ExpiresAt = now.Add(i.accessTTL).Unix()
+	}
+	if claim.Id == "" {
+		claim.Id = randomHex(16)
+	}
+
+	key := i.keys[0]
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claim)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.Key)
+}
+
+// Issue issue access token and new refresh token of subject (login)
+func (i *TokenIssuer) Issue(ctx context.Context, subject string) (*TokenPair, error) {
+	refresh := &RefreshToken{FamilyID: randomHex(16), Subject: subject, IssuedAt: i.clock.Now()}
+	return i.issue(ctx, refresh, "")
+}
+
+// Refresh rotate refresh token and issue new access token. Refresh token can only be used once, reused refresh
+// token (stolen token is replayed or concurrent refresh with same token) revoke the whole session so both holders
+// must login again
+func (i *TokenIssuer) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	refresh, err := i.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return i.issue(ctx, refresh, refresh.SecretHash)
+}
+
+// Revoke revoke session of refresh token (logout)
+func (i *TokenIssuer) Revoke(ctx context.Context, refreshToken string) error {
+	refresh, err := i.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return i.store.Delete(ctx, refresh.FamilyID)
+}
+
+// ValidateToken validate signature with key of "kid" header, expiration, issuer and audience of access token
+func (i *TokenIssuer) ValidateToken(ctx context.Context, tokenString string) (*candishared.TokenClaim, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	claim := new(candishared.TokenClaim)
+	_, err := parser.ParseWithClaims(tokenString, claim, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range i.keys {
+			if key.ID == kid || (kid == "" && len(i.keys) == 1) {
+				if token.Method.Alg() != key.Algorithm {
+					return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+				}
+				return key.verifyKey(), nil
+			}
+		}
+		return nil, fmt.Errorf("key %q not found", kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Invalid token: %w", err)
+	}
+
+	now := i.clock.Now().Unix()
+	switch {
+	case !claim.VerifyExpiresAt(now, true):
+		return nil, errors.New("Token is expired")
+	case !claim.VerifyNotBefore(now, false):
+		return nil, errors.New("Token is not valid yet")
+	case i.issuer != "" && !claim.VerifyIssuer(i.issuer, true):
+		return nil, errors.New("Invalid token issuer")
+	case i.audience != "" && !claim.VerifyAudience(i.audience, true):
+		return nil, errors.New("Invalid token audience")
+	}
+	return claim, nil
+}
+
+// JWKS JSON web key set of RSA and ECDSA public keys, for other service verify token
+func (i *TokenIssuer) JWKS() map[string]any {
+	keys := []map[string]string{}
+	for _, key := range i.keys {
+		jwk := map[string]string{"kid": key.ID, "alg": key.Algorithm, "use": "sig"}
+		switch pub := key.verifyKey().(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			jwk["kty"] = "EC"
+			jwk["crv"] = pub.Curve.Params().Name
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+			jwk["y"] = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+		default:
+			// secret of HMAC key must not be published
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	return map[string]any{"keys": keys}
+}
+
+// JWKSHandler http handler of JWKS, mount at "/.well-known/jwks.json"
+func (i *TokenIssuer) JWKSHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(candihelper.HeaderContentType, candihelper.HeaderMIMEApplicationJSON)
+	w.Header().Set(candihelper.HeaderCacheControl, "public, max-age=300")
+	w.Write(candihelper.ToBytes(i.JWKS()))
+}
+
+// issue sign access token and rotate refresh secret, oldHash empty for new session
+func (i *TokenIssuer) issue(ctx context.Context, refresh *RefreshToken, oldHash string) (*TokenPair, error) {
+	claim := new(candishared.TokenClaim)
+	claim.Subject = refresh.Subject
+	if i.claimsBuilder != nil {
+		if err := i.claimsBuilder(ctx, refresh.Subject, claim); err != nil {
+			return nil, err
+		}
+	}
+	accessToken, err := i.Sign(claim)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := randomHex(32)
+	refresh.SecretHash = hashRefreshSecret(secret)
+	refresh.ExpiresAt = i.clock.Now().Add(i.refreshTTL)
+	if oldHash == "" {
+		err = i.store.Save(ctx, refresh)
+	} else {
+		err = i.store.Rotate(ctx, refresh.FamilyID, oldHash, refresh)
+		if errors.Is(err, ErrInvalidRefreshToken) {
+			// secret has been rotated by other request with same token, treat as reuse
+			i.store.Delete(ctx, refresh.FamilyID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    claim.ExpiresAt - i.clock.Now().Unix(),
+		RefreshToken: refresh.FamilyID + "." + secret,
+	}, nil
+}
+
+func randomHex(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenIssuer(t *testing.T) {
+	ctx := context.Background()
+	clock := testkit.NewFakeClock(time.Now())
+	key, err := ParseSigningKey("k1", "HS256", []byte(strings.Repeat("s", 32)))
+	require.NoError(t, err)
+	_, err = ParseSigningKey("k1", "HS256", []byte("short"))
+	assert.Error(t, err)
+
+	role := "admin"
+	issuer, err := NewTokenIssuer([]SigningKey{key},
+		TokenIssuerSetIssuer("auth"), TokenIssuerSetAudience("api"), TokenIssuerSetClock(clock),
+		TokenIssuerSetClaimsBuilder(func(ctx context.Context, subject string, claim *candishared.TokenClaim) error {
+			claim.Role = role
+			return nil
+		}))
+	require.NoError(t, err)
+
+	pair, err := issuer.Issue(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(15*60), pair.ExpiresIn)
+	claim, err := issuer.ValidateToken(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claim.Subject)
+	assert.Equal(t, "admin", claim.Role)
+	assert.Equal(t, "auth", claim.Issuer)
+
+	clock.Advance(16 * time.Minute)
+	_, err = issuer.ValidateToken(ctx, pair.AccessToken)
+	assert.EqualError(t, err, "Token is expired")
+
+	role = "viewer"
+	refreshed, err := issuer.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	claim, err = issuer.ValidateToken(ctx, refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", claim.Role, "claim is rebuilt on refresh")
+
+	_, err = issuer.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken, "rotated refresh token cannot be reused")
+	_, err = issuer.Refresh(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenNotFound, "session is revoked after reuse detected")
+
+	pair, err = issuer.Issue(ctx, "user-1")
+	require.NoError(t, err)
+	require.NoError(t, issuer.Revoke(ctx, pair.RefreshToken))
+	_, err = issuer.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenNotFound)
+
+	other, _ := NewTokenIssuer([]SigningKey{key}, TokenIssuerSetIssuer("auth"), TokenIssuerSetAudience("web"), TokenIssuerSetClock(clock))
+	token, _ := other.Sign(&candishared.TokenClaim{})
+	_, err = issuer.ValidateToken(ctx, token)
+	assert.EqualError(t, err, "Invalid token audience")
+}
+
+func TestTokenIssuerConcurrentRefresh(t *testing.T) {
+	ctx := context.Background()
+	key, _ := ParseSigningKey("k1", "HS256", []byte(strings.Repeat("s", 32)))
+	issuer, err := NewTokenIssuer([]SigningKey{key})
+	require.NoError(t, err)
+	pair, err := issuer.Issue(ctx, "user-1")
+	require.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		success atomic.Int32
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := issuer.Refresh(ctx, pair.RefreshToken); err == nil {
+				success.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), success.Load(), "same refresh token only rotated once")
+}
+
+func TestMemoryRefreshTokenStoreClock(t *testing.T) {
+	ctx := context.Background()
+	clock := testkit.NewFakeClock(time.Now())
+	store := newMemoryRefreshTokenStore(clock)
+	require.NoError(t, store.Save(ctx, &RefreshToken{FamilyID: "a", SecretHash: "h1", ExpiresAt: clock.Now().Add(time.Minute)}))
+
+	assert.ErrorIs(t, store.Rotate(ctx, "a", "other", &RefreshToken{FamilyID: "a", SecretHash: "h2"}), ErrInvalidRefreshToken)
+	assert.ErrorIs(t, store.Rotate(ctx, "b", "h1", &RefreshToken{FamilyID: "b"}), ErrRefreshTokenNotFound)
+
+	clock.Advance(2 * time.Minute)
+	require.NoError(t, store.Save(ctx, &RefreshToken{FamilyID: "b", ExpiresAt: clock.Now().Add(time.Minute)}))
+	_, err := store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrRefreshTokenNotFound, "expired session removed using injected clock")
+}
+
+func TestTokenIssuerKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+	newKey, err := ParseSigningKey("2024-02", "ES256", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+	require.NoError(t, err)
+	oldKey := SigningKey{ID: "2024-01", Algorithm: "RS256", Key: rsaKey}
+
+	previous, _ := NewTokenIssuer([]SigningKey{oldKey})
+	oldToken, _ := previous.Sign(&candishared.TokenClaim{})
+
+	issuer, err := NewTokenIssuer([]SigningKey{newKey, oldKey})
+	require.NoError(t, err)
+	newToken, _ := issuer.Sign(&candishared.TokenClaim{})
+	_, err = issuer.ValidateToken(ctx, oldToken)
+	assert.NoError(t, err, "token signed with previous key is still valid")
+	_, err = issuer.ValidateToken(ctx, newToken)
+	assert.NoError(t, err)
+
+	keys := issuer.JWKS()["keys"].([]map[string]string)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "EC", keys[0]["kty"])
+	assert.Equal(t, "2024-02", keys[0]["kid"])
+	assert.Equal(t, "RSA", keys[1]["kty"])
+
+	_, err = NewTokenIssuer([]SigningKey{{ID: "pub", Algorithm: "RS256", Key: &rsaKey.PublicKey}})
+	assert.Error(t, err, "public key cannot sign token")
+}
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+var (
+	// ErrRefreshTokenNotFound refresh token session not found or expired
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrInvalidRefreshToken refresh token is invalid or already used
+	ErrInvalidRefreshToken = errors.New("Invalid refresh token")
+)
+
+type (
+	// RefreshToken session of refresh token, one record per login (family) and rotated on each refresh,
+	// only hash of current secret is stored
+	RefreshToken struct {
+		FamilyID   string    `json:"family_id"`
+		Subject    string    `json:"sub"`
+		SecretHash string    `json:"secret_hash"`
+		IssuedAt   time.Time `json:"issued_at"`
+		ExpiresAt  time.Time `json:"expires_at"`
+	}
+
+	// RefreshTokenStore abstract store of refresh token session, record must be expired at RefreshToken.ExpiresAt.
+	// Rotate must be atomic compare-and-swap: replace session only if current secret hash is oldHash,
+	// return ErrInvalidRefreshToken if secret already rotated (concurrent refresh with same token)
+	RefreshTokenStore interface {
+		Get(ctx context.Context, familyID string) (*RefreshToken, error)
+		Save(ctx context.Context, token *RefreshToken) error
+		Rotate(ctx context.Context, familyID, oldHash string, token *RefreshToken) error
+		Delete(ctx context.Context, familyID string) error
+	}
+)
+
+func (i *TokenIssuer) lookupRefreshToken(ctx context.Context, refreshToken string) (*RefreshToken, error) {
+	familyID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || familyID == "" || secret == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	refresh, err := i.store.Get(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if !i.clock.Now().Before(refresh.ExpiresAt) {
+		i.store.Delete(ctx, familyID)
+		return nil, ErrRefreshTokenNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(refresh.SecretHash), []byte(hashRefreshSecret(secret))) != 1 {
+		// rotated token is reused, revoke session
+		i.store.Delete(ctx, familyID)
+		return nil, ErrInvalidRefreshToken
+	}
+	return refresh, nil
+}
+
+func hashRefreshSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+type memoryRefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]RefreshToken
+	clock  candihelper.Clock
+}
+
+// NewMemoryRefreshTokenStore in memory refresh token store, only for single replica service
+func NewMemoryRefreshTokenStore() RefreshTokenStore {
+	return newMemoryRefreshTokenStore(candihelper.SystemClock)
+}
+
+func newMemoryRefreshTokenStore(clock candihelper.Clock) *memoryRefreshTokenStore {
+	return &memoryRefreshTokenStore{tokens: make(map[string]RefreshToken), clock: clock}
+}
+
+func (s *memoryRefreshTokenStore) Get(ctx context.Context, familyID string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[familyID]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+	return &token, nil
+}
+
+func (s *memoryRefreshTokenStore) Save(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.save(token)
+	return nil
+}
+
+func (s *memoryRefreshTokenStore) Rotate(ctx context.Context, familyID, oldHash string, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.tokens[familyID]
+	if !ok {
+		return ErrRefreshTokenNotFound
+	}
+	if current.SecretHash != oldHash {
+		return ErrInvalidRefreshToken
+	}
+	s.save(token)
+	return nil
+}
+
+func (s *memoryRefreshTokenStore) save(token *RefreshToken) {
+	now := s.clock.Now()
+	for familyID, existing := range s.tokens {
+		if now.After(existing.ExpiresAt) {
+			delete(s.tokens, familyID)
+		}
+	}
+	s.tokens[token.FamilyID] = *token
+}
+
+func (s *memoryRefreshTokenStore) Delete(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, familyID)
+	return nil
+}
+
+type cacheRefreshTokenStore struct {
+	cache interfaces.Cache
+}
+
+// NewCacheRefreshTokenStore refresh token store using cache (example: redis), shared between replicas
+func NewCacheRefreshTokenStore(cache interfaces.Cache) RefreshTokenStore {
+	return &cacheRefreshTokenStore{cache: cache}
+}
+
+func (s *cacheRefreshTokenStore) Get(ctx context.Context, familyID string) (*RefreshToken, error) {
+	value, err := s.cache.Get(ctx, s.key(familyID))
+	if err != nil || len(value) == 0 {
+		return nil, ErrRefreshTokenNotFound
+	}
+	var token RefreshToken
+	if err := json.Unmarshal(value, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *cacheRefreshTokenStore) Save(ctx context.Context, token *RefreshToken) error {
+	value, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, s.key(token.FamilyID), value, time.Until(token.ExpiresAt))
+}
+
+// rotate session only if stored secret hash still same, return 1 success, 0 not found, -1 already rotated
+const refreshTokenRotateScript = `local value = redis.call("GET", KEYS[1])
+if not value then return 0 end
+if cjson.decode(value)["secret_hash"] ~= ARGV[1] then return -1 end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1`
+
+func (s *cacheRefreshTokenStore) Rotate(ctx context.Context, familyID, oldHash string, token *RefreshToken) error {
+	value, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(token.ExpiresAt).Milliseconds()
+	if ttl <= 0 {
+		ttl = 1
+	}
+	reply, err := s.cache.DoCommand(ctx, true, "EVAL", refreshTokenRotateScript, 1, s.key(familyID), oldHash, value, ttl)
+	if err != nil {
+		return err
+	}
+	switch result, _ := reply.(int64); result {
+	case 1:
+		return nil
+	case 0:
+		return ErrRefreshTokenNotFound
+	default:
+		return ErrInvalidRefreshToken
+	}
+}
+
+func (s *cacheRefreshTokenStore) Delete(ctx context.Context, familyID string) error {
+	return s.cache.Delete(ctx, s.key(familyID))
+}
+
+func (s *cacheRefreshTokenStore) key(familyID string) string {
+	return "candi:refresh_token:" + familyID
+}
@@ -0,0 +1,237 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/wrapper"
+)
+
+// loginState pending login, stored by state value until callback
+type loginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	ReturnTo     string `json:"return_to"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+}
+
+// LoginHandler redirect to provider authorization endpoint, "return_to" query param (relative path) is redirect
+// target after login success
+func (rp *RelyingParty) LoginHandler(w http.ResponseWriter, req *http.Request) {
+	state, nonce, verifier := randomString(), randomString(), randomString()
+	returnTo := req.URL.Query().Get("return_to")
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		returnTo = "/" // prevent open redirect
+	}
+
+	if err := rp.setJSON(req.Context(), stateKey(state), loginState{
+		Nonce: nonce, CodeVerifier: verifier, ReturnTo: returnTo,
+	}, rp.stateTTL); err != nil {
+		wrapper.NewHTTPResponse(http.StatusInternalServerError, err.Error()).JSON(w)
+		return
+	}
+	// bind state to browser, so callback url of other user login cannot be used (login CSRF)
+	http.SetCookie(w, &http.Cookie{
+		Name: rp.stateCookieName(), Value: hashState(state), Path: "/", MaxAge: int(rp.stateTTL.Seconds()),
+		HttpOnly: true, Secure: rp.cookieSecure, SameSite: http.SameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {rp.clientID},
+		"redirect_uri":          {rp.redirectURL},
+		"scope":                 {strings.Join(rp.scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	http.Redirect(w, req, appendQuery(rp.provider.AuthorizationEndpoint, query), http.StatusFound)
+}
+
+// CallbackHandler handle redirect from provider, verify state is bound to browser (state cookie), exchange
+// authorization code to token, verify id token and nonce, then create session and redirect to return url
+func (rp *RelyingParty) CallbackHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	query := req.URL.Query()
+
+	stateCookie, err := req.Cookie(rp.stateCookieName())
+	http.SetCookie(w, &http.Cookie{
+		Name: rp.stateCookieName(), Value: "", Path: "/", MaxAge: -1,
+		HttpOnly: true, Secure: rp.cookieSecure, SameSite: http.SameSiteLaxMode,
+	})
+	if err != nil || subtle.ConstantTimeCompare([]byte(stateCookie.Value), []byte(hashState(query.Get("state")))) != 1 {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "invalid or expired login state").JSON(w)
+		return
+	}
+
+	if errCode := query.Get("error"); errCode != "" {
+		wrapper.NewHTTPResponse(http.StatusUnauthorized, strings.TrimSpace(errCode+": "+query.Get("error_description"))).JSON(w)
+		return
+	}
+
+	var state loginState
+	if err := rp.getJSON(ctx, stateKey(query.Get("state")), &state); err != nil {
+		wrapper.NewHTTPResponse(http.StatusBadRequest, "invalid or expired login state").JSON(w)
+		return
+	}
+	rp.store.Delete(ctx, stateKey(query.Get("state")))
+
+	token, err := rp.exchange(ctx, query.Get("code"), state.CodeVerifier)
+	if err != nil {
+		wrapper.NewHTTPResponse(http.StatusUnauthorized, err.Error()).JSON(w)
+		return
+	}
+	claims, err := rp.VerifyIDToken(ctx, token.IDToken)
+	if err != nil {
+		wrapper.NewHTTPResponse(http.StatusUnauthorized, err.Error()).JSON(w)
+		return
+	}
+	if nonce, _ := claims["nonce"].(string); nonce != state.Nonce {
+		wrapper.NewHTTPResponse(http.StatusUnauthorized, "oidc: invalid id token nonce").JSON(w)
+		return
+	}
+
+	sessionID := randomString()
+	if err := rp.setJSON(ctx, sessionKey(sessionID), newSession(claims, token, rp.clock.Now().Add(rp.sessionTTL)), rp.sessionTTL); err != nil {
+		wrapper.NewHTTPResponse(http.StatusInternalServerError, err.Error()).JSON(w)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name: rp.cookieName, Value: sessionID, Path: "/", MaxAge: int(rp.sessionTTL.Seconds()),
+		HttpOnly: true, Secure: rp.cookieSecure, SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, req, state.ReturnTo, http.StatusFound)
+}
+
+// LogoutHandler delete session, then redirect to provider end session endpoint (if supported)
+func (rp *RelyingParty) LogoutHandler(w http.ResponseWriter, req *http.Request) {
+	var idToken string
+	if cookie, err := req.Cookie(rp.cookieName); err == nil {
+		if session, err := rp.Session(req); err == nil {
+			idToken = session.IDToken
+		}
+		rp.store.Delete(req.Context(), sessionKey(cookie.Value))
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name: rp.cookieName, Value: "", Path: "/", MaxAge: -1,
+		HttpOnly: true, Secure: rp.cookieSecure, SameSite: http.SameSiteLaxMode,
+	})
+
+	if rp.provider.EndSessionEndpoint == "" {
+		redirect := rp.postLogoutRedirectURL
+		if redirect == "" {
+			redirect = "/"
+		}
+		http.Redirect(w, req, redirect, http.StatusFound)
+		return
+	}
+	query := url.Values{"client_id": {rp.clientID}}
+	if idToken != "" {
+		query.Set("id_token_hint", idToken)
+	}
+	if rp.postLogoutRedirectURL != "" {
+		query.Set("post_logout_redirect_uri", rp.postLogoutRedirectURL)
+	}
+	http.Redirect(w, req, appendQuery(rp.provider.EndSessionEndpoint, query), http.StatusFound)
+}
+
+func (rp *RelyingParty) exchange(ctx context.Context, code, codeVerifier string) (token tokenResponse, err error) {
+	if code == "" {
+		return token, errors.New("oidc: missing authorization code")
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {rp.redirectURL},
+		"client_id":     {rp.clientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rp.provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if rp.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(rp.clientID), url.QueryEscape(rp.clientSecret))
+	}
+
+	resp, err := rp.httpClient.Do(req)
+	if err != nil {
+		return token, fmt.Errorf("oidc: token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return token, fmt.Errorf("oidc: token exchange: unexpected status %s: %s", resp.Status, body)
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return token, fmt.Errorf("oidc: token exchange: %w", err)
+	}
+	if token.IDToken == "" {
+		return token, errors.New("oidc: token exchange: missing id_token")
+	}
+	return token, nil
+}
+
+func (rp *RelyingParty) getJSON(ctx context.Context, key string, target any) error {
+	b, err := rp.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
+func (rp *RelyingParty) setJSON(ctx context.Context, key string, value any, ttl time.Duration) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return rp.store.Set(ctx, key, b, ttl)
+}
+
+func stateKey(state string) string {
+	return "oidc:state:" + state
+}
+
+func (rp *RelyingParty) stateCookieName() string {
+	return rp.cookieName + "_state"
+}
+
+func hashState(state string) string {
+	hash := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func appendQuery(endpoint string, query url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+	return endpoint + "?" + query.Encode()
+}
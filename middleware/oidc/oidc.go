@@ -0,0 +1,144 @@
+// Package oidc OpenID Connect relying party for SSO login in service serving web UI, implement authorization code
+// flow with PKCE (state, nonce, token exchange, id token verification with provider JWKS) and cookie session
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// ProviderMetadata OpenID provider metadata from discovery document
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// RelyingParty OpenID Connect client
+type RelyingParty struct {
+	clientID    string
+	redirectURL string
+	provider    ProviderMetadata
+	keys        *keySet
+
+	clientSecret          string
+	scopes                []string
+	httpClient            *http.Client
+	store                 Store
+	sessionTTL, stateTTL  time.Duration
+	cookieName            string
+	cookieSecure          bool
+	loginPath, logoutPath string
+	postLogoutRedirectURL string
+	clock                 candihelper.Clock
+}
+
+/*
+New create relying party, provider metadata is loaded from "{issuer}/.well-known/openid-configuration",
+redirectURL is callback url registered in provider. Example in REST handler:
+
+	rp, err := oidc.New(ctx, "https://accounts.google.com", clientID, "https://admin.example.com/auth/callback",
+		oidc.SetClientSecret(clientSecret), oidc.SetStore(oidc.NewCacheStore(redisCache)))
+	...
+	func (h *RestHandler) Mount(root interfaces.RESTRouter) {
+		rp.Mount(root) // GET /auth/login, GET /auth/callback, GET /auth/logout
+		admin := root.Group("/admin", rp.RequireLogin)
+		...
+	}
+*/
+func New(ctx context.Context, issuer, clientID, redirectURL string, opts ...OptionFunc) (*RelyingParty, error) {
+	callback, err := url.Parse(redirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid redirect url: %w", err)
+	}
+
+	rp := &RelyingParty{
+		clientID:     clientID,
+		redirectURL:  redirectURL,
+		scopes:       []string{"openid", "profile", "email"},
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		store:        NewMemoryStore(),
+		sessionTTL:   8 * time.Hour,
+		stateTTL:     10 * time.Minute,
+		cookieName:   "candi_session",
+		cookieSecure: callback.Scheme == "https",
+		loginPath:    "/auth/login",
+		logoutPath:   "/auth/logout",
+		clock:        candihelper.SystemClock,
+	}
+	for _, opt := range opts {
+		opt(rp)
+	}
+
+	if err := rp.discover(ctx, issuer); err != nil {
+		return nil, err
+	}
+	rp.keys = &keySet{uri: rp.provider.JWKSURI, httpClient: rp.httpClient}
+	return rp, nil
+}
+
+// Provider provider metadata
+func (rp *RelyingParty) Provider() ProviderMetadata {
+	return rp.provider
+}
+
+// Mount register login, callback (path from redirect url) and logout handler to router
+func (rp *RelyingParty) Mount(router interfaces.RESTRouter) {
+	callbackPath := "/"
+	if u, err := url.Parse(rp.redirectURL); err == nil && u.Path != "" {
+		callbackPath = u.Path
+	}
+	router.GET(rp.loginPath, rp.LoginHandler)
+	router.GET(callbackPath, rp.CallbackHandler)
+	router.GET(rp.logoutPath, rp.LogoutHandler)
+	router.POST(rp.logoutPath, rp.LogoutHandler)
+}
+
+// MountServeMux register login, callback (path from redirect url) and logout handler to standard http.ServeMux
+func (rp *RelyingParty) MountServeMux(mux *http.ServeMux) {
+	callbackPath := "/"
+	if u, err := url.Parse(rp.redirectURL); err == nil && u.Path != "" {
+		callbackPath = u.Path
+	}
+	mux.HandleFunc("GET "+rp.loginPath, rp.LoginHandler)
+	mux.HandleFunc("GET "+callbackPath, rp.CallbackHandler)
+	mux.HandleFunc(rp.logoutPath, rp.LogoutHandler)
+}
+
+func (rp *RelyingParty) discover(ctx context.Context, issuer string) error {
+	issuer = strings.TrimSuffix(issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := rp.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: discovery: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: discovery: unexpected status %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rp.provider); err != nil {
+		return fmt.Errorf("oidc: discovery: %w", err)
+	}
+	if rp.provider.Issuer != issuer {
+		return fmt.Errorf("oidc: discovery: issuer %q does not match %q", rp.provider.Issuer, issuer)
+	}
+	if rp.provider.AuthorizationEndpoint == "" || rp.provider.TokenEndpoint == "" || rp.provider.JWKSURI == "" {
+		return errors.New("oidc: discovery: missing authorization, token or jwks endpoint")
+	}
+	return nil
+}
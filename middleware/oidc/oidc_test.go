@@ -0,0 +1,186 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	*httptest.Server
+	key                    *rsa.PrivateKey
+	challenge, nonce, code string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	p := &fakeProvider{key: key, code: "auth-code"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ProviderMetadata{
+			Issuer: p.URL, AuthorizationEndpoint: p.URL + "/authorize", TokenEndpoint: p.URL + "/token",
+			JWKSURI: p.URL + "/jwks", EndSessionEndpoint: p.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "key-1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		user, pass, _ := r.BasicAuth()
+		if r.PostForm.Get("code") != p.code || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge ||
+			user != "client-id" || pass != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "access", "id_token": p.idToken(t, p.nonce, "client-id")})
+	})
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+func (p *fakeProvider) idToken(t *testing.T, nonce, audience string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": p.URL, "aud": audience, "sub": "user-1", "email": "user@example.com", "role": "admin",
+		"nonce": nonce, "exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix(),
+	})
+	token.Header["kid"] = "key-1"
+	s, err := token.SignedString(p.key)
+	assert.NoError(t, err)
+	return s
+}
+
+func TestLoginFlow(t *testing.T) {
+	provider := newFakeProvider(t)
+	defer provider.Close()
+
+	rp, err := New(context.Background(), provider.URL, "client-id", "http://localhost/auth/callback", SetClientSecret("secret"))
+	assert.NoError(t, err)
+
+	protected := rp.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, _ := SessionFromContext(r.Context())
+		w.Write([]byte(session.Email))
+	}))
+
+	// unauthenticated browser request redirected to login
+	req := httptest.NewRequest(http.MethodGet, "/admin?page=1", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/auth/login?return_to=%2Fadmin%3Fpage%3D1", rec.Header().Get("Location"))
+
+	// login redirect to provider with pkce challenge
+	rec = httptest.NewRecorder()
+	rp.LoginHandler(rec, httptest.NewRequest(http.MethodGet, "/auth/login?return_to=%2Fadmin%3Fpage%3D1", nil))
+	authURL, _ := url.Parse(rec.Header().Get("Location"))
+	assert.Equal(t, provider.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	provider.challenge, provider.nonce = authURL.Query().Get("code_challenge"), authURL.Query().Get("nonce")
+	state := authURL.Query().Get("state")
+	stateCookies := rec.Result().Cookies()
+	require.Len(t, stateCookies, 1)
+	assert.True(t, stateCookies[0].HttpOnly)
+	callbackRequest := func(state string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=auth-code&state="+state, nil)
+		req.AddCookie(stateCookies[0])
+		return req
+	}
+
+	// callback with invalid state
+	rec = httptest.NewRecorder()
+	rp.CallbackHandler(rec, callbackRequest("invalid"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// callback url opened in other browser (login CSRF) rejected
+	rec = httptest.NewRecorder()
+	rp.CallbackHandler(rec, httptest.NewRequest(http.MethodGet, "/auth/callback?code=auth-code&state="+state, nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// callback create session and clear state cookie
+	rec = httptest.NewRecorder()
+	rp.CallbackHandler(rec, callbackRequest(state))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/admin?page=1", rec.Header().Get("Location"))
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 2)
+	assert.Equal(t, "candi_session_state", cookies[0].Name)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+	cookies = cookies[1:]
+	assert.True(t, cookies[0].HttpOnly)
+
+	// state can only be used once
+	rec = httptest.NewRecorder()
+	rp.CallbackHandler(rec, callbackRequest(state))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user@example.com", rec.Body.String())
+
+	// logout delete session
+	req = httptest.NewRequest(http.MethodGet, "/auth/logout", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	rp.LogoutHandler(rec, req)
+	logoutURL, _ := url.Parse(rec.Header().Get("Location"))
+	assert.Equal(t, "/logout", logoutURL.Path)
+	assert.NotEmpty(t, logoutURL.Query().Get("id_token_hint"))
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider := newFakeProvider(t)
+	defer provider.Close()
+	clock := testkit.NewFakeClock(time.Now())
+	rp, err := New(context.Background(), provider.URL, "client-id", "http://localhost/auth/callback", SetClock(clock))
+	assert.NoError(t, err)
+
+	claims, err := rp.VerifyIDToken(context.Background(), provider.idToken(t, "n", "client-id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims["sub"])
+
+	_, err = rp.VerifyIDToken(context.Background(), provider.idToken(t, "n", "other-client"))
+	assert.Error(t, err)
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"iss": provider.URL, "aud": "client-id", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "key-1"
+	forged, _ := token.SignedString(otherKey)
+	_, err = rp.VerifyIDToken(context.Background(), forged)
+	assert.Error(t, err)
+
+	clock.Advance(2 * time.Hour)
+	_, err = rp.VerifyIDToken(context.Background(), provider.idToken(t, "n", "client-id"))
+	assert.EqualError(t, err, "oidc: id token is expired")
+}
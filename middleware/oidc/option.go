@@ -0,0 +1,82 @@
+package oidc
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+)
+
+// OptionFunc type
+type OptionFunc func(*RelyingParty)
+
+// SetClientSecret option func, for confidential client (sent with http basic auth in token request)
+func SetClientSecret(secret string) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.clientSecret = secret
+	}
+}
+
+// SetScopes option func, default "openid profile email"
+func SetScopes(scopes ...string) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.scopes = scopes
+	}
+}
+
+// SetHTTPClient option func
+func SetHTTPClient(client *http.Client) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.httpClient = client
+	}
+}
+
+// SetStore option func, session and login state store (default in memory, use NewCacheStore for multiple replicas)
+func SetStore(store Store) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.store = store
+	}
+}
+
+// SetSessionTTL option func, default 8 hours
+func SetSessionTTL(ttl time.Duration) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.sessionTTL = ttl
+	}
+}
+
+// SetCookie option func, session cookie name (default "candi_session") and secure flag (default true if redirect url is https)
+func SetCookie(name string, secure bool) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.cookieName = name
+		rp.cookieSecure = secure
+	}
+}
+
+// SetLoginPath option func, default "/auth/login"
+func SetLoginPath(path string) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.loginPath = path
+	}
+}
+
+// SetLogoutPath option func, default "/auth/logout"
+func SetLogoutPath(path string) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.logoutPath = path
+	}
+}
+
+// SetPostLogoutRedirectURL option func, redirect url after logout from provider (must be registered in provider)
+func SetPostLogoutRedirectURL(redirectURL string) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.postLogoutRedirectURL = redirectURL
+	}
+}
+
+// SetClock option func, clock for id token and session expiry check (default system clock)
+func SetClock(clock candihelper.Clock) OptionFunc {
+	return func(rp *RelyingParty) {
+		rp.clock = clock
+	}
+}
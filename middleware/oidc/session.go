@@ -0,0 +1,179 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/wrapper"
+)
+
+// ErrNotFound key not found (or expired) in store
+var ErrNotFound = errors.New("not found")
+
+type contextKey struct{}
+
+// Session login session
+type Session struct {
+	Subject      string         `json:"sub"`
+	Email        string         `json:"email,omitempty"`
+	Name         string         `json:"name,omitempty"`
+	Claims       map[string]any `json:"claims"`
+	IDToken      string         `json:"id_token"`
+	AccessToken  string         `json:"access_token"`
+	RefreshToken string         `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time      `json:"expires_at"`
+}
+
+// TokenClaim convert session to token claim, for use with ACL permission middleware
+func (s *Session) TokenClaim() *candishared.TokenClaim {
+	claim := &candishared.TokenClaim{Additional: s.Claims}
+	claim.Subject = s.Subject
+	claim.ExpiresAt = s.ExpiresAt.Unix()
+	claim.Issuer, _ = s.Claims["iss"].(string)
+	claim.Role, _ = s.Claims["role"].(string)
+	claim.Locale, _ = s.Claims["locale"].(string)
+	return claim
+}
+
+// SessionFromContext get login session from context (set by RequireLogin middleware)
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(contextKey{}).(*Session)
+	return session, ok
+}
+
+// RequireLogin http middleware, browser request (GET with html accept header) without valid session is redirected
+// to login, other request is responded unauthorized. Session and token claim is set to request context
+func (rp *RelyingParty) RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		session, err := rp.Session(req)
+		if err != nil {
+			if req.Method == http.MethodGet && strings.Contains(req.Header.Get("Accept"), "text/html") {
+				http.Redirect(w, req, rp.loginPath+"?return_to="+url.QueryEscape(req.URL.RequestURI()), http.StatusFound)
+				return
+			}
+			wrapper.NewHTTPResponse(http.StatusUnauthorized, "Unauthorized").JSON(w)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), contextKey{}, session)
+		ctx = candishared.SetToContext(ctx, candishared.ContextKeyTokenClaim, session.TokenClaim())
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// Session get login session from request cookie
+func (rp *RelyingParty) Session(req *http.Request) (*Session, error) {
+	cookie, err := req.Cookie(rp.cookieName)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var session Session
+	if err := rp.getJSON(req.Context(), sessionKey(cookie.Value), &session); err != nil {
+		return nil, err
+	}
+	if rp.clock.Now().After(session.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func newSession(claims jwt.MapClaims, token tokenResponse, expiresAt time.Time) *Session {
+	session := &Session{
+		Claims: claims, IDToken: token.IDToken, AccessToken: token.AccessToken, RefreshToken: token.RefreshToken,
+		ExpiresAt: expiresAt,
+	}
+	session.Subject, _ = claims["sub"].(string)
+	session.Email, _ = claims["email"].(string)
+	session.Name, _ = claims["name"].(string)
+	return session
+}
+
+func sessionKey(id string) string {
+	return "oidc:session:" + id
+}
+
+// Store session and login state store
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value    []byte
+	expireAt time.Time
+}
+
+// NewMemoryStore in memory store, only for single replica service
+func NewMemoryStore() Store {
+	return &memoryStore{items: make(map[string]memoryItem)}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	if !ok || time.Now().After(item.expireAt) {
+		delete(s.items, key)
+		return nil, ErrNotFound
+	}
+	return item.value, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, item := range s.items {
+		if now.After(item.expireAt) {
+			delete(s.items, k)
+		}
+	}
+	s.items[key] = memoryItem{value: value, expireAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+type cacheStore struct {
+	cache interfaces.Cache
+}
+
+// NewCacheStore store using cache (example: redis), session is shared between replicas
+func NewCacheStore(cache interfaces.Cache) Store {
+	return &cacheStore{cache: cache}
+}
+
+func (s *cacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.cache.Get(ctx, key)
+	if err != nil || len(value) == 0 {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *cacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.cache.Set(ctx, key, value, ttl)
+}
+
+func (s *cacheStore) Delete(ctx context.Context, key string) error {
+	return s.cache.Delete(ctx, key)
+}
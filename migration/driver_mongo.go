@@ -0,0 +1,182 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDriver migration driver for mongodb. Migration file (.json) contains json array of database command
+// (extended json), example: [{"createIndexes": "users", "indexes": [{"key": {"email": 1}, "name": "email_1", "unique": true}]}]
+type MongoDriver struct {
+	db    *mongo.Database
+	opt   driverOption
+	owner string
+}
+
+// NewMongoDriver create mongo migration driver
+func NewMongoDriver(db *mongo.Database, opts ...DriverOption) *MongoDriver {
+	hostname, _ := os.Hostname()
+	return &MongoDriver{
+		db:    db,
+		opt:   newDriverOption(opts),
+		owner: fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// Extension json file
+func (d *MongoDriver) Extension() string {
+	return "json"
+}
+
+// Lock acquire lease lock in "{table}_lock" collection, lease is extended until unlock
+func (d *MongoDriver) Lock(ctx context.Context) (unlock func(), err error) {
+	coll := d.db.Collection(d.opt.table + "_lock")
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		ok, err := d.acquire(ctx, coll)
+		if err != nil {
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquire migration lock: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		extend := time.NewTicker(d.opt.lockTTL / 2)
+		defer extend.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-extend.C:
+				coll.UpdateOne(context.Background(), bson.M{"_id": "lock", "owner": d.owner},
+					bson.M{"$set": bson.M{"expire_at": time.Now().Add(d.opt.lockTTL)}})
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		coll.DeleteOne(context.Background(), bson.M{"_id": "lock", "owner": d.owner})
+	}, nil
+}
+
+func (d *MongoDriver) acquire(ctx context.Context, coll *mongo.Collection) (bool, error) {
+	now := time.Now()
+	_, err := coll.InsertOne(ctx, bson.M{"_id": "lock", "owner": d.owner, "expire_at": now.Add(d.opt.lockTTL)})
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// take over expired lock
+	err = coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": "lock", "expire_at": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"owner": d.owner, "expire_at": now.Add(d.opt.lockTTL)}},
+	).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Version get current version
+func (d *MongoDriver) Version(ctx context.Context) (version uint64, dirty bool, err error) {
+	var doc struct {
+		Version int64 `bson:"version"`
+		Dirty   bool  `bson:"dirty"`
+	}
+	err = d.db.Collection(d.opt.table).FindOne(ctx, bson.M{}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, false, nil
+	}
+	return uint64(doc.Version), doc.Dirty, err
+}
+
+// SetVersion replace current version
+func (d *MongoDriver) SetVersion(ctx context.Context, version uint64, dirty bool) error {
+	coll := d.db.Collection(d.opt.table)
+	if version == 0 && !dirty {
+		_, err := coll.DeleteMany(ctx, bson.M{})
+		return err
+	}
+	_, err := coll.ReplaceOne(ctx, bson.M{}, bson.M{"version": int64(version), "dirty": dirty}, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Exec run each database command in migration file
+func (d *MongoDriver) Exec(ctx context.Context, migration []byte) error {
+	commands, err := parseMongoCommands(migration)
+	if err != nil {
+		return err
+	}
+	for i, cmd := range commands {
+		if err := d.db.RunCommand(ctx, cmd).Err(); err != nil {
+			return fmt.Errorf("command #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func parseMongoCommands(migration []byte) (commands []bson.D, err error) {
+	if len(strings.TrimSpace(candihelper.ByteToString(migration))) == 0 {
+		return nil, nil
+	}
+	// extended json must be document, wrap command list
+	var wrapper struct {
+		Commands []bson.D `bson:"commands"`
+	}
+	content := append(append([]byte(`{"commands":`), migration...), '}')
+	if err := bson.UnmarshalExtJSON(content, false, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid mongo migration, must be json array of command: %w", err)
+	}
+	return wrapper.Commands, nil
+}
+
+// Seeded get applied seeds
+func (d *MongoDriver) Seeded(ctx context.Context) (map[string]time.Time, error) {
+	cur, err := d.db.Collection(d.opt.seedTable).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		Name     string    `bson:"_id"`
+		SeededAt time.Time `bson:"seeded_at"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	seeded := make(map[string]time.Time, len(docs))
+	for _, doc := range docs {
+		seeded[doc.Name] = doc.SeededAt
+	}
+	return seeded, nil
+}
+
+// MarkSeeded insert seed to history
+func (d *MongoDriver) MarkSeeded(ctx context.Context, name string) error {
+	_, err := d.db.Collection(d.opt.seedTable).InsertOne(ctx, bson.M{"_id": name, "seeded_at": time.Now().UTC()})
+	return err
+}
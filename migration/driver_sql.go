@@ -0,0 +1,169 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"time"
+)
+
+// SQLDriver migration driver for postgres, mysql and sqlite3.
+// Lock using postgres advisory lock and mysql named lock, for mysql multi statement migration file set "multiStatements=true" in dsn
+type SQLDriver struct {
+	db         *sql.DB
+	driverName string
+	opt        driverOption
+}
+
+// NewSQLDriver create sql migration driver, create version table if not exist
+func NewSQLDriver(db *sql.DB, opts ...DriverOption) (*SQLDriver, error) {
+	dbDriverType := fmt.Sprintf("%T", db.Driver())
+	driverName, ok := map[string]string{
+		"*pq.Driver":            "postgres",
+		"*stdlib.Driver":        "postgres",
+		"*mysql.MySQLDriver":    "mysql",
+		"*sqlite3.SQLiteDriver": "sqlite3",
+	}[dbDriverType]
+	if !ok {
+		return nil, fmt.Errorf("unknown SQL driver %s for migration, only support postgres, mysql, or sqlite3 driver", dbDriverType)
+	}
+
+	d := &SQLDriver{db: db, driverName: driverName, opt: newDriverOption(opts)}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + d.opt.table + ` (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	if err != nil {
+		return nil, fmt.Errorf("create migration table: %w", err)
+	}
+	return d, nil
+}
+
+// Extension sql file
+func (d *SQLDriver) Extension() string {
+	return "sql"
+}
+
+// Lock acquire database lock with dedicated connection (lock is released when connection closed)
+func (d *SQLDriver) Lock(ctx context.Context) (unlock func(), err error) {
+	if d.driverName == "sqlite3" {
+		return func() {}, nil
+	}
+
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var lockQuery, unlockQuery string
+	var lockKey any
+	switch d.driverName {
+	case "postgres":
+		lockQuery, unlockQuery = `SELECT pg_advisory_lock($1)`, `SELECT pg_advisory_unlock($1)`
+		lockKey = int64(crc32.ChecksumIEEE([]byte("candi-migration:" + d.opt.table)))
+	case "mysql":
+		lockQuery, unlockQuery = `SELECT GET_LOCK(?, -1)`, `SELECT RELEASE_LOCK(?)`
+		lockKey = "candi-migration:" + d.opt.table
+	}
+
+	if _, err := conn.ExecContext(ctx, lockQuery, lockKey); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	return func() {
+		conn.ExecContext(context.Background(), unlockQuery, lockKey)
+		conn.Close()
+	}, nil
+}
+
+// Version get current version
+func (d *SQLDriver) Version(ctx context.Context) (version uint64, dirty bool, err error) {
+	err = d.db.QueryRowContext(ctx, `SELECT version, dirty FROM `+d.opt.table+` LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// SetVersion replace current version
+func (d *SQLDriver) SetVersion(ctx context.Context, version uint64, dirty bool) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+d.opt.table); err != nil {
+		return err
+	}
+	if version > 0 || dirty {
+		query := `INSERT INTO ` + d.opt.table + ` (version, dirty) VALUES (?, ?)`
+		if d.driverName == "postgres" {
+			query = strings.NewReplacer("?, ?", "$1, $2").Replace(query)
+		}
+		if _, err := tx.ExecContext(ctx, query, int64(version), dirty); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Exec execute sql migration file
+func (d *SQLDriver) Exec(ctx context.Context, migration []byte) error {
+	if len(strings.TrimSpace(string(migration))) == 0 {
+		return nil
+	}
+	_, err := d.db.ExecContext(ctx, string(migration))
+	return err
+}
+
+// Seeded get applied seeds, read only (seed history table is created on first MarkSeeded)
+func (d *SQLDriver) Seeded(ctx context.Context) (map[string]time.Time, error) {
+	exists, err := d.seedTableExists(ctx)
+	if err != nil || !exists {
+		return map[string]time.Time{}, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `SELECT name, seeded_at FROM `+d.opt.seedTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seeded := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var seededAt time.Time
+		if err := rows.Scan(&name, &seededAt); err != nil {
+			return nil, err
+		}
+		seeded[name] = seededAt
+	}
+	return seeded, rows.Err()
+}
+
+// MarkSeeded insert seed to history, create seed history table if not exist
+func (d *SQLDriver) MarkSeeded(ctx context.Context, name string) error {
+	_, err := d.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+d.opt.seedTable+` (name VARCHAR(255) NOT NULL PRIMARY KEY, seeded_at TIMESTAMP NOT NULL)`)
+	if err != nil {
+		return fmt.Errorf("create seed history table: %w", err)
+	}
+
+	query := `INSERT INTO ` + d.opt.seedTable + ` (name, seeded_at) VALUES (?, ?)`
+	if d.driverName == "postgres" {
+		query = strings.NewReplacer("?, ?", "$1, $2").Replace(query)
+	}
+	_, err = d.db.ExecContext(ctx, query, name, time.Now().UTC())
+	return err
+}
+
+func (d *SQLDriver) seedTableExists(ctx context.Context) (exists bool, err error) {
+	switch d.driverName {
+	case "postgres":
+		err = d.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, d.opt.seedTable).Scan(&exists)
+	case "mysql":
+		err = d.db.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?`, d.opt.seedTable).Scan(&exists)
+	default:
+		err = d.db.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?`, d.opt.seedTable).Scan(&exists)
+	}
+	return exists, err
+}
@@ -0,0 +1,305 @@
+// Package migration database migration runner with golang-migrate file format
+// ({version}_{title}.up.{ext} and {version}_{title}.down.{ext}), applied version is stored in "schema_migrations"
+// table/collection (compatible with golang-migrate). SQL migration file is executed as is,
+// Mongo migration file contains json array of database command (example: [{"createIndexes": "users", "indexes": [...]}])
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/logger"
+)
+
+var (
+	// ErrNoChange no migration applied
+	ErrNoChange = errors.New("no change")
+
+	fileNameRegex = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.(.*)$`)
+)
+
+// ErrDirty error when last migration is failed, fix database manually then force version (see Migrator.Force)
+type ErrDirty struct {
+	Version uint64
+}
+
+func (e ErrDirty) Error() string {
+	return fmt.Sprintf("dirty database version %d, fix database then force version", e.Version)
+}
+
+// Driver abstraction of migration target database
+type Driver interface {
+	// Extension migration file extension handled by driver, without dot (example: "sql")
+	Extension() string
+	// Lock acquire migration lock, block until acquired or context is done (only one replica applies migration)
+	Lock(ctx context.Context) (unlock func(), err error)
+	// Version current applied version, zero if no migration applied
+	Version(ctx context.Context) (version uint64, dirty bool, err error)
+	// SetVersion set current applied version
+	SetVersion(ctx context.Context, version uint64, dirty bool) error
+	// Exec execute migration file content
+	Exec(ctx context.Context, migration []byte) error
+}
+
+// Migration pair of up and down file with same version
+type Migration struct {
+	Version  uint64
+	Name     string
+	UpFile   string
+	DownFile string
+}
+
+// Status migration with applied status
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Migrator migration runner
+type Migrator struct {
+	driver     Driver
+	source     fs.FS
+	migrations []Migration
+}
+
+// New create migrator, load migration files (with driver extension) from root of source
+func New(driver Driver, source fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := fileNameRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil || match[4] != driver.Extension() {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", entry.Name())
+		}
+		mig, ok := migrations[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			migrations[version] = mig
+		}
+		target := &mig.UpFile
+		if match[3] == "down" {
+			target = &mig.DownFile
+		}
+		if *target != "" {
+			return nil, fmt.Errorf("migration %s: duplicate %s migration for version %d", entry.Name(), match[3], version)
+		}
+		*target = entry.Name()
+	}
+
+	m := &Migrator{driver: driver, source: source}
+	for _, mig := range migrations {
+		m.migrations = append(m.migrations, *mig)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	return m, nil
+}
+
+// Migrations list of migration sorted by version
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Version current applied version
+func (m *Migrator) Version(ctx context.Context) (version uint64, dirty bool, err error) {
+	return m.driver.Version(ctx)
+}
+
+// Status list of migration with applied status
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	version, _, err := m.driver.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		status[i] = Status{Migration: mig, Applied: mig.Version <= version}
+	}
+	return status, nil
+}
+
+// Up apply n next up migration, apply all if n <= 0
+func (m *Migrator) Up(ctx context.Context, n int) error {
+	return m.run(ctx, func(current uint64) (uint64, error) {
+		pending := m.migrations[m.nextIndex(current):]
+		if len(pending) == 0 {
+			return current, nil
+		}
+		if n <= 0 || n > len(pending) {
+			n = len(pending)
+		}
+		return pending[n-1].Version, nil
+	})
+}
+
+// Down apply n down migration from current version, apply all if n <= 0
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.run(ctx, func(current uint64) (uint64, error) {
+		i := m.nextIndex(current) // index after current version
+		if n <= 0 || n >= i {
+			return 0, nil
+		}
+		return m.migrations[i-n-1].Version, nil
+	})
+}
+
+// Goto migrate up or down to given version
+func (m *Migrator) Goto(ctx context.Context, version uint64) error {
+	return m.run(ctx, func(current uint64) (uint64, error) {
+		if version != 0 && m.indexOf(version) < 0 {
+			return current, fmt.Errorf("version %d not found in migration source", version)
+		}
+		return version, nil
+	})
+}
+
+// Force set version without running migration and reset dirty state
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	unlock, err := m.driver.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return m.driver.SetVersion(ctx, version, false)
+}
+
+func (m *Migrator) run(ctx context.Context, target func(current uint64) (uint64, error)) error {
+	unlock, err := m.driver.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, dirty, err := m.driver.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return ErrDirty{Version: current}
+	}
+	to, err := target(current)
+	if err != nil {
+		return err
+	}
+	if to == current {
+		return ErrNoChange
+	}
+
+	if to > current {
+		for _, mig := range m.migrations[m.nextIndex(current):] {
+			if mig.Version > to {
+				break
+			}
+			if err := m.apply(ctx, mig.Version, mig, mig.UpFile, "up"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := m.nextIndex(current) - 1; i >= 0 && m.migrations[i].Version > to; i-- {
+		if m.migrations[i].Version > current {
+			continue
+		}
+		var prev uint64
+		if i > 0 {
+			prev = m.migrations[i-1].Version
+		}
+		if err := m.apply(ctx, prev, m.migrations[i], m.migrations[i].DownFile, "down"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply execute migration file, version is set dirty until migration success
+func (m *Migrator) apply(ctx context.Context, version uint64, mig Migration, file, direction string) error {
+	if file == "" {
+		return fmt.Errorf("migration %d_%s: missing %s migration file", mig.Version, mig.Name, direction)
+	}
+	content, err := fs.ReadFile(m.source, file)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := m.driver.SetVersion(ctx, version, true); err != nil {
+		return err
+	}
+	if err := m.driver.Exec(ctx, content); err != nil {
+		return fmt.Errorf("migration %s: %w", file, err)
+	}
+	if err := m.driver.SetVersion(ctx, version, false); err != nil {
+		return err
+	}
+	logger.LogGreen(fmt.Sprintf("[MIGRATION] %-4s %s (%s)", direction, file, time.Since(start)))
+	return nil
+}
+
+// nextIndex index of first migration with version greater than given version
+func (m *Migrator) nextIndex(version uint64) int {
+	return sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version > version })
+}
+
+func (m *Migrator) indexOf(version uint64) int {
+	if i := m.nextIndex(version) - 1; i >= 0 && m.migrations[i].Version == version {
+		return i
+	}
+	return -1
+}
+
+// Run apply all up migration from source, no error if there is no change
+func Run(ctx context.Context, driver Driver, source fs.FS) error {
+	m, err := New(driver, source)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(ctx, 0); err != nil && !errors.Is(err, ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Create create new up and down migration file in dir, version is current timestamp (format YYYYMMDDhhmmss)
+func Create(dir, name, extension string) (files []string, err error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-zA-Z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+	version := time.Now().UTC().Format("20060102150405")
+	for _, direction := range []string{"up", "down"} {
+		file := fmt.Sprintf("%s/%s_%s.%s.%s", strings.TrimSuffix(dir, "/"), version, name, direction, extension)
+		if err := writeNewFile(file); err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func writeNewFile(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
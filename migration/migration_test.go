@@ -0,0 +1,206 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDriver struct {
+	version  uint64
+	dirty    bool
+	executed []string
+	failOn   string
+	seeded   map[string]time.Time
+}
+
+func (f *fakeDriver) Extension() string { return "sql" }
+func (f *fakeDriver) Lock(ctx context.Context) (func(), error) {
+	return func() {}, nil
+}
+func (f *fakeDriver) Version(ctx context.Context) (uint64, bool, error) {
+	return f.version, f.dirty, nil
+}
+func (f *fakeDriver) SetVersion(ctx context.Context, version uint64, dirty bool) error {
+	f.version, f.dirty = version, dirty
+	return nil
+}
+func (f *fakeDriver) Exec(ctx context.Context, migration []byte) error {
+	if string(migration) == f.failOn {
+		return errors.New("failed")
+	}
+	f.executed = append(f.executed, string(migration))
+	return nil
+}
+
+func (f *fakeDriver) Seeded(ctx context.Context) (map[string]time.Time, error) {
+	if f.seeded == nil {
+		f.seeded = map[string]time.Time{}
+	}
+	return f.seeded, nil
+}
+func (f *fakeDriver) MarkSeeded(ctx context.Context, name string) error {
+	f.seeded[name] = time.Now()
+	return nil
+}
+
+var testSource = fstest.MapFS{
+	"1_create_users.up.sql":     {Data: []byte("up 1")},
+	"1_create_users.down.sql":   {Data: []byte("down 1")},
+	"3_add_email.up.sql":        {Data: []byte("up 3")},
+	"3_add_email.down.sql":      {Data: []byte("down 3")},
+	"10_create_orders.up.sql":   {Data: []byte("up 10")},
+	"10_create_orders.down.sql": {Data: []byte("down 10")},
+	"10_create_orders.up.json":  {Data: []byte("[]")},
+	"README.md":                 {Data: []byte("readme")},
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	driver := &fakeDriver{}
+	m, err := New(driver, testSource)
+	assert.NoError(t, err)
+	assert.Len(t, m.Migrations(), 3)
+
+	assert.NoError(t, m.Up(ctx, 2))
+	assert.Equal(t, uint64(3), driver.version)
+	assert.Equal(t, []string{"up 1", "up 3"}, driver.executed)
+
+	assert.NoError(t, m.Up(ctx, 0))
+	assert.Equal(t, uint64(10), driver.version)
+	assert.ErrorIs(t, m.Up(ctx, 0), ErrNoChange)
+
+	status, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status[2].Applied)
+
+	driver.executed = nil
+	assert.NoError(t, m.Down(ctx, 1))
+	assert.Equal(t, uint64(3), driver.version)
+	assert.NoError(t, m.Goto(ctx, 0))
+	assert.Equal(t, uint64(0), driver.version)
+	assert.Equal(t, []string{"down 10", "down 3", "down 1"}, driver.executed)
+	assert.Error(t, m.Goto(ctx, 2))
+
+	driver.failOn = "up 3"
+	assert.Error(t, m.Up(ctx, 0))
+	assert.Equal(t, uint64(3), driver.version)
+	assert.True(t, driver.dirty)
+	assert.Equal(t, ErrDirty{Version: 3}, m.Up(ctx, 0))
+
+	assert.NoError(t, m.Force(ctx, 1))
+	assert.False(t, driver.dirty)
+	driver.failOn = ""
+	assert.NoError(t, Run(ctx, driver, testSource))
+	assert.Equal(t, uint64(10), driver.version)
+}
+
+func TestNewDuplicateVersion(t *testing.T) {
+	_, err := New(&fakeDriver{}, fstest.MapFS{
+		"1_a.up.sql":  {Data: []byte("")},
+		"01_b.up.sql": {Data: []byte("")},
+	})
+	assert.Error(t, err)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Create(filepath.Join(dir, "migrations"), "Create Users Table", "sql")
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	for _, file := range files {
+		_, err := os.Stat(file)
+		assert.NoError(t, err)
+	}
+	assert.Regexp(t, `[0-9]{14}_create_users_table\.up\.sql$`, files[0])
+}
+
+func TestParseMongoCommands(t *testing.T) {
+	commands, err := parseMongoCommands([]byte(`[{"createIndexes": "users", "indexes": [{"key": {"email": 1}, "name": "email_1"}]}, {"drop": "tmp"}]`))
+	assert.NoError(t, err)
+	assert.Len(t, commands, 2)
+	assert.Equal(t, "createIndexes", commands[0][0].Key)
+
+	_, err = parseMongoCommands([]byte(`{"drop": "tmp"}`))
+	assert.Error(t, err)
+}
+
+func TestSeeder(t *testing.T) {
+	ctx := context.Background()
+	driver := &fakeDriver{}
+	seeds, err := FileSeeds(fstest.MapFS{
+		"001_users.sql":            {Data: []byte("seed users")},
+		"staging/002_products.sql": {Data: []byte("seed staging products")},
+		"production/003_admin.sql": {Data: []byte("seed admin")},
+		"001_users.json":           {Data: []byte("[]")},
+	}, driver)
+	assert.NoError(t, err)
+	assert.Len(t, seeds, 3)
+
+	var goSeedCount int
+	seeder := NewSeeder(driver, "staging", seeds...).Add(Seed{Name: "000_config", Run: func(ctx context.Context) error {
+		goSeedCount++
+		return nil
+	}})
+
+	applied, err := seeder.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"000_config", "001_users.sql", "staging/002_products.sql"}, applied)
+	assert.Equal(t, []string{"seed users", "seed staging products"}, driver.executed)
+
+	applied, err = seeder.Run(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, 1, goSeedCount)
+
+	_, err = seeder.Run(ctx, "production/003_admin.sql")
+	assert.Error(t, err)
+	_, err = seeder.Run(ctx, "unknown")
+	assert.Error(t, err)
+
+	status, err := seeder.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, status, 4)
+	assert.NotNil(t, status[0].SeededAt)
+	assert.False(t, status[2].Allowed)
+
+	prodSeeder := NewSeeder(&fakeDriver{}, ProductionEnvironment, seeds...)
+	applied, err = prodSeeder.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"production/003_admin.sql"}, applied)
+}
+
+func TestHTTPHandlerSeed(t *testing.T) {
+	driver := &fakeDriver{}
+	var initCount int
+	handler := httpHandlerSeed(func() (*Seeder, error) {
+		initCount++
+		if initCount == 1 {
+			return nil, errors.New("database not ready")
+		}
+		return NewSeeder(driver, "staging", Seed{Name: "001_users", Run: func(ctx context.Context) error { return nil }}), nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/seed", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "failed init is retried on next request")
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/seed", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, driver.seeded, "GET does not run seed")
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/seed?name=001_users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, driver.seeded, "001_users")
+	assert.Equal(t, 2, initCount, "seeder is created once")
+}
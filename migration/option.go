@@ -0,0 +1,41 @@
+package migration
+
+import "time"
+
+type driverOption struct {
+	table, seedTable string
+	lockTTL          time.Duration
+}
+
+// DriverOption migration driver option
+type DriverOption func(*driverOption)
+
+// SetTable option, set table/collection name for store applied version (default "schema_migrations")
+func SetTable(table string) DriverOption {
+	return func(o *driverOption) {
+		o.table = table
+	}
+}
+
+// SetSeedTable option, set table/collection name for store applied seed (default "seed_history")
+func SetSeedTable(table string) DriverOption {
+	return func(o *driverOption) {
+		o.seedTable = table
+	}
+}
+
+// SetLockTTL option, set lease duration of migration lock for driver without native lock (mongo), default 1 minute.
+// Lease is extended while migration is running, so expired lock from crashed replica can be taken by another replica
+func SetLockTTL(ttl time.Duration) DriverOption {
+	return func(o *driverOption) {
+		o.lockTTL = ttl
+	}
+}
+
+func newDriverOption(opts []DriverOption) driverOption {
+	o := driverOption{table: "schema_migrations", seedTable: "seed_history", lockTTL: time.Minute}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
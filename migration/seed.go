@@ -0,0 +1,199 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/logger"
+)
+
+// ProductionEnvironment seed without environments guard is never run in this environment
+const ProductionEnvironment = "production"
+
+type (
+	// Seed data seeder, applied once per database (tracked in seed history)
+	Seed struct {
+		Name string
+		// Environments allowed environment (ENVIRONMENT env), empty for all environment except production
+		Environments []string
+		Run          func(ctx context.Context) error
+	}
+
+	// SeedStatus seed with applied status in current environment
+	SeedStatus struct {
+		Name     string     `json:"name"`
+		Allowed  bool       `json:"allowed"`
+		SeededAt *time.Time `json:"seeded_at,omitempty"`
+	}
+
+	// SeedHistory store of applied seed
+	SeedHistory interface {
+		Seeded(ctx context.Context) (map[string]time.Time, error)
+		MarkSeeded(ctx context.Context, name string) error
+	}
+
+	// SeedDriver driver with migration lock and seed history
+	SeedDriver interface {
+		Driver
+		SeedHistory
+	}
+)
+
+var (
+	registeredSeeds []Seed
+	seedMu          sync.Mutex
+)
+
+// RegisterSeeds register Go func seeders (example in module init), run with app "--seed" flag or "/seed" maintenance endpoint
+func RegisterSeeds(seeds ...Seed) {
+	seedMu.Lock()
+	defer seedMu.Unlock()
+	registeredSeeds = append(registeredSeeds, seeds...)
+}
+
+// RegisteredSeeds get registered Go func seeders
+func RegisteredSeeds() []Seed {
+	seedMu.Lock()
+	defer seedMu.Unlock()
+	return append([]Seed{}, registeredSeeds...)
+}
+
+// FileSeeds load seed files with driver extension from source, file in root directory is allowed for all environment
+// (except production) and file in "{environment}/" directory is only allowed in that environment.
+// Seed name is file path with extension (example: "staging/001_users.sql"), so sql and mongo seed with same base name does not collide
+func FileSeeds(source fs.FS, driver Driver) ([]Seed, error) {
+	var seeds []Seed
+	err := fs.WalkDir(source, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.Count(filePath, "/") > 0 {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(filePath) != "."+driver.Extension() {
+			return nil
+		}
+
+		seed := Seed{
+			Name: filePath,
+			Run: func(ctx context.Context) error {
+				content, err := fs.ReadFile(source, filePath)
+				if err != nil {
+					return err
+				}
+				return driver.Exec(ctx, content)
+			},
+		}
+		if dir := path.Dir(filePath); dir != "." {
+			seed.Environments = []string{dir}
+		}
+		seeds = append(seeds, seed)
+		return nil
+	})
+	return seeds, err
+}
+
+// Seeder run seeds once, applied seed is tracked in seed history
+type Seeder struct {
+	driver      SeedDriver
+	environment string
+	seeds       []Seed
+}
+
+// NewSeeder create seeder in environment, seed history is stored in driver database
+func NewSeeder(driver SeedDriver, environment string, seeds ...Seed) *Seeder {
+	s := &Seeder{driver: driver, environment: environment}
+	return s.Add(seeds...)
+}
+
+// Add add seeds, seeds are run sorted by name
+func (s *Seeder) Add(seeds ...Seed) *Seeder {
+	s.seeds = append(s.seeds, seeds...)
+	sort.SliceStable(s.seeds, func(i, j int) bool { return s.seeds[i].Name < s.seeds[j].Name })
+	return s
+}
+
+// Allowed check seed is allowed in seeder environment
+func (s *Seeder) Allowed(seed Seed) bool {
+	if len(seed.Environments) == 0 {
+		return s.environment != ProductionEnvironment
+	}
+	return candihelper.StringInSlice(s.environment, seed.Environments)
+}
+
+// Status list of seed with applied status
+func (s *Seeder) Status(ctx context.Context) ([]SeedStatus, error) {
+	seeded, err := s.driver.Seeded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]SeedStatus, len(s.seeds))
+	for i, seed := range s.seeds {
+		status[i] = SeedStatus{Name: seed.Name, Allowed: s.Allowed(seed)}
+		if seededAt, ok := seeded[seed.Name]; ok {
+			status[i].SeededAt = &seededAt
+		}
+	}
+	return status, nil
+}
+
+// Run run pending seeds allowed in environment (filter by names if not empty), return applied seed names
+func (s *Seeder) Run(ctx context.Context, names ...string) (applied []string, err error) {
+	for _, name := range names {
+		if !s.exists(name) {
+			return nil, fmt.Errorf("seed %q not found", name)
+		}
+	}
+
+	unlock, err := s.driver.Lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	seeded, err := s.driver.Seeded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, seed := range s.seeds {
+		if _, ok := seeded[seed.Name]; ok || (len(names) > 0 && !candihelper.StringInSlice(seed.Name, names)) {
+			continue
+		}
+		if !s.Allowed(seed) {
+			if len(names) > 0 {
+				return applied, fmt.Errorf("seed %q is not allowed in %q environment", seed.Name, s.environment)
+			}
+			continue
+		}
+
+		start := time.Now()
+		if err := seed.Run(ctx); err != nil {
+			return applied, fmt.Errorf("seed %s: %w", seed.Name, err)
+		}
+		if err := s.driver.MarkSeeded(ctx, seed.Name); err != nil {
+			return applied, err
+		}
+		applied = append(applied, seed.Name)
+		logger.LogGreen(fmt.Sprintf("[SEED] %s (%s)", seed.Name, time.Since(start)))
+	}
+	return applied, nil
+}
+
+func (s *Seeder) exists(name string) bool {
+	for _, seed := range s.seeds {
+		if seed.Name == name {
+			return true
+		}
+	}
+	return false
+}
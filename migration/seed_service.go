@@ -0,0 +1,108 @@
+package migration
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/codebase/factory/dependency"
+	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/wrapper"
+)
+
+// NewServiceSeeder create seeder for service environment with registered Go func seeds and file seeds from SEED_DIR
+// (.sql to primary sql database and .json to primary mongo database), seed history stored in sql database (or mongo if no sql)
+func NewServiceSeeder(deps dependency.Dependency) (*Seeder, error) {
+	if deps == nil {
+		return nil, errors.New("dependency is not initialized")
+	}
+
+	var drivers []SeedDriver
+	if sqlDB := deps.GetSQLDatabase(); sqlDB != nil {
+		driver, err := NewSQLDriver(sqlDB.WriteDB())
+		if err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, driver)
+	}
+	if mongoDB := deps.GetMongoDatabase(); mongoDB != nil {
+		drivers = append(drivers, NewMongoDriver(mongoDB.WriteDB()))
+	}
+	if len(drivers) == 0 {
+		return nil, errors.New("seeder require sql or mongo database")
+	}
+
+	seeder := NewSeeder(drivers[0], env.BaseEnv().Environment, RegisteredSeeds()...)
+	if _, err := os.Stat(env.BaseEnv().SeedDir); err == nil {
+		source := os.DirFS(env.BaseEnv().SeedDir)
+		for _, driver := range drivers {
+			seeds, err := FileSeeds(source, driver)
+			if err != nil {
+				return nil, err
+			}
+			seeder.Add(seeds...)
+		}
+	}
+	return seeder, nil
+}
+
+// HTTPHandlerSeed maintenance endpoint, get (GET) seed status or run (POST) pending seeds, filter seed with query param "name" (comma separated).
+// Seeder is created once on first request (retried if failed), GET is read only
+func HTTPHandlerSeed(deps dependency.Dependency) http.HandlerFunc {
+	return httpHandlerSeed(func() (*Seeder, error) { return NewServiceSeeder(deps) })
+}
+
+func httpHandlerSeed(newSeeder func() (*Seeder, error)) http.HandlerFunc {
+	var (
+		mu     sync.Mutex
+		seeder *Seeder
+	)
+	getSeeder := func() (*Seeder, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if seeder != nil {
+			return seeder, nil
+		}
+		s, err := newSeeder()
+		if err != nil {
+			return nil, err
+		}
+		seeder = s
+		return seeder, nil
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		seeder, err := getSeeder()
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Failed init seeder", err).JSON(w)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			status, err := seeder.Status(r.Context())
+			if err != nil {
+				wrapper.NewHTTPResponse(http.StatusInternalServerError, "Failed get seed status", err).JSON(w)
+				return
+			}
+			wrapper.NewHTTPResponse(http.StatusOK, "Seed status", status).JSON(w)
+
+		case http.MethodPost:
+			var names []string
+			if name := r.URL.Query().Get("name"); name != "" {
+				names = strings.Split(name, ",")
+			}
+			applied, err := seeder.Run(r.Context(), names...)
+			if err != nil {
+				wrapper.NewHTTPResponse(http.StatusBadRequest, "Failed run seed", err, map[string]any{"applied": applied}).JSON(w)
+				return
+			}
+			wrapper.NewHTTPResponse(http.StatusOK, "Success run seed", map[string]any{"applied": applied}).JSON(w)
+
+		default:
+			wrapper.NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(w)
+		}
+	}
+}
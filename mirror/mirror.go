@@ -0,0 +1,295 @@
+// Package mirror asynchronously duplicate percentage of incoming REST and gRPC request to shadow target (another base URL,
+// in-process handler version or gRPC connection), shadow response is ignored. Used for validating new implementation
+// against production traffic
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// HeaderMirrored header (and grpc metadata) set in mirrored request, shadow target can check it to skip side effect
+const HeaderMirrored = "X-Mirrored"
+
+var (
+	// hop-by-hop header is not forwarded to shadow target
+	hopHeaders = []string{
+		"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+	}
+	// credential header (and grpc metadata) is not forwarded to shadow target unless SetForwardCredentials
+	credentialHeaders = []string{"Authorization", "Cookie"}
+)
+
+type (
+	// OptionFunc type
+	OptionFunc func(*Mirror)
+
+	// Mirror request mirroring
+	Mirror struct {
+		percentage         float64
+		routes             []string
+		methods            []string
+		forwardCredentials bool
+		baseURL            *url.URL
+		httpClient         *http.Client
+		httpHandler        http.Handler
+		grpcConn           grpc.ClientConnInterface
+		timeout            time.Duration
+		maxBodySize        int64
+		semaphore          chan struct{}
+		randFunc           func() float64
+
+		mirrored, dropped atomic.Int64
+	}
+)
+
+// SetPercentage option func, percentage (0-100) of request to be mirrored (default 100)
+func SetPercentage(percentage float64) OptionFunc {
+	return func(m *Mirror) {
+		m.percentage = percentage
+	}
+}
+
+// SetRoutes option func, only mirror request matched with one of glob pattern, HTTP "{method} {path}"
+// or gRPC full method (default all request)
+func SetRoutes(patterns ...string) OptionFunc {
+	return func(m *Mirror) {
+		m.routes = patterns
+	}
+}
+
+// SetMethods option func, HTTP methods to be mirrored (default safe methods GET, HEAD and OPTIONS),
+// mirroring unsafe method duplicate side effect (example: create order twice) unless shadow target check HeaderMirrored
+func SetMethods(methods ...string) OptionFunc {
+	return func(m *Mirror) {
+		m.methods = methods
+	}
+}
+
+// SetForwardCredentials option func, forward Authorization and Cookie header (grpc metadata) to shadow target (default stripped)
+func SetForwardCredentials() OptionFunc {
+	return func(m *Mirror) {
+		m.forwardCredentials = true
+	}
+}
+
+// SetHTTPTarget option func, mirror REST request to base URL (example "http://order-service-v2:8000")
+func SetHTTPTarget(baseURL string, client *http.Client) OptionFunc {
+	return func(m *Mirror) {
+		m.baseURL, _ = url.Parse(strings.TrimSuffix(baseURL, "/"))
+		if client != nil {
+			m.httpClient = client
+		}
+	}
+}
+
+// SetHTTPHandler option func, mirror REST request to in-process handler (example new version of handler)
+func SetHTTPHandler(handler http.Handler) OptionFunc {
+	return func(m *Mirror) {
+		m.httpHandler = handler
+	}
+}
+
+// SetGRPCTarget option func, mirror gRPC unary request to connection
+func SetGRPCTarget(conn grpc.ClientConnInterface) OptionFunc {
+	return func(m *Mirror) {
+		m.grpcConn = conn
+	}
+}
+
+// SetTimeout option func, timeout of mirrored request (default 10 seconds)
+func SetTimeout(timeout time.Duration) OptionFunc {
+	return func(m *Mirror) {
+		m.timeout = timeout
+	}
+}
+
+// SetMaxBodySize option func, request with larger body is not mirrored (default 1MB)
+func SetMaxBodySize(maxBodySize int64) OptionFunc {
+	return func(m *Mirror) {
+		m.maxBodySize = maxBodySize
+	}
+}
+
+// SetConcurrency option func, max in-flight mirrored request, request is not mirrored when limit reached (default 100)
+func SetConcurrency(concurrency int) OptionFunc {
+	return func(m *Mirror) {
+		m.semaphore = make(chan struct{}, max(concurrency, 1))
+	}
+}
+
+// SetRandFunc option func, random number generator in [0, 1)
+func SetRandFunc(randFunc func() float64) OptionFunc {
+	return func(m *Mirror) {
+		m.randFunc = randFunc
+	}
+}
+
+// NewMirror create request mirroring
+func NewMirror(opts ...OptionFunc) *Mirror {
+	m := &Mirror{
+		percentage:  100,
+		methods:     []string{http.MethodGet, http.MethodHead, http.MethodOptions},
+		httpClient:  &http.Client{},
+		timeout:     10 * time.Second,
+		maxBodySize: 1 << 20,
+		semaphore:   make(chan struct{}, 100),
+		randFunc:    rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Stats number of mirrored request and request not mirrored because concurrency limit reached
+func (m *Mirror) Stats() (mirrored, dropped int64) {
+	return m.mirrored.Load(), m.dropped.Load()
+}
+
+// HTTPMiddleware mirror sampled REST request to http target and/or http handler after request body is read
+func (m *Mirror) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if (m.baseURL == nil && m.httpHandler == nil) || req.Header.Get(HeaderMirrored) != "" ||
+			!slices.Contains(m.methods, req.Method) || !m.sample(req.Method+" "+req.URL.Path) {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		var body []byte
+		if req.Body != nil && req.Body != http.NoBody {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(req.Body, m.maxBodySize+1))
+			if err != nil || int64(len(body)) > m.maxBodySize {
+				req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+				next.ServeHTTP(rw, req)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		header := req.Header.Clone()
+		for _, key := range hopHeaders {
+			header.Del(key)
+		}
+		if !m.forwardCredentials {
+			for _, key := range credentialHeaders {
+				header.Del(key)
+			}
+		}
+		header.Set(HeaderMirrored, "true")
+		method, uri := req.Method, req.URL.RequestURI()
+		m.goMirror(req.Context(), func(ctx context.Context) {
+			if m.baseURL != nil {
+				if shadowReq, err := http.NewRequestWithContext(ctx, method, m.baseURL.String()+uri, bytes.NewReader(body)); err == nil {
+					shadowReq.Header = header.Clone()
+					if resp, err := m.httpClient.Do(shadowReq); err == nil {
+						io.Copy(io.Discard, resp.Body)
+						resp.Body.Close()
+					}
+				}
+			}
+			if m.httpHandler != nil {
+				if shadowReq, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(body)); err == nil {
+					shadowReq.Header = header.Clone()
+					m.httpHandler.ServeHTTP(&discardResponseWriter{header: http.Header{}}, shadowReq)
+				}
+			}
+		})
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// GRPCUnaryInterceptor mirror sampled gRPC unary request to grpc target with same full method after primary handler
+// return response (response type is used for decoding shadow response)
+func (m *Mirror) GRPCUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if m.grpcConn == nil || err != nil {
+		return resp, err
+	}
+	incomingMD, _ := metadata.FromIncomingContext(ctx)
+	if len(incomingMD.Get(HeaderMirrored)) > 0 || !m.sample(info.FullMethod) {
+		return resp, err
+	}
+	reqMsg, ok := req.(proto.Message)
+	respMsg, ok2 := resp.(proto.Message)
+	if !ok || !ok2 {
+		return resp, err
+	}
+
+	md := incomingMD.Copy()
+	md.Delete(":authority")
+	if !m.forwardCredentials {
+		for _, key := range credentialHeaders {
+			md.Delete(key)
+		}
+	}
+	md.Set(HeaderMirrored, "true")
+	reqMsg = proto.Clone(reqMsg)
+	m.goMirror(ctx, func(ctx context.Context) {
+		reply := respMsg.ProtoReflect().New().Interface()
+		m.grpcConn.Invoke(metadata.NewOutgoingContext(ctx, md), info.FullMethod, reqMsg, reply)
+	})
+	return resp, err
+}
+
+func (m *Mirror) sample(route string) bool {
+	if len(m.routes) > 0 {
+		var matched bool
+		for _, pattern := range m.routes {
+			if matched, _ = path.Match(pattern, route); matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return m.randFunc()*100 < m.percentage
+}
+
+// goMirror run mirror func in background with timeout, context value (trace, tenant) is kept but not cancellation
+func (m *Mirror) goMirror(ctx context.Context, mirrorFunc func(ctx context.Context)) {
+	select {
+	case m.semaphore <- struct{}{}:
+	default:
+		m.dropped.Add(1)
+		return
+	}
+	m.mirrored.Add(1)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.timeout)
+	go func() {
+		defer func() {
+			recover()
+			cancel()
+			<-m.semaphore
+		}()
+		mirrorFunc(ctx)
+	}()
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,98 @@
+package mirror
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	received := make(chan string, 2)
+	shadow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		received <- req.Method + " " + req.URL.RequestURI() + " " + string(body) + " " + req.Header.Get(HeaderMirrored)
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	roll := 0.1
+	mirror := NewMirror(SetHTTPTarget(shadow.URL, nil), SetPercentage(50), SetRoutes("POST /v1/orders*"), SetMethods(http.MethodPost),
+		SetRandFunc(func() float64 { return roll }),
+		SetHTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			received <- "handler " + string(body)
+		})))
+	handler := mirror.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		rw.Write(body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/orders?dry=1", strings.NewReader(`{"id":1}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"id":1}`, rec.Body.String())
+
+	var results []string
+	for range 2 {
+		select {
+		case result := <-received:
+			results = append(results, result)
+		case <-time.After(time.Second):
+			t.Fatal("mirrored request not received")
+		}
+	}
+	assert.ElementsMatch(t, []string{`POST /v1/orders?dry=1 {"id":1} true`, `handler {"id":1}`}, results)
+
+	roll = 0.9
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{}`)))
+	roll = 0.1
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+	mirrored, dropped := mirror.Stats()
+	assert.Equal(t, int64(1), mirrored)
+	assert.Equal(t, int64(0), dropped)
+}
+
+func TestHTTPMiddlewareDefault(t *testing.T) {
+	received := make(chan http.Header, 2)
+	mirror := NewMirror(SetHTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received <- req.Header
+	})))
+	handler := mirror.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{}`)))
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Cookie", "session=secret")
+	req.Header.Set("X-Request-Id", "1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	select {
+	case header := <-received:
+		assert.Empty(t, header.Get("Authorization"))
+		assert.Empty(t, header.Get("Cookie"))
+		assert.Equal(t, "1", header.Get("X-Request-Id"))
+	case <-time.After(time.Second):
+		t.Fatal("mirrored request not received")
+	}
+	mirrored, _ := mirror.Stats()
+	assert.Equal(t, int64(1), mirrored, "unsafe method is not mirrored by default")
+}
+
+func TestHTTPMiddlewareLargeBody(t *testing.T) {
+	mirror := NewMirror(SetHTTPHandler(http.NotFoundHandler()), SetMaxBodySize(4), SetMethods(http.MethodPost))
+	handler := mirror.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		rw.Write(body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("0123456789")))
+	assert.Equal(t, "0123456789", rec.Body.String())
+	mirrored, _ := mirror.Stats()
+	assert.Equal(t, int64(0), mirrored)
+}
@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	candiutils "github.com/golangid/candi/candiutils"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -28,6 +30,78 @@ func (_m *WorkerPool[T]) Finish() {
 	_m.Called()
 }
 
+// Shutdown provides a mock function with given fields: ctx
+func (_m *WorkerPool[T]) Shutdown(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Shutdown")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stats provides a mock function with no fields
+func (_m *WorkerPool[T]) Stats() candiutils.WorkerPoolStats {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 candiutils.WorkerPoolStats
+	if rf, ok := ret.Get(0).(func() candiutils.WorkerPoolStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(candiutils.WorkerPoolStats)
+	}
+
+	return r0
+}
+
+// Submit provides a mock function with given fields: ctx, job
+func (_m *WorkerPool[T]) Submit(ctx context.Context, job T) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Submit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TrySubmit provides a mock function with given fields: ctx, job
+func (_m *WorkerPool[T]) TrySubmit(ctx context.Context, job T) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrySubmit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewWorkerPool creates a new instance of WorkerPool. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewWorkerPool[T any](t interface {
@@ -50,6 +50,26 @@ func (_m *Dependency) FetchBroker(_a0 func(types.Worker, interfaces.Broker)) {
 	_m.Called(_a0)
 }
 
+// GetBoltDatabase provides a mock function with given fields:
+func (_m *Dependency) GetBoltDatabase() interfaces.BoltDatabase {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBoltDatabase")
+	}
+
+	var r0 interfaces.BoltDatabase
+	if rf, ok := ret.Get(0).(func() interfaces.BoltDatabase); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.BoltDatabase)
+		}
+	}
+
+	return r0
+}
+
 // GetBroker provides a mock function with given fields: _a0
 func (_m *Dependency) GetBroker(_a0 types.Worker) interfaces.Broker {
 	ret := _m.Called(_a0)
@@ -270,6 +290,66 @@ func (_m *Dependency) GetSQLDatabaseByKey(key string) interfaces.SQLDatabase {
 	return r0
 }
 
+// GetGRPCClient provides a mock function with given fields:
+func (_m *Dependency) GetGRPCClient() interfaces.GRPCClient {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGRPCClient")
+	}
+
+	var r0 interfaces.GRPCClient
+	if rf, ok := ret.Get(0).(func() interfaces.GRPCClient); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.GRPCClient)
+		}
+	}
+
+	return r0
+}
+
+// GetSearchEngine provides a mock function with given fields:
+func (_m *Dependency) GetSearchEngine() interfaces.SearchEngine {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSearchEngine")
+	}
+
+	var r0 interfaces.SearchEngine
+	if rf, ok := ret.Get(0).(func() interfaces.SearchEngine); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.SearchEngine)
+		}
+	}
+
+	return r0
+}
+
+// GetStorage provides a mock function with given fields:
+func (_m *Dependency) GetStorage() interfaces.Storage {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStorage")
+	}
+
+	var r0 interfaces.Storage
+	if rf, ok := ret.Get(0).(func() interfaces.Storage); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interfaces.Storage)
+		}
+	}
+
+	return r0
+}
+
 // GetValidator provides a mock function with given fields:
 func (_m *Dependency) GetValidator() interfaces.Validator {
 	ret := _m.Called()
@@ -305,6 +385,21 @@ func (_m *Dependency) SetMiddleware(mw interfaces.Middleware) {
 	_m.Called(mw)
 }
 
+// SetGRPCClient provides a mock function with given fields: c
+func (_m *Dependency) SetGRPCClient(c interfaces.GRPCClient) {
+	_m.Called(c)
+}
+
+// SetSearchEngine provides a mock function with given fields: s
+func (_m *Dependency) SetSearchEngine(s interfaces.SearchEngine) {
+	_m.Called(s)
+}
+
+// SetStorage provides a mock function with given fields: s
+func (_m *Dependency) SetStorage(s interfaces.Storage) {
+	_m.Called(s)
+}
+
 // SetValidator provides a mock function with given fields: v
 func (_m *Dependency) SetValidator(v interfaces.Validator) {
 	_m.Called(v)
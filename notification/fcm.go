@@ -0,0 +1,163 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golangid/candi/candierrors"
+)
+
+const (
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// FCMSender push notification sender using Firebase Cloud Messaging HTTP v1 API,
+// authorized with service account credentials
+type FCMSender struct {
+	projectID   string
+	clientEmail string
+	tokenURI    string
+	privateKey  *rsa.PrivateKey
+	endpoint    string
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiredAt   time.Time
+}
+
+// NewFCMSender constructor with service account credentials json (downloaded from firebase console)
+func NewFCMSender(credentialsJSON []byte) (*FCMSender, error) {
+	var cred struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(credentialsJSON, &cred); err != nil {
+		return nil, fmt.Errorf("fcm: invalid credentials: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cred.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm: invalid private key: %w", err)
+	}
+	if cred.TokenURI == "" {
+		cred.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &FCMSender{
+		projectID: cred.ProjectID, clientEmail: cred.ClientEmail, tokenURI: cred.TokenURI, privateKey: privateKey,
+		endpoint: fmt.Sprintf(fcmEndpoint, cred.ProjectID), client: defaultHTTPClient,
+	}, nil
+}
+
+// Name of sender
+func (f *FCMSender) Name() string {
+	return ChannelPush
+}
+
+// Send push notification to each recipient (device registration token, or "topic:<name>" for topic),
+// message metadata is sent as data payload
+func (f *FCMSender) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return Permanent(errors.New("fcm: empty recipient"))
+	}
+	token, err := f.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, to := range msg.To {
+		message := map[string]any{
+			"notification": map[string]string{"title": msg.Subject, "body": msg.Body},
+		}
+		if len(msg.Metadata) > 0 {
+			message["data"] = msg.Metadata
+		}
+		if topic, ok := strings.CutPrefix(to, "topic:"); ok {
+			message["topic"] = topic
+		} else {
+			message["token"] = to
+		}
+		payload, _ := json.Marshal(map[string]any{"message": message})
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		if err := doHTTPRequest(f.client, req, "fcm"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	allPermanent := true
+	for _, err := range errs {
+		allPermanent = allPermanent && IsPermanent(err)
+	}
+	if len(errs) < len(msg.To) || allPermanent {
+		// partial failure is not retried, prevent duplicate notification to succeeded recipients
+		return Permanent(errors.Join(errs...))
+	}
+	return candierrors.Transient(errors.Join(errs...))
+}
+
+// token get oauth2 access token with jwt bearer grant, cached until near expired
+func (f *FCMSender) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.accessToken != "" && time.Now().Before(f.expiredAt) {
+		return f.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": f.clientEmail, "scope": fcmScope, "aud": f.tokenURI,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+	}).SignedString(f.privateKey)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error_description"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", fmt.Errorf("fcm: get access token: %s %s", resp.Status, result.Error)
+	}
+	f.accessToken = result.AccessToken
+	f.expiredAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return f.accessToken, nil
+}
@@ -0,0 +1,255 @@
+// Package notification consistent pipeline for send notification (email, push, chat) with template rendering,
+// tracing, and async dispatch backed by task queue worker with retries.
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/candishared"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+)
+
+const (
+	// ChannelEmail channel name for email sender (SMTP, SendGrid)
+	ChannelEmail = "email"
+	// ChannelPush channel name for push notification sender (FCM)
+	ChannelPush = "push"
+	// ChannelSlack channel name for slack webhook sender
+	ChannelSlack = "slack"
+
+	// DefaultTaskName default task name for async dispatch in task queue worker
+	DefaultTaskName = "candi-notification"
+
+	maxRetryDelay = time.Hour
+)
+
+var (
+	// ErrSenderNotFound error when no sender registered for message channel
+	ErrSenderNotFound = errors.New("notification: sender not found")
+
+	defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+type (
+	// Message notification message
+	Message struct {
+		// Channel selected sender name (email, push, slack, or custom sender name)
+		Channel string `json:"channel"`
+		// To recipients (email address, device token or "topic:<name>" for push)
+		To      []string `json:"to,omitempty"`
+		Subject string   `json:"subject,omitempty"`
+		Body    string   `json:"body,omitempty"`
+		HTML    bool     `json:"html,omitempty"`
+		// Template name for render subject & body with Data, see Templates
+		Template string         `json:"template,omitempty"`
+		Data     map[string]any `json:"data,omitempty"`
+		// Metadata additional sender specific value (example: push data payload)
+		Metadata map[string]string `json:"metadata,omitempty"`
+	}
+
+	// Sender abstract notification sender driver
+	Sender interface {
+		Name() string
+		Send(ctx context.Context, msg *Message) error
+	}
+
+	// OptionFunc dispatcher option func type
+	OptionFunc func(*Dispatcher)
+
+	// Dispatcher route message to sender based on channel, send directly or async with task queue worker
+	Dispatcher struct {
+		senders       map[string]Sender
+		templates     *Templates
+		taskName      string
+		maxRetry      int
+		retryInterval time.Duration
+		onResult      func(ctx context.Context, msg *Message, err error)
+		addJob        func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error)
+	}
+
+	senderFunc struct {
+		name string
+		send func(ctx context.Context, msg *Message) error
+	}
+)
+
+// SenderFunc create sender from function
+func SenderFunc(name string, send func(ctx context.Context, msg *Message) error) Sender {
+	return &senderFunc{name: name, send: send}
+}
+
+func (s *senderFunc) Name() string                                 { return s.name }
+func (s *senderFunc) Send(ctx context.Context, msg *Message) error { return s.send(ctx, msg) }
+
+// Permanent mark error as permanent, async dispatch will not retry message with permanent error
+// (example: invalid recipient or rejected by provider), same as candierrors.Permanent
+func Permanent(err error) error {
+	return candierrors.Permanent(err)
+}
+
+// IsPermanent check if error is explicitly classified as permanent or poison (see candierrors.Classify)
+func IsPermanent(err error) bool {
+	class, ok := candierrors.Classify(err)
+	return ok && (class == candierrors.ClassPermanent || class == candierrors.ClassPoison)
+}
+
+// AddSender option func, register sender by sender name (replace existing sender with same name)
+func AddSender(senders ...Sender) OptionFunc {
+	return func(d *Dispatcher) {
+		for _, sender := range senders {
+			d.senders[sender.Name()] = sender
+		}
+	}
+}
+
+// SetTemplates option func
+func SetTemplates(templates *Templates) OptionFunc {
+	return func(d *Dispatcher) {
+		d.templates = templates
+	}
+}
+
+// SetTaskName option func, set task name for async dispatch (default "candi-notification")
+func SetTaskName(taskName string) OptionFunc {
+	return func(d *Dispatcher) {
+		d.taskName = taskName
+	}
+}
+
+// SetMaxRetry option func, set max retry for async dispatch (default 5)
+func SetMaxRetry(maxRetry int) OptionFunc {
+	return func(d *Dispatcher) {
+		d.maxRetry = maxRetry
+	}
+}
+
+// SetRetryInterval option func, set base interval of exponential backoff for async dispatch (default 10 seconds)
+func SetRetryInterval(interval time.Duration) OptionFunc {
+	return func(d *Dispatcher) {
+		d.retryInterval = interval
+	}
+}
+
+// SetOnResult option func, set hook called after each send (for metrics or audit)
+func SetOnResult(onResult func(ctx context.Context, msg *Message, err error)) OptionFunc {
+	return func(d *Dispatcher) {
+		d.onResult = onResult
+	}
+}
+
+// NewDispatcher constructor
+func NewDispatcher(opts ...OptionFunc) *Dispatcher {
+	d := &Dispatcher{
+		senders:       make(map[string]Sender),
+		templates:     NewTemplates(),
+		taskName:      DefaultTaskName,
+		maxRetry:      5,
+		retryInterval: 10 * time.Second,
+		addJob:        taskqueueworker.AddJob,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Templates get template registry of dispatcher
+func (d *Dispatcher) Templates() *Templates {
+	return d.templates
+}
+
+// Send render and send message directly with sender for message channel
+func (d *Dispatcher) Send(ctx context.Context, msg Message) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "Notification:Send")
+	defer func() {
+		if d.onResult != nil {
+			d.onResult(ctx, &msg, err)
+		}
+		trace.Finish(tracer.FinishWithError(err))
+	}()
+	trace.SetTag("channel", msg.Channel)
+	trace.SetTag("template", msg.Template)
+	trace.SetTag("recipients", len(msg.To))
+
+	sender, ok := d.senders[msg.Channel]
+	if !ok {
+		return Permanent(fmt.Errorf("%w for channel %q", ErrSenderNotFound, msg.Channel))
+	}
+	if msg.Template != "" {
+		if err := d.templates.Render(ctx, &msg); err != nil {
+			return Permanent(err)
+		}
+	}
+	return sender.Send(ctx, &msg)
+}
+
+// Dispatch send message asynchronously with task queue worker, register dispatcher task handler
+// with MountHandlers in task queue worker handler of module
+func (d *Dispatcher) Dispatch(ctx context.Context, msg Message) (jobID string, err error) {
+	if _, ok := d.senders[msg.Channel]; !ok {
+		return "", fmt.Errorf("%w for channel %q", ErrSenderNotFound, msg.Channel)
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return d.addJob(ctx, &taskqueueworker.AddJobRequest{
+		TaskName: d.taskName, Args: payload, MaxRetry: d.maxRetry, RetryInterval: d.retryInterval,
+	})
+}
+
+// MountHandlers register dispatcher task handler to task queue worker handler group
+func (d *Dispatcher) MountHandlers(group *types.WorkerHandlerGroup) {
+	group.Add(d.taskName, d.TaskHandler)
+}
+
+// TaskHandler task queue worker handler for async dispatch, retry with exponential backoff if error is not permanent
+func (d *Dispatcher) TaskHandler(eventContext *candishared.EventContext) error {
+	var msg Message
+	if err := json.Unmarshal(eventContext.Message(), &msg); err != nil {
+		return candierrors.Poison(err)
+	}
+
+	err := d.Send(eventContext.Context(), msg)
+	if err == nil || IsPermanent(err) {
+		return err
+	}
+
+	retries, _ := strconv.Atoi(eventContext.Header()[taskqueueworker.HeaderRetries])
+	logger.LogYellow(fmt.Sprintf("notification: send %s failed (retries: %d): %v", msg.Channel, retries, err))
+	return &candishared.ErrorRetrier{
+		Delay:   max(retryDelay(d.retryInterval, retries), candierrors.RetryAfter(err)),
+		Message: err.Error(),
+	}
+}
+
+func retryDelay(interval time.Duration, retries int) time.Duration {
+	delay := time.Duration(float64(interval) * math.Pow(2, float64(retries)))
+	if delay <= 0 || delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// classifyHTTPStatus return permanent error for 4xx http status except 408 & 429 (throttled)
+func classifyHTTPStatus(statusCode int, err error) error {
+	if statusCode == http.StatusTooManyRequests {
+		return candierrors.Throttled(err, 0)
+	}
+	if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError &&
+		statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	var sent []Message
+	sendErr := errors.New("connection reset")
+	var failNext error
+	dispatcher := NewDispatcher(
+		AddSender(SenderFunc(ChannelEmail, func(ctx context.Context, msg *Message) error {
+			if failNext != nil {
+				return failNext
+			}
+			sent = append(sent, *msg)
+			return nil
+		})),
+		SetRetryInterval(time.Second),
+	)
+	assert.NoError(t, dispatcher.Templates().AddHTML("welcome", "Welcome {{.name}}", "<p>Hello {{.name}}</p>"))
+	assert.NoError(t, dispatcher.Templates().Add("welcome:id", "Selamat datang {{.name}}", "Halo {{.name}}"))
+
+	ctx := context.Background()
+	assert.NoError(t, dispatcher.Send(ctx, Message{
+		Channel: ChannelEmail, To: []string{"a@mail.com"}, Template: "welcome", Data: map[string]any{"name": "<agung>"},
+	}))
+	assert.Equal(t, "Welcome <agung>", sent[0].Subject)
+	assert.Equal(t, "<p>Hello &lt;agung&gt;</p>", sent[0].Body)
+	assert.True(t, sent[0].HTML)
+
+	assert.NoError(t, dispatcher.Send(i18n.SetLocaleToContext(ctx, "id-ID"), Message{
+		Channel: ChannelEmail, Template: "welcome", Data: map[string]any{"name": "agung"},
+	}))
+	assert.Equal(t, "Halo agung", sent[1].Body)
+	assert.False(t, sent[1].HTML)
+
+	err := dispatcher.Send(ctx, Message{Channel: ChannelPush})
+	assert.ErrorIs(t, err, ErrSenderNotFound)
+	assert.True(t, IsPermanent(err))
+	assert.True(t, IsPermanent(dispatcher.Send(ctx, Message{Channel: ChannelEmail, Template: "unknown"})))
+
+	// async dispatch
+	var jobs []*taskqueueworker.AddJobRequest
+	dispatcher.addJob = func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error) {
+		jobs = append(jobs, req)
+		return "job-1", nil
+	}
+	jobID, err := dispatcher.Dispatch(ctx, Message{Channel: ChannelEmail, To: []string{"b@mail.com"}, Body: "hi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "job-1", jobID)
+	assert.Equal(t, DefaultTaskName, jobs[0].TaskName)
+	assert.Equal(t, 5, jobs[0].MaxRetry)
+	_, err = dispatcher.Dispatch(ctx, Message{Channel: ChannelSlack})
+	assert.ErrorIs(t, err, ErrSenderNotFound)
+
+	var group types.WorkerHandlerGroup
+	dispatcher.MountHandlers(&group)
+	assert.Equal(t, DefaultTaskName, group.Handlers[0].Pattern)
+
+	eventContext := candishared.NewEventContext(bytes.NewBuffer(nil))
+	eventContext.SetContext(ctx)
+	eventContext.SetHeader(map[string]string{taskqueueworker.HeaderRetries: "2"})
+	eventContext.Write(jobs[0].Args)
+	assert.NoError(t, dispatcher.TaskHandler(eventContext))
+	assert.Equal(t, []string{"b@mail.com"}, sent[2].To)
+
+	failNext = sendErr
+	var retrier *candishared.ErrorRetrier
+	assert.ErrorAs(t, dispatcher.TaskHandler(eventContext), &retrier)
+	assert.Equal(t, 4*time.Second, retrier.Delay)
+
+	failNext = Permanent(errors.New("mailbox not found"))
+	err = dispatcher.TaskHandler(eventContext)
+	assert.True(t, IsPermanent(err))
+	assert.False(t, errors.As(err, &retrier))
+}
+
+func TestHTTPSenders(t *testing.T) {
+	var lastBody map[string]any
+	var lastAuth string
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&lastBody)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	sendgrid := NewSendGridSender("sg-key", "Candi <no-reply@candi.dev>")
+	sendgrid.endpoint = server.URL
+	assert.NoError(t, sendgrid.Send(ctx, &Message{To: []string{"a@mail.com", "Budi <b@mail.com>"}, Subject: "Hi", Body: "<b>hi</b>", HTML: true}))
+	assert.Equal(t, "Bearer sg-key", lastAuth)
+	assert.Len(t, lastBody["personalizations"], 2)
+	assert.Equal(t, map[string]any{"email": "no-reply@candi.dev", "name": "Candi"}, lastBody["from"])
+	assert.True(t, IsPermanent(sendgrid.Send(ctx, &Message{To: []string{"invalid"}})))
+
+	status = http.StatusBadRequest
+	assert.True(t, IsPermanent(sendgrid.Send(ctx, &Message{To: []string{"a@mail.com"}})))
+	status = http.StatusTooManyRequests
+	err := sendgrid.Send(ctx, &Message{To: []string{"a@mail.com"}})
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+
+	status = http.StatusOK
+	slack := NewSlackSender(server.URL)
+	assert.NoError(t, slack.Send(ctx, &Message{Subject: "Deploy", Body: "success"}))
+	assert.Equal(t, "*Deploy*\nsuccess", lastBody["text"])
+}
+
+func TestFCMSender(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	tokenRequests := 0
+	var messages []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			r.ParseForm()
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+			w.Write([]byte(`{"access_token":"access-token","expires_in":3600}`))
+		default:
+			assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+			var body struct {
+				Message map[string]any `json:"message"`
+			}
+			b, _ := io.ReadAll(r.Body)
+			json.Unmarshal(b, &body)
+			if body.Message["token"] == "unregistered" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			messages = append(messages, body.Message)
+		}
+	}))
+	defer server.Close()
+
+	credentials, _ := json.Marshal(map[string]string{
+		"project_id": "candi", "client_email": "fcm@candi.iam.gserviceaccount.com",
+		"private_key": string(privateKey), "token_uri": server.URL + "/token",
+	})
+	sender, err := NewFCMSender(credentials)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://fcm.googleapis.com/v1/projects/candi/messages:send", sender.endpoint)
+	sender.endpoint = server.URL + "/send"
+
+	ctx := context.Background()
+	assert.NoError(t, sender.Send(ctx, &Message{
+		To: []string{"device-1", "topic:promo"}, Subject: "Promo", Body: "50% off", Metadata: map[string]string{"id": "1"},
+	}))
+	assert.Equal(t, "device-1", messages[0]["token"])
+	assert.Equal(t, "promo", messages[1]["topic"])
+	assert.Equal(t, map[string]any{"id": "1"}, messages[1]["data"])
+
+	assert.True(t, IsPermanent(sender.Send(ctx, &Message{To: []string{"unregistered"}})))
+	assert.True(t, IsPermanent(sender.Send(ctx, &Message{To: []string{"device-2", "unregistered"}})))
+	assert.Equal(t, 1, tokenRequests)
+
+	_, err = NewFCMSender([]byte(`{"private_key":"invalid"}`))
+	assert.Error(t, err)
+}
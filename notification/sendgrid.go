@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+)
+
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender email sender using SendGrid v3 mail send API
+type SendGridSender struct {
+	apiKey   string
+	from     string
+	endpoint string
+	client   *http.Client
+}
+
+// NewSendGridSender constructor, from example: "Candi <no-reply@example.com>"
+func NewSendGridSender(apiKey, from string) *SendGridSender {
+	return &SendGridSender{apiKey: apiKey, from: from, endpoint: sendGridEndpoint, client: defaultHTTPClient}
+}
+
+// Name of sender
+func (s *SendGridSender) Name() string {
+	return ChannelEmail
+}
+
+// Send email, each recipient receive separate email (recipients not visible to each other)
+func (s *SendGridSender) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return Permanent(errors.New("sendgrid: empty recipient"))
+	}
+	type address struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	type personalization struct {
+		To []address `json:"to"`
+	}
+	parseAddress := func(s string) (address, error) {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return address{}, Permanent(fmt.Errorf("sendgrid: invalid address %q: %w", s, err))
+		}
+		return address{Email: addr.Address, Name: addr.Name}, nil
+	}
+
+	from, err := parseAddress(s.from)
+	if err != nil {
+		return err
+	}
+	var personalizations []personalization
+	for _, to := range msg.To {
+		addr, err := parseAddress(to)
+		if err != nil {
+			return err
+		}
+		personalizations = append(personalizations, personalization{To: []address{addr}})
+	}
+	contentType := "text/plain"
+	if msg.HTML {
+		contentType = "text/html"
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"personalizations": personalizations,
+		"from":             from,
+		"subject":          msg.Subject,
+		"content":          []map[string]string{{"type": contentType, "value": msg.Body}},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	return doHTTPRequest(s.client, req, "sendgrid")
+}
+
+// doHTTPRequest execute request, non 2xx response is error (classified permanent or not by status code)
+func doHTTPRequest(client *http.Client, req *http.Request, name string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= http.StatusBadRequest {
+		return classifyHTTPStatus(resp.StatusCode, fmt.Errorf("%s: %s: %s", name, resp.Status, bytes.TrimSpace(body)))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// SlackSender chat notification sender using slack incoming webhook
+type SlackSender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackSender constructor
+func NewSlackSender(webhookURL string) *SlackSender {
+	return &SlackSender{webhookURL: webhookURL, client: defaultHTTPClient}
+}
+
+// Name of sender
+func (s *SlackSender) Name() string {
+	return ChannelSlack
+}
+
+// Send message to webhook, subject is written in bold as first line
+func (s *SlackSender) Send(ctx context.Context, msg *Message) error {
+	text := msg.Body
+	if msg.Subject != "" {
+		text = "*" + msg.Subject + "*\n" + text
+	}
+	payload, _ := json.Marshal(map[string]string{"text": text})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doHTTPRequest(s.client, req, "slack")
+}
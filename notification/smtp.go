@@ -0,0 +1,177 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// SMTPConfig config for SMTP sender
+	SMTPConfig struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+		// From sender address, example: "Candi <no-reply@example.com>"
+		From string
+		// ImplicitTLS connect with TLS from start (port 465), else use STARTTLS if server support
+		ImplicitTLS bool
+		TLSConfig   *tls.Config
+		Timeout     time.Duration
+	}
+
+	// SMTPSender email sender using SMTP server
+	SMTPSender struct {
+		cfg SMTPConfig
+	}
+)
+
+// NewSMTPSender constructor
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.TLSConfig == nil {
+		cfg.TLSConfig = &tls.Config{ServerName: cfg.Host}
+	}
+	return &SMTPSender{cfg: cfg}
+}
+
+// Name of sender
+func (s *SMTPSender) Name() string {
+	return ChannelEmail
+}
+
+// Send email to all recipients in single mail
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return Permanent(errors.New("smtp: empty recipient"))
+	}
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return Permanent(fmt.Errorf("smtp: invalid from address: %w", err))
+	}
+	recipients := make([]string, len(msg.To))
+	for i, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return Permanent(fmt.Errorf("smtp: invalid recipient %q: %w", to, err))
+		}
+		recipients[i] = addr.Address
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(from.Address); err != nil {
+		return classifySMTPError(err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return classifySMTPError(err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return classifySMTPError(err)
+	}
+	if _, err := w.Write(buildMIMEMessage(s.cfg.From, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTPError(err)
+	}
+	return client.Quit()
+}
+
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if s.cfg.ImplicitTLS {
+		conn = tls.Client(conn, s.cfg.TLSConfig)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok && !s.cfg.ImplicitTLS {
+		if err := client.StartTLS(s.cfg.TLSConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			client.Close()
+			return nil, Permanent(err)
+		}
+	}
+	return client, nil
+}
+
+// classifySMTPError permanent error for 5xx SMTP reply code
+func classifySMTPError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+func buildMIMEMessage(from string, msg *Message) []byte {
+	var buff bytes.Buffer
+	contentType := "text/plain"
+	if msg.HTML {
+		contentType = "text/html"
+	}
+	headers := [][2]string{
+		{"From", from},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType + "; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "base64"},
+	}
+	for _, header := range headers {
+		buff.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	buff.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buff.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buff.WriteString(encoded + "\r\n")
+	return buff.Bytes()
+}
@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/golangid/candi/i18n"
+)
+
+type (
+	// Templates registry of notification template, template can be localized by register
+	// with name suffix ":<locale>" (example: "welcome:id"), selected by locale from context (see i18n.Locale)
+	Templates struct {
+		mu        sync.RWMutex
+		templates map[string]*notificationTemplate
+		funcs     texttemplate.FuncMap
+	}
+
+	notificationTemplate struct {
+		subject *texttemplate.Template
+		text    *texttemplate.Template
+		html    *htmltemplate.Template
+		isHTML  bool
+	}
+)
+
+// NewTemplates constructor, funcs is additional template functions
+func NewTemplates(funcs ...texttemplate.FuncMap) *Templates {
+	t := &Templates{templates: make(map[string]*notificationTemplate), funcs: texttemplate.FuncMap{}}
+	for _, fn := range funcs {
+		for k, v := range fn {
+			t.funcs[k] = v
+		}
+	}
+	return t
+}
+
+// Add register plain text template, subject and body using text/template syntax
+func (t *Templates) Add(name, subject, body string) error {
+	tpl := &notificationTemplate{}
+	var err error
+	if tpl.subject, err = texttemplate.New(name + ":subject").Funcs(t.funcs).Parse(subject); err != nil {
+		return fmt.Errorf("notification: parse subject template %s: %w", name, err)
+	}
+	if tpl.text, err = texttemplate.New(name).Funcs(t.funcs).Parse(body); err != nil {
+		return fmt.Errorf("notification: parse body template %s: %w", name, err)
+	}
+	t.set(name, tpl)
+	return nil
+}
+
+// AddHTML register html template, body value is escaped with html/template
+func (t *Templates) AddHTML(name, subject, body string) error {
+	tpl := &notificationTemplate{isHTML: true}
+	var err error
+	if tpl.subject, err = texttemplate.New(name + ":subject").Funcs(t.funcs).Parse(subject); err != nil {
+		return fmt.Errorf("notification: parse subject template %s: %w", name, err)
+	}
+	if tpl.html, err = htmltemplate.New(name).Funcs(htmltemplate.FuncMap(t.funcs)).Parse(body); err != nil {
+		return fmt.Errorf("notification: parse body template %s: %w", name, err)
+	}
+	t.set(name, tpl)
+	return nil
+}
+
+// Render subject & body of message from template, message subject & body is replaced
+func (t *Templates) Render(ctx context.Context, msg *Message) error {
+	tpl := t.get(msg.Template, i18n.Locale(ctx))
+	if tpl == nil {
+		return fmt.Errorf("notification: template %q not found", msg.Template)
+	}
+
+	var buff bytes.Buffer
+	if err := tpl.subject.Execute(&buff, msg.Data); err != nil {
+		return fmt.Errorf("notification: render subject %s: %w", msg.Template, err)
+	}
+	msg.Subject = strings.TrimSpace(buff.String())
+
+	buff.Reset()
+	var err error
+	if tpl.isHTML {
+		err = tpl.html.Execute(&buff, msg.Data)
+	} else {
+		err = tpl.text.Execute(&buff, msg.Data)
+	}
+	if err != nil {
+		return fmt.Errorf("notification: render body %s: %w", msg.Template, err)
+	}
+	msg.Body, msg.HTML = buff.String(), tpl.isHTML
+	return nil
+}
+
+func (t *Templates) set(name string, tpl *notificationTemplate) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[name] = tpl
+}
+
+func (t *Templates) get(name, locale string) *notificationTemplate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if locale != "" {
+		if tpl, ok := t.templates[name+":"+locale]; ok {
+			return tpl
+		}
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			if tpl, ok := t.templates[name+":"+base]; ok {
+				return tpl
+			}
+		}
+	}
+	return t.templates[name]
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/wrapper"
+)
+
+// HTTPHandler status polling and download API of async report, mount with prefix and auth middleware, example:
+// mux.Handle("/reports/", http.StripPrefix("/reports", mw.HTTPBearerAuth(generator.HTTPHandler())))
+//
+//	GET /{id}            status of report job
+//	GET /{id}/download   download generated report (409 when report is not ready)
+func (g *Generator) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{id}", func(w http.ResponseWriter, req *http.Request) {
+		result, err := g.Status(req.Context(), req.PathValue("id"))
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusNotFound, "Failed get report status", err).JSON(w)
+			return
+		}
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", result).JSON(w)
+	})
+	mux.HandleFunc("GET /{id}/download", func(w http.ResponseWriter, req *http.Request) {
+		body, result, err := g.Open(req.Context(), req.PathValue("id"))
+		switch {
+		case errors.Is(err, ErrNotReady):
+			wrapper.NewHTTPResponse(http.StatusConflict, "Report is not ready", result).JSON(w)
+			return
+		case err != nil:
+			wrapper.NewHTTPResponse(http.StatusNotFound, "Failed get report", err).JSON(w)
+			return
+		}
+		defer body.Close()
+
+		w.Header().Set(candihelper.HeaderContentType, result.ContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", result.Filename))
+		w.Header().Set(candihelper.HeaderCacheControl, "no-store")
+		io.Copy(w, body)
+	})
+	return mux
+}
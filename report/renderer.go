@@ -0,0 +1,114 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Renderer convert rendered HTML document to output format (PDF), implement with headless browser or converter
+// service of your environment
+type Renderer interface {
+	Render(ctx context.Context, html io.Reader, w io.Writer) error
+	// ContentType mime type of output
+	ContentType() string
+	// Extension file extension of output without dot
+	Extension() string
+}
+
+type htmlRenderer struct{}
+
+// NewHTMLRenderer renderer without conversion, output is rendered HTML (for preview or printed by browser)
+func NewHTMLRenderer() Renderer {
+	return htmlRenderer{}
+}
+
+func (htmlRenderer) Render(ctx context.Context, html io.Reader, w io.Writer) error {
+	_, err := io.Copy(w, html)
+	return err
+}
+
+func (htmlRenderer) ContentType() string { return "text/html; charset=utf-8" }
+func (htmlRenderer) Extension() string   { return "html" }
+
+type commandRenderer struct {
+	name string
+	args []string
+}
+
+// NewCommandRenderer PDF renderer with external command, HTML is written to stdin of command and PDF is read from stdout
+func NewCommandRenderer(name string, args ...string) Renderer {
+	return &commandRenderer{name: name, args: args}
+}
+
+// NewWKHTMLToPDFRenderer PDF renderer with wkhtmltopdf binary, args is additional option (example: "--page-size", "A4")
+func NewWKHTMLToPDFRenderer(args ...string) Renderer {
+	return NewCommandRenderer("wkhtmltopdf", append(append([]string{"--quiet"}, args...), "-", "-")...)
+}
+
+func (r *commandRenderer) Render(ctx context.Context, html io.Reader, w io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.name, r.args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = html, w, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("report: render with %s: %w: %s", r.name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (r *commandRenderer) ContentType() string { return "application/pdf" }
+func (r *commandRenderer) Extension() string   { return "pdf" }
+
+type gotenbergRenderer struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewGotenbergRenderer PDF renderer with Gotenberg service (headless Chromium over HTTP), host is base url of service
+// (example: "http://gotenberg:3000"), use http.DefaultClient when httpClient is nil
+func NewGotenbergRenderer(host string, httpClient *http.Client) Renderer {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &gotenbergRenderer{url: strings.TrimSuffix(host, "/") + "/forms/chromium/convert/html", httpClient: httpClient}
+}
+
+func (r *gotenbergRenderer) Render(ctx context.Context, html io.Reader, w io.Writer) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("files", "index.html")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, html); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("report: render with gotenberg: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("report: render with gotenberg: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (r *gotenbergRenderer) ContentType() string { return "application/pdf" }
+func (r *gotenbergRenderer) Extension() string   { return "pdf" }
@@ -0,0 +1,248 @@
+// Package report render HTML template to document (PDF with pluggable renderer), stream small report as HTTP download
+// or generate large report asynchronously in task queue worker with storage upload and status polling
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/tracer"
+)
+
+// DefaultTaskName task queue worker task name of async report generation
+const DefaultTaskName = "candi-report-generate"
+
+// Status of async report
+const (
+	StatusPending = "PENDING"
+	StatusSuccess = "SUCCESS"
+	StatusFailure = "FAILURE"
+)
+
+var (
+	// ErrStorageNotSet storage is required for async report
+	ErrStorageNotSet = errors.New("report: storage is not set")
+	// ErrNotReady report is not generated successfully yet
+	ErrNotReady = errors.New("report: report is not ready")
+)
+
+type (
+	// Request payload of async report job
+	Request struct {
+		Template string          `json:"template"`
+		Filename string          `json:"filename"`
+		Data     json.RawMessage `json:"data"`
+	}
+
+	// Result status of async report, Key and URL (signed url when supported by storage) is set when status is SUCCESS
+	Result struct {
+		JobID       string    `json:"job_id"`
+		Status      string    `json:"status"`
+		Filename    string    `json:"filename,omitempty"`
+		ContentType string    `json:"content_type,omitempty"`
+		Key         string    `json:"key,omitempty"`
+		URL         string    `json:"url,omitempty"`
+		Error       string    `json:"error,omitempty"`
+		Retries     int       `json:"retries"`
+		CreatedAt   time.Time `json:"created_at"`
+		FinishedAt  time.Time `json:"finished_at"`
+	}
+
+	// Generator render report from template set
+	Generator struct {
+		templates *template.Template
+		renderer  Renderer
+		storage   interfaces.Storage
+		taskName  string
+		maxRetry  int
+		keyPrefix string
+		urlExpire time.Duration
+
+		addJob func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error)
+		getJob func(ctx context.Context, jobID string) (taskqueueworker.Job, error)
+	}
+
+	// OptionFunc option func of generator
+	OptionFunc func(*Generator)
+)
+
+// SetRenderer option func, default NewWKHTMLToPDFRenderer
+func SetRenderer(renderer Renderer) OptionFunc {
+	return func(g *Generator) {
+		g.renderer = renderer
+	}
+}
+
+// SetStorage option func, storage of async report (required for GenerateAsync)
+func SetStorage(storage interfaces.Storage) OptionFunc {
+	return func(g *Generator) {
+		g.storage = storage
+	}
+}
+
+// SetTaskName option func, default DefaultTaskName
+func SetTaskName(taskName string) OptionFunc {
+	return func(g *Generator) {
+		g.taskName = taskName
+	}
+}
+
+// SetMaxRetry option func, max retry of failed async report job, default 3
+func SetMaxRetry(maxRetry int) OptionFunc {
+	return func(g *Generator) {
+		g.maxRetry = maxRetry
+	}
+}
+
+// SetKeyPrefix option func, prefix of storage object key of async report, default "reports/"
+func SetKeyPrefix(prefix string) OptionFunc {
+	return func(g *Generator) {
+		g.keyPrefix = prefix
+	}
+}
+
+// SetURLExpire option func, expiration of signed download url in status result, default 1 hour
+func SetURLExpire(expire time.Duration) OptionFunc {
+	return func(g *Generator) {
+		g.urlExpire = expire
+	}
+}
+
+// NewGenerator create report generator from parsed template set (example: template.ParseFS(templates, "*.html")),
+// register TaskHandler in task queue worker with generator task name for async report
+func NewGenerator(templates *template.Template, opts ...OptionFunc) *Generator {
+	g := &Generator{
+		templates: templates,
+		renderer:  NewWKHTMLToPDFRenderer(),
+		taskName:  DefaultTaskName,
+		maxRetry:  3,
+		keyPrefix: "reports/",
+		urlExpire: time.Hour,
+		addJob:    taskqueueworker.AddJob,
+		getJob:    taskqueueworker.GetDetailJob,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// TaskName task queue worker task name of async report
+func (g *Generator) TaskName() string {
+	return g.taskName
+}
+
+// Generate execute template with data and write rendered document to w, template is executed completely
+// before rendered so template error does not produce partial output
+func (g *Generator) Generate(ctx context.Context, w io.Writer, templateName string, data any) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "Report:Generate")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+	trace.SetTag("template", templateName)
+
+	var html bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&html, templateName, data); err != nil {
+		return fmt.Errorf("report: execute template %s: %w", templateName, err)
+	}
+	return g.renderer.Render(ctx, &html, w)
+}
+
+// HTTPDownload generate report in memory and write as attachment, suitable for small report. Nothing is written
+// to response when return error, so caller can write error response
+func (g *Generator) HTTPDownload(ctx context.Context, w http.ResponseWriter, filename, templateName string, data any) error {
+	var buff bytes.Buffer
+	if err := g.Generate(ctx, &buff, templateName, data); err != nil {
+		return err
+	}
+
+	w.Header().Set(candihelper.HeaderContentType, g.renderer.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", g.filename(filename)))
+	w.Header().Set(candihelper.HeaderCacheControl, "no-store")
+	_, err := buff.WriteTo(w)
+	return err
+}
+
+// GenerateAsync add job to generate report in task queue worker, return job id for polling status
+func (g *Generator) GenerateAsync(ctx context.Context, filename, templateName string, data any) (jobID string, err error) {
+	if g.storage == nil {
+		return "", ErrStorageNotSet
+	}
+	if g.templates.Lookup(templateName) == nil {
+		return "", fmt.Errorf("report: template %s is not defined", templateName)
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	args, _ := json.Marshal(Request{Template: templateName, Filename: g.filename(filename), Data: payload})
+	return g.addJob(ctx, &taskqueueworker.AddJobRequest{TaskName: g.taskName, MaxRetry: g.maxRetry, Args: args})
+}
+
+// Status get status of async report, Result.URL is signed download url when report is ready and storage support it
+func (g *Generator) Status(ctx context.Context, jobID string) (*Result, error) {
+	job, err := g.getJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.TaskName != g.taskName {
+		return nil, fmt.Errorf("report: job %s is not report job", jobID)
+	}
+
+	result := &Result{JobID: job.ID, Status: StatusPending, Retries: job.Retries, CreatedAt: job.CreatedAt}
+	var req Request
+	json.Unmarshal([]byte(job.Arguments), &req)
+	result.Filename = req.Filename
+
+	switch taskqueueworker.JobStatusEnum(job.Status) {
+	case taskqueueworker.StatusSuccess:
+		if err := json.Unmarshal([]byte(job.Result), result); err != nil {
+			return nil, fmt.Errorf("report: invalid result of job %s: %w", jobID, err)
+		}
+		result.JobID, result.Status, result.FinishedAt = job.ID, StatusSuccess, job.FinishedAt
+		if g.storage == nil {
+			break
+		}
+		if url, err := g.storage.SignedURL(ctx, result.Key, http.MethodGet, g.urlExpire); err == nil {
+			result.URL = url
+		}
+	case taskqueueworker.StatusFailure, taskqueueworker.StatusStopped:
+		result.Status, result.Error, result.FinishedAt = StatusFailure, job.Error, job.FinishedAt
+	}
+	return result, nil
+}
+
+// Open read generated async report from storage, return ErrNotReady when report is not generated successfully
+func (g *Generator) Open(ctx context.Context, jobID string) (io.ReadCloser, *Result, error) {
+	if g.storage == nil {
+		return nil, nil, ErrStorageNotSet
+	}
+	result, err := g.Status(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.Status != StatusSuccess {
+		return nil, result, ErrNotReady
+	}
+	body, err := g.storage.Get(ctx, result.Key)
+	return body, result, err
+}
+
+func (g *Generator) filename(filename string) string {
+	if filename == "" {
+		filename = "report"
+	}
+	if ext := "." + g.renderer.Extension(); !strings.HasSuffix(filename, ext) {
+		filename += ext
+	}
+	return filename
+}
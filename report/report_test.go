@@ -0,0 +1,121 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStorage struct {
+	interfaces.Storage
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (s *memoryStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	b, err := io.ReadAll(body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = string(b)
+	return err
+}
+
+func (s *memoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(strings.NewReader(obj)), nil
+}
+
+func (s *memoryStorage) SignedURL(ctx context.Context, key, method string, expire time.Duration) (string, error) {
+	return "https://storage.local/" + key + "?expire=" + expire.String(), nil
+}
+
+var testTemplates = template.Must(template.New("invoice.html").Parse(
+	`<h1>Invoice {{.number}}</h1>{{range .items}}<p>{{.name}}</p>{{end}}`))
+
+func TestGeneratorHTTPDownload(t *testing.T) {
+	generator := NewGenerator(testTemplates, SetRenderer(NewCommandRenderer("cat")))
+	data := map[string]any{"number": "INV-1", "items": []map[string]string{{"name": "<book>"}}}
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, generator.HTTPDownload(context.Background(), rec, "invoice-1", "invoice.html", data))
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="invoice-1.pdf"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, `<h1>Invoice INV-1</h1><p>&lt;book&gt;</p>`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	assert.Error(t, generator.HTTPDownload(context.Background(), rec, "invoice-1", "unknown.html", data))
+	assert.Empty(t, rec.Header().Get("Content-Disposition"), "nothing is written when failed")
+	assert.Zero(t, rec.Body.Len())
+}
+
+func TestGeneratorAsync(t *testing.T) {
+	ctx := context.Background()
+	storage := &memoryStorage{objects: make(map[string]string)}
+	job := taskqueueworker.Job{ID: "job-1", TaskName: DefaultTaskName, Status: string(taskqueueworker.StatusQueueing)}
+	generator := NewGenerator(testTemplates, SetRenderer(NewHTMLRenderer()), SetStorage(storage))
+	generator.addJob = func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error) {
+		job.Arguments = string(req.Args)
+		return job.ID, nil
+	}
+	generator.getJob = func(ctx context.Context, jobID string) (taskqueueworker.Job, error) {
+		if jobID != job.ID {
+			return job, errors.New("job not found")
+		}
+		return job, nil
+	}
+
+	_, err := generator.GenerateAsync(ctx, "invoice", "unknown.html", nil)
+	assert.Error(t, err)
+	jobID, err := generator.GenerateAsync(ctx, "invoice", "invoice.html", map[string]any{"number": 10})
+	require.NoError(t, err)
+
+	handler := generator.HTTPHandler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+jobID+"/download", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	eventContext := candishared.NewEventContextWithResult(&bytes.Buffer{}, &bytes.Buffer{})
+	eventContext.SetContext(ctx)
+	eventContext.SetKey(jobID)
+	eventContext.WriteString(job.Arguments)
+	require.NoError(t, generator.TaskHandler()(eventContext))
+	assert.Equal(t, "<h1>Invoice 10</h1>", storage.objects["reports/job-1.html"])
+	job.Status, job.Result = string(taskqueueworker.StatusSuccess), eventContext.GetResponse().String()
+
+	result, err := generator.Status(ctx, jobID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccess, result.Status)
+	assert.Equal(t, "invoice.html", result.Filename)
+	assert.Equal(t, "https://storage.local/reports/job-1.html?expire=1h0m0s", result.URL)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+jobID+"/download", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="invoice.html"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "<h1>Invoice 10</h1>", rec.Body.String())
+
+	job.Status, job.Error = string(taskqueueworker.StatusFailure), "render failed"
+	result, err = generator.Status(ctx, jobID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailure, result.Status)
+	assert.Equal(t, "render failed", result.Error)
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/tracer"
+)
+
+// TaskHandler task queue worker handler of async report, rendered report is streamed to storage with key
+// "{prefix}{job id}.{extension}" and written as job result.
+// Usage: group.Add(generator.TaskName(), generator.TaskHandler())
+func (g *Generator) TaskHandler() types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		result, err := g.generateToStorage(eventContext.Context(), eventContext.Key(), eventContext.Message())
+		if err != nil {
+			return err
+		}
+		_, err = eventContext.WriteResult(result)
+		return err
+	}
+}
+
+func (g *Generator) generateToStorage(ctx context.Context, jobID string, args []byte) (result []byte, err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "Report:GenerateToStorage")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	if g.storage == nil {
+		return nil, candierrors.Permanent(ErrStorageNotSet)
+	}
+	var req Request
+	if err := json.Unmarshal(args, &req); err != nil {
+		return nil, candierrors.Permanent(err)
+	}
+	var data any
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &data); err != nil {
+			return nil, candierrors.Permanent(err)
+		}
+	}
+	if g.templates.Lookup(req.Template) == nil {
+		return nil, candierrors.Permanent(fmt.Errorf("report: template %s is not defined", req.Template))
+	}
+
+	key := g.keyPrefix + jobID + "." + g.renderer.Extension()
+	trace.SetTag("key", key)
+
+	pr, pw := io.Pipe()
+	putErr := make(chan error, 1)
+	go func() {
+		err := g.storage.Put(ctx, key, pr, g.renderer.ContentType())
+		pr.CloseWithError(err)
+		putErr <- err
+	}()
+	err = g.Generate(ctx, pw, req.Template, data)
+	pw.CloseWithError(err)
+	if perr := <-putErr; err == nil {
+		err = perr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Result{Filename: req.Filename, ContentType: g.renderer.ContentType(), Key: key})
+}
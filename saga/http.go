@@ -0,0 +1,65 @@
+package saga
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	restserver "github.com/golangid/candi/codebase/app/rest_server"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/wrapper"
+)
+
+// Mount register saga management API to router (mount in group with auth middleware):
+//
+//	GET  /sagas?saga=&status=&limit=   list saga instances
+//	GET  /sagas/:id                     get saga instance
+//	POST /sagas/:id/resume              resume saga
+//	POST /sagas/:id/abort?reason=       abort running saga
+func (o *Orchestrator) Mount(router interfaces.RESTRouter) {
+	router.GET("/sagas", o.httpList)
+	router.GET("/sagas/:id", o.httpGet)
+	router.POST("/sagas/:id/resume", o.httpResume)
+	router.POST("/sagas/:id/abort", o.httpAbort)
+}
+
+func (o *Orchestrator) httpList(w http.ResponseWriter, req *http.Request) {
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	instances, err := o.List(req.Context(), Filter{
+		Saga: req.URL.Query().Get("saga"), Status: Status(req.URL.Query().Get("status")), Limit: limit,
+	})
+	if err != nil {
+		wrapper.NewHTTPResponse(http.StatusInternalServerError, err.Error()).JSON(w)
+		return
+	}
+	wrapper.NewHTTPResponse(http.StatusOK, "Success", instances).JSON(w)
+}
+
+func (o *Orchestrator) httpGet(w http.ResponseWriter, req *http.Request) {
+	instance, err := o.Get(req.Context(), restserver.URLParam(req, "id"))
+	writeInstance(w, instance, err)
+}
+
+func (o *Orchestrator) httpResume(w http.ResponseWriter, req *http.Request) {
+	instance, err := o.Resume(req.Context(), restserver.URLParam(req, "id"))
+	writeInstance(w, instance, err)
+}
+
+func (o *Orchestrator) httpAbort(w http.ResponseWriter, req *http.Request) {
+	instance, err := o.Abort(req.Context(), restserver.URLParam(req, "id"), req.URL.Query().Get("reason"))
+	writeInstance(w, instance, err)
+}
+
+func writeInstance(w http.ResponseWriter, instance *Instance, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		wrapper.NewHTTPResponse(http.StatusNotFound, err.Error()).JSON(w)
+	case err != nil:
+		wrapper.NewHTTPResponse(http.StatusBadRequest, err.Error()).JSON(w)
+	default:
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", instance).JSON(w)
+	}
+}
@@ -0,0 +1,331 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/logger"
+	"github.com/google/uuid"
+)
+
+// DefaultTaskName task queue task name for execute saga step
+const DefaultTaskName = "candi-saga"
+
+type jobArgs struct {
+	SagaID string `json:"saga_id"`
+	Seq    int    `json:"seq"`
+}
+
+// OptionFunc type
+type OptionFunc func(*Orchestrator)
+
+// SetTaskName option func, default "candi-saga"
+func SetTaskName(taskName string) OptionFunc {
+	return func(o *Orchestrator) {
+		o.taskName = taskName
+	}
+}
+
+// SetAddJobFunc option func, default taskqueueworker.AddJob
+func SetAddJobFunc(addJob func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error)) OptionFunc {
+	return func(o *Orchestrator) {
+		o.addJob = addJob
+	}
+}
+
+// Orchestrator saga orchestrator, implement interfaces.WorkerHandler for task queue worker
+type Orchestrator struct {
+	store       Store
+	definitions map[string]*Definition
+	taskName    string
+	addJob      func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error)
+}
+
+/*
+NewOrchestrator create saga orchestrator, register orchestrator handler in task queue worker handler of module:
+
+	func (h *TaskQueueHandler) MountHandlers(group *types.WorkerHandlerGroup) {
+		h.orchestrator.MountHandlers(group)
+	}
+
+then start saga with orchestrator.Start(ctx, "create-order", payload)
+*/
+func NewOrchestrator(store Store, definitions []*Definition, opts ...OptionFunc) *Orchestrator {
+	o := &Orchestrator{
+		store:       store,
+		definitions: make(map[string]*Definition, len(definitions)),
+		taskName:    DefaultTaskName,
+		addJob:      taskqueueworker.AddJob,
+	}
+	for _, def := range definitions {
+		o.definitions[def.Name] = def
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// MountHandlers mount saga step task handler
+func (o *Orchestrator) MountHandlers(group *types.WorkerHandlerGroup) {
+	group.Add(o.taskName, o.handleStep)
+}
+
+// Start create saga instance and run first step
+func (o *Orchestrator) Start(ctx context.Context, sagaName string, data any) (*Instance, error) {
+	def, ok := o.definitions[sagaName]
+	if !ok {
+		return nil, fmt.Errorf("saga %q is not registered", sagaName)
+	}
+	if len(def.Steps) == 0 {
+		return nil, fmt.Errorf("saga %q has no step", sagaName)
+	}
+
+	now := time.Now()
+	instance := &Instance{
+		ID: uuid.NewString(), Saga: sagaName, Status: StatusRunning, Seq: 1, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := instance.SetData(data); err != nil {
+		return nil, err
+	}
+	if err := o.store.Create(ctx, instance); err != nil {
+		return nil, err
+	}
+	return instance, o.enqueue(ctx, def, instance)
+}
+
+// Get get saga instance
+func (o *Orchestrator) Get(ctx context.Context, id string) (*Instance, error) {
+	return o.store.Get(ctx, id)
+}
+
+// List list saga instances
+func (o *Orchestrator) List(ctx context.Context, filter Filter) ([]Instance, error) {
+	return o.store.List(ctx, filter)
+}
+
+// Abort request abort running saga, compensation is started after running step finished
+func (o *Orchestrator) Abort(ctx context.Context, id, reason string) (*Instance, error) {
+	instance, err := o.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = o.update(ctx, instance, func(i *Instance) error {
+		if i.Status != StatusRunning {
+			return fmt.Errorf("cannot abort saga with status %s", i.Status)
+		}
+		i.Status, i.Error = StatusAborting, "aborted: "+reason
+		return nil
+	})
+	return instance, err
+}
+
+// Resume re-run failed compensation, or re-run current step of running saga when the job is lost (example: task
+// queue data is cleared), previous job of the saga is skipped
+func (o *Orchestrator) Resume(ctx context.Context, id string) (*Instance, error) {
+	instance, err := o.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	def, ok := o.definitions[instance.Saga]
+	if !ok {
+		return nil, fmt.Errorf("saga %q is not registered", instance.Saga)
+	}
+	err = o.update(ctx, instance, func(i *Instance) error {
+		switch i.Status {
+		case StatusCompleted, StatusCompensated:
+			return fmt.Errorf("cannot resume saga with status %s", i.Status)
+		case StatusFailed:
+			i.Status = StatusCompensating
+		}
+		i.Seq++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instance, o.enqueue(ctx, def, instance)
+}
+
+func (o *Orchestrator) handleStep(eventContext *candishared.EventContext) error {
+	ctx := eventContext.Context()
+	var args jobArgs
+	if err := json.Unmarshal(eventContext.Message(), &args); err != nil {
+		return err
+	}
+	instance, err := o.store.Get(ctx, args.SagaID)
+	if err != nil {
+		return err
+	}
+	if instance.Seq != args.Seq || instance.Status.IsFinished() {
+		return nil // stale job
+	}
+	def, ok := o.definitions[instance.Saga]
+	if !ok {
+		return fmt.Errorf("saga %q is not registered", instance.Saga)
+	}
+
+	header := taskqueueworker.GetContextHeader(eventContext)
+	if instance.Status == StatusAborting {
+		if err := o.update(ctx, instance, func(i *Instance) error {
+			i.Status = StatusCompensating
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if instance.Status == StatusCompensating {
+		return o.compensate(ctx, def, instance, header)
+	}
+	return o.execute(ctx, def, instance, header)
+}
+
+func (o *Orchestrator) execute(ctx context.Context, def *Definition, instance *Instance, header taskqueueworker.ContextHeader) error {
+	index := instance.CurrentStep
+	step := def.Steps[index]
+	history := StepHistory{Step: step.Name, Attempts: max(header.Retries, 1), StartAt: time.Now()}
+	actionErr := step.Action(ctx, instance)
+	history.EndAt = time.Now()
+	if actionErr != nil && header.Retries < header.MaxRetries {
+		return retryError(step, actionErr)
+	}
+
+	data := instance.Data
+	if err := o.update(ctx, instance, func(i *Instance) error {
+		i.Data = data
+		if actionErr != nil {
+			history.Error = actionErr.Error()
+			i.Status, i.Error = StatusCompensating, fmt.Sprintf("step %s: %s", step.Name, actionErr.Error())
+		} else {
+			i.CurrentStep = index + 1
+			switch {
+			case i.Status == StatusAborting:
+				i.Status = StatusCompensating
+			case i.CurrentStep >= len(def.Steps):
+				i.Status = StatusCompleted
+			}
+		}
+		i.History = append(i.History, history)
+		i.Seq++
+		return nil
+	}); err != nil {
+		return err
+	}
+	if instance.Status != StatusCompleted {
+		return o.enqueue(ctx, def, instance)
+	}
+	logger.LogGreen(fmt.Sprintf("[SAGA] %s (%s) completed", instance.Saga, instance.ID))
+	return nil
+}
+
+func (o *Orchestrator) compensate(ctx context.Context, def *Definition, instance *Instance, header taskqueueworker.ContextHeader) error {
+	index := instance.CurrentStep - 1
+	for index >= 0 && def.Steps[index].Compensate == nil {
+		index--
+	}
+
+	var history *StepHistory
+	var compensateErr error
+	if index >= 0 {
+		step := def.Steps[index]
+		history = &StepHistory{Step: step.Name, Compensate: true, Attempts: max(header.Retries, 1), StartAt: time.Now()}
+		compensateErr = step.Compensate(ctx, instance)
+		history.EndAt = time.Now()
+		if compensateErr != nil && header.Retries < header.MaxRetries {
+			return retryError(step, compensateErr)
+		}
+	}
+
+	data := instance.Data
+	if err := o.update(ctx, instance, func(i *Instance) error {
+		i.Data = data
+		if history != nil {
+			if compensateErr != nil {
+				history.Error = compensateErr.Error()
+			}
+			i.History = append(i.History, *history)
+		}
+		switch {
+		case compensateErr != nil:
+			i.Status, i.Error = StatusFailed, fmt.Sprintf("compensate step %s: %s", history.Step, compensateErr.Error())
+		case index <= 0:
+			i.CurrentStep, i.Status = 0, StatusCompensated
+		default:
+			i.CurrentStep = index
+		}
+		i.Seq++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	switch instance.Status {
+	case StatusCompensating:
+		return o.enqueue(ctx, def, instance)
+	case StatusFailed:
+		logger.LogRed(fmt.Sprintf("[SAGA] %s (%s) compensation failed: %s", instance.Saga, instance.ID, instance.Error))
+	}
+	return nil
+}
+
+// update apply change to latest instance and save with optimistic lock, retry when updated by another process
+func (o *Orchestrator) update(ctx context.Context, instance *Instance, apply func(*Instance) error) error {
+	for {
+		latest := *instance
+		if err := apply(&latest); err != nil {
+			return err
+		}
+		latest.UpdatedAt = time.Now()
+		err := o.store.Update(ctx, &latest)
+		if err == nil {
+			*instance = latest
+			return nil
+		}
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+		reloaded, err := o.store.Get(ctx, instance.ID)
+		if err != nil {
+			return err
+		}
+		*instance = *reloaded
+	}
+}
+
+func (o *Orchestrator) enqueue(ctx context.Context, def *Definition, instance *Instance) error {
+	index := instance.CurrentStep
+	if instance.Status == StatusCompensating {
+		index = max(index-1, 0)
+	}
+	step := def.Steps[min(index, len(def.Steps)-1)]
+	maxRetry, interval := step.MaxRetry, step.RetryInterval
+	if maxRetry <= 0 {
+		maxRetry = 3
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	args, _ := json.Marshal(jobArgs{SagaID: instance.ID, Seq: instance.Seq})
+	_, err := o.addJob(context.WithoutCancel(ctx), &taskqueueworker.AddJobRequest{
+		TaskName: o.taskName, Args: args, MaxRetry: maxRetry, RetryInterval: interval,
+	})
+	if err != nil {
+		logger.LogRed(fmt.Sprintf("[SAGA] %s (%s) cannot add job, resume saga after fixed: %s", instance.Saga, instance.ID, err.Error()))
+	}
+	return err
+}
+
+func retryError(step Step, err error) error {
+	delay := step.RetryInterval
+	if delay <= 0 {
+		delay = 5 * time.Second
+	}
+	return &candishared.ErrorRetrier{Delay: delay, Message: err.Error()}
+}
@@ -0,0 +1,123 @@
+// Package saga lightweight saga orchestrator on top of task queue worker. Saga is multi steps workflow,
+// each step has action and optional compensation, when step still failed after retries, compensation of all completed
+// steps is executed in reverse order. Saga instance state is persisted in Store, step is executed as task queue job.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Status saga instance status
+type Status string
+
+const (
+	// StatusRunning step action is running
+	StatusRunning Status = "RUNNING"
+	// StatusAborting abort requested, compensation is started after running step finished
+	StatusAborting Status = "ABORTING"
+	// StatusCompensating compensation of completed steps is running
+	StatusCompensating Status = "COMPENSATING"
+	// StatusCompleted all step actions are success
+	StatusCompleted Status = "COMPLETED"
+	// StatusCompensated all completed steps are compensated
+	StatusCompensated Status = "COMPENSATED"
+	// StatusFailed compensation still failed after retries, need manual fix then resume
+	StatusFailed Status = "FAILED"
+)
+
+var (
+	// ErrNotFound saga instance not found
+	ErrNotFound = errors.New("saga not found")
+	// ErrConflict saga instance is updated by another process
+	ErrConflict = errors.New("saga is updated by another process")
+)
+
+// IsFinished finished status (no job is running)
+func (s Status) IsFinished() bool {
+	return s == StatusCompleted || s == StatusCompensated || s == StatusFailed
+}
+
+// Step saga step
+type Step struct {
+	Name string
+	// Action step action, can update instance data with Instance.SetData
+	Action func(ctx context.Context, instance *Instance) error
+	// Compensate undo action when next step failed or saga aborted, skipped if nil
+	Compensate func(ctx context.Context, instance *Instance) error
+	// MaxRetry max attempts of action or compensation, default 3
+	MaxRetry int
+	// RetryInterval delay between retry, default 5 seconds
+	RetryInterval time.Duration
+}
+
+// Definition saga definition
+type Definition struct {
+	Name  string
+	Steps []Step
+}
+
+// StepHistory executed step action or compensation
+type StepHistory struct {
+	Step       string    `json:"step" bson:"step"`
+	Compensate bool      `json:"compensate,omitempty" bson:"compensate,omitempty"`
+	Attempts   int       `json:"attempts" bson:"attempts"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	StartAt    time.Time `json:"start_at" bson:"start_at"`
+	EndAt      time.Time `json:"end_at" bson:"end_at"`
+}
+
+// Instance running saga state
+type Instance struct {
+	ID     string `json:"id"`
+	Saga   string `json:"saga"`
+	Status Status `json:"status"`
+	// CurrentStep index of step to run, when compensating all steps before this index are not compensated yet
+	CurrentStep int             `json:"current_step"`
+	Data        json.RawMessage `json:"data"`
+	Error       string          `json:"error,omitempty"`
+	History     []StepHistory   `json:"history"`
+	// Seq sequence of current job, job with another sequence is stale and skipped
+	Seq int `json:"seq"`
+	// Version optimistic lock version, incremented on update
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Bind unmarshal saga data to target
+func (i *Instance) Bind(target any) error {
+	if len(i.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(i.Data, target)
+}
+
+// SetData set saga data, passed to next steps
+func (i *Instance) SetData(data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	i.Data = b
+	return nil
+}
+
+// Filter list saga filter
+type Filter struct {
+	Saga   string
+	Status Status
+	Limit  int
+}
+
+// Store saga instance storage
+type Store interface {
+	Create(ctx context.Context, instance *Instance) error
+	Get(ctx context.Context, id string) (*Instance, error)
+	// Update save instance if stored version is same with instance version (return ErrConflict if not), then increment version
+	Update(ctx context.Context, instance *Instance) error
+	// List instances sorted by newest
+	List(ctx context.Context, filter Filter) ([]Instance, error)
+}
@@ -0,0 +1,151 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID        string
+	Reserved  bool
+	Charged   bool
+	Cancelled []string
+}
+
+// runQueue execute queued job like task queue worker, retry job while handler return retry error
+func runQueue(t *testing.T, worker *testkit.WorkerHarness, queue *[]*taskqueueworker.AddJobRequest) {
+	for len(*queue) > 0 {
+		req := (*queue)[0]
+		*queue = (*queue)[1:]
+		for retries := 1; retries <= req.MaxRetry; retries++ {
+			_, err := worker.InvokeEvent(testkit.NewEventContext().WithHandlerRoute(req.TaskName).WithMessage(req.Args).
+				WithHeader(taskqueueworker.HeaderRetries, strconv.Itoa(retries)).
+				WithHeader(taskqueueworker.HeaderMaxRetries, strconv.Itoa(req.MaxRetry)).Build())
+			if err == nil || retries == req.MaxRetry {
+				break
+			}
+		}
+	}
+}
+
+func newTestOrchestrator(chargeErr error) (*Orchestrator, *[]*taskqueueworker.AddJobRequest) {
+	queue := new([]*taskqueueworker.AddJobRequest)
+	def := &Definition{Name: "create-order", Steps: []Step{
+		{
+			Name: "reserve-stock",
+			Action: func(ctx context.Context, i *Instance) error {
+				var o order
+				i.Bind(&o)
+				o.Reserved = true
+				return i.SetData(o)
+			},
+			Compensate: func(ctx context.Context, i *Instance) error {
+				var o order
+				i.Bind(&o)
+				o.Cancelled = append(o.Cancelled, "reserve-stock")
+				return i.SetData(o)
+			},
+		},
+		{
+			Name: "notify", // without compensation
+			Action: func(ctx context.Context, i *Instance) error {
+				return nil
+			},
+		},
+		{
+			Name: "charge-payment", MaxRetry: 2,
+			Action: func(ctx context.Context, i *Instance) error {
+				if chargeErr != nil {
+					return chargeErr
+				}
+				var o order
+				i.Bind(&o)
+				o.Charged = true
+				return i.SetData(o)
+			},
+		},
+	}}
+	o := NewOrchestrator(NewMemoryStore(), []*Definition{def}, SetAddJobFunc(func(ctx context.Context, req *taskqueueworker.AddJobRequest) (string, error) {
+		*queue = append(*queue, req)
+		return "", nil
+	}))
+	return o, queue
+}
+
+func TestSagaCompleted(t *testing.T) {
+	ctx := context.Background()
+	o, queue := newTestOrchestrator(nil)
+	worker := testkit.NewWorkerHarness(types.TaskQueue, o)
+
+	instance, err := o.Start(ctx, "create-order", order{ID: "order-1"})
+	assert.NoError(t, err)
+	runQueue(t, worker, queue)
+
+	instance, err = o.Get(ctx, instance.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCompleted, instance.Status)
+	assert.Len(t, instance.History, 3)
+	var result order
+	instance.Bind(&result)
+	assert.True(t, result.Reserved && result.Charged)
+
+	_, err = o.Abort(ctx, instance.ID, "too late")
+	assert.Error(t, err)
+}
+
+func TestSagaCompensated(t *testing.T) {
+	ctx := context.Background()
+	o, queue := newTestOrchestrator(errors.New("insufficient balance"))
+	worker := testkit.NewWorkerHarness(types.TaskQueue, o)
+
+	instance, err := o.Start(ctx, "create-order", order{ID: "order-1"})
+	assert.NoError(t, err)
+	runQueue(t, worker, queue)
+
+	instance, _ = o.Get(ctx, instance.ID)
+	assert.Equal(t, StatusCompensated, instance.Status)
+	assert.Equal(t, "step charge-payment: insufficient balance", instance.Error)
+	last := instance.History[len(instance.History)-1]
+	assert.True(t, last.Compensate)
+	assert.Equal(t, "reserve-stock", last.Step)
+	var result order
+	instance.Bind(&result)
+	assert.Equal(t, []string{"reserve-stock"}, result.Cancelled)
+	assert.Equal(t, 2, instance.History[2].Attempts)
+}
+
+func TestSagaAbortAndResume(t *testing.T) {
+	ctx := context.Background()
+	o, queue := newTestOrchestrator(nil)
+	worker := testkit.NewWorkerHarness(types.TaskQueue, o)
+
+	instance, err := o.Start(ctx, "create-order", order{ID: "order-1"})
+	assert.NoError(t, err)
+	instance, err = o.Abort(ctx, instance.ID, "cancelled by user")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAborting, instance.Status)
+
+	// job of aborted saga is lost, resume enqueue new job and old job is skipped
+	staleJob := (*queue)[0]
+	*queue = nil
+	_, err = o.Resume(ctx, instance.ID)
+	assert.NoError(t, err)
+	*queue = append(*queue, staleJob)
+	runQueue(t, worker, queue)
+
+	instance, _ = o.Get(ctx, instance.ID)
+	assert.Equal(t, StatusCompensated, instance.Status)
+	assert.Equal(t, "aborted: cancelled by user", instance.Error)
+	assert.Empty(t, instance.History)
+
+	list, err := o.List(ctx, Filter{Saga: "create-order", Status: StatusCompensated})
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+}
@@ -0,0 +1,72 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+type memoryStore struct {
+	mu        sync.Mutex
+	instances map[string][]byte
+}
+
+// NewMemoryStore in memory saga store, state is lost when restart (for test or development only)
+func NewMemoryStore() Store {
+	return &memoryStore{instances: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Create(ctx context.Context, instance *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance.Version = 1
+	s.instances[instance.ID], _ = json.Marshal(instance)
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, id string) (*Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.instances[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var instance Instance
+	err := json.Unmarshal(b, &instance)
+	return &instance, err
+}
+
+func (s *memoryStore) Update(ctx context.Context, instance *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.instances[instance.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	var stored Instance
+	json.Unmarshal(b, &stored)
+	if stored.Version != instance.Version {
+		return ErrConflict
+	}
+	instance.Version++
+	s.instances[instance.ID], _ = json.Marshal(instance)
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, filter Filter) (instances []Instance, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range s.instances {
+		var instance Instance
+		json.Unmarshal(b, &instance)
+		if (filter.Saga == "" || instance.Saga == filter.Saga) && (filter.Status == "" || instance.Status == filter.Status) {
+			instances = append(instances, instance)
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].CreatedAt.After(instances[j].CreatedAt) })
+	if filter.Limit > 0 && len(instances) > filter.Limit {
+		instances = instances[:filter.Limit]
+	}
+	return instances, nil
+}
@@ -0,0 +1,110 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoStore struct {
+	coll *mongo.Collection
+}
+
+type mongoInstance struct {
+	ID          string        `bson:"_id"`
+	Saga        string        `bson:"saga"`
+	Status      Status        `bson:"status"`
+	CurrentStep int           `bson:"current_step"`
+	Data        string        `bson:"data"`
+	Error       string        `bson:"error,omitempty"`
+	History     []StepHistory `bson:"history"`
+	Seq         int           `bson:"seq"`
+	Version     int           `bson:"version"`
+	CreatedAt   time.Time     `bson:"created_at"`
+	UpdatedAt   time.Time     `bson:"updated_at"`
+}
+
+// NewMongoStore saga store in mongo collection (default "saga_instances")
+func NewMongoStore(db *mongo.Database, collection string) Store {
+	if collection == "" {
+		collection = "saga_instances"
+	}
+	return &mongoStore{coll: db.Collection(collection)}
+}
+
+func (s *mongoStore) Create(ctx context.Context, instance *Instance) error {
+	instance.Version = 1
+	_, err := s.coll.InsertOne(ctx, toMongoInstance(instance))
+	return err
+}
+
+func (s *mongoStore) Get(ctx context.Context, id string) (*Instance, error) {
+	var doc mongoInstance
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toInstance(), nil
+}
+
+func (s *mongoStore) Update(ctx context.Context, instance *Instance) error {
+	doc := toMongoInstance(instance)
+	doc.Version++
+	res, err := s.coll.ReplaceOne(ctx, bson.M{"_id": instance.ID, "version": instance.Version}, doc)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	instance.Version++
+	return nil
+}
+
+func (s *mongoStore) List(ctx context.Context, filter Filter) (instances []Instance, err error) {
+	query := bson.M{}
+	if filter.Saga != "" {
+		query["saga"] = filter.Saga
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	cur, err := s.coll.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []mongoInstance
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		instances = append(instances, *doc.toInstance())
+	}
+	return instances, nil
+}
+
+func toMongoInstance(i *Instance) mongoInstance {
+	return mongoInstance{
+		ID: i.ID, Saga: i.Saga, Status: i.Status, CurrentStep: i.CurrentStep, Data: string(i.Data), Error: i.Error,
+		History: i.History, Seq: i.Seq, Version: i.Version, CreatedAt: i.CreatedAt, UpdatedAt: i.UpdatedAt,
+	}
+}
+
+func (m *mongoInstance) toInstance() *Instance {
+	return &Instance{
+		ID: m.ID, Saga: m.Saga, Status: m.Status, CurrentStep: m.CurrentStep, Data: json.RawMessage(m.Data), Error: m.Error,
+		History: m.History, Seq: m.Seq, Version: m.Version, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt,
+	}
+}
@@ -0,0 +1,130 @@
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golangid/candi/candishared"
+)
+
+type sqlStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(argIndex int) string
+}
+
+// NewSQLStore saga store in sql table (postgres, mysql or sqlite3), table is created if not exist
+func NewSQLStore(db *sql.DB, table string) (Store, error) {
+	if table == "" {
+		table = "saga_instances"
+	}
+	s := &sqlStore{db: db, table: table, placeholder: candishared.SQLPlaceholderOf(db)}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+		id VARCHAR(64) NOT NULL PRIMARY KEY,
+		saga VARCHAR(255) NOT NULL,
+		status VARCHAR(32) NOT NULL,
+		current_step INTEGER NOT NULL,
+		data TEXT,
+		error TEXT,
+		history TEXT,
+		seq INTEGER NOT NULL,
+		version INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("create saga table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *sqlStore) Create(ctx context.Context, instance *Instance) error {
+	instance.Version = 1
+	history, _ := json.Marshal(instance.History)
+	_, err := s.db.ExecContext(ctx, candishared.SQLRebind(`INSERT INTO `+s.table+
+		` (id, saga, status, current_step, data, error, history, seq, version, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, s.placeholder),
+		instance.ID, instance.Saga, instance.Status, instance.CurrentStep, string(instance.Data), instance.Error, string(history),
+		instance.Seq, instance.Version, instance.CreatedAt, instance.UpdatedAt,
+	)
+	return err
+}
+
+func (s *sqlStore) Get(ctx context.Context, id string) (*Instance, error) {
+	row := s.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT `+sqlColumns+` FROM `+s.table+` WHERE id = ?`, s.placeholder), id)
+	instance, err := scanInstance(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return instance, err
+}
+
+func (s *sqlStore) Update(ctx context.Context, instance *Instance) error {
+	history, _ := json.Marshal(instance.History)
+	res, err := s.db.ExecContext(ctx, candishared.SQLRebind(`UPDATE `+s.table+
+		` SET status = ?, current_step = ?, data = ?, error = ?, history = ?, seq = ?, version = ?, updated_at = ? WHERE id = ? AND version = ?`, s.placeholder),
+		instance.Status, instance.CurrentStep, string(instance.Data), instance.Error, string(history), instance.Seq,
+		instance.Version+1, instance.UpdatedAt, instance.ID, instance.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrConflict
+	}
+	instance.Version++
+	return nil
+}
+
+func (s *sqlStore) List(ctx context.Context, filter Filter) (instances []Instance, err error) {
+	var where []string
+	var args []any
+	if filter.Saga != "" {
+		where, args = append(where, "saga = ?"), append(args, filter.Saga)
+	}
+	if filter.Status != "" {
+		where, args = append(where, "status = ?"), append(args, filter.Status)
+	}
+	query := `SELECT ` + sqlColumns + ` FROM ` + s.table
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, candishared.SQLRebind(query, s.placeholder), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		instance, err := scanInstance(rows)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, *instance)
+	}
+	return instances, rows.Err()
+}
+
+const sqlColumns = `id, saga, status, current_step, data, error, history, seq, version, created_at, updated_at`
+
+func scanInstance(row interface{ Scan(dest ...any) error }) (*Instance, error) {
+	var instance Instance
+	var data, errMessage, history sql.NullString
+	if err := row.Scan(&instance.ID, &instance.Saga, &instance.Status, &instance.CurrentStep, &data, &errMessage, &history,
+		&instance.Seq, &instance.Version, &instance.CreatedAt, &instance.UpdatedAt); err != nil {
+		return nil, err
+	}
+	instance.Data, instance.Error = json.RawMessage(data.String), errMessage.String
+	if history.String != "" {
+		json.Unmarshal([]byte(history.String), &instance.History)
+	}
+	return &instance, nil
+}
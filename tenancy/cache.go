@@ -0,0 +1,92 @@
+package tenancy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+type tenantCache struct {
+	interfaces.Cache
+	required bool
+}
+
+// NewCache wrap cache for prefix key with "tenant:{id}:" of tenant in context, key without tenant in context is not prefixed
+// (or return ErrTenantRequired if required). DoCommand is not prefixed, prefix the key with CacheKey
+func NewCache(cache interfaces.Cache, required ...bool) interfaces.Cache {
+	return &tenantCache{Cache: cache, required: len(required) > 0 && required[0]}
+}
+
+// CacheKey prefix key with tenant in context
+func CacheKey(ctx context.Context, key string) string {
+	if tenantID := candishared.GetTenantFromContext(ctx); tenantID != "" {
+		return "tenant:" + tenantID + ":" + key
+	}
+	return key
+}
+
+func (c *tenantCache) key(ctx context.Context, key string) (string, error) {
+	if c.required && candishared.GetTenantFromContext(ctx) == "" {
+		return "", ErrTenantRequired
+	}
+	return CacheKey(ctx, key), nil
+}
+
+func (c *tenantCache) Get(ctx context.Context, key string) ([]byte, error) {
+	key, err := c.key(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *tenantCache) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	prefixed, err := c.key(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := c.Cache.GetKeys(ctx, prefixed)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(prefixed, pattern)
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, prefix)
+	}
+	return keys, nil
+}
+
+func (c *tenantCache) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	key, err := c.key(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return c.Cache.GetTTL(ctx, key)
+}
+
+func (c *tenantCache) Set(ctx context.Context, key string, value any, expire time.Duration) error {
+	key, err := c.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	return c.Cache.Set(ctx, key, value, expire)
+}
+
+func (c *tenantCache) Exists(ctx context.Context, key string) (bool, error) {
+	key, err := c.key(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return c.Cache.Exists(ctx, key)
+}
+
+func (c *tenantCache) Delete(ctx context.Context, key string) error {
+	key, err := c.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	return c.Cache.Delete(ctx, key)
+}
@@ -0,0 +1,81 @@
+package tenancy
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/wrapper"
+)
+
+type (
+	option struct {
+		resolvers []Resolver
+		required  bool
+		validator func(ctx context.Context, tenantID string) error
+	}
+
+	// OptionFunc type
+	OptionFunc func(*option)
+)
+
+// SetResolvers option func, resolvers tried in order until tenant found (default FromHeader())
+func SetResolvers(resolvers ...Resolver) OptionFunc {
+	return func(o *option) {
+		o.resolvers = resolvers
+	}
+}
+
+// SetRequired option func, reject request without tenant with status 400 (default true)
+func SetRequired(required bool) OptionFunc {
+	return func(o *option) {
+		o.required = required
+	}
+}
+
+// SetValidator option func, check resolved tenant (example: tenant exists and active), request is rejected with status 403 if error
+func SetValidator(validator func(ctx context.Context, tenantID string) error) OptionFunc {
+	return func(o *option) {
+		o.validator = validator
+	}
+}
+
+// HTTPMiddleware resolve tenant from request into request context
+func HTTPMiddleware(opts ...OptionFunc) func(http.Handler) http.Handler {
+	opt := option{resolvers: []Resolver{FromHeader()}, required: true}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var tenantID string
+			for _, resolve := range opt.resolvers {
+				if tenantID = resolve(req); tenantID != "" {
+					break
+				}
+			}
+			if tenantID == "" {
+				if opt.required {
+					wrapper.NewHTTPResponse(http.StatusBadRequest, ErrTenantRequired.Error()).JSON(w)
+					return
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+			if err := ValidateTenantID(tenantID); err != nil {
+				wrapper.NewHTTPResponse(http.StatusBadRequest, err.Error()).JSON(w)
+				return
+			}
+
+			ctx := candishared.SetTenantToContext(req.Context(), tenantID)
+			if opt.validator != nil {
+				if err := opt.validator(ctx, tenantID); err != nil {
+					wrapper.NewHTTPResponse(http.StatusForbidden, err.Error()).JSON(w)
+					return
+				}
+			}
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
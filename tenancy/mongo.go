@@ -0,0 +1,117 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// MongoRouter route mongo database per tenant: dedicated database instance per tenant,
+	// or database per tenant in shared client (database name from format)
+	MongoRouter struct {
+		mu       sync.RWMutex
+		shared   interfaces.MongoDatabase
+		tenants  map[string]interfaces.MongoDatabase
+		database string
+		required bool
+	}
+
+	// MongoRouterOption option func
+	MongoRouterOption func(*MongoRouter)
+)
+
+// MongoRouterTenant option func, register dedicated database of tenant
+func MongoRouterTenant(tenantID string, db interfaces.MongoDatabase) MongoRouterOption {
+	return func(r *MongoRouter) {
+		r.tenants[tenantID] = db
+	}
+}
+
+// MongoRouterDatabase option func, database name format of tenant in shared client (example "tenant_%s")
+func MongoRouterDatabase(format string) MongoRouterOption {
+	return func(r *MongoRouter) {
+		r.database = format
+	}
+}
+
+// MongoRouterRequired option func, return ErrTenantRequired when tenant not found in context instead of use shared database
+func MongoRouterRequired(required bool) MongoRouterOption {
+	return func(r *MongoRouter) {
+		r.required = required
+	}
+}
+
+// NewMongoRouter create mongo router, shared database is used for tenant without dedicated database
+func NewMongoRouter(shared interfaces.MongoDatabase, opts ...MongoRouterOption) *MongoRouter {
+	r := &MongoRouter{shared: shared, tenants: make(map[string]interfaces.MongoDatabase)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ReadDB get read database of tenant in context
+func (r *MongoRouter) ReadDB(ctx context.Context) (*mongo.Database, error) {
+	return r.route(ctx, func(db interfaces.MongoDatabase) *mongo.Database { return db.ReadDB() })
+}
+
+// WriteDB get write database of tenant in context
+func (r *MongoRouter) WriteDB(ctx context.Context) (*mongo.Database, error) {
+	return r.route(ctx, func(db interfaces.MongoDatabase) *mongo.Database { return db.WriteDB() })
+}
+
+func (r *MongoRouter) route(ctx context.Context, get func(interfaces.MongoDatabase) *mongo.Database) (*mongo.Database, error) {
+	tenantID := candishared.GetTenantFromContext(ctx)
+	if tenantID == "" {
+		if r.required {
+			return nil, ErrTenantRequired
+		}
+		return get(r.shared), nil
+	}
+
+	r.mu.RLock()
+	db, ok := r.tenants[tenantID]
+	r.mu.RUnlock()
+	if ok {
+		return get(db), nil
+	}
+	if r.database == "" {
+		return get(r.shared), nil
+	}
+	if err := ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	shared := get(r.shared)
+	return shared.Client().Database(fmt.Sprintf(r.database, tenantID)), nil
+}
+
+// Health check all databases
+func (r *MongoRouter) Health() map[string]error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	health := r.shared.Health()
+	for tenantID, db := range r.tenants {
+		for key, err := range db.Health() {
+			health["tenant_"+tenantID+"_"+key] = err
+		}
+	}
+	return health
+}
+
+// Disconnect close all registered tenant databases, shared database is closed by owner
+func (r *MongoRouter) Disconnect(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for tenantID, db := range r.tenants {
+		if err := db.Disconnect(ctx); err != nil {
+			return fmt.Errorf("disconnect database of tenant %s: %w", tenantID, err)
+		}
+		delete(r.tenants, tenantID)
+	}
+	return nil
+}
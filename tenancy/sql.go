@@ -0,0 +1,178 @@
+package tenancy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+type (
+	// SQLRouter route sql database per tenant: dedicated connection per tenant (registered or connected on first use),
+	// or shared connection with schema per tenant (postgres search_path in transaction)
+	SQLRouter struct {
+		mu       sync.RWMutex
+		shared   interfaces.SQLDatabase
+		tenants  map[string]interfaces.SQLDatabase
+		connect  func(ctx context.Context, tenantID string) (interfaces.SQLDatabase, error)
+		schema   string
+		required bool
+	}
+
+	// SQLRouterOption option func
+	SQLRouterOption func(*SQLRouter)
+)
+
+// SQLRouterTenant option func, register dedicated database of tenant
+func SQLRouterTenant(tenantID string, db interfaces.SQLDatabase) SQLRouterOption {
+	return func(r *SQLRouter) {
+		r.tenants[tenantID] = db
+	}
+}
+
+// SQLRouterConnect option func, connect dedicated database of unregistered tenant on first use (connection is cached)
+func SQLRouterConnect(connect func(ctx context.Context, tenantID string) (interfaces.SQLDatabase, error)) SQLRouterOption {
+	return func(r *SQLRouter) {
+		r.connect = connect
+	}
+}
+
+// SQLRouterSchema option func, schema name format of tenant in shared database (example "tenant_%s"),
+// schema is selected with "SET LOCAL search_path" in transaction from BeginTx (postgres only)
+func SQLRouterSchema(format string) SQLRouterOption {
+	return func(r *SQLRouter) {
+		r.schema = format
+	}
+}
+
+// SQLRouterRequired option func, return ErrTenantRequired when tenant not found in context instead of use shared database
+func SQLRouterRequired(required bool) SQLRouterOption {
+	return func(r *SQLRouter) {
+		r.required = required
+	}
+}
+
+// NewSQLRouter create sql router, shared database is used for tenant without dedicated database
+func NewSQLRouter(shared interfaces.SQLDatabase, opts ...SQLRouterOption) *SQLRouter {
+	r := &SQLRouter{shared: shared, tenants: make(map[string]interfaces.SQLDatabase)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Database get database of tenant in context
+func (r *SQLRouter) Database(ctx context.Context) (interfaces.SQLDatabase, error) {
+	tenantID := candishared.GetTenantFromContext(ctx)
+	if tenantID == "" {
+		if r.required {
+			return nil, ErrTenantRequired
+		}
+		return r.shared, nil
+	}
+
+	r.mu.RLock()
+	db, ok := r.tenants[tenantID]
+	r.mu.RUnlock()
+	if ok {
+		return db, nil
+	}
+	if r.connect == nil {
+		return r.shared, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if db, ok := r.tenants[tenantID]; ok {
+		return db, nil
+	}
+	if err := ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	db, err := r.connect(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("connect database of tenant %s: %w", tenantID, err)
+	}
+	r.tenants[tenantID] = db
+	return db, nil
+}
+
+// ReadDB get read connection of tenant in context
+func (r *SQLRouter) ReadDB(ctx context.Context) (*sql.DB, error) {
+	db, err := r.Database(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.ReadDB(), nil
+}
+
+// WriteDB get write connection of tenant in context
+func (r *SQLRouter) WriteDB(ctx context.Context) (*sql.DB, error) {
+	db, err := r.Database(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.WriteDB(), nil
+}
+
+// BeginTx begin transaction in write connection of tenant in context, with tenant schema selected if schema format is set
+func (r *SQLRouter) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	db, err := r.WriteDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if schema := r.SchemaName(ctx); schema != "" {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL search_path TO "+quoteIdentifier(schema)); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("set tenant schema: %w", err)
+		}
+	}
+	return tx, nil
+}
+
+// SchemaName get schema name of tenant in context, empty if schema format is not set or tenant not found
+func (r *SQLRouter) SchemaName(ctx context.Context) string {
+	tenantID := candishared.GetTenantFromContext(ctx)
+	if r.schema == "" || tenantID == "" || ValidateTenantID(tenantID) != nil {
+		return ""
+	}
+	return fmt.Sprintf(r.schema, tenantID)
+}
+
+// Health check all databases
+func (r *SQLRouter) Health() map[string]error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	health := r.shared.Health()
+	for tenantID, db := range r.tenants {
+		for key, err := range db.Health() {
+			health["tenant_"+tenantID+"_"+key] = err
+		}
+	}
+	return health
+}
+
+// Disconnect close all tenant databases, shared database is closed by owner
+func (r *SQLRouter) Disconnect(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for tenantID, db := range r.tenants {
+		if err := db.Disconnect(ctx); err != nil {
+			return fmt.Errorf("disconnect database of tenant %s: %w", tenantID, err)
+		}
+		delete(r.tenants, tenantID)
+	}
+	return nil
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
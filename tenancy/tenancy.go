@@ -0,0 +1,76 @@
+// Package tenancy multi-tenancy support: resolve tenant from request into context (see candishared.GetTenantFromContext),
+// route SQL/Mongo database per tenant (connection or schema), and prefix cache keys with tenant.
+// Tenant in context is propagated to published kafka/rabbitmq message in header X-Tenant-ID and restored in worker context.
+package tenancy
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
+)
+
+var (
+	// ErrTenantRequired tenant not found in context
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrInvalidTenant tenant id contains character other than letter, digit, underscore or dash
+	ErrInvalidTenant = errors.New("invalid tenant id")
+
+	tenantIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,63}$`)
+)
+
+// ValidateTenantID check tenant id is safe for used as schema/database name and cache key
+func ValidateTenantID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// Resolver resolve tenant id from request, return empty if not found
+type Resolver func(req *http.Request) string
+
+// FromHeader resolve tenant from request header (default X-Tenant-ID)
+func FromHeader(name ...string) Resolver {
+	header := candihelper.HeaderXTenantID
+	if len(name) > 0 {
+		header = name[0]
+	}
+	return func(req *http.Request) string {
+		return req.Header.Get(header)
+	}
+}
+
+// FromSubdomain resolve tenant from first label of host under base domain,
+// example base domain "example.com" and host "acme.example.com" resolve tenant "acme"
+func FromSubdomain(baseDomain string) Resolver {
+	suffix := "." + strings.TrimPrefix(baseDomain, ".")
+	return func(req *http.Request) string {
+		host := req.Host
+		if h, _, ok := strings.Cut(host, ":"); ok {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// FromTokenClaim resolve tenant from field in token claim additional data (token claim is set by bearer middleware,
+// so tenancy middleware must be placed after auth middleware)
+func FromTokenClaim(field string) Resolver {
+	return func(req *http.Request) string {
+		claim, ok := candishared.GetValueFromContext(req.Context(), candishared.ContextKeyTokenClaim).(*candishared.TokenClaim)
+		if !ok || claim == nil {
+			return ""
+		}
+		additional, _ := claim.Additional.(map[string]any)
+		tenantID, _ := additional[field].(string)
+		return tenantID
+	}
+}
@@ -0,0 +1,117 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/config/database"
+	"github.com/golangid/candi/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var tenantID string
+	handler := HTTPMiddleware(
+		SetResolvers(FromTokenClaim("tenant_id"), FromHeader(), FromSubdomain("example.com")),
+		SetValidator(func(ctx context.Context, tenantID string) error {
+			if tenantID == "suspended" {
+				return errors.New("tenant is suspended")
+			}
+			return nil
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tenantID = candishared.GetTenantFromContext(req.Context())
+	}))
+
+	tests := []struct {
+		name, host, header, claim, wantTenant string
+		wantCode                              int
+	}{
+		{name: "header", host: "api.example.com", header: "acme", wantTenant: "acme", wantCode: http.StatusOK},
+		{name: "subdomain", host: "globex.example.com:8000", wantTenant: "globex", wantCode: http.StatusOK},
+		{name: "token claim first", host: "globex.example.com", header: "acme", claim: "initech", wantTenant: "initech", wantCode: http.StatusOK},
+		{name: "not found", host: "localhost", wantCode: http.StatusBadRequest},
+		{name: "invalid", host: "localhost", header: "acme; DROP", wantCode: http.StatusBadRequest},
+		{name: "rejected by validator", host: "localhost", header: "suspended", wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenantID = ""
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if tt.claim != "" {
+				req = req.WithContext(candishared.SetToContext(req.Context(), candishared.ContextKeyTokenClaim,
+					&candishared.TokenClaim{Additional: map[string]any{"tenant_id": tt.claim}}))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantTenant, tenantID)
+		})
+	}
+}
+
+func TestSQLRouter(t *testing.T) {
+	shared, dedicated := &database.SQLInstance{}, &database.SQLInstance{}
+	var connected []string
+	router := NewSQLRouter(shared,
+		SQLRouterTenant("acme", dedicated),
+		SQLRouterConnect(func(ctx context.Context, tenantID string) (interfaces.SQLDatabase, error) {
+			connected = append(connected, tenantID)
+			return &database.SQLInstance{}, nil
+		}),
+		SQLRouterSchema("tenant_%s"),
+	)
+
+	db, err := router.Database(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, shared, db)
+
+	ctx := candishared.SetTenantToContext(context.Background(), "acme")
+	db, _ = router.Database(ctx)
+	assert.Same(t, dedicated, db)
+	assert.Equal(t, "tenant_acme", router.SchemaName(ctx))
+
+	ctx = candishared.SetTenantToContext(context.Background(), "globex")
+	first, _ := router.Database(ctx)
+	second, _ := router.Database(ctx)
+	assert.Same(t, first, second)
+	assert.Equal(t, []string{"globex"}, connected)
+
+	_, err = NewSQLRouter(shared, SQLRouterRequired(true)).Database(context.Background())
+	assert.ErrorIs(t, err, ErrTenantRequired)
+}
+
+func TestCache(t *testing.T) {
+	redis := testkit.NewRedis()
+	cache := NewCache(redis.Cache())
+	acme := candishared.SetTenantToContext(context.Background(), "acme")
+	globex := candishared.SetTenantToContext(context.Background(), "globex")
+
+	require.NoError(t, cache.Set(acme, "product:1", "acme product", time.Minute))
+	require.NoError(t, cache.Set(globex, "product:1", "globex product", time.Minute))
+
+	value, err := cache.Get(acme, "product:1")
+	require.NoError(t, err)
+	assert.Equal(t, "acme product", string(value))
+	value, _ = cache.Get(globex, "product:1")
+	assert.Equal(t, "globex product", string(value))
+	assert.ElementsMatch(t, []string{"tenant:acme:product:1", "tenant:globex:product:1"}, redis.Keys())
+
+	keys, err := cache.GetKeys(acme, "product:*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"product:1"}, keys)
+
+	_, err = NewCache(redis.Cache(), true).Get(context.Background(), "product:1")
+	assert.ErrorIs(t, err, ErrTenantRequired)
+}
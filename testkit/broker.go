@@ -0,0 +1,88 @@
+package testkit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// Broker in-memory broker, implement interfaces.Broker and interfaces.Publisher, capture all published messages
+type Broker struct {
+	mu         sync.Mutex
+	workerType types.Worker
+	messages   []candishared.PublisherArgument
+	err        error
+}
+
+var (
+	_ interfaces.Broker    = (*Broker)(nil)
+	_ interfaces.Publisher = (*Broker)(nil)
+)
+
+// NewBroker create in-memory broker with worker type (example types.Kafka)
+func NewBroker(workerType types.Worker) *Broker {
+	return &Broker{workerType: workerType}
+}
+
+// GetPublisher broker is the publisher
+func (b *Broker) GetPublisher() interfaces.Publisher { return b }
+
+// GetName worker type
+func (b *Broker) GetName() types.Worker { return b.workerType }
+
+// Health always healthy
+func (b *Broker) Health() map[string]error { return map[string]error{string(b.workerType): nil} }
+
+// Disconnect reset captured messages
+func (b *Broker) Disconnect(ctx context.Context) error {
+	b.Reset()
+	return nil
+}
+
+// PublishMessage capture message, return error from SetPublishError
+func (b *Broker) PublishMessage(ctx context.Context, args *candishared.PublisherArgument) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	if err := args.Validate(); err != nil {
+		return err
+	}
+	if err := args.ApplyCodec(); err != nil {
+		return err
+	}
+	msg := *args
+	msg.Message = append([]byte{}, args.Message...)
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// SetPublishError set error returned by next publish (nil for reset)
+func (b *Broker) SetPublishError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+// Messages captured messages, filter by topics if not empty
+func (b *Broker) Messages(topics ...string) (messages []candishared.PublisherArgument) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, msg := range b.messages {
+		if len(topics) == 0 || containsString(topics, msg.Topic) {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// Reset clear captured messages and publish error
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages, b.err = nil, nil
+}
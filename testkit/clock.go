@@ -0,0 +1,48 @@
+package testkit
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock controllable candihelper.Clock for test, time only move with Set or Advance
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFakeClock create fake clock at given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now current fake time
+func (c *FakeClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Since elapsed duration from t to current fake time
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Until duration from current fake time to t
+func (c *FakeClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Set set current fake time
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// Advance move current fake time forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
@@ -0,0 +1,92 @@
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// EventContextBuilder builder for worker handler event context
+type EventContextBuilder struct {
+	ctx          context.Context
+	workerType   types.Worker
+	handlerRoute string
+	key          string
+	header       map[string]string
+	message      []byte
+	err          error
+}
+
+// NewEventContext create event context builder, example:
+//
+//	eventContext := testkit.NewEventContext().WithWorkerType(types.Kafka).WithHandlerRoute("order-created").WithJSON(payload).Build()
+func NewEventContext() *EventContextBuilder {
+	return &EventContextBuilder{ctx: context.Background(), header: map[string]string{}}
+}
+
+// WithContext set context
+func (b *EventContextBuilder) WithContext(ctx context.Context) *EventContextBuilder {
+	b.ctx = ctx
+	return b
+}
+
+// WithWorkerType set worker type
+func (b *EventContextBuilder) WithWorkerType(workerType types.Worker) *EventContextBuilder {
+	b.workerType = workerType
+	return b
+}
+
+// WithHandlerRoute set handler route (topic, task name, job name, ...)
+func (b *EventContextBuilder) WithHandlerRoute(route string) *EventContextBuilder {
+	b.handlerRoute = route
+	return b
+}
+
+// WithKey set message key
+func (b *EventContextBuilder) WithKey(key string) *EventContextBuilder {
+	b.key = key
+	return b
+}
+
+// WithHeader add message header
+func (b *EventContextBuilder) WithHeader(key, value string) *EventContextBuilder {
+	b.header[key] = value
+	return b
+}
+
+// WithMessage set raw message
+func (b *EventContextBuilder) WithMessage(message []byte) *EventContextBuilder {
+	b.message = message
+	return b
+}
+
+// WithJSON set message from json encoded value
+func (b *EventContextBuilder) WithJSON(value any) *EventContextBuilder {
+	b.message, b.err = json.Marshal(value)
+	return b
+}
+
+// WithProto set message from proto value (encoded with candishared.MarshalProtoPayload)
+func (b *EventContextBuilder) WithProto(value proto.Message) *EventContextBuilder {
+	b.message, b.err = candishared.MarshalProtoPayload(value)
+	return b
+}
+
+// Build create event context, panic if message encoding failed
+func (b *EventContextBuilder) Build() *candishared.EventContext {
+	if b.err != nil {
+		panic("testkit: encode event context message: " + b.err.Error())
+	}
+	eventContext := candishared.NewEventContextWithResult(&bytes.Buffer{}, &bytes.Buffer{})
+	eventContext.SetContext(b.ctx)
+	eventContext.SetWorkerType(string(b.workerType))
+	eventContext.SetHandlerRoute(b.handlerRoute)
+	eventContext.SetKey(b.key)
+	eventContext.SetHeader(b.header)
+	eventContext.Write(b.message)
+	return eventContext
+}
@@ -0,0 +1,51 @@
+package testkit
+
+import (
+	"context"
+	"net"
+
+	grpcserver "github.com/golangid/candi/codebase/app/grpc_server"
+	"github.com/golangid/candi/codebase/interfaces"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// GRPCHarness serve gRPC handlers in memory (bufconn) with same interceptors and middleware group as GRPC server
+type GRPCHarness struct {
+	server   *grpc.Server
+	listener *bufconn.Listener
+	conn     *grpc.ClientConn
+}
+
+// NewGRPCHarness register gRPC handlers and serve in memory, use Conn for create generated client.
+// Call Close after test
+func NewGRPCHarness(handlers []interfaces.GRPCHandler, opts ...grpcserver.OptionFunc) (*GRPCHarness, error) {
+	h := &GRPCHarness{
+		server:   grpcserver.NewServerEngine(handlers, opts...),
+		listener: bufconn.Listen(1024 * 1024),
+	}
+	go h.server.Serve(h.listener)
+
+	var err error
+	h.conn, err = grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return h.listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		h.server.Stop()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Conn client connection to in memory server
+func (h *GRPCHarness) Conn() *grpc.ClientConn {
+	return h.conn
+}
+
+// Close stop server and close client connection
+func (h *GRPCHarness) Close() {
+	h.conn.Close()
+	h.server.Stop()
+}
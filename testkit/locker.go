@@ -0,0 +1,116 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// Locker in-memory locker, implement interfaces.Locker
+type Locker struct {
+	mu    sync.Mutex
+	locks map[string]time.Time // key -> expire time (zero for no expiration)
+	ttl   time.Duration
+}
+
+var _ interfaces.Locker = (*Locker)(nil)
+
+// NewLocker create in-memory locker
+func NewLocker() *Locker {
+	return &Locker{locks: make(map[string]time.Time)}
+}
+
+// IsLocked lock key, return true if key has been locked before
+func (l *Locker) IsLocked(key string) bool {
+	return l.IsLockedTTL(key, 0)
+}
+
+// IsLockedTTL lock key with ttl, return true if key has been locked before
+func (l *Locker) IsLockedTTL(key string, ttl time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.isLocked(key) {
+		return true
+	}
+	if ttl <= 0 {
+		ttl = l.ttl
+	}
+	var expire time.Time
+	if ttl > 0 {
+		expire = time.Now().Add(ttl)
+	}
+	l.locks[key] = expire
+	return false
+}
+
+// HasBeenLocked check key is locked
+func (l *Locker) HasBeenLocked(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.isLocked(key)
+}
+
+// Unlock release key
+func (l *Locker) Unlock(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.locks, key)
+}
+
+// Reset release keys with pattern (support suffix wildcard "*")
+func (l *Locker) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k := range l.locks {
+		if k == key || (strings.HasSuffix(key, "*") && strings.HasPrefix(k, strings.TrimSuffix(key, "*"))) {
+			delete(l.locks, k)
+		}
+	}
+}
+
+// Lock wait until key is released or timeout
+func (l *Locker) Lock(key string, timeout time.Duration) (unlockFunc func(), err error) {
+	if timeout <= 0 {
+		return func() {}, errors.New("timeout must be positive")
+	}
+	if key == "" {
+		return func() {}, errors.New("key cannot empty")
+	}
+
+	unlockFunc = func() { l.Unlock(key) }
+	deadline := time.Now().Add(timeout)
+	for l.IsLocked(key) {
+		if time.Now().After(deadline) {
+			return unlockFunc, errors.New("timeout when waiting unlock another process")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return unlockFunc, nil
+}
+
+// GetPrefixLocker empty prefix
+func (l *Locker) GetPrefixLocker() string { return "" }
+
+// GetTTLLocker default ttl
+func (l *Locker) GetTTLLocker() time.Duration { return l.ttl }
+
+// Disconnect release all keys
+func (l *Locker) Disconnect(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locks = make(map[string]time.Time)
+	return nil
+}
+
+func (l *Locker) isLocked(key string) bool {
+	expire, ok := l.locks[key]
+	if ok && !expire.IsZero() && time.Now().After(expire) {
+		delete(l.locks, key)
+		return false
+	}
+	return ok
+}
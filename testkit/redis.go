@@ -0,0 +1,562 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/cache"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/gomodule/redigo/redis"
+)
+
+type redisEntry struct {
+	value    any // []byte (string), map[string][]byte (hash), or [][]byte (list)
+	expireAt time.Time
+}
+
+// Redis in-memory fake redis server, implement interfaces.RedisPool. Support common string, counter, expire,
+// hash and list commands (enough for candi cache & locker), unsupported command return error
+type Redis struct {
+	mu     sync.Mutex
+	data   map[string]*redisEntry
+	offset time.Duration
+	pool   *redis.Pool
+}
+
+var _ interfaces.RedisPool = (*Redis)(nil)
+
+// NewRedis create in-memory fake redis
+func NewRedis() *Redis {
+	r := &Redis{data: make(map[string]*redisEntry)}
+	r.pool = &redis.Pool{Dial: func() (redis.Conn, error) { return &redisConn{r: r}, nil }}
+	return r
+}
+
+// ReadPool redigo pool connected to fake redis
+func (r *Redis) ReadPool() *redis.Pool { return r.pool }
+
+// WritePool redigo pool connected to fake redis
+func (r *Redis) WritePool() *redis.Pool { return r.pool }
+
+// Health always healthy
+func (r *Redis) Health() map[string]error {
+	return map[string]error{"redis_read": nil, "redis_write": nil}
+}
+
+// Cache redis cache using fake redis
+func (r *Redis) Cache() interfaces.Cache { return cache.NewRedisCache(r.pool, r.pool) }
+
+// Disconnect flush all data
+func (r *Redis) Disconnect(ctx context.Context) error {
+	r.FlushAll()
+	return nil
+}
+
+// FlushAll delete all keys
+func (r *Redis) FlushAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data = make(map[string]*redisEntry)
+}
+
+// Advance move fake redis clock forward, for test key expiration without sleep
+func (r *Redis) Advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offset += d
+}
+
+// Keys list all not expired keys
+func (r *Redis) Keys() (keys []string) {
+	reply, _ := r.do("KEYS", [][]byte{[]byte("*")})
+	for _, k := range reply.([]any) {
+		keys = append(keys, string(k.([]byte)))
+	}
+	return keys
+}
+
+func (r *Redis) now() time.Time {
+	return time.Now().Add(r.offset)
+}
+
+func (r *Redis) get(key string) *redisEntry {
+	e, ok := r.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !r.now().Before(e.expireAt) {
+		delete(r.data, key)
+		return nil
+	}
+	return e
+}
+
+var (
+	errRedisWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errRedisSyntax    = errors.New("ERR syntax error")
+	errRedisNotInt    = errors.New("ERR value is not an integer or out of range")
+)
+
+func (r *Redis) do(command string, args [][]byte) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	command = strings.ToUpper(command)
+	argc := map[string]int{
+		"GET": 1, "SET": 2, "SETEX": 3, "SETNX": 2, "INCR": 1, "INCRBY": 2, "DECR": 1, "DECRBY": 2,
+		"EXPIRE": 2, "PEXPIRE": 2, "TTL": 1, "PTTL": 1, "PERSIST": 1, "KEYS": 1, "SCAN": 1, "MGET": 1, "DEL": 1, "UNLINK": 1, "EXISTS": 1,
+		"HSET": 3, "HGET": 2, "HDEL": 2, "HGETALL": 1, "HEXISTS": 2, "HLEN": 1,
+		"LPUSH": 2, "RPUSH": 2, "LPOP": 1, "RPOP": 1, "LLEN": 1, "LRANGE": 3, "ECHO": 1,
+	}[command]
+	if len(args) < argc {
+		return nil, fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(command))
+	}
+
+	switch command {
+	case "PING":
+		return "PONG", nil
+	case "ECHO":
+		return args[0], nil
+	case "SELECT", "CONFIG", "UNWATCH", "DISCARD":
+		return "OK", nil
+	case "FLUSHDB", "FLUSHALL":
+		r.data = make(map[string]*redisEntry)
+		return "OK", nil
+
+	case "GET":
+		e := r.get(string(args[0]))
+		if e == nil {
+			return nil, nil
+		}
+		if b, ok := e.value.([]byte); ok {
+			return b, nil
+		}
+		return nil, errRedisWrongType
+	case "MGET":
+		reply := make([]any, len(args))
+		for i, key := range args {
+			if e := r.get(string(key)); e != nil {
+				if b, ok := e.value.([]byte); ok {
+					reply[i] = b
+				}
+			}
+		}
+		return reply, nil
+	case "SET", "SETNX", "SETEX":
+		key, value := string(args[0]), args[1]
+		var expire time.Duration
+		var nx, xx bool
+		if command == "SETNX" {
+			nx = true
+		}
+		if command == "SETEX" {
+			sec, err := strconv.ParseInt(string(args[1]), 10, 64)
+			if err != nil {
+				return nil, errRedisNotInt
+			}
+			key, value, expire = string(args[0]), args[2], time.Duration(sec)*time.Second
+		}
+		for i := 2; command == "SET" && i < len(args); i++ {
+			switch opt := strings.ToUpper(string(args[i])); opt {
+			case "NX":
+				nx = true
+			case "XX":
+				xx = true
+			case "EX", "PX":
+				if i+1 >= len(args) {
+					return nil, errRedisSyntax
+				}
+				n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+				if err != nil {
+					return nil, errRedisNotInt
+				}
+				expire = time.Duration(n) * time.Millisecond
+				if opt == "EX" {
+					expire = time.Duration(n) * time.Second
+				}
+				i++
+			default:
+				return nil, errRedisSyntax
+			}
+		}
+		exists := r.get(key) != nil
+		if (nx && exists) || (xx && !exists) {
+			if command == "SETNX" {
+				return int64(0), nil
+			}
+			return nil, nil
+		}
+		e := &redisEntry{value: append([]byte{}, value...)}
+		if expire > 0 {
+			e.expireAt = r.now().Add(expire)
+		}
+		r.data[key] = e
+		if command == "SETNX" {
+			return int64(1), nil
+		}
+		return "OK", nil
+
+	case "INCR", "INCRBY", "DECR", "DECRBY":
+		delta := int64(1)
+		if command == "INCRBY" || command == "DECRBY" {
+			var err error
+			if delta, err = strconv.ParseInt(string(args[1]), 10, 64); err != nil {
+				return nil, errRedisNotInt
+			}
+		}
+		if strings.HasPrefix(command, "DECR") {
+			delta = -delta
+		}
+		e := r.get(string(args[0]))
+		if e == nil {
+			e = &redisEntry{value: []byte("0")}
+			r.data[string(args[0])] = e
+		}
+		b, ok := e.value.([]byte)
+		if !ok {
+			return nil, errRedisWrongType
+		}
+		n, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return nil, errRedisNotInt
+		}
+		n += delta
+		e.value = []byte(strconv.FormatInt(n, 10))
+		return n, nil
+
+	case "DEL", "UNLINK", "EXISTS":
+		var count int64
+		for _, key := range args {
+			if r.get(string(key)) != nil {
+				count++
+				if command != "EXISTS" {
+					delete(r.data, string(key))
+				}
+			}
+		}
+		return count, nil
+	case "EXPIRE", "PEXPIRE":
+		e := r.get(string(args[0]))
+		if e == nil {
+			return int64(0), nil
+		}
+		n, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			return nil, errRedisNotInt
+		}
+		unit := time.Second
+		if command == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		if n <= 0 {
+			delete(r.data, string(args[0]))
+			return int64(1), nil
+		}
+		e.expireAt = r.now().Add(time.Duration(n) * unit)
+		return int64(1), nil
+	case "TTL", "PTTL":
+		e := r.get(string(args[0]))
+		if e == nil {
+			return int64(-2), nil
+		}
+		if e.expireAt.IsZero() {
+			return int64(-1), nil
+		}
+		ttl := e.expireAt.Sub(r.now())
+		if command == "PTTL" {
+			return int64(ttl / time.Millisecond), nil
+		}
+		return int64((ttl + time.Second - 1) / time.Second), nil
+	case "PERSIST":
+		e := r.get(string(args[0]))
+		if e == nil || e.expireAt.IsZero() {
+			return int64(0), nil
+		}
+		e.expireAt = time.Time{}
+		return int64(1), nil
+	case "KEYS":
+		return r.keys(string(args[0])), nil
+	case "SCAN": // all matched keys in single iteration (cursor "0"), COUNT is ignored
+		pattern := "*"
+		for i := 1; i+1 < len(args); i += 2 {
+			if strings.EqualFold(string(args[i]), "MATCH") {
+				pattern = string(args[i+1])
+			}
+		}
+		return []any{[]byte("0"), r.keys(pattern)}, nil
+
+	case "HSET", "HGET", "HDEL", "HGETALL", "HEXISTS", "HLEN":
+		e := r.get(string(args[0]))
+		if e == nil {
+			if command != "HSET" {
+				return map[string]any{"HGETALL": []any{}, "HDEL": int64(0), "HEXISTS": int64(0), "HLEN": int64(0)}[command], nil
+			}
+			e = &redisEntry{value: map[string][]byte{}}
+			r.data[string(args[0])] = e
+		}
+		hash, ok := e.value.(map[string][]byte)
+		if !ok {
+			return nil, errRedisWrongType
+		}
+		switch command {
+		case "HSET":
+			if len(args)%2 != 1 {
+				return nil, errRedisSyntax
+			}
+			var added int64
+			for i := 1; i < len(args); i += 2 {
+				if _, ok := hash[string(args[i])]; !ok {
+					added++
+				}
+				hash[string(args[i])] = append([]byte{}, args[i+1]...)
+			}
+			return added, nil
+		case "HGET":
+			if v, ok := hash[string(args[1])]; ok {
+				return v, nil
+			}
+			return nil, nil
+		case "HDEL":
+			var deleted int64
+			for _, field := range args[1:] {
+				if _, ok := hash[string(field)]; ok {
+					delete(hash, string(field))
+					deleted++
+				}
+			}
+			return deleted, nil
+		case "HEXISTS":
+			_, ok := hash[string(args[1])]
+			return map[bool]int64{true: 1, false: 0}[ok], nil
+		case "HLEN":
+			return int64(len(hash)), nil
+		default: // HGETALL
+			fields := make([]string, 0, len(hash))
+			for field := range hash {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			reply := make([]any, 0, len(hash)*2)
+			for _, field := range fields {
+				reply = append(reply, []byte(field), hash[field])
+			}
+			return reply, nil
+		}
+
+	case "LPUSH", "RPUSH", "LPOP", "RPOP", "LLEN", "LRANGE":
+		e := r.get(string(args[0]))
+		if e == nil {
+			if command != "LPUSH" && command != "RPUSH" {
+				return map[string]any{"LLEN": int64(0), "LRANGE": []any{}}[command], nil
+			}
+			e = &redisEntry{value: [][]byte{}}
+			r.data[string(args[0])] = e
+		}
+		list, ok := e.value.([][]byte)
+		if !ok {
+			return nil, errRedisWrongType
+		}
+		switch command {
+		case "LPUSH":
+			for _, v := range args[1:] {
+				list = append([][]byte{append([]byte{}, v...)}, list...)
+			}
+		case "RPUSH":
+			for _, v := range args[1:] {
+				list = append(list, append([]byte{}, v...))
+			}
+		case "LPOP", "RPOP":
+			if len(list) == 0 {
+				return nil, nil
+			}
+			var v []byte
+			if command == "LPOP" {
+				v, list = list[0], list[1:]
+			} else {
+				v, list = list[len(list)-1], list[:len(list)-1]
+			}
+			if e.value = list; len(list) == 0 {
+				delete(r.data, string(args[0]))
+			}
+			return v, nil
+		case "LLEN":
+			return int64(len(list)), nil
+		case "LRANGE":
+			start, err1 := strconv.Atoi(string(args[1]))
+			stop, err2 := strconv.Atoi(string(args[2]))
+			if err1 != nil || err2 != nil {
+				return nil, errRedisNotInt
+			}
+			if start < 0 {
+				start = max(len(list)+start, 0)
+			}
+			if stop < 0 {
+				stop = len(list) + stop
+			}
+			reply := []any{}
+			for i := start; i <= stop && i < len(list); i++ {
+				reply = append(reply, list[i])
+			}
+			return reply, nil
+		}
+		e.value = list
+		return int64(len(list)), nil
+	}
+
+	return nil, fmt.Errorf("ERR unknown command '%s' (not supported by testkit redis)", strings.ToLower(command))
+}
+
+// redisGlob convert redis glob-style pattern to regexp
+// keys sorted keys matched with glob pattern, must be called with lock held
+func (r *Redis) keys(pattern string) []any {
+	expr := redisGlob(pattern)
+	var keys []string
+	for key := range r.data {
+		if r.get(key) != nil && expr.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	reply := make([]any, len(keys))
+	for i, key := range keys {
+		reply[i] = []byte(key)
+	}
+	return reply
+}
+
+func redisGlob(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			if end := strings.IndexByte(pattern[i:], ']'); end > 0 {
+				expr.WriteString(pattern[i : i+end+1])
+				i += end
+				continue
+			}
+			expr.WriteString(`\[`)
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(pattern) + "$")
+	}
+	return re
+}
+
+// redisConn redigo connection to fake redis
+type redisConn struct {
+	r       *Redis
+	pending []redisReply
+	replies []redisReply
+	closed  bool
+}
+
+type redisReply struct {
+	value any
+	err   error
+}
+
+func (c *redisConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *redisConn) Err() error {
+	if c.closed {
+		return errors.New("redigo: closed")
+	}
+	return nil
+}
+
+func (c *redisConn) Do(command string, args ...any) (reply any, err error) {
+	if command != "" {
+		if err := c.Send(command, args...); err != nil {
+			return nil, err
+		}
+	}
+	c.Flush()
+	for len(c.replies) > 0 {
+		reply, err = c.Receive()
+	}
+	return reply, err
+}
+
+func (c *redisConn) Send(command string, args ...any) error {
+	if c.closed {
+		return errors.New("redigo: closed")
+	}
+	reply, err := c.r.do(command, redisArgs(args))
+	if err != nil {
+		err = redis.Error(err.Error())
+	}
+	c.pending = append(c.pending, redisReply{value: reply, err: err})
+	return nil
+}
+
+func (c *redisConn) Flush() error {
+	c.replies = append(c.replies, c.pending...)
+	c.pending = nil
+	return nil
+}
+
+func (c *redisConn) Receive() (reply any, err error) {
+	if len(c.replies) == 0 {
+		return nil, errors.New("testkit redis: no pending reply (pub/sub is not supported)")
+	}
+	r := c.replies[0]
+	c.replies = c.replies[1:]
+	return r.value, r.err
+}
+
+// redisArgs encode command arguments like redigo
+func redisArgs(args []any) [][]byte {
+	result := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case []byte:
+			result = append(result, v)
+		case string:
+			result = append(result, []byte(v))
+		case int:
+			result = append(result, strconv.AppendInt(nil, int64(v), 10))
+		case int64:
+			result = append(result, strconv.AppendInt(nil, v, 10))
+		case float64:
+			result = append(result, strconv.AppendFloat(nil, v, 'g', -1, 64))
+		case bool:
+			if v {
+				result = append(result, []byte("1"))
+			} else {
+				result = append(result, []byte("0"))
+			}
+		case nil:
+			result = append(result, []byte{})
+		case redis.Argument:
+			result = append(result, []byte(fmt.Sprint(v.RedisArg())))
+		default:
+			result = append(result, []byte(fmt.Sprint(v)))
+		}
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	restserver "github.com/golangid/candi/codebase/app/rest_server"
+	"github.com/golangid/candi/codebase/factory"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/i18n"
+)
+
+// RESTHarness invoke REST handlers with same router and middleware chain as REST server
+type RESTHarness struct {
+	mux chi.Router
+}
+
+// NewRESTHarness mount REST handlers (with middlewares in handler Mount) in test router, root middlewares
+// (tracer and i18n like REST server) executed before given additional root middlewares
+func NewRESTHarness(handlers []interfaces.RESTHandler, rootMiddlewares ...func(http.Handler) http.Handler) *RESTHarness {
+	mux := chi.NewRouter()
+	mux.Use(i18n.HTTPMiddleware, factory.HTTPMiddlewareScope, restserver.HTTPMiddlewareTracer())
+	mux.Use(rootMiddlewares...)
+	router := restserver.NewRouter(mux.Route("/", func(chi.Router) {}))
+	for _, h := range handlers {
+		h.Mount(router)
+	}
+	return &RESTHarness{mux: mux}
+}
+
+// ServeHTTP implement http.Handler, for use with httptest.NewServer
+func (h *RESTHarness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+// Do invoke request, body can be nil, []byte, string, io.Reader, or value encoded to json.
+// Headers are "key", "value" pairs
+func (h *RESTHarness) Do(method, target string, body any, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	case string:
+		reader = strings.NewReader(b)
+	case io.Reader:
+		reader = b
+	default:
+		payload, err := json.Marshal(b)
+		if err != nil {
+			panic("testkit: encode request body: " + err.Error())
+		}
+		reader, isJSON = bytes.NewReader(payload), true
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.mux.ServeHTTP(rec, req)
+	return rec
+}
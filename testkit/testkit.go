@@ -0,0 +1,12 @@
+// Package testkit in-memory implementations (broker, redis, locker) and harnesses for invoke REST, gRPC,
+// and worker handlers with full middleware chain in unit test, without running server or external dependency
+package testkit
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
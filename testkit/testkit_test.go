@@ -0,0 +1,194 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/candiutils"
+	"github.com/golangid/candi/codebase/app/rest_server"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+	"github.com/golangid/candi/wrapper"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	r := NewRedis()
+	cache := r.Cache()
+
+	assert.NoError(t, cache.Set(ctx, "user:1", "agung", time.Minute))
+	val, err := cache.Get(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "agung", string(val))
+	ttl, err := cache.GetTTL(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+	keys, err := cache.GetKeys(ctx, "user:")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, keys)
+
+	r.Advance(2 * time.Minute)
+	exists, err := cache.Exists(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	conn := r.WritePool().Get()
+	defer conn.Close()
+	n, err := redis.Int64(conn.Do("INCRBY", "counter", 5))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	_, err = conn.Do("HSET", "hash", "field", "value")
+	assert.NoError(t, err)
+	hash, err := redis.StringMap(conn.Do("HGETALL", "hash"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"field": "value"}, hash)
+	_, err = conn.Do("GET", "hash")
+	assert.Error(t, err)
+
+	locker := candiutils.NewRedisLocker(r.WritePool())
+	assert.False(t, locker.IsLocked("job"))
+	assert.True(t, locker.IsLocked("job"))
+	locker.Unlock("job")
+	assert.False(t, locker.HasBeenLocked("job"))
+}
+
+func TestBroker(t *testing.T) {
+	ctx := context.Background()
+	broker := NewBroker(types.Kafka)
+	var publisher interfaces.Publisher = broker.GetPublisher()
+
+	assert.NoError(t, publisher.PublishMessage(ctx, &candishared.PublisherArgument{Topic: "order-created", Message: []byte(`{"id":1}`)}))
+	assert.NoError(t, publisher.PublishMessage(ctx, &candishared.PublisherArgument{Topic: "email", Message: []byte(`hello`)}))
+	assert.Len(t, broker.Messages(), 2)
+	assert.Equal(t, `{"id":1}`, string(broker.Messages("order-created")[0].Message))
+
+	broker.SetPublishError(errors.New("broker down"))
+	assert.Error(t, publisher.PublishMessage(ctx, &candishared.PublisherArgument{Topic: "email", Message: []byte(`hello`)}))
+	broker.Reset()
+	assert.Empty(t, broker.Messages())
+}
+
+func TestLocker(t *testing.T) {
+	locker := NewLocker()
+	unlock, err := locker.Lock("key", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, locker.HasBeenLocked("key"))
+
+	_, err = locker.Lock("key", 50*time.Millisecond)
+	assert.Error(t, err)
+
+	unlock()
+	assert.False(t, locker.IsLockedTTL("key", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, locker.HasBeenLocked("key"))
+}
+
+type restHandler struct{}
+
+func (restHandler) Mount(root interfaces.RESTRouter) {
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer token" {
+				wrapper.NewHTTPResponse(http.StatusUnauthorized, "Unauthorized").JSON(w)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	v1 := root.Group("/v1", auth)
+	v1.GET("/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", map[string]string{"id": restserver.URLParam(req, "id")}).JSON(w)
+	})
+}
+
+func TestRESTHarness(t *testing.T) {
+	h := NewRESTHarness([]interfaces.RESTHandler{restHandler{}})
+
+	rec := h.Do(http.MethodGet, "/v1/users/10", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = h.Do(http.MethodGet, "/v1/users/10", nil, "Authorization", "Bearer token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":"10"`)
+}
+
+type grpcHandler struct{}
+
+func (grpcHandler) Register(server *grpc.Server, mw *types.MiddlewareGroup) {
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	mw.Add("/grpc.health.v1.Health/Check", func(ctx context.Context) (context.Context, error) {
+		if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("authorization")) == 0 {
+			return ctx, status.Error(codes.Unauthenticated, "missing authorization")
+		}
+		return ctx, nil
+	})
+}
+
+func TestGRPCHarness(t *testing.T) {
+	h, err := NewGRPCHarness([]interfaces.GRPCHandler{grpcHandler{}})
+	assert.NoError(t, err)
+	defer h.Close()
+
+	client := healthpb.NewHealthClient(h.Conn())
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+type workerHandler struct {
+	publisher interfaces.Publisher
+}
+
+func (h workerHandler) MountHandlers(group *types.WorkerHandlerGroup) {
+	types.AddTypedHandler(group, "order-created", func(ctx context.Context, payload struct{ ID int }) error {
+		if payload.ID == 0 {
+			return errors.New("invalid id")
+		}
+		return h.publisher.PublishMessage(ctx, &candishared.PublisherArgument{Topic: "send-invoice", Message: []byte("invoice")})
+	})
+}
+
+func TestWorkerHarness(t *testing.T) {
+	broker := NewBroker(types.Kafka)
+	h := NewWorkerHarness(types.Kafka, workerHandler{publisher: broker})
+
+	eventContext := NewEventContext().WithHandlerRoute("order-created").WithJSON(map[string]int{"ID": 1}).WithKey("1").Build()
+	_, err := h.InvokeEvent(eventContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", eventContext.Key())
+	assert.Len(t, broker.Messages("send-invoice"), 1)
+
+	_, err = h.Invoke(context.Background(), "order-created", []byte(`{"ID":0}`))
+	assert.Error(t, err)
+	_, err = h.Invoke(context.Background(), "unknown", nil)
+	assert.Error(t, err)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(90 * time.Second)
+	assert.Equal(t, 90*time.Second, clock.Since(start))
+	assert.Equal(t, 30*time.Second, clock.Until(start.Add(2*time.Minute)))
+
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+}
@@ -0,0 +1,68 @@
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/codebase/interfaces"
+)
+
+// WorkerHarness invoke worker handlers (main handler and after handlers) like worker engine
+type WorkerHarness struct {
+	workerType types.Worker
+	handlers   map[string]types.WorkerHandler
+}
+
+// NewWorkerHarness mount worker handlers of worker type
+func NewWorkerHarness(workerType types.Worker, handlers ...interfaces.WorkerHandler) *WorkerHarness {
+	h := &WorkerHarness{workerType: workerType, handlers: make(map[string]types.WorkerHandler)}
+	for _, handler := range handlers {
+		var group types.WorkerHandlerGroup
+		handler.MountHandlers(&group)
+		for _, wh := range group.Handlers {
+			h.handlers[wh.Pattern] = wh
+			// cron job pattern is json key, also register by job name
+			var cronKey struct {
+				JobName string `json:"jobName"`
+			}
+			if json.Unmarshal([]byte(wh.Pattern), &cronKey) == nil && cronKey.JobName != "" {
+				h.handlers[cronKey.JobName] = wh
+			}
+		}
+	}
+	return h
+}
+
+// Patterns registered handler patterns
+func (h *WorkerHarness) Patterns() (patterns []string) {
+	for pattern := range h.handlers {
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// Invoke run handler with pattern (topic, task name, cron job name, ...) and message,
+// return event context (contains result) and error of last failed handler func
+func (h *WorkerHarness) Invoke(ctx context.Context, pattern string, message []byte) (*candishared.EventContext, error) {
+	return h.InvokeEvent(NewEventContext().WithContext(ctx).WithHandlerRoute(pattern).WithMessage(message).Build())
+}
+
+// InvokeEvent run handler with event context from EventContextBuilder, handler route is the pattern
+func (h *WorkerHarness) InvokeEvent(eventContext *candishared.EventContext) (*candishared.EventContext, error) {
+	handler, ok := h.handlers[eventContext.HandlerRoute()]
+	if !ok {
+		return eventContext, fmt.Errorf("testkit: handler %q is not registered in %s worker", eventContext.HandlerRoute(), h.workerType)
+	}
+	eventContext.SetWorkerType(string(h.workerType))
+
+	var err error
+	for _, handlerFunc := range handler.HandlerFuncs {
+		if err = handlerFunc(eventContext); err != nil {
+			eventContext.SetError(err)
+		}
+	}
+	return eventContext, eventContext.Err()
+}
@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/golangid/candi/candihelper"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ValidationError error from struct or json schema validator, contains list of invalid field.
+// Implement candihelper.MultiError, and automatically formatted as bad request in
+// REST (wrapper.NewHTTPResponseFromError), GraphQL (error extensions), and gRPC (status InvalidArgument)
+type ValidationError struct {
+	candihelper.MultiError
+}
+
+// NewValidationError constructor
+func NewValidationError() *ValidationError {
+	return &ValidationError{MultiError: candihelper.NewMultiError()}
+}
+
+// HTTPStatusCode http status code for validation error
+func (v *ValidationError) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Extensions implement graphql error extensions
+func (v *ValidationError) Extensions() map[string]any {
+	return map[string]any{
+		"code":   http.StatusBadRequest,
+		"errors": v.ToMap(),
+	}
+}
+
+// GRPCStatus implement grpc status error, with field violations in error details
+func (v *ValidationError) GRPCStatus() *status.Status {
+	errs := v.ToMap()
+	fields := candihelper.ToKeyMapSlice(errs)
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field: field, Description: errs[field],
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, v.Error())
+	if withDetails, err := st.WithDetails(badRequest); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// IsValidationError check if given error is validation error
+func IsValidationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
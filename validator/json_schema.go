@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/golangid/candi/candihelper"
@@ -29,10 +30,26 @@ func AddHideErrorListTypeJSONSchemaValidatorOption(descType ...string) JSONSchem
 	}
 }
 
+// AddFormatJSONSchemaValidatorOption option func, register custom format (example: "phone-id", "nik")
+func AddFormatJSONSchemaValidatorOption(name string, checker FormatCheckerFunc) JSONSchemaValidatorOptionFunc {
+	return func(v *JSONSchemaValidator) {
+		RegisterFormat(name, checker)
+	}
+}
+
+// AddKeywordJSONSchemaValidatorOption option func, register custom keyword validation,
+// can be used for cross-field validation using sibling field from KeywordContext.Parent
+func AddKeywordJSONSchemaValidatorOption(keyword string, validateFunc KeywordValidateFunc) JSONSchemaValidatorOptionFunc {
+	return func(v *JSONSchemaValidator) {
+		v.keywords[keyword] = validateFunc
+	}
+}
+
 // JSONSchemaValidator validator
 type JSONSchemaValidator struct {
 	SchemaStorage        Storage
 	notShowErrorListType map[string]struct{}
+	keywords             map[string]KeywordValidateFunc
 }
 
 // NewJSONSchemaValidator constructor
@@ -42,6 +59,7 @@ func NewJSONSchemaValidator(opts ...JSONSchemaValidatorOptionFunc) *JSONSchemaVa
 		notShowErrorListType: map[string]struct{}{
 			"condition_else": {}, "condition_then": {},
 		},
+		keywords: make(map[string]KeywordValidateFunc),
 	}
 
 	// overide with custom option
@@ -51,6 +69,34 @@ func NewJSONSchemaValidator(opts ...JSONSchemaValidatorOptionFunc) *JSONSchemaVa
 	return v
 }
 
+// CheckSchemas compile all schema in storage (storage must implement `SchemaIDs() []string`),
+// return error for invalid schema
+func (v *JSONSchemaValidator) CheckSchemas() error {
+	lister, ok := v.SchemaStorage.(interface{ SchemaIDs() []string })
+	if !ok {
+		return nil
+	}
+
+	schemaIDs := lister.SchemaIDs()
+	sort.Strings(schemaIDs)
+	multiErr := candihelper.NewMultiError()
+	for _, schemaID := range schemaIDs {
+		s, err := v.SchemaStorage.Get(schemaID)
+		if err != nil {
+			multiErr.Append(schemaID, err)
+			continue
+		}
+		s = strings.ReplaceAll(s, "{{WORKDIR}}", os.Getenv(candihelper.WORKDIR))
+		if _, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(s)); err != nil {
+			multiErr.Append(schemaID, err)
+		}
+	}
+	if multiErr.HasError() {
+		return multiErr
+	}
+	return nil
+}
+
 // ValidateDocument based on schema id
 func (v *JSONSchemaValidator) ValidateDocument(schemaSource string, documentSource any) error {
 	s, err := v.SchemaStorage.Get(schemaSource)
@@ -64,14 +110,14 @@ func (v *JSONSchemaValidator) ValidateDocument(schemaSource string, documentSour
 		return err
 	}
 
-	document := gojsonschema.NewBytesLoader(candihelper.ToBytes(documentSource))
-	result, err := schema.Validate(document)
+	documentBytes := candihelper.ToBytes(documentSource)
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(documentBytes))
 	if err != nil {
 		return err
 	}
 
+	validationErr := NewValidationError()
 	if !result.Valid() {
-		multiError := candihelper.NewMultiError()
 		for _, desc := range result.Errors() {
 			if _, ok := v.notShowErrorListType[desc.Type()]; ok {
 				continue
@@ -81,12 +127,18 @@ func (v *JSONSchemaValidator) ValidateDocument(schemaSource string, documentSour
 				field = fmt.Sprintf("%s.%s", field, desc.Details()["property"])
 				field = strings.TrimPrefix(field, "(root).")
 			}
-			multiError.Append(field, errors.New(desc.Description()))
+			validationErr.Append(field, errors.New(desc.Description()))
 		}
-		if multiError.HasError() {
-			return multiError
+	}
+
+	if len(v.keywords) > 0 {
+		if err := v.validateKeywords(schemaSource, documentBytes, validationErr); err != nil {
+			return err
 		}
 	}
 
+	if validationErr.HasError() {
+		return validationErr
+	}
 	return nil
 }
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/golangid/gojsonschema"
+)
+
+// FormatCheckerFunc custom json schema format checker, example for validate `"format": "phone-id"`
+type FormatCheckerFunc func(input any) bool
+
+// IsFormat implement gojsonschema.FormatChecker
+func (f FormatCheckerFunc) IsFormat(input any) bool {
+	return f(input)
+}
+
+// RegisterFormat register custom json schema format, format checker is global for all json schema validator
+// and must be registered at startup before validate document
+func RegisterFormat(name string, checker FormatCheckerFunc) {
+	gojsonschema.FormatCheckers.Add(name, checker)
+}
+
+// KeywordContext context for custom keyword validation
+type KeywordContext struct {
+	// Field path of validated value, example: "address.city" or "items.0.qty"
+	Field string
+	// KeywordValue value of keyword in schema, example `"gteField": "start_date"` is "start_date"
+	KeywordValue any
+	// Value document value in field, nil if field not exist
+	Value any
+	// Parent object containing the field, can be used for cross-field validation (sibling field)
+	Parent map[string]any
+	// Root document
+	Root any
+}
+
+// KeywordValidateFunc custom keyword validation func, return error if value is invalid
+type KeywordValidateFunc func(KeywordContext) error
+
+// validateKeywords walk schema properties & items and validate document value for every registered custom keyword
+func (v *JSONSchemaValidator) validateKeywords(schemaSource string, documentSource []byte, validationErr *ValidationError) error {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(schemaSource), &schema); err != nil {
+		return err
+	}
+	var document any
+	if err := json.Unmarshal(documentSource, &document); err != nil {
+		return err
+	}
+
+	v.walkSchemaKeywords(schema, KeywordContext{Value: document, Root: document}, validationErr)
+	return nil
+}
+
+func (v *JSONSchemaValidator) walkSchemaKeywords(schema map[string]any, kc KeywordContext, validationErr *ValidationError) {
+	for keyword, validateFunc := range v.keywords {
+		keywordValue, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+		kc.KeywordValue = keywordValue
+		if err := validateFunc(kc); err != nil {
+			field := kc.Field
+			if field == "" {
+				field = "(root)"
+			}
+			validationErr.Append(field, err)
+		}
+	}
+
+	switch value := kc.Value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for name, propSchema := range properties {
+			propSchemaMap, ok := propSchema.(map[string]any)
+			if !ok {
+				continue
+			}
+			v.walkSchemaKeywords(propSchemaMap, KeywordContext{
+				Field: joinFieldPath(kc.Field, name), Value: value[name], Parent: value, Root: kc.Root,
+			}, validationErr)
+		}
+
+	case []any:
+		itemSchema, ok := schema["items"].(map[string]any)
+		if !ok {
+			return
+		}
+		for i, item := range value {
+			v.walkSchemaKeywords(itemSchema, KeywordContext{
+				Field: joinFieldPath(kc.Field, strconv.Itoa(i)), Value: item, Parent: kc.Parent, Root: kc.Root,
+			}, validationErr)
+		}
+	}
+}
+
+func joinFieldPath(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
@@ -1,14 +1,22 @@
 package validator
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/logger"
 )
 
 // Storage abstraction
@@ -89,39 +97,20 @@ func (l *fileLocalStorage) Store(schemaID string, schema string) error {
 	return nil
 }
 
+func (l *fileLocalStorage) SchemaIDs() []string {
+	return candihelper.ToKeyMapSlice(l.kv)
+}
+
 // NewInMemStorage constructor
 func NewInMemStorage(schemaLocationDir string) Storage {
 	inMem := &inMemStorage{
 		storage: make(map[string]string),
 	}
 
-	filepath.Walk(schemaLocationDir, func(p string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-
-		fileName := info.Name()
-		if strings.HasSuffix(fileName, ".json") {
-			s, err := os.ReadFile(p)
-			if err != nil {
-				return fmt.Errorf("%s: %v", fileName, err)
-			}
-
-			var data map[string]any
-			if err := json.Unmarshal(s, &data); err != nil {
-				return fmt.Errorf("%s: %v", fileName, err)
-			}
-			id, ok := data["$id"].(string)
-			if !ok {
-				id = strings.Trim(strings.TrimSuffix(strings.TrimPrefix(p, schemaLocationDir), ".json"), "/") // take filename without extension
-			}
-			inMem.Store(id, string(s))
-		}
-		return nil
-	})
+	schemas, _ := loadSchemaDir(schemaLocationDir)
+	for id, schema := range schemas {
+		inMem.Store(id, schema)
+	}
 
 	return inMem
 }
@@ -139,6 +128,10 @@ func (i *inMemStorage) Store(schemaID string, schema string) error {
 	return nil
 }
 
+func (i *inMemStorage) SchemaIDs() []string {
+	return candihelper.ToKeyMapSlice(i.storage)
+}
+
 // NewFileSystemStorage constructor
 func NewFileSystemStorage(fileSystem fs.FS, rootPath string) Storage {
 	storage := &fsStorage{
@@ -191,3 +184,196 @@ func (i *fsStorage) Get(schemaID string) (string, error) {
 func (i *fsStorage) Store(schemaID string, schema string) error {
 	return nil
 }
+
+func (i *fsStorage) SchemaIDs() []string {
+	return candihelper.ToKeyMapSlice(i.sourceMap)
+}
+
+// SchemaLoader load all json schema source, map key is schema id
+type SchemaLoader func(ctx context.Context) (map[string]string, error)
+
+// ReloadableStorage in memory json schema storage with hot-reload from schema loader
+// (directory or remote url), can be used with SetSchemaStorageJSONSchemaValidatorOption
+type ReloadableStorage struct {
+	mu      sync.RWMutex
+	storage map[string]string
+	loader  SchemaLoader
+}
+
+// NewReloadableStorage constructor, load all schema from loader at first time
+func NewReloadableStorage(ctx context.Context, loader SchemaLoader) (*ReloadableStorage, error) {
+	r := &ReloadableStorage{
+		storage: make(map[string]string),
+		loader:  loader,
+	}
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Get method
+func (r *ReloadableStorage) Get(schemaID string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.storage[schemaID]
+	if !ok {
+		return "", fmt.Errorf("schema '%s' not found", schemaID)
+	}
+	return schema, nil
+}
+
+// Store method
+func (r *ReloadableStorage) Store(schemaID string, schema string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storage[schemaID] = schema
+	return nil
+}
+
+// SchemaIDs get all schema id
+func (r *ReloadableStorage) SchemaIDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return candihelper.ToKeyMapSlice(r.storage)
+}
+
+// Reload replace all schema with latest source from loader, existing schema will be kept if loader return error
+func (r *ReloadableStorage) Reload(ctx context.Context) error {
+	schemas, err := r.loader(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.storage = schemas
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch reload schema periodically in background until context is done
+func (r *ReloadableStorage) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Reload(ctx); err != nil {
+					logger.LogEf("json schema: reload: %s", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// DirectorySchemaLoader load json schema from local directory
+func DirectorySchemaLoader(schemaLocationDir string) SchemaLoader {
+	return func(ctx context.Context) (map[string]string, error) {
+		return loadSchemaDir(schemaLocationDir)
+	}
+}
+
+// RemoteSchemaLoader load json schema from remote url, response body can be single schema (with "$id"),
+// array of schema (with "$id"), or object with schema id as key and schema as value
+func RemoteSchemaLoader(url string, headers map[string]string) SchemaLoader {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	return func(ctx context.Context) (map[string]string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("remote schema %s: unexpected status %s", url, resp.Status)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return parseRemoteSchema(body)
+	}
+}
+
+func parseRemoteSchema(body []byte) (map[string]string, error) {
+	schemas := make(map[string]string)
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(body, &list); err == nil {
+		for i, raw := range list {
+			var data map[string]any
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return nil, fmt.Errorf("schema index %d: %v", i, err)
+			}
+			id, ok := data["$id"].(string)
+			if !ok {
+				return nil, fmt.Errorf("schema index %d: missing $id", i)
+			}
+			schemas[id] = string(raw)
+		}
+		return schemas, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+	if rawID, ok := object["$id"]; ok {
+		var id string
+		if err := json.Unmarshal(rawID, &id); err != nil {
+			return nil, fmt.Errorf("invalid $id: %v", err)
+		}
+		schemas[id] = string(body)
+		return schemas, nil
+	}
+	for id, raw := range object {
+		schemas[id] = string(raw)
+	}
+	return schemas, nil
+}
+
+func loadSchemaDir(schemaLocationDir string) (map[string]string, error) {
+	schemas := make(map[string]string)
+	err := filepath.Walk(schemaLocationDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		fileName := info.Name()
+		if strings.HasSuffix(fileName, ".json") {
+			s, err := os.ReadFile(p)
+			if err != nil {
+				return fmt.Errorf("%s: %v", fileName, err)
+			}
+
+			var data map[string]any
+			if err := json.Unmarshal(s, &data); err != nil {
+				return fmt.Errorf("%s: %v", fileName, err)
+			}
+			id, ok := data["$id"].(string)
+			if !ok {
+				id = strings.Trim(strings.TrimSuffix(strings.TrimPrefix(p, schemaLocationDir), ".json"), "/") // take filename without extension
+			}
+			schemas[id] = string(s)
+		}
+		return nil
+	})
+	return schemas, err
+}
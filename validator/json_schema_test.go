@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangid/candi/candihelper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchemaValidatorCustomRule(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "booking.json"), []byte(`{
+		"$id": "booking",
+		"type": "object",
+		"properties": {
+			"phone": {"type": "string", "format": "phone-id"},
+			"start_date": {"type": "string"},
+			"end_date": {"type": "string", "gteField": "start_date"}
+		}
+	}`), 0644))
+
+	storage, err := NewReloadableStorage(context.Background(), DirectorySchemaLoader(dir))
+	assert.NoError(t, err)
+
+	v := NewJSONSchemaValidator(
+		SetSchemaStorageJSONSchemaValidatorOption(storage),
+		AddFormatJSONSchemaValidatorOption("phone-id", func(input any) bool {
+			s, ok := input.(string)
+			return !ok || strings.HasPrefix(s, "+62")
+		}),
+		AddKeywordJSONSchemaValidatorOption("gteField", func(kc KeywordContext) error {
+			field, _ := kc.KeywordValue.(string)
+			value, _ := kc.Value.(string)
+			if value < kc.Parent[field].(string) {
+				return errors.New("must be greater than or equal to " + field)
+			}
+			return nil
+		}),
+	)
+
+	err = v.ValidateDocument("booking", `{"phone": "0812", "start_date": "2024-02-01", "end_date": "2024-01-01"}`)
+	assert.True(t, IsValidationError(err))
+	errs := err.(candihelper.MultiError).ToMap()
+	assert.Contains(t, errs, "phone")
+	assert.Equal(t, "must be greater than or equal to start_date", errs["end_date"])
+
+	assert.NoError(t, v.ValidateDocument("booking", `{"phone": "+62812", "start_date": "2024-01-01", "end_date": "2024-01-01"}`))
+
+	// hot reload schema
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "booking.json"), []byte(`{
+		"$id": "booking", "type": "object", "required": ["phone"]
+	}`), 0644))
+	assert.NoError(t, storage.Reload(context.Background()))
+	assert.True(t, IsValidationError(v.ValidateDocument("booking", `{}`)))
+}
@@ -1,11 +1,16 @@
 package validator
 
 import (
+	"context"
 	"errors"
+	"reflect"
 	"strings"
 
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
 	validatorengine "github.com/go-playground/validator/v10"
-	"github.com/golangid/candi/candihelper"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/golangid/candi/i18n"
 )
 
 // StructValidatorOptionFunc type
@@ -15,6 +20,7 @@ type StructValidatorOptionFunc func(*StructValidator)
 func SetCoreStructValidatorOption(additionalConfigFunc ...func(*validatorengine.Validate)) StructValidatorOptionFunc {
 	return func(v *StructValidator) {
 		ve := validatorengine.New()
+		ve.RegisterTagNameFunc(jsonTagName)
 		for _, additionalFunc := range additionalConfigFunc {
 			additionalFunc(ve)
 		}
@@ -22,9 +28,36 @@ func SetCoreStructValidatorOption(additionalConfigFunc ...func(*validatorengine.
 	}
 }
 
+// SetTranslatorStructValidatorOption option func, set translator for validation error message
+// with register translation func (example: github.com/go-playground/validator/v10/translations/id)
+func SetTranslatorStructValidatorOption(trans ut.Translator, registerTranslation func(*validatorengine.Validate, ut.Translator) error) StructValidatorOptionFunc {
+	return func(v *StructValidator) {
+		v.Translator = trans
+		v.registerTranslation = registerTranslation
+	}
+}
+
+// AddLocaleStructValidatorOption option func, add translator for other locale, translator will be selected
+// based on locale from context in ValidateStructContext (example: github.com/go-playground/validator/v10/translations/id)
+func AddLocaleStructValidatorOption(trans ut.Translator, registerTranslation func(*validatorengine.Validate, ut.Translator) error) StructValidatorOptionFunc {
+	return func(v *StructValidator) {
+		v.localeTranslators = append(v.localeTranslators, localeTranslator{trans: trans, register: registerTranslation})
+	}
+}
+
 // StructValidator struct
 type StructValidator struct {
-	Validator *validatorengine.Validate
+	Validator  *validatorengine.Validate
+	Translator ut.Translator
+
+	registerTranslation func(*validatorengine.Validate, ut.Translator) error
+	localeTranslators   []localeTranslator
+	translators         map[string]ut.Translator
+}
+
+type localeTranslator struct {
+	trans    ut.Translator
+	register func(*validatorengine.Validate, ut.Translator) error
 }
 
 // NewStructValidator using go library
@@ -40,6 +73,23 @@ func NewStructValidator(opts ...StructValidatorOptionFunc) *StructValidator {
 
 	if sv.Validator == nil {
 		sv.Validator = validatorengine.New()
+		sv.Validator.RegisterTagNameFunc(jsonTagName)
+	}
+
+	// default translator using english
+	if sv.Translator == nil {
+		enLocale := en.New()
+		sv.Translator, _ = ut.New(enLocale, enLocale).GetTranslator(enLocale.Locale())
+		sv.registerTranslation = entranslations.RegisterDefaultTranslations
+	}
+	sv.translators = make(map[string]ut.Translator)
+	for _, lt := range append([]localeTranslator{{trans: sv.Translator, register: sv.registerTranslation}}, sv.localeTranslators...) {
+		if lt.register != nil {
+			if err := lt.register(sv.Validator, lt.trans); err != nil {
+				panic("struct validator: register translation: " + err.Error())
+			}
+		}
+		sv.translators[i18n.NormalizeLocale(lt.trans.Locale())] = lt.trans
 	}
 
 	return sv
@@ -47,16 +97,36 @@ func NewStructValidator(opts ...StructValidatorOptionFunc) *StructValidator {
 
 // ValidateStruct function
 func (v *StructValidator) ValidateStruct(data any) error {
+	return v.validateStruct(data, v.Translator)
+}
+
+// ValidateStructContext validate struct with error message translated to locale from context (see i18n.Locale)
+func (v *StructValidator) ValidateStructContext(ctx context.Context, data any) error {
+	return v.validateStruct(data, v.translator(i18n.Locale(ctx)))
+}
+
+func (v *StructValidator) translator(locale string) ut.Translator {
+	if trans, ok := v.translators[locale]; ok {
+		return trans
+	}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		if trans, ok := v.translators[base]; ok {
+			return trans
+		}
+	}
+	return v.Translator
+}
+
+func (v *StructValidator) validateStruct(data any, trans ut.Translator) error {
 	if err := v.Validator.Struct(data); err != nil {
 		switch errs := err.(type) {
 		case validatorengine.ValidationErrors:
-			multiError := candihelper.NewMultiError()
+			validationErr := NewValidationError()
 			for _, e := range errs {
-				message := err.Error()
-				multiError.Append(strings.ToLower(e.Field()), errors.New(message))
+				validationErr.Append(fieldPath(e), errors.New(e.Translate(trans)))
 			}
-			if multiError.HasError() {
-				return multiError
+			if validationErr.HasError() {
+				return validationErr
 			}
 		default:
 			return err
@@ -65,3 +135,23 @@ func (v *StructValidator) ValidateStruct(data any) error {
 
 	return nil
 }
+
+// jsonTagName use json tag as field name in validation error, fallback to lower case struct field name
+func jsonTagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// fieldPath return field namespace without root struct name, example: "address.city"
+func fieldPath(e validatorengine.FieldError) string {
+	if _, path, ok := strings.Cut(e.Namespace(), "."); ok {
+		return path
+	}
+	return e.Field()
+}
@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	idtranslations "github.com/go-playground/validator/v10/translations/id"
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/i18n"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStructValidator(t *testing.T) {
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type Payload struct {
+		Name    string  `json:"name" validate:"required"`
+		Email   string  `json:"email" validate:"required,email"`
+		Age     int     `validate:"gte=17"`
+		Address Address `json:"address"`
+	}
+
+	v := NewStructValidator()
+	err := v.ValidateStruct(Payload{Name: "agung", Email: "invalid", Age: 10})
+	assert.Error(t, err)
+	assert.True(t, IsValidationError(err))
+
+	multiErr, ok := err.(candihelper.MultiError)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{
+		"email":        "email must be a valid email address",
+		"age":          "age must be 17 or greater",
+		"address.city": "city is a required field",
+	}, multiErr.ToMap())
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	assert.NoError(t, v.ValidateStruct(Payload{Name: "agung", Email: "agung@mail.com", Age: 17, Address: Address{City: "Jakarta"}}))
+}
+
+func TestStructValidatorContext(t *testing.T) {
+	idLocale := id.New()
+	idTrans, _ := ut.New(idLocale, idLocale).GetTranslator(idLocale.Locale())
+
+	v := NewStructValidator(AddLocaleStructValidatorOption(idTrans, idtranslations.RegisterDefaultTranslations))
+	payload := struct {
+		Name string `json:"name" validate:"required"`
+	}{}
+
+	err := v.ValidateStructContext(i18n.SetLocaleToContext(context.Background(), "id-ID"), payload)
+	assert.Equal(t, map[string]string{"name": "name wajib diisi"}, err.(candihelper.MultiError).ToMap())
+
+	err = v.ValidateStructContext(context.Background(), payload)
+	assert.Equal(t, map[string]string{"name": "name is a required field"}, err.(candihelper.MultiError).ToMap())
+}
@@ -1,5 +1,7 @@
 package validator
 
+import "context"
+
 // OptionFunc type
 type OptionFunc func(*Validator)
 
@@ -46,3 +48,16 @@ func (v *Validator) ValidateDocument(reference string, document any) error {
 func (v *Validator) ValidateStruct(data any) error {
 	return v.StructValidator.ValidateStruct(data)
 }
+
+// SelfTest check all json schema is valid
+func (v *Validator) SelfTest() error {
+	if v.JSONSchema == nil {
+		return nil
+	}
+	return v.JSONSchema.CheckSchemas()
+}
+
+// ValidateStructContext method, validation message translated to locale from context
+func (v *Validator) ValidateStructContext(ctx context.Context, data any) error {
+	return v.StructValidator.ValidateStructContext(ctx, data)
+}
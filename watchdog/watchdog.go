@@ -0,0 +1,214 @@
+// Package watchdog detect slow REST, gRPC and worker handler exceeding soft deadline, log structured slow event with
+// goroutine stack snapshot and emit metric while the handler is still running, for diagnose lock contention and stuck
+// database call in production
+package watchdog
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/logger"
+	"github.com/golangid/candi/tracer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+)
+
+const (
+	// KindHTTP slow event kind of REST request
+	KindHTTP = "http"
+	// KindGRPC slow event kind of gRPC call
+	KindGRPC = "grpc"
+	// KindWorker slow event kind of worker handler
+	KindWorker = "worker"
+)
+
+type (
+	// SlowEvent handler exceeding soft deadline
+	SlowEvent struct {
+		Kind      string        `json:"kind"`
+		Route     string        `json:"route"`
+		Deadline  time.Duration `json:"deadline"`
+		Elapsed   time.Duration `json:"elapsed"`
+		RequestID string        `json:"request_id,omitempty"`
+		TraceID   string        `json:"trace_id,omitempty"`
+		// GoroutineDump stack snapshot of all goroutine when deadline exceeded, empty if dump is rate limited
+		GoroutineDump string `json:"goroutine_dump,omitempty"`
+	}
+
+	// OptionFunc type
+	OptionFunc func(*Watchdog)
+
+	// Watchdog slow handler detector
+	Watchdog struct {
+		softDeadline   time.Duration
+		routeDeadlines map[string]time.Duration
+		routePatterns  []string
+		maxDumpSize    int
+		dumpInterval   time.Duration
+		alertFunc      func(ctx context.Context, event SlowEvent)
+
+		mu         sync.Mutex
+		lastDumpAt time.Time
+		counter    metric.Int64Counter
+	}
+)
+
+// SetSoftDeadline option func, handler running longer than soft deadline is reported (default 5 seconds)
+func SetSoftDeadline(softDeadline time.Duration) OptionFunc {
+	return func(w *Watchdog) {
+		w.softDeadline = softDeadline
+	}
+}
+
+// SetRouteDeadlines option func, soft deadline of route matched with glob pattern (HTTP "{method} {path}", gRPC full
+// method or worker handler route), zero for disable watchdog of matched route. Longest pattern is matched first
+func SetRouteDeadlines(routeDeadlines map[string]time.Duration) OptionFunc {
+	return func(w *Watchdog) {
+		w.routeDeadlines = routeDeadlines
+	}
+}
+
+// SetMaxDumpSize option func, max size of goroutine stack snapshot in bytes (default 64KB), zero for disable dump
+func SetMaxDumpSize(maxDumpSize int) OptionFunc {
+	return func(w *Watchdog) {
+		w.maxDumpSize = maxDumpSize
+	}
+}
+
+// SetDumpInterval option func, min interval between goroutine dump since dump stop the world (default 10 seconds)
+func SetDumpInterval(dumpInterval time.Duration) OptionFunc {
+	return func(w *Watchdog) {
+		w.dumpInterval = dumpInterval
+	}
+}
+
+// SetAlertFunc option func, called when slow handler detected (after slow event is logged)
+func SetAlertFunc(alertFunc func(ctx context.Context, event SlowEvent)) OptionFunc {
+	return func(w *Watchdog) {
+		w.alertFunc = alertFunc
+	}
+}
+
+// New create slow handler watchdog, slow event is exported as OpenTelemetry counter "candi.handler.slow"
+func New(opts ...OptionFunc) *Watchdog {
+	w := &Watchdog{
+		softDeadline: 5 * time.Second,
+		maxDumpSize:  64 << 10,
+		dumpInterval: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	for pattern := range w.routeDeadlines {
+		w.routePatterns = append(w.routePatterns, pattern)
+	}
+	sort.Slice(w.routePatterns, func(i, j int) bool { return len(w.routePatterns[i]) > len(w.routePatterns[j]) })
+	w.counter, _ = otel.GetMeterProvider().Meter("github.com/golangid/candi/watchdog").Int64Counter(
+		"candi.handler.slow", metric.WithDescription("Number of handler exceeding soft deadline"),
+	)
+	return w
+}
+
+// Watch start watching handler of route, call stop func when handler return
+func (w *Watchdog) Watch(ctx context.Context, kind, route string) (stop func()) {
+	deadline := w.deadline(route)
+	if deadline <= 0 {
+		return func() {}
+	}
+	startAt := time.Now()
+	timer := time.AfterFunc(deadline, func() {
+		w.report(ctx, SlowEvent{
+			Kind: kind, Route: route, Deadline: deadline, Elapsed: time.Since(startAt),
+			RequestID: candishared.GetRequestID(ctx), TraceID: tracer.GetTraceID(ctx),
+		})
+	})
+	return func() { timer.Stop() }
+}
+
+// HTTPMiddleware watch REST request with route "{method} {path}"
+func (w *Watchdog) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		defer w.Watch(req.Context(), KindHTTP, req.Method+" "+req.URL.Path)()
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// GRPCUnaryInterceptor watch grpc unary call with route full method
+func (w *Watchdog) GRPCUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	defer w.Watch(ctx, KindGRPC, info.FullMethod)()
+	return handler(ctx, req)
+}
+
+// GRPCStreamInterceptor watch grpc stream call with route full method
+func (w *Watchdog) GRPCStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	defer w.Watch(stream.Context(), KindGRPC, info.FullMethod)()
+	return handler(srv, stream)
+}
+
+// Wrap watch worker handler with route handler route
+func (w *Watchdog) Wrap(handlerFunc types.WorkerHandlerFunc) types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		defer w.Watch(eventContext.Context(), KindWorker, eventContext.HandlerRoute())()
+		return handlerFunc(eventContext)
+	}
+}
+
+// Apply watch main handler of all handler in group, call after all handler added in MountHandlers
+func (w *Watchdog) Apply(group *types.WorkerHandlerGroup) {
+	for i := range group.Handlers {
+		if len(group.Handlers[i].HandlerFuncs) > 0 {
+			group.Handlers[i].HandlerFuncs[0] = w.Wrap(group.Handlers[i].HandlerFuncs[0])
+		}
+	}
+}
+
+func (w *Watchdog) deadline(route string) time.Duration {
+	for _, pattern := range w.routePatterns {
+		if matched, _ := path.Match(pattern, route); matched {
+			return w.routeDeadlines[pattern]
+		}
+	}
+	return w.softDeadline
+}
+
+func (w *Watchdog) report(ctx context.Context, event SlowEvent) {
+	event.GoroutineDump = w.goroutineDump()
+	if w.counter != nil {
+		w.counter.Add(context.WithoutCancel(ctx), 1, metric.WithAttributes(
+			attribute.String("kind", event.Kind), attribute.String("route", event.Route),
+		))
+	}
+	logger.WithContext(ctx).Warnw("slow handler detected",
+		"kind", event.Kind, "route", event.Route, "deadline", event.Deadline.String(), "elapsed", event.Elapsed.String(),
+		"trace_id", event.TraceID, "goroutine_dump", event.GoroutineDump,
+	)
+	if w.alertFunc != nil {
+		w.alertFunc(ctx, event)
+	}
+}
+
+// goroutineDump stack snapshot of all goroutine, rate limited by dump interval
+func (w *Watchdog) goroutineDump() string {
+	if w.maxDumpSize <= 0 {
+		return ""
+	}
+	w.mu.Lock()
+	if !w.lastDumpAt.IsZero() && time.Since(w.lastDumpAt) < w.dumpInterval {
+		w.mu.Unlock()
+		return ""
+	}
+	w.lastDumpAt = time.Now()
+	w.mu.Unlock()
+
+	buf := make([]byte, w.maxDumpSize)
+	return string(buf[:runtime.Stack(buf, true)])
+}
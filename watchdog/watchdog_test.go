@@ -0,0 +1,62 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdog(t *testing.T) {
+	var mu sync.Mutex
+	var events []SlowEvent
+	w := New(
+		SetSoftDeadline(20*time.Millisecond),
+		SetRouteDeadlines(map[string]time.Duration{"GET /export/*": 0, "GET /*": time.Second}),
+		SetAlertFunc(func(ctx context.Context, event SlowEvent) {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		}),
+	)
+
+	slowHandler := w.HTTPMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(60 * time.Millisecond)
+	}))
+	slowHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	slowHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	slowHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/export/orders", nil))
+
+	group := &types.WorkerHandlerGroup{}
+	group.Add("fast", func(eventContext *candishared.EventContext) error { return nil })
+	group.Add("slow", func(eventContext *candishared.EventContext) error {
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	})
+	w.Apply(group)
+	for _, handler := range group.Handlers {
+		eventContext := candishared.NewEventContext(new(bytes.Buffer))
+		eventContext.SetContext(candishared.SetRequestIDToContext(context.Background(), "req-1"))
+		eventContext.SetHandlerRoute(handler.Pattern)
+		require.NoError(t, handler.HandlerFuncs[0](eventContext))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 2)
+	assert.Equal(t, KindHTTP, events[0].Kind)
+	assert.Equal(t, "POST /orders", events[0].Route)
+	assert.Contains(t, events[0].GoroutineDump, "goroutine")
+	assert.Equal(t, KindWorker, events[1].Kind)
+	assert.Equal(t, "slow", events[1].Route)
+	assert.Equal(t, "req-1", events[1].RequestID)
+	assert.Empty(t, events[1].GoroutineDump, "goroutine dump must be rate limited")
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/codebase/factory/types"
+	"github.com/golangid/candi/tracer"
+)
+
+const maxResponseBodyLog = 1024
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// TaskHandler task queue worker handler of webhook delivery, job args is delivery id.
+// Usage: group.Add(manager.TaskName(), manager.TaskHandler())
+func (m *Manager) TaskHandler() types.WorkerHandlerFunc {
+	return func(eventContext *candishared.EventContext) error {
+		return m.deliver(eventContext.Context(), string(eventContext.Message()))
+	}
+}
+
+func (m *Manager) deliver(ctx context.Context, deliveryID string) (err error) {
+	trace, ctx := tracer.StartTraceWithContext(ctx, "Webhook:Deliver")
+	defer func() { trace.Finish(tracer.FinishWithError(err)) }()
+
+	delivery, err := m.store.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return candierrors.Permanent(err)
+	}
+	if delivery.Status == StatusSuccess {
+		return nil
+	}
+	endpoint, err := m.store.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return candierrors.Permanent(err)
+	}
+	trace.SetTag("endpoint", endpoint.URL)
+	trace.SetTag("event_type", delivery.EventType)
+
+	breaker := m.breaker(endpoint.ID)
+	if wait := breaker.openFor(); wait > 0 {
+		// postpone delivery without count as attempt to endpoint
+		return &candishared.ErrorRetrier{Delay: wait, Message: "webhook: circuit open for endpoint " + endpoint.ID}
+	}
+
+	delivery.Attempts++
+	statusCode, responseBody, err := m.send(ctx, endpoint, delivery)
+	delivery.StatusCode, delivery.ResponseBody, delivery.UpdatedAt = statusCode, responseBody, time.Now()
+	delivery.Status, delivery.Error = StatusSuccess, ""
+	if err != nil {
+		delivery.Status, delivery.Error = StatusFailure, err.Error()
+		breaker.failure(m.breakerThreshold, m.breakerCooldown)
+	} else {
+		breaker.success()
+	}
+	if saveErr := m.store.SaveDelivery(ctx, delivery); saveErr != nil {
+		return candierrors.Transient(saveErr)
+	}
+
+	if err == nil {
+		return nil
+	}
+	// client error is not retried except request timeout and rate limited
+	if statusCode >= 400 && statusCode < 500 && statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests {
+		return candierrors.Permanent(err)
+	}
+	return &candishared.ErrorRetrier{Delay: m.backoff(delivery.Attempts), Message: err.Error(), NewRetryIntervalFunc: m.backoff}
+}
+
+func (m *Manager) send(ctx context.Context, endpoint *Endpoint, delivery *Delivery) (statusCode int, responseBody string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyLog))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("webhook: endpoint respond %s", resp.Status)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// backoff exponential backoff with jitter (random between half and full delay)
+func (m *Manager) backoff(attempt int) time.Duration {
+	delay := m.maxDelay
+	if attempt < 32 {
+		delay = min(m.baseDelay<<max(attempt-1, 0), m.maxDelay)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (m *Manager) breaker(endpointID string) *circuitBreaker {
+	breaker, _ := m.breakers.LoadOrStore(endpointID, &circuitBreaker{})
+	return breaker.(*circuitBreaker)
+}
+
+func (c *circuitBreaker) openFor() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Until(c.openUntil)
+}
+
+func (c *circuitBreaker) failure(threshold int, cooldown time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+	if threshold > 0 && c.failures >= threshold {
+		c.openUntil = time.Now().Add(cooldown)
+	}
+}
+
+func (c *circuitBreaker) success() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures, c.openUntil = 0, time.Time{}
+}
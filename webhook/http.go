@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/wrapper"
+)
+
+// HTTPHandler admin API of endpoints and delivery logs, mount with prefix and auth middleware, example:
+// mux.Handle("/webhook/", http.StripPrefix("/webhook", mw.HTTPBasicAuth(manager.HTTPHandler())))
+//
+//	GET    /endpoints                  list endpoints
+//	POST   /endpoints                  register endpoint
+//	DELETE /endpoints/{id}             delete endpoint
+//	GET    /deliveries                 list delivery logs (query: endpoint_id, event_type, status, page, limit)
+//	GET    /deliveries/{id}            detail delivery log
+//	POST   /deliveries/{id}/replay     replay delivery
+func (m *Manager) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /endpoints", func(w http.ResponseWriter, req *http.Request) {
+		endpoints, err := m.store.FindEndpoints(req.Context())
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusInternalServerError, "Failed get endpoints", err).JSON(w)
+			return
+		}
+		for i := range endpoints {
+			endpoints[i].Secret = ""
+		}
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", endpoints).JSON(w)
+	})
+	mux.HandleFunc("POST /endpoints", func(w http.ResponseWriter, req *http.Request) {
+		var endpoint Endpoint
+		if err := json.NewDecoder(req.Body).Decode(&endpoint); err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Failed parse request body", err).JSON(w)
+			return
+		}
+		if err := m.RegisterEndpoint(req.Context(), &endpoint); err != nil {
+			wrapper.NewHTTPResponse(http.StatusBadRequest, "Failed register endpoint", err).JSON(w)
+			return
+		}
+		wrapper.NewHTTPResponse(http.StatusCreated, "Success", endpoint).JSON(w)
+	})
+	mux.HandleFunc("DELETE /endpoints/{id}", func(w http.ResponseWriter, req *http.Request) {
+		if err := m.store.DeleteEndpoint(req.Context(), req.PathValue("id")); err != nil {
+			wrapper.NewHTTPResponse(http.StatusInternalServerError, "Failed delete endpoint", err).JSON(w)
+			return
+		}
+		wrapper.NewHTTPResponse(http.StatusOK, "Success").JSON(w)
+	})
+	mux.HandleFunc("GET /deliveries", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		filter := &DeliveryFilter{EndpointID: query.Get("endpoint_id"), EventType: query.Get("event_type"), Status: query.Get("status")}
+		filter.Page, _ = strconv.Atoi(query.Get("page"))
+		filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+		deliveries, count, err := m.store.FindDeliveries(req.Context(), filter)
+		if err != nil {
+			wrapper.NewHTTPResponse(http.StatusInternalServerError, "Failed get deliveries", err).JSON(w)
+			return
+		}
+		wrapper.NewHTTPResponseWithMeta(http.StatusOK, "Success", candishared.NewMeta(filter.Page, filter.Limit, count), deliveries).JSON(w)
+	})
+	mux.HandleFunc("GET /deliveries/{id}", func(w http.ResponseWriter, req *http.Request) {
+		delivery, err := m.store.GetDelivery(req.Context(), req.PathValue("id"))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		wrapper.NewHTTPResponse(http.StatusOK, "Success", delivery).JSON(w)
+	})
+	mux.HandleFunc("POST /deliveries/{id}/replay", func(w http.ResponseWriter, req *http.Request) {
+		delivery, err := m.Replay(req.Context(), req.PathValue("id"))
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		wrapper.NewHTTPResponse(http.StatusAccepted, "Success", delivery).JSON(w)
+	})
+	return mux
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, ErrDeliveryNotFound) || errors.Is(err, ErrEndpointNotFound) {
+		code = http.StatusNotFound
+	}
+	wrapper.NewHTTPResponse(code, err.Error()).JSON(w)
+}
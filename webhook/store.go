@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golangid/candi/candishared"
+)
+
+type (
+	// Store persist webhook endpoints and delivery logs
+	Store interface {
+		SaveEndpoint(ctx context.Context, endpoint *Endpoint) error
+		GetEndpoint(ctx context.Context, id string) (*Endpoint, error)
+		FindEndpoints(ctx context.Context) ([]Endpoint, error)
+		DeleteEndpoint(ctx context.Context, id string) error
+		SaveDelivery(ctx context.Context, delivery *Delivery) error
+		GetDelivery(ctx context.Context, id string) (*Delivery, error)
+		FindDeliveries(ctx context.Context, filter *DeliveryFilter) (deliveries []Delivery, count int, err error)
+	}
+
+	memoryStore struct {
+		mu         sync.RWMutex
+		endpoints  map[string]Endpoint
+		deliveries map[string]Delivery
+	}
+
+	sqlStore struct {
+		db                           *sql.DB
+		endpointTable, deliveryTable string
+		placeholder                  func(argIndex int) string
+	}
+)
+
+// NewMemoryStore in memory store, for development and testing
+func NewMemoryStore() Store {
+	return &memoryStore{endpoints: make(map[string]Endpoint), deliveries: make(map[string]Delivery)}
+}
+
+func (m *memoryStore) SaveEndpoint(ctx context.Context, endpoint *Endpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpoints[endpoint.ID] = *endpoint
+	return nil
+}
+
+func (m *memoryStore) GetEndpoint(ctx context.Context, id string) (*Endpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	endpoint, ok := m.endpoints[id]
+	if !ok {
+		return nil, ErrEndpointNotFound
+	}
+	return &endpoint, nil
+}
+
+func (m *memoryStore) FindEndpoints(ctx context.Context) (endpoints []Endpoint, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, endpoint := range m.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].CreatedAt.Before(endpoints[j].CreatedAt) })
+	return endpoints, nil
+}
+
+func (m *memoryStore) DeleteEndpoint(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.endpoints, id)
+	return nil
+}
+
+func (m *memoryStore) SaveDelivery(ctx context.Context, delivery *Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (m *memoryStore) GetDelivery(ctx context.Context, id string) (*Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return nil, ErrDeliveryNotFound
+	}
+	return &delivery, nil
+}
+
+func (m *memoryStore) FindDeliveries(ctx context.Context, filter *DeliveryFilter) (deliveries []Delivery, count int, err error) {
+	filter.normalize()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, delivery := range m.deliveries {
+		if (filter.EndpointID == "" || filter.EndpointID == delivery.EndpointID) &&
+			(filter.EventType == "" || filter.EventType == delivery.EventType) &&
+			(filter.Status == "" || filter.Status == delivery.Status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	count = len(deliveries)
+	offset := min((filter.Page-1)*filter.Limit, count)
+	return deliveries[offset:min(offset+filter.Limit, count)], count, nil
+}
+
+// NewSQLStore store in sql table (postgres, mysql or sqlite3), tables is created if not exist with given prefix
+// (default "webhook", table "webhook_endpoints" and "webhook_deliveries")
+func NewSQLStore(db *sql.DB, tablePrefix string) (Store, error) {
+	if tablePrefix == "" {
+		tablePrefix = "webhook"
+	}
+	s := &sqlStore{
+		db: db, endpointTable: tablePrefix + "_endpoints", deliveryTable: tablePrefix + "_deliveries",
+		placeholder: candishared.SQLPlaceholderOf(db),
+	}
+	for _, query := range []string{
+		`CREATE TABLE IF NOT EXISTS ` + s.endpointTable + ` (
+			id VARCHAR(64) NOT NULL PRIMARY KEY,
+			data TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + s.deliveryTable + ` (
+			id VARCHAR(64) NOT NULL PRIMARY KEY,
+			endpoint_id VARCHAR(64) NOT NULL,
+			event_type VARCHAR(255) NOT NULL,
+			status VARCHAR(16) NOT NULL,
+			data TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			return nil, fmt.Errorf("create webhook table: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *sqlStore) SaveEndpoint(ctx context.Context, endpoint *Endpoint) error {
+	data, _ := json.Marshal(endpoint)
+	return s.upsert(ctx, s.endpointTable, endpoint.ID, []string{"data", "created_at"}, string(data), endpoint.CreatedAt)
+}
+
+func (s *sqlStore) GetEndpoint(ctx context.Context, id string) (*Endpoint, error) {
+	var endpoint Endpoint
+	if err := s.get(ctx, s.endpointTable, id, &endpoint); errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEndpointNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (s *sqlStore) FindEndpoints(ctx context.Context) (endpoints []Endpoint, err error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM `+s.endpointTable+` ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		var endpoint Endpoint
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(data), &endpoint)
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+func (s *sqlStore) DeleteEndpoint(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, candishared.SQLRebind(`DELETE FROM `+s.endpointTable+` WHERE id = ?`, s.placeholder), id)
+	return err
+}
+
+func (s *sqlStore) SaveDelivery(ctx context.Context, delivery *Delivery) error {
+	data, _ := json.Marshal(delivery)
+	return s.upsert(ctx, s.deliveryTable, delivery.ID, []string{"endpoint_id", "event_type", "status", "data", "created_at"},
+		delivery.EndpointID, delivery.EventType, delivery.Status, string(data), delivery.CreatedAt)
+}
+
+func (s *sqlStore) GetDelivery(ctx context.Context, id string) (*Delivery, error) {
+	var delivery Delivery
+	if err := s.get(ctx, s.deliveryTable, id, &delivery); errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (s *sqlStore) FindDeliveries(ctx context.Context, filter *DeliveryFilter) (deliveries []Delivery, count int, err error) {
+	filter.normalize()
+	var conditions []string
+	var args []any
+	for _, field := range [][2]string{{"endpoint_id", filter.EndpointID}, {"event_type", filter.EventType}, {"status", filter.Status}} {
+		if field[1] != "" {
+			conditions = append(conditions, field[0]+" = ?")
+			args = append(args, field[1])
+		}
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if err := s.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT COUNT(*) FROM `+s.deliveryTable+where, s.placeholder), args...).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, candishared.SQLRebind(`SELECT data FROM `+s.deliveryTable+where+` ORDER BY created_at DESC LIMIT `+
+		strconv.Itoa(filter.Limit)+` OFFSET `+strconv.Itoa((filter.Page-1)*filter.Limit), s.placeholder), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		var delivery Delivery
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, err
+		}
+		json.Unmarshal([]byte(data), &delivery)
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, count, rows.Err()
+}
+
+func (s *sqlStore) get(ctx context.Context, table, id string, target any) error {
+	var data string
+	if err := s.db.QueryRowContext(ctx, candishared.SQLRebind(`SELECT data FROM `+table+` WHERE id = ?`, s.placeholder), id).Scan(&data); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), target)
+}
+
+func (s *sqlStore) upsert(ctx context.Context, table, id string, columns []string, values ...any) error {
+	res, err := s.db.ExecContext(ctx, candishared.SQLRebind(`UPDATE `+table+` SET `+strings.Join(columns, " = ?, ")+` = ? WHERE id = ?`, s.placeholder),
+		append(values, id)...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected > 0 {
+		return nil
+	}
+	_, err = s.db.ExecContext(ctx, candishared.SQLRebind(`INSERT INTO `+table+` (id, `+strings.Join(columns, ", ")+`) VALUES (?`+
+		strings.Repeat(", ?", len(columns))+`)`, s.placeholder), append([]any{id}, values...)...)
+	return err
+}
+
+func (f *DeliveryFilter) normalize() {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 {
+		f.Limit = 10
+	}
+}
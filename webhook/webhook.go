@@ -0,0 +1,264 @@
+// Package webhook outbound webhook delivery built on task queue worker, register endpoint per event type, deliver with
+// HMAC signature, exponential retry with jitter, per-endpoint circuit breaking, queryable delivery log and replay
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangid/candi/candihelper"
+	taskqueueworker "github.com/golangid/candi/codebase/app/task_queue_worker"
+	"github.com/google/uuid"
+)
+
+const (
+	// HeaderID header of delivery id (idempotency key for receiver)
+	HeaderID = "X-Webhook-ID"
+	// HeaderEvent header of event type
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderTimestamp header of unix timestamp (second) of signature
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature header of signature "sha256=<hex HMAC-SHA256 of timestamp.body>"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// DefaultTaskName task queue worker task name of webhook delivery
+	DefaultTaskName = "candi-webhook-delivery"
+)
+
+// Delivery status
+const (
+	StatusPending = "PENDING"
+	StatusSuccess = "SUCCESS"
+	StatusFailure = "FAILURE"
+)
+
+var (
+	// ErrEndpointNotFound endpoint not found
+	ErrEndpointNotFound = errors.New("webhook: endpoint not found")
+	// ErrDeliveryNotFound delivery not found
+	ErrDeliveryNotFound = errors.New("webhook: delivery not found")
+)
+
+type (
+	// Endpoint webhook subscriber endpoint
+	Endpoint struct {
+		ID     string `json:"id"`
+		URL    string `json:"url"`
+		Secret string `json:"secret,omitempty"`
+		// EventTypes subscribed event types, support glob pattern (example "order.*"), empty for all event types
+		EventTypes []string  `json:"event_types"`
+		Active     bool      `json:"active"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	// Delivery log of webhook delivery
+	Delivery struct {
+		ID           string          `json:"id"`
+		EndpointID   string          `json:"endpoint_id"`
+		EventType    string          `json:"event_type"`
+		Payload      json.RawMessage `json:"payload"`
+		Status       string          `json:"status"`
+		Attempts     int             `json:"attempts"`
+		StatusCode   int             `json:"status_code,omitempty"`
+		ResponseBody string          `json:"response_body,omitempty"`
+		Error        string          `json:"error,omitempty"`
+		ReplayOf     string          `json:"replay_of,omitempty"`
+		CreatedAt    time.Time       `json:"created_at"`
+		UpdatedAt    time.Time       `json:"updated_at"`
+	}
+
+	// DeliveryFilter filter delivery log, empty field is not filtered
+	DeliveryFilter struct {
+		EndpointID string
+		EventType  string
+		Status     string
+		Page       int
+		Limit      int
+	}
+
+	// Manager outbound webhook manager
+	Manager struct {
+		store      Store
+		httpClient *http.Client
+		taskName   string
+		maxRetry   int
+		baseDelay  time.Duration
+		maxDelay   time.Duration
+
+		breakerThreshold int
+		breakerCooldown  time.Duration
+		breakers         sync.Map // endpoint id -> *circuitBreaker
+
+		enqueue func(ctx context.Context, taskName string, maxRetry int, deliveryID string) error
+	}
+
+	// OptionFunc option func of manager
+	OptionFunc func(*Manager)
+)
+
+// SetHTTPClient option func, default client with 10 seconds timeout
+func SetHTTPClient(httpClient *http.Client) OptionFunc {
+	return func(m *Manager) {
+		m.httpClient = httpClient
+	}
+}
+
+// SetTaskName option func, default DefaultTaskName
+func SetTaskName(taskName string) OptionFunc {
+	return func(m *Manager) {
+		m.taskName = taskName
+	}
+}
+
+// SetRetry option func, exponential backoff from baseDelay capped at maxDelay with jitter,
+// default 8 retries from 10 seconds to 1 hour
+func SetRetry(maxRetry int, baseDelay, maxDelay time.Duration) OptionFunc {
+	return func(m *Manager) {
+		m.maxRetry, m.baseDelay, m.maxDelay = maxRetry, baseDelay, maxDelay
+	}
+}
+
+// SetCircuitBreaker option func, endpoint circuit is open after threshold consecutive failures and delivery is
+// postponed until cooldown elapsed, default 5 failures and 1 minute cooldown
+func SetCircuitBreaker(threshold int, cooldown time.Duration) OptionFunc {
+	return func(m *Manager) {
+		m.breakerThreshold, m.breakerCooldown = threshold, cooldown
+	}
+}
+
+// NewManager create webhook manager, register TaskHandler in task queue worker with manager task name
+func NewManager(store Store, opts ...OptionFunc) *Manager {
+	m := &Manager{
+		store:            store,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+		taskName:         DefaultTaskName,
+		maxRetry:         8,
+		baseDelay:        10 * time.Second,
+		maxDelay:         time.Hour,
+		breakerThreshold: 5,
+		breakerCooldown:  time.Minute,
+		enqueue:          addDeliveryJob,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// TaskName task queue worker task name of delivery
+func (m *Manager) TaskName() string {
+	return m.taskName
+}
+
+// RegisterEndpoint create or update endpoint, id and secret is generated if empty
+func (m *Manager) RegisterEndpoint(ctx context.Context, endpoint *Endpoint) error {
+	if endpoint.URL == "" {
+		return errors.New("webhook: endpoint url cannot empty")
+	}
+	if endpoint.ID == "" {
+		endpoint.ID = uuid.NewString()
+	}
+	if endpoint.Secret == "" {
+		secret := make([]byte, 24)
+		rand.Read(secret)
+		endpoint.Secret = "whsec_" + hex.EncodeToString(secret)
+	}
+	if endpoint.CreatedAt.IsZero() {
+		endpoint.CreatedAt = time.Now()
+	}
+	return m.store.SaveEndpoint(ctx, endpoint)
+}
+
+// Publish deliver event to all active endpoints subscribed to event type, return created deliveries
+func (m *Manager) Publish(ctx context.Context, eventType string, data any) ([]Delivery, error) {
+	endpoints, err := m.store.FindEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload := candihelper.ToBytes(data)
+
+	var deliveries []Delivery
+	var errs []error
+	for _, endpoint := range endpoints {
+		if !endpoint.Active || !endpoint.Subscribe(eventType) {
+			continue
+		}
+		delivery := &Delivery{EndpointID: endpoint.ID, EventType: eventType, Payload: payload}
+		if err := m.createDelivery(ctx, delivery); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, errors.Join(errs...)
+}
+
+// Replay deliver payload of existing delivery again as new delivery
+func (m *Manager) Replay(ctx context.Context, deliveryID string) (*Delivery, error) {
+	origin, err := m.store.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	delivery := &Delivery{EndpointID: origin.EndpointID, EventType: origin.EventType, Payload: origin.Payload, ReplayOf: origin.ID}
+	return delivery, m.createDelivery(ctx, delivery)
+}
+
+func (m *Manager) createDelivery(ctx context.Context, delivery *Delivery) error {
+	delivery.ID = uuid.NewString()
+	delivery.Status = StatusPending
+	delivery.CreatedAt, delivery.UpdatedAt = time.Now(), time.Now()
+	if err := m.store.SaveDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	return m.enqueue(ctx, m.taskName, m.maxRetry, delivery.ID)
+}
+
+func addDeliveryJob(ctx context.Context, taskName string, maxRetry int, deliveryID string) error {
+	_, err := taskqueueworker.AddJob(ctx, &taskqueueworker.AddJobRequest{
+		TaskName: taskName, MaxRetry: maxRetry, Args: []byte(deliveryID),
+	})
+	return err
+}
+
+// Subscribe check endpoint subscribed to event type
+func (e *Endpoint) Subscribe(eventType string) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, pattern := range e.EventTypes {
+		if ok, _ := path.Match(pattern, eventType); ok || pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign create signature of payload with secret, signed content is "<timestamp>.<payload>"
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature verify signature header of received webhook, tolerance is maximum age of timestamp (zero for no check)
+func VerifySignature(secret string, header http.Header, payload []byte, tolerance time.Duration) bool {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, payload)))
+}
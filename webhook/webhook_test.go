@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golangid/candi/candierrors"
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerDeliver(t *testing.T) {
+	var statusCode atomic.Int32
+	statusCode.Store(http.StatusServiceUnavailable)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if !VerifySignature("secret", req.Header, body, time.Minute) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(int(statusCode.Load()))
+	}))
+	defer receiver.Close()
+
+	ctx := context.Background()
+	var queued []string
+	manager := NewManager(NewMemoryStore(), SetCircuitBreaker(2, time.Minute))
+	manager.enqueue = func(ctx context.Context, taskName string, maxRetry int, deliveryID string) error {
+		queued = append(queued, deliveryID)
+		return nil
+	}
+
+	require.NoError(t, manager.RegisterEndpoint(ctx, &Endpoint{URL: receiver.URL, Secret: "secret", EventTypes: []string{"order.*"}, Active: true}))
+	require.NoError(t, manager.RegisterEndpoint(ctx, &Endpoint{URL: receiver.URL, EventTypes: []string{"payment.*"}, Active: true}))
+	deliveries, err := manager.Publish(ctx, "order.created", map[string]string{"id": "1"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, []string{deliveries[0].ID}, queued)
+
+	err = manager.deliver(ctx, deliveries[0].ID)
+	var retrier *candishared.ErrorRetrier
+	require.True(t, errors.As(err, &retrier))
+	assert.True(t, retrier.Delay >= 5*time.Second && retrier.Delay <= 10*time.Second)
+	delivery, _ := manager.store.GetDelivery(ctx, deliveries[0].ID)
+	assert.Equal(t, StatusFailure, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.StatusCode)
+
+	statusCode.Store(http.StatusOK)
+	eventContext := candishared.NewEventContext(&bytes.Buffer{})
+	eventContext.SetContext(ctx)
+	eventContext.WriteString(deliveries[0].ID)
+	require.NoError(t, manager.TaskHandler()(eventContext))
+	delivery, _ = manager.store.GetDelivery(ctx, deliveries[0].ID)
+	assert.Equal(t, StatusSuccess, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+
+	replayed, err := manager.Replay(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, delivery.ID, replayed.ReplayOf)
+
+	statusCode.Store(http.StatusBadRequest)
+	err = manager.deliver(ctx, replayed.ID)
+	class, _ := candierrors.Classify(err)
+	assert.Equal(t, candierrors.ClassPermanent, class)
+
+	assert.Error(t, manager.deliver(ctx, replayed.ID))
+	err = manager.deliver(ctx, replayed.ID)
+	require.True(t, errors.As(err, &retrier))
+	assert.Contains(t, retrier.Message, "circuit open")
+	delivery, _ = manager.store.GetDelivery(ctx, replayed.ID)
+	assert.Equal(t, 2, delivery.Attempts)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	manager := NewManager(NewMemoryStore())
+	manager.enqueue = func(ctx context.Context, taskName string, maxRetry int, deliveryID string) error { return nil }
+	handler := manager.HTTPHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/endpoints",
+		strings.NewReader(`{"url":"http://localhost/hook","event_types":["order.*"],"active":true}`)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"secret":"whsec_`)
+
+	deliveries, _ := manager.Publish(context.Background(), "order.paid", map[string]string{"id": "1"})
+	require.Len(t, deliveries, 1)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deliveries?event_type=order.paid", nil))
+	assert.Contains(t, rec.Body.String(), `"totalRecords":1`)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/deliveries/"+deliveries[0].ID+"/replay", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/deliveries/unknown/replay", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,303 @@
+package wrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/golangid/candi/candishared"
+)
+
+const (
+	// FieldMaskTag struct tag for mask field unless caller has one of scope (separated by comma), example: `mask:"pii:read,admin"`
+	FieldMaskTag = "mask"
+	// FieldMaskString replacement value for masked string field
+	FieldMaskString = "***"
+)
+
+// FieldMaskPolicy mask field in path (json name separated by dot, example: "user.email") unless caller has one of scopes
+type FieldMaskPolicy struct {
+	Path   string
+	Scopes []string
+}
+
+// MaskFields mask field with tag `mask` and field in policies unless caller has required scope (see candishared.GetScopesFromContext).
+// Masked string field replaced with FieldMaskString, other type replaced with zero value.
+// Data masked in place, so data must be pointer (or slice/map contains pointer), can be used for REST and GraphQL resolver result.
+// Struct value behind interface or in map copied, masked, then written back.
+// Return error if field must be masked but cannot be set (example: data passed as non pointer struct)
+func MaskFields(ctx context.Context, data any, policies ...FieldMaskPolicy) error {
+	m := fieldMasker{scopes: candishared.GetScopesFromContext(ctx), policies: make(map[string][]string, len(policies))}
+	for _, policy := range policies {
+		m.policies[policy.Path] = policy.Scopes
+	}
+	return m.walk(reflect.ValueOf(data), "", 0)
+}
+
+type fieldMasker struct {
+	scopes   []string
+	policies map[string][]string
+}
+
+func (m *fieldMasker) allowed(required []string) bool {
+	for _, scope := range required {
+		if slices.Contains(m.scopes, strings.TrimSpace(scope)) {
+			return true
+		}
+	}
+	return len(required) == 0
+}
+
+func (m *fieldMasker) masked(path string, tag string) bool {
+	if tag != "" && !m.allowed(strings.Split(tag, ",")) {
+		return true
+	}
+	if required, ok := m.policies[path]; ok && !m.allowed(required) {
+		return true
+	}
+	return false
+}
+
+func (m *fieldMasker) walk(v reflect.Value, path string, depth int) error {
+	if depth > 32 || !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return m.walk(v.Elem(), path, depth+1)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := v.Elem()
+		if v.CanSet() && (elem.Kind() == reflect.Struct || elem.Kind() == reflect.Array) {
+			// value behind interface is not addressable, mask the copy then write back
+			copied := reflect.New(elem.Type()).Elem()
+			copied.Set(elem)
+			if err := m.walk(copied, path, depth+1); err != nil {
+				return err
+			}
+			v.Set(copied)
+			return nil
+		}
+		return m.walk(elem, path, depth+1)
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := jsonFieldName(field)
+			if name == "-" {
+				continue
+			}
+			fieldPath := name
+			if field.Anonymous && field.Tag.Get("json") == "" {
+				fieldPath = path // embedded struct fields promoted to parent
+			} else if path != "" {
+				fieldPath = path + "." + name
+			}
+
+			fieldValue := v.Field(i)
+			if fieldPath != path && m.masked(fieldPath, field.Tag.Get(FieldMaskTag)) {
+				if !fieldValue.CanSet() {
+					return fmt.Errorf("mask field: cannot set field %q of non addressable %s", fieldPath, t)
+				}
+				fieldValue.Set(maskedValue(fieldValue.Type()))
+				continue
+			}
+			if err := m.walk(fieldValue, fieldPath, depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := m.walk(v.Index(i), path, depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			keyPath := iter.Key().String()
+			if path != "" {
+				keyPath = path + "." + keyPath
+			}
+			if m.masked(keyPath, "") {
+				v.SetMapIndex(iter.Key(), maskedValue(v.Type().Elem()))
+				continue
+			}
+
+			value := iter.Value()
+			switch value.Kind() {
+			case reflect.Struct, reflect.Array, reflect.Interface:
+				// map value is not addressable, mask the copy then write back
+				copied := reflect.New(v.Type().Elem()).Elem()
+				copied.Set(value)
+				if err := m.walk(copied, keyPath, depth+1); err != nil {
+					return err
+				}
+				v.SetMapIndex(iter.Key(), copied)
+			default:
+				if err := m.walk(value, keyPath, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func maskedValue(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(FieldMaskString).Convert(t)
+	}
+	if t.Kind() == reflect.Interface {
+		return reflect.ValueOf(FieldMaskString)
+	}
+	return reflect.Zero(t)
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// SelectFields sparse fieldsets, only keep given fields (json name path separated by dot, example: "id", "user.name") in data,
+// list data is filtered for each item. Data converted to generic json value
+func SelectFields(data any, fields ...string) (any, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return result, nil
+	}
+
+	tree := make(fieldTree)
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			tree.add(strings.Split(field, "."))
+		}
+	}
+	return tree.filter(result), nil
+}
+
+type fieldTree map[string]fieldTree
+
+func (f fieldTree) add(path []string) {
+	name := path[0]
+	child, exists := f[name]
+	if exists && child == nil {
+		return // whole field already selected
+	}
+	if len(path) == 1 {
+		f[name] = nil
+		return
+	}
+	if child == nil {
+		child = make(fieldTree)
+		f[name] = child
+	}
+	child.add(path[1:])
+}
+
+func (f fieldTree) filter(data any) any {
+	switch val := data.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(f))
+		for key, child := range f {
+			fieldValue, ok := val[key]
+			if !ok {
+				continue
+			}
+			if child == nil {
+				result[key] = fieldValue
+			} else {
+				result[key] = child.filter(fieldValue)
+			}
+		}
+		return result
+	case []any:
+		for i, item := range val {
+			val[i] = f.filter(item)
+		}
+		return val
+	}
+	return data
+}
+
+// ParseFieldsQuery parse sparse fieldsets from query param "fields" (separated by comma), example: ?fields=id,name,address.city
+func ParseFieldsQuery(req *http.Request) []string {
+	fields := req.URL.Query().Get("fields")
+	if fields == "" {
+		return nil
+	}
+	return strings.Split(fields, ",")
+}
+
+// MaskFields mask response data field based on caller scope (see MaskFields function),
+// non pointer data copied before masked (nested slice and map still masked in place), data removed if cannot be masked
+func (resp *HTTPResponse) MaskFields(ctx context.Context, policies ...FieldMaskPolicy) *HTTPResponse {
+	if resp.Data == nil {
+		return resp
+	}
+	v := reflect.ValueOf(resp.Data)
+	if v.Kind() != reflect.Pointer {
+		copied := reflect.New(v.Type())
+		copied.Elem().Set(v)
+		if err := MaskFields(ctx, copied.Interface(), policies...); err != nil {
+			resp.Data = nil // fail closed, never return unmasked data
+			return resp
+		}
+		resp.Data = copied.Elem().Interface()
+		return resp
+	}
+	if err := MaskFields(ctx, resp.Data, policies...); err != nil {
+		resp.Data = nil
+	}
+	return resp
+}
+
+// SelectFields only keep given fields in response data (see SelectFields function)
+func (resp *HTTPResponse) SelectFields(fields ...string) *HTTPResponse {
+	if resp.Data == nil || len(fields) == 0 {
+		return resp
+	}
+	if data, err := SelectFields(resp.Data, fields...); err == nil {
+		resp.Data = data
+	}
+	return resp
+}
+
+// ApplyFields mask response data based on caller scope from request context,
+// then apply sparse fieldsets from query param "fields"
+func (resp *HTTPResponse) ApplyFields(req *http.Request, policies ...FieldMaskPolicy) *HTTPResponse {
+	return resp.MaskFields(req.Context(), policies...).SelectFields(ParseFieldsQuery(req)...)
+}
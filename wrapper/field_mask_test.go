@@ -0,0 +1,104 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangid/candi/candishared"
+	"github.com/stretchr/testify/assert"
+)
+
+type maskAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type maskUser struct {
+	ID      int            `json:"id"`
+	Name    string         `json:"name"`
+	Email   string         `json:"email" mask:"pii:read,admin"`
+	Salary  int            `json:"salary" mask:"admin"`
+	Address *maskAddress   `json:"address"`
+	Meta    map[string]any `json:"meta"`
+}
+
+func newMaskUsers() []*maskUser {
+	return []*maskUser{
+		{ID: 1, Name: "agung", Email: "agung@mail.com", Salary: 10, Address: &maskAddress{City: "Jakarta", Street: "Sudirman"}, Meta: map[string]any{"phone": "0812"}},
+		{ID: 2, Name: "budi", Email: "budi@mail.com", Salary: 20},
+	}
+}
+
+func TestMaskFields(t *testing.T) {
+	policies := []FieldMaskPolicy{{Path: "address.street", Scopes: []string{"pii:read"}}, {Path: "meta.phone", Scopes: []string{"pii:read"}}}
+
+	users := newMaskUsers()
+	assert.NoError(t, MaskFields(context.Background(), users, policies...))
+	assert.Equal(t, FieldMaskString, users[0].Email)
+	assert.Equal(t, 0, users[0].Salary)
+	assert.Equal(t, FieldMaskString, users[0].Address.Street)
+	assert.Equal(t, "Jakarta", users[0].Address.City)
+	assert.Equal(t, FieldMaskString, users[0].Meta["phone"])
+	assert.Equal(t, "budi", users[1].Name)
+
+	users = newMaskUsers()
+	ctx := candishared.SetScopesToContext(context.Background(), "pii:read")
+	assert.NoError(t, MaskFields(ctx, users, policies...))
+	assert.Equal(t, "agung@mail.com", users[0].Email)
+	assert.Equal(t, 0, users[0].Salary)
+	assert.Equal(t, "Sudirman", users[0].Address.Street)
+
+	users = newMaskUsers()
+	ctx = candishared.SetToContext(context.Background(), candishared.ContextKeyTokenClaim, &candishared.TokenClaim{Role: "admin"})
+	assert.NoError(t, MaskFields(ctx, users))
+	assert.Equal(t, 10, users[0].Salary)
+
+	// non pointer data copied
+	user := *newMaskUsers()[1]
+	resp := NewHTTPResponse(200, "ok", user).MaskFields(context.Background())
+	assert.Equal(t, FieldMaskString, resp.Data.(maskUser).Email)
+	assert.Equal(t, "budi@mail.com", user.Email)
+}
+
+func TestMaskFieldsNonAddressable(t *testing.T) {
+	ctx := context.Background()
+
+	// struct behind interface in slice
+	list := []any{maskUser{Name: "agung", Email: "agung@mail.com"}}
+	assert.NoError(t, MaskFields(ctx, list))
+	assert.Equal(t, FieldMaskString, list[0].(maskUser).Email)
+	assert.Equal(t, "agung", list[0].(maskUser).Name)
+
+	// struct behind interface in map
+	data := map[string]any{"u": maskUser{Email: "agung@mail.com", Address: &maskAddress{Street: "Sudirman"}}}
+	assert.NoError(t, MaskFields(ctx, data, FieldMaskPolicy{Path: "u.address.street", Scopes: []string{"admin"}}))
+	assert.Equal(t, FieldMaskString, data["u"].(maskUser).Email)
+	assert.Equal(t, FieldMaskString, data["u"].(maskUser).Address.Street)
+
+	// map value of struct type
+	users := map[string]maskUser{"a": {Email: "agung@mail.com", Salary: 10}}
+	assert.NoError(t, MaskFields(ctx, users))
+	assert.Equal(t, FieldMaskString, users["a"].Email)
+	assert.Equal(t, 0, users["a"].Salary)
+
+	// non pointer struct cannot be masked in place
+	assert.Error(t, MaskFields(ctx, maskUser{Email: "agung@mail.com"}))
+}
+
+func TestSelectFields(t *testing.T) {
+	data, err := SelectFields(newMaskUsers(), "id", "address.city", "address", "meta.phone", "unknown")
+	assert.NoError(t, err)
+	b, _ := json.Marshal(data)
+	assert.JSONEq(t, `[
+		{"id":1,"address":{"city":"Jakarta","street":"Sudirman"},"meta":{"phone":"0812"}},
+		{"id":2,"address":null,"meta":null}
+	]`, string(b))
+
+	req := httptest.NewRequest("GET", "/users/1?fields=id,name,address.city", nil)
+	req = req.WithContext(candishared.SetScopesToContext(req.Context(), "other"))
+	resp := NewHTTPResponse(200, "ok", newMaskUsers()[0]).ApplyFields(req)
+	b, _ = json.Marshal(resp.Data)
+	assert.JSONEq(t, `{"id":1,"name":"agung","address":{"city":"Jakarta"}}`, string(b))
+}
@@ -8,7 +8,11 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/golangid/candi/candihelper"
+	"github.com/golangid/candi/candishared"
 	"github.com/golangid/candi/config/env"
+	"github.com/golangid/candi/logger"
+	"go.uber.org/zap/zapcore"
 )
 
 // HTTPHandlerDefaultRoot default root http handler
@@ -45,7 +49,7 @@ func HTTPHandlerMemstats(w http.ResponseWriter, r *http.Request) {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 	data := struct {
-		NumGoroutine int         `json:"num_goroutine"`
+		NumGoroutine int `json:"num_goroutine"`
 		Memstats     any `json:"memstats"`
 	}{
 		runtime.NumGoroutine(), m,
@@ -53,3 +57,79 @@ func HTTPHandlerMemstats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
+
+// HTTPHandlerLogLevel get (GET) or change (PUT) runtime log level & sampling, and reset scope log level (DELETE with query param "scope").
+// Example PUT payload: {"level": "debug", "scope": "UserUsecase", "revert_after": "15m", "sampling": {"first": 100, "thereafter": 100, "tick": "1s"}}
+func HTTPHandlerLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+
+	case http.MethodPut, http.MethodPost:
+		var payload struct {
+			Level       string                 `json:"level"`
+			Scope       string                 `json:"scope"`
+			RevertAfter string                 `json:"revert_after"`
+			Sampling    *logger.SamplingConfig `json:"sampling"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			NewHTTPResponse(http.StatusBadRequest, "Invalid payload", err).JSON(w)
+			return
+		}
+
+		var revertAfter time.Duration
+		if payload.RevertAfter != "" {
+			var err error
+			if revertAfter, err = time.ParseDuration(payload.RevertAfter); err != nil {
+				NewHTTPResponse(http.StatusBadRequest, "Invalid revert_after", err).JSON(w)
+				return
+			}
+		}
+
+		if payload.Level != "" {
+			level, err := zapcore.ParseLevel(payload.Level)
+			if err != nil {
+				NewHTTPResponse(http.StatusBadRequest, "Invalid level", err).JSON(w)
+				return
+			}
+			if payload.Scope != "" {
+				logger.SetScopeLevel(payload.Scope, level, revertAfter)
+			} else {
+				logger.SetLevel(level, revertAfter)
+			}
+		}
+
+		if payload.Sampling != nil {
+			var tick time.Duration
+			if payload.Sampling.Tick != "" {
+				var err error
+				if tick, err = time.ParseDuration(payload.Sampling.Tick); err != nil {
+					NewHTTPResponse(http.StatusBadRequest, "Invalid sampling tick", err).JSON(w)
+					return
+				}
+			}
+			logger.SetSampling(payload.Sampling.First, payload.Sampling.Thereafter, tick, revertAfter)
+		}
+
+	case http.MethodDelete:
+		logger.ResetScopeLevel(r.URL.Query().Get("scope"))
+
+	default:
+		NewHTTPResponse(http.StatusMethodNotAllowed, "Method not allowed").JSON(w)
+		return
+	}
+
+	NewHTTPResponse(http.StatusOK, "Log level", logger.GetLevelConfig()).JSON(w)
+}
+
+// HTTPMiddlewareRequestID accept X-Request-ID request header or generate new request id, set to request context
+// (see candishared.GetRequestID) and response header
+func HTTPMiddlewareRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, requestID := candishared.ExtractRequestID(r.Context(), map[string]string{
+			candihelper.HeaderXRequestID: r.Header.Get(candihelper.HeaderXRequestID),
+		})
+		r.Header.Set(candihelper.HeaderXRequestID, requestID)
+		w.Header().Set(candihelper.HeaderXRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
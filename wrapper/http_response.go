@@ -1,22 +1,26 @@
 package wrapper
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"net/http"
 
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
+	"github.com/golangid/candi/i18n"
 )
 
 // HTTPResponse default candi http response format
 type HTTPResponse struct {
-	Success bool        `json:"success"`
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Meta    any `json:"meta,omitempty"`
-	Data    any `json:"data,omitempty"`
-	Errors  any `json:"errors,omitempty"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Meta    any    `json:"meta,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Errors  any    `json:"errors,omitempty"`
 }
 
 // NewHTTPResponse for create common response
@@ -27,6 +31,10 @@ func NewHTTPResponse(code int, message string, params ...any) *HTTPResponse {
 		switch val := param.(type) {
 		case *candishared.Meta, candishared.Meta:
 			commonResponse.Meta = val
+		case candishared.CursorMeta:
+			commonResponse.Meta = candishared.NewCursorMeta(val)
+		case *candishared.CursorMeta:
+			commonResponse.Meta = candishared.NewCursorMeta(*val)
 		case candihelper.MultiError:
 			commonResponse.Errors = val.ToMap()
 		case error:
@@ -51,6 +59,44 @@ func NewHTTPResponseWithMeta[M any](code int, message string, meta M, params ...
 	return commonResponse
 }
 
+// NewHTTPResponseFromError for create error response, http status code taken from error
+// if implement `HTTPStatusCode() int` (example: validator.ValidationError is 400 Bad Request, candierrors.NotFound is 404),
+// request body exceeding max size (413), or context error (deadline exceeded is 504), else using default code
+func NewHTTPResponseFromError(defaultCode int, message string, err error) *HTTPResponse {
+	var errCode interface{ HTTPStatusCode() int }
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &errCode) {
+		defaultCode = errCode.HTTPStatusCode()
+	} else if errors.As(err, &maxBytesErr) {
+		defaultCode = http.StatusRequestEntityTooLarge
+	} else if code := candierrors.CodeOf(err); code != candierrors.CodeUnknown {
+		defaultCode = code.HTTPStatus()
+	}
+
+	if e, ok := candierrors.As(err); ok {
+		errs := map[string]any{"code": e.Code, "detail": e.Message}
+		if len(e.Metadata) > 0 {
+			errs["metadata"] = e.Metadata
+		}
+		resp := NewHTTPResponse(defaultCode, message)
+		resp.Errors = errs
+		return resp
+	}
+	return NewHTTPResponse(defaultCode, message, err)
+}
+
+// Translate message and errors to locale from context using i18n default catalog,
+// message and error value used as catalog key (not translated if key not found in catalog)
+func (resp *HTTPResponse) Translate(ctx context.Context) *HTTPResponse {
+	resp.Message = i18n.T(ctx, resp.Message)
+	if errs, ok := resp.Errors.(map[string]string); ok {
+		for field, message := range errs {
+			errs[field] = i18n.T(ctx, message)
+		}
+	}
+	return resp
+}
+
 // JSON for set http JSON response (Content-Type: application/json) with parameter is http response writer
 func (resp *HTTPResponse) JSON(w http.ResponseWriter) error {
 	w.Header().Set(candihelper.HeaderContentType, candihelper.HeaderMIMEApplicationJSON)
@@ -1,6 +1,7 @@
 package wrapper
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/golangid/candi/candierrors"
 	"github.com/golangid/candi/candihelper"
 	"github.com/golangid/candi/candishared"
 	"github.com/stretchr/testify/assert"
@@ -116,6 +118,22 @@ func TestNewHTTPResponse(t *testing.T) {
 	}
 }
 
+func TestNewHTTPResponseFromError(t *testing.T) {
+	resp := NewHTTPResponseFromError(http.StatusInternalServerError, "Failed", candierrors.NotFound("user not found").WithMetadata("id", "1"))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, map[string]any{"code": candierrors.CodeNotFound, "detail": "user not found", "metadata": map[string]any{"id": "1"}}, resp.Errors)
+
+	resp = NewHTTPResponseFromError(http.StatusInternalServerError, "Failed", fmt.Errorf("query: %w", context.DeadlineExceeded))
+	assert.Equal(t, http.StatusGatewayTimeout, resp.Code)
+
+	resp = NewHTTPResponseFromError(http.StatusBadRequest, "Failed", fmt.Errorf("read body: %w", &http.MaxBytesError{Limit: 10}))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	resp = NewHTTPResponseFromError(http.StatusBadRequest, "Failed", errors.New("error"))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, map[string]string{"detail": "error"}, resp.Errors)
+}
+
 func TestHTTPResponse_JSON(t *testing.T) {
 	rec := httptest.NewRecorder()
 	resp := NewHTTPResponse(200, "success")